@@ -1,18 +1,21 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
 	"rdma-burst/internal/models"
 	"rdma-burst/internal/services/config"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/internal/services/transfer"
+	"rdma-burst/pkg/client"
 	"rdma-burst/pkg/logger"
 )
 
@@ -21,24 +24,24 @@ const (
 )
 
 func main() {
-	// 初始化日志
-	logger, err := logger.NewLogger()
-	if err != nil {
-		log.Fatalf("初始化日志失败: %v", err)
-	}
-	defer logger.Sync()
-
-	// 加载配置
+	// 加载配置（日志系统依赖配置，加载失败时输出到标准错误）
 	configManager := config.NewConfigManager("client")
 	configPath := getConfigPath()
 	
 	clientConfig, err := configManager.LoadConfig(configPath)
 	if err != nil {
-		logger.Fatal("加载配置失败", zap.Error(err))
+		log.Fatalf("加载配置失败: %v", err)
 	}
 
 	cfg := clientConfig.(*models.ClientConfig)
 
+	// 初始化日志
+	if err := logger.Init(newLoggerConfig(cfg.Logging)); err != nil {
+		log.Printf("初始化文件日志失败，使用默认日志: %v", err)
+	}
+	logger := logger.GetLogger()
+	defer logger.Sync()
+
 	// 解析命令行参数
 	if len(os.Args) < 2 {
 		printUsage()
@@ -50,12 +53,18 @@ func main() {
 	switch command {
 	case "transfer":
 		handleTransferCommand(cfg, logger)
+	case "verify":
+		handleVerifyCommand(cfg, logger)
 	case "status":
 		handleStatusCommand(cfg, logger)
 	case "list":
 		handleListCommand(cfg, logger)
 	case "cancel":
 		handleCancelCommand(cfg, logger)
+	case "resume":
+		handleResumeCommand(cfg, logger)
+	case "wait":
+		handleWaitCommand(cfg, logger)
 	case "health":
 		handleHealthCommand(cfg, logger)
 	default:
@@ -68,8 +77,8 @@ func main() {
 // handleTransferCommand 处理传输命令
 func handleTransferCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	if len(os.Args) < 5 {
-		fmt.Println("用法: client transfer <filename> <mode> <direction> [server_ip]")
-		fmt.Println("模式: hugepages, tmpfs, filesystem")
+		fmt.Println("用法: client transfer <filename> <mode> <direction> [server_ip] [key=value ...]")
+		fmt.Println("模式: hugepages, tmpfs, filesystem, auto (按文件大小自动选择)")
 		fmt.Println("方向: put (上传), get (下载)")
 		os.Exit(1)
 	}
@@ -78,11 +87,21 @@ func handleTransferCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	mode := os.Args[3]
 	direction := os.Args[4]
 	
-	var serverIP string
-	if len(os.Args) > 5 {
-		serverIP = os.Args[5]
-	} else {
-		serverIP = cfg.Server.Host
+	// 剩余参数中 key=value 形式的为标签，其余为服务端地址
+	serverIP := cfg.Server.Host
+	var selectors []string
+	for _, arg := range os.Args[5:] {
+		if strings.Contains(arg, "=") {
+			selectors = append(selectors, arg)
+		} else {
+			serverIP = arg
+		}
+	}
+
+	labels, err := transfer.ParseLabelSelector(selectors)
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
 	}
 
 	// 构建传输请求
@@ -91,13 +110,21 @@ func handleTransferCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 		Mode:      mode,
 		Direction: direction,
 		ServerIP:  serverIP,
+		Labels:    labels,
 	}
 
-	// 发送传输请求
-	client := createHTTPClient(cfg)
-	url := fmt.Sprintf("http://%s:%d/api/v1/transfers", cfg.Server.Host, cfg.Server.Port)
+	// put 携带文件大小，服务端据此检查模式的文件大小上限并为 auto 模式选择传输模式
+	if direction == models.DirectionPut {
+		info, err := os.Stat(filename)
+		if err != nil {
+			fmt.Printf("错误: 获取文件信息失败: %v\n", err)
+			os.Exit(1)
+		}
+		req.Size = info.Size()
+	}
 
-	response, err := sendTransferRequest(client, url, req)
+	// 发送传输请求
+	response, err := newAPIClient(cfg).CreateTransfer(context.Background(), req)
 	if err != nil {
 		logger.Error("传输请求失败", zap.Error(err))
 		os.Exit(1)
@@ -107,9 +134,54 @@ func handleTransferCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	fmt.Printf("任务ID: %s\n", response.ID)
 	fmt.Printf("状态: %s\n", response.Status)
 	fmt.Printf("消息: %s\n", response.Message)
+	if response.ModeDecision != "" {
+		fmt.Printf("传输模式: %s (%s)\n", response.Mode, response.ModeDecision)
+	}
 	fmt.Printf("创建时间: %s\n", response.CreatedAt.Format(time.RFC3339))
 }
 
+// handleVerifyCommand 处理校验命令：重新计算本地文件的分块摘要，由服务端与其文件比较
+func handleVerifyCommand(cfg *models.ClientConfig, logger *zap.Logger) {
+	if len(os.Args) < 5 {
+		fmt.Println("用法: client verify <filename> <mode> <direction> [key=value ...]")
+		os.Exit(1)
+	}
+
+	filename := os.Args[2]
+	labels, err := transfer.ParseLabelSelector(os.Args[5:])
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("正在计算本地文件分块摘要: %s\n", filename)
+	m, err := manifest.Build(context.Background(), filename, manifest.DefaultChunkSize)
+	if err != nil {
+		logger.Error("生成分块清单失败", zap.Error(err))
+		os.Exit(1)
+	}
+
+	req := &models.TransferRequest{
+		Filename:  filepath.Base(filename),
+		Mode:      os.Args[3],
+		Direction: os.Args[4],
+		Type:      models.TaskTypeVerify,
+		Labels:    labels,
+		Manifest:  m,
+	}
+
+	response, err := newAPIClient(cfg).CreateTransfer(context.Background(), req)
+	if err != nil {
+		logger.Error("校验请求失败", zap.Error(err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("校验任务已创建:\n")
+	fmt.Printf("任务ID: %s\n", response.ID)
+	fmt.Printf("分块数: %d\n", len(m.Chunks))
+	fmt.Printf("使用 'client wait %s' 等待校验结果\n", response.ID)
+}
+
 // handleStatusCommand 处理状态查询命令
 func handleStatusCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	if len(os.Args) < 3 {
@@ -120,10 +192,7 @@ func handleStatusCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	taskID := os.Args[2]
 
 	// 查询传输状态
-	client := createHTTPClient(cfg)
-	url := fmt.Sprintf("http://%s:%d/api/v1/transfers/%s", cfg.Server.Host, cfg.Server.Port, taskID)
-
-	status, err := getTransferStatus(client, url)
+	status, err := newAPIClient(cfg).GetTransfer(context.Background(), taskID)
 	if err != nil {
 		logger.Error("查询状态失败", zap.Error(err))
 		os.Exit(1)
@@ -133,7 +202,7 @@ func handleStatusCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	fmt.Printf("任务ID: %s\n", status.ID)
 	fmt.Printf("状态: %s\n", status.Status)
 	fmt.Printf("进度: %.2f%%\n", status.Progress)
-	fmt.Printf("已传输: %d / %d 字节\n", status.BytesTransferred, status.TotalBytes)
+	fmt.Printf("已传输: %s / %s (%d / %d 字节)\n", models.FormatBytes(status.BytesTransferred), models.FormatBytes(status.TotalBytes), status.BytesTransferred, status.TotalBytes)
 	fmt.Printf("传输速率: %.2f MB/s\n", status.TransferRate)
 	fmt.Printf("已用时间: %s\n", status.ElapsedTime)
 	
@@ -151,18 +220,30 @@ func handleListCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	page := 1
 	size := 20
 
-	if len(os.Args) > 2 {
-		fmt.Sscanf(os.Args[2], "%d", &page)
+	// key=value 形式的参数为标签过滤条件，其余依次为页码和每页大小
+	var positional, selectors []string
+	for _, arg := range os.Args[2:] {
+		if strings.Contains(arg, "=") {
+			selectors = append(selectors, arg)
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) > 0 {
+		fmt.Sscanf(positional[0], "%d", &page)
 	}
-	if len(os.Args) > 3 {
-		fmt.Sscanf(os.Args[3], "%d", &size)
+	if len(positional) > 1 {
+		fmt.Sscanf(positional[1], "%d", &size)
 	}
 
-	// 获取任务列表
-	client := createHTTPClient(cfg)
-	url := fmt.Sprintf("http://%s:%d/api/v1/transfers?page=%d&size=%d", cfg.Server.Host, cfg.Server.Port, page, size)
+	labels, err := transfer.ParseLabelSelector(selectors)
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
 
-	taskList, err := getTaskList(client, url)
+	// 获取任务列表
+	taskList, err := newAPIClient(cfg).ListTransfersWithLabels(context.Background(), page, size, labels)
 	if err != nil {
 		logger.Error("获取任务列表失败", zap.Error(err))
 		os.Exit(1)
@@ -176,6 +257,9 @@ func handleListCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 		fmt.Printf("   文件名: %s\n", task.Filename)
 		fmt.Printf("   模式: %s, 方向: %s\n", task.Mode, task.Direction)
 		fmt.Printf("   状态: %s, 进度: %.2f%%\n", task.Status, task.Progress)
+		if len(task.Labels) > 0 {
+			fmt.Printf("   标签: %v\n", task.Labels)
+		}
 		fmt.Printf("   创建时间: %s\n", task.CreatedAt.Format("2006-01-02 15:04:05"))
 		fmt.Println("   ---")
 	}
@@ -191,10 +275,7 @@ func handleCancelCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	taskID := os.Args[2]
 
 	// 取消传输任务
-	client := createHTTPClient(cfg)
-	url := fmt.Sprintf("http://%s:%d/api/v1/transfers/%s", cfg.Server.Host, cfg.Server.Port, taskID)
-
-	response, err := cancelTransfer(client, url)
+	response, err := newAPIClient(cfg).CancelTransfer(context.Background(), taskID)
 	if err != nil {
 		logger.Error("取消任务失败", zap.Error(err))
 		os.Exit(1)
@@ -206,155 +287,101 @@ func handleCancelCommand(cfg *models.ClientConfig, logger *zap.Logger) {
 	fmt.Printf("消息: %s\n", response.Message)
 }
 
-// handleHealthCommand 处理健康检查命令
-func handleHealthCommand(cfg *models.ClientConfig, logger *zap.Logger) {
-	// 检查服务健康状态
-	client := createHTTPClient(cfg)
-	url := fmt.Sprintf("http://%s:%d/api/health", cfg.Server.Host, cfg.Server.Port)
-
-	health, err := checkHealth(client, url)
-	if err != nil {
-		logger.Error("健康检查失败", zap.Error(err))
+// handleResumeCommand 处理续传命令
+func handleResumeCommand(cfg *models.ClientConfig, logger *zap.Logger) {
+	if len(os.Args) < 3 {
+		fmt.Println("用法: client resume <task_id>")
 		os.Exit(1)
 	}
 
-	fmt.Printf("服务健康状态:\n")
-	fmt.Printf("状态: %s\n", health.Status)
-	fmt.Printf("版本: %s\n", health.Version)
-	fmt.Printf("时间: %s\n", health.Timestamp)
-}
-
-// createHTTPClient 创建 HTTP 客户端
-func createHTTPClient(cfg *models.ClientConfig) *http.Client {
-	return &http.Client{
-		Timeout: cfg.Server.Timeout,
-	}
-}
-
-// sendTransferRequest 发送传输请求
-func sendTransferRequest(client *http.Client, url string, req *models.TransferRequest) (*models.TransferResponse, error) {
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
+	taskID := os.Args[2]
 
-	resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	// 从检查点续传失败的任务
+	response, err := newAPIClient(cfg).ResumeTransfer(context.Background(), taskID, nil)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		var errorResp models.ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return nil, fmt.Errorf("请求失败: %s", resp.Status)
-		}
-		return nil, fmt.Errorf("%s: %s", errorResp.Error, errorResp.Message)
-	}
-
-	var response models.TransferResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
+		logger.Error("续传任务失败", zap.Error(err))
+		os.Exit(1)
 	}
 
-	return &response, nil
+	fmt.Printf("任务续传已开始:\n")
+	fmt.Printf("任务ID: %s\n", response.ID)
+	fmt.Printf("状态: %s\n", response.Status)
+	fmt.Printf("续传偏移: %d\n", response.ResumeOffset)
+	fmt.Printf("消息: %s\n", response.Message)
 }
 
-// getTransferStatus 获取传输状态
-func getTransferStatus(client *http.Client, url string) (*models.ProgressResponse, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResp models.ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return nil, fmt.Errorf("请求失败: %s", resp.Status)
-		}
-		return nil, fmt.Errorf("%s: %s", errorResp.Error, errorResp.Message)
+// handleWaitCommand 等待传输任务结束并输出进度
+func handleWaitCommand(cfg *models.ClientConfig, logger *zap.Logger) {
+	if len(os.Args) < 3 {
+		fmt.Println("用法: client wait <task_id>")
+		os.Exit(1)
 	}
 
-	var status models.ProgressResponse
-	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-		return nil, err
-	}
+	taskID := os.Args[2]
+	apiClient := newAPIClient(cfg)
 
-	return &status, nil
-}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-// getTaskList 获取任务列表
-func getTaskList(client *http.Client, url string) (*models.TaskListResponse, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
+	// 按进度更新间隔查询，服务端建议的间隔更长时按服务端建议
+	interval := cfg.Monitoring.ProgressUpdateInterval
+	if interval <= 0 {
+		interval = time.Second
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResp models.ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return nil, fmt.Errorf("请求失败: %s", resp.Status)
+	for update := range apiClient.StreamProgress(ctx, taskID, interval) {
+		if update.Err != nil {
+			logger.Error("查询进度失败", zap.Error(update.Err))
+			os.Exit(1)
+		}
+		progress := update.Progress
+		fmt.Printf("[%s] %s %.2f%% (%d / %d 字节, %.2f MB/s)\n",
+			time.Now().Format("15:04:05"), progress.Status, progress.Progress,
+			progress.BytesTransferred, progress.TotalBytes, progress.TransferRate)
+
+		if progress.Status == client.StatusFailed {
+			fmt.Printf("错误: %s\n", progress.Error)
+			os.Exit(1)
 		}
-		return nil, fmt.Errorf("%s: %s", errorResp.Error, errorResp.Message)
-	}
-
-	var taskList models.TaskListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&taskList); err != nil {
-		return nil, err
 	}
-
-	return &taskList, nil
 }
 
-// cancelTransfer 取消传输任务
-func cancelTransfer(client *http.Client, url string) (*models.TransferResponse, error) {
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := client.Do(req)
+// handleHealthCommand 处理健康检查命令
+func handleHealthCommand(cfg *models.ClientConfig, logger *zap.Logger) {
+	// 检查服务健康状态
+	health, err := newAPIClient(cfg).Health(context.Background())
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResp models.ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return nil, fmt.Errorf("请求失败: %s", resp.Status)
-		}
-		return nil, fmt.Errorf("%s: %s", errorResp.Error, errorResp.Message)
-	}
-
-	var response models.TransferResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
+		logger.Error("健康检查失败", zap.Error(err))
+		os.Exit(1)
 	}
 
-	return &response, nil
+	fmt.Printf("服务健康状态:\n")
+	fmt.Printf("状态: %s\n", health.Status)
+	fmt.Printf("版本: %s\n", health.Version)
+	fmt.Printf("时间: %s\n", health.Timestamp)
 }
 
-// checkHealth 检查健康状态
-func checkHealth(client *http.Client, url string) (*models.HealthResponse, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
+// newAPIClient 根据配置创建 API 客户端
+func newAPIClient(cfg *models.ClientConfig) *client.Client {
+	options := []client.Option{
+		client.WithTimeout(cfg.Server.Timeout),
+		client.WithWaitTimeout(cfg.Server.WaitTimeout),
+		client.WithRetry(cfg.Server.RetryAttempts, cfg.Server.RetryDelay),
+		client.WithUserAgent("rdma-burst-client/" + version),
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("服务不可用: %s", resp.Status)
+	if auth := cfg.Security.Auth; auth.Enabled {
+		if auth.Token != "" {
+			options = append(options, client.WithToken(auth.Token))
+		} else if auth.Username != "" {
+			options = append(options, client.WithBasicAuth(auth.Username, auth.Password))
+		}
 	}
 
-	var health models.HealthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
-		return nil, err
+	if signing := cfg.Security.Signing; signing.Enabled {
+		options = append(options, client.WithSigning(signing.Secret))
 	}
 
-	return &health, nil
+	return client.NewFromHostPort(cfg.Server.Host, cfg.Server.Port, options...)
 }
 
 // getConfigPath 获取配置文件路径
@@ -365,6 +392,26 @@ func getConfigPath() string {
 	return "./configs/client.yaml"
 }
 
+// newLoggerConfig 将日志设置转换为日志器配置
+func newLoggerConfig(settings models.LoggingSettings) logger.Config {
+	return logger.Config{
+		Level:      settings.Level,
+		FilePath:   settings.FilePath,
+		MaxSize:    settings.MaxSize,
+		MaxBackups: settings.MaxBackups,
+		MaxAge:     settings.MaxAge,
+		Format:     settings.Format,
+		Components: settings.Components,
+		Sink: logger.SinkConfig{
+			Type:    settings.Sink.Type,
+			Network: settings.Sink.Network,
+			Address: settings.Sink.Address,
+			Tag:     settings.Sink.Tag,
+		},
+		RedactKeys: settings.RedactKeys,
+	}
+}
+
 // printUsage 打印使用说明
 func printUsage() {
 	fmt.Println("RDMA 文件传输客户端")
@@ -373,21 +420,32 @@ func printUsage() {
 	fmt.Println("用法: client <command> [arguments]")
 	fmt.Println()
 	fmt.Println("命令:")
-	fmt.Println("  transfer <filename> <mode> <direction> [server_ip]")
-	fmt.Println("      创建新的传输任务")
+	fmt.Println("  transfer <filename> <mode> <direction> [server_ip] [key=value ...]")
+	fmt.Println("      创建新的传输任务，key=value 参数为任务标签")
+	fmt.Println("  verify <filename> <mode> <direction> [key=value ...]")
+	fmt.Println("      不传输数据，比较本地文件与服务端文件的分块摘要")
 	fmt.Println("  status <task_id>")
 	fmt.Println("      查询传输任务状态")
-	fmt.Println("  list [page] [size]")
-	fmt.Println("      列出传输任务")
+	fmt.Println("  list [page] [size] [key=value ...]")
+	fmt.Println("      列出传输任务，key=value 参数按标签过滤")
 	fmt.Println("  cancel <task_id>")
 	fmt.Println("      取消传输任务")
+	fmt.Println("  resume <task_id>")
+	fmt.Println("      从检查点续传失败的传输任务")
+	fmt.Println("  wait <task_id>")
+	fmt.Println("      等待传输任务结束并输出进度")
 	fmt.Println("  health")
 	fmt.Println("      检查服务健康状态")
 	fmt.Println()
 	fmt.Println("示例:")
 	fmt.Println("  client transfer data.txt filesystem put 192.168.1.100")
 	fmt.Println("  client status task_1234567890")
+	fmt.Println("  client transfer data.txt filesystem put run_id=42 experiment=baseline")
+	fmt.Println("  client verify data.txt filesystem put run_id=42")
 	fmt.Println("  client list 1 10")
+	fmt.Println("  client list run_id=42")
 	fmt.Println("  client cancel task_1234567890")
+	fmt.Println("  client resume task_1234567890")
+	fmt.Println("  client wait task_1234567890")
 	fmt.Println("  client health")
 }
\ No newline at end of file
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rdma-burst/internal/e2e"
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/client"
+)
+
+// benchLabel 压测任务的标签名，值为本次压测的编号，用于查询和清理压测任务
+const benchLabel = "bench_run"
+
+// benchPageSize 查询压测任务时每页的任务数（列表接口上限）
+const benchPageSize = 100
+
+// benchOptions 压测参数
+type benchOptions struct {
+	server      string
+	token       string
+	count       int
+	size        int64
+	mode        string
+	concurrency int
+	dir         string
+	simulate    bool
+	rate        int64
+	wait        bool
+	timeout     time.Duration
+}
+
+// benchSubmission 单个任务的提交结果
+type benchSubmission struct {
+	taskID  string
+	latency time.Duration // 创建请求的响应时间（调度延迟）
+	err     error
+}
+
+// runBench 执行 bench 子命令
+func runBench(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "submit":
+			return runBenchSubmit(args[1:])
+		case "soak":
+			return runSoak(args[1:])
+		}
+	}
+	printBenchUsage()
+	return fmt.Errorf("未知的 bench 子命令")
+}
+
+// printBenchUsage 输出 bench 子命令用法
+func printBenchUsage() {
+	fmt.Println("用法: rdma-burst bench <submit|soak> [flags]")
+	fmt.Println()
+	fmt.Println("  submit  生成稀疏文件并批量提交 put 任务，统计调度延迟和 API 错误率")
+	fmt.Println("  soak    长时间循环传输并采样服务的 Goroutine、文件描述符和内存，发现持续增长时以非零状态退出")
+	fmt.Println()
+	fmt.Println("示例:")
+	fmt.Println("  rdma-burst bench submit --count 100 --size 10G --mode tmpfs")
+	fmt.Println("  rdma-burst bench submit --count 20 --size 64M --simulate --rate 256M --wait")
+	fmt.Println("  rdma-burst bench soak --duration 8h --interval 1m --server http://127.0.0.1:8081")
+}
+
+// runBenchSubmit 执行 bench submit
+func runBenchSubmit(args []string) error {
+	fs := flag.NewFlagSet("bench submit", flag.ContinueOnError)
+	opts := benchOptions{}
+	var sizeText, rateText string
+	fs.StringVar(&opts.server, "server", "http://127.0.0.1:8080", "API 地址（--simulate 时忽略）")
+	fs.StringVar(&opts.token, "token", "", "API Bearer 令牌")
+	fs.IntVar(&opts.count, "count", 100, "提交的任务数")
+	fs.StringVar(&sizeText, "size", "1G", "每个文件的大小，例如 512M、10G")
+	fs.StringVar(&opts.mode, "mode", models.ModeTmpfs, "传输模式: hugepages, tmpfs, filesystem, auto")
+	fs.IntVar(&opts.concurrency, "concurrency", 10, "同时提交的请求数")
+	fs.StringVar(&opts.dir, "dir", filepath.Join(os.TempDir(), "rdma-burst-bench"), "生成稀疏文件的目录")
+	fs.BoolVar(&opts.simulate, "simulate", false, "在进程内启动模拟后端，不需要服务端和 RDMA 设备")
+	fs.StringVar(&rateText, "rate", "1G", "模拟后端每秒复制的字节数（仅 --simulate）")
+	fs.BoolVar(&opts.wait, "wait", false, "等待任务结束并统计开始延迟和传输耗时")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Minute, "等待任务结束的超时时间（仅 --wait）")
+	fs.Usage = func() {
+		printBenchUsage()
+		fmt.Println()
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	size, err := parseByteSize(sizeText)
+	if err != nil {
+		return fmt.Errorf("无效的文件大小: %v", err)
+	}
+	opts.size = size
+	if opts.rate, err = parseByteSize(rateText); err != nil {
+		return fmt.Errorf("无效的模拟速率: %v", err)
+	}
+	if opts.count < 1 || opts.concurrency < 1 {
+		return fmt.Errorf("count 和 concurrency 必须大于 0")
+	}
+
+	runID := strconv.FormatInt(time.Now().Unix(), 10)
+	files, err := createSparseFiles(opts.dir, runID, opts.count, opts.size)
+	if err != nil {
+		return err
+	}
+	defer removeFiles(files)
+
+	api, cleanup, err := benchClient(&opts)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	fmt.Printf("压测编号: %s（任务标签 %s=%s）\n", runID, benchLabel, runID)
+	fmt.Printf("提交 %d 个 %s 任务，每个文件 %s，并发 %d\n", opts.count, opts.mode, sizeText, opts.concurrency)
+
+	start := time.Now()
+	submissions := submitBench(api, &opts, runID, files)
+	elapsed := time.Since(start)
+	printSubmissionReport(submissions, elapsed)
+
+	if opts.wait {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+		defer cancel()
+		tasks, err := waitBench(ctx, api, runID, submissions)
+		if err != nil {
+			return err
+		}
+		printTaskReport(tasks)
+	}
+	return nil
+}
+
+// benchClient 创建压测使用的 API 客户端，--simulate 时在进程内启动模拟后端
+func benchClient(opts *benchOptions) (*client.Client, func(), error) {
+	var clientOpts []client.Option
+	if opts.token != "" {
+		clientOpts = append(clientOpts, client.WithToken(opts.token))
+	}
+
+	if !opts.simulate {
+		return client.New(opts.server, clientOpts...), func() {}, nil
+	}
+
+	serverDir := filepath.Join(opts.dir, "server")
+	if err := os.MkdirAll(serverDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("创建模拟后端目录失败: %v", err)
+	}
+	server, backend := e2e.NewFakeServer(serverDir, opts.rate)
+	fmt.Printf("模拟后端: %s（每秒复制 %d 字节）\n", server.URL, opts.rate)
+	cleanup := func() {
+		backend.Close()
+		server.Close()
+		os.RemoveAll(serverDir)
+	}
+	return server.Client(clientOpts...), cleanup, nil
+}
+
+// createSparseFiles 生成指定大小的稀疏文件，不占用实际磁盘空间
+func createSparseFiles(dir, runID string, count int, size int64) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建压测目录失败: %v", err)
+	}
+
+	files := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("bench_%s_%04d.dat", runID, i))
+		file, err := os.Create(path)
+		if err == nil {
+			err = file.Truncate(size)
+			file.Close()
+		}
+		if err != nil {
+			removeFiles(files)
+			return nil, fmt.Errorf("生成稀疏文件失败: %v", err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// removeFiles 删除生成的文件
+func removeFiles(files []string) {
+	for _, file := range files {
+		os.Remove(file)
+	}
+}
+
+// submitBench 按并发数提交任务，返回每个任务的提交结果
+func submitBench(api *client.Client, opts *benchOptions, runID string, files []string) []benchSubmission {
+	submissions := make([]benchSubmission, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < opts.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				req := &client.TransferRequest{
+					Filename:  files[i],
+					Mode:      opts.mode,
+					Direction: models.DirectionPut,
+					Size:      opts.size,
+					Labels:    map[string]string{benchLabel: runID},
+				}
+				start := time.Now()
+				resp, err := api.CreateTransfer(context.Background(), req)
+				submissions[i] = benchSubmission{latency: time.Since(start), err: err}
+				if err == nil {
+					submissions[i].taskID = resp.ID
+				}
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return submissions
+}
+
+// waitBench 轮询压测任务直到全部结束
+func waitBench(ctx context.Context, api *client.Client, runID string, submissions []benchSubmission) ([]*models.TransferTask, error) {
+	submitted := make(map[string]bool)
+	for _, s := range submissions {
+		if s.err == nil {
+			submitted[s.taskID] = true
+		}
+	}
+	if len(submitted) == 0 {
+		return nil, nil
+	}
+
+	fmt.Printf("等待 %d 个任务结束...\n", len(submitted))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		tasks, err := listBenchTasks(ctx, api, runID)
+		if err != nil {
+			return nil, err
+		}
+
+		finished := 0
+		for _, task := range tasks {
+			if submitted[task.ID] && task.IsFinished() {
+				finished++
+			}
+		}
+		if finished == len(submitted) {
+			return tasks, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待任务结束超时，已结束 %d/%d: %v", finished, len(submitted), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// listBenchTasks 分页查询带有压测标签的任务
+func listBenchTasks(ctx context.Context, api *client.Client, runID string) ([]*models.TransferTask, error) {
+	labels := map[string]string{benchLabel: runID}
+	var tasks []*models.TransferTask
+	for page := 1; ; page++ {
+		list, err := api.ListTransfersWithLabels(ctx, page, benchPageSize, labels)
+		if err != nil {
+			return nil, fmt.Errorf("查询压测任务失败: %v", err)
+		}
+		tasks = append(tasks, list.Tasks...)
+		if len(list.Tasks) < benchPageSize || len(tasks) >= list.Total {
+			return tasks, nil
+		}
+	}
+}
+
+// printSubmissionReport 输出提交延迟和错误率
+func printSubmissionReport(submissions []benchSubmission, elapsed time.Duration) {
+	var latencies []time.Duration
+	errorCodes := make(map[string]int)
+	for _, s := range submissions {
+		latencies = append(latencies, s.latency)
+		if s.err != nil {
+			errorCodes[benchErrorCode(s.err)]++
+		}
+	}
+
+	failed := 0
+	for _, n := range errorCodes {
+		failed += n
+	}
+
+	fmt.Println()
+	fmt.Println("提交结果")
+	fmt.Println("==================================================================")
+	fmt.Printf("总耗时: %s，吞吐: %.1f 请求/秒\n", elapsed.Round(time.Millisecond), float64(len(submissions))/elapsed.Seconds())
+	fmt.Printf("成功: %d，失败: %d，错误率: %.2f%%\n", len(submissions)-failed, failed, float64(failed)*100/float64(len(submissions)))
+	printLatencies("调度延迟", latencies)
+
+	codes := make([]string, 0, len(errorCodes))
+	for code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Printf("  %s: %d\n", code, errorCodes[code])
+	}
+}
+
+// printTaskReport 输出任务开始延迟、传输耗时和结束状态
+func printTaskReport(tasks []*models.TransferTask) {
+	var startLatencies, durations []time.Duration
+	statuses := make(map[string]int)
+	for _, task := range tasks {
+		statuses[task.Status]++
+		if !task.StartTime.IsZero() {
+			startLatencies = append(startLatencies, task.StartTime.Sub(task.CreatedAt))
+			if task.EndTime != nil {
+				durations = append(durations, task.EndTime.Sub(task.StartTime))
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("任务结果")
+	fmt.Println("==================================================================")
+	for _, status := range []string{models.StatusCompleted, models.StatusFailed, models.StatusCancelled} {
+		fmt.Printf("%s: %d\n", status, statuses[status])
+	}
+	printLatencies("开始延迟", startLatencies)
+	printLatencies("传输耗时", durations)
+}
+
+// printLatencies 输出耗时的分位数
+func printLatencies(name string, values []time.Duration) {
+	if len(values) == 0 {
+		fmt.Printf("%s: 无数据\n", name)
+		return
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	percentile := func(p float64) time.Duration {
+		return values[int(p*float64(len(values)-1))].Round(time.Microsecond)
+	}
+	fmt.Printf("%s: p50 %s, p90 %s, p99 %s, 最大 %s\n",
+		name, percentile(0.5), percentile(0.9), percentile(0.99), values[len(values)-1].Round(time.Microsecond))
+}
+
+// benchErrorCode 获取错误的 API 错误码，非 API 错误（网络错误等）归为 NETWORK_ERROR
+func benchErrorCode(err error) string {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Code != "" {
+			return apiErr.Code
+		}
+		return strconv.Itoa(apiErr.StatusCode)
+	}
+	return "NETWORK_ERROR"
+}
+
+// parseByteSize 解析字节数，支持 K、M、G、T 后缀（1024 进制），例如 512M、10G
+func parseByteSize(text string) (int64, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "B"), "I")
+
+	multiplier := int64(1)
+	if n := len(text); n > 0 {
+		switch text[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			text = text[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%q", text)
+	}
+	return int64(value * float64(multiplier)), nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"rdma-burst/internal/wrapper"
+)
+
+// runLogs 执行 logs 子命令
+func runLogs(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "record":
+			return runLogsRecord(args[1:])
+		case "replay":
+			return runLogsReplay(args[1:])
+		}
+	}
+	printLogsUsage()
+	return fmt.Errorf("未知的 logs 子命令")
+}
+
+// printLogsUsage 输出 logs 子命令用法
+func printLogsUsage() {
+	fmt.Println("用法: rdma-burst logs <record|replay> [flags]")
+	fmt.Println()
+	fmt.Println("  record  跟踪正在写入的 rtranfile 日志，按时间录制为 JSON Lines 文件")
+	fmt.Println("  replay  将录制文件（或普通 rtranfile 日志）按原始节奏回放给 LogParser 和 TransferMonitor")
+	fmt.Println()
+	fmt.Println("示例:")
+	fmt.Println("  rdma-burst logs record --log /var/log/rtrans/rtranfile_client.log --out transfer.jsonl")
+	fmt.Println("  rdma-burst logs replay --speed 10 --verbose transfer.jsonl")
+}
+
+// runLogsRecord 执行 logs record：从日志当前末尾开始录制，直到收到中断信号或达到 --duration
+func runLogsRecord(args []string) error {
+	fs := flag.NewFlagSet("logs record", flag.ContinueOnError)
+	logFile := fs.String("log", "", "rtranfile 日志文件")
+	out := fs.String("out", "", "录制文件，为空时使用 <日志文件名>_<时间>.jsonl")
+	duration := fs.Duration("duration", 0, "录制时长，0 表示直到 Ctrl-C")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logFile == "" {
+		fs.Usage()
+		return fmt.Errorf("需要指定 --log")
+	}
+	if *out == "" {
+		*out = fmt.Sprintf("%s_%s.jsonl", filepath.Base(*logFile), time.Now().Format("20060102T150405"))
+	}
+
+	recorder, err := wrapper.NewLogRecorder(*out)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	monitor := wrapper.NewTransferMonitor(*logFile)
+	monitor.AddLineFilter(recorder.Record)
+	if err := monitor.StartMonitoring(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("正在录制 %s -> %s，按 Ctrl-C 结束\n", *logFile, recorder.Path())
+
+	<-ctx.Done()
+	monitor.StopMonitoring()
+	progress := monitor.GetProgress()
+	fmt.Printf("录制结束，最后状态: %s，%d/%d 字节 (%.1f%%)\n",
+		progress.Status, progress.BytesTransferred, progress.TotalBytes, progress.ProgressPercent)
+	return nil
+}
+
+// runLogsReplay 执行 logs replay：逐行输出 LogParser 的解析结果，并按节奏回放给 TransferMonitor 比较最终进度
+func runLogsReplay(args []string) error {
+	fs := flag.NewFlagSet("logs replay", flag.ContinueOnError)
+	speed := fs.Float64("speed", 1, "回放倍速，0 表示不等待")
+	verbose := fs.Bool("verbose", false, "输出每一行的解析结果")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("需要指定一个录制文件")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("打开录制文件失败: %v", err)
+	}
+	lines, err := wrapper.ReadRecording(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("录制文件为空")
+	}
+
+	// 逐行解析，统计各类日志行
+	parser := wrapper.NewLogParser()
+	counts := make(map[wrapper.TransferStatus]int)
+	unmatched, parseErrors := 0, 0
+	for _, line := range lines {
+		info, err := parser.ParseLine(line.Line)
+		switch {
+		case err != nil:
+			parseErrors++
+		case info == nil:
+			unmatched++
+		default:
+			counts[info.Status]++
+		}
+		if *verbose {
+			fmt.Printf("+%-10s %s | %s\n", line.Offset(), describeParse(info, err), line.Line)
+		}
+	}
+
+	recorded := lines[len(lines)-1].Offset()
+	fmt.Printf("共 %d 行，录制时长 %s\n", len(lines), recorded)
+	fmt.Printf("LogParser: 进度 %d，完成 %d，错误 %d，未识别 %d，解析失败 %d\n",
+		counts[wrapper.StatusInProgress], counts[wrapper.StatusCompleted], counts[wrapper.StatusFailed], unmatched, parseErrors)
+
+	dir, err := os.MkdirTemp("", "rdma-burst-replay")
+	if err != nil {
+		return fmt.Errorf("创建回放目录失败: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if *speed > 0 {
+		fmt.Printf("以 %gx 速度回放给 TransferMonitor，预计 %s\n", *speed, time.Duration(float64(recorded) / *speed).Round(time.Millisecond))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	progress, err := wrapper.ReplayLog(ctx, lines, *speed, filepath.Join(dir, "rtranfile.log"))
+	if err != nil {
+		return fmt.Errorf("回放失败: %v", err)
+	}
+	fmt.Printf("TransferMonitor: 状态 %s，%d/%d 字节 (%.1f%%)\n",
+		progress.Status, progress.BytesTransferred, progress.TotalBytes, progress.ProgressPercent)
+	if progress.Error != "" {
+		fmt.Printf("错误: %s\n", progress.Error)
+	}
+	return nil
+}
+
+// describeParse 描述一行日志的解析结果
+func describeParse(info *wrapper.ProgressInfo, err error) string {
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%-12s", "parse_error")
+	case info == nil:
+		return fmt.Sprintf("%-12s", "-")
+	case info.Status == wrapper.StatusInProgress:
+		return fmt.Sprintf("%-12s", fmt.Sprintf("%.1f%%", info.ProgressPercent))
+	default:
+		return fmt.Sprintf("%-12s", info.Status)
+	}
+}
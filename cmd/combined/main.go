@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,10 +23,30 @@ import (
 	"rdma-burst/internal/api/handlers"
 	"rdma-burst/internal/api/middleware"
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/alert"
+	"rdma-burst/internal/services/accounting"
+	"rdma-burst/internal/services/audit"
+	"rdma-burst/internal/services/binaries"
+	"rdma-burst/internal/services/auth"
 	"rdma-burst/internal/services/config"
+	"rdma-burst/internal/services/detect"
+	"rdma-burst/internal/services/fault"
+	"rdma-burst/internal/services/handoff"
+	"rdma-burst/internal/services/hugepool"
+	"rdma-burst/internal/services/journal"
+	"rdma-burst/internal/services/leader"
+	"rdma-burst/internal/services/link"
+	"rdma-burst/internal/services/notify"
+	"rdma-burst/internal/services/readiness"
+	"rdma-burst/internal/services/scrub"
 	"rdma-burst/internal/services/transfer"
+	"rdma-burst/internal/services/tuning"
+	"rdma-burst/internal/utils"
 	"rdma-burst/internal/wrapper"
 	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/client"
+	"rdma-burst/pkg/metrics"
+	"rdma-burst/pkg/tracing"
 )
 
 // 构建信息
@@ -40,12 +65,32 @@ const (
 
 // 应用配置
 type AppConfig struct {
-	Mode         string        `mapstructure:"mode"` // server, client, auto
-	ServerConfig *models.ServerConfig
-	ClientConfig *models.ClientConfig
+	Mode           string        `mapstructure:"mode"` // server, client, auto
+	ServerConfig   *models.ServerConfig
+	ClientConfig   *models.ClientConfig
+	CombinedConfig *models.CombinedConfig // 统一配置（模式检测、互斥启动等）
+	ConfigFile     string                 // 加载的配置文件，切换模式时写入运行模式
 }
 
 func main() {
+	// 压测和日志工具子命令不启动服务
+	if len(os.Args) > 1 {
+		var run func([]string) error
+		switch os.Args[1] {
+		case "bench":
+			run = runBench
+		case "logs":
+			run = runLogs
+		}
+		if run != nil {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// 解析命令行参数
 	var configPath string
 	var mode string
@@ -61,31 +106,86 @@ func main() {
 		return
 	}
 
-	// 初始化日志
-	logger, err := logger.NewLogger()
+	// 初始化启动日志（确定运行模式后按角色使用对应的日志设置）
+	bootLogger, err := logger.NewLogger()
 	if err != nil {
 		log.Fatalf("初始化日志失败: %v", err)
 	}
-	defer logger.Sync()
+	defer bootLogger.Sync()
 
 	// 加载配置
 	appConfig, err := loadConfig(configPath, mode)
 	if err != nil {
-		logger.Fatal("加载配置失败", zap.Error(err))
+		bootLogger.Fatal("加载配置失败", zap.Error(err))
 	}
 
 	// 确定运行模式
-	runtimeMode := determineRuntimeMode(appConfig.Mode, logger)
-	logger.Info("确定运行模式", zap.String("mode", runtimeMode))
+	detector := detect.NewDetector(appConfig.CombinedConfig)
+	detection := determineRuntimeMode(appConfig.Mode, detector, bootLogger)
 
-	// 根据模式启动应用
-	switch runtimeMode {
-	case ModeServer:
-		startServer(appConfig.ServerConfig, logger)
-	case ModeClient:
-		startClient(appConfig.ClientConfig, logger)
-	default:
-		logger.Fatal("未知的运行模式", zap.String("mode", runtimeMode))
+	// 按运行模式初始化日志
+	loggingSettings := appConfig.CombinedConfig.Logging.Server
+	if detection.Mode == ModeClient {
+		loggingSettings = appConfig.CombinedConfig.Logging.Client
+	}
+	if err := logger.Init(newLoggerConfig(loggingSettings)); err != nil {
+		bootLogger.Warn("初始化文件日志失败，使用默认日志", zap.Error(err))
+	}
+	appLogger := logger.GetLogger()
+	defer appLogger.Sync()
+
+	// 初始化追踪
+	shutdownTracing, err := tracing.Init(newTracingConfig(appConfig.CombinedConfig.Tracing, "rdma-burst-"+detection.Mode))
+	if err != nil {
+		appLogger.Warn("初始化追踪失败", zap.Error(err))
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
+	appLogger.Info("确定运行模式",
+		zap.String("mode", detection.Mode),
+		zap.String("source", detection.Source),
+		zap.Strings("reasons", detection.Reasons),
+	)
+
+	// 根据模式启动应用，角色收到切换请求时排空并关闭服务后返回该请求，按目标角色重新初始化
+	// 日志和追踪沿用启动时角色的设置
+	coordinator := handoff.New(detection.Mode)
+	if req, ok := restoredRequest(); ok {
+		coordinator.Restore(req)
+	}
+	for {
+		var next *handoff.Request
+		switch detection.Mode {
+		case ModeServer:
+			next = startServer(appConfig, detector, detection, coordinator, appLogger)
+		case ModeClient:
+			next = startClient(appConfig, detector, detection, coordinator, appLogger)
+		default:
+			appLogger.Fatal("未知的运行模式", zap.String("mode", detection.Mode))
+		}
+		if next == nil {
+			return
+		}
+
+		if next.Restart {
+			// 重新执行进程，成功时不会返回
+			if err := restartProcess(*next, appLogger); err != nil {
+				coordinator.Failed(next.From, err)
+				next = &handoff.Request{From: next.From, Target: next.From, Reason: err.Error()}
+			}
+		} else if err := loadRoleConfig(appConfig, next.Target); err != nil {
+			coordinator.Failed(next.From, err)
+			next = &handoff.Request{From: next.Target, Target: next.From, Reason: err.Error()}
+		}
+		coordinator.Starting(next.Target)
+		detection = detect.NewFixedResult(next.Target, detect.SourceHandoff, next.Reason)
+		detection.Peer = next.Peer
+		appLogger.Info("切换运行角色",
+			zap.String("from", next.From),
+			zap.String("mode", next.Target),
+			zap.String("reason", next.Reason),
+		)
 	}
 }
 
@@ -103,7 +203,8 @@ func loadConfig(configPath string, mode string) (*AppConfig, error) {
 
 	// 根据模式加载配置
 	appConfig := &AppConfig{
-		Mode: mode,
+		Mode:       mode,
+		ConfigFile: configPath,
 	}
 
 	switch mode {
@@ -136,59 +237,154 @@ func loadConfig(configPath string, mode string) (*AppConfig, error) {
 			appConfig.ServerConfig = serverConfig.(*models.ServerConfig)
 		}
 		
-		// 客户端配置使用默认值
-		appConfig.ClientConfig = models.GetDefaultClientConfig()
+		// 客户端配置同样尝试从配置文件加载，失败时使用默认值
+		clientConfigManager := config.NewConfigManager("client")
+		clientConfig, err := clientConfigManager.LoadConfig(configPath)
+		if err != nil {
+			appConfig.ClientConfig = models.GetDefaultClientConfig()
+		} else {
+			appConfig.ClientConfig = clientConfig.(*models.ClientConfig)
+		}
+	}
+
+	// 统一配置用于模式检测和互斥启动，加载失败时使用默认值
+	combinedConfigManager := config.NewConfigManager("combined")
+	combinedConfig, err := combinedConfigManager.LoadConfig(configPath)
+	if err != nil {
+		appConfig.CombinedConfig = models.GetDefaultCombinedConfig()
+	} else {
+		appConfig.CombinedConfig = combinedConfig.(*models.CombinedConfig)
 	}
 
 	return appConfig, nil
 }
 
+// loadRoleConfig 运行时切换到启动时未加载配置的角色（命令行参数指定了模式）时加载该角色的配置
+func loadRoleConfig(app *AppConfig, mode string) error {
+	switch {
+	case mode == ModeServer && app.ServerConfig == nil:
+		serverConfig, err := config.NewConfigManager("server").LoadConfig(app.ConfigFile)
+		if err != nil {
+			return fmt.Errorf("加载服务端配置失败: %v", err)
+		}
+		app.ServerConfig = serverConfig.(*models.ServerConfig)
+	case mode == ModeClient && app.ClientConfig == nil:
+		clientConfig, err := config.NewConfigManager("client").LoadConfig(app.ConfigFile)
+		if err != nil {
+			return fmt.Errorf("加载客户端配置失败: %v", err)
+		}
+		app.ClientConfig = clientConfig.(*models.ClientConfig)
+	}
+	return nil
+}
+
 // determineRuntimeMode 确定运行模式
-func determineRuntimeMode(configMode string, logger *zap.Logger) string {
+func determineRuntimeMode(configMode string, detector *detect.Detector, logger *zap.Logger) *detect.Result {
 	switch configMode {
 	case ModeServer:
-		return ModeServer
+		return detect.NewFixedResult(ModeServer, detect.SourceFlag, "命令行参数指定服务端模式")
 	case ModeClient:
-		return ModeClient
+		return detect.NewFixedResult(ModeClient, detect.SourceFlag, "命令行参数指定客户端模式")
 	case ModeAuto:
-		// 自动检测模式：尝试连接服务端，如果成功则为客户端，否则为服务端
-		return autoDetectMode(logger)
+		// 自动检测模式：综合配置、实例锁、设备角色和对端地址判定
+		return detector.Detect()
 	default:
 		logger.Warn("未知的配置模式，使用自动检测", zap.String("mode", configMode))
-		return autoDetectMode(logger)
+		return detector.Detect()
 	}
 }
 
-// autoDetectMode 自动检测运行模式
-func autoDetectMode(logger *zap.Logger) string {
-	// 尝试连接本地服务端
-	client := &http.Client{Timeout: 3 * time.Second}
-	url := "http://localhost:8080/api/health"
-
-	resp, err := client.Get(url)
-	if err == nil && resp.StatusCode == http.StatusOK {
-		logger.Info("检测到运行中的服务端，启动客户端模式")
-		return ModeClient
-	}
-
-	logger.Info("未检测到运行中的服务端，启动服务端模式")
-	return ModeServer
-}
+// startServer 启动服务端，收到中断信号时返回 nil，收到角色切换请求时排空并关闭服务后返回该请求
+func startServer(app *AppConfig, detector *detect.Detector, detection *detect.Result, coordinator *handoff.Coordinator, logger *zap.Logger) *handoff.Request {
+	cfg := app.ServerConfig
 
-// startServer 启动服务端
-func startServer(cfg *models.ServerConfig, logger *zap.Logger) {
 	// 检查是否已有服务端在运行
 	if isServerRunning(cfg.Server.Host, cfg.Server.Port) {
-		logger.Fatal("服务端已在运行，无法启动新的服务端实例")
+		return roleStartFailed(detection, coordinator, logger, "服务端已在运行，无法启动新的服务端实例")
+	}
+
+	// 获取实例锁，防止同一主机上启动多个服务端
+	var instanceLock *utils.InstanceLock
+	if mutex := app.CombinedConfig.Mutex; mutex.Enabled && mutex.LockFile != "" {
+		lock, err := utils.AcquireInstanceLock(mutex.LockFile)
+		if err != nil {
+			return roleStartFailed(detection, coordinator, logger, "获取实例锁失败", zap.Error(err))
+		}
+		instanceLock = lock
+		defer instanceLock.Release()
+		logger.Info("已获取实例锁", zap.String("lock_file", mutex.LockFile))
 	}
 
 	// 创建传输服务（使用配置中的传输设置）
 	rtranfilePath := getRtranfilePath()
+
+	// rtranfile 版本管理：传输服务通过指向当前版本的符号链接启动 rtranfile，切换版本后新启动的进程使用新版本
+	var rtranfileManager *binaries.Manager
+	if cfg.Transfer.Rtranfile.Enabled {
+		manager, err := binaries.Open(cfg.Transfer.Rtranfile, rtranfilePath)
+		if err != nil {
+			return roleStartFailed(detection, coordinator, logger, "打开 rtranfile 版本目录失败", zap.Error(err))
+		}
+		rtranfileManager = manager
+		rtranfilePath = manager.Path()
+	}
 	transferService := transfer.NewTransferServiceWithConfig(
 		rtranfilePath,
 		&cfg.Transfer,
 		nil, // 单次传输配置为空，使用默认值
 	)
+	transferService.SetProgressInterval(app.CombinedConfig.Monitoring.Client.ProgressUpdateInterval)
+
+	// 任务状态预写日志：守护进程崩溃重启后恢复进行中的任务
+	if journalFile := cfg.Transfer.JournalFile; journalFile != "" {
+		taskJournal, err := journal.Open(journalFile)
+		if err != nil {
+			logger.Warn("打开任务日志失败，任务状态不会持久化", zap.Error(err))
+		} else {
+			defer taskJournal.Close()
+			transferService.SetJournal(taskJournal)
+			if reattached, err := transferService.Recover(context.Background()); err != nil {
+				logger.Warn("从任务日志恢复任务失败", zap.Error(err))
+			} else {
+				logger.Info("已从任务日志恢复任务",
+					zap.String("journal_file", journalFile),
+					zap.Int("reattached", reattached),
+				)
+			}
+		}
+	}
+
+	// 审计日志：记录管理员的人工干预
+	auditLog, err := audit.Open(cfg.Security.AuditLogFile)
+	if err != nil {
+		logger.Warn("打开审计日志失败，审计记录只写入服务日志", zap.Error(err))
+		auditLog, _ = audit.Open("")
+	}
+	defer auditLog.Close()
+
+	// 用量统计：结束的任务按调用方计入当前统计周期，供计费查询
+	var ledger *accounting.Ledger
+	if cfg.Transfer.Accounting.Enabled {
+		ledger, err = accounting.Open(cfg.Transfer.Accounting)
+		if err != nil {
+			logger.Fatal("打开用量记录失败", zap.Error(err))
+		}
+		defer ledger.Close()
+		transferService.SetAccounting(ledger)
+		ledger.Start(context.Background())
+	}
+
+	// 大页缓冲池：启动时预留大页，hugepages 模式的 put 传输准备时租用，避免传输中途因碎片分配大页失败
+	var hugepagePool *hugepool.Pool
+	if cfg.Transfer.HugepagePool.Enabled {
+		hugepagePool, err = hugepool.NewPool(cfg.Transfer.HugepagePool)
+		if err != nil {
+			return roleStartFailed(detection, coordinator, logger, "创建大页缓冲池失败", zap.Error(err))
+		}
+		hugepagePool.Start(context.Background())
+		defer hugepagePool.Stop()
+		transferService.SetHugepagePool(hugepagePool)
+	}
 
 	// 创建进程映射（按需启动监听进程）
 	serverProcesses := make(map[string]*wrapper.ProcessManager)
@@ -196,6 +392,21 @@ func startServer(cfg *models.ServerConfig, logger *zap.Logger) {
 	logger.Info("服务端启动完成，等待客户端传输请求")
 	logger.Info("rtranfile 监听进程将按需启动")
 
+	if cfg.Transfer.Faults.Enabled {
+		logger.Warn("已启用故障注入，仅用于测试", zap.Any("faults", cfg.Transfer.Faults))
+	}
+
+	// 协调者选主：多个实例共享同一个 Kubernetes Lease，只有领导者调度传输，其余实例只提供只读 API
+	elector, err := leader.New(app.CombinedConfig.LeaderElection)
+	if err != nil {
+		logger.Fatal("创建选主器失败", zap.Error(err))
+	}
+	if elector != nil {
+		transferService.SetLeaderCheck(elector.IsLeader)
+		elector.Start(context.Background())
+		defer elector.Stop()
+	}
+
 	// 设置 Gin 模式
 	if cfg.Server.LogLevel == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -207,21 +418,146 @@ func startServer(cfg *models.ServerConfig, logger *zap.Logger) {
 	router := gin.New()
 
 	// 添加中间件
-	middleware := middleware.NewLoggerMiddleware(logger)
+	router.Use(middleware.Tracing())
+	grants := auth.NewGrantStore()
+	authMiddleware := middleware.Auth(cfg.Security.Auth, grants)
+	keyRateLimitMiddleware := middleware.KeyRateLimit(cfg.Security.Auth)
+	signingMiddleware := middleware.Signature(cfg.Security.Signing, rtranfileManager.MaxSize())
+	allowListMiddleware := middleware.IPAllowList(cfg.Security.IPAllowList)
+	faultMiddleware := middleware.FaultInjection(fault.New(cfg.Transfer.Faults))
+	leaderMiddleware := middleware.LeaderOnly(elector)
+	corsMiddleware := middleware.CORS(cfg.Security.CORS)
+	compressMiddleware := middleware.Compress(cfg.Server.Compression)
+	localizeMiddleware := middleware.Localize(cfg.Server.Language)
+	middleware := middleware.NewLoggerMiddleware(logger.Named("api"))
 	router.Use(middleware.Logger())
 	router.Use(middleware.Recovery())
-	router.Use(CORSMiddleware(cfg.Security.CORS))
+	router.Use(corsMiddleware)
+	router.Use(compressMiddleware)
+	router.Use(localizeMiddleware)
 
 	// 创建 API 处理器
 	transferHandler := handlers.NewTransferHandler(transferService, &cfg.Transfer)
+	transferHandler.SetPollInterval(app.CombinedConfig.Monitoring.Client.ProgressUpdateInterval)
 	healthHandler := handlers.NewHealthHandler(transferService, version)
-	modeHandler := handlers.NewModeHandler(version, ModeServer)
+	modeHandler := handlers.NewModeHandlerWithDetection(version, ModeServer, detector, detection)
+	modeHandler.SetHandoff(coordinator)
+	modeHandler.SetConfigFile(app.ConfigFile)
+	loggingHandler := handlers.NewLoggingHandler()
+	deviceHandler := handlers.NewDeviceHandler()
+
+	// 监控 RDMA 链路状态，链路不可用时健康检查降级并拒绝新的传输
+	linkMonitor := link.NewMonitor([]string{cfg.Transfer.Device}, cfg.Monitoring.LinkCheckInterval)
+	linkMonitor.Start(context.Background())
+	defer linkMonitor.Stop()
+	transferHandler.SetLinkMonitor(linkMonitor)
+	transferHandler.SetGrantStore(grants)
+	transferHandler.SetConcurrencySource(transferService)
+	transferHandler.SetAvailabilitySource(transferService)
+	healthHandler.SetLinkMonitor(linkMonitor)
+
+	// 依赖就绪检查
+	readinessChecker := readiness.NewChecker()
+	readinessChecker.Add("rtranfile", readiness.BinaryCheck(rtranfilePath))
+	readinessChecker.Add("log_dir", readiness.LogDirCheck(app.CombinedConfig.Logging.Server.FilePath))
+	readinessChecker.Add("device", readiness.DeviceCheck(cfg.Transfer.Device))
+	readinessChecker.Add("shutdown", transferService.DrainCheck)
+	if app.CombinedConfig.Transfer.WarmListeners.Enabled {
+		readinessChecker.Add("listeners", transferService.WarmListenerCheck)
+	}
+	healthHandler.SetReadinessChecker(readinessChecker)
+
+	// 按健康检查间隔在后台探测设备、磁盘、常驻监听进程和持久化存储，/api/health 返回缓存的结果
+	accountingFile := ""
+	if cfg.Transfer.Accounting.Enabled {
+		accountingFile = cfg.Transfer.Accounting.File
+	}
+	prober := readiness.NewProber(app.CombinedConfig.Monitoring.Server.HealthCheckInterval)
+	prober.Add("device", readiness.DeviceCheck(cfg.Transfer.Device))
+	prober.Add("disk", transfer.DiskCheck(&cfg.Transfer))
+	if app.CombinedConfig.Transfer.WarmListeners.Enabled {
+		prober.Add("listeners", transferService.WarmListenerCheck)
+	}
+	prober.Add("store", readiness.StoreCheck(cfg.Transfer.JournalFile, accountingFile))
+	prober.Start(context.Background())
+	defer prober.Stop()
+	healthHandler.SetProber(prober)
+	if elector != nil {
+		healthHandler.SetLeaderElector(elector)
+	}
+	if rtranfileManager != nil {
+		healthHandler.SetRtranfileManager(rtranfileManager)
+	}
+	if hugepagePool != nil {
+		healthHandler.SetHugepagePool(hugepagePool)
+	}
+
+	// 内置告警：失败率、队列深度、停滞任务数超过阈值时通知 webhook
+	if app.CombinedConfig.Alerting.Enabled {
+		alertEngine := alert.NewEngine(&app.CombinedConfig.Alerting, alert.NewTransferSource(transferService, &app.CombinedConfig.Alerting))
+		alertEngine.Start(context.Background())
+		defer alertEngine.Stop()
+		healthHandler.SetAlertEngine(alertEngine)
+	}
+
+	// 任务事件通知：任务失败或长时间没有进度时按严重级别发送到 webhook、Slack 或邮件
+	var notifier *notify.Notifier
+	if app.CombinedConfig.Notifications.Enabled {
+		var err error
+		notifier, err = notify.New(&app.CombinedConfig.Notifications, transferService)
+		if err != nil {
+			logger.Fatal("创建通知器失败", zap.Error(err))
+		}
+		notifier.Start(context.Background())
+		defer notifier.Stop()
+		transferService.SetNotifier(notifier)
+	}
+
+	// 文件巡检：定期按分块清单重新校验模式目录中保留的文件，发现静默损坏时发送 file_corrupted 通知
+	if app.CombinedConfig.Transfer.Scrub.Enabled {
+		scrubber := scrub.New(&app.CombinedConfig.Transfer)
+		if notifier != nil {
+			scrubber.SetNotifier(notifier)
+		}
+		scrubber.Start(context.Background())
+		defer scrubber.Stop()
+	}
+
+	// 常驻监听进程：为已启用的模式启动 rtranfile 监听进程并定期检查，关闭时由 Cleanup 停止
+	if app.CombinedConfig.Transfer.WarmListeners.Enabled {
+		transferService.StartWarmListeners(context.Background())
+	}
+
+	// 并发自动调整：按链路利用率在 [min_concurrent, max_concurrent] 内调整生效的并发上限
+	if app.CombinedConfig.Transfer.Autoscale.Enabled {
+		transferService.StartAutoscaler(context.Background())
+		defer transferService.StopAutoscaler()
+	}
 
 	// 注册路由
-	api := router.Group("/api/v1")
+	// /api/v1 需要来自白名单网段并通过签名和认证，健康检查接口保持开放
+	api := router.Group("/api/v1", faultMiddleware, allowListMiddleware, signingMiddleware, authMiddleware, keyRateLimitMiddleware, leaderMiddleware)
 	transferHandler.RegisterRoutes(api)
 	healthHandler.RegisterRoutes(router.Group("/api"))
 	modeHandler.RegisterRoutes(api)
+	loggingHandler.RegisterRoutes(api)
+	deviceHandler.RegisterRoutes(api)
+	handlers.NewTokenHandler(grants).RegisterRoutes(api)
+	handlers.NewAdminHandler(transferService, auditLog).RegisterRoutes(api)
+	if rtranfileManager != nil {
+		handlers.NewRtranfileHandler(rtranfileManager, auditLog).RegisterRoutes(api)
+	}
+	if ledger != nil {
+		handlers.NewAccountingHandler(ledger).RegisterRoutes(api)
+	}
+	handlers.NewManifestHandler(&cfg.Transfer).RegisterRoutes(api)
+	handlers.NewMetadataHandler(&cfg.Transfer).RegisterRoutes(api)
+	handlers.NewFileHandler(transfer.NewFileStager(&cfg.Transfer)).RegisterRoutes(api)
+
+	// Prometheus 指标（传输耗时、吞吐量直方图）
+	if cfg.Monitoring.EnableMetrics {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 
 	// 添加模式检测端点（兼容旧版本）
 	router.GET("/api/mode", func(c *gin.Context) {
@@ -248,7 +584,15 @@ func startServer(cfg *models.ServerConfig, logger *zap.Logger) {
 		Handler:        router,
 		ReadTimeout:    cfg.Server.ReadTimeout,
 		WriteTimeout:   cfg.Server.WriteTimeout,
+		IdleTimeout:    cfg.Server.GetIdleTimeout(),
 		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
+		Protocols:      new(http.Protocols),
+	}
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetUnencryptedHTTP2(cfg.Server.HTTP2)
+	listener, err := utils.Listen(server.Addr, cfg.Server.MaxConnections)
+	if err != nil {
+		return roleStartFailed(detection, coordinator, logger, "监听服务端口失败", zap.String("addr", server.Addr), zap.Error(err))
 	}
 
 	// 启动服务器
@@ -260,20 +604,41 @@ func startServer(cfg *models.ServerConfig, logger *zap.Logger) {
 			zap.String("mode", ModeServer),
 		)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("启动服务器失败", zap.Error(err))
 		}
 	}()
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	coordinator.Started()
+
+	// 自动角色切换：peer_addresses 中的服务端上线时切换为客户端
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if settings := app.CombinedConfig.Detection.Handoff; autoHandoff(app) {
+		coordinator.Watch(watchCtx, settings.CheckInterval, settings.FailureThreshold, func() *handoff.Request {
+			peer, ok := detector.FindPeer()
+			if !ok {
+				return nil
+			}
+			return &handoff.Request{Target: ModeClient, Peer: peer, Reason: fmt.Sprintf("对端 %s 的服务端在线", peer)}
+		})
+	}
 
-	logger.Info("正在关闭服务端...")
+	// 等待中断信号或角色切换请求
+	next := waitForShutdown(coordinator)
+	stopWatch()
+
+	if next != nil {
+		logger.Info("正在关闭服务端，切换运行角色...", zap.String("target", next.Target), zap.String("reason", next.Reason))
+	} else {
+		logger.Info("正在关闭服务端...")
+	}
+
+	// 拒绝新的传输，等待进行中的传输结束（期间仍处理心跳和进度上报），超时后由 Cleanup 强制结束
+	transferService.Drain(cfg.Transfer.ShutdownGracePeriod)
 
 	// 设置关闭超时
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.GetShutdownTimeout())
 	defer cancel()
 
 	// 清理传输服务
@@ -297,18 +662,34 @@ func startServer(cfg *models.ServerConfig, logger *zap.Logger) {
 	}
 
 	logger.Info("服务端已关闭")
+	return next
 }
 
-// startClient 启动客户端
-func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
+// startClient 启动客户端，收到中断信号时返回 nil，收到角色切换请求时排空并关闭服务后返回该请求
+func startClient(app *AppConfig, detector *detect.Detector, detection *detect.Result, coordinator *handoff.Coordinator, logger *zap.Logger) *handoff.Request {
+	cfg := app.ClientConfig
+
+	// 检测时发现了服务端地址，则连接该服务端
+	if detection.Peer != "" {
+		if host, portStr, err := net.SplitHostPort(detection.Peer); err == nil {
+			if port, err := strconv.Atoi(portStr); err == nil {
+				cfg.Server.Host = host
+				cfg.Server.Port = port
+			}
+		}
+	}
+
 	// 检查服务端是否可用
 	if !isServerRunning(cfg.Server.Host, cfg.Server.Port) {
-		logger.Fatal("服务端不可用，请先启动服务端",
+		return roleStartFailed(detection, coordinator, logger, "服务端不可用，请先启动服务端",
 			zap.String("host", cfg.Server.Host),
 			zap.Int("port", cfg.Server.Port),
 		)
 	}
 
+	// 运行时从服务端切换回客户端时，重新接受之前排空时拒绝的客户端传输
+	transfer.ResumeExecutions()
+
 	logger.Info("RDMA 文件传输客户端已连接到服务端",
 		zap.String("server_host", cfg.Server.Host),
 		zap.Int("server_port", cfg.Server.Port),
@@ -327,6 +708,10 @@ func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
 		cfg.Transfer.TransferInterval,
 	)
 
+	if cfg.Transfer.Faults.Enabled {
+		logger.Warn("已启用故障注入，仅用于测试", zap.Any("faults", cfg.Transfer.Faults))
+	}
+
 	// 设置 Gin 模式
 	gin.SetMode(gin.ReleaseMode)
 
@@ -334,10 +719,22 @@ func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
 	router := gin.New()
 
 	// 添加中间件
-	middleware := middleware.NewLoggerMiddleware(logger)
+	router.Use(middleware.Tracing())
+	grants := auth.NewGrantStore()
+	authMiddleware := middleware.Auth(cfg.Security.Auth, grants)
+	keyRateLimitMiddleware := middleware.KeyRateLimit(cfg.Security.Auth)
+	signingMiddleware := middleware.Signature(cfg.Security.Signing, 0) // 客户端模式没有 rtranfile 上传接口
+	allowListMiddleware := middleware.IPAllowList(cfg.Security.IPAllowList)
+	faultMiddleware := middleware.FaultInjection(fault.New(cfg.Transfer.Faults))
+	corsMiddleware := middleware.CORS(cfg.Security.CORS)
+	compressMiddleware := middleware.Compress(app.CombinedConfig.Server.Compression)
+	localizeMiddleware := middleware.Localize(app.CombinedConfig.Server.Language)
+	middleware := middleware.NewLoggerMiddleware(logger.Named("api"))
 	router.Use(middleware.Logger())
 	router.Use(middleware.Recovery())
-	router.Use(CORSMiddleware(cfg.Security.CORS))
+	router.Use(corsMiddleware)
+	router.Use(compressMiddleware)
+	router.Use(localizeMiddleware)
 
 	// 创建 API 处理器（客户端模式使用客户端处理器）
 	// 将客户端的传输配置转换为服务端传输配置格式
@@ -348,6 +745,15 @@ func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
 		MaxConcurrentTransfers: cfg.Transfer.MaxConcurrentTransfers,
 		ChunkSize:             cfg.Transfer.ChunkSize,
 		ServerAddress:         cfg.Server.Host,
+		ChunkManifest:         cfg.Transfer.ChunkManifest,
+		Profiles:              cfg.Transfer.Profiles,
+		QoSClasses:            cfg.Transfer.QoSClasses,
+		Preallocate:           cfg.Transfer.Preallocate,
+		PostHooks:             cfg.Transfer.PostHooks,
+		LogRecordingDir:       cfg.Transfer.LogRecordingDir,
+		Faults:                cfg.Transfer.Faults,
+		ObjectStores:          cfg.Transfer.ObjectStores,
+		PartialSuffix:         cfg.Transfer.PartialSuffix,
 		Modes: models.TransferModes{
 			Hugepages: models.ModeConfig{
 				Enabled: true,
@@ -363,15 +769,86 @@ func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
 			},
 		},
 	}
-	transferHandler := handlers.NewClientTransferHandler(cfg.Server.Host, cfg.Server.Port, serverTransferConfig)
+	// 所有请求共用一个客户端传输服务（共享连接池、重试和认证设置）
+	clientService := transfer.NewClientTransferServiceWithPath(cfg.Server.Host, cfg.Server.Port, rtranfilePath, serverTransferConfig, clientAPIOptions(cfg)...)
+	clientService.SetResume(cfg.Client)
+	clientService.SetChecksum(cfg.Client)
+	clientService.SetProgressInterval(app.CombinedConfig.Monitoring.Client.ProgressUpdateInterval)
+	transferHandler := handlers.NewClientTransferHandler(clientService, cfg.Server.Host, cfg.Server.Port, serverTransferConfig)
+
+	// 块大小调优：按设备统计各块大小的吞吐量，启用 apply 时为未指定块大小的传输自动选择
+	var chunkTuner *tuning.ChunkTuner
+	if cfg.Transfer.ChunkTuning.Enabled {
+		tuner, err := tuning.NewChunkTuner(cfg.Transfer.ChunkTuning)
+		if err != nil {
+			return roleStartFailed(detection, coordinator, logger, "加载块大小统计失败", zap.Error(err))
+		}
+		chunkTuner = tuner
+		clientService.SetChunkTuner(chunkTuner)
+	}
+	transferHandler.SetPollInterval(app.CombinedConfig.Monitoring.Client.ProgressUpdateInterval)
 	healthHandler := handlers.NewHealthHandler(transferService, version)
-	modeHandler := handlers.NewModeHandler(version, ModeClient)
+	modeHandler := handlers.NewModeHandlerWithDetection(version, ModeClient, detector, detection)
+	modeHandler.SetHandoff(coordinator)
+	modeHandler.SetConfigFile(app.ConfigFile)
+	loggingHandler := handlers.NewLoggingHandler()
+	deviceHandler := handlers.NewDeviceHandler()
+
+	// 监控 RDMA 链路状态，链路不可用时健康检查降级并拒绝新的传输
+	linkMonitor := link.NewMonitor([]string{cfg.Transfer.Device}, app.CombinedConfig.Monitoring.Server.LinkCheckInterval)
+	linkMonitor.Start(context.Background())
+	defer linkMonitor.Stop()
+	transferHandler.SetLinkMonitor(linkMonitor)
+	transferHandler.SetGrantStore(grants)
+	healthHandler.SetLinkMonitor(linkMonitor)
+
+	// 依赖就绪检查
+	readinessChecker := readiness.NewChecker()
+	readinessChecker.Add("rtranfile", readiness.BinaryCheck(rtranfilePath))
+	readinessChecker.Add("log_dir", readiness.LogDirCheck(app.CombinedConfig.Logging.Client.FilePath))
+	readinessChecker.Add("device", readiness.DeviceCheck(cfg.Transfer.Device))
+	readinessChecker.Add("shutdown", transfer.ExecutionsDrainCheck)
+	healthHandler.SetReadinessChecker(readinessChecker)
+
+	// 按健康检查间隔在后台探测设备和磁盘，/api/health 返回缓存的结果；客户端角色没有常驻监听进程和持久化存储
+	prober := readiness.NewProber(app.CombinedConfig.Monitoring.Server.HealthCheckInterval)
+	prober.Add("device", readiness.DeviceCheck(cfg.Transfer.Device))
+	prober.Add("disk", transfer.DiskCheck(&cfg.Transfer))
+	prober.Start(context.Background())
+	defer prober.Stop()
+	healthHandler.SetProber(prober)
+
+	// 内置告警：失败率、队列深度、停滞任务数超过阈值时通知 webhook
+	if app.CombinedConfig.Alerting.Enabled {
+		alertEngine := alert.NewEngine(&app.CombinedConfig.Alerting, alert.NewTransferSource(transferService, &app.CombinedConfig.Alerting))
+		alertEngine.Start(context.Background())
+		defer alertEngine.Stop()
+		healthHandler.SetAlertEngine(alertEngine)
+	}
 
 	// 注册路由
-	api := router.Group("/api/v1")
+	// /api/v1 需要来自白名单网段并通过签名和认证，健康检查接口保持开放
+	api := router.Group("/api/v1", faultMiddleware, allowListMiddleware, signingMiddleware, authMiddleware, keyRateLimitMiddleware)
 	transferHandler.RegisterRoutes(api)
 	healthHandler.RegisterRoutes(router.Group("/api"))
 	modeHandler.RegisterRoutes(api)
+	loggingHandler.RegisterRoutes(api)
+	deviceHandler.RegisterRoutes(api)
+	handlers.NewTokenHandler(grants).RegisterRoutes(api)
+	if chunkTuner != nil {
+		handlers.NewStatsHandler(chunkTuner).RegisterRoutes(api)
+	}
+	// 流水线传输：一组文件的本地暂存、校验和落盘与 RDMA 传输重叠执行
+	if cfg.Transfer.Pipeline.Enabled {
+		pipelineHandler := handlers.NewPipelineHandler(transfer.NewPipelineRunner(clientService, cfg.Transfer.Pipeline), serverTransferConfig)
+		pipelineHandler.SetLinkMonitor(linkMonitor)
+		pipelineHandler.RegisterRoutes(api)
+	}
+
+	// Prometheus 指标（传输耗时、吞吐量直方图）
+	if app.CombinedConfig.Monitoring.Server.EnableMetrics {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 
 	// 添加模式检测端点（兼容旧版本）
 	router.GET("/api/mode", func(c *gin.Context) {
@@ -429,15 +906,36 @@ func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
 	fmt.Printf("客户端API地址: http://localhost:%d\n", clientPort)
 	fmt.Printf("使用 'curl http://localhost:%d/api/v1/transfers' 发起自动化传输\n", clientPort)
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	coordinator.Started()
+
+	// 自动角色切换：服务端连续不可达时切换为服务端
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if settings := app.CombinedConfig.Detection.Handoff; autoHandoff(app) {
+		coordinator.Watch(watchCtx, settings.CheckInterval, settings.FailureThreshold, func() *handoff.Request {
+			if isServerRunning(cfg.Server.Host, cfg.Server.Port) {
+				return nil
+			}
+			return &handoff.Request{Target: ModeServer, Reason: fmt.Sprintf("服务端 %s:%d 不可达", cfg.Server.Host, cfg.Server.Port)}
+		})
+	}
+
+	// 等待中断信号或角色切换请求
+	next := waitForShutdown(coordinator)
+	stopWatch()
+
+	if next != nil {
+		logger.Info("正在关闭客户端，切换运行角色...", zap.String("target", next.Target), zap.String("reason", next.Reason))
+	} else {
+		logger.Info("正在关闭客户端...")
+	}
 
-	logger.Info("正在关闭客户端...")
+	// 拒绝新的传输，等待执行中的客户端传输结束并上报结果，超时后停止
+	transfer.DrainExecutions(cfg.Transfer.ShutdownGracePeriod, logger)
+	transfer.StopExecutions()
 
 	// 设置关闭超时
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), app.CombinedConfig.Server.GetShutdownTimeout())
 	defer cancel()
 
 	// 清理传输服务
@@ -449,6 +947,107 @@ func startClient(cfg *models.ClientConfig, logger *zap.Logger) {
 	}
 
 	logger.Info("客户端已关闭")
+	return next
+}
+
+// waitForShutdown 等待中断信号或角色切换请求，收到中断信号时返回 nil
+func waitForShutdown(coordinator *handoff.Coordinator) *handoff.Request {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case <-quit:
+		return nil
+	case req := <-coordinator.Requests():
+		return &req
+	}
+}
+
+// restartRequestEnv 重新执行进程时传递切换请求的环境变量，重启后的进程据此报告切换完成
+const restartRequestEnv = "RDMA_BURST_RESTART_REQUEST"
+
+// restartProcess 以相同的参数重新执行当前进程，命令行参数指定了 -mode 时替换为目标模式
+func restartProcess(req handoff.Request, logger *zap.Logger) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取可执行文件路径失败: %v", err)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("编码切换请求失败: %v", err)
+	}
+
+	args := restartArgs(os.Args, req.Target)
+	logger.Info("重新执行进程", zap.String("executable", executable), zap.Strings("args", args))
+	logger.Sync()
+	env := append(os.Environ(), restartRequestEnv+"="+string(data))
+	if err := syscall.Exec(executable, args, env); err != nil {
+		return fmt.Errorf("重新执行进程失败: %v", err)
+	}
+	return nil
+}
+
+// restartArgs 将命令行参数中的 -mode 替换为目标模式
+func restartArgs(args []string, mode string) []string {
+	restarted := make([]string, len(args))
+	copy(restarted, args)
+	for i := 1; i < len(restarted); i++ {
+		arg := restarted[i]
+		if arg == "--" {
+			break
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "mode" {
+			continue
+		}
+		if hasValue {
+			restarted[i] = "-mode=" + mode
+		} else if i+1 < len(restarted) {
+			restarted[i+1] = mode
+		}
+	}
+	return restarted
+}
+
+// restoredRequest 读取重启前的切换请求
+func restoredRequest() (handoff.Request, bool) {
+	var req handoff.Request
+	data := os.Getenv(restartRequestEnv)
+	if data == "" {
+		return req, false
+	}
+	os.Unsetenv(restartRequestEnv)
+	if err := json.Unmarshal([]byte(data), &req); err != nil {
+		return req, false
+	}
+	return req, true
+}
+
+// autoHandoff 是否自动切换角色：启用了 detection.handoff 且运行模式由自动检测决定（命令行参数和配置文件均未固定模式）
+func autoHandoff(app *AppConfig) bool {
+	if !app.CombinedConfig.Detection.Handoff.Enabled {
+		return false
+	}
+	switch {
+	case app.Mode == ModeServer, app.Mode == ModeClient:
+		return false
+	case app.CombinedConfig.Mode == ModeServer, app.CombinedConfig.Mode == ModeClient:
+		return false
+	}
+	return true
+}
+
+// roleStartFailed 处理角色启动失败：进程启动时或回退到原角色后仍失败时退出，运行时切换到的角色启动失败时回退到原角色
+func roleStartFailed(detection *detect.Result, coordinator *handoff.Coordinator, logger *zap.Logger, msg string, fields ...zap.Field) *handoff.Request {
+	status := coordinator.Status()
+	if detection.Source != detect.SourceHandoff || status.Phase == handoff.PhaseFailed || status.Last == nil {
+		logger.Fatal(msg, fields...)
+	}
+
+	logger.Error(msg, fields...)
+	coordinator.Failed(status.Last.From, errors.New(msg))
+	return &handoff.Request{From: detection.Mode, Target: status.Last.From, Reason: msg}
 }
 
 // isServerRunning 检查服务端是否在运行
@@ -479,36 +1078,64 @@ func printVersion() {
 	fmt.Printf("运行模式: 统一模式（支持服务端/客户端自动检测）\n")
 }
 
-// CORSMiddleware CORS 中间件
-func CORSMiddleware(corsConfig models.CORSSettings) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if !corsConfig.Enabled {
-			c.Next()
-			return
-		}
+// clientAPIOptions 根据客户端配置生成调用服务端API的选项
+func clientAPIOptions(cfg *models.ClientConfig) []client.Option {
+	options := []client.Option{
+		client.WithTimeout(cfg.Server.Timeout),
+		client.WithWaitTimeout(cfg.Server.WaitTimeout),
+		client.WithRetry(cfg.Server.RetryAttempts, cfg.Server.RetryDelay),
+		client.WithUserAgent("rdma-burst-client/" + version),
+		client.WithRequestObserver(metrics.ObserveAPIRequest),
+	}
 
-		// 设置 CORS 头
-		origin := c.Request.Header.Get("Origin")
-		if len(corsConfig.AllowedOrigins) > 0 {
-			for _, allowedOrigin := range corsConfig.AllowedOrigins {
-				if allowedOrigin == "*" || allowedOrigin == origin {
-					c.Header("Access-Control-Allow-Origin", origin)
-					break
-				}
-			}
+	if auth := cfg.Security.Auth; auth.Enabled {
+		if auth.Token != "" {
+			options = append(options, client.WithToken(auth.Token))
+		} else if auth.Username != "" {
+			options = append(options, client.WithBasicAuth(auth.Username, auth.Password))
 		}
+	}
+
+	if signing := cfg.Security.Signing; signing.Enabled {
+		options = append(options, client.WithSigning(signing.Secret))
+	}
 
-		c.Header("Access-Control-Allow-Methods", joinStrings(corsConfig.AllowedMethods, ", "))
-		c.Header("Access-Control-Allow-Headers", joinStrings(corsConfig.AllowedHeaders, ", "))
-		c.Header("Access-Control-Allow-Credentials", "true")
+	return options
+}
 
-		// 处理预检请求
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+// newLoggerConfig 将日志设置转换为日志器配置
+func newLoggerConfig(settings models.LoggingSettings) logger.Config {
+	return logger.Config{
+		Level:      settings.Level,
+		FilePath:   settings.FilePath,
+		MaxSize:    settings.MaxSize,
+		MaxBackups: settings.MaxBackups,
+		MaxAge:     settings.MaxAge,
+		Format:     settings.Format,
+		Components: settings.Components,
+		Sink: logger.SinkConfig{
+			Type:    settings.Sink.Type,
+			Network: settings.Sink.Network,
+			Address: settings.Sink.Address,
+			Tag:     settings.Sink.Tag,
+		},
+		RedactKeys: settings.RedactKeys,
+	}
+}
 
-		c.Next()
+// newTracingConfig 将追踪设置转换为追踪器配置
+func newTracingConfig(settings models.TracingSettings, defaultServiceName string) tracing.Config {
+	serviceName := settings.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	return tracing.Config{
+		Enabled:     settings.Enabled,
+		Endpoint:    settings.Endpoint,
+		Insecure:    settings.Insecure,
+		ServiceName: serviceName,
+		SampleRatio: settings.SampleRatio,
+		Headers:     settings.Headers,
 	}
 }
 
@@ -537,16 +1164,3 @@ func getRtranfilePath() string {
 	// 5. 默认返回硬编码路径（兼容旧版本）
 	return "./bin/rtranfile"
 }
-
-// joinStrings 连接字符串切片
-func joinStrings(strs []string, sep string) string {
-	if len(strs) == 0 {
-		return ""
-	}
-
-	result := strs[0]
-	for i := 1; i < len(strs); i++ {
-		result += sep + strs[i]
-	}
-	return result
-}
\ No newline at end of file
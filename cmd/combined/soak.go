@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/client"
+)
+
+// soakOptions 浸泡测试参数
+type soakOptions struct {
+	benchOptions
+	duration           time.Duration
+	interval           time.Duration
+	warmup             time.Duration
+	maxGoroutineGrowth int
+	maxFDGrowth        int
+	maxHeapGrowth      int64
+}
+
+// soakSample 一次服务指标采样
+type soakSample struct {
+	elapsed time.Duration
+	system  models.SystemMetrics
+}
+
+// soakCounters 浸泡测试期间的传输计数
+type soakCounters struct {
+	completed atomic.Int64
+	failed    atomic.Int64
+	apiErrors atomic.Int64
+}
+
+// runSoak 执行 bench soak
+func runSoak(args []string) error {
+	fs := flag.NewFlagSet("bench soak", flag.ContinueOnError)
+	opts := soakOptions{}
+	var sizeText, rateText, heapText string
+	fs.StringVar(&opts.server, "server", "http://127.0.0.1:8080", "API 地址（--simulate 时忽略）")
+	fs.StringVar(&opts.token, "token", "", "API Bearer 令牌")
+	fs.StringVar(&sizeText, "size", "256M", "每个文件的大小，例如 512M、10G")
+	fs.StringVar(&opts.mode, "mode", models.ModeTmpfs, "传输模式: hugepages, tmpfs, filesystem, auto")
+	fs.IntVar(&opts.concurrency, "concurrency", 2, "同时进行的传输数")
+	fs.StringVar(&opts.dir, "dir", filepath.Join(os.TempDir(), "rdma-burst-soak"), "生成稀疏文件的目录")
+	fs.BoolVar(&opts.simulate, "simulate", false, "在进程内启动模拟后端，不需要服务端和 RDMA 设备")
+	fs.StringVar(&rateText, "rate", "1G", "模拟后端每秒复制的字节数（仅 --simulate）")
+	fs.DurationVar(&opts.duration, "duration", 4*time.Hour, "循环传输的总时长")
+	fs.DurationVar(&opts.interval, "interval", time.Minute, "采样服务指标的间隔")
+	fs.DurationVar(&opts.warmup, "warmup", 5*time.Minute, "预热时长，期间的采样不参与增长判断")
+	fs.IntVar(&opts.maxGoroutineGrowth, "max-goroutine-growth", 50, "允许的 Goroutine 增长数")
+	fs.IntVar(&opts.maxFDGrowth, "max-fd-growth", 20, "允许的文件描述符增长数")
+	fs.StringVar(&heapText, "max-heap-growth", "64M", "允许的堆内存增长")
+	fs.Usage = func() {
+		fmt.Println("用法: rdma-burst bench soak [flags]")
+		fmt.Println()
+		fmt.Println("长时间循环传输并采样服务的 Goroutine、文件描述符和内存，发现持续增长时以非零状态退出")
+		fmt.Println()
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if opts.size, err = parseByteSize(sizeText); err != nil {
+		return fmt.Errorf("无效的文件大小: %v", err)
+	}
+	if opts.rate, err = parseByteSize(rateText); err != nil {
+		return fmt.Errorf("无效的模拟速率: %v", err)
+	}
+	if opts.maxHeapGrowth, err = parseByteSize(heapText); err != nil {
+		return fmt.Errorf("无效的堆内存增长上限: %v", err)
+	}
+	if opts.concurrency < 1 || opts.interval <= 0 || opts.duration <= opts.warmup {
+		return fmt.Errorf("concurrency 和 interval 必须大于 0，duration 必须大于 warmup")
+	}
+
+	runID := strconv.FormatInt(time.Now().Unix(), 10)
+	files, err := createSparseFiles(opts.dir, runID, opts.concurrency, opts.size)
+	if err != nil {
+		return err
+	}
+	defer removeFiles(files)
+
+	api, cleanup, err := benchClient(&opts.benchOptions)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	fmt.Printf("浸泡测试编号: %s（任务标签 %s=%s）\n", runID, benchLabel, runID)
+	fmt.Printf("%d 路并发循环传输 %s 的 %s 文件，持续 %s，每 %s 采样一次\n",
+		opts.concurrency, sizeText, opts.mode, opts.duration, opts.interval)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+	defer cancel()
+
+	counters := &soakCounters{}
+	var wg sync.WaitGroup
+	for _, file := range files {
+		wg.Add(1)
+		go func(file string) {
+			defer wg.Done()
+			soakLoop(ctx, api, &opts, runID, file, counters)
+		}(file)
+	}
+
+	samples := sampleSoak(ctx, api, &opts, counters)
+	wg.Wait()
+
+	fmt.Println()
+	fmt.Printf("传输完成: %d，失败: %d，API 错误: %d\n",
+		counters.completed.Load(), counters.failed.Load(), counters.apiErrors.Load())
+	return checkSoakGrowth(samples, &opts)
+}
+
+// soakLoop 循环提交一个文件的传输并等待结束，直到 ctx 结束；结束时取消未完成的任务
+func soakLoop(ctx context.Context, api *client.Client, opts *soakOptions, runID, file string, counters *soakCounters) {
+	for ctx.Err() == nil {
+		resp, err := api.CreateTransfer(ctx, &client.TransferRequest{
+			Filename:  file,
+			Mode:      opts.mode,
+			Direction: models.DirectionPut,
+			Size:      opts.size,
+			Labels:    map[string]string{benchLabel: runID},
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			counters.apiErrors.Add(1)
+			// 服务端暂时不可用或达到并发上限时稍后重试
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		progress, err := api.WaitForCompletion(ctx, resp.ID, time.Second)
+		switch {
+		case ctx.Err() != nil:
+			api.CancelTransfer(context.Background(), resp.ID)
+			return
+		case err != nil && progress == nil:
+			counters.apiErrors.Add(1)
+		case progress.Status == models.StatusCompleted:
+			counters.completed.Add(1)
+		default:
+			counters.failed.Add(1)
+		}
+	}
+}
+
+// sampleSoak 按间隔采样服务指标直到 ctx 结束
+func sampleSoak(ctx context.Context, api *client.Client, opts *soakOptions, counters *soakCounters) []soakSample {
+	start := time.Now()
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	var samples []soakSample
+	for {
+		select {
+		case <-ctx.Done():
+			return samples
+		case now := <-ticker.C:
+			metrics, err := api.Metrics(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Printf("[%s] 采样失败: %v\n", now.Sub(start).Round(time.Second), err)
+				}
+				continue
+			}
+
+			sample := soakSample{elapsed: now.Sub(start), system: metrics.System}
+			samples = append(samples, sample)
+			phase := ""
+			if sample.elapsed <= opts.warmup {
+				phase = "（预热）"
+			}
+			fmt.Printf("[%s]%s 完成 %d 失败 %d | goroutines %d, fds %d, heap %.1fMB, sys %.1fMB\n",
+				sample.elapsed.Round(time.Second), phase,
+				counters.completed.Load(), counters.failed.Load(),
+				sample.system.Goroutines, sample.system.OpenFDs,
+				float64(sample.system.HeapAllocBytes)/(1024*1024), float64(sample.system.SysBytes)/(1024*1024))
+		}
+	}
+}
+
+// checkSoakGrowth 比较预热后第一段和最后一段采样的最小值，超过允许的增长时返回错误
+// 取最小值可以忽略传输进行中的瞬时峰值，只有持续的增长（泄漏）才会抬高最小值
+func checkSoakGrowth(samples []soakSample, opts *soakOptions) error {
+	var measured []soakSample
+	for _, sample := range samples {
+		if sample.elapsed > opts.warmup {
+			measured = append(measured, sample)
+		}
+	}
+
+	window := len(measured) / 10
+	if window < 3 {
+		window = 3
+	}
+	if len(measured) < 2*window {
+		return fmt.Errorf("预热后只有 %d 次采样，至少需要 %d 次才能判断增长", len(measured), 2*window)
+	}
+
+	first, last := soakMinimum(measured[:window]), soakMinimum(measured[len(measured)-window:])
+	goroutineGrowth := last.Goroutines - first.Goroutines
+	fdGrowth := last.OpenFDs - first.OpenFDs
+	heapGrowth := int64(last.HeapAllocBytes) - int64(first.HeapAllocBytes)
+
+	fmt.Println()
+	fmt.Println("资源增长（预热后首段最小值 -> 末段最小值）")
+	fmt.Println("==================================================================")
+	fmt.Printf("goroutines: %d -> %d (%+d)\n", first.Goroutines, last.Goroutines, goroutineGrowth)
+	fmt.Printf("fds: %d -> %d (%+d)\n", first.OpenFDs, last.OpenFDs, fdGrowth)
+	fmt.Printf("heap: %.1fMB -> %.1fMB (%+.1fMB)\n",
+		float64(first.HeapAllocBytes)/(1024*1024), float64(last.HeapAllocBytes)/(1024*1024), float64(heapGrowth)/(1024*1024))
+
+	var leaks []string
+	if goroutineGrowth > opts.maxGoroutineGrowth {
+		leaks = append(leaks, fmt.Sprintf("goroutines 增长 %d（上限 %d）", goroutineGrowth, opts.maxGoroutineGrowth))
+	}
+	if first.OpenFDs >= 0 && fdGrowth > opts.maxFDGrowth {
+		leaks = append(leaks, fmt.Sprintf("文件描述符增长 %d（上限 %d）", fdGrowth, opts.maxFDGrowth))
+	}
+	if heapGrowth > opts.maxHeapGrowth {
+		leaks = append(leaks, fmt.Sprintf("堆内存增长 %.1fMB（上限 %.1fMB）",
+			float64(heapGrowth)/(1024*1024), float64(opts.maxHeapGrowth)/(1024*1024)))
+	}
+	if len(leaks) > 0 {
+		return fmt.Errorf("检测到资源持续增长，可能存在泄漏: %v", leaks)
+	}
+
+	fmt.Println("没有发现资源持续增长")
+	return nil
+}
+
+// soakMinimum 获取一段采样中各项指标的最小值
+func soakMinimum(samples []soakSample) models.SystemMetrics {
+	minimum := samples[0].system
+	for _, sample := range samples[1:] {
+		if sample.system.Goroutines < minimum.Goroutines {
+			minimum.Goroutines = sample.system.Goroutines
+		}
+		if sample.system.OpenFDs < minimum.OpenFDs {
+			minimum.OpenFDs = sample.system.OpenFDs
+		}
+		if sample.system.HeapAllocBytes < minimum.HeapAllocBytes {
+			minimum.HeapAllocBytes = sample.system.HeapAllocBytes
+		}
+	}
+	return minimum
+}
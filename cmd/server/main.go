@@ -8,7 +8,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -16,9 +15,25 @@ import (
 	"rdma-burst/internal/api/handlers"
 	"rdma-burst/internal/api/middleware"
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/alert"
+	"rdma-burst/internal/services/accounting"
+	"rdma-burst/internal/services/audit"
+	"rdma-burst/internal/services/binaries"
+	"rdma-burst/internal/services/auth"
 	"rdma-burst/internal/services/config"
+	"rdma-burst/internal/services/fault"
+	"rdma-burst/internal/services/hugepool"
+	"rdma-burst/internal/services/journal"
+	"rdma-burst/internal/services/leader"
+	"rdma-burst/internal/services/link"
+	"rdma-burst/internal/services/notify"
+	"rdma-burst/internal/services/readiness"
+	"rdma-burst/internal/services/scrub"
 	"rdma-burst/internal/services/transfer"
+	"rdma-burst/internal/utils"
 	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/metrics"
+	"rdma-burst/pkg/tracing"
 )
 
 const (
@@ -26,32 +41,117 @@ const (
 )
 
 func main() {
-	// 初始化日志
-	logger, err := logger.NewLogger()
-	if err != nil {
-		log.Fatalf("初始化日志失败: %v", err)
-	}
-	defer logger.Sync()
-
-	// 加载配置
+	// 加载配置（日志系统依赖配置，加载失败时输出到标准错误）
 	configManager := config.NewConfigManager("server")
 	configPath := getConfigPath()
 	
 	serverConfig, err := configManager.LoadConfig(configPath)
 	if err != nil {
-		logger.Fatal("加载配置失败", zap.Error(err))
+		log.Fatalf("加载配置失败: %v", err)
 	}
 
 	cfg := serverConfig.(*models.ServerConfig)
 
+	// 初始化日志
+	if err := logger.Init(newLoggerConfig(cfg.Logging)); err != nil {
+		log.Printf("初始化文件日志失败，使用默认日志: %v", err)
+	}
+	logger := logger.GetLogger()
+	defer logger.Sync()
+
+	// 初始化追踪
+	shutdownTracing, err := tracing.Init(newTracingConfig(cfg.Tracing, "rdma-burst-server"))
+	if err != nil {
+		logger.Warn("初始化追踪失败", zap.Error(err))
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
 	// 创建传输服务（使用配置中的传输设置）
 	rtranfilePath := "./bin/rtranfile" // rtranfile 二进制文件路径
+
+	// rtranfile 版本管理：传输服务通过指向当前版本的符号链接启动 rtranfile，切换版本后新启动的进程使用新版本
+	var rtranfileManager *binaries.Manager
+	if cfg.Transfer.Rtranfile.Enabled {
+		manager, err := binaries.Open(cfg.Transfer.Rtranfile, rtranfilePath)
+		if err != nil {
+			logger.Fatal("打开 rtranfile 版本目录失败", zap.Error(err))
+		}
+		rtranfileManager = manager
+		rtranfilePath = manager.Path()
+	}
 	transferService := transfer.NewTransferServiceWithConfig(
 		rtranfilePath,
 		&cfg.Transfer,
 		nil, // 单次传输配置为空，使用默认值
 	)
 
+	// 任务状态预写日志：守护进程崩溃重启后恢复进行中的任务
+	if journalFile := cfg.Transfer.JournalFile; journalFile != "" {
+		taskJournal, err := journal.Open(journalFile)
+		if err != nil {
+			logger.Warn("打开任务日志失败，任务状态不会持久化", zap.Error(err))
+		} else {
+			defer taskJournal.Close()
+			transferService.SetJournal(taskJournal)
+			if reattached, err := transferService.Recover(context.Background()); err != nil {
+				logger.Warn("从任务日志恢复任务失败", zap.Error(err))
+			} else {
+				logger.Info("已从任务日志恢复任务",
+					zap.String("journal_file", journalFile),
+					zap.Int("reattached", reattached),
+				)
+			}
+		}
+	}
+
+	// 审计日志：记录管理员的人工干预
+	auditLog, err := audit.Open(cfg.Security.AuditLogFile)
+	if err != nil {
+		logger.Warn("打开审计日志失败，审计记录只写入服务日志", zap.Error(err))
+		auditLog, _ = audit.Open("")
+	}
+	defer auditLog.Close()
+
+	// 用量统计：结束的任务按调用方计入当前统计周期，供计费查询
+	var ledger *accounting.Ledger
+	if cfg.Transfer.Accounting.Enabled {
+		ledger, err = accounting.Open(cfg.Transfer.Accounting)
+		if err != nil {
+			logger.Fatal("打开用量记录失败", zap.Error(err))
+		}
+		defer ledger.Close()
+		transferService.SetAccounting(ledger)
+		ledger.Start(context.Background())
+	}
+
+	// 大页缓冲池：启动时预留大页，hugepages 模式的 put 传输准备时租用，避免传输中途因碎片分配大页失败
+	var hugepagePool *hugepool.Pool
+	if cfg.Transfer.HugepagePool.Enabled {
+		hugepagePool, err = hugepool.NewPool(cfg.Transfer.HugepagePool)
+		if err != nil {
+			logger.Fatal("创建大页缓冲池失败", zap.Error(err))
+		}
+		hugepagePool.Start(context.Background())
+		defer hugepagePool.Stop()
+		transferService.SetHugepagePool(hugepagePool)
+	}
+
+	if cfg.Transfer.Faults.Enabled {
+		logger.Warn("已启用故障注入，仅用于测试", zap.Any("faults", cfg.Transfer.Faults))
+	}
+
+	// 协调者选主：多个实例共享同一个 Kubernetes Lease，只有领导者调度传输，其余实例只提供只读 API
+	elector, err := leader.New(cfg.LeaderElection)
+	if err != nil {
+		logger.Fatal("创建选主器失败", zap.Error(err))
+	}
+	if elector != nil {
+		transferService.SetLeaderCheck(elector.IsLeader)
+		elector.Start(context.Background())
+		defer elector.Stop()
+	}
+
 	// 设置 Gin 模式
 	if cfg.Server.LogLevel == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -63,19 +163,141 @@ func main() {
 	router := gin.New()
 
 	// 添加中间件
-	middleware := middleware.NewLoggerMiddleware(logger)
+	router.Use(middleware.Tracing())
+	grants := auth.NewGrantStore()
+	authMiddleware := middleware.Auth(cfg.Security.Auth, grants)
+	keyRateLimitMiddleware := middleware.KeyRateLimit(cfg.Security.Auth)
+	signingMiddleware := middleware.Signature(cfg.Security.Signing, rtranfileManager.MaxSize())
+	allowListMiddleware := middleware.IPAllowList(cfg.Security.IPAllowList)
+	faultMiddleware := middleware.FaultInjection(fault.New(cfg.Transfer.Faults))
+	leaderMiddleware := middleware.LeaderOnly(elector)
+	corsMiddleware := middleware.CORS(cfg.Security.CORS)
+	compressMiddleware := middleware.Compress(cfg.Server.Compression)
+	localizeMiddleware := middleware.Localize(cfg.Server.Language)
+	middleware := middleware.NewLoggerMiddleware(logger.Named("api"))
 	router.Use(middleware.Logger())
 	router.Use(middleware.Recovery())
-	router.Use(CORSMiddleware(cfg.Security.CORS))
+	router.Use(corsMiddleware)
+	router.Use(compressMiddleware)
+	router.Use(localizeMiddleware)
 
 	// 创建 API 处理器
 	transferHandler := handlers.NewTransferHandler(transferService, &cfg.Transfer)
 	healthHandler := handlers.NewHealthHandler(transferService, version)
+	loggingHandler := handlers.NewLoggingHandler()
+	deviceHandler := handlers.NewDeviceHandler()
+
+	// 监控 RDMA 链路状态，链路不可用时健康检查降级并拒绝新的传输
+	linkMonitor := link.NewMonitor([]string{cfg.Transfer.Device}, cfg.Monitoring.LinkCheckInterval)
+	linkMonitor.Start(context.Background())
+	defer linkMonitor.Stop()
+	transferHandler.SetLinkMonitor(linkMonitor)
+	transferHandler.SetGrantStore(grants)
+	transferHandler.SetConcurrencySource(transferService)
+	transferHandler.SetAvailabilitySource(transferService)
+	healthHandler.SetLinkMonitor(linkMonitor)
+
+	// 依赖就绪检查
+	readinessChecker := readiness.NewChecker()
+	readinessChecker.Add("rtranfile", readiness.BinaryCheck(rtranfilePath))
+	readinessChecker.Add("log_dir", readiness.LogDirCheck(cfg.Logging.FilePath))
+	readinessChecker.Add("device", readiness.DeviceCheck(cfg.Transfer.Device))
+	readinessChecker.Add("shutdown", transferService.DrainCheck)
+	if cfg.Transfer.WarmListeners.Enabled {
+		readinessChecker.Add("listeners", transferService.WarmListenerCheck)
+	}
+	healthHandler.SetReadinessChecker(readinessChecker)
+
+	// 按健康检查间隔在后台探测设备、磁盘、常驻监听进程和持久化存储，/api/health 返回缓存的结果
+	accountingFile := ""
+	if cfg.Transfer.Accounting.Enabled {
+		accountingFile = cfg.Transfer.Accounting.File
+	}
+	prober := readiness.NewProber(cfg.Monitoring.HealthCheckInterval)
+	prober.Add("device", readiness.DeviceCheck(cfg.Transfer.Device))
+	prober.Add("disk", transfer.DiskCheck(&cfg.Transfer))
+	if cfg.Transfer.WarmListeners.Enabled {
+		prober.Add("listeners", transferService.WarmListenerCheck)
+	}
+	prober.Add("store", readiness.StoreCheck(cfg.Transfer.JournalFile, accountingFile))
+	prober.Start(context.Background())
+	defer prober.Stop()
+	healthHandler.SetProber(prober)
+	if elector != nil {
+		healthHandler.SetLeaderElector(elector)
+	}
+	if rtranfileManager != nil {
+		healthHandler.SetRtranfileManager(rtranfileManager)
+	}
+	if hugepagePool != nil {
+		healthHandler.SetHugepagePool(hugepagePool)
+	}
+
+	// 内置告警：失败率、队列深度、停滞任务数超过阈值时通知 webhook
+	if cfg.Alerting.Enabled {
+		alertEngine := alert.NewEngine(&cfg.Alerting, alert.NewTransferSource(transferService, &cfg.Alerting))
+		alertEngine.Start(context.Background())
+		defer alertEngine.Stop()
+		healthHandler.SetAlertEngine(alertEngine)
+	}
+
+	// 任务事件通知：任务失败或长时间没有进度时按严重级别发送到 webhook、Slack 或邮件
+	var notifier *notify.Notifier
+	if cfg.Notifications.Enabled {
+		var err error
+		notifier, err = notify.New(&cfg.Notifications, transferService)
+		if err != nil {
+			logger.Fatal("创建通知器失败", zap.Error(err))
+		}
+		notifier.Start(context.Background())
+		defer notifier.Stop()
+		transferService.SetNotifier(notifier)
+	}
+
+	// 文件巡检：定期按分块清单重新校验模式目录中保留的文件，发现静默损坏时发送 file_corrupted 通知
+	if cfg.Transfer.Scrub.Enabled {
+		scrubber := scrub.New(&cfg.Transfer)
+		if notifier != nil {
+			scrubber.SetNotifier(notifier)
+		}
+		scrubber.Start(context.Background())
+		defer scrubber.Stop()
+	}
+
+	// 常驻监听进程：为已启用的模式启动 rtranfile 监听进程并定期检查，关闭时由 Cleanup 停止
+	if cfg.Transfer.WarmListeners.Enabled {
+		transferService.StartWarmListeners(context.Background())
+	}
+
+	// 并发自动调整：按链路利用率在 [min_concurrent, max_concurrent] 内调整生效的并发上限
+	if cfg.Transfer.Autoscale.Enabled {
+		transferService.StartAutoscaler(context.Background())
+		defer transferService.StopAutoscaler()
+	}
 
 	// 注册路由
-	api := router.Group("/api/v1")
+	// /api/v1 需要来自白名单网段并通过签名和认证，健康检查接口保持开放
+	api := router.Group("/api/v1", faultMiddleware, allowListMiddleware, signingMiddleware, authMiddleware, keyRateLimitMiddleware, leaderMiddleware)
 	transferHandler.RegisterRoutes(api)
 	healthHandler.RegisterRoutes(router.Group("/api"))
+	loggingHandler.RegisterRoutes(api)
+	deviceHandler.RegisterRoutes(api)
+	handlers.NewTokenHandler(grants).RegisterRoutes(api)
+	handlers.NewAdminHandler(transferService, auditLog).RegisterRoutes(api)
+	if rtranfileManager != nil {
+		handlers.NewRtranfileHandler(rtranfileManager, auditLog).RegisterRoutes(api)
+	}
+	if ledger != nil {
+		handlers.NewAccountingHandler(ledger).RegisterRoutes(api)
+	}
+	handlers.NewManifestHandler(&cfg.Transfer).RegisterRoutes(api)
+	handlers.NewMetadataHandler(&cfg.Transfer).RegisterRoutes(api)
+	handlers.NewFileHandler(transfer.NewFileStager(&cfg.Transfer)).RegisterRoutes(api)
+
+	// Prometheus 指标（传输耗时、吞吐量直方图）
+	if cfg.Monitoring.EnableMetrics {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 
 	// 根路径健康检查
 	router.GET("/", func(c *gin.Context) {
@@ -92,7 +314,15 @@ func main() {
 		Handler:        router,
 		ReadTimeout:    cfg.Server.ReadTimeout,
 		WriteTimeout:   cfg.Server.WriteTimeout,
+		IdleTimeout:    cfg.Server.GetIdleTimeout(),
 		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
+		Protocols:      new(http.Protocols),
+	}
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetUnencryptedHTTP2(cfg.Server.HTTP2)
+	listener, err := utils.Listen(server.Addr, cfg.Server.MaxConnections)
+	if err != nil {
+		logger.Fatal("监听服务端口失败", zap.String("addr", server.Addr), zap.Error(err))
 	}
 
 	// 启动服务器
@@ -103,7 +333,7 @@ func main() {
 			zap.String("version", version),
 		)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("启动服务器失败", zap.Error(err))
 		}
 	}()
@@ -115,8 +345,11 @@ func main() {
 
 	logger.Info("正在关闭服务...")
 
+	// 拒绝新的传输，等待进行中的传输结束（期间仍处理心跳和进度上报），超时后由 Cleanup 强制结束
+	transferService.Drain(cfg.Transfer.ShutdownGracePeriod)
+
 	// 设置关闭超时
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.GetShutdownTimeout())
 	defer cancel()
 
 	// 清理传输服务
@@ -141,48 +374,38 @@ func getConfigPath() string {
 	return "./configs/server.yaml"
 }
 
-// CORSMiddleware CORS 中间件
-func CORSMiddleware(corsConfig models.CORSSettings) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if !corsConfig.Enabled {
-			c.Next()
-			return
-		}
-
-		// 设置 CORS 头
-		origin := c.Request.Header.Get("Origin")
-		if len(corsConfig.AllowedOrigins) > 0 {
-			for _, allowedOrigin := range corsConfig.AllowedOrigins {
-				if allowedOrigin == "*" || allowedOrigin == origin {
-					c.Header("Access-Control-Allow-Origin", origin)
-					break
-				}
-			}
-		}
-
-		c.Header("Access-Control-Allow-Methods", joinStrings(corsConfig.AllowedMethods, ", "))
-		c.Header("Access-Control-Allow-Headers", joinStrings(corsConfig.AllowedHeaders, ", "))
-		c.Header("Access-Control-Allow-Credentials", "true")
-
-		// 处理预检请求
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
+// newLoggerConfig 将日志设置转换为日志器配置
+func newLoggerConfig(settings models.LoggingSettings) logger.Config {
+	return logger.Config{
+		Level:      settings.Level,
+		FilePath:   settings.FilePath,
+		MaxSize:    settings.MaxSize,
+		MaxBackups: settings.MaxBackups,
+		MaxAge:     settings.MaxAge,
+		Format:     settings.Format,
+		Components: settings.Components,
+		Sink: logger.SinkConfig{
+			Type:    settings.Sink.Type,
+			Network: settings.Sink.Network,
+			Address: settings.Sink.Address,
+			Tag:     settings.Sink.Tag,
+		},
+		RedactKeys: settings.RedactKeys,
 	}
 }
 
-// joinStrings 连接字符串切片
-func joinStrings(strs []string, sep string) string {
-	if len(strs) == 0 {
-		return ""
+// newTracingConfig 将追踪设置转换为追踪器配置
+func newTracingConfig(settings models.TracingSettings, defaultServiceName string) tracing.Config {
+	serviceName := settings.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
 	}
-	
-	result := strs[0]
-	for i := 1; i < len(strs); i++ {
-		result += sep + strs[i]
+	return tracing.Config{
+		Enabled:     settings.Enabled,
+		Endpoint:    settings.Endpoint,
+		Insecure:    settings.Insecure,
+		ServiceName: serviceName,
+		SampleRatio: settings.SampleRatio,
+		Headers:     settings.Headers,
 	}
-	return result
-}
\ No newline at end of file
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/accounting"
+	"rdma-burst/internal/services/auth"
+)
+
+// defaultAccountingRange 未指定查询范围时返回的时长
+const defaultAccountingRange = 24 * time.Hour
+
+// AccountingHandler 用量统计处理器（服务端模式）
+type AccountingHandler struct {
+	ledger *accounting.Ledger
+}
+
+// NewAccountingHandler 创建新的用量统计处理器
+func NewAccountingHandler(ledger *accounting.Ledger) *AccountingHandler {
+	return &AccountingHandler{ledger: ledger}
+}
+
+// GetAccounting 查询用量记录
+// @Summary 查询用量记录
+// @Description 按统计周期返回各租户（调用方）的传输字节数、任务数和耗时；管理员和只读调用方可以查询所有租户，其他调用方只能查询自己的用量
+// @Tags accounting
+// @Produce json
+// @Param from query string false "开始时间（RFC 3339），默认为结束时间前 24 小时"
+// @Param to query string false "结束时间（RFC 3339），默认为当前时间"
+// @Param tenant query string false "租户，为空时返回所有租户（非管理员为自己）"
+// @Success 200 {object} models.AccountingResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /api/v1/accounting [get]
+func (h *AccountingHandler) GetAccounting(c *gin.Context) {
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.invalid(c, "结束时间格式无效: "+err.Error())
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultAccountingRange)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.invalid(c, "开始时间格式无效: "+err.Error())
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		h.invalid(c, "开始时间必须早于结束时间")
+		return
+	}
+
+	// 管理员和只读调用方可以查询所有租户，其他调用方只能查询自己的用量
+	tenant := c.Query("tenant")
+	if principal, ok := auth.FromContext(c.Request.Context()); ok && !principal.CanViewAll() {
+		if tenant != "" && tenant != principal.Name {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "FORBIDDEN",
+				Message: "只有管理员可以查询其他租户的用量",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+		tenant = principal.Name
+	}
+
+	c.JSON(http.StatusOK, models.AccountingResponse{
+		Records: h.ledger.Records(from, to, tenant),
+		From:    from,
+		To:      to,
+		Period:  h.ledger.Period(),
+	})
+}
+
+// invalid 返回查询参数无效
+func (h *AccountingHandler) invalid(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   "INVALID_REQUEST",
+		Message: message,
+		Code:    http.StatusBadRequest,
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *AccountingHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/accounting", h.GetAccounting)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/audit"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/transfer"
+	"rdma-burst/pkg/logger"
+)
+
+// AdminHandler 管理员人工干预处理器，所有操作都记录审计日志
+type AdminHandler struct {
+	transferService *transfer.TransferService
+	audit           *audit.Log
+}
+
+// NewAdminHandler 创建新的管理员处理器
+func NewAdminHandler(transferService *transfer.TransferService, auditLog *audit.Log) *AdminHandler {
+	return &AdminHandler{
+		transferService: transferService,
+		audit:           auditLog,
+	}
+}
+
+// ForceCompleteRequest 强制结束任务请求
+type ForceCompleteRequest struct {
+	State  string `json:"state" binding:"omitempty,oneof=completed failed"` // 任务的最终状态，为空时已传输全部字节的任务记为完成，否则记为失败
+	Reason string `json:"reason"`                                           // 人工干预的原因，记录在任务消息和审计日志中
+}
+
+// ForceCompleteResponse 强制结束任务响应
+type ForceCompleteResponse struct {
+	PreviousStatus string               `json:"previous_status"`
+	Task           *models.TransferTask `json:"task"`
+}
+
+// ForceComplete 强制结束任务
+// @Summary 强制结束任务
+// @Description 管理员强制结束记录为活跃、但传输进程已不存在或客户端已失联的任务，释放其并发名额，并记录审计日志
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Param request body ForceCompleteRequest false "强制结束请求"
+// @Success 200 {object} ForceCompleteResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/admin/tasks/{id}/force-complete [post]
+func (h *AdminHandler) ForceComplete(c *gin.Context) {
+	principal, ok := auth.FromContext(c.Request.Context())
+	if !ok || !principal.Admin {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: "只有管理员可以强制结束任务（需要启用认证）",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	var req ForceCompleteRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INVALID_REQUEST",
+				Message: "请求参数无效: " + err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	taskID := c.Param("id")
+	task, previous, err := h.transferService.ForceComplete(c.Request.Context(), taskID, req.State, req.Reason)
+	if err != nil {
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "FORCE_COMPLETE_ERROR")
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	// 任务已经结束，审计日志写入失败只记录错误
+	if err := h.audit.Record(audit.Entry{
+		Actor:    principal.Name,
+		RemoteIP: c.ClientIP(),
+		Action:   audit.ActionForceComplete,
+		Target:   taskID,
+		Reason:   req.Reason,
+		Details: map[string]any{
+			"previous_status":   previous,
+			"status":            task.Status,
+			"bytes_transferred": task.BytesTransferred,
+			"total_bytes":       task.TotalBytes,
+			"mode":              task.Mode,
+			"direction":         task.Direction,
+		},
+	}); err != nil {
+		logger.GetLogger().Named(logger.ComponentAPI).Error("写入审计日志失败",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+	}
+
+	c.JSON(http.StatusOK, ForceCompleteResponse{
+		PreviousStatus: previous,
+		Task:           task,
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin")
+	{
+		admin.POST("/tasks/:id/force-complete", h.ForceComplete)
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/transfer"
+)
+
+// CreateBatchTransfer 批量创建传输任务
+// @Summary 批量创建传输任务
+// @Description 一次提交多个文件的传输，所有任务带有同一个批次ID标签（batch），按单个任务的规则排队和执行；单个传输创建失败时记录在对应的项中
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Param request body models.BatchTransferRequest true "批量传输请求"
+// @Success 201 {object} models.BatchTransfer
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/transfers/batch [post]
+func (h *TransferHandler) CreateBatchTransfer(c *gin.Context) {
+	var req models.BatchTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 一次性令牌只授权单个文件的传输
+	if principal, ok := auth.FromContext(c.Request.Context()); ok && principal.GrantToken != "" {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "GRANT_REJECTED",
+			Message: "一次性传输令牌不能用于批量传输",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	// 先检查所有传输，任一传输无效时不创建任何任务
+	for i := range req.Items {
+		if err := transfer.ValidateItemRequest(&req.Items[i]); err != nil {
+			respondBatchError(c, fmt.Errorf("第 %d 个传输: %w", i, err), http.StatusBadRequest, "VALIDATION_ERROR")
+			return
+		}
+	}
+
+	batch, err := transfer.SubmitBatch(c.Request.Context(), h.batchFuncs(), &req)
+	if err != nil {
+		respondBatchError(c, err, http.StatusBadRequest, "BATCH_REJECTED")
+		return
+	}
+	c.JSON(http.StatusCreated, batch)
+}
+
+// GetBatchTransfer 获取批量传输的任务状态和汇总进度
+// @Summary 获取批量传输
+// @Tags transfers
+// @Produce json
+// @Param id path string true "批次ID"
+// @Success 200 {object} models.BatchTransfer
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/transfers/batch/{id} [get]
+func (h *TransferHandler) GetBatchTransfer(c *gin.Context) {
+	batch, err := transfer.GetBatch(c.Request.Context(), h.batchFuncs(), c.Param("id"))
+	if err != nil {
+		respondBatchError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.JSON(http.StatusOK, batch)
+}
+
+// respondBatchError 按错误类型返回批量传输接口的错误响应
+func respondBatchError(c *gin.Context, err error, fallbackStatus int, fallbackCode string) {
+	var status int
+	var code string
+	var createErr *batchCreateError
+	switch {
+	case errors.Is(err, transfer.ErrBatchNotFound):
+		status, code = http.StatusNotFound, "BATCH_NOT_FOUND"
+	case errors.As(err, &createErr):
+		status, code = createErr.resp.Code, createErr.resp.Error
+	default:
+		status, code = transferErrorStatus(err, fallbackStatus, fallbackCode)
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
+
+// batchCreateError 批量传输中单个传输创建失败的错误响应，所有传输均失败时按第一个传输的错误响应返回
+type batchCreateError struct {
+	resp *models.ErrorResponse
+}
+
+func (e *batchCreateError) Error() string {
+	return fmt.Sprintf("%s: %s", e.resp.Error, e.resp.Message)
+}
+
+// batchFuncs 批量传输创建和列出任务的方式与对应的传输接口相同
+func (h *TransferHandler) batchFuncs() transfer.BatchFuncs {
+	return transfer.BatchFuncs{
+		Create: func(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, error) {
+			resp, errResp := h.create(ctx, req)
+			if errResp != nil {
+				return nil, &batchCreateError{resp: errResp}
+			}
+			return resp, nil
+		},
+		List: func(ctx context.Context, page, size int, labels map[string]string) (*models.TaskListResponse, error) {
+			if h.clientMode {
+				return h.clientService.ListTransfers(ctx, page, size, labels)
+			}
+			if h.transferService == nil {
+				return nil, fmt.Errorf("传输服务未初始化")
+			}
+			return h.transferService.ListTransfers(page, size, labels), nil
+		},
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/transfer"
+)
+
+// CreateCampaign 创建传输活动
+// @Summary 创建传输活动
+// @Description 把一组传输作为一个活动在后台分批提交，每批未结束的任务数不超过 batch_size；传输可以使用配置档案的时间窗口和截止时间，depends_on 中的 @序号 引用活动中之前的传输
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param request body models.CampaignRequest true "传输活动请求"
+// @Success 202 {object} models.Campaign
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/campaigns [post]
+func (h *TransferHandler) CreateCampaign(c *gin.Context) {
+	var req models.CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 一次性令牌只授权单个文件的传输
+	if principal, ok := auth.FromContext(c.Request.Context()); ok && principal.GrantToken != "" {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "GRANT_REJECTED",
+			Message: "一次性传输令牌不能用于传输活动",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	for i := range req.Items {
+		if err := transfer.ValidateItemRequest(&req.Items[i]); err != nil {
+			respondCampaignError(c, fmt.Errorf("第 %d 个传输: %w", i, err), http.StatusBadRequest, "VALIDATION_ERROR")
+			return
+		}
+	}
+	if err := transfer.ValidateCampaignDependencies(req.Items); err != nil {
+		respondCampaignError(c, err, http.StatusBadRequest, "INVALID_DEPENDENCY")
+		return
+	}
+
+	if h.linkMonitor != nil {
+		if up, reason := h.linkMonitor.IsUp(); !up {
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "LINK_DOWN",
+				Message: "RDMA链路不可用: " + reason,
+				Code:    http.StatusServiceUnavailable,
+			})
+			return
+		}
+	}
+
+	campaign, err := h.campaigns.Submit(c.Request.Context(), &req)
+	if err != nil {
+		respondCampaignError(c, err, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, campaign)
+}
+
+// ListCampaigns 列出传输活动
+// @Summary 列出传输活动
+// @Description 返回执行中和最近结束的传输活动，最新的在前
+// @Tags campaigns
+// @Produce json
+// @Success 200 {array} models.Campaign
+// @Router /api/v1/campaigns [get]
+func (h *TransferHandler) ListCampaigns(c *gin.Context) {
+	c.JSON(http.StatusOK, h.campaigns.List())
+}
+
+// GetCampaign 获取传输活动的状态和汇总进度
+// @Summary 获取传输活动
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "活动ID"
+// @Success 200 {object} models.Campaign
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/campaigns/{id} [get]
+func (h *TransferHandler) GetCampaign(c *gin.Context) {
+	campaign, err := h.campaigns.Get(c.Param("id"))
+	if err != nil {
+		respondCampaignError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// PauseCampaign 暂停传输活动
+// @Summary 暂停传输活动
+// @Description 不再提交新的传输，已提交的任务继续执行
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "活动ID"
+// @Success 200 {object} models.Campaign
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/campaigns/{id}/pause [post]
+func (h *TransferHandler) PauseCampaign(c *gin.Context) {
+	campaign, err := h.campaigns.Pause(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCampaignError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// ResumeCampaign 恢复传输活动
+// @Summary 恢复传输活动
+// @Description 恢复暂停的传输活动，继续按批次提交传输
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "活动ID"
+// @Success 200 {object} models.Campaign
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/campaigns/{id}/resume [post]
+func (h *TransferHandler) ResumeCampaign(c *gin.Context) {
+	campaign, err := h.campaigns.Resume(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCampaignError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// AbortCampaign 中止传输活动
+// @Summary 中止传输活动
+// @Description 取消已提交未结束的任务，未提交的传输跳过；所有任务结束后生成报告
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "活动ID"
+// @Success 200 {object} models.Campaign
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/campaigns/{id}/abort [post]
+func (h *TransferHandler) AbortCampaign(c *gin.Context) {
+	campaign, err := h.campaigns.Abort(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCampaignError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// GetCampaignReport 获取传输活动的报告
+// @Summary 获取传输活动报告
+// @Description 返回活动结束后生成的报告文件，包含每个传输的最终状态、汇总进度、耗时和平均吞吐量
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "活动ID"
+// @Success 200 {object} models.CampaignReport
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/campaigns/{id}/report [get]
+func (h *TransferHandler) GetCampaignReport(c *gin.Context) {
+	report, err := h.campaigns.Report(c.Param("id"))
+	if err != nil {
+		respondCampaignError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.Name+"-report.json"))
+	c.JSON(http.StatusOK, report)
+}
+
+// respondCampaignError 按错误类型返回传输活动接口的错误响应
+func respondCampaignError(c *gin.Context, err error, fallbackStatus int, fallbackCode string) {
+	var status int
+	var code string
+	switch {
+	case errors.Is(err, transfer.ErrCampaignNotFound):
+		status, code = http.StatusNotFound, "CAMPAIGN_NOT_FOUND"
+	case errors.Is(err, transfer.ErrCampaignEnded):
+		status, code = http.StatusConflict, "CAMPAIGN_ENDED"
+	case errors.Is(err, transfer.ErrCampaignNotEnded):
+		status, code = http.StatusConflict, "CAMPAIGN_NOT_ENDED"
+	default:
+		status, code = transferErrorStatus(err, fallbackStatus, fallbackCode)
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
+
+// campaignFuncs 传输活动创建、查询和取消任务的方式与对应的传输接口相同
+func (h *TransferHandler) campaignFuncs() transfer.CampaignFuncs {
+	return transfer.CampaignFuncs{
+		Create: func(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, error) {
+			resp, errResp := h.create(ctx, req)
+			if errResp != nil {
+				return nil, fmt.Errorf("%s: %s", errResp.Error, errResp.Message)
+			}
+			return resp, nil
+		},
+		Status: func(ctx context.Context, taskID string) (*models.ProgressResponse, error) {
+			if h.clientMode {
+				return h.clientService.GetTransferStatus(ctx, taskID)
+			}
+			if h.transferService == nil {
+				return nil, fmt.Errorf("传输服务未初始化")
+			}
+			return h.transferService.GetTransferStatus(taskID)
+		},
+		Cancel: func(ctx context.Context, taskID string) error {
+			if h.clientMode {
+				return h.clientService.CancelTransfer(ctx, taskID)
+			}
+			if h.transferService == nil {
+				return fmt.Errorf("传输服务未初始化")
+			}
+			return h.transferService.CancelTransfer(ctx, taskID)
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/utils"
+)
+
+// DeviceHandler RDMA 设备处理器
+type DeviceHandler struct{}
+
+// NewDeviceHandler 创建新的 RDMA 设备处理器
+func NewDeviceHandler() *DeviceHandler {
+	return &DeviceHandler{}
+}
+
+// ListDevices 列出 RDMA 设备及其网络接口
+// @Summary 列出RDMA设备
+// @Description 从 sysfs 读取 RDMA 设备与网络接口、IP 地址的映射
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/devices [get]
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	devices, err := utils.ListRDMADevices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "DEVICE_DISCOVERY_FAILED",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"devices":   devices,
+		"total":     len(devices),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetDevice 获取单个 RDMA 设备的网络接口映射
+// @Summary 获取RDMA设备
+// @Description 获取指定 RDMA 设备的网络接口和 IP 地址
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param name path string true "RDMA设备名称"
+// @Success 200 {object} utils.RDMADevice
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/devices/{name} [get]
+func (h *DeviceHandler) GetDevice(c *gin.Context) {
+	device, err := utils.GetRDMADevice(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "DEVICE_NOT_FOUND",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+// GetDeviceGIDs 获取 RDMA 设备的 GID 表
+// @Summary 获取RDMA设备GID表
+// @Description 获取指定 RDMA 设备各端口的有效 GID、类型及 RoCE v2 选用的源地址
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param name path string true "RDMA设备名称"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/devices/{name}/gids [get]
+func (h *DeviceHandler) GetDeviceGIDs(c *gin.Context) {
+	name := c.Param("name")
+	gids, err := utils.GetRDMAGIDs(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "DEVICE_NOT_FOUND",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	response := gin.H{
+		"device": name,
+		"gids":   gids,
+	}
+	if gid, err := utils.SelectRoCEv2GID(name); err == nil {
+		response["roce_v2"] = gid
+	}
+	if sourceIP, err := utils.GetSourceIPForRDMA(name); err == nil {
+		response["source_ip"] = sourceIP
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDeviceCapabilities 获取 RDMA 设备能力
+// @Summary 获取RDMA设备能力
+// @Description 获取设备各端口的链路速率、MTU、最大 QP 等能力，并返回 MTU 不匹配等告警
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param name path string true "RDMA设备名称"
+// @Success 200 {object} utils.RDMACapabilities
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/devices/{name}/capabilities [get]
+func (h *DeviceHandler) GetDeviceCapabilities(c *gin.Context) {
+	caps, err := utils.GetRDMACapabilities(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "DEVICE_NOT_FOUND",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"capabilities":    caps,
+		"total_rate_gbps": caps.TotalRateGbps(),
+		"timestamp":       time.Now().Format(time.RFC3339),
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *DeviceHandler) RegisterRoutes(router *gin.RouterGroup) {
+	devices := router.Group("/devices")
+	{
+		devices.GET("", h.ListDevices)
+		devices.GET("/:name", h.GetDevice)
+		devices.GET("/:name/gids", h.GetDeviceGIDs)
+		devices.GET("/:name/capabilities", h.GetDeviceCapabilities)
+	}
+}
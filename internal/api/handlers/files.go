@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/transfer"
+)
+
+// FileHandler 本地暂存处理器（服务端模式）
+type FileHandler struct {
+	stager *transfer.FileStager
+}
+
+// NewFileHandler 创建新的本地暂存处理器
+func NewFileHandler(stager *transfer.FileStager) *FileHandler {
+	return &FileHandler{stager: stager}
+}
+
+// StageFile 把服务端本机的文件复制或硬链接到传输模式目录
+// @Summary 本地暂存
+// @Description 把服务端本机 transfer.stage_source_dirs 中的文件复制或硬链接到 hugepages、tmpfs 或 filesystem 模式目录，作为 RDMA 传输前的第一步；在后台执行，通过查询接口获取进度
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body models.StageFileRequest true "本地暂存请求"
+// @Success 202 {object} models.StageJob
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/files/stage [post]
+func (h *FileHandler) StageFile(c *gin.Context) {
+	var req models.StageFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	job, err := h.stager.Stage(c.Request.Context(), &req)
+	if err != nil {
+		respondStageError(c, err, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetStageJob 获取本地暂存任务的进度
+// @Summary 获取本地暂存任务
+// @Tags files
+// @Produce json
+// @Param id path string true "暂存任务ID"
+// @Success 200 {object} models.StageJob
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/files/stage/{id} [get]
+func (h *FileHandler) GetStageJob(c *gin.Context) {
+	job, err := h.stager.Get(c.Param("id"))
+	if err != nil {
+		respondStageError(c, err, http.StatusInternalServerError, "STAGE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ListStageJobs 列出本地暂存任务
+// @Summary 列出本地暂存任务
+// @Tags files
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/files/stage [get]
+func (h *FileHandler) ListStageJobs(c *gin.Context) {
+	jobs := h.stager.List()
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"total": len(jobs),
+	})
+}
+
+// CancelStageJob 取消本地暂存任务
+// @Summary 取消本地暂存任务
+// @Tags files
+// @Produce json
+// @Param id path string true "暂存任务ID"
+// @Success 200 {object} models.StageJob
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/files/stage/{id} [delete]
+func (h *FileHandler) CancelStageJob(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.stager.Cancel(c.Request.Context(), id); err != nil {
+		respondStageError(c, err, http.StatusConflict, "STAGE_CANNOT_CANCEL")
+		return
+	}
+
+	job, _ := h.stager.Get(id)
+	c.JSON(http.StatusOK, job)
+}
+
+// respondStageError 按错误类型返回本地暂存接口的错误响应
+func respondStageError(c *gin.Context, err error, fallbackStatus int, fallbackCode string) {
+	var status int
+	var code string
+	switch {
+	case errors.Is(err, transfer.ErrStageSourceNotAllowed):
+		status, code = http.StatusForbidden, "STAGE_SOURCE_NOT_ALLOWED"
+	case errors.Is(err, transfer.ErrStageJobNotFound):
+		status, code = http.StatusNotFound, "STAGE_JOB_NOT_FOUND"
+	default:
+		status, code = transferErrorStatus(err, fallbackStatus, fallbackCode)
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *FileHandler) RegisterRoutes(router *gin.RouterGroup) {
+	files := router.Group("/files")
+	{
+		files.POST("/stage", h.StageFile)
+		files.GET("/stage", h.ListStageJobs)
+		files.GET("/stage/:id", h.GetStageJob)
+		files.DELETE("/stage/:id", h.CancelStageJob)
+	}
+}
@@ -2,23 +2,38 @@ package handlers
 
 import (
 	"net/http"
+	"os"
+	"runtime"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/alert"
+	"rdma-burst/internal/services/binaries"
+	"rdma-burst/internal/services/hugepool"
+	"rdma-burst/internal/services/leader"
+	"rdma-burst/internal/services/link"
+	"rdma-burst/internal/services/readiness"
 	"rdma-burst/internal/services/transfer"
 )
 
 // HealthHandler 健康检查处理器
 type HealthHandler struct {
-	transferService *transfer.TransferService
+	transferService transfer.Backend
 	startTime       time.Time
 	version         string
+	linkMonitor     *link.Monitor
+	alertEngine     *alert.Engine
+	elector         *leader.Elector
+	readiness       *readiness.Checker
+	prober          *readiness.Prober
+	rtranfile       *binaries.Manager
+	hugepool        *hugepool.Pool
 }
 
 // NewHealthHandler 创建新的健康检查处理器
-func NewHealthHandler(transferService *transfer.TransferService, version string) *HealthHandler {
+func NewHealthHandler(transferService transfer.Backend, version string) *HealthHandler {
 	return &HealthHandler{
 		transferService: transferService,
 		startTime:       time.Now(),
@@ -26,6 +41,41 @@ func NewHealthHandler(transferService *transfer.TransferService, version string)
 	}
 }
 
+// SetLinkMonitor 设置 RDMA 链路监控器，链路不可用时健康检查返回 degraded
+func (h *HealthHandler) SetLinkMonitor(monitor *link.Monitor) {
+	h.linkMonitor = monitor
+}
+
+// SetAlertEngine 设置告警引擎，健康检查中返回触发中的告警
+func (h *HealthHandler) SetAlertEngine(engine *alert.Engine) {
+	h.alertEngine = engine
+}
+
+// SetLeaderElector 设置协调者选主器，健康检查中返回选主状态
+func (h *HealthHandler) SetLeaderElector(elector *leader.Elector) {
+	h.elector = elector
+}
+
+// SetReadinessChecker 设置依赖就绪检查器
+func (h *HealthHandler) SetReadinessChecker(checker *readiness.Checker) {
+	h.readiness = checker
+}
+
+// SetProber 设置内部健康探测器，健康检查返回其缓存的探测结果
+func (h *HealthHandler) SetProber(prober *readiness.Prober) {
+	h.prober = prober
+}
+
+// SetRtranfileManager 设置 rtranfile 版本管理器，健康检查中返回当前使用的版本
+func (h *HealthHandler) SetRtranfileManager(manager *binaries.Manager) {
+	h.rtranfile = manager
+}
+
+// SetHugepagePool 设置大页缓冲池，健康检查中返回预留和租用的分段数
+func (h *HealthHandler) SetHugepagePool(pool *hugepool.Pool) {
+	h.hugepool = pool
+}
+
 // HealthCheck 健康检查
 // @Summary 健康检查
 // @Description 检查服务健康状态
@@ -33,6 +83,7 @@ func NewHealthHandler(transferService *transfer.TransferService, version string)
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.HealthResponse
+// @Failure 503 {object} models.HealthResponse
 // @Router /api/health [get]
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
 	uptime := time.Since(h.startTime)
@@ -51,7 +102,48 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 		"start_time":       h.startTime.Format(time.RFC3339),
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// RDMA 链路状态
+	statusCode := http.StatusOK
+	if h.linkMonitor != nil {
+		linkStatus := h.linkMonitor.Status()
+		extraInfo["rdma_link"] = linkStatus
+		extraInfo["rdma_link_events"] = h.linkMonitor.Events()
+		if !linkStatus.Up {
+			response.Status = "degraded"
+			statusCode = http.StatusServiceUnavailable
+		}
+	}
+
+	// 周期性内部健康探测的缓存结果，探测失败时状态为 degraded，但不改变状态码（就绪检查和链路状态决定是否返回 503）
+	if h.prober != nil {
+		snapshot := h.prober.Snapshot()
+		extraInfo["checks"] = snapshot
+		if !snapshot.Healthy {
+			response.Status = "degraded"
+		}
+	}
+
+	// 触发中的告警
+	if h.alertEngine != nil {
+		extraInfo["alerts"] = h.alertEngine.Alerts()
+	}
+
+	// 协调者选主状态，从属实例只提供只读 API，不影响健康状态
+	if h.elector != nil {
+		extraInfo["leader_election"] = h.elector.Status()
+	}
+
+	// 当前使用的 rtranfile 版本
+	if h.rtranfile != nil {
+		extraInfo["rtranfile"] = h.rtranfile.Active()
+	}
+
+	// 大页缓冲池的预留情况，预留不足只影响能否避免大页分配失败，不影响健康状态
+	if h.hugepool != nil {
+		extraInfo["hugepage_pool"] = h.hugepool.Status()
+	}
+
+	c.JSON(statusCode, gin.H{
 		"status":     response.Status,
 		"timestamp":  response.Timestamp,
 		"version":    response.Version,
@@ -61,23 +153,39 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 
 // ReadyCheck 就绪检查
 // @Summary 就绪检查
-// @Description 检查服务是否就绪
+// @Description 检查 rtranfile 可执行文件、日志目录、RDMA 设备及持久化存储等依赖是否就绪
 // @Tags health
 // @Accept json
 // @Produce json
-// @Success 200 {object} models.HealthResponse
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
 // @Router /api/ready [get]
 func (h *HealthHandler) ReadyCheck(c *gin.Context) {
-	// 这里可以添加更复杂的就绪检查逻辑
-	// 例如检查数据库连接、外部服务依赖等
-	
 	response := models.HealthResponse{
 		Status:    "ready",
 		Timestamp: time.Now().Format(time.RFC3339),
 		Version:   h.version,
 	}
 
-	c.JSON(http.StatusOK, response)
+	if h.readiness == nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// 任一依赖检查失败时返回 503，并给出每项检查的结果
+	result := h.readiness.Run()
+	statusCode := http.StatusOK
+	if !result.Ready {
+		response.Status = "not_ready"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, gin.H{
+		"status":    response.Status,
+		"timestamp": response.Timestamp,
+		"version":   response.Version,
+		"checks":    result.Checks,
+	})
 }
 
 // LivenessCheck 存活检查
@@ -104,30 +212,24 @@ func (h *HealthHandler) LivenessCheck(c *gin.Context) {
 // @Tags health
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /api/metrics [get]
+// @Success 200 {object} models.MetricsResponse
+// @Router /api/health/metrics [get]
 func (h *HealthHandler) Metrics(c *gin.Context) {
 	uptime := time.Since(h.startTime)
-	activeTransfers := h.transferService.GetActiveTransfers()
 
-	metrics := map[string]interface{}{
-		"service": map[string]interface{}{
-			"name":          "rdma-burst",
-			"version":       h.version,
-			"uptime_seconds": uptime.Seconds(),
-			"start_time":     h.startTime.Format(time.RFC3339),
+	c.JSON(http.StatusOK, models.MetricsResponse{
+		Service: models.ServiceMetrics{
+			Name:          "rdma-burst",
+			Version:       h.version,
+			UptimeSeconds: uptime.Seconds(),
+			StartTime:     h.startTime.Format(time.RFC3339),
 		},
-		"transfers": map[string]interface{}{
-			"active": activeTransfers,
-			"total":  h.getTotalTransfers(),
+		Transfers: models.TransferMetrics{
+			Active: h.transferService.GetActiveTransfers(),
+			Total:  h.getTotalTransfers(),
 		},
-		"system": map[string]interface{}{
-			"goroutines": getGoroutineCount(),
-			"timestamp":  time.Now().Format(time.RFC3339),
-		},
-	}
-
-	c.JSON(http.StatusOK, metrics)
+		System: systemMetrics(),
+	})
 }
 
 // getTotalTransfers 获取总传输任务数
@@ -137,17 +239,29 @@ func (h *HealthHandler) getTotalTransfers() int {
 	return 0
 }
 
-// getGoroutineCount 获取 Goroutine 数量
-func (h *HealthHandler) getGoroutineCount() int {
-	// 这里需要实现获取 Goroutine 数量的逻辑
-	// 简化实现：返回 0
-	return 0
+// systemMetrics 获取当前进程的 Goroutine、文件描述符和内存使用
+func systemMetrics() models.SystemMetrics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return models.SystemMetrics{
+		Goroutines:     runtime.NumGoroutine(),
+		OpenFDs:        openFDCount(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapObjects:    mem.HeapObjects,
+		SysBytes:       mem.Sys,
+		Timestamp:      time.Now().Format(time.RFC3339),
+	}
 }
 
-// getGoroutineCount 获取 Goroutine 数量（独立函数）
-func getGoroutineCount() int {
-	// 简化实现：返回 0
-	return 0
+// openFDCount 统计 /proc/self/fd 中的文件描述符数，不支持时返回 -1
+func openFDCount() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	// 读取目录本身占用一个描述符
+	return len(entries) - 1
 }
 
 // RegisterRoutes 注册路由
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// LoggingHandler 日志级别处理器
+type LoggingHandler struct{}
+
+// NewLoggingHandler 创建新的日志级别处理器
+func NewLoggingHandler() *LoggingHandler {
+	return &LoggingHandler{}
+}
+
+// SetLevelRequest 设置日志级别请求
+type SetLevelRequest struct {
+	Component string `json:"component"` // 为空或 default 表示默认级别
+	Level     string `json:"level" binding:"required"`
+}
+
+// GetLevels 获取各组件日志级别
+// @Summary 获取日志级别
+// @Description 获取默认及各组件（api、transfer、wrapper、monitor）的日志级别
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/logging/levels [get]
+func (h *LoggingHandler) GetLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"levels":    logger.GetLevels(),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// SetLevel 运行时调整组件日志级别
+// @Summary 设置日志级别
+// @Description 运行时调整默认或指定组件的日志级别
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Param request body SetLevelRequest true "设置日志级别请求"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/logging/levels [put]
+func (h *LoggingHandler) SetLevel(c *gin.Context) {
+	var req SetLevelRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := logger.SetLevel(req.Component, req.Level); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_LEVEL",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"levels":    logger.GetLevels(),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// ResetLevel 移除组件的单独日志级别
+// @Summary 重置组件日志级别
+// @Description 移除组件的单独级别，恢复使用默认级别
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Param component path string true "组件名称"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/logging/levels/{component} [delete]
+func (h *LoggingHandler) ResetLevel(c *gin.Context) {
+	if err := logger.ResetLevel(c.Param("component")); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "LOGGING_ERROR",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"levels":    logger.GetLevels(),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *LoggingHandler) RegisterRoutes(router *gin.RouterGroup) {
+	logging := router.Group("/logging")
+	{
+		logging.GET("/levels", h.GetLevels)
+		logging.PUT("/levels", h.SetLevel)
+		logging.DELETE("/levels/:component", h.ResetLevel)
+	}
+}
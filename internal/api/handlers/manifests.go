@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/internal/services/transfer"
+)
+
+// ManifestHandler 分块清单处理器（服务端模式）
+type ManifestHandler struct {
+	settings *models.TransferSettings
+}
+
+// NewManifestHandler 创建新的分块清单处理器
+func NewManifestHandler(settings *models.TransferSettings) *ManifestHandler {
+	return &ManifestHandler{settings: settings}
+}
+
+// VerifyManifestRequest 分块校验请求
+type VerifyManifestRequest struct {
+	Mode     string             `json:"mode" binding:"required"`
+	Manifest *manifest.Manifest `json:"manifest" binding:"required"`
+}
+
+// GetManifest 获取服务端文件的分块清单
+// @Summary 获取分块清单
+// @Description 计算（或读取缓存的）服务端文件每个分块的摘要，get 后客户端用于逐块校验
+// @Tags manifests
+// @Produce json
+// @Param filename query string true "文件名"
+// @Param mode query string true "传输模式"
+// @Param chunk_size query int false "分块大小（字节），默认 64MB"
+// @Success 200 {object} manifest.Manifest
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/manifests [get]
+func (h *ManifestHandler) GetManifest(c *gin.Context) {
+	path, ok := h.resolvePath(c, c.Query("mode"), c.Query("filename"))
+	if !ok {
+		return
+	}
+
+	chunkSize, _ := strconv.ParseInt(c.DefaultQuery("chunk_size", "0"), 10, 64)
+	m, err := manifest.LoadOrBuild(c.Request.Context(), path, chunkSize)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "FILE_NOT_FOUND",
+			Message: "生成分块清单失败: " + err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// VerifyManifest 按客户端清单逐块校验服务端文件
+// @Summary 分块校验
+// @Description put 后按客户端生成的清单逐块校验服务端文件，返回损坏的分块和需要重新传输的范围；校验通过时保存清单
+// @Tags manifests
+// @Accept json
+// @Produce json
+// @Param request body VerifyManifestRequest true "分块校验请求"
+// @Success 200 {object} manifest.Report
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/manifests/verify [post]
+func (h *ManifestHandler) VerifyManifest(c *gin.Context) {
+	var req VerifyManifestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	path, ok := h.resolvePath(c, req.Mode, req.Manifest.Filename)
+	if !ok {
+		return
+	}
+
+	report, err := manifest.Verify(c.Request.Context(), path, req.Manifest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "VERIFY_ERROR",
+			Message: "分块校验失败: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 保存源端清单，之后 get 的客户端按原始摘要校验
+	if report.OK {
+		_ = manifest.Save(manifest.PathFor(path), req.Manifest)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// resolvePath 解析服务端文件路径，失败时写入错误响应
+func (h *ManifestHandler) resolvePath(c *gin.Context, mode, filename string) (string, bool) {
+	return resolveServerPath(c, h.settings, mode, filename)
+}
+
+// resolveServerPath 解析服务端文件路径，失败时写入错误响应
+func resolveServerPath(c *gin.Context, settings *models.TransferSettings, mode, filename string) (string, bool) {
+	if settings == nil {
+		settings = transfer.DefaultSettings()
+	}
+
+	path, err := transfer.ServerFilePath(settings, mode, filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return "", false
+	}
+	return path, true
+}
+
+// RegisterRoutes 注册路由
+func (h *ManifestHandler) RegisterRoutes(router *gin.RouterGroup) {
+	manifests := router.Group("/manifests")
+	{
+		manifests.GET("", h.GetManifest)
+		manifests.POST("/verify", h.VerifyManifest)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/filemeta"
+)
+
+// MetadataHandler 文件元数据处理器（服务端模式）
+type MetadataHandler struct {
+	settings *models.TransferSettings
+}
+
+// NewMetadataHandler 创建新的文件元数据处理器
+func NewMetadataHandler(settings *models.TransferSettings) *MetadataHandler {
+	return &MetadataHandler{settings: settings}
+}
+
+// ApplyMetadataRequest 恢复文件元数据请求
+type ApplyMetadataRequest struct {
+	Mode     string             `json:"mode" binding:"required"`
+	Metadata *filemeta.Metadata `json:"metadata" binding:"required"`
+}
+
+// GetMetadata 获取服务端文件的元数据
+// @Summary 获取文件元数据
+// @Description 获取服务端文件的权限、修改时间和匹配的扩展属性，get 后客户端用于恢复本地文件的元数据
+// @Tags metadata
+// @Produce json
+// @Param filename query string true "文件名"
+// @Param mode query string true "传输模式"
+// @Param xattrs query string false "扩展属性名模式，逗号分隔，例如 user.*"
+// @Success 200 {object} filemeta.Metadata
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/metadata [get]
+func (h *MetadataHandler) GetMetadata(c *gin.Context) {
+	path, ok := resolveServerPath(c, h.settings, c.Query("mode"), c.Query("filename"))
+	if !ok {
+		return
+	}
+
+	var patterns []string
+	if xattrs := c.Query("xattrs"); xattrs != "" {
+		patterns = strings.Split(xattrs, ",")
+	}
+
+	m, err := filemeta.Capture(path, patterns)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "FILE_NOT_FOUND",
+			Message: "读取文件元数据失败: " + err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// ApplyMetadata 将源文件的元数据恢复到服务端文件
+// @Summary 恢复文件元数据
+// @Description put 后按客户端源文件的元数据设置服务端文件的权限、修改时间和扩展属性
+// @Tags metadata
+// @Accept json
+// @Produce json
+// @Param request body ApplyMetadataRequest true "恢复文件元数据请求"
+// @Success 200 {object} filemeta.Metadata
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/metadata [put]
+func (h *MetadataHandler) ApplyMetadata(c *gin.Context) {
+	var req ApplyMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	path, ok := resolveServerPath(c, h.settings, req.Mode, req.Metadata.Filename)
+	if !ok {
+		return
+	}
+
+	if err := filemeta.Apply(path, req.Metadata); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "METADATA_ERROR",
+			Message: "恢复文件元数据失败: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, req.Metadata)
+}
+
+// RegisterRoutes 注册路由
+func (h *MetadataHandler) RegisterRoutes(router *gin.RouterGroup) {
+	metadata := router.Group("/metadata")
+	{
+		metadata.GET("", h.GetMetadata)
+		metadata.PUT("", h.ApplyMetadata)
+	}
+}
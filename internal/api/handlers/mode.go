@@ -1,17 +1,29 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/config"
+	"rdma-burst/internal/services/detect"
+	"rdma-burst/internal/services/handoff"
 )
 
 // ModeHandler 模式检测处理器
 type ModeHandler struct {
-	startTime time.Time
-	version   string
-	mode      string
+	startTime  time.Time
+	version    string
+	mode       string
+	detector   *detect.Detector     // 模式检测器（可选）
+	detection  *detect.Result       // 启动时的检测结果
+	handoff    *handoff.Coordinator // 运行时角色切换（仅统一模式）
+	configFile string               // 切换模式时写入运行模式的配置文件
+	switchMu   sync.Mutex           // 串行化切换模式时写入配置文件、请求切换和切换被拒绝时恢复配置文件
 }
 
 // NewModeHandler 创建新的模式检测处理器
@@ -23,13 +35,35 @@ func NewModeHandler(version string, mode string) *ModeHandler {
 	}
 }
 
+// NewModeHandlerWithDetection 使用模式检测器创建模式检测处理器
+func NewModeHandlerWithDetection(version string, mode string, detector *detect.Detector, detection *detect.Result) *ModeHandler {
+	return &ModeHandler{
+		startTime: time.Now(),
+		version:   version,
+		mode:      mode,
+		detector:  detector,
+		detection: detection,
+	}
+}
+
+// SetHandoff 设置运行时角色切换协调器
+func (h *ModeHandler) SetHandoff(coordinator *handoff.Coordinator) {
+	h.handoff = coordinator
+}
+
+// SetConfigFile 设置切换模式时写入运行模式的配置文件
+func (h *ModeHandler) SetConfigFile(path string) {
+	h.configFile = path
+}
+
 // ModeResponse 模式检测响应
 type ModeResponse struct {
-	Mode      string `json:"mode"`
-	Version   string `json:"version"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Uptime    string `json:"uptime"`
+	Mode      string         `json:"mode"`
+	Version   string         `json:"version"`
+	Status    string         `json:"status"`
+	Timestamp string         `json:"timestamp"`
+	Uptime    string         `json:"uptime"`
+	Detection *detect.Result `json:"detection,omitempty"`
 }
 
 // GetMode 获取当前运行模式
@@ -49,6 +83,7 @@ func (h *ModeHandler) GetMode(c *gin.Context) {
 		Status:    "running",
 		Timestamp: time.Now().Format(time.RFC3339),
 		Uptime:    uptime.String(),
+		Detection: h.detection,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -64,16 +99,17 @@ func (h *ModeHandler) GetMode(c *gin.Context) {
 // @Router /api/v1/mode/detect [get]
 func (h *ModeHandler) DetectMode(c *gin.Context) {
 	// 检测运行模式逻辑
-	detectedMode := h.detectRuntimeMode()
+	detection := h.detectRuntimeMode()
 
 	uptime := time.Since(h.startTime)
 
 	response := ModeResponse{
-		Mode:      detectedMode,
+		Mode:      detection.Mode,
 		Version:   h.version,
 		Status:    "detected",
 		Timestamp: time.Now().Format(time.RFC3339),
 		Uptime:    uptime.String(),
+		Detection: detection,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -81,13 +117,18 @@ func (h *ModeHandler) DetectMode(c *gin.Context) {
 
 // SwitchMode 切换运行模式
 // @Summary 切换运行模式
-// @Description 请求切换运行模式（需要重启服务）
+// @Description 将运行模式写入配置文件，按 apply 在排空后于进程内切换角色或重启进程，未指定 apply 时下次启动生效（仅管理员）
 // @Tags mode
 // @Accept json
 // @Produce json
 // @Param request body SwitchModeRequest true "切换模式请求"
 // @Success 200 {object} SwitchModeResponse
+// @Success 202 {object} SwitchModeResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/v1/mode/switch [post]
 func (h *ModeHandler) SwitchMode(c *gin.Context) {
 	var req SwitchModeRequest
@@ -115,17 +156,133 @@ func (h *ModeHandler) SwitchMode(c *gin.Context) {
 		})
 		return
 	}
+	if req.Apply == SwitchApplyHandoff && req.Mode == "auto" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_MODE",
+			Message: "进程内切换角色的目标必须是 server 或 client，切换为自动检测请使用 apply=restart",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
 
-	// 这里可以实现配置更新逻辑
-	// 实际模式切换需要重启服务
+	principal, ok := auth.FromContext(c.Request.Context())
+	if !ok || !principal.Admin {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: "只有管理员可以切换运行模式（需要启用认证）",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	if req.Apply != "" && h.handoff == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "HANDOFF_UNAVAILABLE",
+			Message: "只有统一模式（rdma-burst）支持运行时切换角色或重启",
+			Code:    http.StatusServiceUnavailable,
+		})
+		return
+	}
 
+	// 写入配置文件，之后启动时按新的模式运行；重启可能在排空后立即执行，因此在请求切换前写入，切换请求被拒绝时恢复
+	// 同时切换时被拒绝的请求不能把配置文件恢复为另一个请求写入之前的内容，写入到恢复期间持有锁
+	h.switchMu.Lock()
+	defer h.switchMu.Unlock()
 	response := SwitchModeResponse{
 		CurrentMode: h.mode,
 		TargetMode:  req.Mode,
-		Message:     "模式切换请求已接受，需要重启服务生效",
-		RestartRequired: true,
+		Apply:       req.Apply,
+		ConfigFile:  h.configFile,
+	}
+	var restore func() error
+	if h.configFile != "" {
+		var err error
+		if restore, err = config.PersistMode(h.configFile, req.Mode); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "CONFIG_PERSIST_FAILED",
+				Message: "写入运行模式失败: " + err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		response.Persisted = true
+	}
+
+	if req.Apply == "" {
+		response.RestartRequired = true
+		response.Message = "模式切换请求已接受，需要重启服务生效"
+		if response.Persisted {
+			response.Message = "运行模式已写入配置文件，重启服务后生效"
+		}
+		response.Timestamp = time.Now().Format(time.RFC3339)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	err := h.handoff.Request(handoff.Request{
+		Target:  req.Mode,
+		Reason:  "管理员 " + principal.Name + " 请求切换运行模式",
+		Source:  handoff.SourceAPI,
+		Restart: req.Apply == SwitchApplyRestart,
+	})
+	if err != nil && restore != nil {
+		if restoreErr := restore(); restoreErr != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "CONFIG_PERSIST_FAILED",
+				Message: "切换被拒绝（" + err.Error() + "），恢复配置文件中的运行模式失败: " + restoreErr.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+	switch {
+	case errors.Is(err, handoff.ErrSameMode), errors.Is(err, handoff.ErrInProgress):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "HANDOFF_CONFLICT",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_MODE",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	status := h.handoff.Status()
+	response.Handoff = &status
+	response.Message = "模式切换已开始，正在排空当前角色的传输，通过 GET /api/v1/mode/switch 查询进度"
+	response.Timestamp = time.Now().Format(time.RFC3339)
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetSwitchStatus 获取运行模式切换进度
+// @Summary 获取模式切换进度
+// @Description 获取配置文件中的运行模式和进行中的角色切换或重启的阶段
+// @Tags mode
+// @Produce json
+// @Success 200 {object} SwitchStatusResponse
+// @Router /api/v1/mode/switch [get]
+func (h *ModeHandler) GetSwitchStatus(c *gin.Context) {
+	response := SwitchStatusResponse{
+		CurrentMode: h.mode,
+		ConfigFile:  h.configFile,
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
+	if h.configFile != "" {
+		mode, err := config.ReadMode(h.configFile)
+		if err != nil {
+			response.ConfigError = err.Error()
+		}
+		response.ConfigMode = mode
+	}
+	if h.handoff != nil {
+		status := h.handoff.Status()
+		response.Handoff = &status
+	}
 
 	c.JSON(http.StatusOK, response)
 }
@@ -141,10 +298,12 @@ func (h *ModeHandler) SwitchMode(c *gin.Context) {
 func (h *ModeHandler) GetModeStatus(c *gin.Context) {
 	uptime := time.Since(h.startTime)
 
+	detection := h.detectRuntimeMode()
+
 	status := map[string]interface{}{
 		"mode": map[string]interface{}{
 			"current": h.mode,
-			"detected": h.detectRuntimeMode(),
+			"detected": detection.Mode,
 			"supported_modes": []string{"server", "client", "auto"},
 		},
 		"service": map[string]interface{}{
@@ -154,42 +313,180 @@ func (h *ModeHandler) GetModeStatus(c *gin.Context) {
 			"status":     "running",
 		},
 		"detection": map[string]interface{}{
-			"method": "health_check",
-			"timeout": "3s",
-			"endpoint": "http://localhost:8080/api/health",
+			"startup": h.detection,
+			"current": detection,
 		},
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
+	if h.handoff != nil {
+		status["handoff"] = h.handoff.Status()
+	}
 
 	c.JSON(http.StatusOK, status)
 }
 
+// GetHandoff 获取运行时角色切换状态
+// @Summary 获取角色切换状态
+// @Description 获取统一模式运行时角色切换的阶段和最近一次切换请求
+// @Tags mode
+// @Produce json
+// @Success 200 {object} handoff.Status
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/mode/handoff [get]
+func (h *ModeHandler) GetHandoff(c *gin.Context) {
+	if h.handoff == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "HANDOFF_UNAVAILABLE",
+			Message: "只有统一模式（rdma-burst）支持运行时角色切换",
+			Code:    http.StatusServiceUnavailable,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.handoff.Status())
+}
+
+// RequestHandoff 请求运行时角色切换
+// @Summary 请求角色切换
+// @Description 排空当前角色的传输并关闭服务后按目标角色重新初始化，不重启进程（仅管理员）
+// @Tags mode
+// @Accept json
+// @Produce json
+// @Param request body HandoffRequest true "角色切换请求"
+// @Success 202 {object} handoff.Status
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/mode/handoff [post]
+func (h *ModeHandler) RequestHandoff(c *gin.Context) {
+	principal, ok := auth.FromContext(c.Request.Context())
+	if !ok || !principal.Admin {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: "只有管理员可以切换运行角色（需要启用认证）",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	if h.handoff == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "HANDOFF_UNAVAILABLE",
+			Message: "只有统一模式（rdma-burst）支持运行时角色切换",
+			Code:    http.StatusServiceUnavailable,
+		})
+		return
+	}
+
+	var req HandoffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "管理员 " + principal.Name + " 请求切换运行角色"
+	}
+	err := h.handoff.Request(handoff.Request{
+		Target: req.Mode,
+		Peer:   req.Peer,
+		Reason: reason,
+		Source: handoff.SourceAPI,
+	})
+	switch {
+	case errors.Is(err, handoff.ErrInvalidMode):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_MODE",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	case errors.Is(err, handoff.ErrSameMode), errors.Is(err, handoff.ErrInProgress):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "HANDOFF_CONFLICT",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, h.handoff.Status())
+}
+
 // detectRuntimeMode 检测运行模式
-func (h *ModeHandler) detectRuntimeMode() string {
-	// 尝试连接本地服务端
+func (h *ModeHandler) detectRuntimeMode() *detect.Result {
+	if h.detector != nil {
+		return h.detector.Detect()
+	}
+
+	// 未配置检测器时回退为探测本地服务端
 	client := &http.Client{Timeout: 3 * time.Second}
 	url := "http://localhost:8080/api/health"
 
 	resp, err := client.Get(url)
-	if err == nil && resp.StatusCode == http.StatusOK {
-		return "client"
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return detect.NewFixedResult(detect.ModeClient, detect.SourceLocalProbe, "检测到本地运行中的服务端 "+url)
+		}
 	}
 
-	return "server"
+	return detect.NewFixedResult(detect.ModeServer, detect.SourceDefault, "未检测到本地运行中的服务端")
 }
 
+// 模式切换的生效方式
+const (
+	SwitchApplyHandoff = "handoff" // 排空后在进程内切换角色
+	SwitchApplyRestart = "restart" // 排空后重新执行进程
+)
+
 // SwitchModeRequest 切换模式请求
 type SwitchModeRequest struct {
-	Mode string `json:"mode" binding:"required,oneof=server client auto"`
+	Mode  string `json:"mode" binding:"required,oneof=server client auto"`
+	Apply string `json:"apply,omitempty" binding:"omitempty,oneof=handoff restart"` // 为空时只写入配置文件，下次启动生效
+}
+
+// HandoffRequest 角色切换请求
+type HandoffRequest struct {
+	Mode   string `json:"mode" binding:"required,oneof=server client"`
+	Peer   string `json:"peer,omitempty"` // 切换为客户端时连接的服务端地址（host:port），为空时使用配置的地址
+	Reason string `json:"reason,omitempty"`
 }
 
 // SwitchModeResponse 切换模式响应
 type SwitchModeResponse struct {
-	CurrentMode     string `json:"current_mode"`
-	TargetMode      string `json:"target_mode"`
-	Message         string `json:"message"`
-	RestartRequired bool   `json:"restart_required"`
-	Timestamp       string `json:"timestamp"`
+	CurrentMode     string          `json:"current_mode"`
+	TargetMode      string          `json:"target_mode"`
+	Apply           string          `json:"apply,omitempty"`
+	Message         string          `json:"message"`
+	Persisted       bool            `json:"persisted"` // 是否已写入配置文件
+	ConfigFile      string          `json:"config_file,omitempty"`
+	RestartRequired bool            `json:"restart_required"`
+	Handoff         *handoff.Status `json:"handoff,omitempty"` // 切换进度，通过 GET /api/v1/mode/switch 查询后续进度
+	Timestamp       string          `json:"timestamp"`
+}
+
+// SwitchStatusResponse 模式切换进度响应
+type SwitchStatusResponse struct {
+	CurrentMode string          `json:"current_mode"`
+	ConfigMode  string          `json:"config_mode,omitempty"` // 配置文件中的运行模式，下次启动时使用
+	ConfigFile  string          `json:"config_file,omitempty"`
+	ConfigError string          `json:"config_error,omitempty"`
+	Handoff     *handoff.Status `json:"handoff,omitempty"`
+	Timestamp   string          `json:"timestamp"`
 }
 
 // ErrorResponse 错误响应
@@ -206,6 +503,9 @@ func (h *ModeHandler) RegisterRoutes(router *gin.RouterGroup) {
 		mode.GET("", h.GetMode)
 		mode.GET("/detect", h.DetectMode)
 		mode.GET("/status", h.GetModeStatus)
+		mode.GET("/switch", h.GetSwitchStatus)
 		mode.POST("/switch", h.SwitchMode)
+		mode.GET("/handoff", h.GetHandoff)
+		mode.POST("/handoff", h.RequestHandoff)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/link"
+	"rdma-burst/internal/services/transfer"
+)
+
+// PipelineHandler 流水线传输处理器（客户端模式）
+type PipelineHandler struct {
+	runner      *transfer.PipelineRunner
+	settings    *models.TransferSettings // 映射 source_path / destination_path 使用的客户端配置
+	linkMonitor *link.Monitor
+}
+
+// NewPipelineHandler 创建新的流水线传输处理器
+func NewPipelineHandler(runner *transfer.PipelineRunner, settings *models.TransferSettings) *PipelineHandler {
+	if settings == nil {
+		settings = transfer.DefaultSettings()
+	}
+	return &PipelineHandler{runner: runner, settings: settings}
+}
+
+// SetLinkMonitor 设置 RDMA 链路监控器，链路不可用时拒绝新的流水线
+func (h *PipelineHandler) SetLinkMonitor(monitor *link.Monitor) {
+	h.linkMonitor = monitor
+}
+
+// CreatePipeline 创建流水线传输
+// @Summary 创建流水线传输
+// @Description 按顺序传输一组文件，RDMA 传输依次执行，后续文件的暂存和校验和计算、已传输文件的校验和落盘与当前文件的传输重叠进行；在后台执行，通过查询接口获取各文件所处的阶段
+// @Tags pipelines
+// @Accept json
+// @Produce json
+// @Param request body models.PipelineRequest true "流水线传输请求"
+// @Success 202 {object} models.Pipeline
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/pipelines [post]
+func (h *PipelineHandler) CreatePipeline(c *gin.Context) {
+	var req models.PipelineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 一次性令牌只授权单个文件的传输
+	if principal, ok := auth.FromContext(c.Request.Context()); ok && principal.GrantToken != "" {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "GRANT_REJECTED",
+			Message: "一次性传输令牌不能用于流水线传输",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	for i := range req.Items {
+		item := &req.Items[i]
+		if err := transfer.ValidateRequest(item); err != nil {
+			respondPipelineError(c, fmt.Errorf("第 %d 个文件: %w", i, err), http.StatusBadRequest, "VALIDATION_ERROR")
+			return
+		}
+		if err := transfer.MapRequestPaths(item, h.settings, false); err != nil {
+			respondPipelineError(c, fmt.Errorf("第 %d 个文件: %w", i, err), http.StatusBadRequest, "VALIDATION_ERROR")
+			return
+		}
+	}
+
+	if h.linkMonitor != nil {
+		if up, reason := h.linkMonitor.IsUp(); !up {
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "LINK_DOWN",
+				Message: "RDMA链路不可用: " + reason,
+				Code:    http.StatusServiceUnavailable,
+			})
+			return
+		}
+	}
+
+	pipeline, err := h.runner.Submit(c.Request.Context(), &req)
+	if err != nil {
+		respondPipelineError(c, err, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, pipeline)
+}
+
+// ListPipelines 列出流水线传输
+// @Summary 列出流水线传输
+// @Description 返回执行中和最近结束的流水线，最新的在前
+// @Tags pipelines
+// @Produce json
+// @Success 200 {array} models.Pipeline
+// @Router /api/v1/pipelines [get]
+func (h *PipelineHandler) ListPipelines(c *gin.Context) {
+	c.JSON(http.StatusOK, h.runner.List())
+}
+
+// GetPipeline 获取流水线传输的进度
+// @Summary 获取流水线传输
+// @Tags pipelines
+// @Produce json
+// @Param id path string true "流水线ID"
+// @Success 200 {object} models.Pipeline
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/pipelines/{id} [get]
+func (h *PipelineHandler) GetPipeline(c *gin.Context) {
+	pipeline, err := h.runner.Get(c.Param("id"))
+	if err != nil {
+		respondPipelineError(c, err, http.StatusInternalServerError, "INTERNAL_ERROR")
+		return
+	}
+	c.JSON(http.StatusOK, pipeline)
+}
+
+// CancelPipeline 取消流水线传输
+// @Summary 取消流水线传输
+// @Description 停止正在传输的文件并向服务端上报失败，尚未开始传输的文件不再传输，已完成的文件保留
+// @Tags pipelines
+// @Produce json
+// @Param id path string true "流水线ID"
+// @Success 200 {object} models.Pipeline
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/pipelines/{id} [delete]
+func (h *PipelineHandler) CancelPipeline(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.runner.Cancel(c.Request.Context(), id); err != nil {
+		respondPipelineError(c, err, http.StatusConflict, "PIPELINE_CANNOT_CANCEL")
+		return
+	}
+
+	pipeline, _ := h.runner.Get(id)
+	c.JSON(http.StatusOK, pipeline)
+}
+
+// respondPipelineError 按错误类型返回流水线接口的错误响应
+func respondPipelineError(c *gin.Context, err error, fallbackStatus int, fallbackCode string) {
+	var status int
+	var code string
+	switch {
+	case errors.Is(err, transfer.ErrPipelineNotFound):
+		status, code = http.StatusNotFound, "PIPELINE_NOT_FOUND"
+	default:
+		status, code = transferErrorStatus(err, fallbackStatus, fallbackCode)
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *PipelineHandler) RegisterRoutes(router *gin.RouterGroup) {
+	pipelines := router.Group("/pipelines")
+	{
+		pipelines.POST("", h.CreatePipeline)
+		pipelines.GET("", h.ListPipelines)
+		pipelines.GET("/:id", h.GetPipeline)
+		pipelines.DELETE("/:id", h.CancelPipeline)
+	}
+}
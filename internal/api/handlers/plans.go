@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/transfer"
+)
+
+// planLabel 导入传输计划时添加到每个任务的计划名称标签
+const planLabel = "plan"
+
+// EstimatePlan 估算传输计划
+// @Summary 估算传输计划
+// @Description 按当前配置检查计划中的每个传输并选择传输模式，按最近完成的传输的吞吐量估算耗时，不创建任务
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param request body models.TransferPlan true "传输计划"
+// @Success 200 {object} models.PlanEstimate
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/plans/estimate [post]
+func (h *TransferHandler) EstimatePlan(c *gin.Context) {
+	plan, ok := h.bindPlan(c)
+	if !ok {
+		return
+	}
+
+	estimate, errResp := h.estimate(c, plan)
+	if errResp != nil {
+		c.JSON(errResp.Code, errResp)
+		return
+	}
+	c.JSON(http.StatusOK, estimate)
+}
+
+// ExportPlan 导出传输计划
+// @Summary 导出传输计划
+// @Description 返回带有版本、创建时间和估算结果的传输计划文件，之后可以通过导入接口执行
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param request body models.TransferPlan true "传输计划"
+// @Success 200 {object} models.TransferPlan
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/plans/export [post]
+func (h *TransferHandler) ExportPlan(c *gin.Context) {
+	plan, ok := h.bindPlan(c)
+	if !ok {
+		return
+	}
+
+	estimate, errResp := h.estimate(c, plan)
+	if errResp != nil {
+		c.JSON(errResp.Code, errResp)
+		return
+	}
+
+	now := time.Now()
+	plan.Version = models.PlanVersion
+	if plan.CreatedAt == nil {
+		plan.CreatedAt = &now
+	}
+	plan.Estimate = estimate
+
+	name := plan.Name
+	if name == "" {
+		name = "plan"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+	c.JSON(http.StatusOK, plan)
+}
+
+// ImportPlan 导入并执行传输计划
+// @Summary 导入传输计划
+// @Description 按顺序为计划中的每个传输创建任务，与逐个调用创建接口相同；depends_on 中的 @序号 引用计划中之前的传输，单个传输失败只影响依赖它的传输
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param request body models.TransferPlan true "传输计划"
+// @Success 200 {object} models.PlanImportResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/plans/import [post]
+func (h *TransferHandler) ImportPlan(c *gin.Context) {
+	plan, ok := h.bindPlan(c)
+	if !ok {
+		return
+	}
+
+	response := models.PlanImportResponse{
+		Results: make([]models.PlanImportResult, 0, len(plan.Items)),
+	}
+	for i := range plan.Items {
+		req := plan.Items[i]
+		if plan.Name != "" {
+			labels := make(map[string]string, len(req.Labels)+1)
+			for key, value := range req.Labels {
+				labels[key] = value
+			}
+			if _, exists := labels[planLabel]; !exists {
+				labels[planLabel] = plan.Name
+			}
+			req.Labels = labels
+		}
+
+		result := models.PlanImportResult{Index: i, Filename: req.Filename}
+		errResp := resolvePlanDependencies(&req, i, response.Results)
+		var task *models.TransferResponse
+		if errResp == nil {
+			task, errResp = h.create(c.Request.Context(), &req)
+		}
+		if errResp != nil {
+			result.Error = errResp
+			response.Failed++
+		} else {
+			result.Task = task
+			response.Created++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// resolvePlanDependencies 把依赖中以 @序号 引用的计划中之前的传输替换为已创建的任务 ID
+// 引用的传输创建失败时该传输也不创建
+func resolvePlanDependencies(req *models.TransferRequest, index int, results []models.PlanImportResult) *models.ErrorResponse {
+	if len(req.DependsOn) == 0 {
+		return nil
+	}
+	dependsOn := make([]string, 0, len(req.DependsOn))
+	for _, dep := range req.DependsOn {
+		ref, ok := strings.CutPrefix(dep, "@")
+		if !ok {
+			dependsOn = append(dependsOn, dep)
+			continue
+		}
+		i, err := strconv.Atoi(ref)
+		if err != nil || i < 0 || i >= index {
+			return &models.ErrorResponse{
+				Error:   "INVALID_DEPENDENCY",
+				Message: fmt.Sprintf("依赖 %s 必须引用计划中之前的传输", dep),
+				Code:    http.StatusBadRequest,
+			}
+		}
+		if results[i].Task == nil {
+			return &models.ErrorResponse{
+				Error:   "DEPENDENCY_FAILED",
+				Message: fmt.Sprintf("依赖的第 %d 个传输创建失败", i),
+				Code:    http.StatusConflict,
+			}
+		}
+		dependsOn = append(dependsOn, results[i].Task.ID)
+	}
+	req.DependsOn = dependsOn
+	return nil
+}
+
+// bindPlan 绑定传输计划并检查格式版本，失败时已写入错误响应
+func (h *TransferHandler) bindPlan(c *gin.Context) (*models.TransferPlan, bool) {
+	var plan models.TransferPlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return nil, false
+	}
+	if plan.Version > models.PlanVersion {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "UNSUPPORTED_PLAN_VERSION",
+			Message: fmt.Sprintf("不支持的传输计划版本: %d（支持的最高版本为 %d）", plan.Version, models.PlanVersion),
+			Code:    http.StatusBadRequest,
+		})
+		return nil, false
+	}
+	return &plan, true
+}
+
+// estimate 估算传输计划，客户端模式下映射本地路径后由服务端估算
+func (h *TransferHandler) estimate(c *gin.Context, plan *models.TransferPlan) (*models.PlanEstimate, *models.ErrorResponse) {
+	if h.clientMode {
+		estimate, err := h.clientService.EstimatePlan(c.Request.Context(), plan)
+		if err != nil {
+			status, code := transferErrorStatus(err, http.StatusInternalServerError, "CLIENT_TRANSFER_ERROR")
+			return nil, &models.ErrorResponse{
+				Error:   code,
+				Message: "客户端调用服务端API失败: " + err.Error(),
+				Code:    status,
+			}
+		}
+		return estimate, nil
+	}
+
+	settings := h.serverConfig
+	if settings == nil {
+		settings = transfer.DefaultSettings()
+	}
+	return h.transferService.EstimatePlan(c.Request.Context(), plan, settings), nil
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/audit"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/binaries"
+	"rdma-burst/pkg/logger"
+)
+
+// 上传新版本时携带校验和与签名的请求头
+const (
+	RtranfileSHA256Header    = "X-Rtranfile-SHA256"
+	RtranfileSignatureHeader = "X-Rtranfile-Signature"
+)
+
+// RtranfileHandler rtranfile 版本管理处理器，仅管理员可调用，保存和切换版本都记录审计日志
+type RtranfileHandler struct {
+	manager *binaries.Manager
+	audit   *audit.Log
+}
+
+// NewRtranfileHandler 创建新的 rtranfile 版本管理处理器
+func NewRtranfileHandler(manager *binaries.Manager, auditLog *audit.Log) *RtranfileHandler {
+	return &RtranfileHandler{
+		manager: manager,
+		audit:   auditLog,
+	}
+}
+
+// StageRtranfileRequest 从路径或 URL 保存新版本的请求
+type StageRtranfileRequest struct {
+	Version   string `json:"version" binding:"required"`
+	Source    string `json:"source" binding:"required"` // http(s) URL 或服务端本机的绝对路径
+	SHA256    string `json:"sha256" binding:"required"`
+	Signature string `json:"signature,omitempty"` // base64 编码的 Ed25519 签名（对 SHA-256 摘要签名）
+	Activate  bool   `json:"activate,omitempty"`  // 保存后立即切换到该版本
+}
+
+// RtranfileVersionsResponse rtranfile 版本列表响应
+type RtranfileVersionsResponse struct {
+	Active   binaries.Active    `json:"active"`
+	Versions []binaries.Version `json:"versions"`
+}
+
+// StageRtranfileResponse 保存新版本响应
+type StageRtranfileResponse struct {
+	Version *binaries.Version `json:"version"`
+	Active  binaries.Active   `json:"active"`
+}
+
+// ActivateRtranfileResponse 切换版本响应
+type ActivateRtranfileResponse struct {
+	Previous string          `json:"previous"`
+	Active   binaries.Active `json:"active"`
+}
+
+// ListVersions 获取 rtranfile 版本
+// @Summary 获取 rtranfile 版本
+// @Description 获取当前使用的 rtranfile 版本和已保存的版本（仅管理员）
+// @Tags admin
+// @Produce json
+// @Success 200 {object} RtranfileVersionsResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /api/v1/admin/rtranfile [get]
+func (h *RtranfileHandler) ListVersions(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+
+	versions, err := h.manager.Versions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RtranfileVersionsResponse{
+		Active:   h.manager.Active(),
+		Versions: versions,
+	})
+}
+
+// StageFromSource 从路径或 URL 保存新版本
+// @Summary 从路径或 URL 保存 rtranfile 新版本
+// @Description 读取服务端本机路径或下载 URL 中的 rtranfile，校验 SHA-256 和签名后保存，可选立即切换（仅管理员）
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body StageRtranfileRequest true "保存新版本请求"
+// @Success 201 {object} StageRtranfileResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /api/v1/admin/rtranfile/versions [post]
+func (h *RtranfileHandler) StageFromSource(c *gin.Context) {
+	principal, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req StageRtranfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	body, err := h.manager.Fetch(c.Request.Context(), req.Source)
+	if err != nil {
+		h.stageError(c, err)
+		return
+	}
+	defer body.Close()
+
+	h.stage(c, principal, binaries.StageRequest{
+		Version:   req.Version,
+		SHA256:    req.SHA256,
+		Signature: req.Signature,
+		Source:    req.Source,
+		StagedBy:  principal.Name,
+	}, body, req.Activate)
+}
+
+// Upload 上传新版本
+// @Summary 上传 rtranfile 新版本
+// @Description 请求体为 rtranfile 二进制文件，X-Rtranfile-SHA256 为其 SHA-256，X-Rtranfile-Signature 为可选的签名；校验通过后保存，activate=true 时立即切换（仅管理员）
+// @Tags admin
+// @Accept application/octet-stream
+// @Produce json
+// @Param version path string true "版本号"
+// @Param activate query bool false "保存后立即切换到该版本"
+// @Success 201 {object} StageRtranfileResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Router /api/v1/admin/rtranfile/versions/{version} [put]
+func (h *RtranfileHandler) Upload(c *gin.Context) {
+	principal, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+
+	activate, _ := strconv.ParseBool(c.Query("activate"))
+	h.stage(c, principal, binaries.StageRequest{
+		Version:   c.Param("version"),
+		SHA256:    c.GetHeader(RtranfileSHA256Header),
+		Signature: c.GetHeader(RtranfileSignatureHeader),
+		Source:    "upload",
+		StagedBy:  principal.Name,
+	}, c.Request.Body, activate)
+}
+
+// Activate 切换 rtranfile 版本
+// @Summary 切换 rtranfile 版本
+// @Description 原子地切换之后启动的 rtranfile 进程使用的版本，已启动的进程继续使用旧版本；版本为 external 时切换回启用版本管理前使用的 rtranfile（仅管理员）
+// @Tags admin
+// @Produce json
+// @Param version path string true "版本号"
+// @Success 200 {object} ActivateRtranfileResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/admin/rtranfile/versions/{version}/activate [post]
+func (h *RtranfileHandler) Activate(c *gin.Context) {
+	principal, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+
+	active, previous, err := h.manager.Activate(c.Param("version"))
+	if err != nil {
+		h.stageError(c, err)
+		return
+	}
+	h.record(c, principal, audit.ActionActivateRtranfile, active.Version, map[string]any{
+		"previous": previous,
+		"sha256":   active.SHA256,
+	})
+
+	c.JSON(http.StatusOK, ActivateRtranfileResponse{
+		Previous: previous,
+		Active:   active,
+	})
+}
+
+// stage 保存新版本，activate 为 true 时随后切换到该版本
+func (h *RtranfileHandler) stage(c *gin.Context, principal *auth.Principal, req binaries.StageRequest, body io.Reader, activate bool) {
+	version, err := h.manager.Stage(req, body)
+	if err != nil {
+		h.stageError(c, err)
+		return
+	}
+	h.record(c, principal, audit.ActionStageRtranfile, version.Version, map[string]any{
+		"sha256": version.SHA256,
+		"size":   version.Size,
+		"signed": version.Signed,
+		"source": version.Source,
+	})
+
+	if activate {
+		active, previous, err := h.manager.Activate(version.Version)
+		if err != nil {
+			h.stageError(c, err)
+			return
+		}
+		h.record(c, principal, audit.ActionActivateRtranfile, active.Version, map[string]any{
+			"previous": previous,
+			"sha256":   active.SHA256,
+		})
+		version.Active = true
+	}
+
+	c.JSON(http.StatusCreated, StageRtranfileResponse{
+		Version: version,
+		Active:  h.manager.Active(),
+	})
+}
+
+// requireAdmin 只允许管理员调用
+func (h *RtranfileHandler) requireAdmin(c *gin.Context) (*auth.Principal, bool) {
+	principal, ok := auth.FromContext(c.Request.Context())
+	if !ok || !principal.Admin {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: "只有管理员可以管理 rtranfile 版本（需要启用认证）",
+			Code:    http.StatusForbidden,
+		})
+		return nil, false
+	}
+	return principal, true
+}
+
+// record 记录审计日志，操作已经完成，写入失败只记录错误
+func (h *RtranfileHandler) record(c *gin.Context, principal *auth.Principal, action, version string, details map[string]any) {
+	if err := h.audit.Record(audit.Entry{
+		Actor:    principal.Name,
+		RemoteIP: c.ClientIP(),
+		Action:   action,
+		Target:   version,
+		Details:  details,
+	}); err != nil {
+		logger.GetLogger().Named(logger.ComponentAPI).Error("写入审计日志失败",
+			zap.String("version", version),
+			zap.Error(err))
+	}
+}
+
+// stageError 将版本管理错误转换为响应
+func (h *RtranfileHandler) stageError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "INTERNAL_ERROR"
+	switch {
+	case errors.Is(err, binaries.ErrInvalidVersion), errors.Is(err, binaries.ErrInvalidChecksum):
+		status, code = http.StatusBadRequest, "INVALID_REQUEST"
+	case errors.Is(err, binaries.ErrVersionNotFound):
+		status, code = http.StatusNotFound, "VERSION_NOT_FOUND"
+	case errors.Is(err, binaries.ErrVersionExists):
+		status, code = http.StatusConflict, "VERSION_EXISTS"
+	case errors.Is(err, binaries.ErrTooLarge):
+		status, code = http.StatusRequestEntityTooLarge, "BINARY_TOO_LARGE"
+	case errors.Is(err, binaries.ErrChecksumMismatch):
+		status, code = http.StatusUnprocessableEntity, "CHECKSUM_MISMATCH"
+	case errors.Is(err, binaries.ErrSignatureRequired), errors.Is(err, binaries.ErrSignatureInvalid):
+		status, code = http.StatusUnprocessableEntity, "SIGNATURE_INVALID"
+	case errors.Is(err, binaries.ErrFetch):
+		status, code = http.StatusBadGateway, "BINARY_FETCH_FAILED"
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
+
+// RegisterRoutes 注册路由
+func (h *RtranfileHandler) RegisterRoutes(router *gin.RouterGroup) {
+	rtranfile := router.Group("/admin/rtranfile")
+	{
+		rtranfile.GET("", h.ListVersions)
+		rtranfile.POST("/versions", h.StageFromSource)
+		rtranfile.PUT("/versions/:version", h.Upload)
+		rtranfile.POST("/versions/:version/activate", h.Activate)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/services/tuning"
+)
+
+// StatsHandler 传输统计处理器（客户端模式）
+type StatsHandler struct {
+	tuner *tuning.ChunkTuner
+}
+
+// NewStatsHandler 创建新的传输统计处理器
+func NewStatsHandler(tuner *tuning.ChunkTuner) *StatsHandler {
+	return &StatsHandler{tuner: tuner}
+}
+
+// GetChunkSizeStats 查询块大小统计
+// @Summary 查询块大小统计
+// @Description 返回各设备按块大小统计的传输次数和吞吐量，以及样本数足够的块大小中吞吐量最高的推荐值
+// @Tags stats
+// @Produce json
+// @Success 200 {object} models.ChunkStatsResponse
+// @Router /api/v1/stats/chunk-sizes [get]
+func (h *StatsHandler) GetChunkSizeStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.tuner.Stats())
+}
+
+// RegisterRoutes 注册路由
+func (h *StatsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/stats/chunk-sizes", h.GetChunkSizeStats)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/transfer"
+)
+
+// TokenHandler 一次性传输令牌处理器
+type TokenHandler struct {
+	grants *auth.GrantStore
+}
+
+// NewTokenHandler 创建新的一次性传输令牌处理器
+func NewTokenHandler(grants *auth.GrantStore) *TokenHandler {
+	return &TokenHandler{grants: grants}
+}
+
+// MintTokenRequest 签发一次性传输令牌请求
+type MintTokenRequest struct {
+	Filename        string `json:"filename" binding:"required"`
+	Mode            string `json:"mode" binding:"required"`
+	Direction       string `json:"direction" binding:"required"`
+	SourcePath      string `json:"source_path"`                                         // 授权的源路径，为空时请求不能指定
+	DestinationPath string `json:"destination_path"`                                    // 授权的目标路径，为空时请求不能指定
+	Namespace       string `json:"namespace" binding:"omitempty,oneof=none owner task"` // 授权的命名空间，为空时请求不能指定
+	TTLSeconds      int    `json:"ttl_seconds"`                                         // 有效期（秒），为空时默认 10 分钟，最长 24 小时
+}
+
+// MintToken 签发一次性传输令牌
+// @Summary 签发一次性传输令牌
+// @Description 管理员签发绑定文件名、模式、方向、源路径、目标路径和命名空间的一次性令牌，持有令牌的客户端可以创建且只能创建该传输
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param request body MintTokenRequest true "签发令牌请求"
+// @Success 201 {object} auth.Grant
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /api/v1/tokens [post]
+func (h *TokenHandler) MintToken(c *gin.Context) {
+	principal, ok := auth.FromContext(c.Request.Context())
+	if !ok || !principal.Admin {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: "只有管理员可以签发传输令牌（需要启用认证）",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	var req MintTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 令牌授权的内容必须是合法的传输请求
+	if err := transfer.ValidateRequest(&models.TransferRequest{
+		Filename:        req.Filename,
+		Mode:            req.Mode,
+		Direction:       req.Direction,
+		SourcePath:      req.SourcePath,
+		DestinationPath: req.DestinationPath,
+		Namespace:       req.Namespace,
+	}); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	grant, err := h.grants.Mint(auth.GrantScope{
+		Filename:        req.Filename,
+		Mode:            req.Mode,
+		Direction:       req.Direction,
+		SourcePath:      req.SourcePath,
+		DestinationPath: req.DestinationPath,
+		Namespace:       req.Namespace,
+	}, principal.Name, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// RegisterRoutes 注册路由
+func (h *TokenHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/tokens", h.MintToken)
+}
@@ -1,43 +1,88 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/link"
 	"rdma-burst/internal/services/transfer"
+	"rdma-burst/pkg/client"
 )
 
 // TransferHandler 传输处理器
 type TransferHandler struct {
-	transferService *transfer.TransferService
+	transferService transfer.Backend
+	clientService   *transfer.ClientTransferService // 客户端模式下调用服务端API并执行传输，所有请求共用
 	clientMode      bool // 是否为客户端模式
 	serverHost      string
 	serverPort      int
 	serverConfig    *models.TransferSettings // 服务端配置
+	linkMonitor     *link.Monitor            // RDMA 链路监控器
+	grants          *auth.GrantStore         // 一次性传输令牌
+	pollInterval    time.Duration            // 进度更新间隔，用于状态响应中建议的查询间隔
+	concurrency     *transfer.TransferService // 生效的并发上限和并发自动调整状态
+	campaigns       *transfer.CampaignManager // 传输活动
+	availability    *transfer.TransferService // 各传输模式当前能否接受传输
 }
 
 // NewTransferHandler 创建新的传输处理器
-func NewTransferHandler(transferService *transfer.TransferService, serverConfig *models.TransferSettings) *TransferHandler {
-	return &TransferHandler{
+func NewTransferHandler(transferService transfer.Backend, serverConfig *models.TransferSettings) *TransferHandler {
+	h := &TransferHandler{
 		transferService: transferService,
 		clientMode:      false, // 默认为服务端模式
 		serverConfig:    serverConfig, // 保存服务端配置
 	}
+	h.campaigns = transfer.NewCampaignManager(h.campaignFuncs())
+	return h
 }
 
-// NewClientTransferHandler 创建客户端传输处理器
-func NewClientTransferHandler(serverHost string, serverPort int, serverConfig *models.TransferSettings) *TransferHandler {
-	return &TransferHandler{
+// NewClientTransferHandler 创建客户端传输处理器，clientService 在处理器的整个生命周期内共用
+func NewClientTransferHandler(clientService *transfer.ClientTransferService, serverHost string, serverPort int, serverConfig *models.TransferSettings) *TransferHandler {
+	h := &TransferHandler{
+		clientService: clientService,
 		clientMode:   true,
 		serverHost:   serverHost,
 		serverPort:   serverPort,
 		serverConfig: serverConfig, // 保存服务端配置
 	}
+	h.campaigns = transfer.NewCampaignManager(h.campaignFuncs())
+	return h
+}
+
+// SetLinkMonitor 设置 RDMA 链路监控器，链路不可用时拒绝新的传输
+func (h *TransferHandler) SetLinkMonitor(monitor *link.Monitor) {
+	h.linkMonitor = monitor
+}
+
+// SetPollInterval 设置进度更新间隔，状态响应按该间隔建议客户端的查询间隔，为 0 时使用默认值
+func (h *TransferHandler) SetPollInterval(interval time.Duration) {
+	h.pollInterval = interval
+}
+
+// SetConcurrencySource 设置提供生效并发上限和并发自动调整状态的传输服务，活跃传输数量接口一并返回
+func (h *TransferHandler) SetConcurrencySource(service *transfer.TransferService) {
+	h.concurrency = service
+}
+
+// SetAvailabilitySource 设置提供各传输模式实时可用情况的传输服务，传输模式列表接口一并返回
+func (h *TransferHandler) SetAvailabilitySource(service *transfer.TransferService) {
+	h.availability = service
+}
+
+// SetGrantStore 设置一次性传输令牌存储，使用令牌创建任务时兑换令牌
+func (h *TransferHandler) SetGrantStore(grants *auth.GrantStore) {
+	h.grants = grants
 }
 
 // CreateTransfer 创建传输任务
@@ -49,7 +94,9 @@ func NewClientTransferHandler(serverHost string, serverPort int, serverConfig *m
 // @Param request body models.TransferRequest true "传输请求"
 // @Success 201 {object} models.TransferResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Router /api/v1/transfers [post]
 func (h *TransferHandler) CreateTransfer(c *gin.Context) {
 	var req models.TransferRequest
@@ -64,97 +111,198 @@ func (h *TransferHandler) CreateTransfer(c *gin.Context) {
 		return
 	}
 
+	response, errResp := h.create(c.Request.Context(), &req)
+	if errResp != nil {
+		c.JSON(errResp.Code, errResp)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// create 检查传输请求并创建任务，失败时返回错误响应，CreateTransfer 和导入传输计划共用
+func (h *TransferHandler) create(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, *models.ErrorResponse) {
 	// 验证请求参数
-	if err := validateTransferRequest(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+	if err := transfer.ValidateRequestContext(ctx, req); err != nil {
+		return nil, &models.ErrorResponse{
 			Error:   "VALIDATION_ERROR",
 			Message: err.Error(),
 			Code:    http.StatusBadRequest,
-		})
-		return
+		}
+	}
+
+	// 把 source_path / destination_path 映射为本端使用的文件名，令牌和后续检查使用映射后的文件名
+	pathSettings := h.serverConfig
+	if pathSettings == nil {
+		pathSettings = transfer.DefaultSettings()
+	}
+	if err := transfer.MapRequestPaths(req, pathSettings, !h.clientMode); err != nil {
+		status, code := transferErrorStatus(err, http.StatusBadRequest, "VALIDATION_ERROR")
+		return nil, &models.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		}
+	}
+
+	// RDMA 链路不可用时拒绝新的传输（只校验的任务不需要链路）
+	if h.linkMonitor != nil && !req.IsVerify() {
+		if up, reason := h.linkMonitor.IsUp(); !up {
+			return nil, &models.ErrorResponse{
+				Error:   "LINK_DOWN",
+				Message: "RDMA链路不可用: " + reason,
+				Code:    http.StatusServiceUnavailable,
+			}
+		}
 	}
 
+	// 使用一次性令牌时，请求必须与令牌授权的内容一致；创建期间占用令牌，任务创建成功后令牌失效，失败时可以再次使用
+	if principal, ok := auth.FromContext(ctx); ok && principal.GrantToken != "" {
+		if h.grants == nil {
+			return nil, &models.ErrorResponse{
+				Error:   "GRANT_REJECTED",
+				Message: auth.ErrGrantNotFound.Error(),
+				Code:    http.StatusForbidden,
+			}
+		}
+		// 令牌只授权文件、模式、方向、路径和命名空间，冲突策略、暂存、优先级和调度相关的设置使用服务端配置档案的默认值
+		if fields := grantOverrides(req); len(fields) > 0 {
+			return nil, &models.ErrorResponse{
+				Error:   "GRANT_REJECTED",
+				Message: fmt.Sprintf("%v: %s", auth.ErrGrantOverride, strings.Join(fields, ", ")),
+				Code:    http.StatusForbidden,
+			}
+		}
+		if _, err := h.grants.Claim(principal.GrantToken, grantScope(req)); err != nil {
+			return nil, &models.ErrorResponse{
+				Error:   "GRANT_REJECTED",
+				Message: err.Error(),
+				Code:    http.StatusForbidden,
+			}
+		}
+		response, errResp := h.createTransfer(ctx, req)
+		if errResp != nil {
+			h.grants.Release(principal.GrantToken)
+			return nil, errResp
+		}
+		h.grants.Redeem(principal.GrantToken, response.ID)
+		return response, nil
+	}
+	return h.createTransfer(ctx, req)
+}
+
+// grantScope 获取传输请求对应的令牌授权内容
+func grantScope(req *models.TransferRequest) auth.GrantScope {
+	return auth.GrantScope{
+		Filename:        req.Filename,
+		Mode:            req.Mode,
+		Direction:       req.Direction,
+		SourcePath:      req.SourcePath,
+		DestinationPath: req.DestinationPath,
+		Namespace:       req.Namespace,
+	}
+}
+
+// grantOverrides 获取请求中指定的、令牌授权内容以外的传输设置
+func grantOverrides(req *models.TransferRequest) []string {
+	var fields []string
+	if req.OnConflict != "" {
+		fields = append(fields, "on_conflict")
+	}
+	if req.Staging != nil {
+		fields = append(fields, "staging")
+	}
+	if req.QoS != "" {
+		fields = append(fields, "qos")
+	}
+	if req.Profile != "" {
+		fields = append(fields, "profile")
+	}
+	if req.Device != "" {
+		fields = append(fields, "device")
+	}
+	if len(req.DependsOn) > 0 {
+		fields = append(fields, "depends_on")
+	}
+	if req.Deadline != nil {
+		fields = append(fields, "deadline")
+	}
+	if req.Opportunistic {
+		fields = append(fields, "opportunistic")
+	}
+	return fields
+}
+
+// createTransfer 在客户端模式下调用服务端 API，在服务端模式下准备传输环境
+func (h *TransferHandler) createTransfer(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, *models.ErrorResponse) {
 	// 如果是客户端模式，调用服务端API
 	if h.clientMode {
-		// 创建客户端传输服务（传递配置）
-		clientService := transfer.NewClientTransferService(h.serverHost, h.serverPort, h.serverConfig)
-		response, err := clientService.CreateTransfer(&req)
+		response, err := h.clientService.CreateTransfer(ctx, req)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error:   "CLIENT_TRANSFER_ERROR",
+			status, code := transferErrorStatus(err, http.StatusInternalServerError, "CLIENT_TRANSFER_ERROR")
+			return nil, &models.ErrorResponse{
+				Error:   code,
 				Message: "客户端调用服务端API失败: " + err.Error(),
-				Code:    http.StatusInternalServerError,
-			})
-			return
+				Code:    status,
+			}
 		}
-		c.JSON(http.StatusCreated, response)
-		return
+		return response, nil
 	}
 
 	// 服务端模式：使用本地传输服务
 	// 使用从配置中加载的服务端配置
 	if h.serverConfig == nil {
 		// 如果配置为空，使用默认配置
-		h.serverConfig = &models.TransferSettings{
-			Device:                "mlx5_0",
-			BaseDir:               "/var/lib/rtrans",
-			TransferInterval:      5 * 1e9, // 5秒
-			MaxConcurrentTransfers: 1,
-			ChunkSize:             4194304,
-			Modes: models.TransferModes{
-				Hugepages: models.ModeConfig{
-					Enabled: true,
-					BaseDir: "/dev/hugepages/dir",
-				},
-				Tmpfs: models.ModeConfig{
-					Enabled: true,
-					BaseDir: "/dev/shm/dir",
-				},
-				Filesystem: models.ModeConfig{
-					Enabled: true,
-					BaseDir: "/var/lib/rtrans/files",
-				},
-			},
-		}
+		h.serverConfig = transfer.DefaultSettings()
 	}
 	
 	serverConfig := h.serverConfig
 
+	// 只校验的任务不启动传输进程
+	if req.IsVerify() {
+		response, err := h.transferService.StartVerify(ctx, req, serverConfig)
+		if err != nil {
+			status, code := transferErrorStatus(err, http.StatusInternalServerError, "VERIFY_ERROR")
+			return nil, &models.ErrorResponse{
+				Error:   code,
+				Message: "启动校验任务失败: " + err.Error(),
+				Code:    status,
+			}
+		}
+		return response, nil
+	}
+
 	// 在服务端配置中设置服务端地址（用于客户端传输）
 	// 创建一个副本，避免修改原始配置
 	transferConfig := *serverConfig
 	transferConfig.ServerAddress = h.getServerAddress()
 
-	// 第一步：准备传输环境（启动服务端监听进程）
-	if err := h.transferService.PrepareTransfer(&req, &transferConfig); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "PREPARE_ERROR",
-			Message: "准备传输环境失败: " + err.Error(),
-			Code:    http.StatusInternalServerError,
-		})
-		return
-	}
-
+	// 准备传输环境（启动服务端监听进程）
 	// 服务端只负责启动监听进程，不执行客户端传输
 	// 客户端应该在收到准备就绪响应后，在自己的机器上执行传输命令
-	response := &models.TransferResponse{
-		ID:        fmt.Sprintf("prepared_%d", time.Now().Unix()),
-		Status:    models.StatusPrepared,
-		Message:   "传输环境准备就绪，请在客户端执行传输命令",
-		CreatedAt: time.Now(),
+	response, err := h.transferService.Prepare(ctx, req, &transferConfig)
+	if err != nil {
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "PREPARE_ERROR")
+		return nil, &models.ErrorResponse{
+			Error:   code,
+			Message: "准备传输环境失败: " + err.Error(),
+			Code:    status,
+		}
 	}
 
-	c.JSON(http.StatusCreated, response)
+	return response, nil
 }
 
 // GetTransferStatus 获取传输状态
 // @Summary 获取传输状态
-// @Description 获取指定传输任务的状态和进度
+// @Description 获取指定传输任务的状态和进度，响应带有 ETag 和建议的查询间隔 poll_after
 // @Tags transfers
 // @Accept json
 // @Produce json
 // @Param id path string true "任务ID"
+// @Param format query string false "响应格式，human 时另外返回 ISO-8601 时长和带单位的字节数" Enums(human)
+// @Param If-None-Match header string false "上一次响应的 ETag，进度没有变化时返回 304"
 // @Success 200 {object} models.ProgressResponse
+// @Success 304 "进度没有变化"
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /api/v1/transfers/{id} [get]
@@ -170,20 +318,29 @@ func (h *TransferHandler) GetTransferStatus(c *gin.Context) {
 		return
 	}
 
+	format := c.Query("format")
+	if format != models.FormatDefault && format != models.FormatHuman {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: fmt.Sprintf("不支持的响应格式: %q，可选值为 human", format),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
 	// 如果是客户端模式，调用服务端API
 	if h.clientMode {
-		// 创建客户端传输服务（传递配置）
-		clientService := transfer.NewClientTransferService(h.serverHost, h.serverPort, h.serverConfig)
-		status, err := clientService.GetTransferStatus(taskID)
+		status, err := h.clientService.GetTransferStatus(c.Request.Context(), taskID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error:   "TASK_NOT_FOUND",
+			code, errorCode := transferErrorStatus(err, http.StatusInternalServerError, "CLIENT_TRANSFER_ERROR")
+			c.JSON(code, models.ErrorResponse{
+				Error:   errorCode,
 				Message: err.Error(),
-				Code:    http.StatusNotFound,
+				Code:    code,
 			})
 			return
 		}
-		c.JSON(http.StatusOK, status)
+		h.respondProgress(c, status, format)
 		return
 	}
 
@@ -200,17 +357,45 @@ func (h *TransferHandler) GetTransferStatus(c *gin.Context) {
 	// 获取传输状态
 	status, err := h.transferService.GetTransferStatus(taskID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:   "TASK_NOT_FOUND",
+		code, errorCode := transferErrorStatus(err, http.StatusInternalServerError, "SERVICE_ERROR")
+		c.JSON(code, models.ErrorResponse{
+			Error:   errorCode,
 			Message: err.Error(),
-			Code:    http.StatusNotFound,
+			Code:    code,
 		})
 		return
 	}
 
+	h.respondProgress(c, status, format)
+}
+
+// respondProgress 返回任务进度，附带建议的查询间隔和 ETag；进度没有变化（If-None-Match 匹配）时返回 304
+// format 为 human 时另外返回便于阅读的字段
+func (h *TransferHandler) respondProgress(c *gin.Context, status *models.ProgressResponse, format string) {
+	status.PollAfter = transfer.PollAfter(status.Status, h.pollInterval)
+	if format == models.FormatHuman {
+		status.Humanize()
+	}
+	etag := progressETag(status, format)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if status.PollAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(status.PollAfter.Seconds()))))
+	}
+	if match := c.GetHeader("If-None-Match"); match != "" && (match == etag || match == "*") {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, status)
 }
 
+// progressETag 按任务状态、进度、错误和响应格式计算弱 ETag，耗时等随查询时间变化的字段不参与计算
+func progressETag(status *models.ProgressResponse, format string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%d|%d|%s|%d|%s", status.ID, status.Status, status.BytesTransferred, status.TotalBytes, status.Error, status.LastUpdated.UnixNano(), format)
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
 // ListTransfers 列出传输任务
 // @Summary 列出传输任务
 // @Description 获取传输任务列表，支持分页
@@ -219,6 +404,7 @@ func (h *TransferHandler) GetTransferStatus(c *gin.Context) {
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param size query int false "每页大小" default(20)
+// @Param label query []string false "标签过滤，格式 key=value，可重复"
 // @Success 200 {object} models.TaskListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Router /api/v1/transfers [get]
@@ -234,16 +420,26 @@ func (h *TransferHandler) ListTransfers(c *gin.Context) {
 		size = 20
 	}
 
+	// 按标签过滤（可重复，例如 ?label=run_id=42&label=experiment=baseline）
+	labels, err := transfer.ParseLabelSelector(c.QueryArray("label"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
 	// 如果是客户端模式，调用服务端API
 	if h.clientMode {
-		// 创建客户端传输服务（传递配置）
-		clientService := transfer.NewClientTransferService(h.serverHost, h.serverPort, h.serverConfig)
-		response, err := clientService.ListTransfers(page, size)
+		response, err := h.clientService.ListTransfers(c.Request.Context(), page, size, labels)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error:   "CLIENT_TRANSFER_ERROR",
+			status, code := transferErrorStatus(err, http.StatusInternalServerError, "CLIENT_TRANSFER_ERROR")
+			c.JSON(status, models.ErrorResponse{
+				Error:   code,
 				Message: "客户端调用服务端API失败: " + err.Error(),
-				Code:    http.StatusInternalServerError,
+				Code:    status,
 			})
 			return
 		}
@@ -262,7 +458,7 @@ func (h *TransferHandler) ListTransfers(c *gin.Context) {
 	}
 
 	// 获取任务列表
-	response := h.transferService.ListTransfers(page, size)
+	response := h.transferService.ListTransfers(page, size, labels)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -291,14 +487,13 @@ func (h *TransferHandler) CancelTransfer(c *gin.Context) {
 
 	// 如果是客户端模式，调用服务端API
 	if h.clientMode {
-		// 创建客户端传输服务（传递配置）
-		clientService := transfer.NewClientTransferService(h.serverHost, h.serverPort, h.serverConfig)
-		err := clientService.CancelTransfer(taskID)
+		err := h.clientService.CancelTransfer(c.Request.Context(), taskID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error:   "CANCEL_ERROR",
+			status, code := transferErrorStatus(err, http.StatusInternalServerError, "CANCEL_ERROR")
+			c.JSON(status, models.ErrorResponse{
+				Error:   code,
 				Message: err.Error(),
-				Code:    http.StatusNotFound,
+				Code:    status,
 			})
 			return
 		}
@@ -322,12 +517,13 @@ func (h *TransferHandler) CancelTransfer(c *gin.Context) {
 	}
 
 	// 取消传输任务
-	err := h.transferService.CancelTransfer(taskID)
+	err := h.transferService.CancelTransfer(c.Request.Context(), taskID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:   "CANCEL_ERROR",
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "CANCEL_ERROR")
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
 			Message: err.Error(),
-			Code:    http.StatusNotFound,
+			Code:    status,
 		})
 		return
 	}
@@ -339,6 +535,237 @@ func (h *TransferHandler) CancelTransfer(c *gin.Context) {
 	})
 }
 
+// Heartbeat 上报客户端传输心跳
+// @Summary 上报客户端传输心跳
+// @Description 客户端执行准备就绪的传输期间定期上报心跳，传输结束时上报 completed 或 failed；心跳超时的会话过期并释放服务端监听进程
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Param request body models.HeartbeatRequest true "心跳"
+// @Success 200 {object} models.HeartbeatResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 410 {object} models.ErrorResponse
+// @Router /api/v1/transfers/{id}/heartbeat [post]
+func (h *TransferHandler) Heartbeat(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var req models.HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var response *models.HeartbeatResponse
+	var err error
+	if h.clientMode {
+		// 客户端模式：转发到服务端
+		response, err = h.clientService.Heartbeat(c.Request.Context(), taskID, &req)
+	} else if h.transferService != nil {
+		response, err = h.transferService.Heartbeat(c.Request.Context(), taskID, &req)
+	} else {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "SERVICE_ERROR",
+			Message: "传输服务未初始化",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if err != nil {
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "HEARTBEAT_ERROR")
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReportProgress 上报客户端传输进度
+// @Summary 上报客户端传输进度
+// @Description 客户端执行准备就绪的传输期间上报已传输字节数和状态，服务端任务记录据此更新进度；上报同时视为一次心跳
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Param request body models.ProgressReport true "传输进度"
+// @Success 200 {object} models.ProgressResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 410 {object} models.ErrorResponse
+// @Router /api/v1/transfers/{id}/progress [post]
+func (h *TransferHandler) ReportProgress(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var report models.ProgressReport
+	if err := c.ShouldBindJSON(&report); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "请求参数无效: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if report.BytesTransferred < 0 || report.TotalBytes < 0 || report.TransferRate < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "传输进度不能为负数",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var response *models.ProgressResponse
+	var err error
+	if h.clientMode {
+		// 客户端模式：转发到服务端
+		response, err = h.clientService.ReportProgress(c.Request.Context(), taskID, &report)
+	} else if h.transferService != nil {
+		response, err = h.transferService.ReportProgress(c.Request.Context(), taskID, &report)
+	} else {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "SERVICE_ERROR",
+			Message: "传输服务未初始化",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if err != nil {
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "PROGRESS_ERROR")
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResumeTransfer 从检查点续传失败的传输任务
+// @Summary 续传传输任务
+// @Description 客户端启用续传时，失败的任务保留已完成的分块；续传重新准备传输环境，只传输检查点之后的剩余部分。客户端模式按本地检查点文件续传并在后台执行
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Param request body models.ResumeRequest false "续传请求"
+// @Success 200 {object} models.TransferResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/transfers/{id}/resume [post]
+func (h *TransferHandler) ResumeTransfer(c *gin.Context) {
+	taskID := c.Param("id")
+
+	// 请求体可以省略，省略时使用服务端保留的偏移
+	var req models.ResumeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "INVALID_REQUEST",
+				Message: "请求参数无效: " + err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	var response *models.TransferResponse
+	var err error
+	if h.clientMode {
+		// 客户端模式：按本地检查点续传，偏移以检查点为准
+		response, err = h.clientService.ResumeTransfer(c.Request.Context(), taskID)
+	} else if h.transferService != nil {
+		response, err = h.transferService.ResumeTransfer(c.Request.Context(), taskID, &req)
+	} else {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "SERVICE_ERROR",
+			Message: "传输服务未初始化",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if err != nil {
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "RESUME_ERROR")
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryTransfer 按原始请求重试失败或被取消的传输任务
+// @Summary 重试传输任务
+// @Description 按失败或被取消的任务的原始请求创建新任务并从头传输，只有任务的创建者和管理员可以重试；客户端模式不支持
+// @Tags transfers
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 201 {object} models.TransferResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /api/v1/transfers/{id}/retry [post]
+func (h *TransferHandler) RetryTransfer(c *gin.Context) {
+	taskID := c.Param("id")
+
+	if h.clientMode {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "NOT_RETRYABLE",
+			Message: "客户端模式不支持重试，请重新创建传输",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	if h.transferService == nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "SERVICE_ERROR",
+			Message: "传输服务未初始化",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if h.serverConfig == nil {
+		h.serverConfig = transfer.DefaultSettings()
+	}
+
+	// 与创建任务相同，使用副本设置服务端地址
+	transferConfig := *h.serverConfig
+	transferConfig.ServerAddress = h.getServerAddress()
+	response, err := h.transferService.RetryTransfer(c.Request.Context(), taskID, &transferConfig)
+	if err != nil {
+		status, code := transferErrorStatus(err, http.StatusInternalServerError, "RETRY_ERROR")
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
 // GetActiveTransfers 获取活跃传输数量
 // @Summary 获取活跃传输数量
 // @Description 获取当前活跃的传输任务数量
@@ -348,10 +775,10 @@ func (h *TransferHandler) CancelTransfer(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/transfers/active [get]
 func (h *TransferHandler) GetActiveTransfers(c *gin.Context) {
-	// 如果是客户端模式，返回0（客户端不管理活跃传输）
+	// 如果是客户端模式，返回本进程中正在执行的客户端传输数（任务状态以服务端为准）
 	if h.clientMode {
 		c.JSON(http.StatusOK, gin.H{
-			"active_transfers": 0,
+			"active_transfers": transfer.ActiveExecutions(),
 			"timestamp":        time.Now().Format(time.RFC3339),
 		})
 		return
@@ -369,42 +796,120 @@ func (h *TransferHandler) GetActiveTransfers(c *gin.Context) {
 
 	activeCount := h.transferService.GetActiveTransfers()
 	
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"active_transfers": activeCount,
 		"timestamp":        time.Now().Format(time.RFC3339),
-	})
+	}
+	// 生效的并发上限，启用并发自动调整时同时返回调整状态
+	if h.concurrency != nil {
+		response["concurrency_limit"] = h.concurrency.ConcurrencyLimit()
+		if autoscale := h.concurrency.AutoscaleStatus(); autoscale != nil {
+			response["autoscale"] = autoscale
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
-// validateTransferRequest 验证传输请求
-func validateTransferRequest(req *models.TransferRequest) error {
-	// 验证文件名
-	if req.Filename == "" {
-		return fmt.Errorf("文件名不能为空")
+// ListModes 列出传输模式
+// @Summary 列出传输模式
+// @Description 返回各传输模式是否启用及其并发和文件大小上限，请求未启用的模式返回 400 MODE_DISABLED；服务端同时返回各模式当前能否接受传输（剩余空间、活跃任务数和监听进程状态），客户端模式下返回服务端的结果
+// @Tags transfers
+// @Produce json
+// @Success 200 {object} models.TransferModesResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /api/v1/modes [get]
+func (h *TransferHandler) ListModes(c *gin.Context) {
+	if h.clientMode {
+		modes, err := h.clientService.ListModes(c.Request.Context())
+		if err != nil {
+			status, code := transferErrorStatus(err, http.StatusBadGateway, "CLIENT_TRANSFER_ERROR")
+			c.JSON(status, models.ErrorResponse{
+				Error:   code,
+				Message: "客户端调用服务端API失败: " + err.Error(),
+				Code:    status,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, modes)
+		return
 	}
 
-	// 验证传输模式
-	validModes := map[string]bool{
-		models.ModeHugepages:  true,
-		models.ModeTmpfs:      true,
-		models.ModeFilesystem: true,
+	if h.availability != nil {
+		c.JSON(http.StatusOK, h.availability.ModeAvailability())
+		return
 	}
-	if !validModes[req.Mode] {
-		return fmt.Errorf("不支持的传输模式: %s", req.Mode)
+	settings := h.serverConfig
+	if settings == nil {
+		settings = transfer.DefaultSettings()
 	}
+	c.JSON(http.StatusOK, transfer.ListModes(settings))
+}
 
-	// 验证传输方向
-	validDirections := map[string]bool{
-		models.DirectionPut: true,
-		models.DirectionGet: true,
+// transferErrorStatus 根据传输服务返回的错误确定 HTTP 状态码和错误码
+func transferErrorStatus(err error, fallbackStatus int, fallbackCode string) (int, string) {
+	var apiErr *client.APIError
+	switch {
+	case errors.Is(err, transfer.ErrTaskNotFound):
+		return http.StatusNotFound, "TASK_NOT_FOUND"
+	case errors.Is(err, transfer.ErrConcurrencyLimit):
+		return http.StatusTooManyRequests, "CONCURRENCY_LIMIT"
+	case errors.Is(err, transfer.ErrIntervalNotElapsed):
+		return http.StatusTooManyRequests, "INTERVAL_NOT_ELAPSED"
+	case errors.Is(err, transfer.ErrDeviceUnavailable):
+		return http.StatusServiceUnavailable, "DEVICE_UNAVAILABLE"
+	case errors.Is(err, transfer.ErrNotOwner):
+		return http.StatusForbidden, "FORBIDDEN"
+	case errors.Is(err, transfer.ErrNoReferenceManifest):
+		return http.StatusNotFound, "NO_REFERENCE_MANIFEST"
+	case errors.Is(err, transfer.ErrUnknownProfile):
+		return http.StatusBadRequest, "UNKNOWN_PROFILE"
+	case errors.Is(err, transfer.ErrUnknownQoSClass):
+		return http.StatusBadRequest, "UNKNOWN_QOS_CLASS"
+	case errors.Is(err, transfer.ErrUnknownDevice):
+		return http.StatusBadRequest, "UNKNOWN_DEVICE"
+	case errors.Is(err, transfer.ErrDeadlinePassed):
+		return http.StatusBadRequest, "DEADLINE_PASSED"
+	case errors.Is(err, transfer.ErrUnknownDependency):
+		return http.StatusBadRequest, "UNKNOWN_DEPENDENCY"
+	case errors.Is(err, transfer.ErrDependencyFailed):
+		return http.StatusConflict, "DEPENDENCY_FAILED"
+	case errors.Is(err, transfer.ErrModeDisabled):
+		return http.StatusBadRequest, "MODE_DISABLED"
+	case errors.Is(err, transfer.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE"
+	case errors.Is(err, transfer.ErrFileExists):
+		return http.StatusConflict, "FILE_EXISTS"
+	case errors.Is(err, transfer.ErrAdmissionRejected):
+		return http.StatusForbidden, "ADMISSION_REJECTED"
+	case errors.Is(err, transfer.ErrAdmissionUnavailable):
+		return http.StatusServiceUnavailable, "ADMISSION_UNAVAILABLE"
+	case errors.Is(err, transfer.ErrSessionExpired):
+		return http.StatusGone, "SESSION_EXPIRED"
+	case errors.Is(err, transfer.ErrInvalidStaging):
+		return http.StatusBadRequest, "INVALID_STAGING"
+	case errors.Is(err, transfer.ErrStagingUnavailable):
+		return http.StatusServiceUnavailable, "STAGING_UNAVAILABLE"
+	case errors.Is(err, transfer.ErrNetworkFilesystem):
+		return http.StatusUnprocessableEntity, "NETWORK_FILESYSTEM"
+	case errors.Is(err, transfer.ErrInvalidPath):
+		return http.StatusBadRequest, "INVALID_PATH"
+	case errors.Is(err, transfer.ErrInvalidNamespace):
+		return http.StatusBadRequest, "INVALID_NAMESPACE"
+	case errors.Is(err, transfer.ErrInvalidChecksum):
+		return http.StatusBadRequest, "INVALID_CHECKSUM"
+	case errors.Is(err, transfer.ErrTaskRunning):
+		return http.StatusConflict, "TASK_RUNNING"
+	case errors.Is(err, transfer.ErrNotResumable):
+		return http.StatusConflict, "NOT_RESUMABLE"
+	case errors.Is(err, transfer.ErrNotRetryable):
+		return http.StatusConflict, "NOT_RETRYABLE"
+	case errors.Is(err, transfer.ErrShuttingDown):
+		return http.StatusServiceUnavailable, "SHUTTING_DOWN"
+	case errors.As(err, &apiErr) && apiErr.Code != "":
+		// 客户端模式下透传服务端返回的状态码和错误码
+		return apiErr.StatusCode, apiErr.Code
 	}
-	if !validDirections[req.Direction] {
-		return fmt.Errorf("不支持的传输方向: %s", req.Direction)
-	}
-
-	// 客户端传输不再需要请求中包含服务端地址
-	// 服务端地址从配置中获取
-
-	return nil
+	return fallbackStatus, fallbackCode
 }
 
 // buildClientCommand 构建客户端执行命令
@@ -448,7 +953,33 @@ func (h *TransferHandler) RegisterRoutes(router *gin.RouterGroup) {
 		transfers.POST("", h.CreateTransfer)
 		transfers.GET("", h.ListTransfers)
 		transfers.GET("/active", h.GetActiveTransfers)
+		transfers.POST("/batch", h.CreateBatchTransfer)
+		transfers.GET("/batch/:id", h.GetBatchTransfer)
 		transfers.GET("/:id", h.GetTransferStatus)
 		transfers.DELETE("/:id", h.CancelTransfer)
+		transfers.POST("/:id/heartbeat", h.Heartbeat)
+		transfers.POST("/:id/progress", h.ReportProgress)
+		transfers.POST("/:id/resume", h.ResumeTransfer)
+		transfers.POST("/:id/retry", h.RetryTransfer)
+	}
+
+	router.GET("/modes", h.ListModes)
+
+	plans := router.Group("/plans")
+	{
+		plans.POST("/estimate", h.EstimatePlan)
+		plans.POST("/export", h.ExportPlan)
+		plans.POST("/import", h.ImportPlan)
+	}
+
+	campaigns := router.Group("/campaigns")
+	{
+		campaigns.POST("", h.CreateCampaign)
+		campaigns.GET("", h.ListCampaigns)
+		campaigns.GET("/:id", h.GetCampaign)
+		campaigns.POST("/:id/pause", h.PauseCampaign)
+		campaigns.POST("/:id/resume", h.ResumeCampaign)
+		campaigns.POST("/:id/abort", h.AbortCampaign)
+		campaigns.GET("/:id/report", h.GetCampaignReport)
 	}
 }
\ No newline at end of file
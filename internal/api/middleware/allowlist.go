@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+)
+
+// IPAllowList 只允许白名单网段内的主机访问，未启用时直接放行
+// 使用 TCP 连接的对端地址判断，不信任 X-Forwarded-For 等请求头
+func IPAllowList(settings models.IPAllowListSettings) gin.HandlerFunc {
+	if !settings.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	// 配置加载时已经验证过 CIDR 格式
+	allowList, err := auth.ParseAllowList(settings.CIDRs)
+	if err != nil {
+		allowList = &auth.AllowList{}
+	}
+
+	return func(c *gin.Context) {
+		if remoteIP := c.RemoteIP(); !allowList.Allows(remoteIP) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "IP_NOT_ALLOWED",
+				Message: "客户端地址不在允许列表中: " + remoteIP,
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
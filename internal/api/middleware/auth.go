@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+)
+
+// grantRoute 一次性传输令牌兑换前唯一允许访问的接口
+const grantRoute = "/api/v1/transfers"
+
+// grantTaskRoutes 一次性传输令牌兑换后允许访问的接口，只能用于兑换时创建的任务
+var grantTaskRoutes = map[string]bool{
+	"/api/v1/transfers/:id/heartbeat": true,
+	"/api/v1/transfers/:id/progress":  true,
+}
+
+// readOnlyPostRoutes 只读调用方可以调用的 POST 接口（不修改任何状态）
+var readOnlyPostRoutes = map[string]bool{
+	"/api/v1/plans/estimate": true,
+}
+
+// Auth 校验请求凭据并把调用方保存到请求上下文，未启用认证时直接放行
+// grants 不为空时接受一次性传输令牌，持有令牌的调用方只能创建传输任务，并为创建的任务上报心跳和进度
+func Auth(settings models.AuthSettings, grants *auth.GrantStore) gin.HandlerFunc {
+	if !settings.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	authenticator := auth.NewAuthenticator(settings)
+	authenticator.SetGrantStore(grants)
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="rdma-burst"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "UNAUTHORIZED",
+				Message: err.Error(),
+				Code:    http.StatusUnauthorized,
+			})
+			return
+		}
+
+		if principal.GrantToken != "" && !grantRequest(c, principal) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "FORBIDDEN",
+				Message: "传输令牌只能用于创建传输任务，以及为创建的任务上报心跳和进度",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+
+		if principal.ReadOnly && !readOnlyRequest(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "READ_ONLY",
+				Message: "只读调用方只能调用查询接口",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// grantRequest 判断持有一次性令牌的调用方是否可以调用该接口：兑换前只能创建任务，兑换后只能为创建的任务上报心跳和进度
+func grantRequest(c *gin.Context, principal *auth.Principal) bool {
+	if c.Request.Method != http.MethodPost {
+		return false
+	}
+	if principal.GrantTask == "" {
+		return c.FullPath() == grantRoute
+	}
+	return grantTaskRoutes[c.FullPath()] && c.Param("id") == principal.GrantTask
+}
+
+// readOnlyRequest 判断请求是否只查询、不修改状态
+func readOnlyRequest(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return readOnlyPostRoutes[c.FullPath()]
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+)
+
+// compressibleTypes 可以压缩的响应类型，其他类型（如已压缩的文件）原样返回
+var compressibleTypes = []string{"application/json", "application/x-ndjson", "text/"}
+
+// Compress 按 Accept-Encoding 用 gzip 或 deflate 压缩响应，未启用时直接放行
+// 响应体先缓存到 min_size 字节再决定是否压缩，较小的响应、不可压缩的类型和已设置 Content-Encoding 的响应原样返回
+func Compress(settings models.CompressionSettings) gin.HandlerFunc {
+	if !settings.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	level := settings.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	return func(c *gin.Context) {
+		encoding := acceptedEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			level:          level,
+			minSize:        settings.MinSize,
+		}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// acceptedEncoding 从 Accept-Encoding 中选择压缩算法，优先 gzip，都不接受时返回空
+func acceptedEncoding(header string) string {
+	var gzipOK, deflateOK bool
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		// q=0 表示不接受
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip", "*":
+			gzipOK = true
+		case "deflate":
+			deflateOK = true
+		}
+	}
+	switch {
+	case gzipOK:
+		return "gzip"
+	case deflateOK:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter 缓存响应体直到可以决定是否压缩
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding   string
+	level      int
+	minSize    int
+	buf        []byte
+	decided    bool
+	compressor io.WriteCloser // 为空时原样写入
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式响应在首次刷新时按已缓存的大小决定是否压缩
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(len(w.buf) >= w.minSize)
+	}
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 决定是否压缩并写出已缓存的响应体
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if compress && w.compressible(header) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		if w.encoding == "gzip" {
+			w.compressor, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+		} else {
+			w.compressor, _ = flate.NewWriter(w.ResponseWriter, w.level)
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.compressor != nil {
+		_, err := w.compressor.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// compressible 判断响应是否可以压缩
+func (w *compressWriter) compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	switch w.Status() {
+	case http.StatusNoContent, http.StatusNotModified:
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// finish 处理结束后写出剩余的响应体并结束压缩流
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.compressor != nil {
+		w.compressor.Close()
+	}
+}
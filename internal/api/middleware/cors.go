@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+)
+
+// corsPolicy 一个路径前缀生效的 CORS 策略
+type corsPolicy struct {
+	path        string
+	origins     map[string]bool
+	anyOrigin   bool
+	methods     string
+	headers     string
+	credentials bool
+	maxAge      string
+}
+
+// CORS 按请求路径选择 CORS 策略并设置响应头，预检请求直接返回 204，未启用时直接放行
+// "*" 来源不会与凭据同时返回：配置了 "*" 的策略返回 Access-Control-Allow-Origin: * 且不返回 Allow-Credentials，
+// 只有明确列出的来源会被回显并允许携带凭据
+func CORS(settings models.CORSSettings) gin.HandlerFunc {
+	if !settings.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	global := newCORSPolicy("", settings.AllowedOrigins, settings.AllowedMethods, settings.AllowedHeaders, settings.AllowCredentials, settings.MaxAge)
+	routes := make([]*corsPolicy, 0, len(settings.Routes))
+	for _, route := range settings.Routes {
+		origins, methods, headers := route.AllowedOrigins, route.AllowedMethods, route.AllowedHeaders
+		if len(origins) == 0 {
+			origins = settings.AllowedOrigins
+		}
+		if len(methods) == 0 {
+			methods = settings.AllowedMethods
+		}
+		if len(headers) == 0 {
+			headers = settings.AllowedHeaders
+		}
+		credentials, maxAge := settings.AllowCredentials, settings.MaxAge
+		if route.AllowCredentials != nil {
+			credentials = *route.AllowCredentials
+		}
+		if route.MaxAge != nil {
+			maxAge = *route.MaxAge
+		}
+		routes = append(routes, newCORSPolicy(route.Path, origins, methods, headers, credentials, maxAge))
+	}
+	// 最长前缀优先
+	sort.SliceStable(routes, func(i, j int) bool { return len(routes[i].path) > len(routes[j].path) })
+
+	return func(c *gin.Context) {
+		policy := global
+		for _, route := range routes {
+			if strings.HasPrefix(c.Request.URL.Path, route.path) {
+				policy = route
+				break
+			}
+		}
+
+		preflight := c.Request.Method == http.MethodOptions
+		origin := c.Request.Header.Get("Origin")
+		if origin != "" {
+			c.Writer.Header().Add("Vary", "Origin")
+			policy.apply(c, origin, preflight)
+		}
+
+		// 处理预检请求
+		if preflight {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// newCORSPolicy 创建 CORS 策略
+func newCORSPolicy(path string, origins, methods, headers []string, credentials bool, maxAge time.Duration) *corsPolicy {
+	p := &corsPolicy{
+		path:        path,
+		origins:     make(map[string]bool, len(origins)),
+		methods:     strings.Join(methods, ", "),
+		headers:     strings.Join(headers, ", "),
+		credentials: credentials,
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		p.origins[origin] = true
+	}
+	if maxAge > 0 {
+		p.maxAge = strconv.Itoa(int(maxAge / time.Second))
+	}
+	return p
+}
+
+// apply 来源被允许时设置 CORS 响应头，不允许的来源不返回任何 CORS 响应头，由浏览器拒绝
+func (p *corsPolicy) apply(c *gin.Context, origin string, preflight bool) {
+	switch {
+	case p.origins[origin]:
+		c.Header("Access-Control-Allow-Origin", origin)
+		if p.credentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+	case p.anyOrigin:
+		c.Header("Access-Control-Allow-Origin", "*")
+	default:
+		return
+	}
+
+	if !preflight {
+		return
+	}
+	c.Header("Access-Control-Allow-Methods", p.methods)
+	c.Header("Access-Control-Allow-Headers", p.headers)
+	if p.maxAge != "" {
+		c.Header("Access-Control-Max-Age", p.maxAge)
+	}
+}
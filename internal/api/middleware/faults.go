@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/fault"
+)
+
+// FaultInjection 按故障注入设置让部分请求返回 503，用于测试客户端的重试逻辑
+// injector 为 nil 时不注入故障
+func FaultInjection(injector *fault.Injector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if injector.FailRequest() {
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "FAULT_INJECTED",
+				Message: "注入的临时错误，请稍后重试",
+				Code:    http.StatusServiceUnavailable,
+			})
+			return
+		}
+		c.Next()
+	}
+}
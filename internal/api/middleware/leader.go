@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/leader"
+)
+
+// LeaderHeader 从属实例拒绝写请求时返回当前领导者标识的响应头
+const LeaderHeader = "X-RDMA-Leader"
+
+// LeaderOnly 启用选主时只允许领导者处理写请求，从属实例只提供 GET、HEAD 和 OPTIONS 等只读 API
+// elector 为 nil（未启用选主）时不做限制
+func LeaderOnly(elector *leader.Elector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if elector.IsLeader() {
+			c.Next()
+			return
+		}
+
+		current := elector.Leader()
+		message := "当前实例不是协调者领导者，只提供只读 API，领导者尚未选出"
+		if current != "" {
+			c.Header(LeaderHeader, current)
+			message = fmt.Sprintf("当前实例不是协调者领导者，只提供只读 API，请将写请求发送到领导者 %s", current)
+		}
+		c.Header("Retry-After", "2")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "NOT_LEADER",
+			Message: message,
+			Code:    http.StatusServiceUnavailable,
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/services/i18n"
+)
+
+// Localize 按 Accept-Language 选择响应语言（zh 或 en），请求没有指定支持的语言时使用 defaultLang
+// 语言不是服务端消息的原始语言（中文）时，错误响应的 message 替换为消息目录中该错误码的说明，原始消息翻译后移到 detail，error 错误码保持不变；
+// 其他 JSON 响应中的 message（包括任务记录中的状态说明）和 warnings 按消息目录翻译，目录中没有的消息保持原文
+func Localize(defaultLang string) gin.HandlerFunc {
+	fallback := i18n.Normalize(defaultLang)
+	if fallback == "" {
+		fallback = i18n.DefaultLanguage
+	}
+
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"), fallback)
+		c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		if lang == i18n.LangZH {
+			c.Next()
+			return
+		}
+
+		writer := &localizeWriter{ResponseWriter: c.Writer, lang: lang}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// localizeWriter 缓存 JSON 响应，处理结束后替换为目标语言的消息
+type localizeWriter struct {
+	gin.ResponseWriter
+	lang      string
+	buf       []byte
+	buffering bool
+}
+
+func (w *localizeWriter) Write(data []byte) (int, error) {
+	if !w.buffering && w.buf == nil && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.buffering = true
+	}
+	if w.buffering {
+		w.buf = append(w.buf, data...)
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *localizeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// finish 写出缓存的响应：错误码在消息目录中的错误响应按错误码替换，其他响应翻译其中的消息，没有可翻译的消息时原样写出
+func (w *localizeWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	body := w.buf
+	localized, ok := localizeError(body, w.lang)
+	if !ok {
+		localized, ok = localizeMessages(body, w.lang)
+	}
+	if ok {
+		body = localized
+	}
+	if w.Header().Get("Content-Length") != "" {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	w.ResponseWriter.Write(body)
+}
+
+// localizeError 把错误响应的 message 替换为消息目录中错误码的说明，原始消息按消息目录翻译后放入 detail，保留响应中的其他字段
+func localizeError(body []byte, lang string) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false
+	}
+	var code, original string
+	if err := json.Unmarshal(fields["error"], &code); err != nil || code == "" {
+		return nil, false
+	}
+	message, ok := i18n.ErrorMessage(lang, code)
+	if !ok {
+		return nil, false
+	}
+	json.Unmarshal(fields["message"], &original)
+	if translated, ok := i18n.Translate(lang, original); ok {
+		original = translated
+	}
+	if original != "" && original != message {
+		fields["detail"], _ = json.Marshal(original)
+	}
+	fields["message"], _ = json.Marshal(message)
+	localized, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+	return localized, true
+}
+
+// localizeMessages 翻译 JSON 响应中各层对象的 message 和 warnings，没有可翻译的消息时返回 false
+func localizeMessages(body []byte, lang string) ([]byte, bool) {
+	var value json.RawMessage = body
+	if !translateValue(&value, lang) {
+		return nil, false
+	}
+	return value, true
+}
+
+// translateValue 递归翻译对象和数组中的消息字段，只重新编码有改动的部分，数字等其他字段保持原样
+func translateValue(value *json.RawMessage, lang string) bool {
+	trimmed := bytes.TrimSpace(*value)
+	if len(trimmed) == 0 {
+		return false
+	}
+	changed := false
+	switch trimmed[0] {
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return false
+		}
+		for key, field := range fields {
+			var ok bool
+			switch key {
+			case "message":
+				ok = translateString(&field, lang)
+			case "warnings":
+				ok = translateStrings(&field, lang)
+			default:
+				ok = translateValue(&field, lang)
+			}
+			if ok {
+				fields[key] = field
+				changed = true
+			}
+		}
+		if changed {
+			*value, _ = json.Marshal(fields)
+		}
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return false
+		}
+		for i := range items {
+			if translateValue(&items[i], lang) {
+				changed = true
+			}
+		}
+		if changed {
+			*value, _ = json.Marshal(items)
+		}
+	}
+	return changed
+}
+
+// translateString 翻译 JSON 字符串
+func translateString(value *json.RawMessage, lang string) bool {
+	var text string
+	if err := json.Unmarshal(*value, &text); err != nil {
+		return false
+	}
+	translated, ok := i18n.Translate(lang, text)
+	if !ok {
+		return false
+	}
+	*value, _ = json.Marshal(translated)
+	return true
+}
+
+// translateStrings 翻译 JSON 字符串数组
+func translateStrings(value *json.RawMessage, lang string) bool {
+	var texts []string
+	if err := json.Unmarshal(*value, &texts); err != nil {
+		return false
+	}
+	changed := false
+	for i, text := range texts {
+		if translated, ok := i18n.Translate(lang, text); ok {
+			texts[i] = translated
+			changed = true
+		}
+	}
+	if changed {
+		*value, _ = json.Marshal(texts)
+	}
+	return changed
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+
+	"rdma-burst/pkg/tracing"
 )
 
 // LoggerMiddleware 日志中间件
@@ -41,14 +43,18 @@ func (lm *LoggerMiddleware) Logger() gin.HandlerFunc {
 		statusCode := c.Writer.Status()
 		
 		// 记录日志
-		lm.logger.Info("HTTP请求",
+		fields := []zap.Field{
 			zap.String("client_ip", clientIP),
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
 			zap.String("user_agent", c.Request.UserAgent()),
-		)
+		}
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
+		}
+		lm.logger.Info("HTTP请求", fields...)
 	}
 }
 
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+)
+
+// tokenBucket 令牌桶限速器
+type tokenBucket struct {
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// take 尝试取出一个令牌，失败时返回需要等待的时间
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// KeyRateLimit 按 API Key 限制请求速率，未配置 requests_per_second 的 Key 不限速
+// 需要放在 Auth 之后，根据认证得到的调用方选择限速器
+func KeyRateLimit(settings models.AuthSettings) gin.HandlerFunc {
+	limits := make(map[string]models.APIKeySettings)
+	if settings.Enabled {
+		for _, key := range settings.APIKeys {
+			if key.RequestsPerSecond > 0 {
+				limits[key.Name] = key
+			}
+		}
+	}
+	if len(limits) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	var mu sync.Mutex
+	buckets := make(map[string]*tokenBucket, len(limits))
+
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+		limit, limited := limits[principal.Name]
+		if !limited {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		mu.Lock()
+		bucket, exists := buckets[principal.Name]
+		if !exists {
+			burst := float64(limit.Burst)
+			if burst < 1 {
+				burst = math.Max(1, math.Ceil(limit.RequestsPerSecond))
+			}
+			bucket = &tokenBucket{rate: limit.RequestsPerSecond, burst: burst, tokens: burst, last: now}
+			buckets[principal.Name] = bucket
+		}
+		allowed, wait := bucket.take(now)
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error:   "RATE_LIMITED",
+				Message: "请求过于频繁，请稍后重试: " + principal.Name,
+				Code:    http.StatusTooManyRequests,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
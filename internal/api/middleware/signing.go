@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+)
+
+// maxSignedBodySize 签名校验时读取到内存的最大请求体
+const maxSignedBodySize = 1 << 20
+
+// uploadRoute 请求体写入临时文件再校验签名的上传接口（rtranfile 二进制文件）
+const uploadRoute = "/api/v1/admin/rtranfile/versions/:version"
+
+// Signature 校验请求的 HMAC 签名并拒绝重放的请求，未启用签名时直接放行
+// 用于无法部署 TLS 的管理网络，签名覆盖方法、路径、时间戳、随机数和请求体摘要
+// 读取请求体之前先检查签名请求头和时间戳；只有上传接口的请求体写入临时文件，最多 maxUploadSize 字节（rtranfile.max_size），为 0 时不接受上传
+func Signature(settings models.SigningSettings, maxUploadSize int64) gin.HandlerFunc {
+	if !settings.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	verifier := auth.NewVerifier(settings.Secret, settings.MaxSkew)
+	return func(c *gin.Context) {
+		// 缺少签名或时间戳超出范围的请求不读取请求体
+		if err := verifier.Precheck(c.Request); err != nil {
+			abortSignature(c, err)
+			return
+		}
+
+		// 上传的文件边写入临时文件边计算摘要，不读入内存
+		if c.Request.Body != nil && maxUploadSize > 0 && c.Request.Method == http.MethodPut && c.FullPath() == uploadRoute {
+			verifyUpload(c, verifier, maxUploadSize)
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodySize))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+					Error:   "REQUEST_TOO_LARGE",
+					Message: "读取请求体失败: " + err.Error(),
+					Code:    http.StatusRequestEntityTooLarge,
+				})
+				return
+			}
+			body = data
+			// 还原请求体供后续处理器读取
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		if err := verifier.Verify(c.Request, body); err != nil {
+			abortSignature(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// verifyUpload 把上传的请求体写入临时文件并计算摘要，签名有效时以临时文件作为请求体继续处理，处理完成后删除临时文件
+func verifyUpload(c *gin.Context, verifier *auth.Verifier, maxSize int64) {
+	if c.Request.ContentLength > maxSize {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "REQUEST_TOO_LARGE",
+			Message: fmt.Sprintf("上传的文件超过大小上限 %d 字节", maxSize),
+			Code:    http.StatusRequestEntityTooLarge,
+		})
+		return
+	}
+
+	spool, err := os.CreateTemp("", "rdma-burst-upload-*")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "创建上传的临时文件失败: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(spool, digest), http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)); err != nil {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "REQUEST_TOO_LARGE",
+			Message: "读取请求体失败: " + err.Error(),
+			Code:    http.StatusRequestEntityTooLarge,
+		})
+		return
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "读取上传的临时文件失败: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if err := verifier.VerifyDigest(c.Request, digest.Sum(nil)); err != nil {
+		abortSignature(c, err)
+		return
+	}
+	c.Request.Body = io.NopCloser(spool)
+	c.Next()
+}
+
+// abortSignature 签名无效或请求重放时返回 401
+func abortSignature(c *gin.Context, err error) {
+	code := "INVALID_SIGNATURE"
+	if errors.Is(err, auth.ErrReplayedRequest) {
+		code = "REPLAYED_REQUEST"
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    http.StatusUnauthorized,
+	})
+}
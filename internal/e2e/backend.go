@@ -0,0 +1,470 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/internal/services/transfer"
+)
+
+// copyBlockSize 复制文件时每次读写的字节数
+const copyBlockSize = 64 * 1024
+
+// FakeBackend 不依赖 RDMA 设备和 rtranfile 的传输后端
+// 在本机的客户端文件和服务端目录之间复制文件，可以按速率限速以模拟传输过程，用于端到端测试
+// put 将请求中的本地文件复制到服务端目录，get 将服务端目录中的同名文件复制到请求中的本地路径
+type FakeBackend struct {
+	mu            sync.Mutex
+	dir           string // 服务端目录
+	rate          int64  // 每秒复制的字节数，0 表示不限速
+	maxConcurrent int    // 最大并发任务数，0 表示不限制
+	tasks         map[string]*fakeTask
+	history       []*models.TransferTask
+}
+
+// fakeTask 正在复制或已结束的任务
+type fakeTask struct {
+	task   *models.TransferTask
+	req    models.TransferRequest // 原始请求，重试时使用
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ transfer.Backend = (*FakeBackend)(nil)
+
+// NewFakeBackend 创建以 dir 为服务端目录的后端，rate 为每秒复制的字节数（0 表示不限速）
+func NewFakeBackend(dir string, rate int64) *FakeBackend {
+	return &FakeBackend{
+		dir:   dir,
+		rate:  rate,
+		tasks: make(map[string]*fakeTask),
+	}
+}
+
+// SetMaxConcurrent 设置最大并发任务数，超过时返回 transfer.ErrConcurrencyLimit
+func (b *FakeBackend) SetMaxConcurrent(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.maxConcurrent = n
+}
+
+// Dir 获取服务端目录
+func (b *FakeBackend) Dir() string {
+	return b.dir
+}
+
+// Prepare 创建任务并在后台开始复制文件
+func (b *FakeBackend) Prepare(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	src, dst := b.paths(req)
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("获取源文件信息失败: %v", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxConcurrent > 0 && b.activeLocked() >= b.maxConcurrent {
+		return nil, fmt.Errorf("%w (%d)", transfer.ErrConcurrencyLimit, b.maxConcurrent)
+	}
+
+	task := newTask(ctx, req)
+	task.TotalBytes = info.Size()
+	task.MarkStarted()
+	task.MarkInProgress()
+	task.Message = "正在复制文件"
+
+	copyCtx, cancel := context.WithCancel(context.Background())
+	ft := &fakeTask{task: task, req: *req, cancel: cancel, done: make(chan struct{})}
+	b.tasks[task.ID] = ft
+	b.history = append(b.history, task)
+	go b.run(copyCtx, ft, src, dst)
+
+	return &models.TransferResponse{
+		ID:        task.ID,
+		Status:    task.Status,
+		Message:   task.Message,
+		Mode:      task.Mode,
+		Size:      task.TotalBytes,
+		CreatedAt: task.CreatedAt,
+	}, nil
+}
+
+// StartVerify 按请求中的清单同步校验服务端文件，请求没有清单时返回 transfer.ErrNoReferenceManifest
+func (b *FakeBackend) StartVerify(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	if req.Manifest == nil {
+		return nil, fmt.Errorf("%w: %s", transfer.ErrNoReferenceManifest, req.Filename)
+	}
+
+	task := newTask(ctx, req)
+	task.Type = models.TaskTypeVerify
+	task.TotalBytes = req.Manifest.Size
+	task.MarkStarted()
+
+	report, err := manifest.Verify(ctx, filepath.Join(b.dir, filepath.Base(req.Filename)), req.Manifest)
+	switch {
+	case err != nil:
+		task.MarkFailed(fmt.Sprintf("分块校验失败: %v", err))
+	case !report.OK:
+		task.BytesTransferred = report.Size
+		task.MarkFailed(fmt.Sprintf("%d/%d 个分块不一致", len(report.BadChunks), report.TotalChunks))
+	default:
+		task.BytesTransferred = report.Size
+		task.Message = fmt.Sprintf("%d 个分块全部一致", report.TotalChunks)
+		task.MarkCompleted()
+	}
+
+	b.mu.Lock()
+	b.history = append(b.history, task)
+	b.mu.Unlock()
+
+	return &models.TransferResponse{
+		ID:        task.ID,
+		Status:    task.Status,
+		Message:   task.Message,
+		Mode:      task.Mode,
+		CreatedAt: task.CreatedAt,
+	}, nil
+}
+
+// GetTransferStatus 获取任务状态和进度
+func (b *FakeBackend) GetTransferStatus(taskID string) (*models.ProgressResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, task := range b.history {
+		if task.ID == taskID {
+			return progressResponse(task), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", transfer.ErrTaskNotFound, taskID)
+}
+
+// ListTransfers 分页列出包含所有指定标签的任务快照
+func (b *FakeBackend) ListTransfers(page, size int, labels map[string]string) *models.TaskListResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var matched []*models.TransferTask
+	for _, task := range b.history {
+		if task.MatchLabels(labels) {
+			snapshot := *task
+			matched = append(matched, &snapshot)
+		}
+	}
+
+	tasks := []*models.TransferTask{}
+	if start := (page - 1) * size; start >= 0 && start < len(matched) {
+		end := start + size
+		if end > len(matched) {
+			end = len(matched)
+		}
+		tasks = matched[start:end]
+	}
+	return &models.TaskListResponse{Tasks: tasks, Total: len(matched), Page: page, Size: size}
+}
+
+// CancelTransfer 取消正在复制的任务并删除不完整的目标文件
+func (b *FakeBackend) CancelTransfer(ctx context.Context, taskID string) error {
+	b.mu.Lock()
+	ft, ok := b.tasks[taskID]
+	if !ok || ft.task.IsFinished() {
+		b.mu.Unlock()
+		return fmt.Errorf("%w或已完成: %s", transfer.ErrTaskNotFound, taskID)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(ft.task.Owner) {
+		b.mu.Unlock()
+		return fmt.Errorf("%w: %s", transfer.ErrNotOwner, taskID)
+	}
+	ft.cancel()
+	b.mu.Unlock()
+
+	<-ft.done
+	return nil
+}
+
+// GetActiveTransfers 获取正在复制的任务数
+func (b *FakeBackend) GetActiveTransfers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.activeLocked()
+}
+
+// Heartbeat 后端自己执行复制，心跳只确认任务存在，返回任务当前状态
+func (b *FakeBackend) Heartbeat(ctx context.Context, id string, req *models.HeartbeatRequest) (*models.HeartbeatResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ft, ok := b.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", transfer.ErrTaskNotFound, id)
+	}
+	return &models.HeartbeatResponse{ID: id, Status: ft.task.Status}, nil
+}
+
+// ReportProgress 后端自己执行复制，忽略上报的进度，返回后端记录的进度
+func (b *FakeBackend) ReportProgress(ctx context.Context, id string, report *models.ProgressReport) (*models.ProgressResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ft, ok := b.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", transfer.ErrTaskNotFound, id)
+	}
+	return progressResponse(ft.task), nil
+}
+
+// ResumeTransfer 后端自己执行复制，失败的任务不保留续传状态
+func (b *FakeBackend) ResumeTransfer(ctx context.Context, taskID string, req *models.ResumeRequest) (*models.TransferResponse, error) {
+	return nil, fmt.Errorf("%w: %s", transfer.ErrNotResumable, taskID)
+}
+
+// RetryTransfer 按失败或被取消的任务的原始请求重新复制文件，只有任务的创建者和管理员可以重试
+func (b *FakeBackend) RetryTransfer(ctx context.Context, taskID string, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	b.mu.Lock()
+	ft, ok := b.tasks[taskID]
+	if !ok {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", transfer.ErrTaskNotFound, taskID)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(ft.task.Owner) {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", transfer.ErrNotOwner, taskID)
+	}
+	if ft.task.Status != models.StatusFailed && ft.task.Status != models.StatusCancelled {
+		status := ft.task.Status
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s 为 %s", transfer.ErrNotRetryable, taskID, status)
+	}
+	req := ft.req
+	b.mu.Unlock()
+
+	return b.Prepare(ctx, &req, serverConfig)
+}
+
+// EstimatePlan 按源文件大小和限速估算传输计划，不限速时只记录文件大小
+func (b *FakeBackend) EstimatePlan(ctx context.Context, plan *models.TransferPlan, serverConfig *models.TransferSettings) *models.PlanEstimate {
+	estimate := &models.PlanEstimate{
+		Items:       make([]models.PlanItemEstimate, 0, len(plan.Items)),
+		EstimatedAt: time.Now(),
+	}
+	for i := range plan.Items {
+		req := &plan.Items[i]
+		item := models.PlanItemEstimate{
+			Index:     i,
+			Filename:  req.Filename,
+			Direction: req.Direction,
+			Mode:      req.Mode,
+		}
+		src, _ := b.paths(req)
+		info, err := os.Stat(src)
+		if err != nil {
+			item.Error = fmt.Sprintf("获取源文件信息失败: %v", err)
+			estimate.Invalid++
+			estimate.Items = append(estimate.Items, item)
+			continue
+		}
+		item.Size = info.Size()
+		estimate.TotalBytes += item.Size
+		if b.rate > 0 {
+			item.ThroughputMBps = float64(b.rate) / (1024 * 1024)
+			item.EstimatedSeconds = float64(item.Size) / float64(b.rate)
+			estimate.EstimatedSeconds += item.EstimatedSeconds
+		} else {
+			estimate.Unestimated++
+		}
+		estimate.Items = append(estimate.Items, item)
+	}
+	return estimate
+}
+
+// Wait 等待任务结束（完成、失败或取消），返回任务快照
+func (b *FakeBackend) Wait(ctx context.Context, taskID string) (*models.TransferTask, error) {
+	b.mu.Lock()
+	ft, ok := b.tasks[taskID]
+	b.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", transfer.ErrTaskNotFound, taskID)
+	}
+
+	select {
+	case <-ft.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	snapshot := *ft.task
+	return &snapshot, nil
+}
+
+// Close 取消所有正在复制的任务
+func (b *FakeBackend) Close() {
+	b.mu.Lock()
+	tasks := make([]*fakeTask, 0, len(b.tasks))
+	for _, ft := range b.tasks {
+		ft.cancel()
+		tasks = append(tasks, ft)
+	}
+	b.mu.Unlock()
+
+	for _, ft := range tasks {
+		<-ft.done
+	}
+}
+
+// paths 获取请求的源文件和目标文件路径
+func (b *FakeBackend) paths(req *models.TransferRequest) (string, string) {
+	server := filepath.Join(b.dir, filepath.Base(req.Filename))
+	if req.Direction == models.DirectionGet {
+		return server, req.Filename
+	}
+	return req.Filename, server
+}
+
+// activeLocked 统计正在复制的任务数，调用方需持有锁
+func (b *FakeBackend) activeLocked() int {
+	active := 0
+	for _, ft := range b.tasks {
+		if ft.task.IsActive() {
+			active++
+		}
+	}
+	return active
+}
+
+// run 复制文件并更新任务进度，失败或取消时删除不完整的目标文件
+func (b *FakeBackend) run(ctx context.Context, ft *fakeTask, src, dst string) {
+	defer close(ft.done)
+
+	err := b.copyFile(ctx, ft.task, src, dst)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case err == nil:
+		ft.task.Message = "复制完成"
+		ft.task.MarkCompleted()
+	case ctx.Err() != nil:
+		os.Remove(dst)
+		ft.task.Message = "传输任务已取消"
+		ft.task.MarkCancelled()
+	default:
+		os.Remove(dst)
+		ft.task.MarkFailed(err.Error())
+	}
+}
+
+// copyFile 按块复制文件，限速时按已复制字节数和速率计算的时间等待
+func (b *FakeBackend) copyFile(ctx context.Context, task *models.TransferTask, src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("打开源文件失败: %v", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("创建目标文件失败: %v", err)
+	}
+	defer out.Close()
+
+	start := time.Now()
+	buf := make([]byte, copyBlockSize)
+	var copied int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				return fmt.Errorf("写入目标文件失败: %v", err)
+			}
+			copied += int64(n)
+
+			b.mu.Lock()
+			task.UpdateProgress(copied, task.TotalBytes)
+			b.mu.Unlock()
+
+			if err := b.throttle(ctx, start, copied); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return out.Sync()
+		}
+		if readErr != nil {
+			return fmt.Errorf("读取源文件失败: %v", readErr)
+		}
+	}
+}
+
+// throttle 复制速度超过限速时等待
+func (b *FakeBackend) throttle(ctx context.Context, start time.Time, copied int64) error {
+	if b.rate <= 0 {
+		return nil
+	}
+	expected := time.Duration(float64(copied) / float64(b.rate) * float64(time.Second))
+	wait := expected - time.Since(start)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// newTask 按请求创建任务记录
+func newTask(ctx context.Context, req *models.TransferRequest) *models.TransferTask {
+	task := models.NewTransferTaskWithServer(req.Filename, req.Mode, req.Direction, "")
+	task.Profile = req.Profile
+	task.Labels = req.Labels
+	task.Metadata = req.Metadata
+	if principal, ok := auth.FromContext(ctx); ok {
+		task.Owner = principal.Name
+	}
+	return task
+}
+
+// progressResponse 构建任务的进度响应，调用方需持有锁
+func progressResponse(task *models.TransferTask) *models.ProgressResponse {
+	resp := &models.ProgressResponse{
+		ID:               task.ID,
+		Status:           task.Status,
+		Progress:         task.Progress,
+		BytesTransferred: task.BytesTransferred,
+		TotalBytes:       task.TotalBytes,
+		Error:            task.Error,
+		LastUpdated:      task.UpdatedAt,
+	}
+	if !task.StartTime.IsZero() {
+		end := time.Now()
+		if task.EndTime != nil {
+			end = *task.EndTime
+		}
+		elapsed := end.Sub(task.StartTime)
+		resp.ElapsedTime = elapsed.String()
+		resp.ElapsedSeconds = elapsed.Seconds()
+		if elapsed > 0 {
+			resp.TransferRate = float64(task.BytesTransferred) / elapsed.Seconds() / (1024 * 1024)
+		}
+	}
+	return resp
+}
@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/pkg/client"
+)
+
+// newGrantServer 启动启用认证和一次性传输令牌的进程内服务，返回签发给 admin 的令牌
+func newGrantServer(t *testing.T, scope auth.GrantScope) (*Server, *FakeBackend, string) {
+	t.Helper()
+	backend := NewFakeBackend(t.TempDir(), 64*1024)
+	grants := auth.NewGrantStore()
+	server := NewAuthServer(backend, models.AuthSettings{Enabled: true, Token: "admin-token"}, grants)
+	t.Cleanup(func() {
+		backend.Close()
+		server.Close()
+	})
+	grant, err := grants.Mint(scope, "admin", time.Minute)
+	if err != nil {
+		t.Fatalf("签发传输令牌失败: %v", err)
+	}
+	return server, backend, grant.Token
+}
+
+func TestGrantTaskHeartbeatAndProgress(t *testing.T) {
+	src, _ := writeFile(t, t.TempDir(), "grant.bin", 4*1024*1024)
+	scope := auth.GrantScope{Filename: src, Mode: models.ModeFilesystem, Direction: models.DirectionPut}
+	server, backend, token := newGrantServer(t, scope)
+	c := server.Client(client.WithTransferToken(token))
+	ctx := context.Background()
+
+	req := &client.TransferRequest{Filename: src, Mode: models.ModeFilesystem, Direction: models.DirectionPut}
+	created, err := c.CreateTransfer(ctx, req)
+	if err != nil {
+		t.Fatalf("使用令牌创建传输任务失败: %v", err)
+	}
+
+	// 兑换后的令牌可以为创建的任务上报心跳和进度
+	if _, err := c.Heartbeat(ctx, created.ID, &client.HeartbeatRequest{State: models.HeartbeatRunning}); err != nil {
+		t.Fatalf("使用令牌上报心跳失败: %v", err)
+	}
+	if _, err := c.ReportProgress(ctx, created.ID, &client.ProgressReport{State: models.HeartbeatRunning, BytesTransferred: 1024}); err != nil {
+		t.Fatalf("使用令牌上报进度失败: %v", err)
+	}
+
+	// 不能再次创建任务，也不能访问其他任务和其他接口
+	_, err = c.CreateTransfer(ctx, req)
+	apiError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = c.Heartbeat(ctx, "other", &client.HeartbeatRequest{State: models.HeartbeatRunning})
+	apiError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = c.CancelTransfer(ctx, created.ID)
+	apiError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	if _, err := backend.GetTransferStatus(created.ID); err != nil {
+		t.Fatalf("获取传输状态失败: %v", err)
+	}
+}
+
+func TestGrantRejectsOverrides(t *testing.T) {
+	src, _ := writeFile(t, t.TempDir(), "grant.bin", 1024)
+	scope := auth.GrantScope{Filename: src, Mode: models.ModeFilesystem, Direction: models.DirectionPut}
+	server, _, token := newGrantServer(t, scope)
+	c := server.Client(client.WithTransferToken(token))
+	ctx := context.Background()
+
+	_, err := c.CreateTransfer(ctx, &client.TransferRequest{
+		Filename:   src,
+		Mode:       models.ModeFilesystem,
+		Direction:  models.DirectionPut,
+		OnConflict: "overwrite",
+	})
+	apiError(t, err, http.StatusForbidden, "GRANT_REJECTED")
+
+	// 被拒绝的请求不消耗令牌
+	if _, err := c.CreateTransfer(ctx, &client.TransferRequest{Filename: src, Mode: models.ModeFilesystem, Direction: models.DirectionPut}); err != nil {
+		t.Fatalf("使用令牌创建传输任务失败: %v", err)
+	}
+}
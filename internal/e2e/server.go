@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/api/handlers"
+	"rdma-burst/internal/api/middleware"
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/transfer"
+	"rdma-burst/pkg/client"
+)
+
+// version 进程内服务在健康检查中返回的版本
+const version = "e2e"
+
+// Server 在进程内运行的 API 服务，监听本地回环地址的随机端口
+type Server struct {
+	*httptest.Server
+	Backend transfer.Backend
+}
+
+// NewRouter 创建与服务端相同的传输和健康检查路由，middlewares 在路由之前执行（例如认证）
+func NewRouter(backend transfer.Backend, settings *models.TransferSettings, middlewares ...gin.HandlerFunc) *gin.Engine {
+	return newRouter(backend, settings, nil, middlewares...)
+}
+
+// newRouter 创建路由，grants 不为空时传输处理器接受一次性传输令牌
+func newRouter(backend transfer.Backend, settings *models.TransferSettings, grants *auth.GrantStore, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	if settings == nil {
+		settings = transfer.DefaultSettings()
+	}
+
+	router := gin.New()
+	router.Use(middleware.NewLoggerMiddleware(zap.NewNop()).Recovery())
+	router.Use(middlewares...)
+
+	handlers.NewHealthHandler(backend, version).RegisterRoutes(router.Group("/api"))
+	transfers := handlers.NewTransferHandler(backend, settings)
+	if grants != nil {
+		transfers.SetGrantStore(grants)
+	}
+	transfers.RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+// NewServer 使用 backend 启动进程内 API 服务，调用方负责 Close
+func NewServer(backend transfer.Backend, settings *models.TransferSettings, middlewares ...gin.HandlerFunc) *Server {
+	return &Server{
+		Server:  httptest.NewServer(NewRouter(backend, settings, middlewares...)),
+		Backend: backend,
+	}
+}
+
+// NewFakeServer 以 dir 为服务端目录启动使用 FakeBackend 的进程内 API 服务，rate 为每秒复制的字节数（0 表示不限速）
+func NewFakeServer(dir string, rate int64) (*Server, *FakeBackend) {
+	backend := NewFakeBackend(dir, rate)
+	return NewServer(backend, nil), backend
+}
+
+// NewAuthServer 启动启用认证和一次性传输令牌的进程内 API 服务，调用方负责 Close
+func NewAuthServer(backend transfer.Backend, settings models.AuthSettings, grants *auth.GrantStore) *Server {
+	router := newRouter(backend, nil, grants, middleware.Auth(settings, grants))
+	return &Server{
+		Server:  httptest.NewServer(router),
+		Backend: backend,
+	}
+}
+
+// Client 创建访问该服务的 API 客户端
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	return client.New(s.URL, opts...)
+}
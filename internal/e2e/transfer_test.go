@@ -0,0 +1,209 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/client"
+)
+
+// newTestServer 启动使用 FakeBackend 的进程内服务，返回服务端目录和客户端目录
+func newTestServer(t *testing.T, rate int64) (*Server, *FakeBackend, string) {
+	t.Helper()
+	serverDir, clientDir := t.TempDir(), t.TempDir()
+	server, backend := NewFakeServer(serverDir, rate)
+	t.Cleanup(func() {
+		backend.Close()
+		server.Close()
+	})
+	return server, backend, clientDir
+}
+
+// writeFile 在目录中写入指定大小的文件，返回路径和内容
+func writeFile(t *testing.T, dir, name string, size int) (string, []byte) {
+	t.Helper()
+	data := bytes.Repeat([]byte("rdma-burst"), size/10+1)[:size]
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+	return path, data
+}
+
+// waitFinished 等待任务结束并返回任务快照
+func waitFinished(t *testing.T, backend *FakeBackend, taskID string) *models.TransferTask {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	task, err := backend.Wait(ctx, taskID)
+	if err != nil {
+		t.Fatalf("等待任务 %s 结束失败: %v", taskID, err)
+	}
+	return task
+}
+
+// apiError 断言错误为指定状态码和错误码的 API 错误
+func apiError(t *testing.T, err error, status int, code string) {
+	t.Helper()
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("期望 API 错误 %d %s，实际为 %v", status, code, err)
+	}
+	if apiErr.StatusCode != status || apiErr.Code != code {
+		t.Fatalf("期望 API 错误 %d %s，实际为 %d %s: %s", status, code, apiErr.StatusCode, apiErr.Code, apiErr.Message)
+	}
+}
+
+func TestPutTransferCompletes(t *testing.T) {
+	server, backend, clientDir := newTestServer(t, 0)
+	src, data := writeFile(t, clientDir, "put.bin", 256*1024)
+	c := server.Client()
+	ctx := context.Background()
+
+	created, err := c.CreateTransfer(ctx, &client.TransferRequest{
+		Filename:  src,
+		Mode:      models.ModeFilesystem,
+		Direction: models.DirectionPut,
+	})
+	if err != nil {
+		t.Fatalf("创建传输任务失败: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatal("创建传输任务的响应没有任务ID")
+	}
+
+	waitFinished(t, backend, created.ID)
+	status, err := c.GetTransfer(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("获取传输状态失败: %v", err)
+	}
+	if status.Status != models.StatusCompleted {
+		t.Fatalf("任务状态为 %s（%s），期望 %s", status.Status, status.Error, models.StatusCompleted)
+	}
+	if status.BytesTransferred != int64(len(data)) || status.TotalBytes != int64(len(data)) {
+		t.Fatalf("已传输 %d/%d 字节，期望 %d", status.BytesTransferred, status.TotalBytes, len(data))
+	}
+
+	received, err := os.ReadFile(filepath.Join(backend.Dir(), "put.bin"))
+	if err != nil {
+		t.Fatalf("读取服务端文件失败: %v", err)
+	}
+	if !bytes.Equal(received, data) {
+		t.Fatal("服务端文件内容与源文件不一致")
+	}
+}
+
+func TestGetTransferCompletes(t *testing.T) {
+	server, backend, clientDir := newTestServer(t, 0)
+	_, data := writeFile(t, backend.Dir(), "get.bin", 128*1024)
+	dst := filepath.Join(clientDir, "get.bin")
+	c := server.Client()
+	ctx := context.Background()
+
+	created, err := c.CreateTransfer(ctx, &client.TransferRequest{
+		Filename:  dst,
+		Mode:      models.ModeFilesystem,
+		Direction: models.DirectionGet,
+	})
+	if err != nil {
+		t.Fatalf("创建传输任务失败: %v", err)
+	}
+
+	task := waitFinished(t, backend, created.ID)
+	if task.Status != models.StatusCompleted {
+		t.Fatalf("任务状态为 %s（%s），期望 %s", task.Status, task.Error, models.StatusCompleted)
+	}
+	received, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("读取客户端文件失败: %v", err)
+	}
+	if !bytes.Equal(received, data) {
+		t.Fatal("客户端文件内容与服务端文件不一致")
+	}
+
+	list, err := c.ListTransfers(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("列出传输任务失败: %v", err)
+	}
+	if list.Total != 1 || len(list.Tasks) != 1 || list.Tasks[0].ID != created.ID {
+		t.Fatalf("列出的任务为 %+v，期望只有 %s", list.Tasks, created.ID)
+	}
+}
+
+func TestCancelTransfer(t *testing.T) {
+	// 限速 64KB/s 复制 4MB，取消前任务一直在复制
+	server, backend, clientDir := newTestServer(t, 64*1024)
+	src, _ := writeFile(t, clientDir, "cancel.bin", 4*1024*1024)
+	c := server.Client()
+	ctx := context.Background()
+
+	created, err := c.CreateTransfer(ctx, &client.TransferRequest{
+		Filename:  src,
+		Mode:      models.ModeFilesystem,
+		Direction: models.DirectionPut,
+	})
+	if err != nil {
+		t.Fatalf("创建传输任务失败: %v", err)
+	}
+	if active := backend.GetActiveTransfers(); active != 1 {
+		t.Fatalf("活跃任务数为 %d，期望 1", active)
+	}
+
+	if _, err := c.CancelTransfer(ctx, created.ID); err != nil {
+		t.Fatalf("取消传输任务失败: %v", err)
+	}
+	status, err := c.GetTransfer(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("获取传输状态失败: %v", err)
+	}
+	if status.Status != models.StatusCancelled {
+		t.Fatalf("任务状态为 %s，期望 %s", status.Status, models.StatusCancelled)
+	}
+	if _, err := os.Stat(filepath.Join(backend.Dir(), "cancel.bin")); !os.IsNotExist(err) {
+		t.Fatalf("取消后服务端仍有不完整的文件: %v", err)
+	}
+
+	// 已取消的任务不能再次取消
+	_, err = c.CancelTransfer(ctx, created.ID)
+	apiError(t, err, http.StatusNotFound, "TASK_NOT_FOUND")
+}
+
+func TestTransferNotFound(t *testing.T) {
+	server, _, _ := newTestServer(t, 0)
+	c := server.Client()
+	ctx := context.Background()
+
+	_, err := c.GetTransfer(ctx, "missing")
+	apiError(t, err, http.StatusNotFound, "TASK_NOT_FOUND")
+	_, err = c.CancelTransfer(ctx, "missing")
+	apiError(t, err, http.StatusNotFound, "TASK_NOT_FOUND")
+}
+
+func TestCreateTransferRejected(t *testing.T) {
+	server, backend, clientDir := newTestServer(t, 64*1024)
+	src, _ := writeFile(t, clientDir, "limit.bin", 4*1024*1024)
+	c := server.Client()
+	ctx := context.Background()
+
+	_, err := c.CreateTransfer(ctx, &client.TransferRequest{
+		Filename:  src,
+		Mode:      "unknown",
+		Direction: models.DirectionPut,
+	})
+	apiError(t, err, http.StatusBadRequest, "INVALID_REQUEST")
+
+	backend.SetMaxConcurrent(1)
+	req := &client.TransferRequest{Filename: src, Mode: models.ModeFilesystem, Direction: models.DirectionPut}
+	if _, err := c.CreateTransfer(ctx, req); err != nil {
+		t.Fatalf("创建传输任务失败: %v", err)
+	}
+	_, err = c.CreateTransfer(ctx, req)
+	apiError(t, err, http.StatusTooManyRequests, "CONCURRENCY_LIMIT")
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// AnonymousTenant 未启用认证时任务计入的租户
+const AnonymousTenant = "anonymous"
+
+// AccountingRecord 定义一个租户在一个统计周期内的传输用量
+type AccountingRecord struct {
+	Tenant          string           `json:"tenant"` // 创建任务的调用方
+	PeriodStart     time.Time        `json:"period_start"`
+	PeriodEnd       time.Time        `json:"period_end"`
+	Transfers       int              `json:"transfers"`
+	Completed       int              `json:"completed"`
+	Failed          int              `json:"failed"`
+	Cancelled       int              `json:"cancelled"`
+	BytesPut        int64            `json:"bytes_put"`        // put 任务已传输的字节数（包括失败和取消的任务）
+	BytesGet        int64            `json:"bytes_get"`        // get 任务已传输的字节数（包括失败和取消的任务）
+	BytesByMode     map[string]int64 `json:"bytes_by_mode"`    // 按传输模式汇总的字节数
+	TransferSeconds float64          `json:"transfer_seconds"` // 从启动传输进程到任务结束的累计耗时
+	Final           bool             `json:"final"`            // 统计周期已结束，记录不再变化
+}
+
+// AccountingResponse 定义用量查询响应
+type AccountingResponse struct {
+	Records []AccountingRecord `json:"records"`
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	Period  time.Duration      `json:"period"`
+}
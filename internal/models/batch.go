@@ -0,0 +1,20 @@
+package models
+
+// BatchTransferRequest 定义批量传输请求：一次提交多个文件的传输，所有任务使用同一个批次ID
+type BatchTransferRequest struct {
+	Items []TransferRequest `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+// BatchTransfer 定义批量传输的任务和汇总进度
+type BatchTransfer struct {
+	ID       string           `json:"id"`
+	Status   string           `json:"status"`   // 有未结束的任务时为 running，否则为 completed，部分传输可能失败
+	Items    []CampaignItem   `json:"items"`    // 按提交顺序排列；查询时只包含已创建的任务
+	Progress CampaignProgress `json:"progress"` // 与传输活动的汇总进度相同
+}
+
+// 批量传输状态
+const (
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+)
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// CampaignRequest 定义传输活动：把一组传输作为一个整体分批提交和管理，传输可以使用配置档案的时间窗口和任务依赖
+type CampaignRequest struct {
+	Name      string            `json:"name" binding:"required,max=128"`
+	BatchSize int               `json:"batch_size,omitempty" binding:"min=0"` // 同时未结束的任务数上限，为 0 时一次提交所有传输
+	Items     []TransferRequest `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+// Campaign 定义传输活动的状态和汇总进度
+type Campaign struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Status    string           `json:"status"`
+	Owner     string           `json:"owner,omitempty"` // 创建活动的调用方，启用认证时设置
+	BatchSize int              `json:"batch_size,omitempty"`
+	Items     []CampaignItem   `json:"items"`
+	Progress  CampaignProgress `json:"progress"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	EndedAt   *time.Time       `json:"ended_at,omitempty"`
+}
+
+// CampaignItem 定义活动中单个传输的状态
+type CampaignItem struct {
+	Index            int    `json:"index"`
+	Filename         string `json:"filename"`
+	Direction        string `json:"direction"`
+	TaskID           string `json:"task_id,omitempty"` // 提交后的任务ID
+	Status           string `json:"status"`            // 未提交时为 pending，活动中止时未提交的传输为 skipped，提交后为任务状态
+	BytesTransferred int64  `json:"bytes_transferred"`
+	TotalBytes       int64  `json:"total_bytes"`
+	Error            string `json:"error,omitempty"`
+}
+
+// CampaignProgress 定义活动的汇总进度
+type CampaignProgress struct {
+	Total            int     `json:"total"`
+	Pending          int     `json:"pending"` // 尚未提交
+	Running          int     `json:"running"` // 已提交、未结束
+	Completed        int     `json:"completed"`
+	Failed           int     `json:"failed"` // 任务失败或创建失败
+	Cancelled        int     `json:"cancelled"`
+	Skipped          int     `json:"skipped"`
+	BytesTransferred int64   `json:"bytes_transferred"`
+	TotalBytes       int64   `json:"total_bytes"`
+	Progress         float64 `json:"progress"` // 按字节计算的百分比，总字节数未知时按结束的传输数计算
+}
+
+// CampaignReport 定义活动结束后生成的报告
+type CampaignReport struct {
+	CampaignID      string           `json:"campaign_id"`
+	Name            string           `json:"name"`
+	Status          string           `json:"status"`
+	Owner           string           `json:"owner,omitempty"`
+	Progress        CampaignProgress `json:"progress"`
+	Items           []CampaignItem   `json:"items"`
+	CreatedAt       time.Time        `json:"created_at"`
+	EndedAt         time.Time        `json:"ended_at"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	ThroughputMBps  float64          `json:"throughput_mbps,omitempty"` // 已传输字节数除以活动耗时
+}
+
+// 传输活动状态
+const (
+	CampaignRunning   = "running"
+	CampaignPaused    = "paused"    // 不再提交新的传输，已提交的任务继续执行
+	CampaignAborted   = "aborted"   // 已提交未结束的任务被取消，未提交的传输跳过
+	CampaignCompleted = "completed" // 所有传输均已结束，部分传输可能失败
+)
+
+// 活动中未提交的传输状态
+const (
+	CampaignItemPending = "pending"
+	CampaignItemSkipped = "skipped"
+)
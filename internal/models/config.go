@@ -16,6 +16,11 @@ type CombinedConfig struct {
 	ClientSpecific  ClientSpecificSettings `mapstructure:"client_specific" json:"client_specific"`
 	Mutex           MutexSettings          `mapstructure:"mutex" json:"mutex"`
 	SingleTransfer  SingleTransferSettings `mapstructure:"single_transfer" json:"single_transfer"`
+	Detection       DetectionSettings      `mapstructure:"detection" json:"detection"`
+	Tracing         TracingSettings        `mapstructure:"tracing" json:"tracing"`
+	Alerting        AlertingSettings       `mapstructure:"alerting" json:"alerting"`
+	Notifications   NotificationSettings   `mapstructure:"notifications" json:"notifications"`
+	LeaderElection  LeaderElectionSettings `mapstructure:"leader_election" json:"leader_election"`
 }
 
 // ServerConfig 定义服务端配置
@@ -25,6 +30,10 @@ type ServerConfig struct {
 	Logging   LoggingSettings   `mapstructure:"logging" json:"logging"`
 	Monitoring MonitoringSettings `mapstructure:"monitoring" json:"monitoring"`
 	Security  SecuritySettings  `mapstructure:"security" json:"security"`
+	Tracing   TracingSettings   `mapstructure:"tracing" json:"tracing"`
+	Alerting  AlertingSettings  `mapstructure:"alerting" json:"alerting"`
+	Notifications NotificationSettings `mapstructure:"notifications" json:"notifications"`
+	LeaderElection LeaderElectionSettings `mapstructure:"leader_election" json:"leader_election"`
 }
 
 // ClientConfig 定义客户端配置
@@ -35,6 +44,7 @@ type ClientConfig struct {
 	Monitoring ClientMonitoringSettings `mapstructure:"monitoring" json:"monitoring"`
 	Security  SecuritySettings     `mapstructure:"security" json:"security"`
 	Client    ClientSpecificSettings `mapstructure:"client_specific" json:"client"`
+	Tracing   TracingSettings      `mapstructure:"tracing" json:"tracing"`
 }
 
 // ServerSettings 定义服务端设置
@@ -45,13 +55,43 @@ type ServerSettings struct {
 	ReadTimeout    time.Duration `mapstructure:"read_timeout" json:"read_timeout"`
 	WriteTimeout   time.Duration `mapstructure:"write_timeout" json:"write_timeout"`
 	MaxHeaderBytes int           `mapstructure:"max_header_bytes" json:"max_header_bytes"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" json:"shutdown_timeout"` // 关闭 HTTP 服务时等待处理中的请求结束的时间，为 0 时使用默认值 30s
+	IdleTimeout    time.Duration `mapstructure:"idle_timeout" json:"idle_timeout"`       // 空闲的 keep-alive 连接保持的时间，为 0 时使用默认值 120s
+	MaxConnections int           `mapstructure:"max_connections" json:"max_connections"` // 同时打开的最大连接数，超出的连接等待已有连接关闭，0 表示不限制
+	HTTP2          bool          `mapstructure:"http2" json:"http2"`                     // 在明文端口上接受 HTTP/2（h2c），高频轮询的调用方可以复用同一连接
+	Compression    CompressionSettings `mapstructure:"compression" json:"compression"`
+	Language       string        `mapstructure:"language" json:"language"` // API 消息的默认语言 zh|en，请求的 Accept-Language 优先
+}
+
+// CompressionSettings 定义 API 响应压缩设置
+type CompressionSettings struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	MinSize int  `mapstructure:"min_size" json:"min_size"` // 响应体达到该字节数才压缩，较小的响应压缩收益不明显
+	Level   int  `mapstructure:"level" json:"level"`       // 压缩级别 1-9，0 表示默认级别
+}
+
+// GetIdleTimeout 获取空闲连接保持的时间，未配置时使用默认值
+func (s ServerSettings) GetIdleTimeout() time.Duration {
+	if s.IdleTimeout <= 0 {
+		return DefaultIdleTimeout
+	}
+	return s.IdleTimeout
+}
+
+// GetShutdownTimeout 获取关闭 HTTP 服务时等待处理中的请求结束的时间，未配置时使用默认值
+func (s ServerSettings) GetShutdownTimeout() time.Duration {
+	if s.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout
+	}
+	return s.ShutdownTimeout
 }
 
 // ClientServerSettings 定义客户端服务端连接设置
 type ClientServerSettings struct {
 	Host         string        `mapstructure:"host" json:"host"`
 	Port         int           `mapstructure:"port" json:"port"`
-	Timeout      time.Duration `mapstructure:"timeout" json:"timeout"`
+	Timeout      time.Duration `mapstructure:"timeout" json:"timeout"`           // 普通请求每次尝试的超时
+	WaitTimeout  time.Duration `mapstructure:"wait_timeout" json:"wait_timeout"` // 服务端需要读取整个文件的请求（生成和校验分块清单）每次尝试的超时
 	RetryAttempts int          `mapstructure:"retry_attempts" json:"retry_attempts"`
 	RetryDelay   time.Duration `mapstructure:"retry_delay" json:"retry_delay"`
 }
@@ -62,10 +102,238 @@ type TransferSettings struct {
 	BaseDir               string            `mapstructure:"base_dir" json:"base_dir"`
 	TransferInterval      time.Duration     `mapstructure:"transfer_interval" json:"transfer_interval"`
 	MaxConcurrentTransfers int              `mapstructure:"max_concurrent_transfers" json:"max_concurrent_transfers"`
+	MaxConcurrentPuts     int               `mapstructure:"max_concurrent_puts" json:"max_concurrent_puts,omitempty"` // put 任务的并发上限，为 0 时只受 max_concurrent_transfers 限制
+	MaxConcurrentGets     int               `mapstructure:"max_concurrent_gets" json:"max_concurrent_gets,omitempty"` // get 任务的并发上限，为 0 时只受 max_concurrent_transfers 限制
 	ChunkSize            int               `mapstructure:"chunk_size" json:"chunk_size"`
 	Modes                TransferModes     `mapstructure:"modes" json:"modes"`
 	DefaultMode          string            `mapstructure:"default_mode" json:"default_mode,omitempty"`
 	ServerAddress        string            `mapstructure:"server_address,omitempty" json:"server_address,omitempty"` // 临时字段，用于传递服务端地址
+	JournalFile          string            `mapstructure:"journal_file" json:"journal_file,omitempty"` // 任务状态预写日志，为空时不持久化
+	ChunkManifest        bool              `mapstructure:"chunk_manifest" json:"chunk_manifest,omitempty"` // 传输完成后按分块清单逐块校验
+	Profiles             map[string]TransferProfile `mapstructure:"profiles" json:"profiles,omitempty"` // 传输配置档案，请求通过 profile 字段选择
+	QoSClasses           map[string]QoSClass        `mapstructure:"qos_classes" json:"qos_classes,omitempty"` // QoS 等级，请求通过 qos 字段选择
+	AutoMode             AutoModeSettings           `mapstructure:"auto_mode" json:"auto_mode"`                // auto 模式按文件大小选择传输模式的阈值
+	Preallocate          bool                       `mapstructure:"preallocate" json:"preallocate"`            // get 前按服务端文件大小预分配目标文件
+	PostHooks            []HookConfig               `mapstructure:"post_hooks" json:"post_hooks,omitempty"`    // 传输成功后执行的钩子
+	AdmissionHooks       []HookConfig               `mapstructure:"admission_hooks" json:"admission_hooks,omitempty"` // 准备传输前执行的准入钩子，可以修改或拒绝请求
+	HeartbeatTimeout     time.Duration              `mapstructure:"heartbeat_timeout" json:"heartbeat_timeout"` // 准备就绪的会话超过该时间没有收到客户端心跳时过期，为 0 时使用默认值 60s
+	PreparedTTL          time.Duration              `mapstructure:"prepared_ttl" json:"prepared_ttl"`           // 准备就绪的任务超过该时间没有开始执行（没有收到心跳）时过期，为 0 时使用默认值 5m
+	LogRecordingDir      string                     `mapstructure:"log_recording_dir" json:"log_recording_dir,omitempty"` // 客户端传输时按时间录制 rtranfile 日志的目录，用于回放验证日志解析，为空时不录制
+	Faults               FaultSettings              `mapstructure:"faults" json:"faults"`                       // 故障注入，仅用于测试重试、停滞和恢复逻辑
+	ObjectStores         map[string]ObjectStoreSettings `mapstructure:"object_stores" json:"object_stores,omitempty"` // 对象存储（S3/MinIO），按名称供请求的 staging 引用，名为 default 的存储在请求未指定时使用
+	NetworkFSPolicy      string                     `mapstructure:"network_fs_policy" json:"network_fs_policy,omitempty"` // hugepages/tmpfs 目录位于网络文件系统（NFS、Lustre 等）时的处理策略，为空时使用 warn
+	StageSourceDirs      []string                   `mapstructure:"stage_source_dirs" json:"stage_source_dirs,omitempty"` // 本地暂存 API 允许读取的源目录，为空时不允许本地暂存
+	StageIO              StageIOSettings            `mapstructure:"stage_io" json:"stage_io"`                   // 本地暂存复制文件使用的 I/O 方式
+	PartialSuffix        string                     `mapstructure:"partial_suffix" json:"partial_suffix"`       // 接收端传输期间使用的临时文件后缀，校验通过并完成后原子地重命名为目标文件，为空时直接写入目标文件
+	Scrub                ScrubSettings              `mapstructure:"scrub" json:"scrub"`                         // 定期按分块清单重新校验模式目录中保留的文件
+	ShutdownGracePeriod  time.Duration              `mapstructure:"shutdown_grace_period" json:"shutdown_grace_period"` // 收到 SIGTERM 后拒绝新的传输并等待进行中的传输结束的时间，超时后强制结束，为 0 时立即取消
+	ProcessStopTimeout   time.Duration              `mapstructure:"process_stop_timeout" json:"process_stop_timeout"` // 停止 rtranfile 进程时发送中断信号后等待其退出的时间，超时后强制终止，为 0 时使用默认值 10s
+	ProcessCleanupTimeout time.Duration             `mapstructure:"process_cleanup_timeout" json:"process_cleanup_timeout"` // 清理传输任务时等待 rtranfile 进程退出的时间，超时后强制终止，为 0 时使用默认值 5s
+	WarmListeners        WarmListenerSettings       `mapstructure:"warm_listeners" json:"warm_listeners"`       // 启动时为已启用的模式常驻 rtranfile 监听进程，准备传输时不再逐次启动
+	SessionReuse         SessionReuseSettings       `mapstructure:"session_reuse" json:"session_reuse"`         // 会话结束后保留监听进程供后续传输复用，连续传输多个文件时不必每次重新建立
+	Accounting           AccountingSettings         `mapstructure:"accounting" json:"accounting"`               // 按租户（调用方）和统计周期汇总传输字节数和耗时，供计费查询
+	Rtranfile            RtranfileSettings          `mapstructure:"rtranfile" json:"rtranfile"`                 // rtranfile 版本管理，通过管理员 API 上传、校验并切换 rtranfile 版本
+	Autoscale            AutoscaleSettings          `mapstructure:"autoscale" json:"autoscale"`                 // 按聚合吞吐量和链路利用率自动调整 max_concurrent_transfers 生效的并发上限
+	ChunkTuning          ChunkTuningSettings        `mapstructure:"chunk_tuning" json:"chunk_tuning"`           // 按设备统计各块大小的吞吐量，推荐或自动使用表现最好的块大小
+	HugepagePool         HugepagePoolSettings       `mapstructure:"hugepage_pool" json:"hugepage_pool"`         // 启动时预留大页并租给 hugepages 模式的传输
+	Pipeline             PipelineSettings           `mapstructure:"pipeline" json:"pipeline"`                   // 批量传输多个文件时本地 I/O 与 RDMA 传输重叠执行
+	Devices              map[string]DeviceSettings  `mapstructure:"devices" json:"devices,omitempty"`           // 除 device 外可供请求选择的 RDMA 设备（HCA），按设备名称配置各自的调度队列
+}
+
+// DeviceSettings 定义 RDMA 设备的调度设置：每个设备有独立的调度队列，设备的并发传输数达到 max_concurrent 时，
+// 请求该设备的任务按提交顺序排队，不影响其他设备上的传输
+type DeviceSettings struct {
+	MaxConcurrent int `mapstructure:"max_concurrent" json:"max_concurrent"` // 设备同时准备就绪和执行中的传输数上限，为 0 时不单独限制
+}
+
+// RtranfileSettings 定义 rtranfile 版本管理设置：新版本校验后保存到 dir/versions，传输服务通过 dir/current 符号链接启动 rtranfile
+type RtranfileSettings struct {
+	Enabled          bool          `mapstructure:"enabled" json:"enabled"`
+	Dir              string        `mapstructure:"dir" json:"dir"`
+	PublicKeys       []string      `mapstructure:"public_keys" json:"public_keys,omitempty"` // 校验签名的 Ed25519 公钥（base64），签名为对二进制文件 SHA-256 摘要的签名
+	RequireSignature bool          `mapstructure:"require_signature" json:"require_signature"` // 新版本必须带有可通过 public_keys 校验的签名
+	MaxSize          int64         `mapstructure:"max_size" json:"max_size"`                   // 单个版本的最大字节数，为 0 时使用默认值 256MiB
+	DownloadTimeout  time.Duration `mapstructure:"download_timeout" json:"download_timeout"`   // 从 URL 下载新版本的超时，为 0 时使用默认值 5m
+}
+
+// AutoscaleSettings 定义并发自动调整设置：每隔 interval 计算链路利用率，超过 high_utilization 时降低并发上限，
+// 低于 low_utilization 且有传输在等待并发名额时提高并发上限，生效的上限保持在 [min_concurrent, max_concurrent] 内
+type AutoscaleSettings struct {
+	Enabled         bool          `mapstructure:"enabled" json:"enabled"`
+	MinConcurrent   int           `mapstructure:"min_concurrent" json:"min_concurrent"`     // 并发上限的下限，为 0 时使用默认值 1
+	MaxConcurrent   int           `mapstructure:"max_concurrent" json:"max_concurrent"`     // 并发上限的上限，为 0 时使用 max_concurrent_transfers
+	Interval        time.Duration `mapstructure:"interval" json:"interval"`                 // 调整间隔，为 0 时使用默认值 10s
+	HighUtilization float64       `mapstructure:"high_utilization" json:"high_utilization"` // 链路利用率（0-1）不低于该值时视为饱和，降低并发上限，为 0 时使用默认值 0.9
+	LowUtilization  float64       `mapstructure:"low_utilization" json:"low_utilization"`   // 链路利用率低于该值时视为空闲，提高并发上限，为 0 时使用默认值 0.5
+}
+
+// ChunkTuningSettings 定义块大小调优设置（客户端）：按设备统计不同 rtranfile 块大小（-s）的吞吐量，
+// apply 为 true 时没有通过 QoS 等级指定块大小的传输在 candidates 中选择，先让每个候选达到 min_samples，之后使用吞吐量最高的块大小
+type ChunkTuningSettings struct {
+	Enabled      bool   `mapstructure:"enabled" json:"enabled"`
+	Apply        bool   `mapstructure:"apply" json:"apply"`                     // 为 false 时只统计并推荐，不改变传输使用的块大小
+	Candidates   []int  `mapstructure:"candidates" json:"candidates"`           // 自动选择时尝试的块大小（字节）
+	MinSamples   int    `mapstructure:"min_samples" json:"min_samples"`         // 块大小参与推荐需要的最少传输数，为 0 时使用默认值 3
+	MinFileSize  int64  `mapstructure:"min_file_size" json:"min_file_size"`     // 小于该大小的传输主要是启动开销，不计入统计，为 0 时使用默认值 64MB
+	ExploreEvery int    `mapstructure:"explore_every" json:"explore_every"`     // 每 N 次选择改用最久未使用的候选重新测量，为 0 时不重新测量
+	StateFile    string `mapstructure:"state_file" json:"state_file,omitempty"` // 统计保存的文件，为空时只保存在内存中，重启后丢失
+}
+
+// PipelineSettings 定义流水线传输设置（客户端）：依次传输一组文件，RDMA 传输一次只执行一个文件，
+// 其后 lookahead 个文件提前完成暂存和校验和计算，已传输的文件在后台校验和落盘，链路不必等待本地 I/O
+type PipelineSettings struct {
+	Enabled   bool   `mapstructure:"enabled" json:"enabled"`
+	Lookahead int    `mapstructure:"lookahead" json:"lookahead"`            // 传输前最多提前准备的文件数，为 0 时使用默认值 2
+	IOWorkers int    `mapstructure:"io_workers" json:"io_workers"`         // 同时执行传输前准备的文件数，传输后校验、落盘同样按该数量并发，为 0 时使用默认值 2
+	StageDir  string `mapstructure:"stage_dir" json:"stage_dir,omitempty"` // put 传输前把源文件复制到该目录（例如 tmpfs）再发送，复制按 stage_io 使用 io_uring，与前一个文件的传输重叠；为空时直接发送源文件
+}
+
+// StageIOSettings 定义本地暂存复制文件的 I/O 设置：io_uring 同时保持 queue_depth 个读请求在途，写入与后续的读取重叠进行
+type StageIOSettings struct {
+	Engine     string `mapstructure:"engine" json:"engine"`           // auto|io_uring|sync，auto 在 io_uring 不可用时使用同步读写，io_uring 不可用时暂存失败
+	QueueDepth int    `mapstructure:"queue_depth" json:"queue_depth"` // io_uring 同时在途的读请求数，为 0 时使用默认值 8
+}
+
+// 本地暂存的 I/O 方式
+const (
+	StageIOAuto  = "auto"
+	StageIOURing = "io_uring"
+	StageIOSync  = "sync"
+)
+
+// HugepagePoolSettings 定义大页缓冲池设置（服务端）：启动时在 dir（须位于 hugetlbfs）中按 segment_size 分段预分配共 size 字节的大页，
+// hugepages 模式的 put 传输准备时按文件大小释放相应的分段供接收使用，任务结束后每隔 refill_interval 补回
+type HugepagePoolSettings struct {
+	Enabled        bool          `mapstructure:"enabled" json:"enabled"`
+	Dir            string        `mapstructure:"dir" json:"dir"`                         // 预留分段所在的目录，须与 hugepages 模式目录位于同一个 hugetlbfs
+	Size           int64         `mapstructure:"size" json:"size"`                       // 预留的总字节数
+	SegmentSize    int64         `mapstructure:"segment_size" json:"segment_size"`       // 每个分段的字节数，须为 2MB 的整数倍，为 0 时使用默认值 1GB
+	RefillInterval time.Duration `mapstructure:"refill_interval" json:"refill_interval"` // 回收结束任务的租用并补回分段的间隔，为 0 时使用默认值 10s
+}
+
+// AccountingSettings 定义用量统计设置：结束的任务按调用方计入当前统计周期，周期结束后的记录追加到 file
+type AccountingSettings struct {
+	Enabled   bool          `mapstructure:"enabled" json:"enabled"`
+	Period    time.Duration `mapstructure:"period" json:"period"`       // 统计周期，按 UTC 对齐，为 0 时使用默认值 1h
+	File      string        `mapstructure:"file" json:"file,omitempty"` // 用量记录文件（JSON Lines），为空时只保存在内存中，重启后丢失
+	Retention time.Duration `mapstructure:"retention" json:"retention"` // 用量记录的保留时间，为 0 时使用默认值 2160h（90 天）
+}
+
+// SessionReuseSettings 定义会话复用设置：会话结束后监听进程在空闲超时内保持运行，最多服务 max_transfers 个传输后重新启动
+type SessionReuseSettings struct {
+	Enabled      bool          `mapstructure:"enabled" json:"enabled"`
+	MaxTransfers int           `mapstructure:"max_transfers" json:"max_transfers"` // 一个监听进程最多服务的传输数，达到后在空闲时停止，下一次传输重新启动，为 0 时不限制
+	IdleTimeout  time.Duration `mapstructure:"idle_timeout" json:"idle_timeout"`   // 没有会话时监听进程保持运行的时间，为 0 时使用默认值 30s
+}
+
+// WarmListenerSettings 定义常驻监听进程设置：启动时为每个已启用的模式启动监听进程并定期检查，退出时自动重启
+type WarmListenerSettings struct {
+	Enabled       bool          `mapstructure:"enabled" json:"enabled"`
+	CheckInterval time.Duration `mapstructure:"check_interval" json:"check_interval"` // 检查监听进程是否存活的间隔，为 0 时使用默认值 10s
+}
+
+// ScrubSettings 定义文件巡检设置：按数据文件旁保存的分块清单重新校验，发现静默损坏时记录指标并发送 file_corrupted 通知
+type ScrubSettings struct {
+	Enabled  bool          `mapstructure:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" json:"interval"` // 两轮巡检的间隔，为 0 时使用默认值 24h
+}
+
+// DefaultPartialSuffix 接收端传输期间使用的默认临时文件后缀
+const DefaultPartialSuffix = ".part"
+
+// DefaultShutdownTimeout 关闭 HTTP 服务时等待处理中的请求结束的默认时间
+const DefaultShutdownTimeout = 30 * time.Second
+
+// DefaultIdleTimeout 空闲的 keep-alive 连接保持的默认时间
+const DefaultIdleTimeout = 120 * time.Second
+
+// 内存模式目录位于网络文件系统时的处理策略
+const (
+	NetworkFSWarn       = "warn"       // 启动时和准备传输时记录警告，照常传输
+	NetworkFSFilesystem = "filesystem" // 改用 filesystem 模式传输，auto 模式不选择该模式
+	NetworkFSRefuse     = "refuse"     // 拒绝使用该模式的请求
+)
+
+// HookConfig 定义传输钩子：执行命令或向 URL 发送任务 JSON，command 和 url 只能配置一个
+type HookConfig struct {
+	Name    string        `mapstructure:"name" json:"name"`
+	Command []string      `mapstructure:"command" json:"command,omitempty"` // 命令及参数，任务 JSON 通过标准输入传入
+	URL     string        `mapstructure:"url" json:"url,omitempty"`         // 以 POST 发送任务 JSON
+	Timeout time.Duration `mapstructure:"timeout" json:"timeout,omitempty"` // 超时时间，为 0 时使用默认值 30s
+}
+
+// ObjectStoreSettings 定义 S3 兼容的对象存储（AWS S3、MinIO 等）
+type ObjectStoreSettings struct {
+	Endpoint  string        `mapstructure:"endpoint" json:"endpoint"`     // 例如 https://s3.us-east-1.amazonaws.com、http://minio:9000
+	Region    string        `mapstructure:"region" json:"region"`         // 签名使用的区域，为空时使用 us-east-1
+	AccessKey string        `mapstructure:"access_key" json:"access_key"`
+	SecretKey string        `mapstructure:"secret_key" json:"secret_key,omitempty"`
+	PathStyle bool          `mapstructure:"path_style" json:"path_style"` // 使用 <endpoint>/<bucket>/<key> 访问对象（MinIO 需要开启），否则使用 <bucket>.<endpoint>
+	PartSize  int64         `mapstructure:"part_size" json:"part_size"`   // 分段上传的段大小（字节），不小于 5MB，为 0 时使用 64MB；不超过一段的文件直接上传
+	Timeout   time.Duration `mapstructure:"timeout" json:"timeout"`       // 单个请求（下载、上传一段）的超时时间，为 0 时不限制
+}
+
+// AutoModeSettings 定义 auto 模式的文件大小阈值
+// 小于 tmpfs_min_size 使用 filesystem，小于 hugepages_min_size 使用 tmpfs，其余使用 hugepages
+type AutoModeSettings struct {
+	TmpfsMinSize     int64 `mapstructure:"tmpfs_min_size" json:"tmpfs_min_size"`
+	HugepagesMinSize int64 `mapstructure:"hugepages_min_size" json:"hugepages_min_size"`
+}
+
+// DefaultAutoModeSettings 获取默认 auto 模式阈值
+func DefaultAutoModeSettings() AutoModeSettings {
+	return AutoModeSettings{
+		TmpfsMinSize:     64 * 1024 * 1024,   // 64MB
+		HugepagesMinSize: 1024 * 1024 * 1024, // 1GB
+	}
+}
+
+// FaultSettings 定义故障注入设置，enabled 为 false 时所有故障都不生效
+type FaultSettings struct {
+	Enabled              bool          `mapstructure:"enabled" json:"enabled"`
+	LaunchDelay          time.Duration `mapstructure:"launch_delay" json:"launch_delay,omitempty"`                     // 启动 rtranfile 进程前等待的时间
+	KillAfter            time.Duration `mapstructure:"kill_after" json:"kill_after,omitempty"`                         // 客户端 rtranfile 进程启动后经过该时间强制结束，为 0 时不结束
+	CorruptProgressEvery int           `mapstructure:"corrupt_progress_every" json:"corrupt_progress_every,omitempty"` // 每 N 行传输日志替换为无法解析的内容，为 0 时不替换
+	APIErrorEvery        int           `mapstructure:"api_error_every" json:"api_error_every,omitempty"`               // 每 N 个 API 请求返回 503，为 0 时不返回
+}
+
+// TransferProfile 定义传输配置档案
+type TransferProfile struct {
+	Windows    []string         `mapstructure:"windows" json:"windows,omitempty"`         // 允许开始传输的每日时间窗口（本地时间），例如 "22:00-06:00"，为空表示不限制
+	QoS        string           `mapstructure:"qos" json:"qos,omitempty"`                 // 请求未指定 qos 时使用的 QoS 等级
+	Metadata   MetadataSettings `mapstructure:"metadata" json:"metadata,omitempty"`       // 文件元数据保留设置
+	OnConflict string           `mapstructure:"on_conflict" json:"on_conflict,omitempty"` // 请求未指定 on_conflict 时使用的冲突策略
+	Namespace  string           `mapstructure:"namespace" json:"namespace,omitempty"`     // 请求未指定 namespace 时 get 的目标文件所在的命名空间
+}
+
+// MetadataSettings 定义文件元数据保留设置
+type MetadataSettings struct {
+	Preserve bool     `mapstructure:"preserve" json:"preserve"`       // put 后在服务端、get 后在本地恢复源文件的权限和修改时间
+	Xattrs   []string `mapstructure:"xattrs" json:"xattrs,omitempty"` // 需要保留的扩展属性名模式，例如 "user.*"
+}
+
+// QoSClass 定义 QoS 等级到传输参数的映射
+type QoSClass struct {
+	ChunkSize        int     `mapstructure:"chunk_size" json:"chunk_size,omitempty"`               // rtranfile 块大小（-s），为 0 时使用 rtranfile 默认值
+	ConcurrencyShare float64 `mapstructure:"concurrency_share" json:"concurrency_share,omitempty"` // 该等级最多占用的并发传输比例（0-1），为 0 表示不限制
+	DSCP             int     `mapstructure:"dscp" json:"dscp,omitempty"`                           // RoCE 流量的 DSCP（0-63），通过 rtranfile --tclass 设置，为 0 时不设置
+}
+
+// QoS 等级名称
+const (
+	QoSBulk        = "bulk"
+	QoSInteractive = "interactive"
+	QoSCritical    = "critical"
+)
+
+// DefaultQoSClasses 获取默认 QoS 等级
+// rtranfile 没有单独的 SL 参数，RoCE 网卡按 DSCP 映射优先级
+func DefaultQoSClasses() map[string]QoSClass {
+	return map[string]QoSClass{
+		QoSBulk:        {ChunkSize: 64 * 1024 * 1024, ConcurrencyShare: 0.5, DSCP: 10}, // AF11
+		QoSInteractive: {ChunkSize: 4 * 1024 * 1024, ConcurrencyShare: 0.8, DSCP: 26},  // AF31
+		QoSCritical:    {ChunkSize: 1024 * 1024, ConcurrencyShare: 1, DSCP: 46},        // EF
+	}
 }
 
 // TransferModes 定义传输模式配置
@@ -77,8 +345,11 @@ type TransferModes struct {
 
 // ModeConfig 定义模式配置
 type ModeConfig struct {
-	Enabled bool   `mapstructure:"enabled" json:"enabled"`
-	BaseDir string `mapstructure:"base_dir" json:"base_dir"`
+	Enabled              bool    `mapstructure:"enabled" json:"enabled"`
+	BaseDir              string  `mapstructure:"base_dir" json:"base_dir"`
+	MaxConcurrent        int     `mapstructure:"max_concurrent" json:"max_concurrent,omitempty"`                     // 该模式的并发上限，为 0 时不单独限制
+	MaxFileSize          int64   `mapstructure:"max_file_size" json:"max_file_size,omitempty"`                       // 单个文件大小上限（字节），为 0 时不限制
+	MaxFileSizeMemoryPct float64 `mapstructure:"max_file_size_memory_pct" json:"max_file_size_memory_pct,omitempty"` // 单个文件大小上限占物理内存的百分比，为 0 时不限制
 }
 
 // LoggingSettings 定义日志设置
@@ -89,6 +360,17 @@ type LoggingSettings struct {
 	MaxAge     int    `mapstructure:"max_age" json:"max_age"`
 	Level      string `mapstructure:"level" json:"level"`
 	Format     string `mapstructure:"format" json:"format"`
+	Components map[string]string `mapstructure:"components" json:"components,omitempty"` // 组件日志级别（api、transfer、wrapper、monitor）
+	Sink       LogSinkSettings   `mapstructure:"sink" json:"sink"`                          // 可选的 syslog/journald 输出
+	RedactKeys []string          `mapstructure:"redact_keys" json:"redact_keys,omitempty"`  // 额外需要脱敏的字段名
+}
+
+// LogSinkSettings 定义额外日志输出设置
+type LogSinkSettings struct {
+	Type    string `mapstructure:"type" json:"type"`       // syslog 或 journald，为空表示不启用
+	Network string `mapstructure:"network" json:"network"` // syslog 网络类型: udp, tcp, unix；为空时使用本地 syslog
+	Address string `mapstructure:"address" json:"address"`
+	Tag     string `mapstructure:"tag" json:"tag"`
 }
 
 // MonitoringSettings 定义监控设置
@@ -96,6 +378,7 @@ type MonitoringSettings struct {
 	HealthCheckInterval time.Duration `mapstructure:"health_check_interval" json:"health_check_interval"`
 	EnableMetrics       bool          `mapstructure:"enable_metrics" json:"enable_metrics"`
 	MetricsPort         int           `mapstructure:"metrics_port" json:"metrics_port"`
+	LinkCheckInterval   time.Duration `mapstructure:"link_check_interval" json:"link_check_interval"` // RDMA 链路状态检查间隔
 }
 
 // ClientMonitoringSettings 定义客户端监控设置
@@ -104,12 +387,86 @@ type ClientMonitoringSettings struct {
 	TransferTimeout       time.Duration `mapstructure:"transfer_timeout" json:"transfer_timeout"`
 }
 
+// TracingSettings 定义 OpenTelemetry 追踪设置
+type TracingSettings struct {
+	Enabled     bool              `mapstructure:"enabled" json:"enabled"`
+	Endpoint    string            `mapstructure:"endpoint" json:"endpoint"` // OTLP/HTTP 端点，例如 localhost:4318
+	Insecure    bool              `mapstructure:"insecure" json:"insecure"`
+	ServiceName string            `mapstructure:"service_name" json:"service_name"`
+	SampleRatio float64           `mapstructure:"sample_ratio" json:"sample_ratio"`
+	Headers     map[string]string `mapstructure:"headers" json:"headers,omitempty"`
+}
+
+// AlertingSettings 定义内置告警设置（用于没有 Prometheus/Alertmanager 的站点）
+type AlertingSettings struct {
+	Enabled       bool          `mapstructure:"enabled" json:"enabled"`
+	Interval      time.Duration `mapstructure:"interval" json:"interval"`             // 规则评估间隔
+	FailureWindow time.Duration `mapstructure:"failure_window" json:"failure_window"` // failure_rate 的统计窗口
+	StallTimeout  time.Duration `mapstructure:"stall_timeout" json:"stall_timeout"`   // 任务无进度超过该时间计为停滞
+	Rules         []AlertRule   `mapstructure:"rules" json:"rules"`
+}
+
+// LeaderElectionSettings 定义协调者选主设置
+// 在 Kubernetes 中运行多个实例时通过同一个 Lease 选主，只有领导者调度传输，其余实例只提供只读 API
+type LeaderElectionSettings struct {
+	Enabled       bool          `mapstructure:"enabled" json:"enabled"`
+	LeaseName     string        `mapstructure:"lease_name" json:"lease_name"`         // coordination.k8s.io/v1 Lease 名称
+	Namespace     string        `mapstructure:"namespace" json:"namespace"`           // 为空时使用 Pod 所在的命名空间
+	Identity      string        `mapstructure:"identity" json:"identity"`             // 实例标识，为空时使用 POD_NAME 或主机名
+	LeaseDuration time.Duration `mapstructure:"lease_duration" json:"lease_duration"` // 领导者停止续约后其他实例接管前等待的时间
+	RenewDeadline time.Duration `mapstructure:"renew_deadline" json:"renew_deadline"` // 领导者续约失败超过该时间后放弃领导权
+	RetryPeriod   time.Duration `mapstructure:"retry_period" json:"retry_period"`     // 获取和续约 Lease 的间隔
+}
+
+// NotificationSettings 定义任务事件通知设置
+// 事件（task_failed、task_stalled）按 events 映射到严重级别，再按严重级别的渠道和模板发送
+type NotificationSettings struct {
+	Enabled       bool                           `mapstructure:"enabled" json:"enabled"`
+	CheckInterval time.Duration                  `mapstructure:"check_interval" json:"check_interval"` // 检查停滞任务的间隔
+	StallTimeout  time.Duration                  `mapstructure:"stall_timeout" json:"stall_timeout"`   // 任务无进度超过该时间发送 task_stalled
+	Events        map[string]string              `mapstructure:"events" json:"events"`                 // 事件类型到严重级别的映射
+	Severities    map[string]NotificationRoute   `mapstructure:"severities" json:"severities"`         // 各严重级别的通知渠道和消息模板
+	Channels      map[string]NotificationChannel `mapstructure:"channels" json:"channels"`             // 通知渠道，按名称引用
+}
+
+// NotificationRoute 定义严重级别的通知渠道和消息模板（Go text/template），模板为空时使用默认模板
+type NotificationRoute struct {
+	Channels []string `mapstructure:"channels" json:"channels"`
+	Subject  string   `mapstructure:"subject" json:"subject,omitempty"`   // 标题模板，用于邮件标题
+	Template string   `mapstructure:"template" json:"template,omitempty"` // 正文模板
+}
+
+// NotificationChannel 定义通知渠道：webhook、slack 或 email
+type NotificationChannel struct {
+	Type     string        `mapstructure:"type" json:"type"`
+	URL      string        `mapstructure:"url" json:"url,omitempty"`             // webhook 地址或 Slack Incoming Webhook 地址
+	SMTPHost string        `mapstructure:"smtp_host" json:"smtp_host,omitempty"` // email: SMTP 服务器
+	SMTPPort int           `mapstructure:"smtp_port" json:"smtp_port,omitempty"` // email: SMTP 端口，默认 25
+	Username string        `mapstructure:"username" json:"username,omitempty"`   // email: SMTP 认证用户名，为空时不认证
+	Password string        `mapstructure:"password" json:"password,omitempty"`
+	From     string        `mapstructure:"from" json:"from,omitempty"`
+	To       []string      `mapstructure:"to" json:"to,omitempty"`
+	Timeout  time.Duration `mapstructure:"timeout" json:"timeout,omitempty"` // 发送超时，默认 10s
+}
+
+// AlertRule 定义告警规则：指标持续超过阈值 duration 后向 webhook 发送通知
+type AlertRule struct {
+	Name      string        `mapstructure:"name" json:"name"`
+	Metric    string        `mapstructure:"metric" json:"metric"` // failure_rate, queue_depth, stall_count
+	Threshold float64       `mapstructure:"threshold" json:"threshold"`
+	Duration  time.Duration `mapstructure:"duration" json:"duration"`
+	Webhook   string        `mapstructure:"webhook" json:"webhook"`
+}
+
 // SecuritySettings 定义安全设置
 type SecuritySettings struct {
-	CORS      CORSSettings      `mapstructure:"cors" json:"cors"`
-	RateLimit RateLimitSettings `mapstructure:"rate_limit" json:"rate_limit"`
-	TLS       TLSSettings       `mapstructure:"tls" json:"tls,omitempty"`
-	Auth      AuthSettings      `mapstructure:"auth" json:"auth,omitempty"`
+	CORS        CORSSettings        `mapstructure:"cors" json:"cors"`
+	RateLimit   RateLimitSettings   `mapstructure:"rate_limit" json:"rate_limit"`
+	TLS         TLSSettings         `mapstructure:"tls" json:"tls,omitempty"`
+	Auth        AuthSettings        `mapstructure:"auth" json:"auth,omitempty"`
+	Signing     SigningSettings     `mapstructure:"signing" json:"signing,omitempty"`
+	IPAllowList IPAllowListSettings `mapstructure:"ip_allowlist" json:"ip_allowlist,omitempty"`
+	AuditLogFile string             `mapstructure:"audit_log_file" json:"audit_log_file,omitempty"` // 管理员人工干预（如强制结束任务）的审计日志文件，为空时只写入服务日志
 }
 
 // CORSSettings 定义 CORS 设置
@@ -118,6 +475,19 @@ type CORSSettings struct {
 	AllowedOrigins  []string `mapstructure:"allowed_origins" json:"allowed_origins"`
 	AllowedMethods  []string `mapstructure:"allowed_methods" json:"allowed_methods"`
 	AllowedHeaders  []string `mapstructure:"allowed_headers" json:"allowed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials" json:"allow_credentials"` // 允许携带 Cookie 等凭据，不能与 "*" 来源同时使用
+	MaxAge           time.Duration `mapstructure:"max_age" json:"max_age"`                     // 预检结果的缓存时间（Access-Control-Max-Age），0 表示不返回
+	Routes           []CORSRoute   `mapstructure:"routes" json:"routes,omitempty"`             // 按路径前缀覆盖的策略，最长前缀优先
+}
+
+// CORSRoute 定义一个路径前缀的 CORS 策略，未配置的字段沿用全局设置
+type CORSRoute struct {
+	Path             string         `mapstructure:"path" json:"path"` // 路径前缀，例如 /api/v1/metrics
+	AllowedOrigins   []string       `mapstructure:"allowed_origins" json:"allowed_origins,omitempty"`
+	AllowedMethods   []string       `mapstructure:"allowed_methods" json:"allowed_methods,omitempty"`
+	AllowedHeaders   []string       `mapstructure:"allowed_headers" json:"allowed_headers,omitempty"`
+	AllowCredentials *bool          `mapstructure:"allow_credentials" json:"allow_credentials,omitempty"`
+	MaxAge           *time.Duration `mapstructure:"max_age" json:"max_age,omitempty"`
 }
 
 // RateLimitSettings 定义速率限制设置
@@ -141,6 +511,37 @@ type AuthSettings struct {
 	Token    string `mapstructure:"token" json:"token"`
 	Username string `mapstructure:"username" json:"username"`
 	Password string `mapstructure:"password" json:"password"`
+	APIKeys  []APIKeySettings `mapstructure:"api_keys" json:"api_keys,omitempty"` // 按调用方区分的 API Key
+	ObserverToken string `mapstructure:"observer_token" json:"observer_token,omitempty"` // 全局只读令牌，调用方为 observer，只能调用查询接口
+}
+
+// RoleObserver 只读调用方：可以查询任务、指标和日志级别，不能创建、取消任务或修改配置
+const RoleObserver = "observer"
+
+// APIKeySettings 定义 API Key，name 作为任务所有者记录
+type APIKeySettings struct {
+	Name  string `mapstructure:"name" json:"name"`
+	Key   string `mapstructure:"key" json:"key"`
+	Admin bool   `mapstructure:"admin" json:"admin"` // 管理员可以管理所有任务
+	Role  string `mapstructure:"role" json:"role,omitempty"` // observer 表示只读调用方，不能与 admin 同时配置
+
+	// 按 Key 的限制，0 表示不限制
+	RequestsPerSecond  float64 `mapstructure:"requests_per_second" json:"requests_per_second,omitempty"`
+	Burst              int     `mapstructure:"burst" json:"burst,omitempty"`
+	MaxConcurrentTasks int     `mapstructure:"max_concurrent_tasks" json:"max_concurrent_tasks,omitempty"`
+}
+
+// SigningSettings 定义请求签名设置（HMAC-SHA256，适用于无法部署 TLS 的管理网络）
+type SigningSettings struct {
+	Enabled bool          `mapstructure:"enabled" json:"enabled"`
+	Secret  string        `mapstructure:"secret" json:"secret"`
+	MaxSkew time.Duration `mapstructure:"max_skew" json:"max_skew"` // 允许的时钟偏差，同时决定防重放窗口
+}
+
+// IPAllowListSettings 定义数据面 API 的客户端地址白名单
+type IPAllowListSettings struct {
+	Enabled bool     `mapstructure:"enabled" json:"enabled"`
+	CIDRs   []string `mapstructure:"cidrs" json:"cidrs"` // 允许的网段，例如 RDMA 管理子网 10.0.0.0/24
 }
 
 // CombinedLoggingSettings 定义统一日志设置
@@ -161,6 +562,23 @@ type MutexSettings struct {
 	CheckTimeout  time.Duration `mapstructure:"check_timeout" json:"check_timeout"`
 	RetryCount    int           `mapstructure:"retry_count" json:"retry_count"`
 	RetryInterval time.Duration `mapstructure:"retry_interval" json:"retry_interval"`
+	LockFile      string        `mapstructure:"lock_file" json:"lock_file"` // 服务端实例锁文件
+}
+
+// DetectionSettings 定义自动模式检测设置
+type DetectionSettings struct {
+	PeerAddresses []string          `mapstructure:"peer_addresses" json:"peer_addresses"` // 显式对端地址列表（host 或 host:port）
+	DeviceRoles   map[string]string `mapstructure:"device_roles" json:"device_roles"`     // RDMA 设备角色映射，例如 mlx5_0: server
+	ProbeTimeout  time.Duration     `mapstructure:"probe_timeout" json:"probe_timeout"`
+	Handoff       HandoffSettings   `mapstructure:"handoff" json:"handoff"` // 运行时自动切换角色
+}
+
+// HandoffSettings 定义统一模式运行时的自动角色切换设置
+// 客户端角色连续检测到服务端离线时切换为服务端，服务端角色连续检测到 peer_addresses 中的服务端在线时切换为客户端
+type HandoffSettings struct {
+	Enabled          bool          `mapstructure:"enabled" json:"enabled"`
+	CheckInterval    time.Duration `mapstructure:"check_interval" json:"check_interval"`
+	FailureThreshold int           `mapstructure:"failure_threshold" json:"failure_threshold"` // 连续多少次检查满足条件后切换
 }
 
 // SingleTransferSettings 定义单次传输设置
@@ -177,7 +595,8 @@ type ClientSpecificSettings struct {
 	EnableChecksum       bool          `mapstructure:"enable_checksum" json:"enable_checksum"`
 	ChecksumAlgorithm    string        `mapstructure:"checksum_algorithm" json:"checksum_algorithm"`
 	EnableResume         bool          `mapstructure:"enable_resume" json:"enable_resume"`
-	ResumeCheckInterval  time.Duration `mapstructure:"resume_check_interval" json:"resume_check_interval"`
+	ResumeCheckInterval  time.Duration `mapstructure:"resume_check_interval" json:"resume_check_interval"` // 执行传输期间写入检查点的间隔
+	CheckpointDir        string        `mapstructure:"checkpoint_dir" json:"checkpoint_dir,omitempty"`     // 启用续传时保存各任务检查点文件的目录
 }
 
 // GetDefaultServerConfig 获取默认服务端配置
@@ -190,6 +609,13 @@ func GetDefaultServerConfig() *ServerConfig {
 			ReadTimeout:    30 * time.Second,
 			WriteTimeout:   30 * time.Second,
 			MaxHeaderBytes: 1048576,
+			ShutdownTimeout: DefaultShutdownTimeout,
+			IdleTimeout:    DefaultIdleTimeout,
+			Compression: CompressionSettings{
+				Enabled: true,
+				MinSize: 1024,
+			},
+			Language: "zh",
 		},
 		Transfer: TransferSettings{
 			Device:                "mlx5_0",
@@ -197,14 +623,86 @@ func GetDefaultServerConfig() *ServerConfig {
 			TransferInterval:      5 * time.Second,
 			MaxConcurrentTransfers: 1,
 			ChunkSize:             4194304, // 4MB
+			JournalFile:           "/var/lib/rtrans/journal/tasks.jsonl",
+			ChunkManifest:         false,
+			QoSClasses:            DefaultQoSClasses(),
+			AutoMode:              DefaultAutoModeSettings(),
+			Preallocate:           true,
+			HeartbeatTimeout:      60 * time.Second,
+			PreparedTTL:           5 * time.Minute,
+			NetworkFSPolicy:       NetworkFSWarn,
+			PartialSuffix:         DefaultPartialSuffix,
+			Scrub: ScrubSettings{
+				Enabled:  false,
+				Interval: 24 * time.Hour,
+			},
+			ShutdownGracePeriod: 0,
+			ProcessStopTimeout:    10 * time.Second,
+			ProcessCleanupTimeout: 5 * time.Second,
+			WarmListeners: WarmListenerSettings{
+				Enabled:       false,
+				CheckInterval: 10 * time.Second,
+			},
+			SessionReuse: SessionReuseSettings{
+				Enabled:      false,
+				MaxTransfers: 0,
+				IdleTimeout:  30 * time.Second,
+			},
+			Accounting: AccountingSettings{
+				Enabled:   false,
+				Period:    time.Hour,
+				File:      "/var/lib/rtrans/accounting/usage.jsonl",
+				Retention: 90 * 24 * time.Hour,
+			},
+			Rtranfile: RtranfileSettings{
+				Enabled:         false,
+				Dir:             "/var/lib/rtrans/rtranfile",
+				PublicKeys:      []string{},
+				MaxSize:         256 << 20,
+				DownloadTimeout: 5 * time.Minute,
+			},
+			Autoscale: AutoscaleSettings{
+				Enabled:         false,
+				MinConcurrent:   1,
+				MaxConcurrent:   8,
+				Interval:        10 * time.Second,
+				HighUtilization: 0.9,
+				LowUtilization:  0.5,
+			},
+			ChunkTuning: ChunkTuningSettings{
+				Enabled:      false,
+				Apply:        false,
+				Candidates:   []int{1 << 20, 4 << 20, 16 << 20, 64 << 20},
+				MinSamples:   3,
+				MinFileSize:  64 << 20,
+				ExploreEvery: 20,
+				StateFile:    "/var/lib/rtrans/tuning/chunk_sizes.json",
+			},
+			Pipeline: PipelineSettings{
+				Enabled:   false,
+				Lookahead: 2,
+				IOWorkers: 2,
+			},
+			StageIO: StageIOSettings{
+				Engine:     StageIOAuto,
+				QueueDepth: 8,
+			},
+			HugepagePool: HugepagePoolSettings{
+				Enabled:        false,
+				Dir:            "/dev/hugepages/pool",
+				Size:           16 << 30,
+				SegmentSize:    1 << 30,
+				RefillInterval: 10 * time.Second,
+			},
 			Modes: TransferModes{
 				Hugepages: ModeConfig{
 					Enabled: true,
 					BaseDir: "/dev/hugepages/dir",
 				},
 				Tmpfs: ModeConfig{
-					Enabled: true,
-					BaseDir: "/dev/shm/dir",
+					Enabled:              true,
+					BaseDir:              "/dev/shm/dir",
+					MaxFileSizeMemoryPct: 50,
 				},
 				Filesystem: ModeConfig{
 					Enabled: true,
@@ -222,6 +720,7 @@ func GetDefaultServerConfig() *ServerConfig {
 		},
 		Monitoring: MonitoringSettings{
 			HealthCheckInterval: 30 * time.Second,
+			LinkCheckInterval:   5 * time.Second,
 			EnableMetrics:       true,
 			MetricsPort:         9090,
 		},
@@ -231,6 +730,7 @@ func GetDefaultServerConfig() *ServerConfig {
 				AllowedOrigins:  []string{"*"},
 				AllowedMethods:  []string{"GET", "POST", "DELETE"},
 				AllowedHeaders:  []string{"Content-Type", "Authorization"},
+				MaxAge:          10 * time.Minute,
 			},
 			RateLimit: RateLimitSettings{
 				Enabled:           true,
@@ -238,6 +738,13 @@ func GetDefaultServerConfig() *ServerConfig {
 				Burst:             20,
 			},
 		},
+		LeaderElection: LeaderElectionSettings{
+			Enabled:       false,
+			LeaseName:     "rdma-burst-coordinator",
+			LeaseDuration: 15 * time.Second,
+			RenewDeadline: 10 * time.Second,
+			RetryPeriod:   2 * time.Second,
+		},
 	}
 }
 
@@ -252,11 +759,19 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 			ReadTimeout:    30 * time.Second,
 			WriteTimeout:   30 * time.Second,
 			MaxHeaderBytes: 1048576,
+			ShutdownTimeout: DefaultShutdownTimeout,
+			IdleTimeout:    DefaultIdleTimeout,
+			Compression: CompressionSettings{
+				Enabled: true,
+				MinSize: 1024,
+			},
+			Language: "zh",
 		},
 		Client: ClientServerSettings{
 			Host:         "localhost",
 			Port:         8080,
 			Timeout:      30 * time.Second,
+			WaitTimeout:  10 * time.Minute,
 			RetryAttempts: 3,
 			RetryDelay:   5 * time.Second,
 		},
@@ -266,6 +781,66 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 			TransferInterval:      5 * time.Second,
 			MaxConcurrentTransfers: 1,
 			ChunkSize:             4194304, // 4MB
+			JournalFile:           "/var/lib/rtrans/journal/tasks.jsonl",
+			ChunkManifest:         false,
+			QoSClasses:            DefaultQoSClasses(),
+			AutoMode:              DefaultAutoModeSettings(),
+			Preallocate:           true,
+			HeartbeatTimeout:      60 * time.Second,
+			PreparedTTL:           5 * time.Minute,
+			NetworkFSPolicy:       NetworkFSWarn,
+			PartialSuffix:         DefaultPartialSuffix,
+			Scrub: ScrubSettings{
+				Enabled:  false,
+				Interval: 24 * time.Hour,
+			},
+			ShutdownGracePeriod: 0,
+			ProcessStopTimeout:    10 * time.Second,
+			ProcessCleanupTimeout: 5 * time.Second,
+			WarmListeners: WarmListenerSettings{
+				Enabled:       false,
+				CheckInterval: 10 * time.Second,
+			},
+			SessionReuse: SessionReuseSettings{
+				Enabled:      false,
+				MaxTransfers: 0,
+				IdleTimeout:  30 * time.Second,
+			},
+			Accounting: AccountingSettings{
+				Enabled:   false,
+				Period:    time.Hour,
+				File:      "/var/lib/rtrans/accounting/usage.jsonl",
+				Retention: 90 * 24 * time.Hour,
+			},
+			Rtranfile: RtranfileSettings{
+				Enabled:         false,
+				Dir:             "/var/lib/rtrans/rtranfile",
+				PublicKeys:      []string{},
+				MaxSize:         256 << 20,
+				DownloadTimeout: 5 * time.Minute,
+			},
+			Autoscale: AutoscaleSettings{
+				Enabled:         false,
+				MinConcurrent:   1,
+				MaxConcurrent:   8,
+				Interval:        10 * time.Second,
+				HighUtilization: 0.9,
+				LowUtilization:  0.5,
+			},
+			ChunkTuning: ChunkTuningSettings{
+				Enabled:      false,
+				Apply:        false,
+				Candidates:   []int{1 << 20, 4 << 20, 16 << 20, 64 << 20},
+				MinSamples:   3,
+				MinFileSize:  64 << 20,
+				ExploreEvery: 20,
+				StateFile:    "/var/lib/rtrans/tuning/chunk_sizes.json",
+			},
+			Pipeline: PipelineSettings{
+				Enabled:   false,
+				Lookahead: 2,
+				IOWorkers: 2,
+			},
 			DefaultMode:           "filesystem",
 			Modes: TransferModes{
 				Hugepages: ModeConfig{
@@ -273,8 +848,9 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 					BaseDir: "/dev/hugepages/dir",
 				},
 				Tmpfs: ModeConfig{
-					Enabled: true,
-					BaseDir: "/dev/shm/dir",
+					Enabled:              true,
+					BaseDir:              "/dev/shm/dir",
+					MaxFileSizeMemoryPct: 50,
 				},
 				Filesystem: ModeConfig{
 					Enabled: true,
@@ -303,6 +879,7 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 		Monitoring: CombinedMonitoringSettings{
 			Server: MonitoringSettings{
 				HealthCheckInterval: 30 * time.Second,
+				LinkCheckInterval:   5 * time.Second,
 				EnableMetrics:       true,
 				MetricsPort:         9090,
 			},
@@ -317,6 +894,7 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 				AllowedOrigins:  []string{"*"},
 				AllowedMethods:  []string{"GET", "POST", "DELETE"},
 				AllowedHeaders:  []string{"Content-Type", "Authorization"},
+				MaxAge:          10 * time.Minute,
 			},
 			RateLimit: RateLimitSettings{
 				Enabled:           true,
@@ -329,6 +907,10 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 			Auth: AuthSettings{
 				Enabled: false,
 			},
+			Signing: SigningSettings{
+				Enabled: false,
+				MaxSkew: 5 * time.Minute,
+			},
 		},
 		ClientSpecific: ClientSpecificSettings{
 			MaxParallelTransfers: 1,
@@ -336,12 +918,14 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 			ChecksumAlgorithm:    "sha256",
 			EnableResume:         true,
 			ResumeCheckInterval:  10 * time.Second,
+			CheckpointDir:        "/var/lib/rtrans/checkpoints",
 		},
 		Mutex: MutexSettings{
 			Enabled:       true,
 			CheckTimeout:  3 * time.Second,
 			RetryCount:    3,
 			RetryInterval: 1 * time.Second,
+			LockFile:      "/var/run/rtrans/rdma-burst.lock",
 		},
 		SingleTransfer: SingleTransferSettings{
 			Enabled:          true,
@@ -349,6 +933,42 @@ func GetDefaultCombinedConfig() *CombinedConfig {
 			RequireReconnect: true,
 			KeepAliveTimeout: 10 * time.Second,
 		},
+		Detection: DetectionSettings{
+			PeerAddresses: []string{},
+			DeviceRoles:   map[string]string{},
+			ProbeTimeout:  3 * time.Second,
+			Handoff: HandoffSettings{
+				Enabled:          false,
+				CheckInterval:    10 * time.Second,
+				FailureThreshold: 3,
+			},
+		},
+		Alerting: AlertingSettings{
+			Enabled:       false,
+			Interval:      15 * time.Second,
+			FailureWindow: 5 * time.Minute,
+			StallTimeout:  2 * time.Minute,
+			Rules:         []AlertRule{},
+		},
+		Notifications: NotificationSettings{
+			Enabled:       false,
+			CheckInterval: 30 * time.Second,
+			StallTimeout:  5 * time.Minute,
+			Events: map[string]string{
+				"task_failed":    "critical",
+				"task_stalled":   "warning",
+				"file_corrupted": "critical",
+			},
+			Severities: map[string]NotificationRoute{},
+			Channels:   map[string]NotificationChannel{},
+		},
+		LeaderElection: LeaderElectionSettings{
+			Enabled:       false,
+			LeaseName:     "rdma-burst-coordinator",
+			LeaseDuration: 15 * time.Second,
+			RenewDeadline: 10 * time.Second,
+			RetryPeriod:   2 * time.Second,
+		},
 	}
 }
 
@@ -359,6 +979,7 @@ func GetDefaultClientConfig() *ClientConfig {
 			Host:         "localhost",
 			Port:         8080,
 			Timeout:      30 * time.Second,
+			WaitTimeout:  10 * time.Minute,
 			RetryAttempts: 3,
 			RetryDelay:   5 * time.Second,
 		},
@@ -367,15 +988,44 @@ func GetDefaultClientConfig() *ClientConfig {
 			BaseDir:          "/var/lib/rtrans",
 			TransferInterval: 5 * time.Second,
 			ChunkSize:        4194304, // 4MB
+			QoSClasses:       DefaultQoSClasses(),
+			AutoMode:         DefaultAutoModeSettings(),
+			Preallocate:      true,
 			DefaultMode:      "filesystem",
+			ChunkTuning: ChunkTuningSettings{
+				Enabled:      false,
+				Apply:        false,
+				Candidates:   []int{1 << 20, 4 << 20, 16 << 20, 64 << 20},
+				MinSamples:   3,
+				MinFileSize:  64 << 20,
+				ExploreEvery: 20,
+				StateFile:    "/var/lib/rtrans/tuning/chunk_sizes.json",
+			},
+			Pipeline: PipelineSettings{
+				Enabled:   false,
+				Lookahead: 2,
+				IOWorkers: 2,
+			},
+			StageIO: StageIOSettings{
+				Engine:     StageIOAuto,
+				QueueDepth: 8,
+			},
+			HugepagePool: HugepagePoolSettings{
+				Enabled:        false,
+				Dir:            "/dev/hugepages/pool",
+				Size:           16 << 30,
+				SegmentSize:    1 << 30,
+				RefillInterval: 10 * time.Second,
+			},
 			Modes: TransferModes{
 				Hugepages: ModeConfig{
 					Enabled: true,
 					BaseDir: "/dev/hugepages/dir",
 				},
 				Tmpfs: ModeConfig{
-					Enabled: true,
-					BaseDir: "/dev/shm/dir",
+					Enabled:              true,
+					BaseDir:              "/dev/shm/dir",
+					MaxFileSizeMemoryPct: 50,
 				},
 				Filesystem: ModeConfig{
 					Enabled: true,
@@ -402,6 +1052,10 @@ func GetDefaultClientConfig() *ClientConfig {
 			Auth: AuthSettings{
 				Enabled: false,
 			},
+			Signing: SigningSettings{
+				Enabled: false,
+				MaxSkew: 5 * time.Minute,
+			},
 		},
 		Client: ClientSpecificSettings{
 			MaxParallelTransfers: 1,
@@ -409,6 +1063,7 @@ func GetDefaultClientConfig() *ClientConfig {
 			ChecksumAlgorithm:    "sha256",
 			EnableResume:         true,
 			ResumeCheckInterval:  10 * time.Second,
+			CheckpointDir:        "/var/lib/rtrans/checkpoints",
 		},
 	}
 }
\ No newline at end of file
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 响应格式（format 查询参数）
+const (
+	FormatDefault = ""      // 字节数为整数，耗时同时以 Go 时长字符串和秒数返回
+	FormatHuman   = "human" // 另外返回 human 字段：ISO-8601 时长和带单位的字节数、速率
+)
+
+// HumanProgress 定义便于阅读的进度字段，请求 format=human 时返回
+type HumanProgress struct {
+	BytesTransferred string `json:"bytes_transferred"`        // 例如 "25.0 MiB"
+	TotalBytes       string `json:"total_bytes"`              // 总字节数未知时为 "0 B"
+	TransferRate     string `json:"transfer_rate,omitempty"`  // 例如 "1.22 GiB/s"
+	ElapsedTime      string `json:"elapsed_time,omitempty"`   // ISO-8601 时长，例如 "PT1M5.2S"
+	EstimatedTime    string `json:"estimated_time,omitempty"` // ISO-8601 时长
+	PollAfter        string `json:"poll_after,omitempty"`     // ISO-8601 时长
+}
+
+// Humanize 按数值字段填充 Human
+func (p *ProgressResponse) Humanize() {
+	p.Human = &HumanProgress{
+		BytesTransferred: FormatBytes(p.BytesTransferred),
+		TotalBytes:       FormatBytes(p.TotalBytes),
+		ElapsedTime:      FormatISODuration(secondsDuration(p.ElapsedSeconds)),
+		EstimatedTime:    FormatISODuration(secondsDuration(p.EstimatedSeconds)),
+		PollAfter:        FormatISODuration(p.PollAfter),
+	}
+	if p.TransferRate > 0 {
+		p.Human.TransferRate = FormatBytes(int64(p.TransferRate*1024*1024)) + "/s"
+	}
+}
+
+// FormatBytes 以 1024 为进制格式化字节数，例如 1536 为 "1.5 KiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	value := float64(n)
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := -1
+	for math.Abs(value) >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
+
+// FormatISODuration 把时长格式化为 ISO-8601 时长（例如 "PT1H2M3.5S"），不大于 0 时返回空字符串
+func FormatISODuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if d > 0 || b.Len() == 2 {
+		// 保留到毫秒
+		b.WriteString(strconv.FormatFloat(d.Round(time.Millisecond).Seconds(), 'f', -1, 64))
+		b.WriteString("S")
+	}
+	return b.String()
+}
+
+// secondsDuration 把秒数转换为时长
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// PipelineRequest 定义流水线传输请求：按顺序传输一组文件，本地 I/O（暂存、校验和、校验、落盘）与 RDMA 传输重叠执行
+type PipelineRequest struct {
+	Name  string            `json:"name,omitempty"`
+	Items []TransferRequest `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+// Pipeline 定义流水线的执行状态
+type Pipeline struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name,omitempty"`
+	Status      string         `json:"status"`
+	Owner       string         `json:"owner,omitempty"` // 创建流水线的调用方，启用认证时设置
+	Items       []PipelineItem `json:"items"`
+	Completed   int            `json:"completed"`
+	Failed      int            `json:"failed"`
+	CreatedAt   time.Time      `json:"created_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+}
+
+// PipelineItem 定义流水线中单个文件的执行状态
+type PipelineItem struct {
+	Index        int                `json:"index"`
+	Filename     string             `json:"filename"`
+	Direction    string             `json:"direction"`
+	Stage        string             `json:"stage"`
+	TaskID       string             `json:"task_id,omitempty"` // 服务端任务ID，进入传输阶段后设置
+	Error        string             `json:"error,omitempty"`
+	StageSeconds map[string]float64 `json:"stage_seconds,omitempty"` // 已结束的各阶段耗时，按阶段名称索引
+}
+
+// 流水线状态
+const (
+	PipelineRunning   = "running"
+	PipelineCompleted = "completed" // 所有文件均已结束，部分文件可能失败
+	PipelineCancelled = "cancelled"
+)
+
+// 流水线阶段：staging 和 checksum 为传输前的本地 I/O，verify 和 finalize 为传输后的本地 I/O，与其他文件的 transfer 重叠执行
+const (
+	PipelineStageQueued    = "queued"
+	PipelineStageStaging   = "staging"  // 从对象存储拉取源文件，或复制到暂存目录（put）
+	PipelineStageChecksum  = "checksum" // 计算本地分块清单和源文件摘要（put，启用分块清单或校验和时）
+	PipelineStageReady     = "ready"    // 本地准备完成，等待传输
+	PipelineStageTransfer  = "transfer" // 在服务端创建任务并执行 rtranfile 传输
+	PipelineStageVerify    = "verify"   // 按分块清单校验传输结果
+	PipelineStageFinalize  = "finalize" // 移动到目标位置、恢复元数据、推送到对象存储
+	PipelineStageCompleted = "completed"
+	PipelineStageFailed    = "failed"
+	PipelineStageCancelled = "cancelled"
+)
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// PlanVersion 传输计划的格式版本，导入时拒绝更高的版本
+const PlanVersion = 1
+
+// MaxPlanItems 一个传输计划最多包含的传输数
+const MaxPlanItems = 1000
+
+// TransferPlan 定义传输计划：一组计划执行的传输请求，可以导出保存并在之后导入执行
+type TransferPlan struct {
+	Version   int               `json:"version,omitempty"`
+	Name      string            `json:"name,omitempty"` // 计划名称，导入时作为 plan 标签添加到每个任务
+	CreatedAt *time.Time        `json:"created_at,omitempty"`
+	Items     []TransferRequest `json:"items" binding:"required,min=1,max=1000,dive"`
+	Estimate  *PlanEstimate     `json:"estimate,omitempty"` // 导出时按历史吞吐量估算，导入时忽略
+}
+
+// PlanEstimate 定义传输计划的估算结果
+type PlanEstimate struct {
+	Items            []PlanItemEstimate `json:"items"`
+	TotalBytes       int64              `json:"total_bytes"`
+	EstimatedSeconds float64            `json:"estimated_seconds"` // 依次执行所有可估算的传输所需的时间
+	Unestimated      int                `json:"unestimated"`       // 可以执行但没有历史吞吐量或文件大小、无法估算耗时的传输数
+	Invalid          int                `json:"invalid"`           // 无法执行的传输数
+	EstimatedAt      time.Time          `json:"estimated_at"`
+}
+
+// PlanItemEstimate 定义计划中单个传输的估算
+type PlanItemEstimate struct {
+	Index            int     `json:"index"`
+	Filename         string  `json:"filename"`
+	Direction        string  `json:"direction"`
+	Mode             string  `json:"mode,omitempty"` // 实际使用的传输模式，auto 按当前配置和文件大小选择
+	ModeDecision     string  `json:"mode_decision,omitempty"`
+	Size             int64   `json:"size"`
+	ThroughputMBps   float64 `json:"throughput_mbps,omitempty"` // 最近完成的同模式传输的平均吞吐量
+	SetupSeconds     float64 `json:"setup_seconds,omitempty"`   // 最近完成的同模式传输从接受请求到启动传输进程的平均耗时
+	EstimatedSeconds float64 `json:"estimated_seconds,omitempty"`
+	Samples          int     `json:"samples"`         // 估算依据的历史传输数
+	Error            string  `json:"error,omitempty"` // 传输无法执行的原因
+}
+
+// PlanImportResponse 定义导入传输计划的结果
+type PlanImportResponse struct {
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Results []PlanImportResult `json:"results"`
+}
+
+// PlanImportResult 定义计划中单个传输的创建结果
+type PlanImportResult struct {
+	Index    int               `json:"index"`
+	Filename string            `json:"filename"`
+	Task     *TransferResponse `json:"task,omitempty"`
+	Error    *ErrorResponse    `json:"error,omitempty"`
+}
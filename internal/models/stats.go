@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ChunkSizeStats 定义一个设备上某个块大小的传输统计
+type ChunkSizeStats struct {
+	ChunkSize  int       `json:"chunk_size"`          // rtranfile 块大小（-s），0 表示 rtranfile 默认值
+	Transfers  int       `json:"transfers"`           // 计入统计的成功传输数
+	Bytes      int64     `json:"bytes"`               // 累计传输字节数
+	Seconds    float64   `json:"seconds"`             // 累计传输耗时
+	Throughput float64   `json:"throughput"`          // 每次传输吞吐量的指数加权平均（MB/s）
+	LastUsed   time.Time `json:"last_used,omitempty"` // 最近一次计入统计的时间
+}
+
+// DeviceChunkStats 定义一个 RDMA 设备的块大小统计
+type DeviceChunkStats struct {
+	Device      string           `json:"device"`
+	Recommended *int             `json:"recommended,omitempty"` // 样本数足够的块大小中吞吐量最高的，0 表示 rtranfile 默认值，样本不足时为空
+	ChunkSizes  []ChunkSizeStats `json:"chunk_sizes"`
+}
+
+// ChunkStatsResponse 定义块大小统计响应
+type ChunkStatsResponse struct {
+	Apply       bool               `json:"apply"`         // 是否自动为之后的传输使用推荐的块大小
+	Candidates  []int              `json:"candidates"`    // 自动选择时尝试的块大小
+	MinSamples  int                `json:"min_samples"`   // 块大小参与推荐需要的最少传输数
+	MinFileSize int64              `json:"min_file_size"` // 计入统计的最小文件大小
+	Devices     []DeviceChunkStats `json:"devices"`
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"fmt"
 	"time"
+
+	"rdma-burst/internal/services/manifest"
 )
 
 // TransferTask 定义传输任务
@@ -13,7 +15,16 @@ type TransferTask struct {
 	TargetPath  string    `json:"target_path"`
 	Mode        string    `json:"mode"` // hugepages, tmpfs, filesystem
 	Direction   string    `json:"direction"` // put, get
+	Type        string    `json:"type,omitempty"` // transfer（默认）, verify
+	Profile     string    `json:"profile,omitempty"` // 传输配置档案
+	QoS         string    `json:"qos,omitempty"` // QoS 等级
+	Opportunistic bool    `json:"opportunistic,omitempty"` // 机会型任务，只使用设备的空闲名额，常规任务到达时被抢占
+	Deadline    *time.Time `json:"deadline,omitempty"` // 截止时间，调度时优先准备截止时间早的任务
+	DependsOn   []string  `json:"depends_on,omitempty"` // 依赖的任务，全部成功完成后才准备
+	ResumeOffset int64    `json:"resume_offset,omitempty"` // 最近一次续传开始的字节偏移，之前的部分已在接收端
+	ModeDecision string   `json:"mode_decision,omitempty"` // auto 模式的选择依据
 	ServerIP    string    `json:"server_ip,omitempty"` // 服务端地址
+	Device      string    `json:"device,omitempty"` // 服务端 RDMA 设备
 	Status      string    `json:"status"`
 	Progress    float64   `json:"progress"`
 	BytesTransferred int64 `json:"bytes_transferred"`
@@ -22,10 +33,56 @@ type TransferTask struct {
 	EndTime     *time.Time `json:"end_time,omitempty"`
 	Error       string    `json:"error,omitempty"`
 	Message     string    `json:"message,omitempty"`
+	TraceID     string    `json:"trace_id,omitempty"` // OpenTelemetry 追踪ID
+	Owner       string    `json:"owner,omitempty"`    // 创建任务的调用方（API Key 名称或用户名）
+	Labels      map[string]string      `json:"labels,omitempty"`   // 标签，可在列表接口中过滤
+	Metadata    map[string]interface{} `json:"metadata,omitempty"` // 自由格式的元数据
+	Staging     *StagingSpec `json:"staging,omitempty"` // 对象存储暂存
+	Checksum    *FileChecksum `json:"checksum,omitempty"` // put 源文件的摘要，客户端上报完成后服务端据此校验收到的文件
+	Hooks       []HookResult `json:"hooks,omitempty"` // 传输成功后执行的钩子结果
+	Timeline    []TimelineEvent `json:"timeline,omitempty"` // 生命周期事件，按发生顺序
+	ETA         *TaskETA  `json:"eta,omitempty"` // 排队任务按历史吞吐量估算的开始和完成时间，只在列表接口中返回
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// TimelineEvent 定义任务生命周期事件
+type TimelineEvent struct {
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// TaskETA 定义排队任务的预计开始和完成时间
+type TaskETA struct {
+	EstimatedStart      time.Time  `json:"estimated_start"`
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"` // 没有同模式的历史吞吐量或文件大小未知时为空
+	ThroughputMBps      float64    `json:"throughput_mbps,omitempty"`      // 估算使用的平均吞吐量
+	Samples             int        `json:"samples"`                        // 估算依据的历史传输数
+}
+
+// 生命周期事件常量，任务结束时记录 completed、failed 或 cancelled
+const (
+	EventQueued          = "queued"           // 服务端接受请求并创建任务
+	EventDeferred        = "deferred"         // 等待配置档案的时间窗口开放
+	EventStaging         = "staging"          // 开始从对象存储拉取源文件
+	EventListenerStarted = "listener_started" // 服务端监听进程就绪
+	EventProcessSpawned  = "process_spawned"  // 客户端启动 rtranfile 进程
+	EventFirstByte       = "first_byte"       // 首次上报已传输的字节
+	EventPreempted       = "preempted"        // 机会型任务第一次被常规任务抢占
+	EventSLAMissed       = "sla_missed"       // 截止时间已过任务仍未完成
+	EventBlocked         = "blocked"          // 等待依赖的任务完成
+	EventResumed         = "resumed"          // 失败的任务第一次从检查点续传
+)
+
+// HookResult 定义钩子执行结果
+type HookResult struct {
+	Name     string        `json:"name"`
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
 // TransferConfig 定义传输配置
 type TransferConfig struct {
 	Device            string        `json:"device"`
@@ -43,10 +100,48 @@ type TransferConfig struct {
 
 // TransferRequest 定义传输请求
 type TransferRequest struct {
-	Filename  string `json:"filename" binding:"required"`
-	Mode      string `json:"mode" binding:"required,oneof=hugepages tmpfs filesystem"`
+	Filename  string `json:"filename,omitempty"` // 文件名（客户端为本地路径），指定 source_path / destination_path 时可以为空
+	SourcePath      string `json:"source_path,omitempty"`      // 发送端的文件路径：put 为客户端本地路径，get 为服务端文件名或模式基础目录中的绝对路径
+	DestinationPath string `json:"destination_path,omitempty"` // 接收端的文件路径：put 为服务端文件名或模式基础目录中的绝对路径，get 为客户端本地路径
+	Mode      string `json:"mode" binding:"required,oneof=hugepages tmpfs filesystem auto"` // auto 表示按文件大小自动选择
 	Direction string `json:"direction" binding:"required,oneof=put get"`
+	Type      string `json:"type,omitempty" binding:"omitempty,oneof=transfer verify"` // 为空表示 transfer
+	Profile   string `json:"profile,omitempty"` // 传输配置档案，档案声明的时间窗口外提交的任务会延后执行
+	Device    string `json:"device,omitempty"` // 服务端 RDMA 设备，为空时使用 transfer.device，其他设备须在 transfer.devices 中配置
+	Opportunistic bool `json:"opportunistic,omitempty"` // 机会型任务：设备调度队列中没有常规任务等待时才开始，常规任务到达而设备已满时被抢占，暂停后重新排队
+	Deadline  *time.Time `json:"deadline,omitempty"` // 截止时间（RFC 3339），排队时截止时间早的任务优先，超过截止时间仍未完成时记录 sla_missed
+	DependsOn []string   `json:"depends_on,omitempty"` // 依赖的任务 ID，全部成功完成后才准备；导入传输计划时可以用 @序号 引用计划中之前的传输
+	QoS       string `json:"qos,omitempty"` // QoS 等级（bulk、interactive、critical 或配置中的自定义等级），为空时使用配置档案的等级
 	ServerIP  string `json:"server_ip,omitempty"` // 客户端使用
+	Size      int64  `json:"size,omitempty"` // 文件大小（字节），auto 模式的 put 请求必须提供
+	OnConflict string `json:"on_conflict,omitempty" binding:"omitempty,oneof=fail overwrite rename version"` // 接收端已有同名文件时的处理策略，为空时使用配置档案的策略，默认覆盖
+	Namespace string `json:"namespace,omitempty" binding:"omitempty,oneof=none owner task"` // get 的目标文件所在的命名空间子目录，为空时使用配置档案的设置，默认不使用
+	Manifest  *manifest.Manifest     `json:"manifest,omitempty"` // verify 任务的参考清单，为空时使用服务端保存的清单
+	Labels    map[string]string      `json:"labels,omitempty"`   // 标签，例如 run_id、experiment
+	Metadata  map[string]interface{} `json:"metadata,omitempty"` // 自由格式的元数据
+	Staging   *StagingSpec           `json:"staging,omitempty"`  // 对象存储暂存：发送端传输前拉取对象，接收端传输后推送文件
+	Checksum  *FileChecksum          `json:"checksum,omitempty"` // put 源文件的摘要，服务端收到文件后校验，不一致时任务失败
+}
+
+// FileChecksum 定义整文件摘要
+type FileChecksum struct {
+	Algorithm string `json:"algorithm"` // md5、sha1 或 sha256
+	Value     string `json:"value"`     // 十六进制摘要
+}
+
+// StagingSpec 定义传输任务的对象存储暂存
+// pull 在发送端（put 为客户端，get 为服务端）传输前把对象下载到传输模式目录中的文件
+// push 在接收端（put 为服务端，get 为客户端）传输完成后把收到的文件上传到对象存储，上传完成后任务才算完成
+type StagingSpec struct {
+	Pull *ObjectRef `json:"pull,omitempty"`
+	Push *ObjectRef `json:"push,omitempty"`
+}
+
+// ObjectRef 定义对象存储中的对象
+type ObjectRef struct {
+	Store  string `json:"store,omitempty"` // transfer.object_stores 中的名称，为空时使用 default
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
 }
 
 // TransferResponse 定义传输响应
@@ -55,9 +150,54 @@ type TransferResponse struct {
 	Status       string    `json:"status"`
 	Message      string    `json:"message"`
 	ClientCommand string   `json:"client_command,omitempty"`
+	Mode         string    `json:"mode,omitempty"`          // 实际使用的传输模式（请求为 auto 时）
+	ModeDecision string    `json:"mode_decision,omitempty"` // auto 模式的选择依据
+	Size         int64     `json:"size,omitempty"`          // 文件大小（字节），get 时为服务端文件大小，客户端据此预分配目标文件
+	TargetFilename string  `json:"target_filename,omitempty"` // put 时客户端发送使用的文件名（rename 策略或临时文件后缀可能与请求不同）；续传时为剩余部分使用的文件名
+	ResumeOffset int64     `json:"resume_offset,omitempty"` // 续传开始的字节偏移，客户端只传输该偏移之后的部分
+	HeartbeatInterval time.Duration `json:"heartbeat_interval,omitempty"` // 客户端执行传输期间发送心跳的间隔
+	Warnings     []string  `json:"warnings,omitempty"`      // 预检告警（例如链路预计占满、MTU 不匹配），不阻止传输
+	TraceID      string    `json:"trace_id,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// HeartbeatRequest 定义客户端执行传输期间上报的心跳
+type HeartbeatRequest struct {
+	State string `json:"state" binding:"required,oneof=running completed failed"` // 客户端传输状态
+	Error string `json:"error,omitempty"`                                         // 失败原因（state 为 failed 时）
+}
+
+// ProgressReport 定义客户端执行传输期间上报的进度，同时视为一次心跳
+type ProgressReport struct {
+	State            string  `json:"state" binding:"required,oneof=running completed failed"` // 客户端传输状态
+	BytesTransferred int64   `json:"bytes_transferred"`
+	TotalBytes       int64   `json:"total_bytes,omitempty"`   // 为 0 时使用创建任务时的文件大小
+	TransferRate     float64 `json:"transfer_rate,omitempty"` // MB/s
+	Error            string  `json:"error,omitempty"`         // 失败原因（state 为 failed 时）
+	ProcessStartedAt *time.Time `json:"process_started_at,omitempty"` // 客户端启动 rtranfile 进程的时间
+	Checkpoint       int64      `json:"checkpoint,omitempty"`         // 客户端启用续传时已完整传输的字节偏移（按块对齐），任务失败后服务端据此保留续传状态
+	Checksum         *FileChecksum `json:"checksum,omitempty"`        // put 源文件的摘要（state 为 completed 时），创建任务时没有附带摘要的任务据此校验收到的文件
+}
+
+// ResumeRequest 定义续传请求
+type ResumeRequest struct {
+	Offset int64 `json:"offset,omitempty" binding:"min=0"` // 客户端检查点记录的字节偏移，为 0 时使用服务端保留的偏移；put 不超过服务端已接收的字节数
+}
+
+// HeartbeatResponse 定义心跳响应
+type HeartbeatResponse struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // 未收到下一次心跳时会话过期的时间
+}
+
+// 心跳上报的客户端传输状态
+const (
+	HeartbeatRunning   = "running"
+	HeartbeatCompleted = "completed"
+	HeartbeatFailed    = "failed"
+)
+
 // ProgressResponse 定义进度响应
 type ProgressResponse struct {
 	ID               string    `json:"id"`
@@ -68,8 +208,14 @@ type ProgressResponse struct {
 	TransferRate     float64   `json:"transfer_rate"` // MB/s
 	ElapsedTime      string    `json:"elapsed_time"`
 	EstimatedTime    string    `json:"estimated_time,omitempty"`
+	ElapsedSeconds   float64   `json:"elapsed_seconds"`             // 与 elapsed_time 相同，以秒为单位
+	EstimatedSeconds float64   `json:"estimated_seconds,omitempty"` // 与 estimated_time 相同，以秒为单位
 	Error            string    `json:"error,omitempty"`
 	LastUpdated      time.Time `json:"last_updated"`
+	PollAfter        time.Duration `json:"poll_after,omitempty"` // 建议的下一次状态查询间隔，任务结束后为空
+	Restored         bool      `json:"restored,omitempty"`    // 上报进度的响应：会话在服务端守护进程重启后从预写日志恢复，客户端的传输可能已中断
+	Timeline         []TimelineEvent `json:"timeline,omitempty"` // 任务生命周期事件
+	Human            *HumanProgress  `json:"human,omitempty"`    // 请求 format=human 时返回的便于阅读的字段
 }
 
 // TaskListResponse 定义任务列表响应
@@ -80,6 +226,44 @@ type TaskListResponse struct {
 	Size  int            `json:"size"`
 }
 
+// StageFileRequest 定义本地暂存请求：把服务端本机的文件复制或硬链接到传输模式目录，作为 RDMA 传输前的第一步
+type StageFileRequest struct {
+	Source     string `json:"source" binding:"required"` // 服务端本机的源文件绝对路径，必须位于 transfer.stage_source_dirs 中
+	Mode       string `json:"mode" binding:"required"`   // 目标传输模式 hugepages|tmpfs|filesystem
+	Filename   string `json:"filename,omitempty"`        // 模式目录中的文件名，为空时使用源文件名
+	Method     string `json:"method,omitempty"`          // auto|copy|link|reflink，auto 依次尝试硬链接、reflink，都不可用时复制
+	OnConflict string `json:"on_conflict,omitempty"`     // 目标文件已存在时的策略，默认 overwrite
+}
+
+// StageJob 定义本地暂存任务
+type StageJob struct {
+	ID          string     `json:"id"`
+	Source      string     `json:"source"`
+	Mode        string     `json:"mode"`
+	Target      string     `json:"target"` // 目标路径，rename 策略选择新文件名后为最终路径
+	Method      string     `json:"method"` // 实际使用的方式 copy、link 或 reflink
+	IOEngine    string     `json:"io_engine,omitempty"` // 复制时实际使用的 I/O 方式 io_uring 或 sync
+	Status      string     `json:"status"`
+	Message     string     `json:"message,omitempty"`
+	TotalBytes  int64      `json:"total_bytes"`
+	BytesCopied int64      `json:"bytes_copied"`
+	Progress    float64    `json:"progress"`
+	CopyRate    float64    `json:"copy_rate"` // MB/s
+	Error       string     `json:"error,omitempty"`
+	Owner       string     `json:"owner,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+}
+
+// 本地暂存方式
+const (
+	StageMethodAuto    = "auto"
+	StageMethodCopy    = "copy"
+	StageMethodLink    = "link"
+	StageMethodReflink = "reflink" // 写时复制的克隆（btrfs、XFS 等），与源文件共享数据块但互不影响
+)
+
 // HealthResponse 定义健康检查响应
 type HealthResponse struct {
 	Status    string `json:"status"`
@@ -87,17 +271,90 @@ type HealthResponse struct {
 	Version   string `json:"version"`
 }
 
+// MetricsResponse 定义服务指标响应
+type MetricsResponse struct {
+	Service   ServiceMetrics  `json:"service"`
+	Transfers TransferMetrics `json:"transfers"`
+	System    SystemMetrics   `json:"system"`
+}
+
+// ServiceMetrics 定义服务信息
+type ServiceMetrics struct {
+	Name          string  `json:"name"`
+	Version       string  `json:"version"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	StartTime     string  `json:"start_time"`
+}
+
+// TransferMetrics 定义传输任务计数
+type TransferMetrics struct {
+	Active int `json:"active"`
+	Total  int `json:"total"`
+}
+
+// SystemMetrics 定义服务进程的资源使用，用于长时间运行时发现泄漏
+type SystemMetrics struct {
+	Goroutines     int    `json:"goroutines"`
+	OpenFDs        int    `json:"open_fds"`         // 打开的文件描述符数，无法获取时为 -1
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"` // 堆上正在使用的字节数
+	HeapObjects    uint64 `json:"heap_objects"`
+	SysBytes       uint64 `json:"sys_bytes"` // 从操作系统获取的内存字节数
+	Timestamp      string `json:"timestamp"`
+}
+
+// TransferModeInfo 定义传输模式是否启用及其限制
+type TransferModeInfo struct {
+	Mode          string `json:"mode"`
+	Enabled       bool   `json:"enabled"`
+	MaxConcurrent int    `json:"max_concurrent,omitempty"` // 该模式的并发上限，为 0 时不单独限制
+	MaxFileSize   int64  `json:"max_file_size,omitempty"`  // 生效的单个文件大小上限（字节），为 0 时不限制
+	Availability  *ModeAvailability `json:"availability,omitempty"` // 当前能否接受传输，服务端提供
+}
+
+// ModeAvailability 定义传输模式当前能否接受传输
+type ModeAvailability struct {
+	Available     bool   `json:"available"`
+	Reason        string `json:"reason,omitempty"`         // 不可用的原因
+	FreeBytes     int64  `json:"free_bytes"`               // 目录所在文件系统的剩余空间，hugepages 为空闲的大页
+	TotalBytes    int64  `json:"total_bytes"`
+	ReservedBytes int64  `json:"reserved_bytes,omitempty"` // 大页缓冲池预留、可以租给 put 传输的字节数
+	NetworkFS     string `json:"network_fs,omitempty"`     // 目录所在的网络文件系统类型
+	Active        int    `json:"active"`                   // 该模式的活跃任务数
+	Listener      string `json:"listener"`                 // 服务端监听进程状态：running 或 stopped
+	Warm          bool   `json:"warm,omitempty"`           // 是否为常驻监听进程
+}
+
+// 服务端监听进程状态
+const (
+	ListenerRunning = "running"
+	ListenerStopped = "stopped" // 没有常驻监听进程时在准备传输时启动
+)
+
+// TransferModesResponse 定义传输模式列表响应
+type TransferModesResponse struct {
+	Modes     []TransferModeInfo `json:"modes"`
+	Enabled   []string           `json:"enabled"`              // 已启用的模式，至少一个模式启用时包含 auto
+	Available []string           `json:"available,omitempty"`  // 当前可以接受传输的模式，至少一个模式可用时包含 auto
+	CheckedAt *time.Time         `json:"checked_at,omitempty"` // 检查可用情况的时间
+}
+
 // ErrorResponse 定义错误响应
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	Detail  string `json:"detail,omitempty"` // 响应语言不是中文时为按消息目录翻译的详细消息，message 为消息目录中错误码的说明
 }
 
 // 状态常量
 const (
 	StatusPending    = "pending"
 	StatusPrepared   = "prepared"  // 传输环境准备就绪
+	StatusDeferred   = "deferred"  // 等待配置档案的时间窗口开放
+	StatusStaging    = "staging"   // 服务端正在从对象存储拉取源文件
+	StatusQueued     = "queued"    // 在 RDMA 设备的调度队列中等待并发名额
+	StatusPaused     = "paused"    // 机会型任务被常规任务抢占，在设备的调度队列中等待重新开始
+	StatusBlocked    = "blocked"   // 等待依赖的任务成功完成
 	StatusStarting   = "starting"
 	StatusInProgress = "in_progress"
 	StatusCompleted  = "completed"
@@ -110,6 +367,22 @@ const (
 	ModeHugepages  = "hugepages"
 	ModeTmpfs      = "tmpfs"
 	ModeFilesystem = "filesystem"
+	ModeAuto       = "auto" // 按文件大小自动选择，只用于请求
+)
+
+// 冲突策略常量
+const (
+	ConflictFail      = "fail"      // 目标文件已存在时拒绝
+	ConflictOverwrite = "overwrite" // 覆盖已有文件
+	ConflictRename    = "rename"    // 使用不冲突的新文件名 <名称>.N<扩展名>
+	ConflictVersion   = "version"   // 已有文件重命名为 <文件>.~N~ 后写入
+)
+
+// 目标文件命名空间常量
+const (
+	NamespaceNone  = "none"  // 直接写入目标目录
+	NamespaceOwner = "owner" // 写入目标目录中以调用方命名的子目录
+	NamespaceTask  = "task"  // 写入目标目录中以任务 ID 命名的子目录
 )
 
 // 传输方向常量
@@ -118,6 +391,17 @@ const (
 	DirectionGet = "get"
 )
 
+// 任务类型常量
+const (
+	TaskTypeTransfer = "transfer" // 传输数据
+	TaskTypeVerify   = "verify"   // 只重新计算并比较两端文件的分块摘要，不传输数据
+)
+
+// IsVerify 判断是否为只校验的请求
+func (r *TransferRequest) IsVerify() bool {
+	return r.Type == TaskTypeVerify
+}
+
 // NewTransferTask 创建新的传输任务
 func NewTransferTask(filename, mode, direction string) *TransferTask {
 	now := time.Now()
@@ -128,6 +412,7 @@ func NewTransferTask(filename, mode, direction string) *TransferTask {
 		Direction:   direction,
 		Status:      StatusPending,
 		Progress:    0,
+		Timeline:    []TimelineEvent{{Event: EventQueued, Time: now}},
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -144,6 +429,7 @@ func NewTransferTaskWithServer(filename, mode, direction, serverIP string) *Tran
 		ServerIP:    serverIP,
 		Status:      StatusPending,
 		Progress:    0,
+		Timeline:    []TimelineEvent{{Event: EventQueued, Time: now}},
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -181,6 +467,7 @@ func (t *TransferTask) MarkCompleted() {
 	t.Progress = 100
 	t.EndTime = &now
 	t.UpdatedAt = now
+	t.AddEventAt(StatusCompleted, now, "")
 }
 
 // MarkFailed 标记任务失败
@@ -190,6 +477,7 @@ func (t *TransferTask) MarkFailed(errorMsg string) {
 	t.Error = errorMsg
 	t.EndTime = &now
 	t.UpdatedAt = now
+	t.AddEventAt(StatusFailed, now, "")
 }
 
 // MarkCancelled 标记任务取消
@@ -198,6 +486,63 @@ func (t *TransferTask) MarkCancelled() {
 	t.Status = StatusCancelled
 	t.EndTime = &now
 	t.UpdatedAt = now
+	t.AddEventAt(StatusCancelled, now, "")
+}
+
+// AddEvent 记录当前时间发生的生命周期事件
+func (t *TransferTask) AddEvent(event, detail string) {
+	t.AddEventAt(event, time.Now(), detail)
+}
+
+// AddEventAt 记录生命周期事件，同一事件只记录第一次
+// 时间早于上一个事件时（例如客户端时钟偏差）使用上一个事件的时间，保持时间线有序
+func (t *TransferTask) AddEventAt(event string, at time.Time, detail string) {
+	if t.HasEvent(event) {
+		return
+	}
+	if n := len(t.Timeline); n > 0 && at.Before(t.Timeline[n-1].Time) {
+		at = t.Timeline[n-1].Time
+	}
+	t.Timeline = append(t.Timeline, TimelineEvent{Event: event, Time: at, Detail: detail})
+}
+
+// HasEvent 检查是否已记录生命周期事件
+func (t *TransferTask) HasEvent(event string) bool {
+	for _, e := range t.Timeline {
+		if e.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTime 获取生命周期事件发生的时间，没有记录时返回 false
+func (t *TransferTask) EventTime(event string) (time.Time, bool) {
+	for _, e := range t.Timeline {
+		if e.Event == event {
+			return e.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MatchLabels 检查任务是否包含选择器中的所有标签
+func (t *TransferTask) MatchLabels(selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := t.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// IsQueued 检查任务是否在等待开始传输（已登记但还没有客户端开始传输）
+func (t *TransferTask) IsQueued() bool {
+	switch t.Status {
+	case StatusPending, StatusPrepared, StatusDeferred, StatusStaging, StatusQueued, StatusPaused, StatusBlocked:
+		return true
+	}
+	return false
 }
 
 // IsActive 检查任务是否活跃
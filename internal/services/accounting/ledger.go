@@ -0,0 +1,389 @@
+package accounting
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// DefaultPeriod 默认统计周期
+const DefaultPeriod = time.Hour
+
+// DefaultRetention 用量记录的默认保留时间
+const DefaultRetention = 90 * 24 * time.Hour
+
+// maxLineSize 单条记录的最大长度
+const maxLineSize = 1024 * 1024
+
+// Ledger 用量账本：结束的任务按租户计入当前统计周期，周期结束后的记录追加到文件
+// 关闭时当前周期的记录也写入文件，重启后在同一周期内继续累计；文件中同一租户同一周期的记录以最后一条为准
+type Ledger struct {
+	mu        sync.Mutex
+	period    time.Duration
+	retention time.Duration
+	file      *os.File
+	start     time.Time                           // 当前统计周期的开始时间
+	current   map[string]*models.AccountingRecord // 当前周期按租户的记录
+	charged   map[string]bool                     // 当前周期已计入的任务
+	closed    []models.AccountingRecord           // 已结束的周期的记录，按周期开始时间排序
+	cancel    context.CancelFunc
+	logger    *zap.Logger
+}
+
+// Open 创建账本并加载文件中保留时间内的记录，path 为空时只保存在内存中
+func Open(settings models.AccountingSettings) (*Ledger, error) {
+	l := &Ledger{
+		period:    settings.Period,
+		retention: settings.Retention,
+		current:   make(map[string]*models.AccountingRecord),
+		charged:   make(map[string]bool),
+		logger:    logger.GetLogger().Named(logger.ComponentTransfer).Named("accounting"),
+	}
+	if l.period <= 0 {
+		l.period = DefaultPeriod
+	}
+	if l.retention <= 0 {
+		l.retention = DefaultRetention
+	}
+	l.start = time.Now().UTC().Truncate(l.period)
+	if settings.File == "" {
+		return l, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(settings.File), 0755); err != nil {
+		return nil, fmt.Errorf("创建用量记录目录失败: %v", err)
+	}
+	records, err := load(settings.File)
+	if err != nil {
+		return nil, err
+	}
+
+	// 丢弃超过保留时间的记录，当前周期的记录继续累计，其余记录重写到文件
+	cutoff := time.Now().Add(-l.retention)
+	var kept []models.AccountingRecord
+	for _, record := range records {
+		switch {
+		case record.PeriodStart.Equal(l.start):
+			r := record
+			l.current[r.Tenant] = &r
+		case record.PeriodEnd.Before(cutoff):
+		default:
+			record.Final = true
+			kept = append(kept, record)
+		}
+	}
+	l.closed = kept
+
+	if err := rewrite(settings.File, kept); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(settings.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开用量记录文件失败: %v", err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// Period 获取统计周期
+func (l *Ledger) Period() time.Duration {
+	return l.period
+}
+
+// Start 按统计周期定期结束当前周期并写入记录
+func (l *Ledger) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	l.mu.Lock()
+	l.cancel = cancel
+	next := l.start.Add(l.period)
+	l.mu.Unlock()
+
+	go func() {
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-timer.C:
+				l.mu.Lock()
+				l.roll(now)
+				next = l.start.Add(l.period)
+				l.mu.Unlock()
+				timer.Reset(time.Until(next))
+			}
+		}
+	}()
+}
+
+// Charge 把结束的任务计入调用方当前周期的用量，同一任务只计入一次，只校验的任务不计入
+func (l *Ledger) Charge(task *models.TransferTask) {
+	if !task.IsFinished() || task.Type == models.TaskTypeVerify {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(time.Now())
+	if l.charged[task.ID] {
+		return
+	}
+	l.charged[task.ID] = true
+
+	tenant := task.Owner
+	if tenant == "" {
+		tenant = models.AnonymousTenant
+	}
+	record, ok := l.current[tenant]
+	if !ok {
+		record = &models.AccountingRecord{
+			Tenant:      tenant,
+			PeriodStart: l.start,
+			PeriodEnd:   l.start.Add(l.period),
+		}
+		l.current[tenant] = record
+	}
+	if record.BytesByMode == nil {
+		record.BytesByMode = make(map[string]int64)
+	}
+
+	record.Transfers++
+	switch task.Status {
+	case models.StatusCompleted:
+		record.Completed++
+	case models.StatusFailed:
+		record.Failed++
+	case models.StatusCancelled:
+		record.Cancelled++
+	}
+	if task.Direction == models.DirectionGet {
+		record.BytesGet += task.BytesTransferred
+	} else {
+		record.BytesPut += task.BytesTransferred
+	}
+	if task.BytesTransferred > 0 {
+		record.BytesByMode[task.Mode] += task.BytesTransferred
+	}
+	record.TransferSeconds += transferDuration(task).Seconds()
+}
+
+// Records 获取与 [from, to) 有交集的统计周期的记录，tenant 不为空时只返回该租户的记录
+// 当前周期的记录 final 为 false，之后仍会变化
+func (l *Ledger) Records(from, to time.Time, tenant string) []models.AccountingRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(time.Now())
+
+	match := func(record models.AccountingRecord) bool {
+		return (tenant == "" || record.Tenant == tenant) &&
+			record.PeriodEnd.After(from) && record.PeriodStart.Before(to)
+	}
+
+	records := []models.AccountingRecord{}
+	for _, record := range l.closed {
+		if match(record) {
+			records = append(records, record)
+		}
+	}
+	var current []models.AccountingRecord
+	for _, record := range l.current {
+		if match(*record) {
+			current = append(current, copyRecord(record))
+		}
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].Tenant < current[j].Tenant })
+	return append(records, current...)
+}
+
+// Close 停止定期写入，把当前周期的记录写入文件后关闭
+func (l *Ledger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cancel != nil {
+		l.cancel()
+		l.cancel = nil
+	}
+	if l.file == nil {
+		return nil
+	}
+
+	for _, tenant := range l.tenants() {
+		l.write(*l.current[tenant])
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// roll 当前周期已结束时写入并保存其记录，开始新的周期，调用方需持有锁
+func (l *Ledger) roll(now time.Time) {
+	start := now.UTC().Truncate(l.period)
+	if !start.After(l.start) {
+		return
+	}
+
+	for _, tenant := range l.tenants() {
+		record := *l.current[tenant]
+		record.Final = true
+		l.write(record)
+		l.closed = append(l.closed, record)
+	}
+	l.logger.Debug("用量统计周期结束",
+		zap.Time("period_start", l.start),
+		zap.Int("tenants", len(l.current)),
+	)
+
+	cutoff := now.Add(-l.retention)
+	for len(l.closed) > 0 && l.closed[0].PeriodEnd.Before(cutoff) {
+		l.closed = l.closed[1:]
+	}
+	l.start = start
+	l.current = make(map[string]*models.AccountingRecord)
+	l.charged = make(map[string]bool)
+}
+
+// tenants 获取当前周期的租户，按名称排序，调用方需持有锁
+func (l *Ledger) tenants() []string {
+	tenants := make([]string, 0, len(l.current))
+	for tenant := range l.current {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// write 把记录追加到文件并落盘，失败时只记录日志，调用方需持有锁
+func (l *Ledger) write(record models.AccountingRecord) {
+	if l.file == nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err == nil {
+		if _, err = l.file.Write(append(data, '\n')); err == nil {
+			err = l.file.Sync()
+		}
+	}
+	if err != nil {
+		l.logger.Error("写入用量记录失败",
+			zap.String("tenant", record.Tenant),
+			zap.Time("period_start", record.PeriodStart),
+			zap.Error(err),
+		)
+	}
+}
+
+// load 读取文件中的记录，同一租户同一周期保留最后一条，按周期开始时间和租户排序
+func load(path string) ([]models.AccountingRecord, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开用量记录文件失败: %v", err)
+	}
+	defer file.Close()
+
+	type key struct {
+		tenant string
+		start  int64
+	}
+	latest := make(map[key]models.AccountingRecord)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var record models.AccountingRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// 跳过写入中断的不完整记录
+			continue
+		}
+		latest[key{record.Tenant, record.PeriodStart.UnixNano()}] = record
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取用量记录文件失败: %v", err)
+	}
+
+	records := make([]models.AccountingRecord, 0, len(latest))
+	for _, record := range latest {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].PeriodStart.Equal(records[j].PeriodStart) {
+			return records[i].PeriodStart.Before(records[j].PeriodStart)
+		}
+		return records[i].Tenant < records[j].Tenant
+	})
+	return records, nil
+}
+
+// rewrite 用 records 原子地替换文件内容
+func rewrite(path string, records []models.AccountingRecord) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("重写用量记录文件失败: %v", err)
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("重写用量记录文件失败: %v", err)
+		}
+	}
+	err = writer.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("重写用量记录文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("重写用量记录文件失败: %v", err)
+	}
+	return nil
+}
+
+// transferDuration 任务从启动传输进程（或首次上报字节）到结束的耗时，没有记录时使用任务的开始时间
+func transferDuration(task *models.TransferTask) time.Duration {
+	if task.EndTime == nil {
+		return 0
+	}
+	start, ok := task.EventTime(models.EventProcessSpawned)
+	if !ok {
+		start, ok = task.EventTime(models.EventFirstByte)
+	}
+	if !ok {
+		start = task.StartTime
+	}
+	if start.IsZero() || !task.EndTime.After(start) {
+		return 0
+	}
+	return task.EndTime.Sub(start)
+}
+
+// copyRecord 复制记录，避免调用方读取时与计入并发修改
+func copyRecord(record *models.AccountingRecord) models.AccountingRecord {
+	c := *record
+	c.BytesByMode = make(map[string]int64, len(record.BytesByMode))
+	for mode, bytes := range record.BytesByMode {
+		c.BytesByMode[mode] = bytes
+	}
+	return c
+}
@@ -0,0 +1,248 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// 内置告警指标
+const (
+	MetricFailureRate = "failure_rate" // 统计窗口内失败任务占结束任务的比例（0-1）
+	MetricQueueDepth  = "queue_depth"  // 进行中的传输任务数
+	MetricStallCount  = "stall_count"  // 长时间没有进度的任务数
+)
+
+// 告警状态
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Source 告警指标数据源
+type Source interface {
+	// Value 获取指标当前值，指标不可用时返回 false
+	Value(metric string) (float64, bool)
+}
+
+// Alert 告警通知内容
+type Alert struct {
+	Rule      string    `json:"rule"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ruleState 规则评估状态
+type ruleState struct {
+	exceededSince *time.Time // 开始超过阈值的时间
+	firing        bool
+	alert         Alert
+}
+
+// Engine 按固定间隔评估告警规则，超过阈值持续 duration 后通过 webhook 通知
+type Engine struct {
+	mu       sync.RWMutex
+	rules    []models.AlertRule
+	source   Source
+	interval time.Duration
+	states   map[int]*ruleState
+	client   *http.Client
+	cancel   context.CancelFunc
+	logger   *zap.Logger
+}
+
+// NewEngine 创建告警引擎
+func NewEngine(settings *models.AlertingSettings, source Source) *Engine {
+	interval := settings.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+
+	return &Engine{
+		rules:    settings.Rules,
+		source:   source,
+		interval: interval,
+		states:   make(map[int]*ruleState),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger.GetLogger().Named(logger.ComponentMonitor).Named("alert"),
+	}
+}
+
+// SetLogger 设置日志器
+func (e *Engine) SetLogger(logger *zap.Logger) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.logger = logger
+}
+
+// Start 开始周期性评估
+func (e *Engine) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.cancel = cancel
+	e.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.Evaluate(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止评估
+func (e *Engine) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+// Evaluate 评估所有规则并发送状态变化通知
+func (e *Engine) Evaluate(ctx context.Context) {
+	now := time.Now()
+	notifications := make(map[int]Alert)
+
+	e.mu.Lock()
+	for i, rule := range e.rules {
+		value, ok := e.source.Value(rule.Metric)
+		if !ok {
+			continue
+		}
+
+		state, exists := e.states[i]
+		if !exists {
+			state = &ruleState{}
+			e.states[i] = state
+		}
+
+		if value > rule.Threshold {
+			if state.exceededSince == nil {
+				since := now
+				state.exceededSince = &since
+			}
+			state.alert.Value = value
+			if !state.firing && now.Sub(*state.exceededSince) >= rule.Duration {
+				state.firing = true
+				state.alert = Alert{
+					Rule:      ruleName(i, rule),
+					Metric:    rule.Metric,
+					Value:     value,
+					Threshold: rule.Threshold,
+					Status:    StatusFiring,
+					StartedAt: *state.exceededSince,
+					Timestamp: now,
+				}
+				notifications[i] = state.alert
+			}
+			continue
+		}
+
+		state.exceededSince = nil
+		if state.firing {
+			state.firing = false
+			state.alert.Value = value
+			state.alert.Status = StatusResolved
+			state.alert.Timestamp = now
+			notifications[i] = state.alert
+		}
+	}
+	e.mu.Unlock()
+
+	for i, alert := range notifications {
+		e.notify(ctx, e.rules[i].Webhook, alert)
+	}
+}
+
+// Alerts 获取当前触发中的告警
+func (e *Engine) Alerts() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := make([]Alert, 0)
+	for _, state := range e.states {
+		if state.firing {
+			alerts = append(alerts, state.alert)
+		}
+	}
+	return alerts
+}
+
+// notify 向 webhook 发送告警
+func (e *Engine) notify(ctx context.Context, webhook string, alert Alert) {
+	e.mu.RLock()
+	log := e.logger
+	e.mu.RUnlock()
+
+	fields := []zap.Field{
+		zap.String("rule", alert.Rule),
+		zap.String("metric", alert.Metric),
+		zap.Float64("value", alert.Value),
+		zap.Float64("threshold", alert.Threshold),
+		zap.String("status", alert.Status),
+	}
+	if alert.Status == StatusFiring {
+		log.Warn("告警触发", fields...)
+	} else {
+		log.Info("告警恢复", fields...)
+	}
+
+	if err := e.post(ctx, webhook, alert); err != nil {
+		log.Error("发送告警通知失败", append(fields, zap.String("webhook", webhook), zap.Error(err))...)
+	}
+}
+
+// post 以 JSON 格式发送告警
+func (e *Engine) post(ctx context.Context, webhook string, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回错误状态: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ruleName 获取规则名称，未配置时使用指标名和序号
+func ruleName(index int, rule models.AlertRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("%s_%d", rule.Metric, index)
+}
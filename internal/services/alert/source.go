@@ -0,0 +1,90 @@
+package alert
+
+import (
+	"sync"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/transfer"
+	"rdma-burst/pkg/metrics"
+)
+
+// sample 结束任务计数快照
+type sample struct {
+	at     time.Time
+	failed int64
+	total  int64
+}
+
+// TransferSource 基于传输指标的告警数据源
+type TransferSource struct {
+	mu              sync.Mutex
+	transferService *transfer.TransferService // 可为空（客户端模式没有本地任务表）
+	failureWindow   time.Duration
+	stallTimeout    time.Duration
+	samples         []sample
+}
+
+// NewTransferSource 创建传输告警数据源
+func NewTransferSource(transferService *transfer.TransferService, settings *models.AlertingSettings) *TransferSource {
+	failureWindow := settings.FailureWindow
+	if failureWindow <= 0 {
+		failureWindow = 5 * time.Minute
+	}
+	stallTimeout := settings.StallTimeout
+	if stallTimeout <= 0 {
+		stallTimeout = 2 * time.Minute
+	}
+
+	return &TransferSource{
+		transferService: transferService,
+		failureWindow:   failureWindow,
+		stallTimeout:    stallTimeout,
+		samples:         make([]sample, 0),
+	}
+}
+
+// Value 获取指标当前值
+func (s *TransferSource) Value(metric string) (float64, bool) {
+	switch metric {
+	case MetricFailureRate:
+		return s.failureRate(), true
+	case MetricQueueDepth:
+		return float64(metrics.ActiveTransfers()), true
+	case MetricStallCount:
+		if s.transferService == nil {
+			return 0, false
+		}
+		return float64(s.transferService.StalledTransfers(s.stallTimeout)), true
+	default:
+		return 0, false
+	}
+}
+
+// failureRate 计算统计窗口内的失败率，窗口内没有结束任务时为 0
+func (s *TransferSource) failureRate() float64 {
+	counts := metrics.FinishedCounts()
+	current := sample{
+		at:     time.Now(),
+		failed: counts[metrics.ResultFailed],
+	}
+	for _, count := range counts {
+		current.total += count
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 丢弃窗口外的快照，保留最早的一个作为基准
+	s.samples = append(s.samples, current)
+	for len(s.samples) > 1 && current.at.Sub(s.samples[1].at) >= s.failureWindow {
+		s.samples = s.samples[1:]
+	}
+
+	base := s.samples[0]
+	total := current.total - base.total
+	if total <= 0 {
+		return 0
+	}
+	return float64(current.failed-base.failed) / float64(total)
+}
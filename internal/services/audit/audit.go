@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/pkg/logger"
+)
+
+// 审计操作
+const (
+	ActionForceComplete     = "force_complete"
+	ActionStageRtranfile    = "stage_rtranfile"
+	ActionActivateRtranfile = "activate_rtranfile"
+)
+
+// Entry 审计日志条目，记录一次人工干预
+type Entry struct {
+	Time     time.Time      `json:"time"`
+	Actor    string         `json:"actor"`               // 执行操作的调用方
+	RemoteIP string         `json:"remote_ip,omitempty"` // 调用方地址
+	Action   string         `json:"action"`
+	Target   string         `json:"target"` // 操作对象，例如任务 ID
+	Reason   string         `json:"reason,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// Log 审计日志：每条记录写入服务日志，配置了文件时同时追加一行 JSON 并落盘
+type Log struct {
+	mu     sync.Mutex
+	file   *os.File
+	logger *zap.Logger
+}
+
+// Open 打开（不存在时创建）审计日志文件，path 为空时只写入服务日志
+func Open(path string) (*Log, error) {
+	log := &Log{logger: logger.GetLogger().Named(logger.ComponentAPI).Named("audit")}
+	if path == "" {
+		return log, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建审计日志目录失败: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("打开审计日志失败: %v", err)
+	}
+	log.file = file
+	return log, nil
+}
+
+// Record 记录一条审计日志，写入文件失败时返回错误
+func (l *Log) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	// 原因和详情可能包含带凭据的 URL 或命令行参数（例如 rtranfile 的下载地址），写入前脱敏
+	entry.Reason = logger.Redact(entry.Reason)
+	entry.Details = redactDetails(entry.Details)
+
+	l.logger.Warn("审计: 人工干预",
+		zap.String("actor", entry.Actor),
+		zap.String("remote_ip", entry.RemoteIP),
+		zap.String("action", entry.Action),
+		zap.String("target", entry.Target),
+		zap.String("reason", entry.Reason),
+		zap.Any("details", entry.Details),
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %v", err)
+	}
+	data = append(data, '\n')
+	if _, err := l.file.Write(data); err != nil {
+		return fmt.Errorf("写入审计日志失败: %v", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("同步审计日志失败: %v", err)
+	}
+	return nil
+}
+
+// redactDetails 返回字符串值已脱敏的详情副本，嵌套的详情同样脱敏
+func redactDetails(details map[string]any) map[string]any {
+	if details == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(details))
+	for key, value := range details {
+		switch v := value.(type) {
+		case string:
+			redacted[key] = logger.Redact(v)
+		case map[string]any:
+			redacted[key] = redactDetails(v)
+		default:
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+// Close 关闭审计日志文件
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// AllowList 客户端地址白名单
+type AllowList struct {
+	prefixes []netip.Prefix
+}
+
+// ParseAllowList 解析 CIDR 列表，单个 IP 视为 /32（IPv6 为 /128）
+func ParseAllowList(entries []string) (*AllowList, error) {
+	list := &AllowList{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 CIDR: %s", entry)
+			}
+			list.prefixes = append(list.prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 IP 地址: %s", entry)
+		}
+		list.prefixes = append(list.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return list, nil
+}
+
+// Allows 判断地址是否在白名单内
+func (l *AllowList) Allows(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	// IPv4 映射的 IPv6 地址按 IPv4 匹配
+	addr = addr.Unmap()
+
+	for _, prefix := range l.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"rdma-burst/internal/models"
+)
+
+// APIKeyHeader 传递 API Key 的请求头（也可以使用 Authorization: Bearer）
+const APIKeyHeader = "X-API-Key"
+
+// ErrUnauthenticated 请求未携带有效凭据
+var ErrUnauthenticated = errors.New("未认证或凭据无效")
+
+// Principal 已认证的调用方
+type Principal struct {
+	Name       string `json:"name"`
+	Admin      bool   `json:"admin"`
+	ReadOnly   bool   `json:"read_only,omitempty"` // 只读调用方（observer），只能调用查询接口
+	GrantToken string `json:"-"`                   // 使用一次性传输令牌认证时的令牌，只能创建令牌授权的传输
+	GrantTask  string `json:"-"`                   // 令牌已兑换时创建的任务，只能为该任务上报心跳和进度
+
+	MaxConcurrentTasks int `json:"-"` // 调用方同时运行的最大任务数，0 表示只受全局限制
+}
+
+// CanManage 判断调用方是否可以管理 owner 创建的任务，只读调用方不能管理任何任务
+func (p *Principal) CanManage(owner string) bool {
+	if p.ReadOnly {
+		return false
+	}
+	return p.Admin || owner == "" || owner == p.Name
+}
+
+// CanViewAll 判断调用方是否可以查看所有调用方的数据（管理员和只读调用方）
+func (p *Principal) CanViewAll() bool {
+	return p.Admin || p.ReadOnly
+}
+
+type principalKey struct{}
+
+// WithPrincipal 在上下文中保存调用方
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext 获取上下文中的调用方，未启用认证时不存在
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// credential 一组可用的凭据
+type credential struct {
+	secret    string
+	principal *Principal
+}
+
+// Authenticator 根据认证配置校验请求凭据
+type Authenticator struct {
+	keys     []credential // Bearer 令牌和 API Key
+	username string
+	password string
+	grants   *GrantStore // 一次性传输令牌，为空时不接受
+}
+
+// NewAuthenticator 创建认证器
+// 全局 token 和用户名密码视为管理员，observer_token 视为只读调用方，api_keys 中的每个 key 对应一个调用方
+func NewAuthenticator(settings models.AuthSettings) *Authenticator {
+	a := &Authenticator{
+		username: settings.Username,
+		password: settings.Password,
+	}
+	if settings.Token != "" {
+		a.keys = append(a.keys, credential{
+			secret:    settings.Token,
+			principal: &Principal{Name: "token", Admin: true},
+		})
+	}
+	if settings.ObserverToken != "" {
+		a.keys = append(a.keys, credential{
+			secret:    settings.ObserverToken,
+			principal: &Principal{Name: models.RoleObserver, ReadOnly: true},
+		})
+	}
+	for _, key := range settings.APIKeys {
+		if key.Key == "" {
+			continue
+		}
+		readOnly := key.Role == models.RoleObserver
+		a.keys = append(a.keys, credential{
+			secret:    key.Key,
+			principal: &Principal{Name: key.Name, Admin: key.Admin && !readOnly, ReadOnly: readOnly, MaxConcurrentTasks: key.MaxConcurrentTasks},
+		})
+	}
+	return a
+}
+
+// SetGrantStore 设置一次性传输令牌存储
+func (a *Authenticator) SetGrantStore(grants *GrantStore) {
+	a.grants = grants
+}
+
+// Authenticate 校验请求凭据并返回调用方
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if token := r.Header.Get(GrantTokenHeader); token != "" {
+		return a.lookupGrant(token)
+	}
+
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.lookupKey(key)
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		if a.username != "" && secureEqual(username, a.username) && secureEqual(password, a.password) {
+			return &Principal{Name: username, Admin: true}, nil
+		}
+		return nil, ErrUnauthenticated
+	}
+
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return a.lookupKey(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+// lookupKey 查找令牌对应的调用方
+func (a *Authenticator) lookupKey(secret string) (*Principal, error) {
+	for _, cred := range a.keys {
+		if secureEqual(secret, cred.secret) {
+			principal := *cred.principal
+			return &principal, nil
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+// lookupGrant 查找一次性令牌对应的调用方，令牌在创建任务时才会被消耗，兑换后记录创建的任务
+func (a *Authenticator) lookupGrant(token string) (*Principal, error) {
+	if a.grants == nil {
+		return nil, ErrUnauthenticated
+	}
+	grant, err := a.grants.Lookup(token)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	// 任务所有者记为签发者，签发者可以管理令牌创建的任务
+	return &Principal{Name: grant.IssuedBy, GrantToken: token, GrantTask: grant.TaskID}, nil
+}
+
+// secureEqual 常量时间比较，避免时序攻击
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
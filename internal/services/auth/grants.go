@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GrantTokenHeader 传递一次性传输令牌的请求头
+const GrantTokenHeader = "X-Transfer-Token"
+
+// 一次性令牌有效期限制
+const (
+	DefaultGrantTTL = 10 * time.Minute
+	MaxGrantTTL     = 24 * time.Hour
+	// RedeemedGrantTTL 兑换后令牌保留的时间，期间持有令牌的客户端为创建的任务上报心跳和进度
+	RedeemedGrantTTL = 24 * time.Hour
+)
+
+// 一次性令牌错误
+var (
+	ErrGrantNotFound = errors.New("传输令牌不存在、已使用或已过期")
+	ErrGrantMismatch = errors.New("传输请求与令牌授权的内容不一致")
+	ErrGrantInUse    = errors.New("传输令牌正在被另一个请求使用")
+	ErrGrantOverride = errors.New("一次性传输令牌不能指定授权内容以外的传输设置")
+)
+
+// GrantScope 令牌授权的传输内容，路径和命名空间为空时请求也不能指定
+type GrantScope struct {
+	Filename        string `json:"filename"`
+	Mode            string `json:"mode"`
+	Direction       string `json:"direction"`
+	SourcePath      string `json:"source_path,omitempty"`
+	DestinationPath string `json:"destination_path,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+}
+
+// Grant 一次性传输令牌，只能用于创建与授权内容完全一致的一个传输任务
+type Grant struct {
+	Token string `json:"token"`
+	GrantScope
+	IssuedBy  string    `json:"issued_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	TaskID    string    `json:"task_id,omitempty"` // 兑换时创建的任务，兑换后令牌只能用于该任务的心跳和进度上报
+
+	claimed bool // 已被正在创建任务的请求占用
+}
+
+// Matches 判断传输请求是否与令牌授权的内容一致
+func (g *Grant) Matches(scope GrantScope) bool {
+	return g.GrantScope == scope
+}
+
+// GrantStore 一次性令牌存储（仅保存在内存中，重启后失效）
+type GrantStore struct {
+	mu     sync.Mutex
+	grants map[string]*Grant
+}
+
+// NewGrantStore 创建令牌存储
+func NewGrantStore() *GrantStore {
+	return &GrantStore{
+		grants: make(map[string]*Grant),
+	}
+}
+
+// Mint 签发绑定文件名、模式、方向、路径和命名空间的一次性令牌，ttl 为 0 时使用 DefaultGrantTTL
+func (s *GrantStore) Mint(scope GrantScope, issuedBy string, ttl time.Duration) (*Grant, error) {
+	if ttl <= 0 {
+		ttl = DefaultGrantTTL
+	}
+	if ttl > MaxGrantTTL {
+		return nil, fmt.Errorf("令牌有效期不能超过 %s", MaxGrantTTL)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("生成传输令牌失败: %v", err)
+	}
+
+	now := time.Now()
+	grant := &Grant{
+		Token:      hex.EncodeToString(raw),
+		GrantScope: scope,
+		IssuedBy:   issuedBy,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(now)
+	s.grants[grant.Token] = grant
+
+	copied := *grant
+	return &copied, nil
+}
+
+// Lookup 查找未过期的令牌，不会消耗令牌
+func (s *GrantStore) Lookup(token string) (*Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+	grant, ok := s.grants[token]
+	if !ok {
+		return nil, ErrGrantNotFound
+	}
+
+	copied := *grant
+	return &copied, nil
+}
+
+// Claim 校验请求与令牌一致后占用令牌，不会消耗令牌；任务创建成功后调用 Redeem，失败时调用 Release 释放
+// 令牌被占用期间其他请求无法使用
+func (s *GrantStore) Claim(token string, scope GrantScope) (*Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+	grant, ok := s.grants[token]
+	if !ok {
+		return nil, ErrGrantNotFound
+	}
+	if grant.TaskID != "" {
+		return nil, ErrGrantNotFound
+	}
+	if !grant.Matches(scope) {
+		return nil, ErrGrantMismatch
+	}
+	if grant.claimed {
+		return nil, ErrGrantInUse
+	}
+
+	grant.claimed = true
+	copied := *grant
+	return &copied, nil
+}
+
+// Release 释放占用的令牌，令牌可以再次使用
+func (s *GrantStore) Release(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if grant, ok := s.grants[token]; ok {
+		grant.claimed = false
+	}
+}
+
+// Redeem 消耗已占用的令牌，每个令牌只能成功兑换一次
+// 兑换后令牌记录创建的任务并保留 RedeemedGrantTTL，客户端执行传输期间凭令牌为该任务上报心跳和进度
+func (s *GrantStore) Redeem(token, taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if grant, ok := s.grants[token]; ok {
+		grant.claimed = false
+		grant.TaskID = taskID
+		grant.ExpiresAt = time.Now().Add(RedeemedGrantTTL)
+	}
+}
+
+// pruneLocked 清理过期令牌，调用方需持有锁
+func (s *GrantStore) pruneLocked(now time.Time) {
+	for token, grant := range s.grants {
+		if now.After(grant.ExpiresAt) {
+			delete(s.grants, token)
+		}
+	}
+}
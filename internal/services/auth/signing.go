@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 请求签名使用的请求头
+const (
+	TimestampHeader = "X-RDMA-Timestamp" // Unix 秒级时间戳
+	NonceHeader     = "X-RDMA-Nonce"     // 每个请求唯一的随机数，用于防重放
+	SignatureHeader = "X-RDMA-Signature" // 十六进制 HMAC-SHA256 签名
+)
+
+// DefaultMaxSkew 默认允许的时钟偏差
+const DefaultMaxSkew = 5 * time.Minute
+
+// 签名校验错误
+var (
+	ErrSignatureMissing = errors.New("请求缺少签名")
+	ErrSignatureInvalid = errors.New("请求签名无效")
+	ErrSignatureExpired = errors.New("请求时间戳超出允许范围")
+	ErrReplayedRequest  = errors.New("请求已被使用（重放）")
+)
+
+// SignRequest 为请求生成时间戳、随机数和签名
+// body 必须与实际发送的请求体一致
+func SignRequest(req *http.Request, body []byte, secret string) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("生成签名随机数失败: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceHex := hex.EncodeToString(nonce)
+
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(NonceHeader, nonceHex)
+	req.Header.Set(SignatureHeader, computeSignature(secret, req.Method, req.URL.RequestURI(), timestamp, nonceHex, body))
+	return nil
+}
+
+// computeSignature 计算签名
+// 签名内容: METHOD\nREQUEST_URI\nTIMESTAMP\nNONCE\nSHA256(BODY)
+func computeSignature(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	digest := sha256.Sum256(body)
+	return digestSignature(secret, method, requestURI, timestamp, nonce, digest[:])
+}
+
+// digestSignature 按请求体的 SHA-256 摘要计算签名
+func digestSignature(secret, method, requestURI, timestamp, nonce string, digest []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(requestURI))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(hex.EncodeToString(digest)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier 校验请求签名并拒绝重放的请求
+type Verifier struct {
+	secret  []byte
+	maxSkew time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // 时间窗口内已使用的随机数及其过期时间
+}
+
+// NewVerifier 创建签名校验器，maxSkew 为 0 时使用 DefaultMaxSkew
+func NewVerifier(secret string, maxSkew time.Duration) *Verifier {
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	return &Verifier{
+		secret:  []byte(secret),
+		maxSkew: maxSkew,
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+// Verify 校验请求签名，body 为读取出的请求体
+func (v *Verifier) Verify(r *http.Request, body []byte) error {
+	digest := sha256.Sum256(body)
+	return v.VerifyDigest(r, digest[:])
+}
+
+// Precheck 在读取请求体之前检查签名请求头是否齐全、时间戳是否在允许的时钟偏差内，不校验签名本身
+func (v *Verifier) Precheck(r *http.Request) error {
+	_, err := v.signedAt(r)
+	return err
+}
+
+// signedAt 检查签名请求头并返回请求的签名时间
+func (v *Verifier) signedAt(r *http.Request) (time.Time, error) {
+	timestamp := r.Header.Get(TimestampHeader)
+	if timestamp == "" || r.Header.Get(NonceHeader) == "" || r.Header.Get(SignatureHeader) == "" {
+		return time.Time{}, ErrSignatureMissing
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrSignatureInvalid
+	}
+	now := time.Now()
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return time.Time{}, ErrSignatureExpired
+	}
+	return signedAt, nil
+}
+
+// VerifyDigest 按请求体的 SHA-256 摘要校验请求签名，用于边读取边计算摘要的大请求体
+func (v *Verifier) VerifyDigest(r *http.Request, digest []byte) error {
+	signedAt, err := v.signedAt(r)
+	if err != nil {
+		return err
+	}
+	timestamp := r.Header.Get(TimestampHeader)
+	nonce := r.Header.Get(NonceHeader)
+	signature := r.Header.Get(SignatureHeader)
+	now := time.Now()
+
+	expected := digestSignature(string(v.secret), r.Method, r.URL.RequestURI(), timestamp, nonce, digest)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureInvalid
+	}
+
+	// 签名有效后再记录随机数，避免伪造请求占满缓存
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for key, expiresAt := range v.nonces {
+		if now.After(expiresAt) {
+			delete(v.nonces, key)
+		}
+	}
+	if _, used := v.nonces[nonce]; used {
+		return ErrReplayedRequest
+	}
+	// 时间戳超出窗口后请求会被直接拒绝，随机数只需保留到窗口结束
+	v.nonces[nonce] = signedAt.Add(v.maxSkew)
+	return nil
+}
@@ -0,0 +1,470 @@
+package binaries
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// rtranfile 版本管理：新版本校验后保存到 dir/versions/<version>/rtranfile，
+// dir/current 是指向当前版本的符号链接，传输服务通过它启动 rtranfile，切换版本时原子地替换该链接
+// 已启动的 rtranfile 进程继续使用旧版本，之后启动的进程使用新版本
+
+// DefaultMaxSize 单个版本的默认最大字节数
+const DefaultMaxSize = 256 << 20
+
+// DefaultDownloadTimeout 从 URL 下载新版本的默认超时
+const DefaultDownloadTimeout = 5 * time.Minute
+
+// ExternalVersion 启用版本管理前使用的 rtranfile（RTRANFILE_PATH 等位置），可以重新切换回该版本
+const ExternalVersion = "external"
+
+const (
+	binaryName   = "rtranfile"
+	metadataName = "version.json"
+	currentLink  = "current"
+	versionsDir  = "versions"
+)
+
+// versionPattern 版本号格式，同时用作目录名
+var versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+var (
+	// ErrInvalidVersion 版本号格式无效
+	ErrInvalidVersion = errors.New("版本号只能包含字母、数字、点、下划线和连字符，以字母或数字开头，最长 64 个字符")
+	// ErrVersionExists 版本已存在
+	ErrVersionExists = errors.New("版本已存在")
+	// ErrVersionNotFound 版本不存在
+	ErrVersionNotFound = errors.New("版本不存在")
+	// ErrInvalidChecksum SHA-256 校验和格式无效
+	ErrInvalidChecksum = errors.New("sha256 必须是 64 位十六进制字符串")
+	// ErrChecksumMismatch 文件的 SHA-256 与请求中的不一致
+	ErrChecksumMismatch = errors.New("文件的 SHA-256 与请求中的校验和不一致")
+	// ErrSignatureRequired 配置要求签名但请求未提供
+	ErrSignatureRequired = errors.New("需要提供签名")
+	// ErrSignatureInvalid 签名无法通过任一配置的公钥校验
+	ErrSignatureInvalid = errors.New("签名无效")
+	// ErrTooLarge 文件超过最大字节数
+	ErrTooLarge = errors.New("文件超过最大字节数")
+	// ErrFetch 读取指定的路径或 URL 失败
+	ErrFetch = errors.New("读取新版本失败")
+)
+
+// Version 已保存的 rtranfile 版本
+type Version struct {
+	Version  string    `json:"version"`
+	SHA256   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	Signed   bool      `json:"signed"`           // 是否通过了签名校验
+	Source   string    `json:"source,omitempty"` // 上传时为 upload，否则为指定的路径或 URL
+	StagedBy string    `json:"staged_by,omitempty"`
+	StagedAt time.Time `json:"staged_at"`
+	Active   bool      `json:"active"`
+}
+
+// Active 当前使用的版本
+type Active struct {
+	Version     string    `json:"version"`
+	Path        string    `json:"path"` // 符号链接指向的二进制文件
+	SHA256      string    `json:"sha256,omitempty"`
+	ActivatedAt time.Time `json:"activated_at"`
+}
+
+// StageRequest 保存新版本的请求
+type StageRequest struct {
+	Version   string
+	SHA256    string // 十六进制
+	Signature string // base64 编码的 Ed25519 签名（对 SHA-256 摘要签名），可选
+	Source    string
+	StagedBy  string
+}
+
+// Manager rtranfile 版本管理器
+type Manager struct {
+	mu               sync.Mutex
+	dir              string
+	fallback         string // 启用版本管理前使用的 rtranfile
+	keys             []ed25519.PublicKey
+	requireSignature bool
+	maxSize          int64
+	client           *http.Client
+	active           Active
+	logger           *zap.Logger
+}
+
+// Open 创建版本管理器，dir/current 不存在时指向 fallback（启用版本管理前使用的 rtranfile）
+func Open(settings models.RtranfileSettings, fallback string) (*Manager, error) {
+	keys, err := ParsePublicKeys(settings.PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	if fallback != "" {
+		if abs, err := filepath.Abs(fallback); err == nil {
+			fallback = abs
+		}
+	}
+
+	m := &Manager{
+		dir:              settings.Dir,
+		fallback:         fallback,
+		keys:             keys,
+		requireSignature: settings.RequireSignature,
+		maxSize:          settings.MaxSize,
+		client:           &http.Client{Timeout: settings.DownloadTimeout},
+		logger:           logger.GetLogger().Named(logger.ComponentWrapper).Named("binaries"),
+	}
+	if m.maxSize <= 0 {
+		m.maxSize = DefaultMaxSize
+	}
+	if m.client.Timeout <= 0 {
+		m.client.Timeout = DefaultDownloadTimeout
+	}
+
+	if err := os.MkdirAll(filepath.Join(m.dir, versionsDir), 0755); err != nil {
+		return nil, fmt.Errorf("创建 rtranfile 版本目录失败: %v", err)
+	}
+	if _, err := os.Lstat(m.Path()); os.IsNotExist(err) {
+		if err := m.link(fallback); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.loadActive(); err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("rtranfile 版本管理已启用",
+		zap.String("dir", m.dir),
+		zap.String("version", m.active.Version),
+		zap.String("path", m.active.Path),
+	)
+	return m, nil
+}
+
+// ParsePublicKeys 解析 base64 编码的 Ed25519 公钥
+func ParsePublicKeys(encoded []string) ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0, len(encoded))
+	for i, value := range encoded {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个公钥不是有效的 base64: %v", i+1, err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("第 %d 个公钥长度应为 %d 字节，实际为 %d 字节", i+1, ed25519.PublicKeySize, len(key))
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	return keys, nil
+}
+
+// Path 传输服务启动 rtranfile 使用的路径（指向当前版本的符号链接）
+func (m *Manager) Path() string {
+	return filepath.Join(m.dir, currentLink)
+}
+
+// MaxSize 单个版本的最大字节数，未启用版本管理（m 为 nil）时返回 0
+func (m *Manager) MaxSize() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.maxSize
+}
+
+// Active 获取当前使用的版本
+func (m *Manager) Active() Active {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active
+}
+
+// Versions 获取已保存的版本，按保存时间从新到旧排序
+func (m *Manager) Versions() ([]Version, error) {
+	entries, err := os.ReadDir(filepath.Join(m.dir, versionsDir))
+	if err != nil {
+		return nil, fmt.Errorf("读取 rtranfile 版本目录失败: %v", err)
+	}
+
+	active := m.Active()
+	versions := make([]Version, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || !versionPattern.MatchString(entry.Name()) {
+			continue
+		}
+		version, err := m.readVersion(entry.Name())
+		if err != nil {
+			m.logger.Warn("读取 rtranfile 版本信息失败", zap.String("version", entry.Name()), zap.Error(err))
+			continue
+		}
+		version.Active = version.Version == active.Version
+		versions = append(versions, *version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].StagedAt.After(versions[j].StagedAt) })
+	return versions, nil
+}
+
+// Fetch 打开指定的新版本来源：http(s) URL 或本机绝对路径
+func (m *Manager) Fetch(ctx context.Context, source string) (io.ReadCloser, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+		}
+		resp, err := m.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %s 返回 %s", ErrFetch, source, resp.Status)
+		}
+		return resp.Body, nil
+	}
+
+	if !filepath.IsAbs(source) {
+		return nil, fmt.Errorf("%w: source 必须是 http(s) URL 或本机绝对路径", ErrFetch)
+	}
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	return file, nil
+}
+
+// Stage 读取新版本并校验大小、SHA-256 和签名，通过后保存到 dir/versions/<version>
+// 先写入临时目录，校验通过后重命名，失败时不会留下不完整的版本
+func (m *Manager) Stage(req StageRequest, r io.Reader) (*Version, error) {
+	if !versionPattern.MatchString(req.Version) || req.Version == ExternalVersion {
+		return nil, ErrInvalidVersion
+	}
+	expected, err := hex.DecodeString(strings.ToLower(req.SHA256))
+	if err != nil || len(expected) != sha256.Size {
+		return nil, ErrInvalidChecksum
+	}
+	var signature []byte
+	if req.Signature != "" {
+		signature, err = base64.StdEncoding.DecodeString(req.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 签名不是有效的 base64", ErrSignatureInvalid)
+		}
+	} else if m.requireSignature {
+		return nil, ErrSignatureRequired
+	}
+
+	target := filepath.Join(m.dir, versionsDir, req.Version)
+	if _, err := os.Stat(target); err == nil {
+		return nil, ErrVersionExists
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Join(m.dir, versionsDir), "."+req.Version+".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// 写入文件并计算摘要，多读一个字节判断是否超过最大字节数
+	file, err := os.OpenFile(filepath.Join(tmpDir, binaryName), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("创建文件失败: %v", err)
+	}
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hasher), io.LimitReader(r, m.maxSize+1))
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("写入文件失败: %v", err)
+	}
+	if size > m.maxSize {
+		return nil, fmt.Errorf("%w（%d 字节）", ErrTooLarge, m.maxSize)
+	}
+
+	digest := hasher.Sum(nil)
+	if !bytes.Equal(digest, expected) {
+		return nil, fmt.Errorf("%w: 实际为 %s", ErrChecksumMismatch, hex.EncodeToString(digest))
+	}
+	if signature != nil && !m.verify(digest, signature) {
+		return nil, ErrSignatureInvalid
+	}
+
+	version := &Version{
+		Version:  req.Version,
+		SHA256:   hex.EncodeToString(digest),
+		Size:     size,
+		Signed:   signature != nil,
+		Source:   req.Source,
+		StagedBy: req.StagedBy,
+		StagedAt: time.Now(),
+	}
+	data, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化版本信息失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, metadataName), data, 0644); err != nil {
+		return nil, fmt.Errorf("写入版本信息失败: %v", err)
+	}
+	if err := os.Chmod(tmpDir, 0755); err != nil {
+		return nil, fmt.Errorf("设置版本目录权限失败: %v", err)
+	}
+
+	// 并发保存同一版本时只有一个重命名成功
+	if err := os.Rename(tmpDir, target); err != nil {
+		if _, statErr := os.Stat(target); statErr == nil {
+			return nil, ErrVersionExists
+		}
+		return nil, fmt.Errorf("保存版本失败: %v", err)
+	}
+
+	m.logger.Info("已保存 rtranfile 新版本",
+		zap.String("version", version.Version),
+		zap.String("sha256", version.SHA256),
+		zap.Int64("size", version.Size),
+		zap.Bool("signed", version.Signed),
+		zap.String("source", version.Source),
+	)
+	return version, nil
+}
+
+// Activate 切换到指定版本，返回切换前的版本；version 为 external 时切换回启用版本管理前使用的 rtranfile
+func (m *Manager) Activate(version string) (Active, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target := m.fallback
+	if version != ExternalVersion {
+		if !versionPattern.MatchString(version) {
+			return Active{}, "", ErrInvalidVersion
+		}
+		target = filepath.Join(versionsDir, version, binaryName)
+		if _, err := os.Stat(filepath.Join(m.dir, target)); err != nil {
+			return Active{}, "", ErrVersionNotFound
+		}
+	} else if target == "" {
+		return Active{}, "", ErrVersionNotFound
+	}
+
+	previous := m.active.Version
+	if err := m.link(target); err != nil {
+		return Active{}, "", err
+	}
+	if err := m.loadActiveLocked(); err != nil {
+		return Active{}, "", err
+	}
+
+	m.logger.Info("已切换 rtranfile 版本",
+		zap.String("previous", previous),
+		zap.String("version", m.active.Version),
+		zap.String("path", m.active.Path),
+	)
+	return m.active, previous, nil
+}
+
+// link 原子地将 dir/current 指向 target：先创建临时链接再重命名覆盖
+func (m *Manager) link(target string) error {
+	tmp := filepath.Join(m.dir, "."+currentLink+".tmp")
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("创建 rtranfile 链接失败: %v", err)
+	}
+	if err := os.Rename(tmp, m.Path()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("切换 rtranfile 链接失败: %v", err)
+	}
+	return nil
+}
+
+// loadActive 根据 dir/current 的指向确定当前版本
+func (m *Manager) loadActive() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadActiveLocked()
+}
+
+func (m *Manager) loadActiveLocked() error {
+	info, err := os.Lstat(m.Path())
+	if err != nil {
+		return fmt.Errorf("读取 rtranfile 链接失败: %v", err)
+	}
+	target, err := os.Readlink(m.Path())
+	if err != nil {
+		return fmt.Errorf("读取 rtranfile 链接失败: %v", err)
+	}
+
+	active := Active{Version: ExternalVersion, Path: target, ActivatedAt: info.ModTime()}
+	if !filepath.IsAbs(target) {
+		active.Path = filepath.Join(m.dir, target)
+		// versions/<version>/rtranfile
+		if parts := strings.Split(filepath.ToSlash(target), "/"); len(parts) == 3 && parts[0] == versionsDir {
+			active.Version = parts[1]
+			if version, err := m.readVersion(parts[1]); err == nil {
+				active.SHA256 = version.SHA256
+			}
+		}
+	}
+	if active.SHA256 == "" {
+		if digest, err := fileDigest(active.Path); err == nil {
+			active.SHA256 = digest
+		}
+	}
+	m.active = active
+	return nil
+}
+
+// readVersion 读取版本信息
+func (m *Manager) readVersion(name string) (*Version, error) {
+	data, err := os.ReadFile(filepath.Join(m.dir, versionsDir, name, metadataName))
+	if err != nil {
+		return nil, err
+	}
+	var version Version
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// verify 使用任一配置的公钥校验对摘要的签名
+func (m *Manager) verify(digest, signature []byte) bool {
+	for _, key := range m.keys {
+		if ed25519.Verify(key, digest, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDigest 计算文件的 SHA-256
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,111 @@
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// 支持的整文件摘要算法
+const (
+	MD5    = "md5"
+	SHA1   = "sha1"
+	SHA256 = "sha256"
+)
+
+// DefaultAlgorithm 未配置算法时使用的摘要算法
+const DefaultAlgorithm = SHA256
+
+var (
+	// ErrUnsupportedAlgorithm 不支持的摘要算法
+	ErrUnsupportedAlgorithm = errors.New("不支持的摘要算法")
+
+	// ErrInvalidValue 摘要不是对应算法长度的十六进制字符串
+	ErrInvalidValue = errors.New("摘要必须是十六进制字符串")
+)
+
+// New 按算法创建摘要，算法为空时使用 DefaultAlgorithm
+func New(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "", SHA256:
+		return sha256.New(), nil
+	case SHA1:
+		return sha1.New(), nil
+	case MD5:
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+}
+
+// Validate 检查算法是否支持、摘要是否为该算法长度的十六进制字符串
+func Validate(algorithm, value string) error {
+	if algorithm == "" {
+		return fmt.Errorf("%w: 没有指定算法", ErrUnsupportedAlgorithm)
+	}
+	h, err := New(algorithm)
+	if err != nil {
+		return err
+	}
+	if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != h.Size() {
+		return fmt.Errorf("%w: %s 摘要为 %d 位", ErrInvalidValue, algorithm, h.Size()*2)
+	}
+	return nil
+}
+
+// Sum 获取摘要的十六进制字符串
+func Sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Equal 比较两个十六进制摘要，不区分大小写
+func Equal(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// File 读取文件计算摘要，读取期间 ctx 取消时返回错误
+func File(ctx context.Context, path, algorithm string) (string, error) {
+	h, err := New(algorithm)
+	if err != nil {
+		return "", err
+	}
+	if err := Copy(ctx, h, path); err != nil {
+		return "", err
+	}
+	return Sum(h), nil
+}
+
+// Copy 把文件内容写入 w（例如已写入部分数据的摘要），读取期间 ctx 取消时返回错误
+func Copy(ctx context.Context, w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, &contextReader{ctx: ctx, r: file}); err != nil {
+		return fmt.Errorf("读取文件失败: %v", err)
+	}
+	return nil
+}
+
+// contextReader 每次读取前检查 ctx，大文件计算摘要期间可以取消
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -4,12 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/binaries"
+	"rdma-burst/internal/services/checksum"
+	"rdma-burst/internal/services/notify"
+	"rdma-burst/internal/services/schedule"
+	"rdma-burst/internal/services/staging"
 	"rdma-burst/internal/utils"
 )
 
@@ -66,6 +73,8 @@ func (cm *ConfigManager) LoadConfig(configPath string) (interface{}, error) {
 		return cm.loadServerConfig()
 	case "client":
 		return cm.loadClientConfig()
+	case "combined":
+		return cm.loadCombinedConfig()
 	default:
 		return nil, fmt.Errorf("不支持的配置类型: %s", cm.configType)
 	}
@@ -120,19 +129,84 @@ func (cm *ConfigManager) loadClientConfig() (*models.ClientConfig, error) {
 	return &config, nil
 }
 
+// loadCombinedConfig 加载统一配置
+func (cm *ConfigManager) loadCombinedConfig() (*models.CombinedConfig, error) {
+	// 以默认配置为基础，配置文件中未出现的字段保留默认值
+	config := models.GetDefaultCombinedConfig()
+	
+	// 解析配置到结构体
+	if err := cm.viper.Unmarshal(config); err != nil {
+		return nil, fmt.Errorf("解析统一配置失败: %v", err)
+	}
+	
+	// 验证配置
+	if err := cm.validateCombinedConfig(config); err != nil {
+		return nil, err
+	}
+	
+	return config, nil
+}
+
 // bindServerEnvVars 绑定服务端环境变量
 func (cm *ConfigManager) bindServerEnvVars() {
 	// 服务端设置
 	cm.viper.BindEnv("server.host", "RDMA_SERVER_HOST")
 	cm.viper.BindEnv("server.port", "RDMA_SERVER_PORT")
 	cm.viper.BindEnv("server.log_level", "RDMA_SERVER_LOG_LEVEL")
+	cm.viper.BindEnv("server.shutdown_timeout", "RDMA_SERVER_SHUTDOWN_TIMEOUT")
+	cm.viper.BindEnv("server.idle_timeout", "RDMA_SERVER_IDLE_TIMEOUT")
+	cm.viper.BindEnv("server.max_connections", "RDMA_SERVER_MAX_CONNECTIONS")
+	cm.viper.BindEnv("server.http2", "RDMA_SERVER_HTTP2")
+	cm.viper.BindEnv("server.compression.enabled", "RDMA_SERVER_COMPRESSION_ENABLED")
+	cm.viper.BindEnv("server.compression.min_size", "RDMA_SERVER_COMPRESSION_MIN_SIZE")
 	
 	// 传输设置
 	cm.viper.BindEnv("transfer.device", "RDMA_TRANSFER_DEVICE")
 	cm.viper.BindEnv("transfer.base_dir", "RDMA_TRANSFER_BASE_DIR")
 	cm.viper.BindEnv("transfer.transfer_interval", "RDMA_TRANSFER_INTERVAL")
 	cm.viper.BindEnv("transfer.max_concurrent_transfers", "RDMA_MAX_CONCURRENT_TRANSFERS")
+	cm.viper.BindEnv("transfer.max_concurrent_puts", "RDMA_MAX_CONCURRENT_PUTS")
+	cm.viper.BindEnv("transfer.max_concurrent_gets", "RDMA_MAX_CONCURRENT_GETS")
 	cm.viper.BindEnv("transfer.chunk_size", "RDMA_CHUNK_SIZE")
+	cm.viper.BindEnv("transfer.journal_file", "RDMA_TRANSFER_JOURNAL_FILE")
+	cm.viper.BindEnv("transfer.chunk_manifest", "RDMA_TRANSFER_CHUNK_MANIFEST")
+	cm.viper.BindEnv("transfer.heartbeat_timeout", "RDMA_HEARTBEAT_TIMEOUT")
+	cm.viper.BindEnv("transfer.prepared_ttl", "RDMA_PREPARED_TTL")
+	cm.viper.BindEnv("transfer.log_recording_dir", "RDMA_LOG_RECORDING_DIR")
+	cm.viper.BindEnv("transfer.network_fs_policy", "RDMA_NETWORK_FS_POLICY")
+	cm.viper.BindEnv("transfer.stage_io.engine", "RDMA_STAGE_IO_ENGINE")
+	cm.viper.BindEnv("transfer.partial_suffix", "RDMA_PARTIAL_SUFFIX")
+	cm.viper.BindEnv("transfer.scrub.enabled", "RDMA_SCRUB_ENABLED")
+	cm.viper.BindEnv("transfer.scrub.interval", "RDMA_SCRUB_INTERVAL")
+	cm.viper.BindEnv("transfer.shutdown_grace_period", "RDMA_SHUTDOWN_GRACE_PERIOD")
+	cm.viper.BindEnv("transfer.process_stop_timeout", "RDMA_PROCESS_STOP_TIMEOUT")
+	cm.viper.BindEnv("transfer.process_cleanup_timeout", "RDMA_PROCESS_CLEANUP_TIMEOUT")
+	cm.viper.BindEnv("transfer.warm_listeners.enabled", "RDMA_WARM_LISTENERS_ENABLED")
+	cm.viper.BindEnv("transfer.warm_listeners.check_interval", "RDMA_WARM_LISTENERS_CHECK_INTERVAL")
+	cm.viper.BindEnv("transfer.session_reuse.enabled", "RDMA_SESSION_REUSE_ENABLED")
+	cm.viper.BindEnv("transfer.session_reuse.max_transfers", "RDMA_SESSION_REUSE_MAX_TRANSFERS")
+	cm.viper.BindEnv("transfer.session_reuse.idle_timeout", "RDMA_SESSION_REUSE_IDLE_TIMEOUT")
+	cm.viper.BindEnv("transfer.accounting.enabled", "RDMA_ACCOUNTING_ENABLED")
+	cm.viper.BindEnv("transfer.accounting.period", "RDMA_ACCOUNTING_PERIOD")
+	cm.viper.BindEnv("transfer.accounting.file", "RDMA_ACCOUNTING_FILE")
+	cm.viper.BindEnv("transfer.accounting.retention", "RDMA_ACCOUNTING_RETENTION")
+	cm.viper.BindEnv("transfer.rtranfile.enabled", "RDMA_RTRANFILE_MANAGEMENT_ENABLED")
+	cm.viper.BindEnv("transfer.rtranfile.dir", "RDMA_RTRANFILE_DIR")
+	cm.viper.BindEnv("transfer.autoscale.enabled", "RDMA_AUTOSCALE_ENABLED")
+	cm.viper.BindEnv("transfer.autoscale.min_concurrent", "RDMA_AUTOSCALE_MIN_CONCURRENT")
+	cm.viper.BindEnv("transfer.autoscale.max_concurrent", "RDMA_AUTOSCALE_MAX_CONCURRENT")
+	cm.viper.BindEnv("transfer.chunk_tuning.enabled", "RDMA_CHUNK_TUNING_ENABLED")
+	cm.viper.BindEnv("transfer.chunk_tuning.apply", "RDMA_CHUNK_TUNING_APPLY")
+	cm.viper.BindEnv("transfer.chunk_tuning.state_file", "RDMA_CHUNK_TUNING_STATE_FILE")
+	cm.viper.BindEnv("transfer.hugepage_pool.enabled", "RDMA_HUGEPAGE_POOL_ENABLED")
+	cm.viper.BindEnv("transfer.hugepage_pool.size", "RDMA_HUGEPAGE_POOL_SIZE")
+	cm.viper.BindEnv("transfer.pipeline.enabled", "RDMA_PIPELINE_ENABLED")
+	cm.viper.BindEnv("transfer.pipeline.stage_dir", "RDMA_PIPELINE_STAGE_DIR")
+	cm.viper.BindEnv("transfer.faults.enabled", "RDMA_FAULTS_ENABLED")
+	cm.viper.BindEnv("transfer.faults.launch_delay", "RDMA_FAULTS_LAUNCH_DELAY")
+	cm.viper.BindEnv("transfer.faults.kill_after", "RDMA_FAULTS_KILL_AFTER")
+	cm.viper.BindEnv("transfer.faults.corrupt_progress_every", "RDMA_FAULTS_CORRUPT_PROGRESS_EVERY")
+	cm.viper.BindEnv("transfer.faults.api_error_every", "RDMA_FAULTS_API_ERROR_EVERY")
 	
 	// 日志设置
 	cm.viper.BindEnv("logging.file_path", "RDMA_LOG_FILE_PATH")
@@ -142,6 +216,16 @@ func (cm *ConfigManager) bindServerEnvVars() {
 	cm.viper.BindEnv("monitoring.health_check_interval", "RDMA_HEALTH_CHECK_INTERVAL")
 	cm.viper.BindEnv("monitoring.enable_metrics", "RDMA_ENABLE_METRICS")
 	cm.viper.BindEnv("monitoring.metrics_port", "RDMA_METRICS_PORT")
+	
+	// 安全设置
+	cm.viper.BindEnv("security.signing.secret", "RDMA_SIGNING_SECRET")
+	cm.viper.BindEnv("security.audit_log_file", "RDMA_AUDIT_LOG_FILE")
+	
+	// 选主设置
+	cm.viper.BindEnv("leader_election.enabled", "RDMA_LEADER_ELECTION_ENABLED")
+	cm.viper.BindEnv("leader_election.lease_name", "RDMA_LEADER_ELECTION_LEASE_NAME")
+	cm.viper.BindEnv("leader_election.namespace", "RDMA_LEADER_ELECTION_NAMESPACE")
+	cm.viper.BindEnv("leader_election.identity", "RDMA_LEADER_ELECTION_IDENTITY")
 }
 
 // bindClientEnvVars 绑定客户端环境变量
@@ -150,6 +234,7 @@ func (cm *ConfigManager) bindClientEnvVars() {
 	cm.viper.BindEnv("server.host", "RDMA_SERVER_HOST")
 	cm.viper.BindEnv("server.port", "RDMA_SERVER_PORT")
 	cm.viper.BindEnv("server.timeout", "RDMA_SERVER_TIMEOUT")
+	cm.viper.BindEnv("server.wait_timeout", "RDMA_SERVER_WAIT_TIMEOUT")
 	cm.viper.BindEnv("server.retry_attempts", "RDMA_RETRY_ATTEMPTS")
 	cm.viper.BindEnv("server.retry_delay", "RDMA_RETRY_DELAY")
 	
@@ -159,6 +244,8 @@ func (cm *ConfigManager) bindClientEnvVars() {
 	cm.viper.BindEnv("transfer.transfer_interval", "RDMA_TRANSFER_INTERVAL")
 	cm.viper.BindEnv("transfer.chunk_size", "RDMA_CHUNK_SIZE")
 	cm.viper.BindEnv("transfer.default_mode", "RDMA_DEFAULT_MODE")
+	cm.viper.BindEnv("transfer.preallocate", "RDMA_TRANSFER_PREALLOCATE")
+	cm.viper.BindEnv("transfer.chunk_manifest", "RDMA_TRANSFER_CHUNK_MANIFEST")
 	
 	// 日志设置
 	cm.viper.BindEnv("logging.file_path", "RDMA_LOG_FILE_PATH")
@@ -168,6 +255,9 @@ func (cm *ConfigManager) bindClientEnvVars() {
 	cm.viper.BindEnv("client.max_parallel_transfers", "RDMA_MAX_PARALLEL_TRANSFERS")
 	cm.viper.BindEnv("client.enable_checksum", "RDMA_ENABLE_CHECKSUM")
 	cm.viper.BindEnv("client.checksum_algorithm", "RDMA_CHECKSUM_ALGORITHM")
+	
+	// 安全设置
+	cm.viper.BindEnv("security.signing.secret", "RDMA_SIGNING_SECRET")
 }
 
 // validateServerConfig 验证服务端配置
@@ -185,6 +275,24 @@ func (cm *ConfigManager) validateServerConfig(config *models.ServerConfig) error
 		return fmt.Errorf("写入超时必须大于 0")
 	}
 	
+	if config.Server.ShutdownTimeout < 0 {
+		return fmt.Errorf("关闭超时不能为负数: %v", config.Server.ShutdownTimeout)
+	}
+	
+	if config.Server.IdleTimeout < 0 {
+		return fmt.Errorf("空闲连接超时不能为负数: %v", config.Server.IdleTimeout)
+	}
+	if config.Server.MaxConnections < 0 {
+		return fmt.Errorf("最大连接数不能为负数: %d", config.Server.MaxConnections)
+	}
+	
+	if config.Server.Compression.MinSize < 0 {
+		return fmt.Errorf("响应压缩的最小字节数不能为负数: %d", config.Server.Compression.MinSize)
+	}
+	if config.Server.Compression.Level < 0 || config.Server.Compression.Level > 9 {
+		return fmt.Errorf("响应压缩级别无效: %d（应为 1-9，0 表示默认级别）", config.Server.Compression.Level)
+	}
+	
 	// 验证传输设置
 	if config.Transfer.Device == "" {
 		return fmt.Errorf("RDMA 设备不能为空")
@@ -202,6 +310,10 @@ func (cm *ConfigManager) validateServerConfig(config *models.ServerConfig) error
 		return fmt.Errorf("最大并发传输数必须大于 0")
 	}
 	
+	if config.Transfer.MaxConcurrentPuts < 0 || config.Transfer.MaxConcurrentGets < 0 {
+		return fmt.Errorf("put/get 并发上限不能为负数")
+	}
+	
 	if config.Transfer.ChunkSize <= 0 {
 		return fmt.Errorf("块大小必须大于 0")
 	}
@@ -211,16 +323,181 @@ func (cm *ConfigManager) validateServerConfig(config *models.ServerConfig) error
 		return err
 	}
 	
+	// 验证对象存储设置
+	if err := staging.ValidateStores(config.Transfer.ObjectStores); err != nil {
+		return err
+	}
+	
+	// 验证网络文件系统策略
+	switch config.Transfer.NetworkFSPolicy {
+	case "", models.NetworkFSWarn, models.NetworkFSFilesystem, models.NetworkFSRefuse:
+	default:
+		return fmt.Errorf("不支持的网络文件系统策略: %s", config.Transfer.NetworkFSPolicy)
+	}
+	
+	// 验证本地暂存的 I/O 设置
+	switch config.Transfer.StageIO.Engine {
+	case "", models.StageIOAuto, models.StageIOURing, models.StageIOSync:
+	default:
+		return fmt.Errorf("不支持的本地暂存 I/O 方式: %s", config.Transfer.StageIO.Engine)
+	}
+	if config.Transfer.StageIO.QueueDepth < 0 || config.Transfer.StageIO.QueueDepth > 4096 {
+		return fmt.Errorf("本地暂存的 io_uring 队列深度必须在 0 到 4096 之间: %d", config.Transfer.StageIO.QueueDepth)
+	}
+	
+	// 验证本地暂存的源目录
+	for _, dir := range config.Transfer.StageSourceDirs {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("本地暂存的源目录必须是绝对路径: %s", dir)
+		}
+	}
+	
+	// 验证临时文件后缀
+	if strings.Contains(config.Transfer.PartialSuffix, "/") {
+		return fmt.Errorf("临时文件后缀不能包含路径分隔符: %s", config.Transfer.PartialSuffix)
+	}
+	
+	// 验证文件巡检间隔
+	if config.Transfer.Scrub.Interval < 0 {
+		return fmt.Errorf("文件巡检间隔不能为负数: %v", config.Transfer.Scrub.Interval)
+	}
+	
+	// 验证关闭等待时间
+	if config.Transfer.ShutdownGracePeriod < 0 {
+		return fmt.Errorf("关闭等待时间不能为负数: %v", config.Transfer.ShutdownGracePeriod)
+	}
+	
+	// 验证进程停止和清理超时
+	if config.Transfer.ProcessStopTimeout < 0 || config.Transfer.ProcessCleanupTimeout < 0 {
+		return fmt.Errorf("进程停止和清理超时不能为负数")
+	}
+	
+	// 验证常驻监听进程检查间隔
+	if config.Transfer.WarmListeners.CheckInterval < 0 {
+		return fmt.Errorf("常驻监听进程检查间隔不能为负数: %v", config.Transfer.WarmListeners.CheckInterval)
+	}
+	
+	// 验证会话复用设置
+	if config.Transfer.SessionReuse.MaxTransfers < 0 {
+		return fmt.Errorf("会话复用的最大传输数不能为负数: %d", config.Transfer.SessionReuse.MaxTransfers)
+	}
+	if config.Transfer.SessionReuse.IdleTimeout < 0 {
+		return fmt.Errorf("会话复用的空闲超时不能为负数: %v", config.Transfer.SessionReuse.IdleTimeout)
+	}
+	
+	// 验证用量统计设置
+	if config.Transfer.Accounting.Period < 0 || config.Transfer.Accounting.Retention < 0 {
+		return fmt.Errorf("用量统计周期和保留时间不能为负数")
+	}
+	
+	if err := cm.validateRtranfile(&config.Transfer.Rtranfile); err != nil {
+		return err
+	}
+	
+	if err := cm.validateAutoscale(&config.Transfer.Autoscale); err != nil {
+		return err
+	}
+	
+	if err := cm.validateChunkTuning(&config.Transfer.ChunkTuning); err != nil {
+		return err
+	}
+	
+	if err := cm.validatePipeline(&config.Transfer.Pipeline); err != nil {
+		return err
+	}
+	
+	if err := cm.validateDevices(config.Transfer.Devices); err != nil {
+		return err
+	}
+	
+	if err := cm.validateHugepagePool(&config.Transfer.HugepagePool, &config.Transfer.Modes); err != nil {
+		return err
+	}
+	
 	// 验证日志设置
 	if config.Logging.FilePath == "" {
 		return fmt.Errorf("日志文件路径不能为空")
 	}
 	
+	if err := cm.validateLogSink(&config.Logging.Sink); err != nil {
+		return err
+	}
+	
 	// 验证监控设置
 	if config.Monitoring.HealthCheckInterval <= 0 {
 		return fmt.Errorf("健康检查间隔必须大于 0")
 	}
 	
+	// 验证告警设置
+	if err := cm.validateAlerting(&config.Alerting); err != nil {
+		return err
+	}
+	
+	// 验证通知设置
+	if err := cm.validateNotifications(&config.Notifications); err != nil {
+		return err
+	}
+	
+	// 验证选主设置
+	if err := cm.validateLeaderElection(&config.LeaderElection); err != nil {
+		return err
+	}
+	
+	// 验证认证设置
+	if err := cm.validateAuth(&config.Security.Auth); err != nil {
+		return err
+	}
+	
+	// 验证签名设置
+	if err := cm.validateSigning(&config.Security.Signing); err != nil {
+		return err
+	}
+	
+	// 验证地址白名单
+	if err := cm.validateIPAllowList(&config.Security.IPAllowList); err != nil {
+		return err
+	}
+	
+	// 验证 CORS 设置
+	if err := cm.validateCORS(&config.Security.CORS); err != nil {
+		return err
+	}
+	
+	// 验证 QoS 等级
+	if err := cm.validateQoSClasses(config.Transfer.QoSClasses); err != nil {
+		return err
+	}
+	
+	// 验证 auto 模式阈值
+	if err := cm.validateAutoMode(&config.Transfer.AutoMode); err != nil {
+		return err
+	}
+	
+	if config.Transfer.HeartbeatTimeout < 0 {
+		return fmt.Errorf("心跳超时不能为负数")
+	}
+	if config.Transfer.PreparedTTL < 0 {
+		return fmt.Errorf("准备就绪任务的有效期不能为负数")
+	}
+	
+	// 验证故障注入设置
+	if err := cm.validateFaults(&config.Transfer.Faults); err != nil {
+		return err
+	}
+	
+	// 验证传输配置档案
+	if err := cm.validateProfiles(config.Transfer.Profiles, config.Transfer.QoSClasses); err != nil {
+		return err
+	}
+	
+	// 验证传输钩子
+	if err := cm.validateHooks(config.Transfer.PostHooks); err != nil {
+		return err
+	}
+	if err := cm.validateHooks(config.Transfer.AdmissionHooks); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
@@ -239,6 +516,10 @@ func (cm *ConfigManager) validateClientConfig(config *models.ClientConfig) error
 		return fmt.Errorf("连接超时必须大于 0")
 	}
 	
+	if config.Server.WaitTimeout < 0 {
+		return fmt.Errorf("等待类请求的超时不能为负数")
+	}
+	
 	// 验证传输设置
 	if config.Transfer.Device == "" {
 		return fmt.Errorf("RDMA 设备不能为空")
@@ -273,19 +554,555 @@ func (cm *ConfigManager) validateClientConfig(config *models.ClientConfig) error
 		return err
 	}
 	
+	// 验证对象存储设置
+	if err := staging.ValidateStores(config.Transfer.ObjectStores); err != nil {
+		return err
+	}
+	
+	// 验证网络文件系统策略
+	switch config.Transfer.NetworkFSPolicy {
+	case "", models.NetworkFSWarn, models.NetworkFSFilesystem, models.NetworkFSRefuse:
+	default:
+		return fmt.Errorf("不支持的网络文件系统策略: %s", config.Transfer.NetworkFSPolicy)
+	}
+	
+	// 验证本地暂存的源目录
+	for _, dir := range config.Transfer.StageSourceDirs {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("本地暂存的源目录必须是绝对路径: %s", dir)
+		}
+	}
+	
+	// 验证临时文件后缀
+	if strings.Contains(config.Transfer.PartialSuffix, "/") {
+		return fmt.Errorf("临时文件后缀不能包含路径分隔符: %s", config.Transfer.PartialSuffix)
+	}
+	
+	// 验证文件巡检间隔
+	if config.Transfer.Scrub.Interval < 0 {
+		return fmt.Errorf("文件巡检间隔不能为负数: %v", config.Transfer.Scrub.Interval)
+	}
+	
+	// 验证关闭等待时间
+	if config.Transfer.ShutdownGracePeriod < 0 {
+		return fmt.Errorf("关闭等待时间不能为负数: %v", config.Transfer.ShutdownGracePeriod)
+	}
+	
+	// 验证进程停止和清理超时
+	if config.Transfer.ProcessStopTimeout < 0 || config.Transfer.ProcessCleanupTimeout < 0 {
+		return fmt.Errorf("进程停止和清理超时不能为负数")
+	}
+	
+	// 验证常驻监听进程检查间隔
+	if config.Transfer.WarmListeners.CheckInterval < 0 {
+		return fmt.Errorf("常驻监听进程检查间隔不能为负数: %v", config.Transfer.WarmListeners.CheckInterval)
+	}
+	
+	// 验证会话复用设置
+	if config.Transfer.SessionReuse.MaxTransfers < 0 {
+		return fmt.Errorf("会话复用的最大传输数不能为负数: %d", config.Transfer.SessionReuse.MaxTransfers)
+	}
+	if config.Transfer.SessionReuse.IdleTimeout < 0 {
+		return fmt.Errorf("会话复用的空闲超时不能为负数: %v", config.Transfer.SessionReuse.IdleTimeout)
+	}
+	
+	// 验证用量统计设置
+	if config.Transfer.Accounting.Period < 0 || config.Transfer.Accounting.Retention < 0 {
+		return fmt.Errorf("用量统计周期和保留时间不能为负数")
+	}
+	
+	if err := cm.validateRtranfile(&config.Transfer.Rtranfile); err != nil {
+		return err
+	}
+	
+	if err := cm.validateAutoscale(&config.Transfer.Autoscale); err != nil {
+		return err
+	}
+	
+	if err := cm.validateChunkTuning(&config.Transfer.ChunkTuning); err != nil {
+		return err
+	}
+	
+	if err := cm.validatePipeline(&config.Transfer.Pipeline); err != nil {
+		return err
+	}
+	
 	// 验证日志设置
 	if config.Logging.FilePath == "" {
 		return fmt.Errorf("日志文件路径不能为空")
 	}
 	
+	if err := cm.validateLogSink(&config.Logging.Sink); err != nil {
+		return err
+	}
+	
+	// 验证 QoS 等级
+	if err := cm.validateQoSClasses(config.Transfer.QoSClasses); err != nil {
+		return err
+	}
+	
+	// 验证传输钩子
+	if err := cm.validateHooks(config.Transfer.PostHooks); err != nil {
+		return err
+	}
+	
 	// 验证客户端设置
 	if config.Client.MaxParallelTransfers <= 0 {
 		return fmt.Errorf("最大并行传输数必须大于 0")
 	}
+	if config.Client.EnableChecksum {
+		if _, err := checksum.New(config.Client.ChecksumAlgorithm); err != nil {
+			return fmt.Errorf("无效的校验和算法: %v", err)
+		}
+	}
+	
+	// 验证签名设置
+	if err := cm.validateSigning(&config.Security.Signing); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
+// validateCombinedConfig 验证统一配置
+func (cm *ConfigManager) validateCombinedConfig(config *models.CombinedConfig) error {
+	// 验证运行模式
+	switch config.Mode {
+	case "", "server", "client", "auto":
+	default:
+		return fmt.Errorf("不支持的运行模式: %s", config.Mode)
+	}
+	
+	// 验证设备角色
+	for device, role := range config.Detection.DeviceRoles {
+		if role != "server" && role != "client" {
+			return fmt.Errorf("RDMA 设备 %s 的角色无效: %s", device, role)
+		}
+	}
+	
+	if config.Detection.ProbeTimeout <= 0 {
+		return fmt.Errorf("探测超时必须大于 0")
+	}
+	
+	if handoff := config.Detection.Handoff; handoff.Enabled {
+		if handoff.CheckInterval <= 0 {
+			return fmt.Errorf("角色切换检查间隔必须大于 0")
+		}
+		if handoff.FailureThreshold <= 0 {
+			return fmt.Errorf("角色切换的连续检查次数必须大于 0")
+		}
+	}
+	
+	if config.Server.ShutdownTimeout < 0 {
+		return fmt.Errorf("关闭超时不能为负数: %v", config.Server.ShutdownTimeout)
+	}
+	
+	if config.Server.IdleTimeout < 0 {
+		return fmt.Errorf("空闲连接超时不能为负数: %v", config.Server.IdleTimeout)
+	}
+	if config.Server.MaxConnections < 0 {
+		return fmt.Errorf("最大连接数不能为负数: %d", config.Server.MaxConnections)
+	}
+	
+	if config.Server.Compression.MinSize < 0 {
+		return fmt.Errorf("响应压缩的最小字节数不能为负数: %d", config.Server.Compression.MinSize)
+	}
+	if config.Server.Compression.Level < 0 || config.Server.Compression.Level > 9 {
+		return fmt.Errorf("响应压缩级别无效: %d（应为 1-9，0 表示默认级别）", config.Server.Compression.Level)
+	}
+	
+	// 验证告警设置
+	if err := cm.validateAlerting(&config.Alerting); err != nil {
+		return err
+	}
+	
+	// 验证通知设置
+	if err := cm.validateNotifications(&config.Notifications); err != nil {
+		return err
+	}
+	
+	// 验证选主设置
+	if err := cm.validateLeaderElection(&config.LeaderElection); err != nil {
+		return err
+	}
+	
+	// 验证 CORS 设置
+	if err := cm.validateCORS(&config.Security.CORS); err != nil {
+		return err
+	}
 	
 	return nil
 }
 
+// validateAuth 验证认证设置
+func (cm *ConfigManager) validateAuth(auth *models.AuthSettings) error {
+	if !auth.Enabled {
+		return nil
+	}
+	
+	if auth.Token == "" && auth.Username == "" && len(auth.APIKeys) == 0 && auth.ObserverToken == "" {
+		return fmt.Errorf("启用认证时必须配置 token、observer_token、用户名密码或 api_keys")
+	}
+	if auth.ObserverToken != "" && auth.ObserverToken == auth.Token {
+		return fmt.Errorf("observer_token 不能与 token 相同")
+	}
+	
+	names := make(map[string]bool, len(auth.APIKeys))
+	for _, key := range auth.APIKeys {
+		if key.Name == "" || key.Key == "" {
+			return fmt.Errorf("API Key 的 name 和 key 不能为空")
+		}
+		if names[key.Name] {
+			return fmt.Errorf("API Key 名称重复: %s", key.Name)
+		}
+		if key.RequestsPerSecond < 0 || key.Burst < 0 || key.MaxConcurrentTasks < 0 {
+			return fmt.Errorf("API Key %s 的限速和并发限制不能为负数", key.Name)
+		}
+		if key.Role != "" && key.Role != models.RoleObserver {
+			return fmt.Errorf("API Key %s 的角色无效: %s（只支持 %s）", key.Name, key.Role, models.RoleObserver)
+		}
+		if key.Role == models.RoleObserver && key.Admin {
+			return fmt.Errorf("API Key %s 不能同时是管理员和只读调用方", key.Name)
+		}
+		names[key.Name] = true
+	}
+	
+	return nil
+}
+
+// validateSigning 验证请求签名设置
+func (cm *ConfigManager) validateSigning(signing *models.SigningSettings) error {
+	if !signing.Enabled {
+		return nil
+	}
+	
+	if len(signing.Secret) < 16 {
+		return fmt.Errorf("启用请求签名时密钥长度不能少于 16 个字符")
+	}
+	
+	if signing.MaxSkew < 0 {
+		return fmt.Errorf("签名允许的时钟偏差不能为负数")
+	}
+	
+	return nil
+}
+
+// validateIPAllowList 验证地址白名单
+func (cm *ConfigManager) validateIPAllowList(allowList *models.IPAllowListSettings) error {
+	if !allowList.Enabled {
+		return nil
+	}
+	
+	if len(allowList.CIDRs) == 0 {
+		return fmt.Errorf("启用地址白名单时必须配置 cidrs")
+	}
+	
+	if _, err := auth.ParseAllowList(allowList.CIDRs); err != nil {
+		return fmt.Errorf("地址白名单配置无效: %v", err)
+	}
+	
+	return nil
+}
+
+// validateCORS 验证 CORS 设置，"*" 来源不能与凭据同时使用
+func (cm *ConfigManager) validateCORS(cors *models.CORSSettings) error {
+	if !cors.Enabled {
+		return nil
+	}
+	
+	if cors.MaxAge < 0 {
+		return fmt.Errorf("CORS 预检缓存时间不能为负数")
+	}
+	if cors.AllowCredentials && slices.Contains(cors.AllowedOrigins, "*") {
+		return fmt.Errorf("CORS allow_credentials 不能与 \"*\" 来源同时使用，请列出允许的来源")
+	}
+	
+	for _, route := range cors.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("CORS 路由策略的路径必须以 / 开头: %q", route.Path)
+		}
+		if route.MaxAge != nil && *route.MaxAge < 0 {
+			return fmt.Errorf("CORS 路由策略 %s 的预检缓存时间不能为负数", route.Path)
+		}
+		origins, credentials := route.AllowedOrigins, cors.AllowCredentials
+		if len(origins) == 0 {
+			origins = cors.AllowedOrigins
+		}
+		if route.AllowCredentials != nil {
+			credentials = *route.AllowCredentials
+		}
+		if credentials && slices.Contains(origins, "*") {
+			return fmt.Errorf("CORS 路由策略 %s 的 allow_credentials 不能与 \"*\" 来源同时使用", route.Path)
+		}
+	}
+	
+	return nil
+}
+
+// validateProfiles 验证传输配置档案
+func (cm *ConfigManager) validateProfiles(profiles map[string]models.TransferProfile, classes map[string]models.QoSClass) error {
+	for name, profile := range profiles {
+		if _, err := schedule.ParseWindows(profile.Windows); err != nil {
+			return fmt.Errorf("传输配置档案 %s 无效: %v", name, err)
+		}
+		if profile.QoS != "" {
+			if _, ok := classes[strings.ToLower(profile.QoS)]; !ok {
+				return fmt.Errorf("传输配置档案 %s 的 QoS 等级不存在: %s", name, profile.QoS)
+			}
+		}
+		for _, pattern := range profile.Metadata.Xattrs {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("传输配置档案 %s 的扩展属性模式无效: %s", name, pattern)
+			}
+		}
+		switch profile.OnConflict {
+		case "", models.ConflictFail, models.ConflictOverwrite, models.ConflictRename, models.ConflictVersion:
+		default:
+			return fmt.Errorf("传输配置档案 %s 的冲突策略无效: %s", name, profile.OnConflict)
+		}
+		switch profile.Namespace {
+		case "", models.NamespaceNone, models.NamespaceOwner, models.NamespaceTask:
+		default:
+			return fmt.Errorf("传输配置档案 %s 的命名空间无效: %s", name, profile.Namespace)
+		}
+	}
+	return nil
+}
+
+// validateQoSClasses 验证 QoS 等级
+func (cm *ConfigManager) validateQoSClasses(classes map[string]models.QoSClass) error {
+	for name, class := range classes {
+		if class.ChunkSize < 0 {
+			return fmt.Errorf("QoS 等级 %s 的块大小不能为负数", name)
+		}
+		if class.ConcurrencyShare < 0 || class.ConcurrencyShare > 1 {
+			return fmt.Errorf("QoS 等级 %s 的并发份额必须在 0-1 范围内", name)
+		}
+		if class.DSCP < 0 || class.DSCP > 63 {
+			return fmt.Errorf("QoS 等级 %s 的 DSCP 必须在 0-63 范围内", name)
+		}
+	}
+	return nil
+}
+
+// validateAutoMode 验证 auto 模式阈值
+func (cm *ConfigManager) validateAutoMode(autoMode *models.AutoModeSettings) error {
+	if autoMode.TmpfsMinSize < 0 {
+		return fmt.Errorf("auto 模式的 tmpfs 阈值不能为负数")
+	}
+	if autoMode.HugepagesMinSize < autoMode.TmpfsMinSize {
+		return fmt.Errorf("auto 模式的 hugepages 阈值不能小于 tmpfs 阈值")
+	}
+	return nil
+}
+
+// validateFaults 验证故障注入设置
+func (cm *ConfigManager) validateFaults(faults *models.FaultSettings) error {
+	if faults.LaunchDelay < 0 || faults.KillAfter < 0 {
+		return fmt.Errorf("故障注入的等待时间不能为负数")
+	}
+	if faults.CorruptProgressEvery < 0 || faults.APIErrorEvery < 0 {
+		return fmt.Errorf("故障注入的间隔次数不能为负数")
+	}
+	return nil
+}
+
+// validateHooks 验证传输钩子
+func (cm *ConfigManager) validateHooks(hooks []models.HookConfig) error {
+	for i, hook := range hooks {
+		if (len(hook.Command) > 0) == (hook.URL != "") {
+			return fmt.Errorf("钩子 %d 必须配置 command 或 url 其中之一", i)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("钩子 %d 的超时时间不能为负数", i)
+		}
+	}
+	return nil
+}
+
+// validateAlerting 验证告警设置
+func (cm *ConfigManager) validateAlerting(alerting *models.AlertingSettings) error {
+	if !alerting.Enabled {
+		return nil
+	}
+	
+	for i, rule := range alerting.Rules {
+		switch rule.Metric {
+		case "failure_rate", "queue_depth", "stall_count":
+		default:
+			return fmt.Errorf("告警规则 %d 的指标无效: %s", i, rule.Metric)
+		}
+		
+		if rule.Webhook == "" {
+			return fmt.Errorf("告警规则 %d 的 webhook 不能为空", i)
+		}
+		
+		if rule.Duration < 0 {
+			return fmt.Errorf("告警规则 %d 的持续时间不能为负数", i)
+		}
+	}
+	
+	return nil
+}
+
+// validateLeaderElection 验证选主设置，未配置的时间使用默认值，已配置的需满足 lease_duration > renew_deadline > retry_period
+func (cm *ConfigManager) validateLeaderElection(election *models.LeaderElectionSettings) error {
+	if !election.Enabled {
+		return nil
+	}
+	
+	if election.LeaseDuration < 0 || election.RenewDeadline < 0 || election.RetryPeriod < 0 {
+		return fmt.Errorf("选主的 lease_duration、renew_deadline 和 retry_period 不能为负数")
+	}
+	
+	if election.LeaseDuration > 0 && election.RenewDeadline > 0 && election.RenewDeadline >= election.LeaseDuration {
+		return fmt.Errorf("选主的 renew_deadline 必须小于 lease_duration")
+	}
+	
+	if election.RenewDeadline > 0 && election.RetryPeriod > 0 && election.RetryPeriod >= election.RenewDeadline {
+		return fmt.Errorf("选主的 retry_period 必须小于 renew_deadline")
+	}
+	
+	return nil
+}
+
+// validateNotifications 验证任务事件通知设置
+func (cm *ConfigManager) validateNotifications(notifications *models.NotificationSettings) error {
+	if !notifications.Enabled {
+		return nil
+	}
+	
+	for event := range notifications.Events {
+		switch event {
+		case notify.EventTaskFailed, notify.EventTaskStalled:
+		default:
+			return fmt.Errorf("不支持的通知事件: %s", event)
+		}
+	}
+	
+	if notifications.StallTimeout < 0 || notifications.CheckInterval < 0 {
+		return fmt.Errorf("通知的检查间隔和停滞超时不能为负数")
+	}
+	
+	return notify.Validate(notifications)
+}
+
+// validateLogSink 验证额外日志输出设置
+func (cm *ConfigManager) validateLogSink(sink *models.LogSinkSettings) error {
+	switch sink.Type {
+	case "", "journald":
+	case "syslog":
+		if sink.Network != "" && sink.Address == "" {
+			return fmt.Errorf("syslog 指定网络类型时地址不能为空")
+		}
+	default:
+		return fmt.Errorf("不支持的日志输出类型: %s", sink.Type)
+	}
+	
+	return nil
+}
+
+// validateRtranfile 验证 rtranfile 版本管理设置
+func (cm *ConfigManager) validateRtranfile(settings *models.RtranfileSettings) error {
+	if settings.MaxSize < 0 || settings.DownloadTimeout < 0 {
+		return fmt.Errorf("rtranfile 版本的最大字节数和下载超时不能为负数")
+	}
+	if !settings.Enabled {
+		return nil
+	}
+	if settings.Dir == "" {
+		return fmt.Errorf("启用 rtranfile 版本管理时必须配置 dir")
+	}
+	keys, err := binaries.ParsePublicKeys(settings.PublicKeys)
+	if err != nil {
+		return fmt.Errorf("rtranfile 签名公钥无效: %v", err)
+	}
+	if settings.RequireSignature && len(keys) == 0 {
+		return fmt.Errorf("要求 rtranfile 签名时必须配置 public_keys")
+	}
+	return nil
+}
+
+// validateAutoscale 验证并发自动调整设置
+func (cm *ConfigManager) validateAutoscale(settings *models.AutoscaleSettings) error {
+	if settings.MinConcurrent < 0 || settings.MaxConcurrent < 0 || settings.Interval < 0 {
+		return fmt.Errorf("并发自动调整的上下限和调整间隔不能为负数")
+	}
+	if settings.MaxConcurrent > 0 && settings.MinConcurrent > settings.MaxConcurrent {
+		return fmt.Errorf("并发自动调整的 min_concurrent (%d) 不能大于 max_concurrent (%d)", settings.MinConcurrent, settings.MaxConcurrent)
+	}
+	if settings.HighUtilization < 0 || settings.HighUtilization > 1 || settings.LowUtilization < 0 || settings.LowUtilization > 1 {
+		return fmt.Errorf("并发自动调整的链路利用率阈值必须在 0 到 1 之间")
+	}
+	if settings.HighUtilization > 0 && settings.LowUtilization >= settings.HighUtilization {
+		return fmt.Errorf("并发自动调整的 low_utilization (%.2f) 必须小于 high_utilization (%.2f)", settings.LowUtilization, settings.HighUtilization)
+	}
+	return nil
+}
+
+// validateChunkTuning 验证块大小调优设置
+func (cm *ConfigManager) validateChunkTuning(settings *models.ChunkTuningSettings) error {
+	if settings.MinSamples < 0 || settings.MinFileSize < 0 || settings.ExploreEvery < 0 {
+		return fmt.Errorf("块大小调优的 min_samples、min_file_size 和 explore_every 不能为负数")
+	}
+	for _, size := range settings.Candidates {
+		if size <= 0 {
+			return fmt.Errorf("块大小调优的候选块大小必须大于 0: %d", size)
+		}
+	}
+	if settings.Enabled && settings.Apply && len(settings.Candidates) == 0 {
+		return fmt.Errorf("自动选择块大小时必须配置 candidates")
+	}
+	return nil
+}
+
+// validatePipeline 验证流水线传输设置
+func (cm *ConfigManager) validatePipeline(settings *models.PipelineSettings) error {
+	if settings.Lookahead < 0 || settings.IOWorkers < 0 {
+		return fmt.Errorf("流水线传输的 lookahead 和 io_workers 不能为负数")
+	}
+	return nil
+}
+
+// validateDevices 验证 RDMA 设备的调度设置
+func (cm *ConfigManager) validateDevices(devices map[string]models.DeviceSettings) error {
+	for name, device := range devices {
+		if name == "" {
+			return fmt.Errorf("RDMA 设备名称不能为空")
+		}
+		if device.MaxConcurrent < 0 {
+			return fmt.Errorf("RDMA 设备 %s 的 max_concurrent 不能为负数: %d", name, device.MaxConcurrent)
+		}
+	}
+	return nil
+}
+
+// validateHugepagePool 验证大页缓冲池设置
+func (cm *ConfigManager) validateHugepagePool(settings *models.HugepagePoolSettings, modes *models.TransferModes) error {
+	if settings.Size < 0 || settings.SegmentSize < 0 || settings.RefillInterval < 0 {
+		return fmt.Errorf("大页缓冲池的大小、分段大小和补回间隔不能为负数")
+	}
+	if settings.SegmentSize%(2<<20) != 0 {
+		return fmt.Errorf("大页缓冲池的分段大小必须是 2MB 的整数倍: %d", settings.SegmentSize)
+	}
+	if !settings.Enabled {
+		return nil
+	}
+	if !modes.Hugepages.Enabled {
+		return fmt.Errorf("启用大页缓冲池时必须启用 hugepages 模式")
+	}
+	if settings.Dir == "" {
+		return fmt.Errorf("启用大页缓冲池时必须配置 dir")
+	}
+	if settings.Size <= 0 {
+		return fmt.Errorf("启用大页缓冲池时 size 必须大于 0")
+	}
+	if settings.SegmentSize > 0 && settings.Size < settings.SegmentSize {
+		return fmt.Errorf("大页缓冲池的 size (%d) 不能小于 segment_size (%d)", settings.Size, settings.SegmentSize)
+	}
+	return nil
+}
+
 // validateTransferModes 验证传输模式配置
 func (cm *ConfigManager) validateTransferModes(modes *models.TransferModes) error {
 	// 验证大页内存模式
@@ -303,6 +1120,23 @@ func (cm *ConfigManager) validateTransferModes(modes *models.TransferModes) erro
 		return fmt.Errorf("文件系统模式启用时，基础目录不能为空")
 	}
 	
+	// 验证模式并发上限和文件大小上限
+	for name, mode := range map[string]models.ModeConfig{
+		"hugepages":  modes.Hugepages,
+		"tmpfs":      modes.Tmpfs,
+		"filesystem": modes.Filesystem,
+	} {
+		if mode.MaxConcurrent < 0 {
+			return fmt.Errorf("%s 模式的并发上限不能为负数", name)
+		}
+		if mode.MaxFileSize < 0 {
+			return fmt.Errorf("%s 模式的文件大小上限不能为负数", name)
+		}
+		if mode.MaxFileSizeMemoryPct < 0 || mode.MaxFileSizeMemoryPct > 100 {
+			return fmt.Errorf("%s 模式的文件大小内存百分比必须在 0-100 范围内", name)
+		}
+	}
+	
 	return nil
 }
 
@@ -313,6 +1147,8 @@ func (cm *ConfigManager) getDefaultConfig() interface{} {
 		return models.GetDefaultServerConfig()
 	case "client":
 		return models.GetDefaultClientConfig()
+	case "combined":
+		return models.GetDefaultCombinedConfig()
 	default:
 		return nil
 	}
@@ -406,12 +1242,16 @@ func (cm *ConfigManager) fixServerTimeFields(config *models.ServerConfig) {
 		}
 	}
 	
-	// 修复服务端日志路径
-	if config.Logging.FilePath == "" {
-		if logPath, ok := cm.viper.Get("logging.server.file_path").(string); ok && logPath != "" {
-			config.Logging.FilePath = logPath
+	if config.Monitoring.LinkCheckInterval == 0 {
+		if strVal, ok := cm.viper.Get("monitoring.server.link_check_interval").(string); ok {
+			if duration, err := time.ParseDuration(strVal); err == nil {
+				config.Monitoring.LinkCheckInterval = duration
+			}
 		}
 	}
+	
+	// 修复服务端日志设置（统一配置中位于 logging.server 下）
+	cm.fixLoggingFields(&config.Logging, "logging.server")
 }
 
 // fixClientTimeFields 修复客户端时间字段
@@ -425,6 +1265,14 @@ func (cm *ConfigManager) fixClientTimeFields(config *models.ClientConfig) {
 		}
 	}
 	
+	if config.Server.WaitTimeout == 0 {
+		if strVal, ok := cm.viper.Get("client.wait_timeout").(string); ok {
+			if duration, err := time.ParseDuration(strVal); err == nil {
+				config.Server.WaitTimeout = duration
+			}
+		}
+	}
+	
 	if config.Server.RetryDelay == 0 {
 		if strVal, ok := cm.viper.Get("client.retry_delay").(string); ok {
 			if duration, err := time.ParseDuration(strVal); err == nil {
@@ -465,12 +1313,8 @@ func (cm *ConfigManager) fixClientTimeFields(config *models.ClientConfig) {
 		}
 	}
 	
-	// 修复客户端日志路径
-	if config.Logging.FilePath == "" {
-		if logPath, ok := cm.viper.Get("logging.client.file_path").(string); ok && logPath != "" {
-			config.Logging.FilePath = logPath
-		}
-	}
+	// 修复客户端日志设置（统一配置中位于 logging.client 下）
+	cm.fixLoggingFields(&config.Logging, "logging.client")
 	
 	// 修复客户端并行传输数
 	if config.Client.MaxParallelTransfers <= 0 {
@@ -480,6 +1324,37 @@ func (cm *ConfigManager) fixClientTimeFields(config *models.ClientConfig) {
 	}
 }
 
+// fixLoggingFields 从指定前缀下补全缺失的日志设置
+func (cm *ConfigManager) fixLoggingFields(logging *models.LoggingSettings, prefix string) {
+	if logging.FilePath == "" {
+		logging.FilePath = cm.viper.GetString(prefix + ".file_path")
+	}
+	if logging.Level == "" {
+		logging.Level = cm.viper.GetString(prefix + ".level")
+	}
+	if logging.Format == "" {
+		logging.Format = cm.viper.GetString(prefix + ".format")
+	}
+	if logging.MaxSize <= 0 {
+		logging.MaxSize = cm.viper.GetInt(prefix + ".max_size")
+	}
+	if logging.MaxBackups <= 0 {
+		logging.MaxBackups = cm.viper.GetInt(prefix + ".max_backups")
+	}
+	if logging.MaxAge <= 0 {
+		logging.MaxAge = cm.viper.GetInt(prefix + ".max_age")
+	}
+	if len(logging.Components) == 0 {
+		logging.Components = cm.viper.GetStringMapString(prefix + ".components")
+	}
+	if len(logging.RedactKeys) == 0 {
+		logging.RedactKeys = cm.viper.GetStringSlice(prefix + ".redact_keys")
+	}
+	if logging.Sink.Type == "" {
+		_ = cm.viper.UnmarshalKey(prefix+".sink", &logging.Sink)
+	}
+}
+
 // autoDetectServerAddress 自动检测服务端地址
 func (cm *ConfigManager) autoDetectServerAddress(config *models.ClientConfig) {
 	// 如果服务端地址是localhost，尝试根据RDMA设备自动检测
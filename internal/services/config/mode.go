@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// modeLine 配置文件顶层的 mode 配置项，保留行尾注释
+var modeLine = regexp.MustCompile(`^mode:\s*("[^"]*"|'[^']*'|[^\s#]*)`)
+
+// ReadMode 读取配置文件顶层的 mode 配置项，没有配置时返回空
+func ReadMode(configPath string) (string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if match := modeLine.FindStringSubmatch(scanner.Text()); match != nil {
+			return strings.Trim(match[1], `"'`), nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// PersistMode 将运行模式写入配置文件顶层的 mode 配置项，保留文件中的注释和其他配置，没有 mode 配置项时添加到文件开头
+// 先写入同目录的临时文件再重命名，写入中断时不会损坏配置文件；返回的 restore 把配置文件恢复为写入前的内容
+func PersistMode(configPath, mode string) (restore func() error, err error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	value := fmt.Sprintf("mode: %q", mode)
+	lines := strings.SplitAfter(string(data), "\n")
+	replaced := false
+	for i, line := range lines {
+		if modeLine.MatchString(line) {
+			lines[i] = modeLine.ReplaceAllLiteralString(line, value)
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		lines = append([]string{value + "\n"}, lines...)
+	}
+
+	if err := replaceFile(configPath, []byte(strings.Join(lines, "")), info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return replaceFile(configPath, data, info.Mode().Perm())
+	}, nil
+}
+
+// replaceFile 先写入同目录的临时文件再重命名为 path
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("创建临时配置文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("设置配置文件权限失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("替换配置文件失败: %v", err)
+	}
+	return nil
+}
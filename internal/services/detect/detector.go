@@ -0,0 +1,186 @@
+package detect
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/utils"
+)
+
+// 运行模式
+const (
+	ModeServer = "server"
+	ModeClient = "client"
+	ModeAuto   = "auto"
+)
+
+// 判定来源
+const (
+	SourceFlag       = "flag"        // 命令行参数
+	SourceConfig     = "config"      // 配置文件中的 mode
+	SourceLock       = "lock"        // 本机实例锁
+	SourceDeviceRole = "device_role" // RDMA 设备角色
+	SourcePeer       = "peer"        // 显式对端地址
+	SourceLocalProbe = "local_probe" // 本地服务端探测
+	SourceDefault    = "default"     // 默认回退
+	SourceHandoff    = "handoff"     // 运行时角色切换
+)
+
+// Result 模式检测结果
+type Result struct {
+	Mode       string    `json:"mode"`
+	Source     string    `json:"source"`
+	Peer       string    `json:"peer,omitempty"` // 判定为客户端时发现的服务端地址
+	Reasons    []string  `json:"reasons"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Detector 运行模式检测器
+type Detector struct {
+	config *models.CombinedConfig
+	client *http.Client
+}
+
+// NewDetector 创建新的运行模式检测器
+func NewDetector(config *models.CombinedConfig) *Detector {
+	timeout := config.Detection.ProbeTimeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+
+	return &Detector{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// NewFixedResult 创建由外部指定模式的检测结果
+func NewFixedResult(mode, source, reason string) *Result {
+	return &Result{
+		Mode:       mode,
+		Source:     source,
+		Reasons:    []string{reason},
+		DetectedAt: time.Now(),
+	}
+}
+
+// Detect 依次根据配置、实例锁、设备角色、对端地址和本地探测确定运行模式
+func (d *Detector) Detect() *Result {
+	result := &Result{
+		Reasons:    make([]string, 0),
+		DetectedAt: time.Now(),
+	}
+
+	// 1. 配置文件中显式指定的模式
+	switch d.config.Mode {
+	case ModeServer, ModeClient:
+		return d.decide(result, d.config.Mode, SourceConfig, fmt.Sprintf("配置文件指定运行模式: %s", d.config.Mode))
+	}
+	result.Reasons = append(result.Reasons, "配置文件未指定固定模式，继续自动检测")
+
+	// 2. 本机实例锁：已有服务端实例持有锁时只能作为客户端运行
+	if d.config.Mutex.Enabled && d.config.Mutex.LockFile != "" {
+		pid, held := utils.CheckInstanceLock(d.config.Mutex.LockFile)
+		if held && pid != os.Getpid() {
+			result.Peer = net.JoinHostPort("localhost", strconv.Itoa(d.config.Server.Port))
+			return d.decide(result, ModeClient, SourceLock,
+				fmt.Sprintf("实例锁 %s 被进程 %d 持有，本机已有服务端实例", d.config.Mutex.LockFile, pid))
+		}
+		result.Reasons = append(result.Reasons, fmt.Sprintf("实例锁 %s 未被持有", d.config.Mutex.LockFile))
+	}
+
+	// 3. RDMA 设备角色
+	if mode, reason, ok := d.detectByDeviceRole(); ok {
+		return d.decide(result, mode, SourceDeviceRole, reason)
+	} else if reason != "" {
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	// 4. 显式对端地址列表
+	for _, peer := range d.config.Detection.PeerAddresses {
+		addr := d.normalizeAddress(peer, d.config.Client.Port)
+		if d.probe(addr) {
+			result.Peer = addr
+			return d.decide(result, ModeClient, SourcePeer, fmt.Sprintf("对端 %s 的服务端在线", addr))
+		}
+		result.Reasons = append(result.Reasons, fmt.Sprintf("对端 %s 不可达", addr))
+	}
+
+	// 5. 本地服务端探测
+	localAddr := net.JoinHostPort("localhost", strconv.Itoa(d.config.Server.Port))
+	if d.probe(localAddr) {
+		result.Peer = localAddr
+		return d.decide(result, ModeClient, SourceLocalProbe, fmt.Sprintf("检测到本地运行中的服务端 %s", localAddr))
+	}
+	result.Reasons = append(result.Reasons, fmt.Sprintf("本地服务端 %s 未运行", localAddr))
+
+	return d.decide(result, ModeServer, SourceDefault, "未发现可用的服务端，作为服务端启动")
+}
+
+// FindPeer 探测 detection.peer_addresses 中的对端地址，返回第一个在线的服务端地址
+// 用于服务端角色运行时发现其他服务端，不探测本地服务端（即本实例）
+func (d *Detector) FindPeer() (string, bool) {
+	for _, peer := range d.config.Detection.PeerAddresses {
+		addr := d.normalizeAddress(peer, d.config.Client.Port)
+		if d.probe(addr) {
+			return addr, true
+		}
+	}
+	return "", false
+}
+
+// detectByDeviceRole 根据 RDMA 设备角色判定模式
+func (d *Detector) detectByDeviceRole() (string, string, bool) {
+	device := d.config.Transfer.Device
+	if device == "" {
+		return "", "", false
+	}
+
+	// 显式配置的设备角色优先
+	if role, exists := d.config.Detection.DeviceRoles[device]; exists {
+		return role, fmt.Sprintf("RDMA 设备 %s 配置的角色为 %s", device, role), true
+	}
+
+	// 设备地址与配置的服务端地址一致时，本机即为服务端
+	ip, err := utils.GetIPFromRDMAInterface(device)
+	if err != nil {
+		return "", fmt.Sprintf("无法获取 RDMA 设备 %s 的地址: %v", device, err), false
+	}
+	if ip == d.config.Client.Host {
+		return ModeServer, fmt.Sprintf("RDMA 设备 %s 的地址 %s 即为配置的服务端地址", device, ip), true
+	}
+
+	return "", fmt.Sprintf("RDMA 设备 %s 的地址 %s 与配置的服务端地址 %s 不一致", device, ip, d.config.Client.Host), false
+}
+
+// probe 探测指定地址的服务端健康检查端点
+func (d *Detector) probe(addr string) bool {
+	resp, err := d.client.Get(fmt.Sprintf("http://%s/api/health", addr))
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+// normalizeAddress 为缺少端口的地址补全默认端口
+func (d *Detector) normalizeAddress(addr string, defaultPort int) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, strconv.Itoa(defaultPort))
+}
+
+// decide 记录最终判定
+func (d *Detector) decide(result *Result, mode, source, reason string) *Result {
+	result.Mode = mode
+	result.Source = source
+	result.Reasons = append(result.Reasons, reason)
+	return result
+}
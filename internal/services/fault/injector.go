@@ -0,0 +1,96 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// CorruptedLine 替换被损坏的传输日志行，传输日志解析器无法识别
+const CorruptedLine = "\x00\x00 fault injected: corrupted progress line \x00\x00"
+
+// Injector 按配置注入故障，用于确定性地测试重试、停滞和恢复逻辑
+// 未启用时 New 返回 nil，nil 的 Injector 所有方法都不注入故障
+type Injector struct {
+	settings models.FaultSettings
+	lines    atomic.Int64 // 已读取的传输日志行数
+	requests atomic.Int64 // 已处理的 API 请求数
+	logger   *zap.Logger
+}
+
+// New 创建故障注入器，settings 未启用时返回 nil
+func New(settings models.FaultSettings) *Injector {
+	if !settings.Enabled {
+		return nil
+	}
+	return &Injector{
+		settings: settings,
+		logger:   logger.GetLogger().Named(logger.ComponentTransfer).Named("fault"),
+	}
+}
+
+// DelayLaunch 启动 rtranfile 进程前等待 launch_delay，ctx 取消时返回错误
+func (i *Injector) DelayLaunch(ctx context.Context) error {
+	if i == nil || i.settings.LaunchDelay <= 0 {
+		return nil
+	}
+
+	i.logger.Info("注入故障：延迟启动进程", zap.Duration("delay", i.settings.LaunchDelay))
+	timer := time.NewTimer(i.settings.LaunchDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("延迟启动进程被取消: %v", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// KillAfter 进程启动 kill_after 后强制结束，进程先退出或 ctx 取消时不结束
+func (i *Injector) KillAfter(ctx context.Context, process *os.Process) {
+	if i == nil || i.settings.KillAfter <= 0 || process == nil {
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(i.settings.KillAfter)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := process.Kill(); err != nil {
+			i.logger.Debug("注入故障：结束进程失败", zap.Int("pid", process.Pid), zap.Error(err))
+			return
+		}
+		i.logger.Info("注入故障：已强制结束进程", zap.Int("pid", process.Pid), zap.Duration("after", i.settings.KillAfter))
+	}()
+}
+
+// CorruptLine 每 corrupt_progress_every 行传输日志返回 CorruptedLine，其余原样返回
+func (i *Injector) CorruptLine(line string) string {
+	if i == nil || i.settings.CorruptProgressEvery <= 0 {
+		return line
+	}
+	if i.lines.Add(1)%int64(i.settings.CorruptProgressEvery) != 0 {
+		return line
+	}
+	return CorruptedLine
+}
+
+// FailRequest 每 api_error_every 个请求返回 true，表示该请求应返回临时错误
+func (i *Injector) FailRequest() bool {
+	if i == nil || i.settings.APIErrorEvery <= 0 {
+		return false
+	}
+	return i.requests.Add(1)%int64(i.settings.APIErrorEvery) == 0
+}
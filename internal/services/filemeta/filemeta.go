@@ -0,0 +1,85 @@
+package filemeta
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Metadata 需要在两端保持一致的文件元数据
+type Metadata struct {
+	Filename string            `json:"filename"`
+	Mode     os.FileMode       `json:"mode"` // 权限位
+	ModTime  time.Time         `json:"mod_time"`
+	Xattrs   map[string][]byte `json:"xattrs,omitempty"` // 扩展属性，只包含匹配配置模式的属性
+}
+
+// Capture 读取文件的权限、修改时间和匹配 patterns 的扩展属性
+// patterns 为 filepath.Match 风格的属性名模式，例如 "user.*"，为空时不读取扩展属性
+func Capture(path string, patterns []string) (*Metadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+
+	m := &Metadata{
+		Filename: filepath.Base(path),
+		Mode:     info.Mode().Perm(),
+		ModTime:  info.ModTime(),
+	}
+
+	if len(patterns) > 0 {
+		names, err := listXattrs(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取扩展属性失败: %v", err)
+		}
+		for _, name := range names {
+			if !Matches(name, patterns) {
+				continue
+			}
+			value, err := getXattr(path, name)
+			if err != nil {
+				return nil, fmt.Errorf("读取扩展属性 %s 失败: %v", name, err)
+			}
+			if m.Xattrs == nil {
+				m.Xattrs = make(map[string][]byte)
+			}
+			m.Xattrs[name] = value
+		}
+	}
+
+	return m, nil
+}
+
+// Apply 将元数据恢复到文件：先写扩展属性和权限，最后设置修改时间，避免被前面的操作覆盖
+func Apply(path string, m *Metadata) error {
+	for name, value := range m.Xattrs {
+		if err := setXattr(path, name, value); err != nil {
+			return fmt.Errorf("设置扩展属性 %s 失败: %v", name, err)
+		}
+	}
+
+	if m.Mode != 0 {
+		if err := os.Chmod(path, m.Mode.Perm()); err != nil {
+			return fmt.Errorf("设置文件权限失败: %v", err)
+		}
+	}
+
+	if !m.ModTime.IsZero() {
+		if err := os.Chtimes(path, time.Now(), m.ModTime); err != nil {
+			return fmt.Errorf("设置修改时间失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// Matches 判断扩展属性名是否匹配任一模式
+func Matches(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package filemeta
+
+import "errors"
+
+// errXattrUnsupported 当前平台不支持扩展属性
+var errXattrUnsupported = errors.New("当前平台不支持扩展属性")
+
+// listXattrs 非 Linux 平台不读取扩展属性
+func listXattrs(path string) ([]string, error) {
+	return nil, nil
+}
+
+// getXattr 非 Linux 平台不支持扩展属性
+func getXattr(path, name string) ([]byte, error) {
+	return nil, errXattrUnsupported
+}
+
+// setXattr 非 Linux 平台不支持扩展属性
+func setXattr(path, name string, value []byte) error {
+	return errXattrUnsupported
+}
@@ -0,0 +1,226 @@
+package handoff
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/services/detect"
+	"rdma-burst/pkg/logger"
+)
+
+// 统一模式（combined）运行时的角色切换：当前角色收到切换请求后排空传输并关闭服务，
+// 主循环按目标角色重新初始化服务并注册路由，不需要重启进程；请求重启时主循环改为重新执行进程
+
+// 切换阶段
+const (
+	PhaseRunning  = "running"  // 角色正常运行，没有进行中的切换
+	PhaseDraining = "draining" // 当前角色正在排空传输并关闭服务
+	PhaseStarting = "starting" // 正在按目标角色重新初始化
+	PhaseFailed   = "failed"   // 目标角色启动失败，已回退到原角色
+)
+
+// 切换请求来源
+const (
+	SourceAPI   = "api"   // 管理员通过内部 API 请求
+	SourceWatch = "watch" // 自动检测到服务端离线或出现
+)
+
+var (
+	// ErrInvalidMode 目标角色无效
+	ErrInvalidMode = errors.New("目标角色必须是 server 或 client（重启时可以是 auto）")
+	// ErrSameMode 目标角色与当前角色相同
+	ErrSameMode = errors.New("目标角色与当前角色相同")
+	// ErrInProgress 已有进行中的切换
+	ErrInProgress = errors.New("角色切换正在进行")
+)
+
+// Request 角色切换请求
+type Request struct {
+	From        string    `json:"from"`
+	Target      string    `json:"target"`
+	Peer        string    `json:"peer,omitempty"` // 切换为客户端时连接的服务端地址（host:port），为空时使用配置的地址
+	Reason      string    `json:"reason"`
+	Source      string    `json:"source"`
+	Restart     bool      `json:"restart,omitempty"` // 排空后重新执行进程，而不是在进程内切换
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// Status 角色切换状态
+type Status struct {
+	Mode      string    `json:"mode"` // 当前角色
+	Phase     string    `json:"phase"`
+	Last      *Request  `json:"last,omitempty"` // 最近一次切换请求
+	Error     string    `json:"error,omitempty"`
+	Switches  int       `json:"switches"` // 已完成的切换次数
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Coordinator 角色切换协调器，在角色重启之间保持不变
+type Coordinator struct {
+	mu       sync.Mutex
+	status   Status
+	requests chan Request
+	logger   *zap.Logger
+}
+
+// New 创建角色切换协调器，mode 为启动时的角色
+func New(mode string) *Coordinator {
+	return &Coordinator{
+		status: Status{
+			Mode:      mode,
+			Phase:     PhaseStarting,
+			ChangedAt: time.Now(),
+		},
+		requests: make(chan Request, 1),
+		logger:   logger.GetLogger().Named(logger.ComponentMonitor),
+	}
+}
+
+// Request 提交切换请求，当前角色从 Requests 收到后开始排空
+// 已有进行中的切换或目标角色与当前角色相同时返回错误；重启请求的目标可以是 auto 或当前角色
+func (c *Coordinator) Request(req Request) error {
+	switch req.Target {
+	case detect.ModeServer, detect.ModeClient:
+	case detect.ModeAuto:
+		if !req.Restart {
+			return ErrInvalidMode
+		}
+	default:
+		return ErrInvalidMode
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.Phase != PhaseRunning && c.status.Phase != PhaseFailed {
+		return ErrInProgress
+	}
+	if req.Target == c.status.Mode && !req.Restart {
+		return ErrSameMode
+	}
+
+	req.From = c.status.Mode
+	req.RequestedAt = time.Now()
+	c.status.Phase = PhaseDraining
+	c.status.Last = &req
+	c.status.Error = ""
+	c.status.ChangedAt = req.RequestedAt
+	c.requests <- req
+
+	c.logger.Info("请求切换运行角色",
+		zap.String("from", req.From),
+		zap.String("target", req.Target),
+		zap.String("source", req.Source),
+		zap.String("reason", req.Reason),
+		zap.Bool("restart", req.Restart),
+	)
+	return nil
+}
+
+// Requests 当前角色等待切换请求的通道
+func (c *Coordinator) Requests() <-chan Request {
+	return c.requests
+}
+
+// Starting 当前角色已关闭，开始按目标角色初始化，回退到原角色时保持失败状态
+func (c *Coordinator) Starting(mode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.Mode = mode
+	if c.status.Phase != PhaseFailed {
+		c.status.Phase = PhaseStarting
+	}
+	c.status.ChangedAt = time.Now()
+}
+
+// Restore 恢复重启前的切换请求，重启后的进程启动完成时计为一次完成的切换
+func (c *Coordinator) Restore(req Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.Last = &req
+}
+
+// Started 角色初始化完成并开始提供服务
+func (c *Coordinator) Started() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.Phase == PhaseStarting && c.status.Last != nil {
+		c.status.Switches++
+		c.logger.Info("运行角色切换完成",
+			zap.String("mode", c.status.Mode),
+			zap.Duration("elapsed", time.Since(c.status.Last.RequestedAt)),
+		)
+	}
+	if c.status.Phase != PhaseFailed {
+		c.status.Phase = PhaseRunning
+	}
+	c.status.ChangedAt = time.Now()
+}
+
+// Failed 目标角色启动失败，回退到 fallback 角色
+func (c *Coordinator) Failed(fallback string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.Mode = fallback
+	c.status.Phase = PhaseFailed
+	c.status.Error = err.Error()
+	c.status.ChangedAt = time.Now()
+	c.logger.Error("运行角色切换失败，回退到原角色", zap.String("mode", fallback), zap.Error(err))
+}
+
+// Status 获取角色切换状态
+func (c *Coordinator) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := c.status
+	if status.Last != nil {
+		last := *status.Last
+		status.Last = &last
+	}
+	return status
+}
+
+// Watch 在后台每隔 interval 调用 check，连续 threshold 次返回切换请求时提交最后一次的请求，ctx 取消时停止
+func (c *Coordinator) Watch(ctx context.Context, interval time.Duration, threshold int, check func() *Request) {
+	if threshold <= 0 {
+		threshold = 1
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		hits := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			req := check()
+			if req == nil {
+				hits = 0
+				continue
+			}
+			hits++
+			if hits < threshold {
+				c.logger.Warn("检测到运行角色需要切换",
+					zap.String("reason", req.Reason),
+					zap.Int("hits", hits),
+					zap.Int("threshold", threshold),
+				)
+				continue
+			}
+
+			req.Source = SourceWatch
+			if err := c.Request(*req); err != nil {
+				c.logger.Warn("提交角色切换请求失败", zap.Error(err))
+			}
+			hits = 0
+		}
+	}()
+}
@@ -0,0 +1,127 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"rdma-burst/internal/models"
+)
+
+// maxDecisionSize 准入钩子返回结果的大小上限
+const maxDecisionSize = 1 << 20
+
+// Decision 准入钩子的返回结果
+type Decision struct {
+	Allowed *bool                   `json:"allowed,omitempty"` // 是否允许，未返回时视为允许
+	Reason  string                  `json:"reason,omitempty"`  // 拒绝原因
+	Request *models.TransferRequest `json:"request,omitempty"` // 修改后的请求，为空时不修改
+}
+
+// IsAllowed 判断准入钩子是否允许请求
+func (d Decision) IsAllowed() bool {
+	return d.Allowed == nil || *d.Allowed
+}
+
+// Admit 执行准入钩子，请求 JSON 通过命令的标准输入或 HTTP 请求体传入
+// command: 退出码非 0 表示拒绝，输出为拒绝原因；退出码为 0 时标准输出为空表示允许，否则按 Decision 解析
+// url: 返回非 2xx 状态码视为执行失败；响应体为空表示允许，否则按 Decision 解析
+func Admit(ctx context.Context, hook models.HookConfig, req *models.TransferRequest) (Decision, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output []byte
+	switch {
+	case len(hook.Command) > 0:
+		var rejected bool
+		output, rejected, err = admitCommand(ctx, hook.Command, payload)
+		if err == nil && rejected {
+			allowed := false
+			return Decision{Allowed: &allowed, Reason: truncate(string(output))}, nil
+		}
+	case hook.URL != "":
+		output, err = admitURL(ctx, hook.URL, payload)
+	default:
+		return Decision{}, fmt.Errorf("钩子未配置 command 或 url")
+	}
+
+	if ctx.Err() != nil {
+		return Decision{}, fmt.Errorf("执行超时 (%s)", timeout)
+	}
+	if err != nil {
+		return Decision{}, err
+	}
+
+	var decision Decision
+	if len(bytes.TrimSpace(output)) == 0 {
+		return decision, nil
+	}
+	if err := json.Unmarshal(output, &decision); err != nil {
+		return Decision{}, fmt.Errorf("解析准入结果失败: %v", err)
+	}
+	return decision, nil
+}
+
+// admitCommand 执行准入命令，退出码非 0 时返回 rejected
+func admitCommand(ctx context.Context, command []string, payload []byte) ([]byte, bool, error) {
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && ctx.Err() == nil {
+			reason := strings.TrimSpace(stderr.String())
+			if reason == "" {
+				reason = strings.TrimSpace(string(output))
+			}
+			return []byte(reason), true, nil
+		}
+		return nil, false, err
+	}
+	return output, false, nil
+}
+
+// admitURL 以 POST 发送请求 JSON 并读取准入结果
+func admitURL(ctx context.Context, url string, payload []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("钩子返回错误状态: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDecisionSize))
+}
+
+// truncate 截断过长的输出
+func truncate(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxOutput {
+		return output[:maxOutput] + "..."
+	}
+	return output
+}
@@ -0,0 +1,136 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+// DefaultTimeout 钩子未配置超时时间时使用的默认值
+const DefaultTimeout = 30 * time.Second
+
+// maxOutput 命令失败时记录到结果中的输出长度上限
+const maxOutput = 512
+
+// Run 依次执行钩子，任务 JSON 通过命令的标准输入或 HTTP 请求体传入
+// 单个钩子失败不影响后续钩子，返回每个钩子的执行结果
+func Run(ctx context.Context, hooks []models.HookConfig, task *models.TransferTask) []models.HookResult {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(task)
+	results := make([]models.HookResult, 0, len(hooks))
+	for i, hook := range hooks {
+		start := time.Now()
+		runErr := err
+		if runErr == nil {
+			runErr = run(ctx, hook, task, payload)
+		}
+
+		result := models.HookResult{
+			Name:     Name(i, hook),
+			Success:  runErr == nil,
+			Duration: time.Since(start),
+		}
+		if runErr != nil {
+			result.Error = runErr.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Failed 统计执行失败的钩子数
+func Failed(results []models.HookResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Name 获取钩子名称，未配置时使用序号
+func Name(index int, hook models.HookConfig) string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return fmt.Sprintf("hook_%d", index)
+}
+
+// run 在超时时间内执行单个钩子
+func run(ctx context.Context, hook models.HookConfig, task *models.TransferTask, payload []byte) error {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var err error
+	switch {
+	case len(hook.Command) > 0:
+		err = runCommand(ctx, hook.Command, task, payload)
+	case hook.URL != "":
+		err = post(ctx, hook.URL, payload)
+	default:
+		return fmt.Errorf("钩子未配置 command 或 url")
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("执行超时 (%s)", timeout)
+	}
+	return err
+}
+
+// runCommand 执行命令，任务的基本信息同时通过环境变量传入
+func runCommand(ctx context.Context, command []string, task *models.TransferTask, payload []byte) error {
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"RDMA_TASK_ID="+task.ID,
+		"RDMA_TASK_FILENAME="+task.Filename,
+		"RDMA_TASK_MODE="+task.Mode,
+		"RDMA_TASK_DIRECTION="+task.Direction,
+		"RDMA_TASK_STATUS="+task.Status,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if out := truncate(string(output)); out != "" {
+			return fmt.Errorf("%v: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+// post 以 POST 发送任务 JSON
+func post(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("钩子返回错误状态: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package hugepool
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// hugetlbfsMagic hugetlbfs 的 statfs 类型
+const hugetlbfsMagic = 0x958458f6
+
+// checkHugetlbfs 检查目录是否位于 hugetlbfs，其他文件系统上的分段不占用大页
+func checkHugetlbfs(dir string) error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return fmt.Errorf("获取大页缓冲池目录的文件系统失败: %v", err)
+	}
+	if uint32(stat.Type) != hugetlbfsMagic {
+		return fmt.Errorf("大页缓冲池目录 %s 不在 hugetlbfs 上", dir)
+	}
+	return nil
+}
+
+// allocate 创建分段文件并通过 fallocate 分配大页，系统中空闲的大页不足时返回错误
+func allocate(path string, size int64) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("创建分段文件失败: %v", err)
+	}
+	defer file.Close()
+
+	if err := syscall.Fallocate(int(file.Fd()), 0, 0, size); err != nil {
+		return fmt.Errorf("分配大页失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package hugepool
+
+import "fmt"
+
+// checkHugetlbfs 非 Linux 平台没有 hugetlbfs
+func checkHugetlbfs(dir string) error {
+	return fmt.Errorf("大页缓冲池只支持 Linux")
+}
+
+// allocate 非 Linux 平台不支持预分配大页
+func allocate(path string, size int64) error {
+	return fmt.Errorf("大页缓冲池只支持 Linux")
+}
@@ -0,0 +1,267 @@
+package hugepool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// 大页缓冲池的默认值
+const (
+	DefaultSegmentSize    = 1 << 30
+	DefaultRefillInterval = 10 * time.Second
+)
+
+// segmentPrefix 预留分段的文件名前缀，目录中其他文件不受影响
+const segmentPrefix = "segment-"
+
+// Status 大页缓冲池状态
+type Status struct {
+	Dir           string    `json:"dir"`
+	SegmentSize   int64     `json:"segment_size"`
+	Target        int       `json:"target"`         // 配置的分段数
+	Reserved      int       `json:"reserved"`       // 当前预留的分段数
+	Leased        int       `json:"leased"`         // 租给执行中任务的分段数
+	ReservedBytes int64     `json:"reserved_bytes"` // 当前预留的字节数
+	Leases        int       `json:"leases"`         // 执行中持有租用的任务数
+	Misses        int64     `json:"misses"`         // 预留的分段不足、未能完全覆盖文件大小的租用次数
+	LastError     string    `json:"last_error,omitempty"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// Pool 大页缓冲池：启动时在 hugetlbfs 目录中按分段预分配大页并一直持有，hugepages 模式的传输准备时释放所需的分段，
+// 释放出的大页立即被接收文件使用，不会因其他进程占用或内存碎片导致传输中途分配大页失败；任务结束后补回
+type Pool struct {
+	mu          sync.Mutex
+	dir         string
+	segmentSize int64
+	target      int
+	interval    time.Duration
+	segments    []string       // 已预留的分段文件
+	next        int            // 下一个分段文件的序号
+	leases      map[string]int // 任务 ID -> 租用的分段数
+	misses      int64
+	lastError   string
+	checkedAt   time.Time
+	active      func(id string) bool
+	cancel      context.CancelFunc
+	done        chan struct{}
+	logger      *zap.Logger
+}
+
+// NewPool 创建大页缓冲池，复用目录中上次运行留下的完整分段，删除不完整的分段
+func NewPool(settings models.HugepagePoolSettings) (*Pool, error) {
+	p := &Pool{
+		dir:         settings.Dir,
+		segmentSize: settings.SegmentSize,
+		interval:    settings.RefillInterval,
+		leases:      make(map[string]int),
+		logger:      logger.GetLogger().Named(logger.ComponentTransfer).Named("hugepool"),
+	}
+	if p.segmentSize <= 0 {
+		p.segmentSize = DefaultSegmentSize
+	}
+	if p.interval <= 0 {
+		p.interval = DefaultRefillInterval
+	}
+	p.target = int(settings.Size / p.segmentSize)
+	if p.target <= 0 {
+		return nil, fmt.Errorf("大页缓冲池的大小 %d 小于分段大小 %d", settings.Size, p.segmentSize)
+	}
+
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建大页缓冲池目录失败: %v", err)
+	}
+	if err := checkHugetlbfs(p.dir); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取大页缓冲池目录失败: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), segmentPrefix) {
+			continue
+		}
+		path := filepath.Join(p.dir, entry.Name())
+		var index int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(entry.Name(), segmentPrefix), "%d", &index); err == nil {
+			p.next = max(p.next, index+1)
+		}
+		info, err := entry.Info()
+		if err == nil && info.Size() == p.segmentSize && len(p.segments) < p.target {
+			p.segments = append(p.segments, path)
+			continue
+		}
+		os.Remove(path)
+	}
+	sort.Strings(p.segments)
+	return p, nil
+}
+
+// SetLeaseCheck 设置判断任务是否仍在执行的函数，补回分段时回收已结束任务的租用；未设置时租用在下一次补回时回收
+func (p *Pool) SetLeaseCheck(active func(id string) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = active
+}
+
+// Start 立即预留分段并开始周期性回收租用和补回分段
+func (p *Pool) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	p.mu.Lock()
+	p.cancel = cancel
+	p.done = done
+	p.mu.Unlock()
+
+	p.refill()
+	status := p.Status()
+	p.logger.Info("大页缓冲池已启动",
+		zap.String("dir", p.dir),
+		zap.Int64("segment_size", p.segmentSize),
+		zap.Int("target", status.Target),
+		zap.Int("reserved", status.Reserved),
+	)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.refill()
+			}
+		}
+	}()
+}
+
+// Stop 停止周期性补回，已预留的分段保留在目录中，下次启动时复用
+func (p *Pool) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel = nil
+	p.done = nil
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Lease 为任务释放覆盖 size 字节所需的分段，返回释放的字节数；预留的分段不足时释放全部剩余分段并返回 false
+func (p *Pool) Lease(id string, size int64) (int64, bool) {
+	if size <= 0 {
+		return 0, true
+	}
+	needed := int((size + p.segmentSize - 1) / p.segmentSize)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := 0
+	for count < needed && len(p.segments) > 0 {
+		last := p.segments[len(p.segments)-1]
+		if err := os.Remove(last); err != nil && !os.IsNotExist(err) {
+			p.lastError = fmt.Sprintf("释放分段失败: %v", err)
+			break
+		}
+		p.segments = p.segments[:len(p.segments)-1]
+		count++
+	}
+	if count > 0 {
+		p.leases[id] += count
+	}
+	if count < needed {
+		p.misses++
+		return int64(count) * p.segmentSize, false
+	}
+	return int64(count) * p.segmentSize, true
+}
+
+// Status 获取大页缓冲池状态
+func (p *Pool) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	leased := 0
+	for _, count := range p.leases {
+		leased += count
+	}
+	return Status{
+		Dir:           p.dir,
+		SegmentSize:   p.segmentSize,
+		Target:        p.target,
+		Reserved:      len(p.segments),
+		Leased:        leased,
+		ReservedBytes: int64(len(p.segments)) * p.segmentSize,
+		Leases:        len(p.leases),
+		Misses:        p.misses,
+		LastError:     p.lastError,
+		CheckedAt:     p.checkedAt,
+	}
+}
+
+// refill 回收已结束任务的租用，并把预留的分段补回到配置的数量减去仍在租用的分段数
+// 系统中空闲的大页不足时停止补回，下一次再试
+func (p *Pool) refill() {
+	p.mu.Lock()
+	active := p.active
+	ids := make([]string, 0, len(p.leases))
+	for id := range p.leases {
+		ids = append(ids, id)
+	}
+	p.mu.Unlock()
+
+	// 在锁外判断任务状态，避免与传输服务的锁形成环
+	finished := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if active == nil || !active(id) {
+			finished = append(finished, id)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checkedAt = time.Now()
+	for _, id := range finished {
+		delete(p.leases, id)
+	}
+	want := p.target
+	for _, count := range p.leases {
+		want -= count
+	}
+
+	for len(p.segments) < want {
+		path := filepath.Join(p.dir, fmt.Sprintf("%s%06d", segmentPrefix, p.next))
+		p.next++
+		if err := allocate(path, p.segmentSize); err != nil {
+			os.Remove(path)
+			if p.lastError == "" {
+				p.logger.Warn("预留大页分段失败，稍后重试",
+					zap.Int("reserved", len(p.segments)),
+					zap.Int("want", want),
+					zap.Error(err),
+				)
+			}
+			p.lastError = err.Error()
+			return
+		}
+		p.segments = append(p.segments, path)
+	}
+	p.lastError = ""
+}
@@ -0,0 +1,175 @@
+package i18n
+
+// catalog 各语言中错误码的说明，错误码与 API 响应的 error 字段相同
+var catalog = map[string]map[string]string{
+	LangZH: {
+		"ADMISSION_REJECTED":       "准入检查拒绝了传输",
+		"ADMISSION_UNAVAILABLE":    "准入检查服务不可用",
+		"BATCH_NOT_FOUND":          "批量传输不存在",
+		"BATCH_REJECTED":           "批量传输中的所有传输均创建失败",
+		"BINARY_FETCH_FAILED":      "下载 rtranfile 失败",
+		"BINARY_TOO_LARGE":         "rtranfile 文件超过大小上限",
+		"CAMPAIGN_ENDED":           "传输活动已结束",
+		"CAMPAIGN_NOT_ENDED":       "传输活动尚未结束，没有报告",
+		"CAMPAIGN_NOT_FOUND":       "传输活动不存在",
+		"CANCEL_ERROR":             "取消传输任务失败",
+		"CHECKSUM_MISMATCH":        "rtranfile 文件的校验和不一致",
+		"CLIENT_TRANSFER_ERROR":    "客户端调用服务端API失败",
+		"CONCURRENCY_LIMIT":        "已达到最大并发传输限制",
+		"CONFIG_PERSIST_FAILED":    "写入配置文件失败",
+		"DEADLINE_PASSED":          "截止时间已过",
+		"DEPENDENCY_FAILED":        "依赖的任务已失败或被取消",
+		"DEVICE_DISCOVERY_FAILED":  "发现 RDMA 设备失败",
+		"DEVICE_NOT_FOUND":         "RDMA 设备不存在",
+		"DEVICE_UNAVAILABLE":       "RDMA 设备不可用",
+		"FAULT_INJECTED":           "注入的故障",
+		"FILE_EXISTS":              "目标文件已存在",
+		"FILE_NOT_FOUND":           "文件不存在",
+		"FILE_TOO_LARGE":           "文件超过大小上限",
+		"FORBIDDEN":                "没有权限执行该操作",
+		"FORCE_COMPLETE_ERROR":     "强制结束任务失败",
+		"GRANT_REJECTED":           "一次性传输令牌无效或与请求不一致",
+		"HANDOFF_CONFLICT":         "已有进行中的角色切换",
+		"HANDOFF_UNAVAILABLE":      "只有统一模式支持运行时切换角色或重启",
+		"HEARTBEAT_ERROR":          "上报心跳失败",
+		"INTERNAL_ERROR":           "内部错误",
+		"INTERNAL_SERVER_ERROR":    "服务器内部错误",
+		"INTERVAL_NOT_ELAPSED":     "距离上次传输的间隔不足",
+		"INVALID_DEPENDENCY":       "依赖无效",
+		"INVALID_LEVEL":            "日志级别无效",
+		"INVALID_MODE":             "传输模式无效",
+		"INVALID_NAMESPACE":        "命名空间无效",
+		"INVALID_CHECKSUM":         "文件摘要无效",
+		"INVALID_PATH":             "路径无效",
+		"INVALID_REQUEST":          "请求参数无效",
+		"INVALID_SIGNATURE":        "请求签名无效",
+		"INVALID_STAGING":          "对象存储暂存设置无效",
+		"IP_NOT_ALLOWED":           "客户端地址不在允许列表中",
+		"LINK_DOWN":                "RDMA链路不可用",
+		"LOGGING_ERROR":            "调整日志级别失败",
+		"METADATA_ERROR":           "处理文件元数据失败",
+		"MISSING_PARAM":            "缺少必需的参数",
+		"MODE_DISABLED":            "传输模式未启用",
+		"NETWORK_FILESYSTEM":       "传输模式目录位于网络文件系统",
+		"NOT_LEADER":               "当前实例不是领导者，只提供只读 API",
+		"NOT_RESUMABLE":            "任务不能续传",
+		"NOT_RETRYABLE":            "任务不能重试",
+		"NO_REFERENCE_MANIFEST":    "没有可用于比较的分块清单",
+		"PIPELINE_CANNOT_CANCEL":   "流水线已结束，不能取消",
+		"PIPELINE_NOT_FOUND":       "流水线不存在",
+		"PREPARE_ERROR":            "准备传输环境失败",
+		"PROGRESS_ERROR":           "上报传输进度失败",
+		"RATE_LIMITED":             "请求过于频繁",
+		"READ_ONLY":                "只读调用方只能调用查询接口",
+		"REPLAYED_REQUEST":         "重复的签名请求",
+		"REQUEST_TOO_LARGE":        "请求体过大",
+		"RESUME_ERROR":             "续传传输任务失败",
+		"RETRY_ERROR":              "重试传输任务失败",
+		"SERVICE_ERROR":            "服务未初始化或不可用",
+		"SESSION_EXPIRED":          "传输会话已过期",
+		"SHUTTING_DOWN":            "服务正在关闭，不接受新的传输",
+		"SIGNATURE_INVALID":        "请求签名无效",
+		"STAGE_CANNOT_CANCEL":      "暂存任务已结束，不能取消",
+		"STAGE_ERROR":              "本地暂存失败",
+		"STAGE_JOB_NOT_FOUND":      "暂存任务不存在",
+		"STAGE_SOURCE_NOT_ALLOWED": "源文件不在允许暂存的目录中",
+		"STAGING_UNAVAILABLE":      "对象存储不可用",
+		"TASK_NOT_FOUND":           "任务不存在",
+		"TASK_RUNNING":             "任务的传输进程仍在运行",
+		"UNAUTHORIZED":             "未认证或认证信息无效",
+		"UNKNOWN_DEPENDENCY":       "依赖的任务不存在",
+		"UNKNOWN_DEVICE":           "未知的 RDMA 设备",
+		"UNKNOWN_PROFILE":          "未知的配置档案",
+		"UNKNOWN_QOS_CLASS":        "未知的 QoS 等级",
+		"UNSUPPORTED_PLAN_VERSION": "不支持的传输计划版本",
+		"VALIDATION_ERROR":         "请求验证失败",
+		"VERIFY_ERROR":             "分块校验失败",
+		"VERSION_EXISTS":           "rtranfile 版本已存在",
+		"VERSION_NOT_FOUND":        "rtranfile 版本不存在",
+	},
+	LangEN: {
+		"ADMISSION_REJECTED":       "The transfer was rejected by the admission check",
+		"ADMISSION_UNAVAILABLE":    "The admission check service is unavailable",
+		"BATCH_NOT_FOUND":          "Batch transfer not found",
+		"BATCH_REJECTED":           "None of the transfers in the batch could be created",
+		"BINARY_FETCH_FAILED":      "Failed to fetch the rtranfile binary",
+		"BINARY_TOO_LARGE":         "The rtranfile binary exceeds the size limit",
+		"CAMPAIGN_ENDED":           "The campaign has already ended",
+		"CAMPAIGN_NOT_ENDED":       "The campaign has not ended yet and has no report",
+		"CAMPAIGN_NOT_FOUND":       "Campaign not found",
+		"CANCEL_ERROR":             "Failed to cancel the transfer",
+		"CHECKSUM_MISMATCH":        "The rtranfile binary checksum does not match",
+		"CLIENT_TRANSFER_ERROR":    "The client failed to call the server API",
+		"CONCURRENCY_LIMIT":        "The maximum number of concurrent transfers has been reached",
+		"CONFIG_PERSIST_FAILED":    "Failed to write the configuration file",
+		"DEADLINE_PASSED":          "The deadline has already passed",
+		"DEPENDENCY_FAILED":        "A task this transfer depends on failed or was cancelled",
+		"DEVICE_DISCOVERY_FAILED":  "Failed to discover RDMA devices",
+		"DEVICE_NOT_FOUND":         "RDMA device not found",
+		"DEVICE_UNAVAILABLE":       "The RDMA device is unavailable",
+		"FAULT_INJECTED":           "Injected fault",
+		"FILE_EXISTS":              "The target file already exists",
+		"FILE_NOT_FOUND":           "File not found",
+		"FILE_TOO_LARGE":           "The file exceeds the size limit",
+		"FORBIDDEN":                "Permission denied",
+		"FORCE_COMPLETE_ERROR":     "Failed to force-complete the task",
+		"GRANT_REJECTED":           "The one-time transfer token is invalid or does not match the request",
+		"HANDOFF_CONFLICT":         "A role handoff is already in progress",
+		"HANDOFF_UNAVAILABLE":      "Runtime role handoff and restart are only supported in unified mode",
+		"HEARTBEAT_ERROR":          "Failed to record the heartbeat",
+		"INTERNAL_ERROR":           "Internal error",
+		"INTERNAL_SERVER_ERROR":    "Internal server error",
+		"INTERVAL_NOT_ELAPSED":     "The minimum interval since the previous transfer has not elapsed",
+		"INVALID_DEPENDENCY":       "Invalid dependency",
+		"INVALID_LEVEL":            "Invalid log level",
+		"INVALID_MODE":             "Invalid transfer mode",
+		"INVALID_NAMESPACE":        "Invalid namespace",
+		"INVALID_CHECKSUM":         "Invalid file checksum",
+		"INVALID_PATH":             "Invalid path",
+		"INVALID_REQUEST":          "Invalid request",
+		"INVALID_SIGNATURE":        "Invalid request signature",
+		"INVALID_STAGING":          "Invalid object storage staging settings",
+		"IP_NOT_ALLOWED":           "The client address is not in the allow list",
+		"LINK_DOWN":                "The RDMA link is down",
+		"LOGGING_ERROR":            "Failed to change the log level",
+		"METADATA_ERROR":           "Failed to process file metadata",
+		"MISSING_PARAM":            "A required parameter is missing",
+		"MODE_DISABLED":            "The transfer mode is disabled",
+		"NETWORK_FILESYSTEM":       "The transfer mode directory is on a network filesystem",
+		"NOT_LEADER":               "This instance is not the leader and only serves read-only requests",
+		"NOT_RESUMABLE":            "The task cannot be resumed",
+		"NOT_RETRYABLE":            "The task cannot be retried",
+		"NO_REFERENCE_MANIFEST":    "No reference manifest is available",
+		"PIPELINE_CANNOT_CANCEL":   "The pipeline has ended and cannot be cancelled",
+		"PIPELINE_NOT_FOUND":       "Pipeline not found",
+		"PREPARE_ERROR":            "Failed to prepare the transfer",
+		"PROGRESS_ERROR":           "Failed to record the transfer progress",
+		"RATE_LIMITED":             "Too many requests",
+		"READ_ONLY":                "Read-only callers can only call query endpoints",
+		"REPLAYED_REQUEST":         "Replayed signed request",
+		"REQUEST_TOO_LARGE":        "The request body is too large",
+		"RESUME_ERROR":             "Failed to resume the transfer",
+		"RETRY_ERROR":              "Failed to retry the transfer",
+		"SERVICE_ERROR":            "The service is not initialized or unavailable",
+		"SESSION_EXPIRED":          "The transfer session has expired",
+		"SHUTTING_DOWN":            "The service is shutting down and does not accept new transfers",
+		"SIGNATURE_INVALID":        "Invalid request signature",
+		"STAGE_CANNOT_CANCEL":      "The staging job has ended and cannot be cancelled",
+		"STAGE_ERROR":              "Local staging failed",
+		"STAGE_JOB_NOT_FOUND":      "Staging job not found",
+		"STAGE_SOURCE_NOT_ALLOWED": "The source file is not in an allowed staging directory",
+		"STAGING_UNAVAILABLE":      "Object storage is unavailable",
+		"TASK_NOT_FOUND":           "Task not found",
+		"TASK_RUNNING":             "The transfer process of the task is still running",
+		"UNAUTHORIZED":             "Authentication is required or the credentials are invalid",
+		"UNKNOWN_DEPENDENCY":       "The task this transfer depends on does not exist",
+		"UNKNOWN_DEVICE":           "Unknown RDMA device",
+		"UNKNOWN_PROFILE":          "Unknown profile",
+		"UNKNOWN_QOS_CLASS":        "Unknown QoS class",
+		"UNSUPPORTED_PLAN_VERSION": "Unsupported transfer plan version",
+		"VALIDATION_ERROR":         "Request validation failed",
+		"VERIFY_ERROR":             "Chunk verification failed",
+		"VERSION_EXISTS":           "The rtranfile version already exists",
+		"VERSION_NOT_FOUND":        "The rtranfile version was not found",
+	},
+}
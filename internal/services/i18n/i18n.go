@@ -0,0 +1,84 @@
+// Package i18n 提供 API 消息目录：按 Accept-Language 或配置的默认语言选择中文或英文消息，错误码保持不变
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言
+const (
+	LangZH = "zh" // 服务端消息的原始语言
+	LangEN = "en"
+)
+
+// DefaultLanguage 未配置语言时使用的语言
+const DefaultLanguage = LangZH
+
+// Normalize 把语言标签（例如 en-US、zh_CN）规范为支持的语言，不支持时返回空字符串
+func Normalize(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	switch primary {
+	case LangZH:
+		return LangZH
+	case LangEN:
+		return LangEN
+	}
+	return ""
+}
+
+// Negotiate 按 Accept-Language 的权重选择支持的语言，都不支持或请求头为空时返回 fallback
+func Negotiate(header, fallback string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if lang := Normalize(tag); lang != "" && q > 0 {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+type contextKey struct{}
+
+// WithLanguage 在上下文中记录本次请求使用的语言
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext 获取请求使用的语言，未记录时返回 DefaultLanguage
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// ErrorMessage 获取错误码在指定语言中的说明，目录中没有该错误码时返回 false
+func ErrorMessage(lang, code string) (string, bool) {
+	messages, ok := catalog[lang]
+	if !ok {
+		return "", false
+	}
+	message, ok := messages[code]
+	return message, ok
+}
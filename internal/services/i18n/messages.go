@@ -0,0 +1,215 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// messages 服务端消息（任务状态说明、成功响应的 message、错误的详细消息）的译文，按中文原文查找
+// 原文中的 %s 匹配任意文本并继续按目录翻译（例如包装的错误），%d、%.1f 等匹配数字，译文按顺序或 %[n]s 形式的序号引用
+var messages = map[string]map[string]string{
+	LangEN: {
+		"%d 个分块全部一致":                                         "All %d chunks match",
+		"%s 端口 %d 未处于活跃状态":                                   "Port %[2]d of %[1]s is not active",
+		"%s 端口 %d 的 RDMA MTU %d 超过网络接口 %s 的 MTU %d":          "The RDMA MTU %[3]d of port %[2]d on %[1]s exceeds the MTU %[5]d of network interface %[4]s",
+		"%s 端口 %d 的活跃 MTU %d 小于最大 MTU %d，可能存在对端或交换机 MTU 不匹配": "The active MTU %[3]d of port %[2]d on %[1]s is below the maximum MTU %[4]d; the peer or switch MTU may not match",
+		"%s，在设备 %s 的调度队列中排在第 %d 位":                           "%[1]s; position %[3]d in the scheduling queue of device %[2]s",
+		"QoS 等级不存在":                                          "QoS class not found",
+		"RDMA 设备没有配置":                                        "No RDMA device is configured",
+		"RDMA设备不可用":                                          "The RDMA device is unavailable",
+		"RDMA链路不可用: %s":                                      "The RDMA link is down: %s",
+		"一次性传输令牌不能指定授权内容以外的传输设置: %s":                         "One-time transfer tokens cannot set transfer settings outside the grant: %s",
+		"一次性传输令牌不能用于传输活动":                                    "One-time transfer tokens cannot be used for campaigns",
+		"一次性传输令牌不能用于批量传输":                                    "One-time transfer tokens cannot be used for batch transfers",
+		"一次性传输令牌不能用于流水线传输":                                   "One-time transfer tokens cannot be used for pipelines",
+		"从 %d 字节处续传":                                         "Resuming from byte %d",
+		"任务ID不能为空":                                           "The task ID must not be empty",
+		"任务不存在":                                              "Task not found",
+		"任务不存在: %s":                                          "Task not found: %s",
+		"任务不能续传":                                             "The task cannot be resumed",
+		"任务不能重试":                                             "The task cannot be retried",
+		"传输令牌只能用于创建传输任务，以及为创建的任务上报心跳和进度": "Transfer tokens can only be used to create a transfer task and report heartbeats and progress for it",
+		"传输任务已取消":                  "The transfer task was cancelled",
+		"传输任务已启动":                  "The transfer task has started",
+		"传输会话已过期":                  "The transfer session has expired",
+		"传输完成，%d 个钩子执行失败":          "Transfer completed; %d hooks failed",
+		"传输完成，已推送到对象存储 %s":         "Transfer completed and pushed to object storage %s",
+		"传输服务未初始化":                 "The transfer service is not initialized",
+		"传输模式未启用":                  "The transfer mode is not enabled",
+		"传输模式目录位于网络文件系统":           "The transfer mode directory is on a network file system",
+		"传输活动不存在":                  "Campaign not found",
+		"传输活动尚未结束":                 "The campaign has not ended",
+		"传输活动已结束":                  "The campaign has ended",
+		"传输环境准备就绪，请在客户端从 %d 字节处续传": "The transfer environment is ready; resume from byte %d on the client",
+		"传输环境准备就绪，请在客户端执行传输命令":     "The transfer environment is ready; run the transfer command on the client",
+		"传输进度不能为负数":                "Transfer progress must not be negative",
+		"传输进程仍在运行":                 "The transfer process is still running",
+		"传输配置档案不存在":                "Transfer profile not found",
+		"依赖 %s 必须引用计划中之前的传输":       "Dependency %s must refer to an earlier transfer in the plan",
+		"依赖的任务不存在":                 "Dependency task not found",
+		"依赖的任务已完成，传输环境准备就绪":        "Dependencies completed; the transfer environment is ready",
+		"依赖的任务已完成，配置档案 %s 的时间窗口未开放，预计 %s 开始": "Dependencies completed; the time window of profile %s is closed, expected to start at %s",
+		"依赖的任务未成功完成":                     "A dependency did not complete successfully",
+		"依赖的第 %d 个传输创建失败":                "Failed to create dependency transfer %d",
+		"准入检查执行失败":                       "The admission check failed to run",
+		"准入检查拒绝了传输请求":                    "The admission check rejected the transfer request",
+		"准备传输环境失败: %s":                   "Failed to prepare the transfer environment: %s",
+		"准备续传的剩余部分失败: %s":                "Failed to prepare the resumed remainder: %s",
+		"分块校验失败: %s":                     "Chunk verification failed: %s",
+		"只有管理员可以切换运行模式（需要启用认证）":          "Only administrators can switch the run mode (authentication must be enabled)",
+		"只有管理员可以切换运行角色（需要启用认证）":          "Only administrators can switch the run role (authentication must be enabled)",
+		"只有管理员可以强制结束任务（需要启用认证）":          "Only administrators can force-complete tasks (authentication must be enabled)",
+		"只有管理员可以查询其他租户的用量":               "Only administrators can query other tenants' usage",
+		"只有管理员可以签发传输令牌（需要启用认证）":          "Only administrators can issue transfer tokens (authentication must be enabled)",
+		"只有管理员可以管理 rtranfile 版本（需要启用认证）": "Only administrators can manage rtranfile versions (authentication must be enabled)",
+		"只有统一模式（rdma-burst）支持运行时切换角色或重启": "Runtime role switching and restart are only supported in unified mode (rdma-burst)",
+		"只有统一模式（rdma-burst）支持运行时角色切换":    "Runtime role switching is only supported in unified mode (rdma-burst)",
+		"只读调用方只能调用查询接口":                  "Read-only callers can only call query endpoints",
+		"启动校验任务失败: %s":                   "Failed to start the verification task: %s",
+		"守护进程重启后已恢复传输会话":                 "The transfer session was restored after the daemon restarted",
+		"客户端传输失败":                        "The client transfer failed",
+		"客户端传输完成":                        "The client transfer completed",
+		"客户端传输完成，正在拼接续传收到的剩余部分":          "The client transfer completed; joining the resumed remainder",
+		"客户端传输完成，正在推送到对象存储 %s":           "The client transfer completed; pushing to object storage %s",
+		"客户端传输完成，正在校验文件摘要":               "The client transfer completed; verifying the file checksum",
+		"客户端传输已开始执行，请通过查询接口获取进度":         "The client transfer has started; query the task for progress",
+		"客户端地址不在允许列表中: %s":               "The client address is not in the allow list: %s",
+		"客户端模式不支持重试，请重新创建传输":             "Retry is not supported in client mode; create the transfer again",
+		"客户端正在从 %d 字节处续传，请通过查询接口获取进度":    "The client is resuming from byte %d; query the task for progress",
+		"客户端正在执行传输":                      "The client is running the transfer",
+		"客户端调用服务端API失败: %s":              "The client failed to call the server API: %s",
+		"对象存储不可用":                        "Object storage is unavailable",
+		"已达到最大并发传输限制":                    "The maximum number of concurrent transfers has been reached",
+		"当前平台不支持 reflink":                "reflink is not supported on this platform",
+		"恢复文件元数据失败: %s":                  "Failed to restore file metadata: %s",
+		"截止时间已过":                         "The deadline has passed",
+		"批量传输不存在":                        "Batch not found",
+		"文件超过传输模式的大小上限":                  "The file exceeds the size limit of the transfer mode",
+		"无效的传输路径":                        "Invalid transfer path",
+		"无效的对象存储暂存设置":                    "Invalid object storage staging settings",
+		"无效的文件摘要":                        "Invalid file checksum",
+		"无效的目标文件命名空间":                    "Invalid target namespace",
+		"无权操作其他调用方创建的任务":                 "Not allowed to operate on tasks created by other callers",
+		"时间窗口已开放，传输环境准备就绪":               "The time window is open; the transfer environment is ready",
+		"暂存任务不存在":                        "Staging job not found",
+		"服务器内部错误":                        "Internal server error",
+		"服务正在关闭，不再接受新的传输":                "The service is shutting down and no longer accepts new transfers",
+		"未达到传输最小间隔":                      "The minimum interval between transfers has not elapsed",
+		"校验任务已开始":                        "The verification task has started",
+		"模式切换已开始，正在排空当前角色的传输，通过 GET /api/v1/mode/switch 查询进度": "The mode switch has started and is draining transfers of the current role; query GET /api/v1/mode/switch for progress",
+		"模式切换请求已接受，需要重启服务生效":                                  "The mode switch request was accepted; restart the service to apply it",
+		"正在从对象存储拉取 %s":                                        "Pulling %s from object storage",
+		"正在准备从 %d 字节处续传的剩余部分":                                 "Preparing the remainder to resume from byte %d",
+		"正在准备续传的剩余部分: %d/%d 字节":                               "Preparing the resumed remainder: %d/%d bytes",
+		"没有可用的参考清单":                                           "No reference manifest is available",
+		"注入的临时错误，请稍后重试":                                       "Injected transient error; retry later",
+		"流水线不存在":                                              "Pipeline not found",
+		"源文件不在允许暂存的目录中":                                       "The source file is not in a directory allowed for staging",
+		"源文件已从对象存储拉取，传输环境准备就绪":                                "The source file was pulled from object storage; the transfer environment is ready",
+		"生成分块清单失败: %s":                                        "Failed to generate the chunk manifest: %s",
+		"目标文件已存在":                                             "The target file already exists",
+		"等待依赖的任务完成: %s":                                       "Waiting for dependencies to complete: %s",
+		"被常规传输抢占，在设备 %s 的调度队列中排在第 %d 位":                       "Preempted by a regular transfer; position %[2]d in the scheduling queue of device %[1]s",
+		"设备 %s 最多同时运行 %d 个传输，排在第 %d 位":                        "Device %s runs at most %d transfers at a time; queued at position %d",
+		"设备 %s 的链路速率为 %.0f Gb/s，加上本任务共 %d 个传输，按平均吞吐量 %.1f Gb/s 预计占满链路": "Device %s has a link rate of %.0f Gb/s; with this task there are %d transfers, which are expected to saturate the link at an average throughput of %.1f Gb/s",
+		"设备已空出名额，传输环境准备就绪":                                             "A device slot is free; the transfer environment is ready",
+		"请求参数无效: %s":          "Invalid request: %s",
+		"请求过于频繁，请稍后重试: %s":    "Too many requests, retry later: %s",
+		"读取文件元数据失败: %s":       "Failed to read file metadata: %s",
+		"运行模式已写入配置文件，重启服务后生效": "The run mode was written to the configuration file; restart the service to apply it",
+		"进程内切换角色的目标必须是 server 或 client，切换为自动检测请使用 apply=restart": "In-process role switching must target server or client; use apply=restart to switch to auto detection",
+		"配置档案 %s 的时间窗口未开放，预计 %s 开始":                              "The time window of profile %s is closed, expected to start at %s",
+	},
+}
+
+// template 含占位符的原文编译成的正则表达式
+type template struct {
+	pattern *regexp.Regexp
+	verbs   []byte // 各占位符的动词（s、d 或 f），与正则表达式的分组一一对应
+	target  string // 占位符统一为 %s 的译文
+}
+
+var (
+	templatesOnce sync.Once
+	templates     map[string][]template
+)
+
+// compileTemplates 把含占位符的原文编译为正则表达式，原文较长的模板优先匹配
+func compileTemplates() {
+	templates = make(map[string][]template)
+	verb := regexp.MustCompile(`%(?:\.\d+)?[sdf]`)
+	number := regexp.MustCompile(`%(\[\d+\])?(?:\.\d+)?[df]`)
+	for lang, entries := range messages {
+		var list []template
+		for source, target := range entries {
+			if !verb.MatchString(source) {
+				continue
+			}
+			var expr strings.Builder
+			var verbs []byte
+			expr.WriteString("^")
+			last := 0
+			for _, loc := range verb.FindAllStringIndex(source, -1) {
+				expr.WriteString(regexp.QuoteMeta(source[last:loc[0]]))
+				switch v := source[loc[1]-1]; v {
+				case 'd':
+					expr.WriteString(`(-?\d+)`)
+					verbs = append(verbs, v)
+				case 'f':
+					expr.WriteString(`(-?\d+(?:\.\d+)?)`)
+					verbs = append(verbs, v)
+				default:
+					expr.WriteString(`(.+?)`)
+					verbs = append(verbs, v)
+				}
+				last = loc[1]
+			}
+			expr.WriteString(regexp.QuoteMeta(source[last:]))
+			expr.WriteString("$")
+			list = append(list, template{
+				pattern: regexp.MustCompile(expr.String()),
+				verbs:   verbs,
+				target:  number.ReplaceAllString(target, "%${1}s"),
+			})
+		}
+		sort.SliceStable(list, func(i, j int) bool {
+			return len(list[i].pattern.String()) > len(list[j].pattern.String())
+		})
+		templates[lang] = list
+	}
+}
+
+// Translate 把服务端消息翻译为指定语言，目录中没有匹配的原文或语言为中文时返回 false
+// 占位符 %s 匹配到的文本（例如包装的错误消息）继续按目录翻译，没有译文时保留原文
+func Translate(lang, text string) (string, bool) {
+	entries, ok := messages[lang]
+	if !ok || text == "" {
+		return "", false
+	}
+	if target, ok := entries[text]; ok {
+		return target, true
+	}
+
+	templatesOnce.Do(compileTemplates)
+	for _, t := range templates[lang] {
+		groups := t.pattern.FindStringSubmatch(text)
+		if groups == nil {
+			continue
+		}
+		args := make([]any, len(t.verbs))
+		for i, v := range t.verbs {
+			arg := groups[i+1]
+			if v == 's' {
+				if translated, ok := Translate(lang, arg); ok {
+					arg = translated
+				}
+			}
+			args[i] = arg
+		}
+		return fmt.Sprintf(t.target, args...), true
+	}
+	return "", false
+}
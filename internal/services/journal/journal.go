@@ -0,0 +1,197 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// maxLineSize 单条记录的最大长度
+const maxLineSize = 1024 * 1024
+
+// Entry 任务状态变更记录
+type Entry struct {
+	Task       models.TransferTask     `json:"task"`
+	PID        int                     `json:"pid,omitempty"`         // rtranfile 进程 PID
+	StartTicks uint64                  `json:"start_ticks,omitempty"` // rtranfile 进程启动时间（系统启动后的时钟周期数），重新挂载前据此确认 PID 没有被复用
+	LogFile    string                  `json:"log_file,omitempty"`    // rtranfile 日志文件，用于重新挂载进度监控
+	Device     string                  `json:"device,omitempty"`
+	Request    *models.TransferRequest `json:"request,omitempty"` // 延后任务和未结束会话的原始请求，重启后据此重新等待时间窗口或重新登记会话
+	RecordedAt time.Time               `json:"recorded_at"`
+}
+
+// Journal 任务状态预写日志，每次状态变更追加一行 JSON 并落盘
+type Journal struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	logger *zap.Logger
+}
+
+// Open 打开（不存在时创建）日志文件
+func Open(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建任务日志目录失败: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开任务日志失败: %v", err)
+	}
+
+	return &Journal{
+		path:   path,
+		file:   file,
+		logger: logger.GetLogger().Named(logger.ComponentTransfer),
+	}, nil
+}
+
+// Path 获取日志文件路径
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append 追加一条记录并同步到磁盘
+func (j *Journal) Append(entry Entry) error {
+	if entry.RecordedAt.IsZero() {
+		entry.RecordedAt = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化任务记录失败: %v", err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return fmt.Errorf("任务日志已关闭")
+	}
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("写入任务日志失败: %v", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("同步任务日志失败: %v", err)
+	}
+	return nil
+}
+
+// Load 读取日志，返回每个任务的最新记录（按任务首次出现的顺序）
+// 崩溃时写了一半的记录会被跳过
+func (j *Journal) Load() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("打开任务日志失败: %v", err)
+	}
+	defer file.Close()
+
+	latest := make(map[string]Entry)
+	var order []string
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Task.ID == "" {
+			j.logger.Warn("跳过损坏的任务日志记录", zap.String("path", j.path), zap.Int("line", lineNo))
+			continue
+		}
+		if _, exists := latest[entry.Task.ID]; !exists {
+			order = append(order, entry.Task.ID)
+		}
+		latest[entry.Task.ID] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取任务日志失败: %v", err)
+	}
+
+	entries := make([]Entry, 0, len(order))
+	for _, id := range order {
+		entries = append(entries, latest[id])
+	}
+	return entries, nil
+}
+
+// Compact 用给定记录重写日志，丢弃历史状态变更
+func (j *Journal) Compact(entries []Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("创建任务日志临时文件失败: %v", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("写入任务日志临时文件失败: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入任务日志临时文件失败: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("同步任务日志临时文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("关闭任务日志临时文件失败: %v", err)
+	}
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("替换任务日志失败: %v", err)
+	}
+
+	// 重新打开追加句柄
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("重新打开任务日志失败: %v", err)
+	}
+	if j.file != nil {
+		j.file.Close()
+	}
+	j.file = file
+	return nil
+}
+
+// Close 关闭日志
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
@@ -0,0 +1,404 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/metrics"
+)
+
+// 未配置时使用的选主参数
+const (
+	defaultLeaseName     = "rdma-burst-coordinator"
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+// Status 选主状态
+type Status struct {
+	Identity  string     `json:"identity"`
+	Leader    string     `json:"leader"` // 当前观察到的领导者，未知时为空
+	IsLeader  bool       `json:"is_leader"`
+	Lease     string     `json:"lease"` // <namespace>/<name>
+	LastRenew *time.Time `json:"last_renew,omitempty"`
+}
+
+// Elector 基于 Kubernetes Lease 的选主器
+// 每个 retry_period 获取或续约 Lease；其他实例持有且未过期时保持从属，领导者续约失败超过 renew_deadline 后放弃领导权
+// 未启用时 New 返回 nil，nil 的 Elector 始终是领导者
+type Elector struct {
+	client        LeaseClient
+	identity      string
+	leaseName     string
+	namespace     string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+
+	mu           sync.RWMutex
+	lease        *Lease    // 最近一次读取或写入的 Lease
+	observedTime time.Time // Lease 持有者或续约时间最近一次变化时的本地时间，用于判断过期，避免依赖各节点时钟一致
+	leader       bool
+	lastRenew    time.Time
+	callbacks    []func(isLeader bool)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	logger *zap.Logger
+}
+
+// New 创建 Pod 内的选主器，settings 未启用时返回 nil
+func New(settings models.LeaderElectionSettings) (*Elector, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	if settings.LeaseName == "" {
+		settings.LeaseName = defaultLeaseName
+	}
+	if settings.Namespace == "" {
+		namespace, err := InClusterNamespace()
+		if err != nil {
+			return nil, err
+		}
+		settings.Namespace = namespace
+	}
+
+	client, err := NewInClusterClient(settings.Namespace, settings.LeaseName)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithClient(settings, client)
+}
+
+// NewWithClient 使用指定的 Lease 客户端创建选主器
+func NewWithClient(settings models.LeaderElectionSettings, client LeaseClient) (*Elector, error) {
+	identity := settings.Identity
+	if identity == "" {
+		identity = os.Getenv("POD_NAME")
+	}
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("获取实例标识失败: %v", err)
+		}
+		identity = hostname
+	}
+
+	e := &Elector{
+		client:        client,
+		identity:      identity,
+		leaseName:     settings.LeaseName,
+		namespace:     settings.Namespace,
+		leaseDuration: settings.LeaseDuration,
+		renewDeadline: settings.RenewDeadline,
+		retryPeriod:   settings.RetryPeriod,
+		logger:        logger.GetLogger().Named(logger.ComponentMonitor).Named("leader"),
+	}
+	if e.leaseName == "" {
+		e.leaseName = defaultLeaseName
+	}
+	if e.leaseDuration <= 0 {
+		e.leaseDuration = defaultLeaseDuration
+	}
+	if e.renewDeadline <= 0 {
+		e.renewDeadline = defaultRenewDeadline
+	}
+	if e.retryPeriod <= 0 {
+		e.retryPeriod = defaultRetryPeriod
+	}
+	if e.renewDeadline >= e.leaseDuration || e.retryPeriod >= e.renewDeadline {
+		return nil, fmt.Errorf("选主参数必须满足 lease_duration(%s) > renew_deadline(%s) > retry_period(%s)",
+			e.leaseDuration, e.renewDeadline, e.retryPeriod)
+	}
+	return e, nil
+}
+
+// OnChange 注册领导权变化回调，回调在选主 goroutine 中同步执行
+func (e *Elector) OnChange(callback func(isLeader bool)) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.callbacks = append(e.callbacks, callback)
+}
+
+// Start 开始选主
+func (e *Elector) Start(ctx context.Context) {
+	if e == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	done := e.done
+	e.mu.Unlock()
+
+	metrics.SetLeader(false)
+	e.logger.Info("开始选主",
+		zap.String("identity", e.identity),
+		zap.String("lease", e.namespace+"/"+e.leaseName),
+	)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.retryPeriod)
+		defer ticker.Stop()
+
+		for {
+			e.tryAcquireOrRenew(ctx)
+			select {
+			case <-ctx.Done():
+				e.release()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止选主，持有 Lease 时释放，其他实例无需等待 Lease 过期即可接管
+func (e *Elector) Stop() {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel = nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// IsLeader 当前实例是否是领导者
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Leader 获取当前观察到的领导者标识，未知时返回空字符串
+func (e *Elector) Leader() string {
+	if e == nil {
+		return ""
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.currentLeader(time.Now())
+}
+
+// Status 获取选主状态
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := Status{
+		Identity: e.identity,
+		Leader:   e.currentLeader(time.Now()),
+		IsLeader: e.leader,
+		Lease:    e.namespace + "/" + e.leaseName,
+	}
+	if !e.lastRenew.IsZero() {
+		lastRenew := e.lastRenew
+		status.LastRenew = &lastRenew
+	}
+	return status
+}
+
+// currentLeader 获取未过期的 Lease 持有者（调用方需持有锁）
+func (e *Elector) currentLeader(now time.Time) string {
+	if e.leader {
+		return e.identity
+	}
+	if e.lease == nil || e.expired(now) {
+		return ""
+	}
+	return e.lease.Spec.HolderIdentity
+}
+
+// tryAcquireOrRenew 获取或续约一次 Lease 并更新领导权
+func (e *Elector) tryAcquireOrRenew(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.renewDeadline)
+	defer cancel()
+
+	held, err := e.acquireOrRenew(ctx)
+	now := time.Now()
+	if err == nil {
+		if held {
+			e.mu.Lock()
+			e.lastRenew = now
+			e.mu.Unlock()
+		}
+		e.setLeader(held)
+		return
+	}
+	if ctx.Err() != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	e.mu.RLock()
+	leader, lastRenew := e.leader, e.lastRenew
+	e.mu.RUnlock()
+
+	if !leader {
+		e.logger.Debug("获取 Lease 失败", zap.Error(err))
+		return
+	}
+	e.logger.Warn("续约 Lease 失败", zap.Error(err), zap.Duration("since_last_renew", now.Sub(lastRenew)))
+	if now.Sub(lastRenew) >= e.renewDeadline {
+		e.logger.Error("续约 Lease 超过 renew_deadline，放弃领导权", zap.Duration("renew_deadline", e.renewDeadline))
+		e.setLeader(false)
+	}
+}
+
+// acquireOrRenew 读取 Lease，空闲、已过期或由自己持有时写入自己为持有者，返回是否持有 Lease
+func (e *Elector) acquireOrRenew(ctx context.Context) (bool, error) {
+	lease, err := e.client.Get(ctx)
+	now := time.Now()
+	if errors.Is(err, ErrLeaseNotFound) {
+		lease = &Lease{
+			Metadata: LeaseMetadata{Name: e.leaseName, Namespace: e.namespace},
+			Spec: LeaseSpec{
+				HolderIdentity:       e.identity,
+				LeaseDurationSeconds: int32(e.leaseDuration / time.Second),
+				AcquireTime:          NewMicroTime(now),
+				RenewTime:            NewMicroTime(now),
+			},
+		}
+		created, err := e.client.Create(ctx, lease)
+		if err != nil {
+			return false, err
+		}
+		e.observe(created, now)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	e.observe(lease, now)
+	holder := lease.Spec.HolderIdentity
+	if holder != "" && holder != e.identity {
+		e.mu.RLock()
+		expired := e.expired(now)
+		e.mu.RUnlock()
+		if !expired {
+			return false, nil
+		}
+		e.logger.Info("领导者 Lease 已过期，尝试接管", zap.String("previous_leader", holder))
+	}
+
+	if holder != e.identity {
+		lease.Spec.AcquireTime = NewMicroTime(now)
+		lease.Spec.LeaseTransitions++
+	}
+	lease.Spec.HolderIdentity = e.identity
+	lease.Spec.LeaseDurationSeconds = int32(e.leaseDuration / time.Second)
+	lease.Spec.RenewTime = NewMicroTime(now)
+
+	updated, err := e.client.Update(ctx, lease)
+	if err != nil {
+		return false, err
+	}
+	e.observe(updated, now)
+	return true, nil
+}
+
+// observe 记录读取到的 Lease，持有者或续约时间变化时刷新观察时间
+func (e *Elector) observe(lease *Lease, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lease == nil || e.lease.Spec.HolderIdentity != lease.Spec.HolderIdentity || !sameTime(e.lease.Spec.RenewTime, lease.Spec.RenewTime) {
+		e.observedTime = now
+	}
+	e.lease = lease
+}
+
+// expired 最近观察到的 Lease 是否已过期（调用方需持有锁）
+func (e *Elector) expired(now time.Time) bool {
+	duration := e.leaseDuration
+	if e.lease != nil && e.lease.Spec.LeaseDurationSeconds > 0 {
+		duration = time.Duration(e.lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return !e.observedTime.Add(duration).After(now)
+}
+
+// setLeader 更新领导权，变化时记录日志、更新指标并执行回调
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	if e.leader == leader {
+		e.mu.Unlock()
+		return
+	}
+	e.leader = leader
+	callbacks := append([]func(bool){}, e.callbacks...)
+	holder := ""
+	if e.lease != nil {
+		holder = e.lease.Spec.HolderIdentity
+	}
+	e.mu.Unlock()
+
+	metrics.SetLeader(leader)
+	if leader {
+		e.logger.Info("成为协调者领导者", zap.String("identity", e.identity))
+	} else {
+		e.logger.Warn("失去协调者领导权，切换为只读", zap.String("identity", e.identity), zap.String("leader", holder))
+	}
+	for _, callback := range callbacks {
+		callback(leader)
+	}
+}
+
+// release 释放持有的 Lease：清空持有者并将时长设为 1 秒
+func (e *Elector) release() {
+	e.mu.RLock()
+	leader, lease := e.leader, e.lease
+	e.mu.RUnlock()
+	if !leader || lease == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.retryPeriod)
+	defer cancel()
+
+	now := time.Now()
+	released := *lease
+	released.Spec.HolderIdentity = ""
+	released.Spec.LeaseDurationSeconds = 1
+	released.Spec.RenewTime = NewMicroTime(now)
+	if updated, err := e.client.Update(ctx, &released); err != nil {
+		e.logger.Warn("释放 Lease 失败，其他实例将在 Lease 过期后接管", zap.Error(err))
+	} else {
+		e.observe(updated, now)
+		e.logger.Info("已释放 Lease")
+	}
+	e.setLeader(false)
+}
+
+// sameTime 比较两个可能为空的时间
+func sameTime(a, b *MicroTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b.Time)
+}
@@ -0,0 +1,213 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir Pod 内服务账号令牌、CA 证书和命名空间的挂载目录
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// microTimeLayout Kubernetes MicroTime 的序列化格式
+const microTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var (
+	// ErrLeaseNotFound Lease 不存在
+	ErrLeaseNotFound = errors.New("lease 不存在")
+	// ErrLeaseConflict Lease 已被其他实例创建或更新（resourceVersion 不匹配）
+	ErrLeaseConflict = errors.New("lease 已被其他实例更新")
+)
+
+// Lease coordination.k8s.io/v1 Lease 中选主用到的字段
+type Lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   LeaseMetadata `json:"metadata"`
+	Spec       LeaseSpec     `json:"spec"`
+}
+
+// LeaseMetadata Lease 元数据，更新时通过 resourceVersion 做乐观并发控制
+type LeaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// LeaseSpec Lease 持有者信息
+type LeaseSpec struct {
+	HolderIdentity       string     `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int32      `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *MicroTime `json:"acquireTime,omitempty"`
+	RenewTime            *MicroTime `json:"renewTime,omitempty"`
+	LeaseTransitions     int32      `json:"leaseTransitions,omitempty"`
+}
+
+// MicroTime 微秒精度的时间，对应 Kubernetes 的 MicroTime
+type MicroTime struct {
+	time.Time
+}
+
+// NewMicroTime 创建 MicroTime
+func NewMicroTime(t time.Time) *MicroTime {
+	return &MicroTime{Time: t}
+}
+
+// MarshalJSON 按 Kubernetes 格式序列化
+func (t MicroTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(microTimeLayout))
+}
+
+// UnmarshalJSON 解析 RFC3339 时间
+func (t *MicroTime) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	if text == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// LeaseClient 读写选主使用的 Lease
+type LeaseClient interface {
+	// Get 获取 Lease，不存在时返回 ErrLeaseNotFound
+	Get(ctx context.Context) (*Lease, error)
+	// Create 创建 Lease，已存在时返回 ErrLeaseConflict
+	Create(ctx context.Context, lease *Lease) (*Lease, error)
+	// Update 更新 Lease，resourceVersion 不匹配时返回 ErrLeaseConflict
+	Update(ctx context.Context, lease *Lease) (*Lease, error)
+}
+
+// kubeClient 通过 Kubernetes API 读写 Lease，使用 Pod 的服务账号认证
+type kubeClient struct {
+	leasesURL string // .../namespaces/<namespace>/leases
+	name      string
+	tokenFile string
+	client    *http.Client
+}
+
+// InClusterNamespace 获取 Pod 所在的命名空间
+func InClusterNamespace() (string, error) {
+	data, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return "", fmt.Errorf("读取 Pod 命名空间失败: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// NewInClusterClient 创建 Pod 内的 Lease 客户端，API 地址取自 KUBERNETES_SERVICE_HOST 和 KUBERNETES_SERVICE_PORT
+func NewInClusterClient(namespace, name string) (LeaseClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("未在 Kubernetes Pod 中运行：缺少 KUBERNETES_SERVICE_HOST 或 KUBERNETES_SERVICE_PORT")
+	}
+
+	tokenFile := filepath.Join(serviceAccountDir, "token")
+	if _, err := os.Stat(tokenFile); err != nil {
+		return nil, fmt.Errorf("读取服务账号令牌失败: %v", err)
+	}
+
+	caData, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("读取集群 CA 证书失败: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caData) {
+		return nil, fmt.Errorf("解析集群 CA 证书失败")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return &kubeClient{
+		leasesURL: fmt.Sprintf("https://%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", net.JoinHostPort(host, port), namespace),
+		name:      name,
+		tokenFile: tokenFile,
+		client:    &http.Client{Transport: transport, Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Get 获取 Lease
+func (k *kubeClient) Get(ctx context.Context) (*Lease, error) {
+	return k.do(ctx, http.MethodGet, k.leasesURL+"/"+k.name, nil)
+}
+
+// Create 创建 Lease
+func (k *kubeClient) Create(ctx context.Context, lease *Lease) (*Lease, error) {
+	return k.do(ctx, http.MethodPost, k.leasesURL, lease)
+}
+
+// Update 更新 Lease
+func (k *kubeClient) Update(ctx context.Context, lease *Lease) (*Lease, error) {
+	return k.do(ctx, http.MethodPut, k.leasesURL+"/"+k.name, lease)
+}
+
+// do 发送请求并解析返回的 Lease
+func (k *kubeClient) do(ctx context.Context, method, url string, lease *Lease) (*Lease, error) {
+	var body io.Reader
+	if lease != nil {
+		lease.APIVersion = "coordination.k8s.io/v1"
+		lease.Kind = "Lease"
+		data, err := json.Marshal(lease)
+		if err != nil {
+			return nil, fmt.Errorf("序列化 Lease 失败: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Lease 请求失败: %v", err)
+	}
+	// 服务账号令牌会被 kubelet 定期轮换，每次请求重新读取
+	token, err := os.ReadFile(k.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取服务账号令牌失败: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 Kubernetes API 失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrLeaseNotFound
+	case resp.StatusCode == http.StatusConflict:
+		return nil, ErrLeaseConflict
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("Kubernetes API 返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result Lease
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析 Lease 失败: %v", err)
+	}
+	return &result, nil
+}
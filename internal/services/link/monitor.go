@@ -0,0 +1,249 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/utils"
+	"rdma-burst/pkg/logger"
+)
+
+// maxEvents 保留的最近链路状态变化事件数
+const maxEvents = 50
+
+// Status 链路整体状态
+type Status struct {
+	Up        bool                  `json:"up"`
+	Reason    string                `json:"reason,omitempty"`
+	Ports     []utils.RDMAPortState `json:"ports"`
+	CheckedAt time.Time             `json:"checked_at"`
+}
+
+// Event 链路状态变化事件
+type Event struct {
+	Device    string    `json:"device"`
+	Port      int       `json:"port"`
+	OldState  string    `json:"old_state"`
+	NewState  string    `json:"new_state"`
+	Active    bool      `json:"active"`
+	Rate      string    `json:"rate"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventHandler 链路状态变化回调
+type EventHandler func(event Event)
+
+// Monitor 周期性读取 sysfs 中配置设备的端口状态
+type Monitor struct {
+	mu       sync.RWMutex
+	devices  []string
+	interval time.Duration
+	status   Status
+	ports    map[string]utils.RDMAPortState // device/port -> 上次状态
+	events   []Event
+	handlers []EventHandler
+	cancel   context.CancelFunc
+	logger   *zap.Logger
+}
+
+// NewMonitor 创建链路监控器
+func NewMonitor(devices []string, interval time.Duration) *Monitor {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	configured := make([]string, 0, len(devices))
+	for _, device := range devices {
+		if device != "" {
+			configured = append(configured, device)
+		}
+	}
+
+	return &Monitor{
+		devices:  configured,
+		interval: interval,
+		status:   Status{Up: true, Ports: []utils.RDMAPortState{}},
+		ports:    make(map[string]utils.RDMAPortState),
+		events:   make([]Event, 0),
+		logger:   logger.GetLogger().Named(logger.ComponentMonitor),
+	}
+}
+
+// SetLogger 设置日志器
+func (m *Monitor) SetLogger(logger *zap.Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+}
+
+// OnChange 注册链路状态变化回调
+func (m *Monitor) OnChange(handler EventHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Start 立即检查一次并开始周期性检查
+func (m *Monitor) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	m.Check()
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check()
+			}
+		}
+	}()
+}
+
+// Stop 停止周期性检查
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
+// Check 读取所有配置设备的端口状态并更新链路状态
+func (m *Monitor) Check() Status {
+	status := Status{
+		Up:        true,
+		Ports:     make([]utils.RDMAPortState, 0),
+		CheckedAt: time.Now(),
+	}
+
+	for _, device := range m.devices {
+		ports, err := utils.GetRDMAPortStates(device)
+		if err != nil {
+			status.Up = false
+			status.Reason = err.Error()
+			continue
+		}
+
+		deviceUp := false
+		for _, port := range ports {
+			if port.Active {
+				deviceUp = true
+			}
+		}
+		if !deviceUp {
+			status.Up = false
+			status.Reason = fmt.Sprintf("RDMA设备 %s 没有处于 ACTIVE 状态的端口", device)
+		}
+		status.Ports = append(status.Ports, ports...)
+	}
+
+	events := m.update(status)
+	for _, event := range events {
+		m.emit(event)
+	}
+
+	return status
+}
+
+// update 保存最新状态并返回状态变化事件
+func (m *Monitor) update(status Status) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	events := make([]Event, 0)
+	for _, port := range status.Ports {
+		key := fmt.Sprintf("%s/%d", port.Device, port.Port)
+		previous, exists := m.ports[key]
+		m.ports[key] = port
+
+		if exists && previous.State == port.State && previous.PhysState == port.PhysState {
+			continue
+		}
+		// 首次检查时只记录非活跃端口
+		if !exists && port.Active {
+			continue
+		}
+
+		oldState := ""
+		if exists {
+			oldState = previous.State + "/" + previous.PhysState
+		}
+		event := Event{
+			Device:    port.Device,
+			Port:      port.Port,
+			OldState:  oldState,
+			NewState:  port.State + "/" + port.PhysState,
+			Active:    port.Active,
+			Rate:      port.Rate,
+			Timestamp: status.CheckedAt,
+		}
+		events = append(events, event)
+
+		m.events = append(m.events, event)
+		if len(m.events) > maxEvents {
+			m.events = m.events[len(m.events)-maxEvents:]
+		}
+	}
+
+	m.status = status
+	return events
+}
+
+// emit 记录事件并通知回调
+func (m *Monitor) emit(event Event) {
+	m.mu.RLock()
+	log := m.logger
+	handlers := append([]EventHandler(nil), m.handlers...)
+	m.mu.RUnlock()
+
+	fields := []zap.Field{
+		zap.String("device", event.Device),
+		zap.Int("port", event.Port),
+		zap.String("old_state", event.OldState),
+		zap.String("new_state", event.NewState),
+		zap.String("rate", event.Rate),
+	}
+	if event.Active {
+		log.Info("RDMA 链路状态变化", fields...)
+	} else {
+		log.Warn("RDMA 链路状态变化", fields...)
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Status 获取最近一次检查的链路状态
+func (m *Monitor) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// IsUp 链路是否可用，返回不可用原因
+func (m *Monitor) IsUp() (bool, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Up, m.status.Reason
+}
+
+// Events 获取最近的链路状态变化事件
+func (m *Monitor) Events() []Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Event(nil), m.events...)
+}
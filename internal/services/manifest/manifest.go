@@ -0,0 +1,238 @@
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Algorithm 分块摘要算法
+const Algorithm = "sha256"
+
+// DefaultChunkSize 默认分块大小
+const DefaultChunkSize = 64 * 1024 * 1024
+
+// fileSuffix 清单文件后缀，保存在数据文件旁边
+const fileSuffix = ".manifest.json"
+
+// Chunk 单个分块的摘要
+type Chunk struct {
+	Index  int    `json:"index"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+	Hash   string `json:"hash"`
+}
+
+// Manifest 文件分块清单
+type Manifest struct {
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	ChunkSize int64     `json:"chunk_size"`
+	Algorithm string    `json:"algorithm"`
+	Chunks    []Chunk   `json:"chunks"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Range 需要重新传输的字节范围
+type Range struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// Report 分块校验结果
+type Report struct {
+	Filename     string  `json:"filename"`
+	OK           bool    `json:"ok"`
+	Size         int64   `json:"size"`          // 本地文件大小
+	ExpectedSize int64   `json:"expected_size"` // 清单中的文件大小
+	TotalChunks  int     `json:"total_chunks"`
+	BadChunks    []int   `json:"bad_chunks,omitempty"` // 损坏或缺失的分块序号
+	BadRanges    []Range `json:"bad_ranges,omitempty"` // 合并相邻分块后的损坏范围
+}
+
+// PathFor 获取数据文件对应的清单文件路径
+func PathFor(filename string) string {
+	return filename + fileSuffix
+}
+
+// Build 读取文件并计算每个分块的摘要
+func Build(ctx context.Context, filename string, chunkSize int64) (*Manifest, error) {
+	return BuildTee(ctx, filename, chunkSize, nil)
+}
+
+// BuildTee 读取文件计算每个分块的摘要，tee 不为 nil 时按顺序同时写入读取的数据（例如计算整文件摘要），文件只读取一次
+func BuildTee(ctx context.Context, filename string, chunkSize int64, tee io.Writer) (*Manifest, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+
+	m := &Manifest{
+		Filename:  filepath.Base(filename),
+		Size:      info.Size(),
+		ChunkSize: chunkSize,
+		Algorithm: Algorithm,
+		CreatedAt: time.Now(),
+	}
+
+	for index, offset := 0, int64(0); offset < info.Size(); index, offset = index+1, offset+chunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hash, length, err := hashChunk(file, offset, chunkSize, tee)
+		if err != nil {
+			return nil, err
+		}
+		m.Chunks = append(m.Chunks, Chunk{Index: index, Offset: offset, Length: length, Hash: hash})
+	}
+
+	return m, nil
+}
+
+// Verify 按清单逐块校验文件，返回损坏的分块和需要重新传输的范围
+func Verify(ctx context.Context, filename string, m *Manifest) (*Report, error) {
+	if m.Algorithm != "" && m.Algorithm != Algorithm {
+		return nil, fmt.Errorf("不支持的摘要算法: %s", m.Algorithm)
+	}
+
+	report := &Report{
+		Filename:     filename,
+		ExpectedSize: m.Size,
+		TotalChunks:  len(m.Chunks),
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("打开文件失败: %v", err)
+		}
+		// 文件不存在时所有分块都需要重新传输
+		report.markBad(m.Chunks...)
+		return report, nil
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+	report.Size = info.Size()
+
+	for _, chunk := range m.Chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if chunk.Offset < 0 || chunk.Length < 0 || chunk.Offset+chunk.Length > info.Size() {
+			report.markBad(chunk)
+			continue
+		}
+		hash, _, err := hashChunk(file, chunk.Offset, chunk.Length, nil)
+		if err != nil {
+			return nil, err
+		}
+		if hash != chunk.Hash {
+			report.markBad(chunk)
+		}
+	}
+
+	report.OK = len(report.BadChunks) == 0 && report.Size == report.ExpectedSize
+	return report, nil
+}
+
+// hashChunk 计算从 offset 开始最多 length 字节的摘要，tee 不为 nil 时同时写入读取的数据
+func hashChunk(file *os.File, offset, length int64, tee io.Writer) (string, int64, error) {
+	hasher := sha256.New()
+	var w io.Writer = hasher
+	if tee != nil {
+		w = io.MultiWriter(hasher, tee)
+	}
+	n, err := io.Copy(w, io.NewSectionReader(file, offset, length))
+	if err != nil {
+		return "", 0, fmt.Errorf("读取文件分块失败 (offset=%d): %v", offset, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), n, nil
+}
+
+// markBad 记录损坏的分块，与上一个损坏范围相邻时合并
+func (r *Report) markBad(chunks ...Chunk) {
+	for _, chunk := range chunks {
+		r.BadChunks = append(r.BadChunks, chunk.Index)
+		if n := len(r.BadRanges); n > 0 && r.BadRanges[n-1].Offset+r.BadRanges[n-1].Length == chunk.Offset {
+			r.BadRanges[n-1].Length += chunk.Length
+			continue
+		}
+		r.BadRanges = append(r.BadRanges, Range{Offset: chunk.Offset, Length: chunk.Length})
+	}
+}
+
+// Save 保存清单到文件
+func Save(path string, m *Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("序列化清单失败: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("写入清单失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("保存清单失败: %v", err)
+	}
+	return nil
+}
+
+// Load 从文件读取清单
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("解析清单失败: %v", err)
+	}
+	return &m, nil
+}
+
+// LoadOrBuild 读取数据文件旁边的清单，清单不存在或比数据文件旧时重新生成并保存
+func LoadOrBuild(ctx context.Context, filename string, chunkSize int64) (*Manifest, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+
+	path := PathFor(filename)
+	if cached, err := os.Stat(path); err == nil && !cached.ModTime().Before(info.ModTime()) {
+		if m, err := Load(path); err == nil && m.Size == info.Size() {
+			return m, nil
+		}
+	}
+
+	m, err := Build(ctx, filename, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	// 清单保存失败不影响本次结果，下次重新生成
+	_ = Save(path, m)
+	return m, nil
+}
@@ -0,0 +1,330 @@
+package mrcache
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/metrics"
+)
+
+// ErrClosed 缓存已关闭
+var ErrClosed = errors.New("内存注册缓存已关闭")
+
+// Region 需要注册的缓冲区区域，设备、保护域、访问权限相同且地址范围完全覆盖时可以复用已有的注册
+type Region struct {
+	Device string  // RDMA 设备名
+	PD     uint64  // 保护域句柄
+	Addr   uintptr // 起始地址
+	Length int64   // 字节数
+	Access int     // ibv_access_flags
+	Path   string  // 映射的暂存文件，只用于失效，不参与匹配
+}
+
+// end 区域的结束地址（不含）
+func (r Region) end() uintptr {
+	return r.Addr + uintptr(r.Length)
+}
+
+// covers 判断区域是否完全覆盖另一个区域
+func (r Region) covers(other Region) bool {
+	return r.Device == other.Device && r.PD == other.PD && r.Access == other.Access &&
+		r.Addr <= other.Addr && other.end() <= r.end()
+}
+
+// overlaps 判断区域是否与同一设备上的地址范围重叠
+func (r Region) overlaps(device string, addr uintptr, length int64) bool {
+	return r.Device == device && r.Addr < addr+uintptr(length) && addr < r.end()
+}
+
+// MR 注册钩子返回的内存注册，Handle 由后端自行解释（例如 *C.struct_ibv_mr）
+type MR struct {
+	LKey   uint32
+	RKey   uint32
+	Handle any
+}
+
+// Hooks 注册和注销内存区域的钩子，由原生 verbs 后端提供
+type Hooks struct {
+	Register   func(region Region) (MR, error)
+	Deregister func(region Region, mr MR) error
+}
+
+// Stats 缓存状态
+type Stats struct {
+	Entries         int   `json:"entries"`          // 缓存的注册数
+	Leased          int   `json:"leased"`           // 被租用的注册数
+	RegisteredBytes int64 `json:"registered_bytes"` // 缓存的注册覆盖的总字节数
+	MaxBytes        int64 `json:"max_bytes"`
+	Hits            int64 `json:"hits"`
+	Misses          int64 `json:"misses"`
+	Evictions       int64 `json:"evictions"`
+}
+
+// entry 一个注册，引用计数为 0 时位于空闲链表中按最近使用顺序淘汰
+type entry struct {
+	region Region
+	mr     MR
+	refs   int
+	stale  bool          // 覆盖的映射已失效，归还时注销
+	cached bool          // 未缓存的注册（容量不足时直接注册）归还时注销
+	idle   *list.Element // 在空闲链表中的位置，被租用时为 nil
+}
+
+// Lease 租用的注册，传输结束时通过 Release 归还
+type Lease struct {
+	entry *entry
+}
+
+// MR 获取租用的注册
+func (l *Lease) MR() MR {
+	return l.entry.mr
+}
+
+// Region 获取注册覆盖的区域，可能大于申请的区域
+func (l *Lease) Region() Region {
+	return l.entry.region
+}
+
+// Cache 按缓冲区区域缓存内存注册：重复传输同一暂存文件时复用已有的注册，不再每次重新注册数 GB 的内存
+// 缓存按已注册的总字节数限制容量，超过时按最近使用顺序淘汰空闲的注册，全部被占用时直接注册不缓存
+type Cache struct {
+	mu        sync.Mutex
+	hooks     Hooks
+	maxBytes  int64
+	bytes     map[string]int64 // 设备 -> 缓存的注册覆盖的字节数
+	entries   []*entry
+	idle      *list.List // 空闲的注册，队首最近使用
+	hits      int64
+	misses    int64
+	evictions int64
+	closed    bool
+	logger    *zap.Logger
+}
+
+// New 创建内存注册缓存，maxBytes 为缓存的注册覆盖的总字节数上限（不超过设备的 max_mr_size 和进程的 locked memory 限制）
+func New(hooks Hooks, maxBytes int64) (*Cache, error) {
+	if hooks.Register == nil || hooks.Deregister == nil {
+		return nil, fmt.Errorf("内存注册缓存需要注册和注销钩子")
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("内存注册缓存的容量必须大于 0")
+	}
+	return &Cache{
+		hooks:    hooks,
+		maxBytes: maxBytes,
+		bytes:    make(map[string]int64),
+		idle:     list.New(),
+		logger:   logger.GetLogger().Named(logger.ComponentTransfer).Named("mrcache"),
+	}, nil
+}
+
+// Acquire 租用覆盖区域的注册：命中时复用已有的注册，否则调用注册钩子注册并在容量允许时缓存
+func (c *Cache) Acquire(region Region) (*Lease, error) {
+	if region.Length <= 0 {
+		return nil, fmt.Errorf("注册区域的长度必须大于 0")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+
+	if e := c.lookup(region); e != nil {
+		c.hits++
+		metrics.ObserveMRLookup(region.Device, true)
+		c.lease(e)
+		return &Lease{entry: e}, nil
+	}
+	c.misses++
+	metrics.ObserveMRLookup(region.Device, false)
+
+	// 先淘汰空闲的注册腾出容量，再注册，避免注销和注册的总量同时超过 locked memory 限制
+	cached := c.reclaim(region.Length)
+	mr, err := c.hooks.Register(region)
+	if err != nil {
+		return nil, fmt.Errorf("注册内存区域失败: %v", err)
+	}
+	e := &entry{region: region, mr: mr, refs: 1, cached: cached}
+	if cached {
+		c.entries = append(c.entries, e)
+		c.addBytes(region.Device, region.Length)
+	}
+	return &Lease{entry: e}, nil
+}
+
+// Release 归还租用的注册，引用计数为 0 的注册保留在缓存中，已失效或未缓存的注册立即注销
+func (c *Cache) Release(lease *Lease) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := lease.entry
+	if e.refs <= 0 {
+		return nil
+	}
+	e.refs--
+	if e.refs > 0 {
+		return nil
+	}
+	if !e.cached || e.stale || c.closed {
+		return c.deregister(e)
+	}
+	e.idle = c.idle.PushFront(e)
+	return nil
+}
+
+// Invalidate 暂存文件被删除、截断或重新写入前调用：注销与该地址范围重叠的空闲注册，仍被租用的注册标记为失效，归还时注销
+// 解除映射前必须先失效，否则注册会固定已释放的页面
+func (c *Cache) Invalidate(device string, addr uintptr, length int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.invalidate(func(r Region) bool { return r.overlaps(device, addr, length) })
+}
+
+// InvalidateFile 失效映射该暂存文件的所有注册
+func (c *Cache) InvalidateFile(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.invalidate(func(r Region) bool { return r.Path == path })
+}
+
+// Purge 设备链路断开或保护域重建时清空该设备的缓存
+func (c *Cache) Purge(device string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.invalidate(func(r Region) bool { return r.Device == device })
+}
+
+// Stats 获取缓存状态
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		Entries:   len(c.entries),
+		MaxBytes:  c.maxBytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+	for _, e := range c.entries {
+		if e.refs > 0 {
+			stats.Leased++
+		}
+	}
+	for _, bytes := range c.bytes {
+		stats.RegisteredBytes += bytes
+	}
+	return stats
+}
+
+// Close 注销所有空闲的注册，仍被租用的注册在归还时注销
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return c.invalidate(func(Region) bool { return true })
+}
+
+// lookup 查找完全覆盖区域的有效注册，优先选择最小的注册，调用方需持有锁
+func (c *Cache) lookup(region Region) *entry {
+	var best *entry
+	for _, e := range c.entries {
+		if e.stale || !e.region.covers(region) {
+			continue
+		}
+		if best == nil || e.region.Length < best.region.Length {
+			best = e
+		}
+	}
+	return best
+}
+
+// lease 增加注册的引用计数并移出空闲链表，调用方需持有锁
+func (c *Cache) lease(e *entry) {
+	if e.idle != nil {
+		c.idle.Remove(e.idle)
+		e.idle = nil
+	}
+	e.refs++
+}
+
+// reclaim 按最近使用顺序淘汰空闲的注册，直到可以缓存 length 字节；空闲的注册不足时返回 false，调用方需持有锁
+func (c *Cache) reclaim(length int64) bool {
+	if length > c.maxBytes {
+		return false
+	}
+	for c.total()+length > c.maxBytes {
+		back := c.idle.Back()
+		if back == nil {
+			return false
+		}
+		e := back.Value.(*entry)
+		if err := c.deregister(e); err != nil {
+			c.logger.Warn("淘汰内存注册失败", zap.String("device", e.region.Device), zap.Error(err))
+		}
+		c.evictions++
+		metrics.MREvicted(e.region.Device)
+	}
+	return true
+}
+
+// invalidate 注销匹配的空闲注册，被租用的注册标记为失效，返回第一个注销错误，调用方需持有锁
+func (c *Cache) invalidate(match func(Region) bool) error {
+	var firstErr error
+	for _, e := range append([]*entry(nil), c.entries...) {
+		if !match(e.region) {
+			continue
+		}
+		if e.refs > 0 {
+			e.stale = true
+			continue
+		}
+		if err := c.deregister(e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// deregister 把注册移出缓存并调用注销钩子，调用方需持有锁
+func (c *Cache) deregister(e *entry) error {
+	if e.idle != nil {
+		c.idle.Remove(e.idle)
+		e.idle = nil
+	}
+	if e.cached {
+		for i, other := range c.entries {
+			if other == e {
+				c.entries = append(c.entries[:i], c.entries[i+1:]...)
+				break
+			}
+		}
+		c.addBytes(e.region.Device, -e.region.Length)
+		e.cached = false
+	}
+	if err := c.hooks.Deregister(e.region, e.mr); err != nil {
+		return fmt.Errorf("注销内存区域失败: %v", err)
+	}
+	return nil
+}
+
+// addBytes 调整设备缓存的注册字节数并更新指标，调用方需持有锁
+func (c *Cache) addBytes(device string, delta int64) {
+	c.bytes[device] += delta
+	metrics.SetMRRegisteredBytes(device, c.bytes[device])
+}
+
+// total 缓存的注册覆盖的总字节数，调用方需持有锁
+func (c *Cache) total() int64 {
+	var total int64
+	for _, bytes := range c.bytes {
+		total += bytes
+	}
+	return total
+}
@@ -0,0 +1,233 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+// defaultSendTimeout 渠道未配置超时时间时使用的默认值
+const defaultSendTimeout = 10 * time.Second
+
+// Channel 通知渠道
+type Channel interface {
+	// Send 发送通知消息
+	Send(ctx context.Context, msg Message) error
+}
+
+// ChannelFactory 根据配置创建通知渠道
+type ChannelFactory func(config models.NotificationChannel) (Channel, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]ChannelFactory{
+		"webhook": newWebhookChannel,
+		"slack":   newSlackChannel,
+		"email":   newEmailChannel,
+	}
+)
+
+// RegisterChannel 注册通知渠道类型，已存在的类型会被替换
+func RegisterChannel(kind string, factory ChannelFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[kind] = factory
+}
+
+// NewChannel 按渠道类型创建通知渠道
+func NewChannel(config models.NotificationChannel) (Channel, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[config.Type]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的通知渠道类型: %s", config.Type)
+	}
+	return factory(config)
+}
+
+// sendTimeout 获取渠道的发送超时
+func sendTimeout(config models.NotificationChannel) time.Duration {
+	if config.Timeout > 0 {
+		return config.Timeout
+	}
+	return defaultSendTimeout
+}
+
+// webhookChannel 以 JSON 格式 POST 事件和渲染后的消息
+type webhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookChannel(config models.NotificationChannel) (Channel, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook 渠道的 url 不能为空")
+	}
+	return &webhookChannel{url: config.URL, client: &http.Client{Timeout: sendTimeout(config)}}, nil
+}
+
+// Send 发送通知
+func (c *webhookChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, c.client, c.url, map[string]interface{}{
+		"event":    msg.Event.Type,
+		"severity": msg.Event.Severity,
+		"subject":  msg.Subject,
+		"text":     msg.Body,
+		"task":     msg.Event.Task,
+		"stalled":  msg.Event.Stalled.String(),
+		"file":     msg.Event.File,
+		"time":     msg.Event.Timestamp,
+	})
+}
+
+// slackChannel 通过 Slack Incoming Webhook 发送消息
+type slackChannel struct {
+	url    string
+	client *http.Client
+}
+
+func newSlackChannel(config models.NotificationChannel) (Channel, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("slack 渠道的 url 不能为空")
+	}
+	return &slackChannel{url: config.URL, client: &http.Client{Timeout: sendTimeout(config)}}, nil
+}
+
+// Send 发送通知
+func (c *slackChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, c.client, c.url, map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", msg.Subject, msg.Body),
+	})
+}
+
+// postJSON 以 JSON 格式发送请求，返回非 2xx 状态码时返回错误
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("通知渠道返回错误状态: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// emailChannel 通过 SMTP 发送邮件
+type emailChannel struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	to      []string
+	timeout time.Duration
+}
+
+func newEmailChannel(config models.NotificationChannel) (Channel, error) {
+	if config.SMTPHost == "" {
+		return nil, fmt.Errorf("email 渠道的 smtp_host 不能为空")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("email 渠道需要配置 from 和 to")
+	}
+	port := config.SMTPPort
+	if port == 0 {
+		port = 25
+	}
+
+	c := &emailChannel{
+		addr:    net.JoinHostPort(config.SMTPHost, strconv.Itoa(port)),
+		host:    config.SMTPHost,
+		from:    config.From,
+		to:      config.To,
+		timeout: sendTimeout(config),
+	}
+	if config.Username != "" {
+		c.auth = smtp.PlainAuth("", config.Username, config.Password, config.SMTPHost)
+	}
+	return c, nil
+}
+
+// Send 发送通知，服务器支持时使用 STARTTLS
+func (c *emailChannel) Send(ctx context.Context, msg Message) error {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if c.auth != nil {
+		if err := client.Auth(c.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	for _, to := range c.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(c.message(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message 构建邮件内容
+func (c *emailChannel) message(msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", c.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(c.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
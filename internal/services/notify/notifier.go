@@ -0,0 +1,302 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// 事件类型
+const (
+	EventTaskFailed    = "task_failed"    // 任务失败
+	EventTaskStalled   = "task_stalled"   // 任务长时间没有进度
+	EventFileCorrupted = "file_corrupted" // 文件巡检发现保留的文件与分块清单不一致
+)
+
+// 默认模板
+const (
+	DefaultSubject  = `[rdma-burst] {{.Severity}}: {{.Type}} {{if .Task}}{{.Task.ID}}{{else}}{{.File}}{{end}}`
+	DefaultTemplate = `{{if eq .Type "file_corrupted"}}文件 {{.File}}（{{.Mode}}）与分块清单不一致，损坏的分块: {{.BadChunks}}{{else}}{{if eq .Type "task_failed"}}传输任务 {{.Task.ID}} 失败: {{.Task.Error}}{{else}}传输任务 {{.Task.ID}} 已 {{.Stalled}} 没有进度（{{printf "%.1f" .Task.Progress}}%）{{end}}
+文件: {{.Task.Filename}}（{{.Task.Mode}} {{.Task.Direction}}）{{end}}`
+)
+
+// Event 通知事件
+type Event struct {
+	Type      string               `json:"type"`
+	Severity  string               `json:"severity"`
+	Task      *models.TransferTask `json:"task,omitempty"`
+	Stalled   time.Duration        `json:"stalled,omitempty"`    // task_stalled: 没有进度的时长
+	Mode      string               `json:"mode,omitempty"`       // file_corrupted: 文件所在的传输模式目录
+	File      string               `json:"file,omitempty"`       // file_corrupted: 损坏的文件
+	BadChunks []int                `json:"bad_chunks,omitempty"` // file_corrupted: 损坏的分块序号
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+// Message 按模板渲染后的通知消息
+type Message struct {
+	Subject string
+	Body    string
+	Event   Event
+}
+
+// StallSource 停滞任务数据源
+type StallSource interface {
+	// StalledTasks 获取超过 timeout 没有进度更新的活跃任务
+	StalledTasks(timeout time.Duration) []*models.TransferTask
+}
+
+// route 严重级别的通知渠道和已解析的模板
+type route struct {
+	channels []string
+	subject  *template.Template
+	body     *template.Template
+}
+
+// Notifier 按事件的严重级别渲染消息并发送到对应渠道
+type Notifier struct {
+	mu           sync.Mutex
+	events       map[string]string
+	routes       map[string]route
+	channels     map[string]Channel
+	source       StallSource
+	interval     time.Duration
+	stallTimeout time.Duration
+	stalled      map[string]bool // 已发送过 task_stalled 的任务
+	cancel       context.CancelFunc
+	logger       *zap.Logger
+}
+
+// New 创建通知器，source 为空时不检查停滞任务
+func New(settings *models.NotificationSettings, source StallSource) (*Notifier, error) {
+	channels, routes, err := build(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notifier{
+		events:       settings.Events,
+		routes:       routes,
+		channels:     channels,
+		source:       source,
+		interval:     settings.CheckInterval,
+		stallTimeout: settings.StallTimeout,
+		stalled:      make(map[string]bool),
+		logger:       logger.GetLogger().Named(logger.ComponentMonitor).Named("notify"),
+	}
+	if n.interval <= 0 {
+		n.interval = 30 * time.Second
+	}
+	if n.stallTimeout <= 0 {
+		n.stallTimeout = 5 * time.Minute
+	}
+	if len(n.events) == 0 {
+		n.events = map[string]string{EventTaskFailed: "critical", EventTaskStalled: "warning", EventFileCorrupted: "critical"}
+	}
+	return n, nil
+}
+
+// Validate 验证通知设置：渠道配置、模板语法以及严重级别引用的渠道
+func Validate(settings *models.NotificationSettings) error {
+	_, _, err := build(settings)
+	return err
+}
+
+// build 创建通知渠道并解析各严重级别的模板
+func build(settings *models.NotificationSettings) (map[string]Channel, map[string]route, error) {
+	channels := make(map[string]Channel, len(settings.Channels))
+	for name, config := range settings.Channels {
+		channel, err := NewChannel(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("通知渠道 %s 无效: %v", name, err)
+		}
+		channels[name] = channel
+	}
+
+	routes := make(map[string]route, len(settings.Severities))
+	for severity, config := range settings.Severities {
+		r, err := parseRoute(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("严重级别 %s 的模板无效: %v", severity, err)
+		}
+		for _, name := range r.channels {
+			if _, ok := channels[name]; !ok {
+				return nil, nil, fmt.Errorf("严重级别 %s 引用的通知渠道不存在: %s", severity, name)
+			}
+		}
+		routes[severity] = r
+	}
+	return channels, routes, nil
+}
+
+// parseRoute 解析严重级别的消息模板
+func parseRoute(config models.NotificationRoute) (route, error) {
+	subject, body := config.Subject, config.Template
+	if subject == "" {
+		subject = DefaultSubject
+	}
+	if body == "" {
+		body = DefaultTemplate
+	}
+
+	r := route{channels: config.Channels}
+	var err error
+	if r.subject, err = template.New("subject").Parse(subject); err != nil {
+		return route{}, err
+	}
+	if r.body, err = template.New("body").Parse(body); err != nil {
+		return route{}, err
+	}
+	return r, nil
+}
+
+// SetLogger 设置日志器
+func (n *Notifier) SetLogger(logger *zap.Logger) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logger = logger
+}
+
+// Start 开始周期性检查停滞任务
+func (n *Notifier) Start(ctx context.Context) {
+	if n.source == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	n.mu.Lock()
+	n.cancel = cancel
+	n.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n.CheckStalls(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止检查
+func (n *Notifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.cancel != nil {
+		n.cancel()
+		n.cancel = nil
+	}
+}
+
+// TaskFailed 在后台发送任务失败通知，调用方可以持有任务所在服务的锁
+func (n *Notifier) TaskFailed(task *models.TransferTask) {
+	snapshot := *task
+	go n.Notify(context.Background(), Event{
+		Type:      EventTaskFailed,
+		Task:      &snapshot,
+		Timestamp: time.Now(),
+	})
+}
+
+// FileCorrupted 在后台发送文件损坏通知
+func (n *Notifier) FileCorrupted(mode, file string, badChunks []int) {
+	go n.Notify(context.Background(), Event{
+		Type:      EventFileCorrupted,
+		Mode:      mode,
+		File:      file,
+		BadChunks: badChunks,
+		Timestamp: time.Now(),
+	})
+}
+
+// CheckStalls 对新出现的停滞任务发送通知，每个任务只通知一次
+func (n *Notifier) CheckStalls(ctx context.Context) {
+	now := time.Now()
+	tasks := n.source.StalledTasks(n.stallTimeout)
+
+	n.mu.Lock()
+	current := make(map[string]bool, len(tasks))
+	var events []Event
+	for _, task := range tasks {
+		current[task.ID] = true
+		if n.stalled[task.ID] {
+			continue
+		}
+		events = append(events, Event{
+			Type:      EventTaskStalled,
+			Task:      task,
+			Stalled:   now.Sub(task.UpdatedAt).Round(time.Second),
+			Timestamp: now,
+		})
+	}
+	// 恢复进度或已结束的任务再次停滞时重新通知
+	n.stalled = current
+	n.mu.Unlock()
+
+	for _, event := range events {
+		n.Notify(ctx, event)
+	}
+}
+
+// Notify 按事件类型的严重级别渲染消息并发送到该级别的所有渠道，单个渠道失败只记录日志
+func (n *Notifier) Notify(ctx context.Context, event Event) {
+	n.mu.Lock()
+	log := n.logger
+	if event.Severity == "" {
+		event.Severity = n.events[event.Type]
+	}
+	r, ok := n.routes[event.Severity]
+	n.mu.Unlock()
+
+	fields := []zap.Field{
+		zap.String("event", event.Type),
+		zap.String("severity", event.Severity),
+	}
+	if event.Task != nil {
+		fields = append(fields, zap.String("task_id", event.Task.ID))
+	} else {
+		fields = append(fields, zap.String("file", event.File))
+	}
+	if !ok || len(r.channels) == 0 {
+		log.Debug("事件没有配置通知渠道", fields...)
+		return
+	}
+
+	msg, err := render(r, event)
+	if err != nil {
+		log.Error("渲染通知消息失败", append(fields, zap.Error(err))...)
+		return
+	}
+
+	for _, name := range r.channels {
+		if err := n.channels[name].Send(ctx, msg); err != nil {
+			log.Error("发送通知失败", append(fields, zap.String("channel", name), zap.Error(err))...)
+			continue
+		}
+		log.Info("已发送通知", append(fields, zap.String("channel", name))...)
+	}
+}
+
+// render 按模板渲染消息
+func render(r route, event Event) (Message, error) {
+	var subject, body bytes.Buffer
+	if err := r.subject.Execute(&subject, event); err != nil {
+		return Message{}, err
+	}
+	if err := r.body.Execute(&body, event); err != nil {
+		return Message{}, err
+	}
+	return Message{Subject: subject.String(), Body: body.String(), Event: event}, nil
+}
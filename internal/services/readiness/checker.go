@@ -0,0 +1,156 @@
+package readiness
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"rdma-burst/internal/utils"
+)
+
+// CheckFunc 就绪检查函数，返回 nil 表示通过
+type CheckFunc func() error
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Name     string `json:"name"`
+	Ready    bool   `json:"ready"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Result 就绪检查汇总结果
+type Result struct {
+	Ready  bool          `json:"ready"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// check 命名的检查项
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker 依赖就绪检查器
+type Checker struct {
+	mu     sync.RWMutex
+	checks []check
+}
+
+// NewChecker 创建就绪检查器
+func NewChecker() *Checker {
+	return &Checker{
+		checks: make([]check, 0),
+	}
+}
+
+// Add 注册检查项
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// Run 按注册顺序执行所有检查
+func (c *Checker) Run() Result {
+	c.mu.RLock()
+	checks := append([]check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	result := Result{
+		Ready:  true,
+		Checks: make([]CheckResult, 0, len(checks)),
+	}
+	for _, check := range checks {
+		start := time.Now()
+		err := check.fn()
+
+		checkResult := CheckResult{
+			Name:     check.name,
+			Ready:    err == nil,
+			Duration: time.Since(start).String(),
+		}
+		if err != nil {
+			checkResult.Error = err.Error()
+			result.Ready = false
+		}
+		result.Checks = append(result.Checks, checkResult)
+	}
+
+	return result
+}
+
+// BinaryCheck 检查可执行文件存在且可执行
+func BinaryCheck(path string) CheckFunc {
+	return func() error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("rtranfile 不存在: %v", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("rtranfile 路径是目录: %s", path)
+		}
+		if info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("rtranfile 不可执行: %s", path)
+		}
+		return nil
+	}
+}
+
+// WritableDirCheck 检查目录可写（目录不存在时尝试创建）
+func WritableDirCheck(dir string) CheckFunc {
+	return func() error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录 %s 失败: %v", dir, err)
+		}
+
+		file, err := os.CreateTemp(dir, ".ready-*")
+		if err != nil {
+			return fmt.Errorf("目录 %s 不可写: %v", dir, err)
+		}
+		name := file.Name()
+		file.Close()
+		return os.Remove(name)
+	}
+}
+
+// LogDirCheck 检查日志文件所在目录可写，未配置日志文件时跳过
+func LogDirCheck(logFile string) CheckFunc {
+	if logFile == "" {
+		return func() error { return nil }
+	}
+	return WritableDirCheck(filepath.Dir(logFile))
+}
+
+// StoreCheck 检查持久化文件（任务日志、用量记录等）所在目录可写，未配置的文件跳过
+func StoreCheck(files ...string) CheckFunc {
+	return func() error {
+		for _, file := range files {
+			if file == "" {
+				continue
+			}
+			if err := WritableDirCheck(filepath.Dir(file))(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// DeviceCheck 检查 RDMA 设备存在且至少有一个活跃端口
+func DeviceCheck(device string) CheckFunc {
+	return func() error {
+		ports, err := utils.GetRDMAPortStates(device)
+		if err != nil {
+			return err
+		}
+		for _, port := range ports {
+			if port.Active {
+				return nil
+			}
+		}
+		return fmt.Errorf("RDMA设备 %s 没有处于 ACTIVE 状态的端口", device)
+	}
+}
@@ -0,0 +1,158 @@
+package readiness
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/pkg/logger"
+)
+
+// DefaultProbeInterval 未配置健康检查间隔时的默认探测间隔
+const DefaultProbeInterval = 30 * time.Second
+
+// ProbeResult 单项探测的缓存结果
+type ProbeResult struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`   // 最近一次探测的耗时
+	CheckedAt time.Time `json:"checked_at"` // 最近一次探测完成的时间，尚未探测时为零值
+	Age       string    `json:"age"`        // 距最近一次探测完成的时间，明显大于探测间隔时说明探测被阻塞
+}
+
+// Snapshot 探测结果快照
+type Snapshot struct {
+	Healthy  bool          `json:"healthy"`
+	Interval string        `json:"interval"`
+	Checks   []ProbeResult `json:"checks"`
+}
+
+// Prober 按固定间隔在后台执行内部健康探测并缓存结果，健康检查接口直接返回缓存的快照，不在请求中执行探测
+type Prober struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	checks   []check
+	results  map[string]ProbeResult
+	cancel   context.CancelFunc
+	logger   *zap.Logger
+}
+
+// NewProber 创建健康探测器，interval 不大于 0 时使用默认值 30s
+func NewProber(interval time.Duration) *Prober {
+	if interval <= 0 {
+		interval = DefaultProbeInterval
+	}
+	return &Prober{
+		interval: interval,
+		checks:   make([]check, 0),
+		results:  make(map[string]ProbeResult),
+		logger:   logger.GetLogger().Named(logger.ComponentMonitor),
+	}
+}
+
+// Add 注册探测项，需要在 Start 之前调用
+func (p *Prober) Add(name string, fn CheckFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, check{name: name, fn: fn})
+}
+
+// Start 立即探测一次并开始周期性探测
+func (p *Prober) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	p.Probe()
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Probe()
+			}
+		}
+	}()
+}
+
+// Stop 停止周期性探测
+func (p *Prober) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// Probe 按注册顺序执行所有探测，每项完成后立即更新缓存，某项阻塞时其余结果的时间不受影响
+func (p *Prober) Probe() {
+	p.mu.RLock()
+	checks := append([]check(nil), p.checks...)
+	p.mu.RUnlock()
+
+	for _, check := range checks {
+		start := time.Now()
+		err := check.fn()
+
+		result := ProbeResult{
+			Name:      check.name,
+			Healthy:   err == nil,
+			Duration:  time.Since(start).String(),
+			CheckedAt: time.Now(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		p.mu.Lock()
+		previous, exists := p.results[check.name]
+		p.results[check.name] = result
+		p.mu.Unlock()
+
+		// 只在状态变化时记录日志，首次探测失败也记录
+		if exists && previous.Healthy == result.Healthy {
+			continue
+		}
+		if err != nil {
+			p.logger.Warn("内部健康探测失败", zap.String("check", check.name), zap.Error(err))
+		} else if exists {
+			p.logger.Info("内部健康探测恢复", zap.String("check", check.name))
+		}
+	}
+}
+
+// Snapshot 返回缓存的探测结果，尚未完成首次探测的项视为健康
+func (p *Prober) Snapshot() Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	snapshot := Snapshot{
+		Healthy:  true,
+		Interval: p.interval.String(),
+		Checks:   make([]ProbeResult, 0, len(p.checks)),
+	}
+	for _, check := range p.checks {
+		result, ok := p.results[check.name]
+		if !ok {
+			snapshot.Checks = append(snapshot.Checks, ProbeResult{Name: check.name, Healthy: true})
+			continue
+		}
+		result.Age = now.Sub(result.CheckedAt).Round(time.Millisecond).String()
+		if !result.Healthy {
+			snapshot.Healthy = false
+		}
+		snapshot.Checks = append(snapshot.Checks, result)
+	}
+	return snapshot
+}
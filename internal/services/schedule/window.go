@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minutesPerDay 一天的分钟数
+const minutesPerDay = 24 * 60
+
+// Window 每日时间窗口（本地时间），结束时间早于开始时间表示跨越午夜
+type Window struct {
+	start int // 距 00:00 的分钟数
+	end   int
+}
+
+// ParseWindow 解析 "HH:MM-HH:MM" 格式的时间窗口，开始和结束相同表示全天
+func ParseWindow(s string) (Window, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 2 {
+		return Window{}, fmt.Errorf("无效的时间窗口: %s（格式为 HH:MM-HH:MM）", s)
+	}
+
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return Window{}, fmt.Errorf("无效的时间窗口: %s: %v", s, err)
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return Window{}, fmt.Errorf("无效的时间窗口: %s: %v", s, err)
+	}
+	return Window{start: start, end: end}, nil
+}
+
+// ParseWindows 解析时间窗口列表
+func ParseWindows(values []string) ([]Window, error) {
+	windows := make([]Window, 0, len(values))
+	for _, value := range values {
+		window, err := ParseWindow(value)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// parseClock 解析 HH:MM
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("无效的时间 %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains 判断时间是否在窗口内
+func (w Window) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case w.start == w.end:
+		return true
+	case w.start < w.end:
+		return minute >= w.start && minute < w.end
+	default:
+		return minute >= w.start || minute < w.end
+	}
+}
+
+// String 返回 HH:MM-HH:MM 格式
+func (w Window) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.start/60, w.start%60, w.end/60, w.end%60)
+}
+
+// InAny 判断时间是否在任一窗口内，没有窗口表示不限制
+func InAny(windows []Window, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, window := range windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen 获取 t 之后最近一个窗口开放的时间，t 已在窗口内时返回 t
+func NextOpen(windows []Window, t time.Time) time.Time {
+	if InAny(windows, t) {
+		return t
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	best := minutesPerDay
+	for _, window := range windows {
+		wait := (window.start - minute + minutesPerDay) % minutesPerDay
+		if wait < best {
+			best = wait
+		}
+	}
+	return t.Truncate(time.Minute).Add(time.Duration(best) * time.Minute)
+}
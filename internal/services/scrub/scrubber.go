@@ -0,0 +1,290 @@
+package scrub
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/metrics"
+)
+
+// DefaultInterval 两轮巡检之间的默认间隔
+const DefaultInterval = 24 * time.Hour
+
+// manifestSuffix 清单文件后缀
+var manifestSuffix = manifest.PathFor("")
+
+// Corruption 校验失败的文件
+type Corruption struct {
+	Mode       string    `json:"mode"`
+	File       string    `json:"file"`
+	Size       int64     `json:"size"`
+	BadChunks  []int     `json:"bad_chunks,omitempty"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Report 一轮巡检的结果
+type Report struct {
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Checked    int          `json:"checked"`
+	Skipped    int          `json:"skipped"` // 数据文件不存在或巡检期间被修改
+	Errors     int          `json:"errors"`
+	Corrupted  []Corruption `json:"corrupted,omitempty"`
+}
+
+// Notifier 发现损坏文件时的回调
+type Notifier interface {
+	FileCorrupted(mode, file string, badChunks []int)
+}
+
+// Scrubber 周期性按清单重新校验模式目录中保留的文件，发现静默损坏时记录指标并发送通知
+type Scrubber struct {
+	mu        sync.Mutex
+	settings  *models.TransferSettings
+	interval  time.Duration
+	notifier  Notifier
+	corrupted map[string]bool // 已通知过的损坏文件
+	last      *Report
+	cancel    context.CancelFunc
+	logger    *zap.Logger
+}
+
+// New 创建巡检器
+func New(settings *models.TransferSettings) *Scrubber {
+	interval := settings.Scrub.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Scrubber{
+		settings:  settings,
+		interval:  interval,
+		corrupted: make(map[string]bool),
+		logger:    logger.GetLogger().Named(logger.ComponentMonitor).Named("scrub"),
+	}
+}
+
+// SetNotifier 设置损坏文件的通知器
+func (s *Scrubber) SetNotifier(notifier Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifier = notifier
+}
+
+// Start 开始周期性巡检，启动后先等待一个间隔
+func (s *Scrubber) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止巡检，正在进行的一轮在下一个分块处中止
+func (s *Scrubber) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// Last 获取最近一轮巡检的结果，尚未巡检时返回 nil
+func (s *Scrubber) Last() *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Run 执行一轮巡检：校验已启用模式目录中所有带清单的文件
+func (s *Scrubber) Run(ctx context.Context) *Report {
+	report := &Report{StartedAt: time.Now()}
+	current := make(map[string]bool)
+
+	for _, dir := range s.dirs() {
+		entries, err := os.ReadDir(dir.path)
+		if err != nil {
+			s.logger.Warn("读取模式目录失败", zap.String("mode", dir.mode), zap.String("dir", dir.path), zap.Error(err))
+			continue
+		}
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return report
+			}
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), manifestSuffix) {
+				continue
+			}
+			file := filepath.Join(dir.path, strings.TrimSuffix(entry.Name(), manifestSuffix))
+			if s.settings.PartialSuffix != "" && strings.HasSuffix(file, s.settings.PartialSuffix) {
+				// 正在接收的临时文件
+				continue
+			}
+
+			corruption, result := s.check(ctx, dir.mode, file)
+			metrics.ObserveScrub(dir.mode, result)
+			switch result {
+			case metrics.ScrubOK:
+				report.Checked++
+			case metrics.ScrubCorrupted:
+				report.Checked++
+				report.Corrupted = append(report.Corrupted, *corruption)
+				current[file] = true
+			case metrics.ScrubSkipped:
+				report.Skipped++
+			default:
+				report.Errors++
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return report
+	}
+	report.FinishedAt = time.Now()
+	metrics.SetScrubCorrupted(len(report.Corrupted))
+	metrics.ScrubFinished(report.FinishedAt)
+
+	s.mu.Lock()
+	notifier := s.notifier
+	var fresh []Corruption
+	for _, corruption := range report.Corrupted {
+		if !s.corrupted[corruption.File] {
+			fresh = append(fresh, corruption)
+		}
+	}
+	// 修复后再次损坏的文件重新通知
+	s.corrupted = current
+	s.last = report
+	s.mu.Unlock()
+
+	for _, corruption := range fresh {
+		s.logger.Error("文件校验失败，可能发生静默损坏",
+			zap.String("mode", corruption.Mode),
+			zap.String("file", corruption.File),
+			zap.Ints("bad_chunks", corruption.BadChunks))
+		if notifier != nil {
+			notifier.FileCorrupted(corruption.Mode, corruption.File, corruption.BadChunks)
+		}
+	}
+	s.logger.Info("文件巡检完成",
+		zap.Int("checked", report.Checked),
+		zap.Int("corrupted", len(report.Corrupted)),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("errors", report.Errors),
+		zap.Duration("duration", report.FinishedAt.Sub(report.StartedAt)))
+	return report
+}
+
+// check 按清单校验单个文件，巡检期间文件或清单被修改时跳过，避免把正在替换的文件误报为损坏
+func (s *Scrubber) check(ctx context.Context, mode, file string) (*Corruption, string) {
+	manifestPath := manifest.PathFor(file)
+	before, err := stat(file, manifestPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, metrics.ScrubSkipped
+		}
+		s.logger.Warn("获取文件信息失败", zap.String("file", file), zap.Error(err))
+		return nil, metrics.ScrubError
+	}
+
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		s.logger.Warn("读取分块清单失败", zap.String("file", file), zap.Error(err))
+		return nil, metrics.ScrubError
+	}
+	report, err := manifest.Verify(ctx, file, m)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("校验文件失败", zap.String("file", file), zap.Error(err))
+		}
+		return nil, metrics.ScrubError
+	}
+
+	after, err := stat(file, manifestPath)
+	if err != nil || after != before {
+		return nil, metrics.ScrubSkipped
+	}
+	if report.OK {
+		return nil, metrics.ScrubOK
+	}
+	return &Corruption{
+		Mode:       mode,
+		File:       file,
+		Size:       report.Size,
+		BadChunks:  report.BadChunks,
+		DetectedAt: time.Now(),
+	}, metrics.ScrubCorrupted
+}
+
+// fileState 用于判断巡检期间文件是否被修改
+type fileState struct {
+	size, manifestSize       int64
+	modTime, manifestModTime time.Time
+}
+
+func stat(file, manifestPath string) (fileState, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileState{}, err
+	}
+	manifestInfo, err := os.Stat(manifestPath)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{
+		size:            info.Size(),
+		manifestSize:    manifestInfo.Size(),
+		modTime:         info.ModTime(),
+		manifestModTime: manifestInfo.ModTime(),
+	}, nil
+}
+
+// modeDir 巡检的模式目录
+type modeDir struct {
+	mode, path string
+}
+
+// dirs 获取已启用模式的基础目录，多个模式共用同一目录时只巡检一次
+func (s *Scrubber) dirs() []modeDir {
+	modes := []struct {
+		name   string
+		config models.ModeConfig
+	}{
+		{models.ModeHugepages, s.settings.Modes.Hugepages},
+		{models.ModeTmpfs, s.settings.Modes.Tmpfs},
+		{models.ModeFilesystem, s.settings.Modes.Filesystem},
+	}
+
+	seen := make(map[string]bool)
+	var dirs []modeDir
+	for _, mode := range modes {
+		if !mode.config.Enabled || mode.config.BaseDir == "" || seen[mode.config.BaseDir] {
+			continue
+		}
+		seen[mode.config.BaseDir] = true
+		dirs = append(dirs, modeDir{mode: mode.name, path: mode.config.BaseDir})
+	}
+	return dirs
+}
@@ -0,0 +1,405 @@
+package staging
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+const (
+	// defaultRegion 未配置区域时签名使用的区域
+	defaultRegion = "us-east-1"
+	// defaultPartSize 未配置段大小时分段上传的段大小
+	defaultPartSize = 64 << 20
+	// minPartSize S3 要求除最后一段外每段不小于 5MB
+	minPartSize = 5 << 20
+	// maxParts S3 分段上传的段数上限
+	maxParts = 10000
+	// unsignedPayload 请求体不参与签名，避免为大文件计算 SHA-256
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// s3Client S3 兼容对象存储客户端，使用 AWS Signature Version 4 签名
+type s3Client struct {
+	endpoint  *url.URL
+	region    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	partSize  int64
+	timeout   time.Duration
+	client    *http.Client
+}
+
+// newS3Client 创建对象存储客户端
+func newS3Client(settings models.ObjectStoreSettings) (*s3Client, error) {
+	endpoint, err := url.Parse(settings.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("无效的对象存储地址: %s", settings.Endpoint)
+	}
+
+	c := &s3Client{
+		endpoint:  endpoint,
+		region:    settings.Region,
+		accessKey: settings.AccessKey,
+		secretKey: settings.SecretKey,
+		pathStyle: settings.PathStyle,
+		partSize:  settings.PartSize,
+		timeout:   settings.Timeout,
+		client:    &http.Client{},
+	}
+	if c.region == "" {
+		c.region = defaultRegion
+	}
+	if c.partSize <= 0 {
+		c.partSize = defaultPartSize
+	}
+	if c.partSize < minPartSize {
+		return nil, fmt.Errorf("对象存储的 part_size 不能小于 %d 字节", minPartSize)
+	}
+	return c, nil
+}
+
+// s3Error S3 返回的错误内容
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// Head 获取对象大小
+func (c *s3Client) Head(ctx context.Context, bucket, key string) (int64, error) {
+	resp, err := c.do(ctx, http.MethodHead, bucket, key, nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// Download 下载对象到 path：先写入同目录的临时文件，完成后重命名，返回写入的字节数；tee 不为 nil 时同时写入下载的数据
+func (c *s3Client) Download(ctx context.Context, bucket, key, path string, tee io.Writer) (int64, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".pull-*")
+	if err != nil {
+		return 0, fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var w io.Writer = tmp
+	if tee != nil {
+		w = io.MultiWriter(tmp, tee)
+	}
+	written, err := io.Copy(w, resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, fmt.Errorf("下载对象 %s/%s 失败: %v", bucket, key, err)
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return written, fmt.Errorf("下载对象 %s/%s 不完整: %d/%d 字节", bucket, key, written, resp.ContentLength)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return written, fmt.Errorf("保存下载的对象失败: %v", err)
+	}
+	return written, nil
+}
+
+// Upload 上传文件：不超过一段时直接上传，否则分段上传，失败时中止分段上传释放已上传的段
+func (c *s3Client) Upload(ctx context.Context, bucket, key, path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("打开待上传文件失败: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("获取待上传文件信息失败: %v", err)
+	}
+	size := info.Size()
+
+	if size <= c.partSize {
+		resp, err := c.do(ctx, http.MethodPut, bucket, key, nil, io.NewSectionReader(file, 0, size), size)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return size, nil
+	}
+
+	partSize := c.partSize
+	if (size+partSize-1)/partSize > maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	return size, c.uploadMultipart(ctx, bucket, key, file, size, partSize)
+}
+
+// completedPart 分段上传完成请求中的一段
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadMultipart 分段上传
+func (c *s3Client) uploadMultipart(ctx context.Context, bucket, key string, file *os.File, size, partSize int64) (err error) {
+	resp, err := c.do(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	decodeErr := xml.NewDecoder(resp.Body).Decode(&initiated)
+	resp.Body.Close()
+	if decodeErr != nil || initiated.UploadID == "" {
+		return fmt.Errorf("创建分段上传失败: %v", decodeErr)
+	}
+	uploadID := initiated.UploadID
+
+	defer func() {
+		if err != nil {
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			if resp, abortErr := c.do(abortCtx, http.MethodDelete, bucket, key, url.Values{"uploadId": {uploadID}}, nil, 0); abortErr == nil {
+				resp.Body.Close()
+			}
+		}
+	}()
+
+	var parts []completedPart
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		length := min(partSize, size-offset)
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		resp, err := c.do(ctx, http.MethodPut, bucket, key, query, io.NewSectionReader(file, offset, length), length)
+		if err != nil {
+			return fmt.Errorf("上传第 %d 段失败: %v", number, err)
+		}
+		resp.Body.Close()
+		parts = append(parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("序列化分段列表失败: %v", err)
+	}
+	resp, err = c.do(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return fmt.Errorf("完成分段上传失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// 完成分段上传可能在返回 200 后才失败，错误在响应体中
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var failure s3Error
+	if xml.Unmarshal(data, &failure) == nil && failure.Code != "" {
+		return fmt.Errorf("完成分段上传失败: %s: %s", failure.Code, failure.Message)
+	}
+	return nil
+}
+
+// do 发送签名的请求，非 2xx 响应返回错误
+func (c *s3Client) do(ctx context.Context, method, bucket, key string, query url.Values, body io.Reader, length int64) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	req, err := c.newRequest(ctx, method, bucket, key, query, body, length)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp, err := c.send(req, bucket, key)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// 响应体读取完成（关闭）后才取消超时上下文
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// send 发送请求并检查状态码
+func (c *s3Client) send(req *http.Request, bucket, key string) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求对象存储失败: %v", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var failure s3Error
+	if xml.Unmarshal(data, &failure) == nil && failure.Code != "" {
+		return nil, fmt.Errorf("对象存储返回 %d (%s/%s): %s: %s", resp.StatusCode, bucket, key, failure.Code, failure.Message)
+	}
+	return nil, fmt.Errorf("对象存储返回 %d (%s/%s)", resp.StatusCode, bucket, key)
+}
+
+// newRequest 创建签名的请求
+func (c *s3Client) newRequest(ctx context.Context, method, bucket, key string, query url.Values, body io.Reader, length int64) (*http.Request, error) {
+	target := *c.endpoint
+	escapedKey := escapePath(key)
+	if c.pathStyle {
+		basePath, baseRawPath := strings.TrimSuffix(target.Path, "/"), strings.TrimSuffix(target.EscapedPath(), "/")
+		target.Path = basePath + "/" + bucket + "/" + key
+		target.RawPath = baseRawPath + "/" + escapePath(bucket) + "/" + escapedKey
+	} else {
+		target.Host = bucket + "." + target.Host
+		target.Path = "/" + key
+		target.RawPath = "/" + escapedKey
+	}
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("创建对象存储请求失败: %v", err)
+	}
+	if body != nil {
+		req.ContentLength = length
+	}
+	c.sign(req, time.Now())
+	return req, nil
+}
+
+// sign 按 AWS Signature Version 4 签名请求，签名 host 和已设置的请求头
+func (c *s3Client) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 按签名要求编码查询参数：按名称排序，没有值的参数保留 "="
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			pairs = append(pairs, escape(name, true)+"="+escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapePath 按签名要求编码对象路径，保留 "/"
+func escapePath(path string) string {
+	return escape(path, false)
+}
+
+// escape 按 RFC 3986 编码，只保留非保留字符；encodeSlash 为 false 时保留 "/"
+func escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// hexSHA256 计算十六进制 SHA-256
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// cancelBody 关闭响应体时取消请求的超时上下文
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close 关闭响应体并取消上下文
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
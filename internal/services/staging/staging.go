@@ -0,0 +1,139 @@
+package staging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// DefaultStore 请求未指定对象存储时使用的名称
+const DefaultStore = "default"
+
+// ErrUnknownStore 请求引用的对象存储未配置
+var ErrUnknownStore = errors.New("对象存储未配置")
+
+// Stager 在传输前从对象存储拉取源文件，或在传输完成后把收到的文件推送到对象存储
+type Stager struct {
+	stores map[string]models.ObjectStoreSettings
+	logger *zap.Logger
+}
+
+// New 创建暂存器
+func New(stores map[string]models.ObjectStoreSettings) *Stager {
+	return &Stager{
+		stores: stores,
+		logger: logger.GetLogger().Named(logger.ComponentTransfer).Named("staging"),
+	}
+}
+
+// ValidateSpec 验证请求中的暂存设置
+func ValidateSpec(spec *models.StagingSpec) error {
+	if spec == nil {
+		return nil
+	}
+	for name, ref := range map[string]*models.ObjectRef{"pull": spec.Pull, "push": spec.Push} {
+		if ref != nil && (ref.Bucket == "" || ref.Key == "") {
+			return fmt.Errorf("staging.%s 需要指定 bucket 和 key", name)
+		}
+	}
+	return nil
+}
+
+// ValidateStores 验证对象存储配置
+func ValidateStores(stores map[string]models.ObjectStoreSettings) error {
+	for name, store := range stores {
+		if _, err := newS3Client(store); err != nil {
+			return fmt.Errorf("对象存储 %s: %v", name, err)
+		}
+		if store.AccessKey == "" || store.SecretKey == "" {
+			return fmt.Errorf("对象存储 %s 需要配置 access_key 和 secret_key", name)
+		}
+	}
+	return nil
+}
+
+// Check 检查对象引用的对象存储是否已配置
+func (s *Stager) Check(ref *models.ObjectRef) error {
+	_, err := s.client(ref)
+	return err
+}
+
+// Size 获取对象大小
+func (s *Stager) Size(ctx context.Context, ref *models.ObjectRef) (int64, error) {
+	client, err := s.client(ref)
+	if err != nil {
+		return 0, err
+	}
+	return client.Head(ctx, ref.Bucket, ref.Key)
+}
+
+// Pull 把对象下载到 path，返回下载的字节数；tee 不为 nil 时下载的数据同时写入 tee（例如计算摘要），不需要再读取一次文件
+func (s *Stager) Pull(ctx context.Context, ref *models.ObjectRef, path string, tee io.Writer) (int64, error) {
+	client, err := s.client(ref)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	size, err := client.Download(ctx, ref.Bucket, ref.Key, path, tee)
+	if err != nil {
+		return size, err
+	}
+	s.logger.Info("已从对象存储拉取文件",
+		zap.String("object", Describe(ref)),
+		zap.String("path", path),
+		zap.Int64("bytes", size),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return size, nil
+}
+
+// Push 把 path 上传为对象，返回上传的字节数
+func (s *Stager) Push(ctx context.Context, ref *models.ObjectRef, path string) (int64, error) {
+	client, err := s.client(ref)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	size, err := client.Upload(ctx, ref.Bucket, ref.Key, path)
+	if err != nil {
+		return 0, err
+	}
+	s.logger.Info("已推送文件到对象存储",
+		zap.String("object", Describe(ref)),
+		zap.String("path", path),
+		zap.Int64("bytes", size),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return size, nil
+}
+
+// client 获取对象引用的对象存储客户端
+func (s *Stager) client(ref *models.ObjectRef) (*s3Client, error) {
+	name := ref.Store
+	if name == "" {
+		name = DefaultStore
+	}
+	store, ok := s.stores[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStore, name)
+	}
+	return newS3Client(store)
+}
+
+// Describe 描述对象引用（<存储>:<桶>/<键>），用于日志和任务消息
+func Describe(ref *models.ObjectRef) string {
+	name := ref.Store
+	if name == "" {
+		name = DefaultStore
+	}
+	return fmt.Sprintf("%s:%s/%s", name, ref.Bucket, ref.Key)
+}
@@ -0,0 +1,91 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/tracing"
+)
+
+// ForceComplete 强制结束记录为活跃、但传输进程已不存在或客户端已失联的任务，释放其占用的并发名额
+// state 为 completed 或 failed，为空时已传输全部字节的任务记为完成，否则记为失败；返回结束后的任务和结束前的状态
+// 服务端监控的任务的传输进程仍在运行时返回 ErrTaskRunning，应改为取消任务
+func (ts *TransferService) ForceComplete(ctx context.Context, taskID, state, reason string) (task *models.TransferTask, previous string, err error) {
+	_, span := tracing.Start(ctx, "transfer.force_complete", attribute.String("transfer.task_id", taskID))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	message := "管理员强制结束"
+	if reason != "" {
+		message += ": " + reason
+	}
+
+	if session, ok := ts.sessions[taskID]; ok && session.endedAt == nil && !session.task.IsFinished() {
+		previous = session.task.Status
+		if forcedState(session.task, state) == models.StatusCompleted {
+			ts.finishSession(taskID, session, models.HeartbeatCompleted, "")
+		} else {
+			ts.finishSession(taskID, session, models.HeartbeatFailed, message)
+		}
+		session.task.Message = message
+		ts.progress.forget(taskID)
+		ts.stopIdleListener(session.mode)
+		ts.logForced(session.task, previous)
+		forced := *session.task
+		return &forced, previous, nil
+	}
+
+	taskWrapper, exists := ts.activeTasks[taskID]
+	if !exists || taskWrapper.Task.IsFinished() {
+		return nil, "", fmt.Errorf("%w或已结束: %s", ErrTaskNotFound, taskID)
+	}
+	if taskWrapper.Process.IsRunning() {
+		return nil, "", fmt.Errorf("%w（PID %d），请取消任务: %s", ErrTaskRunning, taskWrapper.Process.GetPID(), taskID)
+	}
+
+	previous = taskWrapper.Task.Status
+	if taskWrapper.Cancel != nil {
+		taskWrapper.Cancel()
+	}
+	if forcedState(taskWrapper.Task, state) == models.StatusCompleted {
+		taskWrapper.Task.MarkCompleted()
+	} else {
+		taskWrapper.Task.MarkFailed(message)
+	}
+	taskWrapper.Task.Message = message
+	ts.releaseTask(taskWrapper)
+	ts.logForced(taskWrapper.Task, previous)
+	forced := *taskWrapper.Task
+	return &forced, previous, nil
+}
+
+// forcedState 强制结束的任务的最终状态，未指定时按已传输字节数判断
+func forcedState(task *models.TransferTask, state string) string {
+	if state != "" {
+		return state
+	}
+	if task.TotalBytes > 0 && task.BytesTransferred >= task.TotalBytes {
+		return models.StatusCompleted
+	}
+	return models.StatusFailed
+}
+
+// logForced 记录强制结束的任务
+func (ts *TransferService) logForced(task *models.TransferTask, previous string) {
+	ts.logger.Warn("任务已被强制结束",
+		zap.String("task_id", task.ID),
+		zap.String("previous_status", previous),
+		zap.String("status", task.Status),
+		zap.Int64("bytes_transferred", task.BytesTransferred),
+		zap.Int64("total_bytes", task.TotalBytes),
+	)
+}
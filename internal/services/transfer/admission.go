@@ -0,0 +1,49 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/hook"
+	"rdma-burst/pkg/tracing"
+)
+
+// admit 在准备传输前依次执行准入钩子，钩子可以拒绝请求或返回修改后的请求
+// 修改后的请求需要重新通过校验，后续钩子收到的是修改后的请求
+func (ts *TransferService) admit(ctx context.Context, req *models.TransferRequest, settings *models.TransferSettings) error {
+	if settings == nil || len(settings.AdmissionHooks) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "transfer.admit")
+	defer span.End()
+
+	for i, h := range settings.AdmissionHooks {
+		name := hook.Name(i, h)
+		decision, err := hook.Admit(ctx, h, req)
+		if err != nil {
+			err = fmt.Errorf("%w: %s: %v", ErrAdmissionUnavailable, name, err)
+			tracing.RecordError(span, err)
+			return err
+		}
+		if !decision.IsAllowed() {
+			ts.logger.Info("准入钩子拒绝了传输请求",
+				zap.String("hook", name),
+				zap.String("filename", req.Filename),
+				zap.String("reason", decision.Reason),
+			)
+			return fmt.Errorf("%w: %s: %s", ErrAdmissionRejected, name, decision.Reason)
+		}
+		if decision.Request != nil {
+			if err := ValidateRequest(decision.Request); err != nil {
+				return fmt.Errorf("%w: %s 修改后的请求无效: %v", ErrAdmissionRejected, name, err)
+			}
+			*req = *decision.Request
+			ts.logger.Info("准入钩子修改了传输请求", zap.String("hook", name), zap.String("filename", req.Filename))
+		}
+	}
+	return nil
+}
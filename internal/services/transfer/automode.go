@@ -0,0 +1,187 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"rdma-burst/internal/models"
+)
+
+// modeConfig 获取传输模式的配置
+func modeConfig(settings *models.TransferSettings, mode string) (models.ModeConfig, bool) {
+	switch mode {
+	case models.ModeHugepages:
+		return settings.Modes.Hugepages, true
+	case models.ModeTmpfs:
+		return settings.Modes.Tmpfs, true
+	case models.ModeFilesystem:
+		return settings.Modes.Filesystem, true
+	}
+	return models.ModeConfig{}, false
+}
+
+// modeDir 获取传输模式的目录，模式未启用时返回 false
+func modeDir(settings *models.TransferSettings, mode string) (string, bool) {
+	config, ok := modeConfig(settings, mode)
+	return config.BaseDir, ok && config.Enabled && config.BaseDir != ""
+}
+
+// checkModeEnabled 检查请求的传输模式是否已启用，auto 模式的请求在选择实际模式之后检查
+func checkModeEnabled(settings *models.TransferSettings, req *models.TransferRequest) error {
+	if _, ok := modeDir(settings, req.Mode); !ok {
+		return fmt.Errorf("%w: %s", ErrModeDisabled, req.Mode)
+	}
+	return nil
+}
+
+// ListModes 返回各传输模式是否启用及其限制，供客户端选择可用的模式
+func ListModes(settings *models.TransferSettings) *models.TransferModesResponse {
+	response := &models.TransferModesResponse{
+		Modes:   make([]models.TransferModeInfo, 0, 3),
+		Enabled: []string{},
+	}
+	for _, mode := range []string{models.ModeHugepages, models.ModeTmpfs, models.ModeFilesystem} {
+		config, _ := modeConfig(settings, mode)
+		_, enabled := modeDir(settings, mode)
+		response.Modes = append(response.Modes, models.TransferModeInfo{
+			Mode:          mode,
+			Enabled:       enabled,
+			MaxConcurrent: config.MaxConcurrent,
+			MaxFileSize:   maxFileSize(settings, mode),
+		})
+		if enabled {
+			response.Enabled = append(response.Enabled, mode)
+		}
+	}
+	if len(response.Enabled) > 0 {
+		response.Enabled = append(response.Enabled, models.ModeAuto)
+	}
+	return response
+}
+
+// ResolveAutoMode 为 auto 模式的请求选择实际的传输模式，并将 req.Mode 替换为选择结果
+// put 按请求中的文件大小选择：小文件使用 filesystem，中等文件使用 tmpfs，大文件使用 hugepages，
+// 所选模式未启用或剩余空间不足时依次降级；get 和 verify 使用文件所在模式的目录
+// 返回选择依据，请求不是 auto 模式时返回空字符串
+func ResolveAutoMode(settings *models.TransferSettings, req *models.TransferRequest) (string, error) {
+	if req.Mode != models.ModeAuto {
+		return "", nil
+	}
+
+	if req.Direction == models.DirectionGet || req.IsVerify() {
+		return resolveExistingMode(settings, req)
+	}
+
+	if req.Size <= 0 {
+		return "", fmt.Errorf("auto 模式的 put 请求需要提供文件大小 (size)")
+	}
+
+	thresholds := settings.AutoMode
+	if thresholds == (models.AutoModeSettings{}) {
+		thresholds = models.DefaultAutoModeSettings()
+	}
+	var candidates []string
+	switch {
+	case req.Size >= thresholds.HugepagesMinSize:
+		candidates = []string{models.ModeHugepages, models.ModeTmpfs, models.ModeFilesystem}
+	case req.Size >= thresholds.TmpfsMinSize:
+		candidates = []string{models.ModeTmpfs, models.ModeFilesystem}
+	default:
+		candidates = []string{models.ModeFilesystem}
+	}
+
+	var skipped []string
+	for _, mode := range candidates {
+		if reason := modeUnavailable(settings, mode, req.Size); reason != "" {
+			skipped = append(skipped, fmt.Sprintf("%s %s", mode, reason))
+			continue
+		}
+
+		req.Mode = mode
+		decision := fmt.Sprintf("auto: 文件大小 %d 字节，选择 %s 模式", req.Size, mode)
+		if len(skipped) > 0 {
+			decision += "（" + strings.Join(skipped, "，") + "）"
+		}
+		return decision, nil
+	}
+
+	return "", fmt.Errorf("auto 模式没有可用的传输模式: %s", strings.Join(skipped, "，"))
+}
+
+// resolveExistingMode 在各模式目录中查找已有文件，使用文件所在的模式
+func resolveExistingMode(settings *models.TransferSettings, req *models.TransferRequest) (string, error) {
+	for _, mode := range []string{models.ModeHugepages, models.ModeTmpfs, models.ModeFilesystem} {
+		dir, ok := modeDir(settings, mode)
+		if !ok {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, filepath.Base(req.Filename)))
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		req.Mode = mode
+		return fmt.Sprintf("auto: 文件位于 %s 模式目录，大小 %d 字节", mode, info.Size()), nil
+	}
+	return "", fmt.Errorf("auto 模式未在任何传输模式目录中找到文件: %s", req.Filename)
+}
+
+// modeUnavailable 检查传输模式是否启用、是否超过文件大小上限以及剩余空间是否足够，可用时返回空字符串
+func modeUnavailable(settings *models.TransferSettings, mode string, size int64) string {
+	dir, ok := modeDir(settings, mode)
+	if !ok {
+		return "未启用"
+	}
+	if limit := maxFileSize(settings, mode); limit > 0 && size > limit {
+		return fmt.Sprintf("超过文件大小上限 (%d 字节)", limit)
+	}
+	if _, fstype := memoryModeOnNetwork(settings, mode); fstype != "" && networkFSPolicy(settings) != models.NetworkFSWarn {
+		return fmt.Sprintf("目录位于网络文件系统 (%s)", fstype)
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		// 文件系统模式的目录在传输前创建，无法获取空间信息时不降级
+		if mode == models.ModeFilesystem {
+			return ""
+		}
+		return "不可用"
+	}
+	if free := int64(stat.Bavail) * int64(stat.Bsize); free < size {
+		return fmt.Sprintf("剩余空间不足 (%d 字节)", free)
+	}
+	return ""
+}
+
+// DiskCheck 返回检查已启用传输模式目录所在文件系统可用的函数，用于周期性健康探测
+// 目录无法获取空间信息或剩余空间为 0 时返回错误；文件系统模式的目录在传输前创建，不存在时跳过
+func DiskCheck(settings *models.TransferSettings) func() error {
+	return func() error {
+		var problems []string
+		for _, mode := range []string{models.ModeHugepages, models.ModeTmpfs, models.ModeFilesystem} {
+			dir, ok := modeDir(settings, mode)
+			if !ok {
+				continue
+			}
+
+			var stat syscall.Statfs_t
+			if err := syscall.Statfs(dir, &stat); err != nil {
+				if mode == models.ModeFilesystem && os.IsNotExist(err) {
+					continue
+				}
+				problems = append(problems, fmt.Sprintf("%s 模式目录 %s 不可用: %v", mode, dir, err))
+				continue
+			}
+			if stat.Bavail == 0 {
+				problems = append(problems, fmt.Sprintf("%s 模式目录 %s 剩余空间为 0", mode, dir))
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s", strings.Join(problems, "；"))
+		}
+		return nil
+	}
+}
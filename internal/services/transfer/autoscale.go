@@ -0,0 +1,300 @@
+package transfer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/utils"
+	"rdma-burst/pkg/metrics"
+)
+
+// 并发自动调整的默认值
+const (
+	defaultAutoscaleInterval = 10 * time.Second
+	defaultHighUtilization   = 0.9
+	defaultLowUtilization    = 0.5
+)
+
+// 链路利用率的来源
+const (
+	UtilizationSourceCounters   = "counters"   // RDMA 端口流量计数，包含本服务以外的流量
+	UtilizationSourceThroughput = "throughput" // 执行中任务的传输速率之和，端口没有流量计数时使用
+)
+
+// AutoscaleStatus 并发自动调整状态
+type AutoscaleStatus struct {
+	Limit        int        `json:"limit"` // 生效的并发上限
+	Min          int        `json:"min"`
+	Max          int        `json:"max"`
+	Active       int        `json:"active"`        // 最近一次调整时的活跃任务数
+	Rejected     int64      `json:"rejected"`      // 最近一个调整间隔内因并发上限被拒绝的请求数
+	Throughput   float64    `json:"throughput"`    // 执行中任务的聚合传输速率（MB/s）
+	Utilization  float64    `json:"utilization"`   // 链路利用率（0-1）
+	Source       string     `json:"source"`        // 链路利用率的来源：counters 或 throughput
+	CapacityGbps float64    `json:"capacity_gbps"` // 活跃端口的总速率
+	Reason       string     `json:"reason"`        // 最近一次调整或保持上限的原因
+	AdjustedAt   *time.Time `json:"adjusted_at,omitempty"`
+	CheckedAt    time.Time  `json:"checked_at"`
+}
+
+// autoscaler 按聚合吞吐量和链路利用率调整生效的并发上限（TransferService.maxConcurrent）
+type autoscaler struct {
+	mu       sync.Mutex
+	settings models.AutoscaleSettings // 补全默认值后的设置
+	status   *AutoscaleStatus         // 未启用时为 nil
+	sample   map[int]utils.RDMAPortCounters
+	sampleAt time.Time
+	rejected atomic.Int64 // 当前调整间隔内因并发上限被拒绝的请求数
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// StartAutoscaler 启用并发自动调整时，按配置的间隔根据链路利用率调整生效的并发上限：
+// 链路饱和时降低上限，链路空闲且有请求在等待并发名额时提高上限，上限保持在 [min_concurrent, max_concurrent] 内
+func (ts *TransferService) StartAutoscaler(ctx context.Context) {
+	if ts.serverConfig == nil || !ts.serverConfig.Autoscale.Enabled {
+		return
+	}
+	settings := autoscaleDefaults(ts.serverConfig.Autoscale, ts.serverConfig.MaxConcurrentTransfers)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	ts.autoscale.mu.Lock()
+	if ts.autoscale.cancel != nil {
+		ts.autoscale.mu.Unlock()
+		cancel()
+		return
+	}
+	ts.mu.Lock()
+	ts.maxConcurrent = min(max(ts.maxConcurrent, settings.MinConcurrent), settings.MaxConcurrent)
+	limit := ts.maxConcurrent
+	ts.mu.Unlock()
+	ts.autoscale.settings = settings
+	ts.autoscale.status = &AutoscaleStatus{
+		Limit:     limit,
+		Min:       settings.MinConcurrent,
+		Max:       settings.MaxConcurrent,
+		Reason:    "等待首次调整",
+		CheckedAt: time.Now(),
+	}
+	// 记录初始的端口流量计数，首次调整即可按计数差值计算链路利用率
+	ts.autoscale.sample = nil
+	ts.linkUtilization(time.Now(), 0)
+	ts.autoscale.cancel = cancel
+	ts.autoscale.done = done
+	ts.autoscale.mu.Unlock()
+	metrics.SetConcurrencyLimit(limit)
+
+	ts.logger.Info("启用并发自动调整",
+		zap.Int("limit", limit),
+		zap.Int("min", settings.MinConcurrent),
+		zap.Int("max", settings.MaxConcurrent),
+		zap.Duration("interval", settings.Interval),
+	)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(settings.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ts.adjustConcurrency()
+			}
+		}
+	}()
+}
+
+// StopAutoscaler 停止并发自动调整，生效的并发上限保持最后一次调整的结果
+func (ts *TransferService) StopAutoscaler() {
+	ts.autoscale.mu.Lock()
+	cancel, done := ts.autoscale.cancel, ts.autoscale.done
+	ts.autoscale.cancel = nil
+	ts.autoscale.done = nil
+	ts.autoscale.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// AutoscaleStatus 获取并发自动调整状态，未启用时返回 nil
+func (ts *TransferService) AutoscaleStatus() *AutoscaleStatus {
+	ts.autoscale.mu.Lock()
+	defer ts.autoscale.mu.Unlock()
+	if ts.autoscale.status == nil {
+		return nil
+	}
+	status := *ts.autoscale.status
+	return &status
+}
+
+// ConcurrencyLimit 获取生效的并发上限
+func (ts *TransferService) ConcurrencyLimit() int {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.maxConcurrent
+}
+
+// autoscaleDefaults 补全并发自动调整设置的默认值，上限未配置时使用 max_concurrent_transfers
+func autoscaleDefaults(settings models.AutoscaleSettings, maxConcurrent int) models.AutoscaleSettings {
+	if settings.MinConcurrent <= 0 {
+		settings.MinConcurrent = 1
+	}
+	if settings.MaxConcurrent <= 0 {
+		settings.MaxConcurrent = max(maxConcurrent, settings.MinConcurrent)
+	}
+	if settings.Interval <= 0 {
+		settings.Interval = defaultAutoscaleInterval
+	}
+	if settings.HighUtilization <= 0 {
+		settings.HighUtilization = defaultHighUtilization
+	}
+	if settings.LowUtilization <= 0 || settings.LowUtilization >= settings.HighUtilization {
+		settings.LowUtilization = min(defaultLowUtilization, settings.HighUtilization/2)
+	}
+	return settings
+}
+
+// adjustConcurrency 计算链路利用率并调整生效的并发上限
+func (ts *TransferService) adjustConcurrency() {
+	now := time.Now()
+
+	ts.mu.RLock()
+	limit := ts.maxConcurrent
+	// 准备就绪和执行中的会话、正在准备和拉取源文件的任务占用并发名额
+	live := ts.liveTasks("", false)
+	ts.mu.RUnlock()
+	active := len(live)
+
+	// 执行中任务的聚合传输速率
+	throughput := 0.0
+	for id := range live {
+		if progress, ok := ts.progress.load(id); ok && progress.Status == models.StatusInProgress {
+			throughput += progress.TransferRate
+		}
+	}
+
+	ts.autoscale.mu.Lock()
+	defer ts.autoscale.mu.Unlock()
+	if ts.autoscale.status == nil {
+		return
+	}
+	settings := ts.autoscale.settings
+	rejected := ts.autoscale.rejected.Swap(0)
+
+	status := ts.autoscale.status
+	status.Active = active
+	status.Rejected = rejected
+	status.Throughput = throughput
+	status.CheckedAt = now
+
+	utilization, source, capacity, ok := ts.linkUtilization(now, throughput)
+	status.CapacityGbps = capacity
+	if !ok {
+		status.Limit = limit
+		status.Utilization = 0
+		status.Source = ""
+		status.Reason = "无法获取链路速率，保持并发上限"
+		return
+	}
+	status.Utilization = utilization
+	status.Source = source
+	metrics.SetLinkUtilization(utilization)
+
+	next := limit
+	switch {
+	case utilization >= settings.HighUtilization && limit > settings.MinConcurrent:
+		next = limit - 1
+		status.Reason = "链路饱和，降低并发上限"
+	case utilization < settings.LowUtilization && limit < settings.MaxConcurrent && (active >= limit || rejected > 0):
+		next = limit + 1
+		status.Reason = "链路空闲且有请求等待并发名额，提高并发上限"
+	default:
+		status.Limit = limit
+		status.Reason = "保持并发上限"
+		return
+	}
+
+	ts.mu.Lock()
+	ts.maxConcurrent = next
+	ts.mu.Unlock()
+	status.Limit = next
+	status.AdjustedAt = &now
+	metrics.SetConcurrencyLimit(next)
+
+	ts.logger.Info("调整并发上限",
+		zap.Int("from", limit),
+		zap.Int("to", next),
+		zap.Float64("utilization", utilization),
+		zap.String("source", source),
+		zap.Float64("throughput_mbps", throughput),
+		zap.Int("active", active),
+		zap.Int64("rejected", rejected),
+	)
+}
+
+// linkUtilization 根据两次端口流量计数的差值计算链路利用率（收发取较大者），端口没有流量计数时按聚合传输速率估算
+// 返回利用率、来源和活跃端口的总速率，无法获取链路速率时返回 false，调用方需持有 autoscale.mu
+func (ts *TransferService) linkUtilization(now time.Time, throughput float64) (float64, string, float64, bool) {
+	ports, err := utils.GetRDMAPortCounters(ts.serverConfig.Device)
+	if err != nil {
+		ts.autoscale.sample = nil
+		return 0, "", 0, false
+	}
+
+	capacity := 0.0
+	counted := true
+	for _, port := range ports {
+		if port.Active {
+			capacity += port.RateGbps
+			counted = counted && port.Counted
+		}
+	}
+	if capacity <= 0 {
+		ts.autoscale.sample = nil
+		return 0, "", 0, false
+	}
+	capacityBytes := capacity * 1e9 / 8
+
+	previous, previousAt := ts.autoscale.sample, ts.autoscale.sampleAt
+	ts.autoscale.sample = make(map[int]utils.RDMAPortCounters, len(ports))
+	for _, port := range ports {
+		ts.autoscale.sample[port.Port] = port
+	}
+	ts.autoscale.sampleAt = now
+
+	if counted && previous != nil {
+		var xmit, rcv uint64
+		valid := true
+		for _, port := range ports {
+			if !port.Active {
+				continue
+			}
+			last, ok := previous[port.Port]
+			// 计数回绕或端口重置时跳过本次计数，改用传输速率估算
+			if !ok || !last.Counted || port.XmitBytes < last.XmitBytes || port.RcvBytes < last.RcvBytes {
+				valid = false
+				break
+			}
+			xmit += port.XmitBytes - last.XmitBytes
+			rcv += port.RcvBytes - last.RcvBytes
+		}
+		if elapsed := now.Sub(previousAt).Seconds(); valid && elapsed > 0 {
+			return float64(max(xmit, rcv)) / elapsed / capacityBytes, UtilizationSourceCounters, capacity, true
+		}
+	}
+
+	return throughput * 1024 * 1024 / capacityBytes, UtilizationSourceThroughput, capacity, true
+}
@@ -0,0 +1,35 @@
+package transfer
+
+import (
+	"context"
+
+	"rdma-burst/internal/models"
+)
+
+// Backend 传输 API 使用的传输后端，服务端由 TransferService 实现，测试中可以替换为不依赖 RDMA 的实现
+type Backend interface {
+	// Prepare 准备传输并返回任务
+	Prepare(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error)
+	// StartVerify 启动只校验任务
+	StartVerify(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error)
+	// GetTransferStatus 获取任务状态和进度
+	GetTransferStatus(taskID string) (*models.ProgressResponse, error)
+	// ListTransfers 分页列出包含所有指定标签的任务
+	ListTransfers(page, size int, labels map[string]string) *models.TaskListResponse
+	// CancelTransfer 取消任务
+	CancelTransfer(ctx context.Context, taskID string) error
+	// GetActiveTransfers 获取活跃任务数
+	GetActiveTransfers() int
+	// Heartbeat 记录客户端心跳
+	Heartbeat(ctx context.Context, id string, req *models.HeartbeatRequest) (*models.HeartbeatResponse, error)
+	// ReportProgress 记录客户端上报的传输进度
+	ReportProgress(ctx context.Context, id string, report *models.ProgressReport) (*models.ProgressResponse, error)
+	// ResumeTransfer 从检查点续传失败的任务
+	ResumeTransfer(ctx context.Context, taskID string, req *models.ResumeRequest) (*models.TransferResponse, error)
+	// RetryTransfer 按失败或被取消的任务的原始请求重新创建任务
+	RetryTransfer(ctx context.Context, taskID string, serverConfig *models.TransferSettings) (*models.TransferResponse, error)
+	// EstimatePlan 按历史吞吐量估算传输计划，不创建任务
+	EstimatePlan(ctx context.Context, plan *models.TransferPlan, serverConfig *models.TransferSettings) *models.PlanEstimate
+}
+
+var _ Backend = (*TransferService)(nil)
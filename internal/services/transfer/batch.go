@@ -0,0 +1,115 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+// batchLabel 批量传输的任务带有该标签，值为批次ID，查询批次时按标签列出任务
+const batchLabel = "batch"
+
+// batchListSize 查询批次时每次列出的任务数
+const batchListSize = 100
+
+// ErrBatchNotFound 批量传输不存在或没有创建任何任务
+var ErrBatchNotFound = errors.New("批量传输不存在")
+
+// BatchFuncs 批量传输创建和列出任务的方式，与对应的传输接口相同
+type BatchFuncs struct {
+	Create func(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, error)
+	List   func(ctx context.Context, page, size int, labels map[string]string) (*models.TaskListResponse, error)
+}
+
+// SubmitBatch 依次创建批量传输中的任务，所有任务带有同一个批次ID标签，按单个任务的规则排队和执行
+// 单个传输创建失败时记录在对应的项中，不影响其余传输；所有传输均创建失败时返回第一个错误
+func SubmitBatch(ctx context.Context, funcs BatchFuncs, req *models.BatchTransferRequest) (*models.BatchTransfer, error) {
+	batch := &models.BatchTransfer{
+		ID:    fmt.Sprintf("batch_%d", time.Now().UnixNano()),
+		Items: make([]models.CampaignItem, len(req.Items)),
+	}
+
+	var firstErr error
+	for i := range req.Items {
+		item := req.Items[i]
+		labels := make(map[string]string, len(item.Labels)+1)
+		for k, v := range item.Labels {
+			labels[k] = v
+		}
+		labels[batchLabel] = batch.ID
+		item.Labels = labels
+
+		batch.Items[i] = models.CampaignItem{
+			Index:      i,
+			Filename:   item.Filename,
+			Direction:  item.Direction,
+			TotalBytes: item.Size,
+		}
+		resp, err := funcs.Create(withReservedLabels(ctx), &item)
+		if err != nil {
+			batch.Items[i].Status = models.StatusFailed
+			batch.Items[i].Error = err.Error()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("第 %d 个传输: %w", i, err)
+			}
+			continue
+		}
+		batch.Items[i].TaskID = resp.ID
+		batch.Items[i].Status = resp.Status
+		if resp.Size > 0 {
+			batch.Items[i].TotalBytes = resp.Size
+		}
+	}
+
+	batch.Progress = campaignProgress(batch.Items)
+	if batch.Progress.Failed == len(batch.Items) {
+		return nil, firstErr
+	}
+	batch.Status = batchStatus(batch.Progress)
+	return batch, nil
+}
+
+// GetBatch 按批次ID标签列出批量传输的任务，汇总状态和进度
+func GetBatch(ctx context.Context, funcs BatchFuncs, id string) (*models.BatchTransfer, error) {
+	labels := map[string]string{batchLabel: id}
+	batch := &models.BatchTransfer{ID: id, Items: []models.CampaignItem{}}
+	for page := 1; ; page++ {
+		list, err := funcs.List(ctx, page, batchListSize, labels)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range list.Tasks {
+			batch.Items = append(batch.Items, models.CampaignItem{
+				Index:            len(batch.Items),
+				Filename:         task.Filename,
+				Direction:        task.Direction,
+				TaskID:           task.ID,
+				Status:           task.Status,
+				BytesTransferred: task.BytesTransferred,
+				TotalBytes:       task.TotalBytes,
+				Error:            task.Error,
+			})
+		}
+		if len(list.Tasks) == 0 || len(batch.Items) >= list.Total {
+			break
+		}
+	}
+	if len(batch.Items) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBatchNotFound, id)
+	}
+
+	batch.Progress = campaignProgress(batch.Items)
+	batch.Status = batchStatus(batch.Progress)
+	return batch, nil
+}
+
+// batchStatus 所有任务结束（或创建失败）后批量传输为 completed
+func batchStatus(p models.CampaignProgress) string {
+	if p.Running > 0 {
+		return models.BatchRunning
+	}
+	return models.BatchCompleted
+}
@@ -0,0 +1,517 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/pkg/client"
+	"rdma-burst/pkg/logger"
+)
+
+// 传输活动参数
+const (
+	campaignCheckInterval = 2 * time.Second // 刷新任务状态和提交下一批传输的间隔
+	maxCampaignHistory    = 50              // 保留的已结束活动数
+)
+
+// campaignLabel 活动提交的任务带有的活动 ID 标签
+const campaignLabel = "campaign"
+
+var (
+	// ErrCampaignNotFound 传输活动不存在
+	ErrCampaignNotFound = errors.New("传输活动不存在")
+	// ErrCampaignEnded 传输活动已结束，不能再暂停、恢复或中止
+	ErrCampaignEnded = errors.New("传输活动已结束")
+	// ErrCampaignNotEnded 传输活动尚未结束，还没有报告
+	ErrCampaignNotEnded = errors.New("传输活动尚未结束")
+)
+
+// CampaignFuncs 传输活动创建、查询和取消任务使用的函数，服务端模式和客户端模式由 API 处理器分别提供
+type CampaignFuncs struct {
+	Create func(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, error)
+	Status func(ctx context.Context, taskID string) (*models.ProgressResponse, error)
+	Cancel func(ctx context.Context, taskID string) error
+}
+
+// campaignRun 执行中的传输活动
+type campaignRun struct {
+	campaign *models.Campaign
+	items    []models.TransferRequest // 原始请求，依赖中的 @序号 在提交时替换为任务ID
+	next     int                      // 下一个提交的传输
+	aborted  bool                     // 已取消已提交的任务
+	report   *models.CampaignReport
+}
+
+// CampaignManager 传输活动：按批次提交一组传输，汇总进度，支持暂停、恢复和中止，结束后生成报告
+type CampaignManager struct {
+	funcs  CampaignFuncs
+	mu     sync.RWMutex
+	runs   map[string]*campaignRun
+	order  []string // 按创建顺序排列的活动 ID
+	logger *zap.Logger
+}
+
+// NewCampaignManager 创建传输活动管理器
+func NewCampaignManager(funcs CampaignFuncs) *CampaignManager {
+	return &CampaignManager{
+		funcs:  funcs,
+		runs:   make(map[string]*campaignRun),
+		logger: logger.GetLogger().Named(logger.ComponentTransfer).Named("campaign"),
+	}
+}
+
+// ValidateCampaignDependencies 检查活动中以 @序号 引用的依赖，只能引用之前的传输
+func ValidateCampaignDependencies(items []models.TransferRequest) error {
+	for i, item := range items {
+		for _, dep := range item.DependsOn {
+			if _, err := campaignReference(dep, i); err != nil {
+				return fmt.Errorf("第 %d 个传输: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// campaignReference 解析依赖中以 @序号 引用的传输，不是引用时返回 -1
+func campaignReference(dep string, index int) (int, error) {
+	ref, ok := strings.CutPrefix(dep, "@")
+	if !ok {
+		return -1, nil
+	}
+	i, err := strconv.Atoi(ref)
+	if err != nil || i < 0 || i >= index {
+		return 0, fmt.Errorf("依赖 %s 必须引用之前的传输", dep)
+	}
+	return i, nil
+}
+
+// Submit 创建传输活动并在后台按批次提交传输，返回活动
+func (m *CampaignManager) Submit(ctx context.Context, req *models.CampaignRequest) (*models.Campaign, error) {
+	if err := ValidateCampaignDependencies(req.Items); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &models.Campaign{
+		ID:        fmt.Sprintf("campaign_%d", now.UnixNano()),
+		Name:      req.Name,
+		Status:    models.CampaignRunning,
+		BatchSize: req.BatchSize,
+		Items:     make([]models.CampaignItem, len(req.Items)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		c.Owner = principal.Name
+	}
+	for i, item := range req.Items {
+		c.Items[i] = models.CampaignItem{
+			Index:      i,
+			Filename:   item.Filename,
+			Direction:  item.Direction,
+			Status:     models.CampaignItemPending,
+			TotalBytes: item.Size,
+		}
+	}
+	c.Progress = campaignProgress(c.Items)
+
+	run := &campaignRun{campaign: c, items: req.Items}
+	m.mu.Lock()
+	m.runs[c.ID] = run
+	m.order = append(m.order, c.ID)
+	m.pruneLocked()
+	snapshot := snapshotCampaign(c)
+	m.mu.Unlock()
+
+	m.logger.Info("创建传输活动",
+		zap.String("campaign_id", c.ID),
+		zap.String("name", c.Name),
+		zap.Int("items", len(req.Items)),
+		zap.Int("batch_size", req.BatchSize),
+	)
+
+	// 保留调用方身份，但不随请求结束而取消
+	go m.execute(context.WithoutCancel(ctx), run)
+	return snapshot, nil
+}
+
+// Get 获取传输活动
+func (m *CampaignManager) Get(id string) (*models.Campaign, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, id)
+	}
+	return snapshotCampaign(run.campaign), nil
+}
+
+// List 列出传输活动，最新的在前
+func (m *CampaignManager) List() []*models.Campaign {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	campaigns := make([]*models.Campaign, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		campaigns = append(campaigns, snapshotCampaign(m.runs[m.order[i]].campaign))
+	}
+	return campaigns
+}
+
+// Report 获取已结束的传输活动的报告
+func (m *CampaignManager) Report(id string) (*models.CampaignReport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, id)
+	}
+	if run.report == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotEnded, run.campaign.Status)
+	}
+	report := *run.report
+	report.Items = append([]models.CampaignItem(nil), run.report.Items...)
+	return &report, nil
+}
+
+// Pause 暂停传输活动：不再提交新的传输，已提交的任务继续执行
+func (m *CampaignManager) Pause(ctx context.Context, id string) (*models.Campaign, error) {
+	return m.transition(ctx, id, models.CampaignPaused)
+}
+
+// Resume 恢复暂停的传输活动
+func (m *CampaignManager) Resume(ctx context.Context, id string) (*models.Campaign, error) {
+	return m.transition(ctx, id, models.CampaignRunning)
+}
+
+// Abort 中止传输活动：取消已提交未结束的任务，未提交的传输跳过
+func (m *CampaignManager) Abort(ctx context.Context, id string) (*models.Campaign, error) {
+	return m.transition(ctx, id, models.CampaignAborted)
+}
+
+// transition 切换活动状态，已结束或已中止的活动不能再切换
+func (m *CampaignManager) transition(ctx context.Context, id, status string) (*models.Campaign, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, id)
+	}
+	c := run.campaign
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(c.Owner) {
+		return nil, fmt.Errorf("%w: %s", ErrNotOwner, id)
+	}
+	if c.EndedAt != nil || c.Status == models.CampaignAborted {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignEnded, c.Status)
+	}
+	if c.Status != status {
+		m.logger.Info("传输活动状态变化",
+			zap.String("campaign_id", id),
+			zap.String("from", c.Status),
+			zap.String("to", status),
+		)
+		c.Status = status
+		c.UpdatedAt = time.Now()
+	}
+	return snapshotCampaign(c), nil
+}
+
+// execute 定期刷新已提交任务的状态，运行中的活动按批次提交传输，中止的活动取消未结束的任务，所有传输结束后生成报告
+func (m *CampaignManager) execute(ctx context.Context, run *campaignRun) {
+	ticker := time.NewTicker(campaignCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		m.refresh(ctx, run)
+		if m.advance(ctx, run) {
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// refresh 查询已提交未结束的任务的状态
+func (m *CampaignManager) refresh(ctx context.Context, run *campaignRun) {
+	m.mu.RLock()
+	var taskIDs []string
+	for _, item := range run.campaign.Items {
+		if item.TaskID != "" && !campaignItemEnded(item.Status) {
+			taskIDs = append(taskIDs, item.TaskID)
+		}
+	}
+	m.mu.RUnlock()
+
+	statuses := make(map[string]*models.ProgressResponse, len(taskIDs))
+	for _, id := range taskIDs {
+		status, err := m.funcs.Status(ctx, id)
+		if errors.Is(err, ErrTaskNotFound) || client.IsNotFound(err) {
+			// 任务已不存在（例如服务端重启后没有恢复），按失败计入
+			statuses[id] = &models.ProgressResponse{ID: id, Status: models.StatusFailed, Error: err.Error()}
+			continue
+		}
+		if err != nil {
+			m.logger.Warn("查询传输活动的任务状态失败", zap.String("campaign_id", run.campaign.ID), zap.String("task_id", id), zap.Error(err))
+			continue
+		}
+		statuses[id] = status
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range run.campaign.Items {
+		item := &run.campaign.Items[i]
+		status, ok := statuses[item.TaskID]
+		if !ok {
+			continue
+		}
+		item.Status = status.Status
+		item.BytesTransferred = status.BytesTransferred
+		if status.TotalBytes > 0 {
+			item.TotalBytes = status.TotalBytes
+		}
+		item.Error = status.Error
+	}
+	run.campaign.Progress = campaignProgress(run.campaign.Items)
+	run.campaign.UpdatedAt = time.Now()
+}
+
+// advance 按活动状态提交下一批传输或取消未结束的任务，所有传输结束时生成报告并返回 true
+func (m *CampaignManager) advance(ctx context.Context, run *campaignRun) bool {
+	m.mu.Lock()
+	c := run.campaign
+	switch c.Status {
+	case models.CampaignAborted:
+		var cancel []string
+		if !run.aborted {
+			run.aborted = true
+			for i := range c.Items {
+				item := &c.Items[i]
+				switch {
+				case item.Status == models.CampaignItemPending:
+					item.Status = models.CampaignItemSkipped
+				case item.TaskID != "" && !campaignItemEnded(item.Status):
+					cancel = append(cancel, item.TaskID)
+				}
+			}
+			run.next = len(run.items)
+		}
+		m.mu.Unlock()
+		for _, id := range cancel {
+			if err := m.funcs.Cancel(ctx, id); err != nil {
+				m.logger.Warn("中止传输活动时取消任务失败", zap.String("campaign_id", c.ID), zap.String("task_id", id), zap.Error(err))
+			}
+		}
+	case models.CampaignRunning:
+		m.mu.Unlock()
+		m.submitBatch(ctx, run)
+	default:
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if run.next < len(run.items) {
+		return false
+	}
+	for _, item := range c.Items {
+		if !campaignItemEnded(item.Status) {
+			return false
+		}
+	}
+
+	now := time.Now()
+	if c.Status != models.CampaignAborted {
+		c.Status = models.CampaignCompleted
+	}
+	c.Progress = campaignProgress(c.Items)
+	c.EndedAt = &now
+	c.UpdatedAt = now
+	run.report = buildCampaignReport(c)
+	m.logger.Info("传输活动结束",
+		zap.String("campaign_id", c.ID),
+		zap.String("status", c.Status),
+		zap.Int("completed", c.Progress.Completed),
+		zap.Int("failed", c.Progress.Failed),
+		zap.Int("cancelled", c.Progress.Cancelled),
+		zap.Int("skipped", c.Progress.Skipped),
+	)
+	return true
+}
+
+// submitBatch 在未结束的任务数低于批次大小时按顺序提交传输，暂停或中止后停止提交
+func (m *CampaignManager) submitBatch(ctx context.Context, run *campaignRun) {
+	for {
+		m.mu.Lock()
+		c := run.campaign
+		if c.Status != models.CampaignRunning || run.next >= len(run.items) {
+			m.mu.Unlock()
+			return
+		}
+		if c.BatchSize > 0 {
+			inFlight := 0
+			for _, item := range c.Items {
+				if item.TaskID != "" && !campaignItemEnded(item.Status) {
+					inFlight++
+				}
+			}
+			if inFlight >= c.BatchSize {
+				m.mu.Unlock()
+				return
+			}
+		}
+		index := run.next
+		run.next++
+		req, err := run.resolve(index)
+		m.mu.Unlock()
+
+		var resp *models.TransferResponse
+		if err == nil {
+			resp, err = m.funcs.Create(withReservedLabels(ctx), req)
+		}
+
+		m.mu.Lock()
+		item := &c.Items[index]
+		if err != nil {
+			item.Status = models.StatusFailed
+			item.Error = err.Error()
+			m.logger.Warn("传输活动提交传输失败", zap.String("campaign_id", c.ID), zap.Int("index", index), zap.Error(err))
+		} else {
+			item.TaskID = resp.ID
+			item.Status = resp.Status
+		}
+		c.Progress = campaignProgress(c.Items)
+		c.UpdatedAt = time.Now()
+		m.mu.Unlock()
+	}
+}
+
+// resolve 复制第 index 个传输的请求，把依赖中的 @序号 替换为已提交的任务ID，调用方需持有锁
+// 引用的传输创建失败时返回错误，该传输不再提交
+func (r *campaignRun) resolve(index int) (*models.TransferRequest, error) {
+	req := r.items[index]
+	req.Labels = make(map[string]string, len(r.items[index].Labels)+1)
+	for key, value := range r.items[index].Labels {
+		req.Labels[key] = value
+	}
+	req.Labels[campaignLabel] = r.campaign.ID
+	if len(req.DependsOn) == 0 {
+		return &req, nil
+	}
+
+	req.DependsOn = make([]string, 0, len(r.items[index].DependsOn))
+	for _, dep := range r.items[index].DependsOn {
+		i, err := campaignReference(dep, index)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			req.DependsOn = append(req.DependsOn, dep)
+			continue
+		}
+		if r.campaign.Items[i].TaskID == "" {
+			return nil, fmt.Errorf("%w: 第 %d 个传输创建失败", ErrDependencyFailed, i)
+		}
+		req.DependsOn = append(req.DependsOn, r.campaign.Items[i].TaskID)
+	}
+	return &req, nil
+}
+
+// campaignItemEnded 检查活动中的传输是否已结束（任务结束、创建失败或跳过）
+func campaignItemEnded(status string) bool {
+	switch status {
+	case models.StatusCompleted, models.StatusFailed, models.StatusCancelled, models.CampaignItemSkipped:
+		return true
+	}
+	return false
+}
+
+// campaignProgress 汇总活动中各传输的状态和字节数
+func campaignProgress(items []models.CampaignItem) models.CampaignProgress {
+	p := models.CampaignProgress{Total: len(items)}
+	for _, item := range items {
+		switch item.Status {
+		case models.CampaignItemPending:
+			p.Pending++
+		case models.CampaignItemSkipped:
+			p.Skipped++
+		case models.StatusCompleted:
+			p.Completed++
+		case models.StatusFailed:
+			p.Failed++
+		case models.StatusCancelled:
+			p.Cancelled++
+		default:
+			p.Running++
+		}
+		p.BytesTransferred += item.BytesTransferred
+		p.TotalBytes += item.TotalBytes
+	}
+	if p.TotalBytes > 0 {
+		p.Progress = float64(p.BytesTransferred) / float64(p.TotalBytes) * 100
+		if p.Progress > 100 {
+			p.Progress = 100
+		}
+	} else if p.Total > 0 {
+		p.Progress = float64(p.Completed+p.Failed+p.Cancelled+p.Skipped) / float64(p.Total) * 100
+	}
+	return p
+}
+
+// buildCampaignReport 按活动的最终状态生成报告
+func buildCampaignReport(c *models.Campaign) *models.CampaignReport {
+	report := &models.CampaignReport{
+		CampaignID: c.ID,
+		Name:       c.Name,
+		Status:     c.Status,
+		Owner:      c.Owner,
+		Progress:   c.Progress,
+		Items:      append([]models.CampaignItem(nil), c.Items...),
+		CreatedAt:  c.CreatedAt,
+		EndedAt:    *c.EndedAt,
+	}
+	duration := c.EndedAt.Sub(c.CreatedAt)
+	report.DurationSeconds = duration.Seconds()
+	if duration > 0 && c.Progress.BytesTransferred > 0 {
+		report.ThroughputMBps = float64(c.Progress.BytesTransferred) / duration.Seconds() / (1024 * 1024)
+	}
+	return report
+}
+
+// snapshotCampaign 复制活动状态，调用方需持有锁
+func snapshotCampaign(c *models.Campaign) *models.Campaign {
+	snapshot := *c
+	snapshot.Items = append([]models.CampaignItem(nil), c.Items...)
+	return &snapshot
+}
+
+// pruneLocked 只保留最近结束的 maxCampaignHistory 个活动，调用方需持有锁
+func (m *CampaignManager) pruneLocked() {
+	ended := 0
+	for _, id := range m.order {
+		if m.runs[id].campaign.EndedAt != nil {
+			ended++
+		}
+	}
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if ended > maxCampaignHistory && m.runs[id].campaign.EndedAt != nil {
+			delete(m.runs, id)
+			ended--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
@@ -0,0 +1,176 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/checksum"
+	"rdma-burst/internal/services/manifest"
+)
+
+// SetChecksum 按客户端设置启用 put 源文件的整文件摘要：客户端在拉取或读取源文件时一次计算摘要，服务端收到文件后校验
+func (cts *ClientTransferService) SetChecksum(settings models.ClientSpecificSettings) {
+	if !settings.EnableChecksum {
+		cts.digestAlgorithm = ""
+		return
+	}
+	cts.digestAlgorithm = settings.ChecksumAlgorithm
+	if cts.digestAlgorithm == "" {
+		cts.digestAlgorithm = checksum.DefaultAlgorithm
+	}
+}
+
+// newDigest 启用校验和时按配置的算法创建源文件摘要，未启用时返回 nil
+func (cts *ClientTransferService) newDigest() (hash.Hash, error) {
+	if cts.digestAlgorithm == "" {
+		return nil, nil
+	}
+	digest, err := checksum.New(cts.digestAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChecksum, err)
+	}
+	return digest, nil
+}
+
+// withChecksum 返回附带源文件摘要的请求
+func (cts *ClientTransferService) withChecksum(req *models.TransferRequest, digest hash.Hash) *models.TransferRequest {
+	summed := *req
+	summed.Checksum = &models.FileChecksum{Algorithm: cts.digestAlgorithm, Value: checksum.Sum(digest)}
+	return &summed
+}
+
+// hashSource 读取一次 put 源文件：buildManifest 为 true 时计算分块清单，digest 不为 nil 时同时写入整文件摘要
+func hashSource(ctx context.Context, path string, digest hash.Hash, buildManifest bool) (*manifest.Manifest, error) {
+	if buildManifest {
+		m, err := manifest.BuildTee(ctx, path, manifest.DefaultChunkSize, digest)
+		if err != nil {
+			return nil, fmt.Errorf("生成分块清单失败: %v", err)
+		}
+		return m, nil
+	}
+	if digest == nil {
+		return nil, nil
+	}
+	if err := checksum.Copy(ctx, digest, path); err != nil {
+		return nil, fmt.Errorf("计算源文件摘要失败: %v", err)
+	}
+	return nil, nil
+}
+
+// stageSource put 执行传输前准备源文件：启用校验和时在从对象存储拉取的同时计算摘要，不需要拉取时读取一次本地文件，
+// 启用分块清单时同时计算清单，传输后校验不再读取源文件
+// 返回不再需要拉取的请求（摘要记录在 checksum 中）和分块清单；未启用校验和或请求已附带摘要时原样返回，由 runTransfer 拉取
+func (cts *ClientTransferService) stageSource(ctx context.Context, req *models.TransferRequest, log *zap.Logger) (*models.TransferRequest, *manifest.Manifest, error) {
+	if req.Direction != models.DirectionPut || req.IsVerify() || req.Checksum != nil {
+		return req, nil, nil
+	}
+	digest, err := cts.newDigest()
+	if err != nil || digest == nil {
+		return req, nil, err
+	}
+
+	start := time.Now()
+	var m *manifest.Manifest
+	if sourcePull(req, false) != nil {
+		if err := cts.pullSource(ctx, req, digest, log); err != nil {
+			return nil, nil, err
+		}
+		pulled := *req
+		spec := *req.Staging
+		spec.Pull = nil
+		pulled.Staging = &spec
+		req = &pulled
+	} else if m, err = hashSource(ctx, req.Filename, digest, cts.config != nil && cts.config.ChunkManifest); err != nil {
+		return nil, nil, err
+	}
+
+	req = cts.withChecksum(req, digest)
+	log.Info("已计算源文件摘要",
+		zap.String("algorithm", req.Checksum.Algorithm),
+		zap.String("checksum", req.Checksum.Value),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return req, m, nil
+}
+
+// setChecksum 记录 put 源文件的摘要，上报完成时交给服务端校验
+func (p *clientProgress) setChecksum(sum *models.FileChecksum) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checksum = sum
+}
+
+// checkChecksum 检查请求附带的源文件摘要：只用于 put 传输，算法必须支持且摘要为对应长度的十六进制字符串
+func checkChecksum(req *models.TransferRequest) error {
+	if req.Checksum == nil {
+		return nil
+	}
+	if req.Direction != models.DirectionPut || req.IsVerify() {
+		return fmt.Errorf("%w: 只有 put 传输可以附带源文件摘要", ErrInvalidChecksum)
+	}
+	if err := checksum.Validate(req.Checksum.Algorithm, req.Checksum.Value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChecksum, err)
+	}
+	return nil
+}
+
+// receivedFile put 收到的文件：启用临时后缀且临时文件存在时为临时文件，否则为目标文件
+func (ts *TransferService) receivedFile(task *models.TransferTask) string {
+	if suffix := partialSuffix(ts.serverConfig); suffix != "" {
+		if _, err := os.Lstat(task.TargetPath + suffix); err == nil {
+			return task.TargetPath + suffix
+		}
+	}
+	return task.TargetPath
+}
+
+// verifyReceived 客户端上报 put 完成后，在后台按源文件摘要校验收到的文件，一致时完成任务，不一致时任务失败并删除临时文件，调用方需持有锁
+// 服务端只在此时读取一次收到的文件，客户端的摘要在拉取或读取源文件时已经计算
+func (ts *TransferService) verifyReceived(task *models.TransferTask) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ts.trackStaging(task, cancel)
+	task.Message = "客户端传输完成，正在校验文件摘要"
+	task.UpdatedAt = time.Now()
+	expected := *task.Checksum
+	path := ts.receivedFile(task)
+	log := ts.logger.With(zap.String("task_id", task.ID), zap.String("path", path), zap.String("algorithm", expected.Algorithm))
+
+	go func() {
+		defer cancel()
+		start := time.Now()
+		actual, err := checksum.File(ctx, path, expected.Algorithm)
+		if err == nil && !checksum.Equal(actual, expected.Value) {
+			err = fmt.Errorf("摘要不一致: 期望 %s，实际 %s", expected.Value, actual)
+		}
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		delete(ts.staging, task.ID)
+		if task.Status == models.StatusCancelled {
+			return
+		}
+		if err != nil {
+			task.MarkFailed(fmt.Sprintf("文件摘要校验失败: %v", err))
+			ts.notifyFailed(task)
+			ts.discardPartial(task)
+			ts.recordTask(task, nil)
+			log.Error("文件摘要校验失败", zap.Error(err))
+			return
+		}
+		log.Info("文件摘要校验通过", zap.Duration("duration", time.Since(start)))
+		ts.completeSession(task)
+		// 需要推送到对象存储的任务在推送完成后记录
+		if task.IsFinished() {
+			ts.recordTask(task, nil)
+		}
+	}()
+}
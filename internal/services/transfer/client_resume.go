@@ -0,0 +1,332 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/client"
+	"rdma-burst/pkg/tracing"
+)
+
+// defaultResumeCheckInterval 未配置检查间隔时写入检查点的间隔
+const defaultResumeCheckInterval = 10 * time.Second
+
+// transferCheckpoint 客户端保存的传输检查点，任务失败后据此续传
+type transferCheckpoint struct {
+	TaskID    string                 `json:"task_id"`
+	Request   models.TransferRequest `json:"request"`           // 按服务端选择的模式和文件名调整后的请求
+	Offset    int64                  `json:"offset"`            // 已完整传输的字节偏移，按块大小对齐
+	Partial   string                 `json:"partial,omitempty"` // get 已接收部分所在的本地文件
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// checkpointStore 保存客户端传输检查点的目录，每个任务一个文件
+type checkpointStore struct {
+	dir      string
+	interval time.Duration // 执行传输期间写入检查点的间隔
+}
+
+// path 任务的检查点文件
+func (s *checkpointStore) path(taskID string) string {
+	return filepath.Join(s.dir, filepath.Base(taskID)+".json")
+}
+
+// save 写入检查点文件，先写临时文件再重命名，中断时不会留下不完整的检查点
+func (s *checkpointStore) save(cp *transferCheckpoint) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("创建检查点目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化检查点失败: %v", err)
+	}
+	path := s.path(cp.TaskID)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("写入检查点失败: %v", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// load 读取任务的检查点，没有检查点时返回 ErrNotResumable
+func (s *checkpointStore) load(taskID string) (*transferCheckpoint, error) {
+	data, err := os.ReadFile(s.path(taskID))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: 没有任务 %s 的检查点", ErrNotResumable, taskID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %v", err)
+	}
+	var cp transferCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("解析检查点失败: %v", err)
+	}
+	return &cp, nil
+}
+
+// remove 删除任务的检查点，未启用续传时不做任何操作
+func (s *checkpointStore) remove(taskID string) {
+	if s == nil {
+		return
+	}
+	if err := os.Remove(s.path(taskID)); err != nil && !os.IsNotExist(err) {
+		logger := zap.L()
+		logger.Warn("删除检查点失败", zap.String("task_id", taskID), zap.Error(err))
+	}
+}
+
+// SetResume 按客户端设置启用断点续传：执行传输期间按检查间隔把已完整传输的字节偏移写入检查点文件，
+// 失败的任务可以通过 ResumeTransfer 从检查点续传；未启用或没有配置检查点目录时不写入检查点
+func (cts *ClientTransferService) SetResume(settings models.ClientSpecificSettings) {
+	if !settings.EnableResume || settings.CheckpointDir == "" {
+		cts.checkpoints = nil
+		return
+	}
+	interval := settings.ResumeCheckInterval
+	if interval <= 0 {
+		interval = defaultResumeCheckInterval
+	}
+	cts.checkpoints = &checkpointStore{dir: settings.CheckpointDir, interval: interval}
+}
+
+// startCheckpoints 启用续传时在后台按检查间隔写入传输检查点，返回的函数停止写入并等待后台协程退出
+func (cts *ClientTransferService) startCheckpoints(ctx context.Context, req *models.TransferRequest, taskID string, progress *clientProgress, log *zap.Logger) func() {
+	if cts.checkpoints == nil {
+		return func() {}
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(cts.checkpoints.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cts.saveCheckpoint(req, taskID, progress, log)
+			}
+		}
+	}()
+
+	return func() {
+		stop()
+		<-done
+	}
+}
+
+// saveCheckpoint 写入任务当前的检查点，未启用续传或没有完整传输的分块时不写入
+func (cts *ClientTransferService) saveCheckpoint(req *models.TransferRequest, taskID string, progress *clientProgress, log *zap.Logger) {
+	if cts.checkpoints == nil {
+		return
+	}
+	offset, partial := progress.checkpoint()
+	if offset <= 0 {
+		return
+	}
+	cp := &transferCheckpoint{
+		TaskID:    taskID,
+		Request:   *req,
+		Offset:    offset,
+		Partial:   partial,
+		UpdatedAt: time.Now(),
+	}
+	if err := cts.checkpoints.save(cp); err != nil {
+		log.Warn("写入传输检查点失败", zap.Error(err))
+	}
+}
+
+// discardCheckpoint 删除不能续传的任务的检查点，get 保留在暂存目录中的已接收部分一起删除
+func (cts *ClientTransferService) discardCheckpoint(cp *transferCheckpoint) {
+	cts.checkpoints.remove(cp.TaskID)
+	if cp.Partial != "" && cp.Partial != localTarget(&cp.Request) {
+		os.RemoveAll(filepath.Dir(cp.Partial))
+	}
+}
+
+// ResumeTransfer 按本地检查点续传失败的任务：服务端重新准备后在后台只传输检查点之后的剩余部分
+// 服务端没有保留续传状态或 get 已接收的部分已不在本地时删除检查点
+func (cts *ClientTransferService) ResumeTransfer(ctx context.Context, taskID string) (*models.TransferResponse, error) {
+	if cts.checkpoints == nil {
+		return nil, fmt.Errorf("%w: 客户端没有启用续传", ErrNotResumable)
+	}
+	if err := checkExecutionsDraining(); err != nil {
+		return nil, err
+	}
+	cp, err := cts.checkpoints.load(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if cp.Request.Direction == models.DirectionGet {
+		if info, err := os.Stat(cp.Partial); err != nil || info.Size() < cp.Offset {
+			cts.discardCheckpoint(cp)
+			return nil, fmt.Errorf("%w: 已接收的部分不完整: %s", ErrNotResumable, cp.Partial)
+		}
+	}
+
+	resp, err := cts.api.ResumeTransfer(ctx, taskID, &models.ResumeRequest{Offset: cp.Offset})
+	if err != nil {
+		if client.IsNotResumable(err) {
+			cts.discardCheckpoint(cp)
+		}
+		return nil, err
+	}
+
+	// 设备已满时服务端任务先在调度队列中等待，准备就绪后再执行
+	execCtx, cancel := context.WithCancel(tracing.Detach(ctx))
+	untrack := trackExecution(taskID, cancel)
+	status := resp.Status
+	go func() {
+		defer untrack()
+		if status != models.StatusPrepared && cts.awaitPrepared(execCtx, &cp.Request, taskID, status) != nil {
+			return
+		}
+		cts.executeResumedAsync(execCtx, cp, resp.TargetFilename, resp.ResumeOffset, resp.HeartbeatInterval)
+	}()
+
+	if status == models.StatusPrepared {
+		resp.Status = models.StatusInProgress
+		resp.Message = fmt.Sprintf("客户端正在从 %d 字节处续传，请通过查询接口获取进度", resp.ResumeOffset)
+	}
+	return resp, nil
+}
+
+// executeResumedAsync 执行续传并按完整的任务上报进度和结果
+// 续传的执行不处理机会型任务的抢占：被抢占后服务端的会话过期，任务可以再次续传
+func (cts *ClientTransferService) executeResumedAsync(ctx context.Context, cp *transferCheckpoint, segment string, offset int64, heartbeat time.Duration) {
+	exec := cts.beginExecution(ctx, &cp.Request, cp.TaskID, heartbeat)
+	exec.progress.resumeFrom(offset, cp.Partial)
+	exec.log.Info("从检查点续传", zap.Int64("offset", offset), zap.String("segment", segment))
+	err := cts.executeSegment(exec.ctx, cp, segment, offset, exec.progress, exec.log)
+	cts.endExecution(exec, err)
+}
+
+// executeSegment 传输续传偏移之后的剩余部分，segment 为服务端为剩余部分指定的文件名
+// put: 把本地文件的剩余部分复制为单独的文件发送，服务端收到后拼接，文件只在服务端拼接后才完整，客户端不做分块校验；
+// get: 收到的剩余部分拼接到本地已接收的部分，之后与完整传输相同，按分块清单校验并移动到目标位置
+func (cts *ClientTransferService) executeSegment(ctx context.Context, cp *transferCheckpoint, segment string, offset int64, progress *clientProgress, log *zap.Logger) error {
+	req := cp.Request
+	segReq := req
+	segReq.SourcePath, segReq.DestinationPath = "", ""
+	segReq.Staging = nil
+	if req.Size > offset {
+		segReq.Size = req.Size - offset
+	}
+
+	if req.Direction == models.DirectionPut {
+		segReq.Filename = filepath.Join(getFileDirectory(req.Filename), segment)
+		if err := reflinkRange(req.Filename, segReq.Filename, offset, 0); err != nil {
+			if _, err := copySegment(ctx, req.Filename, segReq.Filename, offset, nil); err != nil {
+				return err
+			}
+		}
+		defer os.Remove(segReq.Filename)
+
+		run, err := cts.runTransfer(ctx, &segReq, segment, nil, progress, log)
+		if err != nil {
+			return err
+		}
+		run.close()
+		return nil
+	}
+
+	segReq.Filename = filepath.Join(filepath.Dir(cp.Partial), segment)
+	segReq.OnConflict = models.ConflictOverwrite
+	defer os.Remove(segReq.Filename)
+	run, err := cts.runTransfer(ctx, &segReq, "", nil, progress, log)
+	if err != nil {
+		return err
+	}
+	received := run.staged
+	if received == "" {
+		received = localTarget(&segReq)
+	}
+	_, err = spliceSegment(cp.Partial, received, offset, -1)
+	run.close()
+	if err != nil {
+		return err
+	}
+	log.Info("已拼接续传收到的剩余部分", zap.String("path", cp.Partial))
+
+	full := &clientRun{req: &req, policy: resolveConflictPolicy(cts.config, &req)}
+	if cp.Partial != localTarget(&req) {
+		full.staged = cp.Partial
+		full.cleanups = append(full.cleanups, func() { os.RemoveAll(filepath.Dir(cp.Partial)) })
+	}
+	defer full.close()
+
+	if err := cts.verifyTransfer(ctx, full, log); err != nil {
+		return err
+	}
+	return cts.finalizeTransfer(ctx, full, log)
+}
+
+// resumeFrom 记录续传开始的偏移和 get 已接收部分所在的文件
+// 续传的 get 失败时收到的剩余部分不保留，检查点停留在续传开始的偏移
+func (p *clientProgress) resumeFrom(offset int64, partial string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.offset = offset
+	if partial != "" {
+		p.partial = partial
+		p.fixed = true
+	}
+}
+
+// setReceiving 记录 rtranfile 块大小和 get 接收数据的本地文件，续传时已记录的文件不再改变
+func (p *clientProgress) setReceiving(chunk int64, partial string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.chunk = chunk
+	if p.partial == "" {
+		p.partial = partial
+	}
+}
+
+// checkpoint 获取已完整传输的字节偏移和 get 已接收部分所在的文件，未启用续传或执行因抢占停止时偏移为 0
+func (p *clientProgress) checkpoint() (int64, string) {
+	if p == nil {
+		return 0, ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.checkpointLocked(), p.partial
+}
+
+// checkpointLocked 续传开始的偏移加上本次已传输的字节数，按块大小向下对齐，调用方需持有锁
+func (p *clientProgress) checkpointLocked() int64 {
+	if !p.resume || p.paused {
+		return 0
+	}
+	done := p.offset
+	if p.monitor != nil && !p.fixed {
+		n := p.monitor.GetProgress().BytesTransferred
+		if p.chunk > 0 {
+			n -= n % p.chunk
+		}
+		done += n
+	}
+	return done
+}
+
+// retains 传输失败时是否保留 path：启用续传、path 为检查点记录的已接收部分且已有完整传输的分块
+func (p *clientProgress) retains(path string) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.partial == path && p.checkpointLocked() > 0
+}
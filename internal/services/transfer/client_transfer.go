@@ -1,198 +1,1034 @@
 package transfer
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"strings"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/fault"
+	"rdma-burst/internal/services/filemeta"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/internal/services/staging"
+	"rdma-burst/internal/services/tuning"
 	"rdma-burst/internal/wrapper"
+	"rdma-burst/pkg/client"
+	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/metrics"
+	"rdma-burst/pkg/tracing"
 )
 
+// progressReportInterval 未设置进度更新间隔时客户端执行传输期间上报进度的最大间隔
+const progressReportInterval = 5 * time.Second
+
 // ClientTransferService 客户端传输服务
 type ClientTransferService struct {
-	serverURL     string // 服务端API地址
-	client        *http.Client
-	rtranfilePath string // rtranfile工具路径
-	config        *models.TransferSettings // 客户端配置
+	api              *client.Client           // 服务端API客户端
+	rtranfilePath    string                   // rtranfile工具路径
+	config           *models.TransferSettings // 客户端配置
+	faults           *fault.Injector          // 故障注入，未启用时为 nil
+	tuner            *tuning.ChunkTuner       // 块大小调优，未启用时为 nil
+	checkpoints      *checkpointStore         // 续传检查点，未启用续传时为 nil
+	progressInterval time.Duration            // 读取传输日志和上报进度的间隔，为 0 时使用默认值
+	digestAlgorithm  string                   // put 源文件整文件摘要的算法，未启用校验和时为空
+	logger           *zap.Logger
 }
 
-// NewClientTransferService 创建新的客户端传输服务
-func NewClientTransferService(serverHost string, serverPort int, config *models.TransferSettings) *ClientTransferService {
-	return &ClientTransferService{
-		serverURL:     fmt.Sprintf("http://%s:%d/api/v1", serverHost, serverPort),
-		rtranfilePath: "/usr/local/bin/rtranfile", // 默认rtranfile路径
-		config:        config,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
+// NewClientTransferService 创建新的客户端传输服务，opts 为服务端API客户端选项（超时、重试、认证等）
+func NewClientTransferService(serverHost string, serverPort int, config *models.TransferSettings, opts ...client.Option) *ClientTransferService {
+	return NewClientTransferServiceWithPath(serverHost, serverPort, "/usr/local/bin/rtranfile", config, opts...) // 默认rtranfile路径
 }
 
 // NewClientTransferServiceWithPath 使用指定rtranfile路径创建客户端传输服务
-func NewClientTransferServiceWithPath(serverHost string, serverPort int, rtranfilePath string, config *models.TransferSettings) *ClientTransferService {
-	return &ClientTransferService{
-		serverURL:     fmt.Sprintf("http://%s:%d/api/v1", serverHost, serverPort),
+func NewClientTransferServiceWithPath(serverHost string, serverPort int, rtranfilePath string, config *models.TransferSettings, opts ...client.Option) *ClientTransferService {
+	cts := &ClientTransferService{
+		api:           client.NewFromHostPort(serverHost, serverPort, opts...),
 		rtranfilePath: rtranfilePath,
 		config:        config,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		logger:        logger.GetLogger().Named(logger.ComponentTransfer),
+	}
+	if config != nil {
+		cts.faults = fault.New(config.Faults)
 	}
+	return cts
 }
 
-// CreateTransfer 通过服务端API创建传输任务
-func (cts *ClientTransferService) CreateTransfer(req *models.TransferRequest) (*models.TransferResponse, error) {
-	// 准备请求体
-	requestBody, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("序列化请求失败: %v", err)
-	}
+// SetLogger 设置日志器
+func (cts *ClientTransferService) SetLogger(logger *zap.Logger) {
+	cts.logger = logger
+}
 
-	// 发送请求到服务端
-	resp, err := cts.client.Post(cts.serverURL+"/transfers", "application/json", bytes.NewBuffer(requestBody))
-	if err != nil {
-		return nil, fmt.Errorf("调用服务端API失败: %v", err)
-	}
-	defer resp.Body.Close()
+// SetChunkTuner 设置块大小调优器，传输成功后记录块大小的吞吐量，启用自动选择时为未指定块大小的传输选择块大小
+func (cts *ClientTransferService) SetChunkTuner(tuner *tuning.ChunkTuner) {
+	cts.tuner = tuner
+}
 
-	// 检查响应状态
-	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("服务端返回错误状态: %d", resp.StatusCode)
-	}
+// SetProgressInterval 设置进度更新间隔（monitoring.client.progress_update_interval），
+// 执行传输期间按该间隔读取 rtranfile 日志，并按该间隔和服务端要求的心跳间隔中较小的一个上报进度
+func (cts *ClientTransferService) SetProgressInterval(interval time.Duration) {
+	cts.progressInterval = interval
+}
 
-	// 解析响应
-	var transferResp models.TransferResponse
-	if err := json.NewDecoder(resp.Body).Decode(&transferResp); err != nil {
-		return nil, fmt.Errorf("解析服务端响应失败: %v", err)
+// CreateTransfer 通过服务端API创建传输任务
+func (cts *ClientTransferService) CreateTransfer(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, error) {
+	transferResp, req, err := cts.createTask(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 如果服务端返回准备就绪状态，客户端在后台执行实际传输
-	if transferResp.Status == models.StatusPrepared {
-		// 在后台异步执行客户端传输
-		go cts.executeClientTransferAsync(req, transferResp.ID)
-		
+	// 客户端使用服务端任务ID执行，任务状态以服务端为准，取消任务时同时停止本地执行
+	if transferResp.Status == models.StatusPrepared && !req.IsVerify() {
+		// 在后台异步执行客户端传输（保留追踪信息，但不随请求结束而取消）
+		execCtx, cancel := context.WithCancel(tracing.Detach(ctx))
+		untrack := trackExecution(transferResp.ID, cancel)
+		go func() {
+			defer untrack()
+			cts.executeClientTransferAsync(execCtx, req, transferResp.ID, transferResp.TargetFilename, transferResp.HeartbeatInterval)
+		}()
+
 		// 立即返回，不等待传输完成
 		transferResp.Status = models.StatusInProgress
 		transferResp.Message = "客户端传输已开始执行，请通过查询接口获取进度"
 	}
 
-	return &transferResp, nil
+	// 服务端延后、正在从对象存储拉取源文件或在设备调度队列中等待的任务：等待服务端准备就绪，再执行客户端传输
+	if transferResp.Status == models.StatusDeferred || transferResp.Status == models.StatusStaging || transferResp.Status == models.StatusQueued || transferResp.Status == models.StatusBlocked {
+		waitCtx, cancel := context.WithCancel(tracing.Detach(ctx))
+		untrack := trackExecution(transferResp.ID, cancel)
+		go func() {
+			defer untrack()
+			cts.waitForDeferred(waitCtx, req, transferResp.ID, transferResp.Status, transferResp.TargetFilename, transferResp.HeartbeatInterval)
+		}()
+	}
+
+	return transferResp, nil
 }
 
-// GetTransferStatus 获取传输状态
-func (cts *ClientTransferService) GetTransferStatus(taskID string) (*models.ProgressResponse, error) {
-	resp, err := cts.client.Get(cts.serverURL + "/transfers/" + taskID)
-	if err != nil {
-		return nil, fmt.Errorf("获取传输状态失败: %v", err)
+// createTask 检查请求并在服务端创建任务，返回服务端响应和按服务端选择的模式、文件名调整后的请求
+func (cts *ClientTransferService) createTask(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, *models.TransferRequest, error) {
+	ctx, span := tracing.Start(ctx, "transfer.client.create",
+		attribute.String("transfer.filename", req.Filename),
+		attribute.String("transfer.mode", req.Mode),
+		attribute.String("transfer.direction", req.Direction),
+	)
+	defer span.End()
+
+	if err := checkExecutionsDraining(); err != nil {
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("服务端返回错误状态: %d", resp.StatusCode)
+	// 客户端按本地配置的 QoS 等级设置传输参数，提前检查等级是否存在
+	if cts.config != nil && !req.IsVerify() {
+		if _, _, err := resolveQoS(cts.config, req); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	var progressResp models.ProgressResponse
-	if err := json.NewDecoder(resp.Body).Decode(&progressResp); err != nil {
-		return nil, fmt.Errorf("解析传输状态失败: %v", err)
+	// 本端需要拉取或推送的对象存储必须在客户端配置中
+	if err := checkStaging(cts.config, req, false); err != nil {
+		return nil, nil, err
 	}
 
-	return &progressResp, nil
-}
+	// get 请求按命名空间把目标文件放到以调用方（owner）或任务 ID（task）命名的子目录中，再按冲突策略处理同名文件
+	namespace := models.NamespaceNone
+	if req.Direction == models.DirectionGet && !req.IsVerify() {
+		namespace = resolveNamespace(cts.config, req)
+	}
+	if namespace == models.NamespaceOwner {
+		principal, ok := auth.FromContext(ctx)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: 按调用方划分命名空间需要启用认证", ErrInvalidNamespace)
+		}
+		target, err := namespacedPath(localTarget(req), principal.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		namespacedReq := *req
+		namespacedReq.Filename = target
+		namespacedReq.Namespace = namespace
+		req = &namespacedReq
+	}
 
-// ListTransfers 列出传输任务
-func (cts *ClientTransferService) ListTransfers(page, size int) (*models.TaskListResponse, error) {
-	url := fmt.Sprintf("%s/transfers?page=%d&size=%d", cts.serverURL, page, size)
-	resp, err := cts.client.Get(url)
+	// get 请求的冲突策略为 fail 时，本地已有同名文件则不再请求服务端
+	if req.Direction == models.DirectionGet && !req.IsVerify() && resolveConflictPolicy(cts.config, req) == models.ConflictFail {
+		if _, err := os.Lstat(localTarget(req)); err == nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrFileExists, req.Filename)
+		}
+	}
+
+	// put 请求携带本地文件大小，服务端据此检查模式的文件大小上限并为 auto 模式选择传输模式
+	// 源文件需要从对象存储拉取时使用对象大小，拉取在服务端准备就绪后执行
+	if req.Direction == models.DirectionPut && !req.IsVerify() && req.Size == 0 {
+		sizedReq := *req
+		if ref := sourcePull(req, false); ref != nil {
+			size, err := staging.New(cts.config.ObjectStores).Size(ctx, ref)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrStagingUnavailable, err)
+			}
+			sizedReq.Size = size
+		} else {
+			info, err := os.Stat(req.Filename)
+			if err != nil {
+				return nil, nil, fmt.Errorf("获取本地文件信息失败: %v", err)
+			}
+			sizedReq.Size = info.Size()
+		}
+		req = &sizedReq
+	}
+
+	// 只校验的任务：重新计算本地文件的分块清单，交给服务端与其文件比较
+	if req.IsVerify() && req.Manifest == nil {
+		m, err := manifest.Build(ctx, req.Filename, manifest.DefaultChunkSize)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, nil, fmt.Errorf("生成本地分块清单失败: %v", err)
+		}
+		verifyReq := *req
+		verifyReq.Filename = remoteName(req)
+		verifyReq.Manifest = m
+		req = &verifyReq
+	}
+
+	// 发送请求到服务端（透传追踪上下文）
+	transferResp, err := cts.api.CreateTransfer(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("获取任务列表失败: %v", err)
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+
+	transferResp.TraceID = tracing.TraceID(ctx)
+	span.SetAttributes(attribute.String("transfer.task_id", transferResp.ID))
+
+	// 客户端按服务端选择的模式执行传输（auto 模式，或服务端按网络文件系统策略改用 filesystem 模式），get 按服务端文件大小预分配目标文件
+	resolvedReq := *req
+	if transferResp.Mode != "" && transferResp.Mode != req.Mode {
+		resolvedReq.Mode = transferResp.Mode
+	}
+	if req.Direction == models.DirectionGet {
+		resolvedReq.Size = transferResp.Size
+	}
+	if namespace == models.NamespaceTask {
+		// 任务 ID 只在服务端登记后确定，每个任务的子目录不同，不会与已有文件冲突
+		resolvedReq.Filename = filepath.Join(getFileDirectory(req.Filename), transferResp.ID, filepath.Base(req.Filename))
+		resolvedReq.Namespace = namespace
+	}
+	req = &resolvedReq
+
+	return transferResp, req, nil
+}
+
+// EstimatePlan 按客户端配置映射计划中的路径并填写 put 的本地文件大小，由服务端估算
+// 本地文件无法读取的传输在服务端的估算结果中记为无法执行
+func (cts *ClientTransferService) EstimatePlan(ctx context.Context, plan *models.TransferPlan) (*models.PlanEstimate, error) {
+	settings := cts.config
+	if settings == nil {
+		settings = DefaultSettings()
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("服务端返回错误状态: %d", resp.StatusCode)
+	resolved := *plan
+	resolved.Items = make([]models.TransferRequest, len(plan.Items))
+	localErrors := make(map[int]string)
+	for i, item := range plan.Items {
+		req := item
+		if err := MapRequestPaths(&req, settings, false); err != nil {
+			localErrors[i] = err.Error()
+		} else if req.Direction == models.DirectionPut && !req.IsVerify() && req.Size == 0 && sourcePull(&req, false) == nil {
+			if info, err := os.Stat(req.Filename); err != nil {
+				localErrors[i] = fmt.Sprintf("获取本地文件信息失败: %v", err)
+			} else {
+				req.Size = info.Size()
+			}
+		}
+		resolved.Items[i] = req
 	}
 
-	var taskListResp models.TaskListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&taskListResp); err != nil {
-		return nil, fmt.Errorf("解析任务列表失败: %v", err)
+	estimate, err := cts.api.EstimatePlan(ctx, &resolved)
+	if err != nil {
+		return nil, err
+	}
+	for i := range estimate.Items {
+		item := &estimate.Items[i]
+		msg, ok := localErrors[item.Index]
+		if !ok || item.Error != "" {
+			continue
+		}
+		if item.EstimatedSeconds > 0 {
+			estimate.EstimatedSeconds -= item.EstimatedSeconds
+		} else {
+			estimate.Unestimated--
+		}
+		estimate.TotalBytes -= item.Size
+		estimate.Invalid++
+		*item = models.PlanItemEstimate{
+			Index:     item.Index,
+			Filename:  item.Filename,
+			Direction: item.Direction,
+			Mode:      item.Mode,
+			Error:     msg,
+		}
+	}
+	return estimate, nil
+}
+
+// ListModes 获取服务端各传输模式是否启用
+func (cts *ClientTransferService) ListModes(ctx context.Context) (*models.TransferModesResponse, error) {
+	return cts.api.ListModes(ctx)
+}
+
+// GetTransferStatus 获取传输状态
+func (cts *ClientTransferService) GetTransferStatus(ctx context.Context, taskID string) (*models.ProgressResponse, error) {
+	return cts.api.GetTransfer(ctx, taskID)
+}
+
+// ListTransfers 列出传输任务
+func (cts *ClientTransferService) ListTransfers(ctx context.Context, page, size int, labels map[string]string) (*models.TaskListResponse, error) {
+	return cts.api.ListTransfersWithLabels(ctx, page, size, labels)
+}
+
+// CancelTransfer 取消服务端任务，任务在本地执行时同时停止本地执行
+func (cts *ClientTransferService) CancelTransfer(ctx context.Context, taskID string) error {
+	_, err := cts.api.CancelTransfer(ctx, taskID)
+	if cancelExecution(taskID) {
+		cts.logger.Info("已停止本地执行的客户端传输", zap.String("task_id", taskID))
 	}
+	return err
+}
+
+// Heartbeat 转发客户端心跳到服务端
+func (cts *ClientTransferService) Heartbeat(ctx context.Context, taskID string, req *models.HeartbeatRequest) (*models.HeartbeatResponse, error) {
+	return cts.api.Heartbeat(ctx, taskID, req)
+}
 
-	return &taskListResp, nil
+// ReportProgress 转发客户端传输进度到服务端
+func (cts *ClientTransferService) ReportProgress(ctx context.Context, taskID string, report *models.ProgressReport) (*models.ProgressResponse, error) {
+	return cts.api.ReportProgress(ctx, taskID, report)
 }
 
-// CancelTransfer 取消传输任务
-func (cts *ClientTransferService) CancelTransfer(taskID string) error {
-	req, err := http.NewRequest("DELETE", cts.serverURL+"/transfers/"+taskID, nil)
+// executeClientTransfer 执行客户端传输命令，传输完成后校验并移动到目标位置
+// target 为服务端按冲突策略选择的文件名（put），为空时使用本地文件名
+// progress 不为空时在 rtranfile 启动后设置日志监控器，供上报进度使用
+func (cts *ClientTransferService) executeClientTransfer(ctx context.Context, req *models.TransferRequest, target string, progress *clientProgress, log *zap.Logger) error {
+	// put: 启用校验和时先拉取或读取一次源文件计算摘要，完成时随结果上报给服务端校验
+	req, m, err := cts.stageSource(ctx, req, log)
 	if err != nil {
-		return fmt.Errorf("创建取消请求失败: %v", err)
+		return err
 	}
+	progress.setChecksum(req.Checksum)
 
-	resp, err := cts.client.Do(req)
+	run, err := cts.runTransfer(ctx, req, target, m, progress, log)
 	if err != nil {
-		return fmt.Errorf("取消传输任务失败: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
+	defer run.close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("服务端返回错误状态: %d", resp.StatusCode)
+	if err := cts.verifyTransfer(ctx, run, log); err != nil {
+		return err
 	}
+	return cts.finalizeTransfer(ctx, run, log)
+}
 
-	return nil
+// clientRun rtranfile 已执行完成、等待校验和落盘的客户端传输
+type clientRun struct {
+	req      *models.TransferRequest
+	staged   string             // get 在暂存目录中收到的文件，为空时直接写入目标位置
+	policy   string             // get 的冲突策略，移动到目标位置时使用
+	manifest *manifest.Manifest // put 传输前计算的本地分块清单，为 nil 时校验时计算
+	cleanups []func()           // 落盘后删除发送用的链接和暂存目录
 }
 
-// executeClientTransfer 执行客户端传输命令
-func (cts *ClientTransferService) executeClientTransfer(req *models.TransferRequest) error {
+// close 删除传输使用的链接和暂存目录
+func (r *clientRun) close() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+// runTransfer 执行 rtranfile 传输，返回等待校验和落盘的传输，失败时已删除链接和暂存目录
+// m 为 put 传输前已计算的本地分块清单，为 nil 时校验时重新计算
+func (cts *ClientTransferService) runTransfer(ctx context.Context, req *models.TransferRequest, target string, m *manifest.Manifest, progress *clientProgress, log *zap.Logger) (_ *clientRun, err error) {
+	run := &clientRun{manifest: m}
+	defer func() {
+		if err != nil {
+			run.close()
+		}
+	}()
+
+	// put: 先从对象存储拉取源文件
+	if err := cts.pullSource(ctx, req, nil, log); err != nil {
+		return nil, err
+	}
+
+	// put: 服务端选择了新文件名或请求指定了不同的服务端文件名时，通过链接以新文件名发送本地文件
+	if req.Direction == models.DirectionPut && target == "" {
+		target = remoteName(req)
+	}
+	if req.Direction == models.DirectionPut && target != getFileName(req.Filename) {
+		link, err := linkSource(req.Filename, target)
+		if err != nil {
+			return nil, err
+		}
+		run.cleanups = append(run.cleanups, func() { os.Remove(link) })
+		linkedReq := *req
+		linkedReq.Filename = link
+		linkedReq.SourcePath = req.Filename
+		linkedReq.DestinationPath = ""
+		req = &linkedReq
+		log.Info("以服务端文件名发送本地文件", zap.String("target", target))
+	}
+
 	// 构建传输配置
 	config, err := cts.buildTransferConfig(req)
 	if err != nil {
-		return fmt.Errorf("构建传输配置失败: %v", err)
+		return nil, fmt.Errorf("构建传输配置失败: %v", err)
+	}
+
+	// get: 目标文件位于命名空间子目录时先创建子目录
+	if req.Direction == models.DirectionGet && usesNamespace(req) {
+		if err := os.MkdirAll(filepath.Dir(localTarget(req)), 0755); err != nil {
+			return nil, fmt.Errorf("创建命名空间目录失败: %v", err)
+		}
+	}
+
+	// get: 启用临时后缀、冲突策略不是 overwrite 或服务端文件名与本地文件名不同时，先以服务端文件名下载到暂存目录，
+	// 校验通过后按策略原子地移动到目标位置，监视目标目录的程序不会看到传输了一半的文件
+	policy := resolveConflictPolicy(cts.config, req)
+	renamed := req.Direction == models.DirectionGet && remoteName(req) != filepath.Base(localTarget(req))
+	var staging string
+	if req.Direction == models.DirectionGet && (partialSuffix(cts.config) != "" || policy != models.ConflictOverwrite || renamed) {
+		if staging, err = stagingDir(localTarget(req)); err != nil {
+			return nil, err
+		}
+		config.Directory = staging
+		config.Filename = remoteName(req)
+		// 启用续传时失败的传输保留已接收的部分，检查点记录其位置
+		received := filepath.Join(staging, filepath.Base(config.Filename))
+		run.cleanups = append(run.cleanups, func() {
+			if err != nil && progress.retains(received) {
+				return
+			}
+			os.RemoveAll(staging)
+		})
 	}
 
+	// 检查点按 rtranfile 块大小对齐，只记录完整传输的分块
+	chunk := config.BlockSize
+	if chunk <= 0 {
+		chunk = config.ChunkSize
+	}
+	var receiving string
+	if req.Direction == models.DirectionGet {
+		receiving = filepath.Join(config.Directory, filepath.Base(config.Filename))
+	}
+	progress.setReceiving(int64(chunk), receiving)
+
 	// 验证配置
 	rtranfileWrapper := wrapper.NewRtranfileWrapper(cts.rtranfilePath)
 	if err := rtranfileWrapper.ValidateConfig(config); err != nil {
-		return fmt.Errorf("传输配置验证失败: %v", err)
+		return nil, fmt.Errorf("传输配置验证失败: %v", err)
+	}
+
+	// 传输结束后记录耗时和吞吐量指标（本地文件大小即传输字节数）
+	startTime := time.Now()
+	metrics.TransferStarted()
+	defer func() {
+		metrics.TransferFinished()
+		result := metrics.ResultCompleted
+		var size int64
+		if err != nil {
+			result = metrics.ResultFailed
+		} else if info, statErr := os.Stat(req.Filename); statErr == nil {
+			size = info.Size()
+		}
+		metrics.ObserveTransfer(req.Mode, req.Direction, config.Device, result, time.Since(startTime), size)
+		if err == nil && cts.tuner != nil {
+			cts.tuner.Record(config.Device, config.BlockSize, size, time.Since(startTime))
+		}
+	}()
+
+	// 预分配目标文件，空间不足时在传输开始前失败
+	if req.Direction == models.DirectionGet && req.Size > 0 && cts.config != nil && cts.config.Preallocate {
+		target := filepath.Join(config.Directory, filepath.Base(config.Filename))
+		if err := preallocate(target, req.Size); err != nil {
+			return nil, err
+		}
+		log.Info("已预分配目标文件", zap.String("path", target), zap.Int64("size", req.Size))
 	}
 
 	// 执行客户端传输命令
-	fmt.Printf("正在执行客户端传输命令...\n")
-	fmt.Printf("文件: %s, 模式: %s, 方向: %s\n", req.Filename, req.Mode, req.Direction)
+	log.Info("正在执行客户端传输命令", zap.String("filename", req.Filename))
 	
-	cmd, err := rtranfileWrapper.StartClient(context.Background(), config)
+	cmd, err := rtranfileWrapper.StartClient(ctx, config)
 	if err != nil {
-		return fmt.Errorf("启动客户端传输失败: %v", err)
+		return nil, fmt.Errorf("启动客户端传输失败: %v", err)
 	}
 
 	// 启动进程
+	_, span := tracing.Start(ctx, "rtranfile.client.process",
+		attribute.String("rdma.device", config.Device),
+		attribute.String("transfer.server_address", config.ServerAddress),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	if err := cts.faults.DelayLaunch(ctx); err != nil {
+		return nil, err
+	}
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("启动客户端传输进程失败: %v", err)
+		return nil, fmt.Errorf("启动客户端传输进程失败: %v", err)
+	}
+	progress.setStarted(time.Now())
+	span.SetAttributes(attribute.Int("process.pid", cmd.Process.Pid))
+	log.Info("客户端传输进程已启动", zap.Int("pid", cmd.Process.Pid))
+	cts.faults.KillAfter(ctx, cmd.Process)
+
+	// 监控 rtranfile 日志获取传输进度
+	monitor := wrapper.NewTransferMonitor(config.LogFile)
+	monitor.SetLogger(log)
+	monitor.SetInterval(cts.progressInterval)
+	if cts.config != nil && cts.config.LogRecordingDir != "" {
+		name := fmt.Sprintf("%s_%s.jsonl", time.Now().Format("20060102T150405"), filepath.Base(config.Filename))
+		recorder, err := wrapper.NewLogRecorder(filepath.Join(cts.config.LogRecordingDir, name))
+		if err != nil {
+			log.Warn("录制传输日志失败", zap.Error(err))
+		} else {
+			defer recorder.Close()
+			monitor.AddLineFilter(recorder.Record)
+			log.Info("正在录制传输日志", zap.String("path", recorder.Path()))
+		}
+	}
+	if cts.faults != nil {
+		monitor.AddLineFilter(cts.faults.CorruptLine)
+	}
+	if err := monitor.StartMonitoring(ctx); err != nil {
+		log.Warn("启动传输进度监控失败", zap.Error(err))
 	}
+	defer monitor.StopMonitoring()
+	progress.setMonitor(monitor)
 
 	// 等待传输完成
 	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("客户端传输执行失败: %v", err)
+		return nil, fmt.Errorf("客户端传输执行失败: %v", err)
+	}
+
+	run.req = req
+	run.policy = policy
+	if staging != "" {
+		run.staged = filepath.Join(staging, filepath.Base(config.Filename))
+	}
+	return run, nil
+}
+
+// verifyTransfer 按分块清单校验传输结果，get 在移动到目标位置前校验暂存目录中的文件
+func (cts *ClientTransferService) verifyTransfer(ctx context.Context, run *clientRun, log *zap.Logger) error {
+	if cts.config == nil || !cts.config.ChunkManifest {
+		return nil
+	}
+	req := run.req
+	if run.staged != "" {
+		stagedReq := *req
+		stagedReq.Filename = run.staged
+		req = &stagedReq
+	}
+	return cts.verifyChunks(ctx, req, run.manifest, log)
+}
+
+// finalizeTransfer 把 get 收到的文件按冲突策略移动到目标位置，恢复元数据并推送到对象存储
+func (cts *ClientTransferService) finalizeTransfer(ctx context.Context, run *clientRun, log *zap.Logger) error {
+	req := run.req
+	if run.staged != "" {
+		final, err := finalizeTarget(run.staged, localTarget(req), run.policy)
+		if err != nil {
+			return err
+		}
+		if final != localTarget(req) {
+			log.Info("本地已有同名文件，已使用新文件名保存", zap.String("path", final))
+		}
+		finalReq := *req
+		finalReq.Filename = final
+		req = &finalReq
+	}
+
+	// 按配置档案恢复源文件的元数据
+	if cts.config != nil && req.Profile != "" {
+		if profile, ok := lookupProfile(cts.config, req.Profile); ok && profile.Metadata.Preserve {
+			if err := cts.preserveMetadata(ctx, req, profile.Metadata, log); err != nil {
+				return err
+			}
+		}
+	}
+
+	// get: 把收到的文件推送到对象存储，推送完成后才上报完成
+	return cts.pushReceived(ctx, req, localTarget(req), log)
+}
+
+// preserveMetadata 将源文件的权限、修改时间和扩展属性恢复到目标文件
+// put: 读取本地文件元数据，由服务端恢复；get: 获取服务端文件元数据，恢复到本地文件
+func (cts *ClientTransferService) preserveMetadata(ctx context.Context, req *models.TransferRequest, settings models.MetadataSettings, log *zap.Logger) error {
+	_, span := tracing.Start(ctx, "transfer.client.preserve_metadata")
+	defer span.End()
+
+	switch req.Direction {
+	case models.DirectionPut:
+		m, err := filemeta.Capture(req.Filename, settings.Xattrs)
+		if err != nil {
+			return fmt.Errorf("读取本地文件元数据失败: %v", err)
+		}
+		if err := cts.api.ApplyFileMetadata(ctx, req.Mode, m); err != nil {
+			tracing.RecordError(span, err)
+			return fmt.Errorf("服务端恢复文件元数据失败: %v", err)
+		}
+	case models.DirectionGet:
+		m, err := cts.api.GetFileMetadata(ctx, remoteName(req), req.Mode, settings.Xattrs)
+		if err != nil {
+			return fmt.Errorf("获取服务端文件元数据失败: %v", err)
+		}
+		if err := filemeta.Apply(req.Filename, m); err != nil {
+			tracing.RecordError(span, err)
+			return fmt.Errorf("恢复本地文件元数据失败: %v", err)
+		}
+	default:
+		return nil
 	}
 
-	fmt.Printf("客户端传输完成\n")
+	log.Info("已保留文件元数据", zap.Strings("xattrs", settings.Xattrs))
+	return nil
+}
+
+// verifyChunks 按分块清单校验传输结果
+// put: 生成本地清单（prebuilt 不为 nil 时使用传输前计算的清单），由服务端逐块校验；get: 获取服务端清单，逐块校验本地文件
+func (cts *ClientTransferService) verifyChunks(ctx context.Context, req *models.TransferRequest, prebuilt *manifest.Manifest, log *zap.Logger) error {
+	_, span := tracing.Start(ctx, "transfer.client.verify_chunks")
+	defer span.End()
+
+	var report *manifest.Report
+	switch req.Direction {
+	case models.DirectionPut:
+		var m *manifest.Manifest
+		var err error
+		if prebuilt != nil {
+			// 传输前按源文件计算的清单，服务端按发送时的文件名查找文件
+			named := *prebuilt
+			named.Filename = filepath.Base(req.Filename)
+			m = &named
+		} else if m, err = manifest.Build(ctx, req.Filename, manifest.DefaultChunkSize); err != nil {
+			return fmt.Errorf("生成分块清单失败: %v", err)
+		}
+		// 以服务端文件名发送时 filename 为链接，本地清单保存在源文件旁
+		source := req.Filename
+		if req.SourcePath != "" {
+			source = req.SourcePath
+		}
+		if err := manifest.Save(manifest.PathFor(source), m); err != nil {
+			log.Warn("保存本地分块清单失败", zap.Error(err))
+		}
+		if report, err = cts.api.VerifyManifest(ctx, req.Mode, m); err != nil {
+			return fmt.Errorf("服务端分块校验失败: %v", err)
+		}
+	case models.DirectionGet:
+		m, err := cts.api.GetManifest(ctx, remoteName(req), req.Mode, 0)
+		if err != nil {
+			return fmt.Errorf("获取服务端分块清单失败: %v", err)
+		}
+		if report, err = manifest.Verify(ctx, req.Filename, m); err != nil {
+			return fmt.Errorf("本地分块校验失败: %v", err)
+		}
+	default:
+		return nil
+	}
+
+	if !report.OK {
+		log.Error("分块校验发现损坏",
+			zap.Ints("bad_chunks", report.BadChunks),
+			zap.Any("bad_ranges", report.BadRanges),
+			zap.Int64("size", report.Size),
+			zap.Int64("expected_size", report.ExpectedSize),
+		)
+		err := fmt.Errorf("分块校验失败: %d/%d 个分块损坏", len(report.BadChunks), report.TotalChunks)
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	log.Info("分块校验通过", zap.Int("chunks", report.TotalChunks))
 	return nil
 }
 
 // executeClientTransferAsync 异步执行客户端传输命令
-func (cts *ClientTransferService) executeClientTransferAsync(req *models.TransferRequest, taskID string) {
-	fmt.Printf("开始异步执行客户端传输，任务ID: %s\n", taskID)
-	
-	if err := cts.executeClientTransfer(req); err != nil {
-		fmt.Printf("客户端传输执行失败，任务ID: %s, 错误: %v\n", taskID, err)
+// heartbeat 为服务端要求的心跳间隔，执行期间定期上报心跳，会话过期时停止传输
+func (cts *ClientTransferService) executeClientTransferAsync(ctx context.Context, req *models.TransferRequest, taskID, target string, heartbeat time.Duration) {
+	exec := cts.beginExecution(ctx, req, taskID, heartbeat)
+	for {
+		err := cts.executeClientTransfer(exec.ctx, req, target, exec.progress, exec.log)
+		// 服务端守护进程重启后恢复了会话：监听进程重新启动导致传输中断时，按同一任务重新执行一次
+		if cts.shouldRestart(exec, err) {
+			err = cts.executeClientTransfer(exec.ctx, req, target, exec.progress, exec.log)
+		}
+		// 机会型任务被常规传输抢占：等待服务端重新准备后从头执行
+		next, paused := cts.resumePreempted(ctx, exec, err)
+		if !paused {
+			cts.endExecution(exec, err)
+			return
+		}
+		if next == nil {
+			return
+		}
+		exec = next
+	}
+}
+
+// clientExecution 客户端正在执行的服务端任务，执行期间定期上报进度，结束时上报结果
+type clientExecution struct {
+	ctx       context.Context // 执行使用的上下文，服务端任务结束或会话过期时取消
+	base      context.Context // 上报结果使用的上下文
+	req       *models.TransferRequest
+	taskID    string
+	heartbeat time.Duration
+	progress  *clientProgress
+	log       *zap.Logger
+	span      trace.Span
+	cancel    context.CancelFunc
+	stop      func() // 停止上报进度和写入检查点
+	untrack   func()
+}
+
+// beginExecution 开始执行服务端任务：登记执行中的传输并在后台上报进度
+func (cts *ClientTransferService) beginExecution(ctx context.Context, req *models.TransferRequest, taskID string, heartbeat time.Duration) *clientExecution {
+	ctx, span := tracing.Start(ctx, "transfer.client.execute",
+		attribute.String("transfer.task_id", taskID),
+		attribute.String("transfer.mode", req.Mode),
+		attribute.String("transfer.direction", req.Direction),
+	)
+
+	log := cts.logger.With(
+		zap.String("task_id", taskID),
+		zap.String("mode", req.Mode),
+		zap.String("direction", req.Direction),
+		zap.String("trace_id", tracing.TraceID(ctx)),
+	)
+	log.Info("开始异步执行客户端传输")
+
+	execCtx, cancel := context.WithCancel(ctx)
+	progress := &clientProgress{size: req.Size, resume: cts.checkpoints != nil}
+	stopCheckpoints := cts.startCheckpoints(execCtx, req, taskID, progress, log)
+	stopReporting := cts.startReporting(execCtx, taskID, heartbeat, progress, cancel, log)
+	return &clientExecution{
+		ctx:       execCtx,
+		base:      ctx,
+		req:       req,
+		taskID:    taskID,
+		heartbeat: heartbeat,
+		progress:  progress,
+		log:       log,
+		span:      span,
+		cancel:    cancel,
+		untrack:   trackRunning(taskID, progress),
+		stop: func() {
+			stopReporting()
+			stopCheckpoints()
+		},
+	}
+}
+
+// shouldRestart 传输失败后判断服务端会话是否在守护进程重启后恢复，恢复时清除上次的日志监控器，调用方按同一任务重新执行
+func (cts *ClientTransferService) shouldRestart(exec *clientExecution, err error) bool {
+	if err == nil || exec.ctx.Err() != nil || !cts.sessionRestored(exec.ctx, exec.taskID, exec.heartbeat, exec.progress) {
+		return false
+	}
+	exec.log.Warn("服务端守护进程重启后已恢复传输会话，重新执行传输", zap.Error(err))
+	exec.progress.setMonitor(nil)
+	return true
+}
+
+// resumePreempted 传输因机会型任务被抢占而停止时结束本次执行，等待服务端重新准备任务后开始新的执行
+// 返回的 paused 为 false 时调用方按原结果结束执行；paused 为 true 而执行为 nil 时任务不再准备就绪，服务端已记录任务状态，调用方不再上报结果
+func (cts *ClientTransferService) resumePreempted(ctx context.Context, exec *clientExecution, err error) (*clientExecution, bool) {
+	if err == nil || !exec.progress.isPaused() {
+		return nil, false
+	}
+	exec.stop()
+	exec.untrack()
+	exec.cancel()
+	exec.span.End()
+
+	exec.log.Warn("机会型任务被常规传输抢占，等待服务端重新准备", zap.Error(err))
+	if cts.awaitPrepared(ctx, exec.req, exec.taskID, models.StatusPaused) != nil {
+		return nil, true
+	}
+	return cts.beginExecution(ctx, exec.req, exec.taskID, exec.heartbeat), true
+}
+
+// endExecution 停止上报进度，向服务端上报执行结果，成功时执行传输后钩子
+func (cts *ClientTransferService) endExecution(exec *clientExecution, err error) {
+	defer exec.span.End()
+	defer exec.cancel()
+	defer exec.untrack()
+
+	exec.stop()
+	// 取消后仍需向服务端上报结果
+	ctx := context.WithoutCancel(exec.base)
+	if err != nil {
+		tracing.RecordError(exec.span, err)
+		exec.log.Error("客户端传输执行失败", zap.Error(err))
+		cts.saveCheckpoint(exec.req, exec.taskID, exec.progress, exec.log)
+		report := exec.progress.report(models.HeartbeatFailed)
+		report.Error = err.Error()
+		cts.sendProgress(ctx, exec.taskID, exec.heartbeat, report, exec.log)
+	} else {
+		exec.log.Info("客户端传输完成")
+		cts.checkpoints.remove(exec.taskID)
+		cts.sendProgress(ctx, exec.taskID, exec.heartbeat, exec.progress.report(models.HeartbeatCompleted), exec.log)
+		cts.runPostHooks(ctx, exec.req, exec.taskID, exec.log)
+	}
+}
+
+// clientProgress 客户端传输进度，rtranfile 启动后由执行协程设置日志监控器
+type clientProgress struct {
+	mu        sync.Mutex
+	size      int64     // 请求中的文件大小，日志中没有总字节数时使用
+	startedAt time.Time // rtranfile 进程第一次启动的时间，上报给服务端记录在任务时间线中
+	monitor   *wrapper.TransferMonitor
+	paused    bool                 // 服务端任务被常规传输抢占，执行已停止
+	resume    bool                 // 启用了续传，失败时记录检查点
+	chunk     int64                // rtranfile 块大小，检查点按块对齐
+	offset    int64                // 续传开始的偏移，本次传输的字节数从该偏移开始计算
+	partial   string               // get 已接收部分所在的本地文件
+	fixed     bool                 // 续传的 get 失败时不保留收到的剩余部分，检查点停留在续传开始的偏移
+	checksum  *models.FileChecksum // put 源文件的摘要，上报完成时交给服务端校验
+}
+
+// pause 记录服务端任务已被抢占
+func (p *clientProgress) pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = true
+}
+
+// isPaused 检查执行是否因服务端任务被抢占而停止
+func (p *clientProgress) isPaused() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// setStarted 记录 rtranfile 进程启动的时间，重新执行时保留第一次启动的时间
+func (p *clientProgress) setStarted(at time.Time) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.startedAt.IsZero() {
+		p.startedAt = at
+	}
+}
+
+// setMonitor 设置 rtranfile 日志监控器
+func (p *clientProgress) setMonitor(monitor *wrapper.TransferMonitor) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.monitor = monitor
+}
+
+// report 按当前进度构建上报内容
+func (p *clientProgress) report(state string) *models.ProgressReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := &models.ProgressReport{State: state, TotalBytes: p.size}
+	if !p.startedAt.IsZero() {
+		startedAt := p.startedAt
+		report.ProcessStartedAt = &startedAt
+	}
+	if p.monitor != nil {
+		info := p.monitor.GetProgress()
+		report.BytesTransferred = p.offset + info.BytesTransferred
+		report.TransferRate = info.TransferRate
+		if info.TotalBytes > 0 {
+			report.TotalBytes = p.offset + info.TotalBytes
+		}
+	}
+	report.Checkpoint = p.checkpointLocked()
+	if state == models.HeartbeatCompleted {
+		report.Checksum = p.checksum
+	}
+	return report
+}
+
+// startReporting 在后台定期上报传输进度（同时作为心跳），服务端返回会话已过期或任务已结束时调用 cancel 停止传输
+// 上报间隔为服务端要求的心跳间隔，不超过进度更新间隔（未设置时为 progressReportInterval）；返回的函数停止上报并等待后台协程退出，心跳间隔不大于 0 时不上报
+func (cts *ClientTransferService) startReporting(ctx context.Context, taskID string, heartbeat time.Duration, progress *clientProgress, cancel context.CancelFunc, log *zap.Logger) func() {
+	if heartbeat <= 0 {
+		return func() {}
+	}
+	limit := cts.progressInterval
+	if limit <= 0 {
+		limit = progressReportInterval
+	}
+	interval := heartbeat
+	if interval > limit {
+		interval = limit
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			resp, err := cts.api.ReportProgress(ctx, taskID, progress.report(models.HeartbeatRunning))
+			switch {
+			case ctx.Err() != nil:
+			case err == nil:
+				if resp.Status == models.StatusCancelled || resp.Status == models.StatusFailed {
+					log.Warn("服务端任务已结束，停止传输", zap.String("status", resp.Status))
+					cancel()
+					return
+				}
+				if resp.Status == models.StatusPaused {
+					log.Warn("服务端任务被常规传输抢占，暂停传输")
+					progress.pause()
+					cancel()
+					return
+				}
+			case client.IsSessionExpired(err):
+				log.Error("服务端传输会话已过期，停止传输", zap.Error(err))
+				cancel()
+				return
+			default:
+				log.Warn("上报传输进度失败", zap.Error(err))
+			}
+		}
+	}()
+
+	return func() {
+		stop()
+		<-done
+	}
+}
+
+// sessionRestored 传输失败后向服务端上报一次进度，判断会话是否在服务端守护进程重启后恢复
+// 服务端正在重启时在心跳超时内按心跳间隔重试，心跳间隔不大于 0 时服务端不跟踪会话
+func (cts *ClientTransferService) sessionRestored(ctx context.Context, taskID string, heartbeat time.Duration, progress *clientProgress) bool {
+	if heartbeat <= 0 {
+		return false
+	}
+	// 服务端的心跳间隔为心跳超时的三分之一
+	deadline := time.Now().Add(3 * heartbeat)
+	for {
+		resp, err := cts.api.ReportProgress(ctx, taskID, progress.report(models.HeartbeatRunning))
+		if err == nil {
+			return resp.Restored && resp.Status == models.StatusInProgress
+		}
+		if client.IsSessionExpired(err) || time.Now().Add(heartbeat).After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(heartbeat):
+		}
+	}
+}
+
+// sendProgress 上报传输结果，心跳间隔不大于 0 时服务端不需要上报
+func (cts *ClientTransferService) sendProgress(ctx context.Context, taskID string, heartbeat time.Duration, report *models.ProgressReport, log *zap.Logger) {
+	if heartbeat <= 0 {
+		return
+	}
+	if _, err := cts.api.ReportProgress(ctx, taskID, report); err != nil {
+		log.Warn("上报传输结果失败", zap.String("state", report.State), zap.Error(err))
+	}
+}
+
+// waitForDeferred 等待延后、正在暂存或排队的任务准备就绪，再执行客户端传输
+func (cts *ClientTransferService) waitForDeferred(ctx context.Context, req *models.TransferRequest, taskID, status, target string, heartbeat time.Duration) {
+	if cts.awaitPrepared(ctx, req, taskID, status) == nil {
+		cts.executeClientTransferAsync(ctx, req, taskID, target, heartbeat)
+	}
+}
+
+// awaitPrepared 轮询延后、正在暂存、排队、被抢占或等待依赖的任务状态，任务准备就绪时返回 nil，status 为服务端创建任务时返回的状态
+func (cts *ClientTransferService) awaitPrepared(ctx context.Context, req *models.TransferRequest, taskID, status string) error {
+	log := cts.logger.With(zap.String("task_id", taskID), zap.String("profile", req.Profile))
+	interval := deferredCheckInterval
+	if status == models.StatusPaused {
+		interval = stagingCheckInterval
+		log.Info("传输任务被常规传输抢占，等待设备空出名额后重新准备")
+	} else if status == models.StatusBlocked {
+		// 依赖的任务完成后可能立即准备就绪，按暂存的间隔轮询
+		interval = stagingCheckInterval
+		log.Info("传输任务等待依赖的任务完成")
+	} else if sourcePull(req, true) != nil {
+		// 服务端拉取完源文件后准备就绪的会话需要在有效期内开始执行，缩短轮询间隔
+		interval = stagingCheckInterval
+		log.Info("服务端正在从对象存储拉取源文件，等待准备就绪")
+	} else if status == models.StatusQueued {
+		// 设备空出名额后很快准备就绪，按暂存的间隔轮询
+		interval = stagingCheckInterval
+		log.Info("传输任务在服务端设备调度队列中等待，等待准备就绪")
 	} else {
-		fmt.Printf("客户端传输完成，任务ID: %s\n", taskID)
+		log.Info("传输任务已被服务端延后，等待时间窗口开放")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("已停止等待延后任务")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		progress, err := cts.api.GetTransfer(ctx, taskID)
+		if err != nil {
+			log.Warn("查询延后任务状态失败", zap.Error(err))
+			continue
+		}
+
+		switch progress.Status {
+		case models.StatusDeferred, models.StatusStaging, models.StatusQueued, models.StatusPaused, models.StatusBlocked:
+			continue
+		case models.StatusPrepared:
+			return nil
+		default:
+			log.Warn("延后任务未进入准备就绪状态，放弃执行", zap.String("status", progress.Status), zap.String("error", progress.Error))
+			return fmt.Errorf("任务未进入准备就绪状态: %s", progress.Status)
+		}
 	}
 }
 
+// localTarget 获取 get 请求在本地保存的文件路径
+func localTarget(req *models.TransferRequest) string {
+	return filepath.Join(getFileDirectory(req.Filename), filepath.Base(req.Filename))
+}
+
 // buildTransferConfig 构建客户端传输配置
 func (cts *ClientTransferService) buildTransferConfig(req *models.TransferRequest) (*wrapper.TransferConfig, error) {
 	// 使用配置中的设备设置
@@ -212,6 +1048,23 @@ func (cts *ClientTransferService) buildTransferConfig(req *models.TransferReques
 		ChunkSize: chunkSize,
 	}
 
+	// 设置 QoS 等级对应的块大小和流量类别
+	if cts.config != nil {
+		_, class, err := resolveQoS(cts.config, req)
+		if err != nil {
+			return nil, err
+		}
+		applyQoS(config, class)
+	}
+
+	// QoS 等级未指定块大小时由调优器选择
+	if config.BlockSize == 0 && cts.tuner != nil {
+		if size, reason := cts.tuner.Select(device); size > 0 {
+			config.BlockSize = size
+			cts.logger.Debug("自动选择块大小", zap.String("device", device), zap.Int("block_size", size), zap.String("reason", reason))
+		}
+	}
+
 	// 设置传输模式
 	switch req.Mode {
 	case models.ModeHugepages:
@@ -252,14 +1105,9 @@ func (cts *ClientTransferService) buildTransferConfig(req *models.TransferReques
 	}
 
 	// 设置服务端地址（从服务端URL中提取）
-	// 假设服务端URL格式为 http://host:port/api/v1
-	serverHost := cts.serverURL
-	if len(serverHost) > 7 { // 跳过 "http://"
-		serverHost = serverHost[7:]
-	}
-	// 移除端口和路径部分
-	if idx := strings.Index(serverHost, ":"); idx > 0 {
-		serverHost = serverHost[:idx]
+	serverHost := cts.api.BaseURL()
+	if serverURL, err := url.Parse(serverHost); err == nil {
+		serverHost = serverURL.Hostname()
 	}
 	config.ServerAddress = serverHost
 
@@ -0,0 +1,199 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rdma-burst/internal/models"
+)
+
+// ErrFileExists 目标文件已存在且冲突策略为 fail
+var ErrFileExists = errors.New("目标文件已存在")
+
+// maxConflictSuffix 查找可用文件名时尝试的最大序号
+const maxConflictSuffix = 10000
+
+// resolveConflictPolicy 获取请求使用的冲突策略，请求未指定时使用配置档案的策略，默认覆盖
+func resolveConflictPolicy(settings *models.TransferSettings, req *models.TransferRequest) string {
+	if req.OnConflict != "" {
+		return req.OnConflict
+	}
+	if settings != nil && req.Profile != "" {
+		if profile, ok := lookupProfile(settings, req.Profile); ok && profile.OnConflict != "" {
+			return profile.OnConflict
+		}
+	}
+	return models.ConflictOverwrite
+}
+
+// resolveNamespace 获取 get 请求的目标文件命名空间，请求未指定时使用配置档案的设置，默认不使用
+func resolveNamespace(settings *models.TransferSettings, req *models.TransferRequest) string {
+	if req.Namespace != "" {
+		return req.Namespace
+	}
+	if settings != nil && req.Profile != "" {
+		if profile, ok := lookupProfile(settings, req.Profile); ok && profile.Namespace != "" {
+			return profile.Namespace
+		}
+	}
+	return models.NamespaceNone
+}
+
+// namespacedPath 把目标文件放到所在目录中以 namespace 命名的子目录
+func namespacedPath(path, namespace string) (string, error) {
+	if namespace == "" || namespace == "." || namespace == ".." || strings.ContainsAny(namespace, `/\`) {
+		return "", fmt.Errorf("%w: 不能用作目录名: %q", ErrInvalidNamespace, namespace)
+	}
+	return filepath.Join(filepath.Dir(path), namespace, filepath.Base(path)), nil
+}
+
+// usesNamespace 目标文件是否位于命名空间子目录
+func usesNamespace(req *models.TransferRequest) bool {
+	return req.Namespace == models.NamespaceOwner || req.Namespace == models.NamespaceTask
+}
+
+// prepareReceivingTarget put 请求在服务端按冲突策略处理已存在的同名文件
+// 返回服务端实际写入的文件名，与请求的文件名相同时返回空字符串
+func prepareReceivingTarget(settings *models.TransferSettings, req *models.TransferRequest) (string, error) {
+	if req.Direction != models.DirectionPut || req.IsVerify() {
+		return "", nil
+	}
+
+	path, err := ServerFilePath(settings, req.Mode, req.Filename)
+	if err != nil {
+		return "", err
+	}
+	target, err := prepareTarget(path, resolveConflictPolicy(settings, req))
+	if err != nil {
+		return "", err
+	}
+	if target == filepath.Base(path) {
+		return "", nil
+	}
+	return target, nil
+}
+
+// prepareTarget 在接收端传输开始前按冲突策略处理已存在的目标文件，返回实际写入的文件名
+// fail: 返回 ErrFileExists；version: 将已有文件重命名为 <文件>.~N~；rename: 选择不冲突的新文件名
+func prepareTarget(path, policy string) (string, error) {
+	name := filepath.Base(path)
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return name, nil
+		}
+		return "", fmt.Errorf("检查目标文件失败: %v", err)
+	}
+
+	switch policy {
+	case models.ConflictFail:
+		return "", fmt.Errorf("%w: %s", ErrFileExists, name)
+	case models.ConflictVersion:
+		backup, err := versionPath(path)
+		if err != nil {
+			return "", err
+		}
+		if err := os.Rename(path, backup); err != nil {
+			return "", fmt.Errorf("保留已有文件版本失败: %v", err)
+		}
+		return name, nil
+	case models.ConflictRename:
+		renamed, err := uniquePath(path)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Base(renamed), nil
+	}
+	return name, nil
+}
+
+// finalizeTarget 将暂存文件按冲突策略原子地移动到目标位置，返回最终路径
+func finalizeTarget(staged, path, policy string) (string, error) {
+	switch policy {
+	case models.ConflictFail:
+		// 硬链接不会覆盖已有文件，传输期间出现的同名文件不会被覆盖
+		if err := os.Link(staged, path); err != nil {
+			if os.IsExist(err) {
+				return "", fmt.Errorf("%w: %s", ErrFileExists, filepath.Base(path))
+			}
+			return "", fmt.Errorf("移动暂存文件失败: %v", err)
+		}
+		os.Remove(staged)
+		return path, nil
+	case models.ConflictRename:
+		if _, err := os.Lstat(path); err == nil {
+			renamed, err := uniquePath(path)
+			if err != nil {
+				return "", err
+			}
+			path = renamed
+		}
+	case models.ConflictVersion:
+		if _, err := os.Lstat(path); err == nil {
+			backup, err := versionPath(path)
+			if err != nil {
+				return "", err
+			}
+			if err := os.Rename(path, backup); err != nil {
+				return "", fmt.Errorf("保留已有文件版本失败: %v", err)
+			}
+		}
+	}
+
+	if err := os.Rename(staged, path); err != nil {
+		return "", fmt.Errorf("移动暂存文件失败: %v", err)
+	}
+	return path, nil
+}
+
+// versionPath 获取已有文件的备份路径 <文件>.~N~
+func versionPath(path string) (string, error) {
+	for i := 1; i <= maxConflictSuffix; i++ {
+		candidate := fmt.Sprintf("%s.~%d~", path, i)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("没有可用的版本文件名: %s", path)
+}
+
+// uniquePath 获取不冲突的新文件路径 <名称>.N<扩展名>
+func uniquePath(path string) (string, error) {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for i := 1; i <= maxConflictSuffix; i++ {
+		candidate := fmt.Sprintf("%s.%d%s", stem, i, ext)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("没有可用的文件名: %s", path)
+}
+
+// linkSource 在源文件所在目录创建指向源文件的硬链接，使 rtranfile 以服务端选择的文件名发送
+// 无法创建硬链接（如跨文件系统）时使用符号链接，返回链接路径
+func linkSource(source, target string) (string, error) {
+	link := filepath.Join(filepath.Dir(source), target)
+	if err := os.Link(source, link); err == nil {
+		return link, nil
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("获取源文件路径失败: %v", err)
+	}
+	if err := os.Symlink(abs, link); err != nil {
+		return "", fmt.Errorf("创建源文件链接失败: %v", err)
+	}
+	return link, nil
+}
+
+// stagingDir 在目标文件所在目录创建暂存目录，暂存文件与目标文件位于同一文件系统以便原子重命名
+func stagingDir(path string) (string, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".rdma-burst-staging-")
+	if err != nil {
+		return "", fmt.Errorf("创建暂存目录失败: %v", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,257 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/schedule"
+	"rdma-burst/pkg/tracing"
+)
+
+// deferredCheckInterval 检查延后任务时间窗口的间隔
+const deferredCheckInterval = 30 * time.Second
+
+// ErrUnknownProfile 请求的传输配置档案不存在
+var ErrUnknownProfile = errors.New("传输配置档案不存在")
+
+// deferredTransfer 等待时间窗口开放的传输
+type deferredTransfer struct {
+	ctx      context.Context // 保留追踪信息，不随请求结束而取消
+	task     *models.TransferTask
+	req      models.TransferRequest
+	settings models.TransferSettings
+	windows  []schedule.Window
+}
+
+// lookupProfile 查找配置档案，配置文件中的档案名会被转换为小写
+func lookupProfile(settings *models.TransferSettings, name string) (models.TransferProfile, bool) {
+	profile, ok := settings.Profiles[name]
+	if !ok {
+		profile, ok = settings.Profiles[strings.ToLower(name)]
+	}
+	return profile, ok
+}
+
+// profileWindows 获取请求使用的配置档案的时间窗口
+func profileWindows(settings *models.TransferSettings, name string) ([]schedule.Window, error) {
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := lookupProfile(settings, name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+	return schedule.ParseWindows(profile.Windows)
+}
+
+// deferTransfer 创建延后任务，时间窗口开放后自动准备传输环境
+func (ts *TransferService) deferTransfer(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings, windows []schedule.Window, now time.Time, modeDecision, target string) *models.TransferResponse {
+	opensAt := schedule.NextOpen(windows, now)
+
+	task := newServerTask(ctx, req, serverConfig, modeDecision)
+	task.TargetPath = receivingPath(serverConfig, req, target)
+	task.Status = models.StatusDeferred
+	task.AddEvent(models.EventDeferred, opensAt.Format(time.RFC3339))
+	task.Message = fmt.Sprintf("配置档案 %s 的时间窗口未开放，预计 %s 开始", req.Profile, opensAt.Format(time.RFC3339))
+
+	ts.mu.Lock()
+	if ts.deferred == nil {
+		ts.deferred = make(map[string]*deferredTransfer)
+	}
+	ts.deferred[task.ID] = &deferredTransfer{
+		ctx:      tracing.Detach(ctx),
+		task:     task,
+		req:      *req,
+		settings: *serverConfig,
+		windows:  windows,
+	}
+	ts.taskHistory = append(ts.taskHistory, task)
+	ts.rememberRequest(task, req)
+	ts.recordDeferred(task, req)
+	ts.mu.Unlock()
+
+	ts.startScheduler()
+	if task.Deadline != nil {
+		ts.startDeadlineWatch()
+	}
+
+	ts.logger.Info("传输任务已延后",
+		zap.String("task_id", task.ID),
+		zap.String("profile", req.Profile),
+		zap.Time("opens_at", opensAt),
+	)
+
+	return &models.TransferResponse{
+		ID:                task.ID,
+		Status:            task.Status,
+		Message:           task.Message,
+		Mode:              task.Mode,
+		ModeDecision:      modeDecision,
+		Size:              req.Size,
+		HeartbeatInterval: ts.heartbeatInterval(),
+		TraceID:           task.TraceID,
+		CreatedAt:         task.CreatedAt,
+	}
+}
+
+// startScheduler 启动延后任务调度，只启动一次
+func (ts *TransferService) startScheduler() {
+	ts.schedulerOnce.Do(func() {
+		ts.mu.Lock()
+		stop := make(chan struct{})
+		ts.schedulerStop = stop
+		ts.mu.Unlock()
+		go ts.runDeferred(stop)
+	})
+}
+
+// recordDeferred 把延后任务的状态追加到预写日志，等待中的任务同时记录原始请求，调用方需持有锁
+func (ts *TransferService) recordDeferred(task *models.TransferTask, req *models.TransferRequest) {
+	if task.Status != models.StatusDeferred {
+		req = nil
+	}
+	ts.recordTask(task, req)
+}
+
+// restoreDeferred 按预写日志中的请求重新登记延后任务，时间窗口按当前配置的档案重新解析，调用方需持有锁
+func (ts *TransferService) restoreDeferred(ctx context.Context, task *models.TransferTask, req *models.TransferRequest) error {
+	if req == nil {
+		return fmt.Errorf("没有记录延后任务的请求")
+	}
+	if ts.serverConfig == nil {
+		return fmt.Errorf("服务端配置为空")
+	}
+	windows, err := profileWindows(ts.serverConfig, req.Profile)
+	if err != nil {
+		return err
+	}
+
+	settings := ts.taskSettings(task)
+	task.Device = settings.Device
+
+	if ts.deferred == nil {
+		ts.deferred = make(map[string]*deferredTransfer)
+	}
+	ts.deferred[task.ID] = &deferredTransfer{
+		ctx:      tracing.Detach(ctx),
+		task:     task,
+		req:      *req,
+		settings: settings,
+		windows:  windows,
+	}
+	return nil
+}
+
+// runDeferred 定期检查延后任务，时间窗口开放时启动
+func (ts *TransferService) runDeferred(stop <-chan struct{}) {
+	ticker := time.NewTicker(deferredCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			ts.startDueTransfers(now)
+		}
+	}
+}
+
+// startDueTransfers 准备时间窗口已开放的延后任务，启用选主时只有领导者启动
+func (ts *TransferService) startDueTransfers(now time.Time) {
+	ts.mu.Lock()
+	if ts.leaderCheck != nil && !ts.leaderCheck() {
+		ts.mu.Unlock()
+		return
+	}
+	var due []*deferredTransfer
+	for id, d := range ts.deferred {
+		if schedule.InAny(d.windows, now) {
+			due = append(due, d)
+			delete(ts.deferred, id)
+		}
+	}
+	ts.mu.Unlock()
+
+	// 同时开放的任务按截止时间准备，截止时间相同或都没有截止时间时按提交顺序
+	sort.SliceStable(due, func(i, j int) bool {
+		return deadlineOrder(due[i].task, due[j].task)
+	})
+
+	for _, d := range due {
+		// 源文件需要从对象存储拉取时在后台拉取，完成后再准备传输环境
+		if sourcePull(&d.req, true) != nil {
+			ts.stageTransfer(d.ctx, d.task, d.req, d.settings)
+			continue
+		}
+
+		// 设备的并发传输数已满时进入设备的调度队列
+		ts.mu.Lock()
+		reserved := ts.reserveDevice(d.ctx, d.task, &d.req, &d.settings)
+		ts.mu.Unlock()
+		if !reserved {
+			continue
+		}
+
+		ctx, span := tracing.Start(d.ctx, "transfer.deferred.start", attribute.String("transfer.task_id", d.task.ID))
+		err := ts.PrepareTransfer(ctx, &d.req, &d.settings)
+		tracing.RecordError(span, err)
+		span.End()
+
+		ts.mu.Lock()
+		ts.releaseDevice(d.task)
+		if err != nil {
+			d.task.MarkFailed(fmt.Sprintf("时间窗口开放后准备传输环境失败: %v", err))
+			ts.notifyFailed(d.task)
+			ts.logger.Error("延后任务启动失败", zap.String("task_id", d.task.ID), zap.Error(err))
+			ts.recordDeferred(d.task, &d.req)
+			ts.dispatchDevice(d.settings.Device)
+		} else {
+			d.task.Status = models.StatusPrepared
+			d.task.Message = "时间窗口已开放，传输环境准备就绪"
+			d.task.UpdatedAt = time.Now()
+			// 登记会话时连同请求写入预写日志
+			ts.registerSession(&d.req, d.task)
+			ts.logger.Info("延后任务已启动", zap.String("task_id", d.task.ID))
+		}
+		ts.mu.Unlock()
+	}
+}
+
+// cancelDeferred 取消延后任务，任务不存在时返回 false，调用方需持有锁
+func (ts *TransferService) cancelDeferred(ctx context.Context, taskID string) (bool, error) {
+	d, ok := ts.deferred[taskID]
+	if !ok {
+		return false, nil
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(d.task.Owner) {
+		return true, fmt.Errorf("%w: %s", ErrNotOwner, taskID)
+	}
+
+	d.task.MarkCancelled()
+	ts.recordDeferred(d.task, &d.req)
+	delete(ts.deferred, taskID)
+	return true, nil
+}
+
+// stopDeferred 停止延后任务调度并取消所有延后任务，调用方需持有锁
+// 取消不写入预写日志，守护进程重启后延后任务会从日志中恢复
+func (ts *TransferService) stopDeferred() {
+	if ts.schedulerStop != nil {
+		close(ts.schedulerStop)
+		ts.schedulerStop = nil
+	}
+	for id, d := range ts.deferred {
+		d.task.MarkCancelled()
+		delete(ts.deferred, id)
+	}
+}
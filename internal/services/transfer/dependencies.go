@@ -0,0 +1,302 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/schedule"
+	"rdma-burst/pkg/tracing"
+)
+
+// dependencyCheckInterval 检查被阻塞任务的依赖是否完成的间隔
+const dependencyCheckInterval = 2 * time.Second
+
+// maxDependencies 单个任务最多依赖的任务数
+const maxDependencies = 100
+
+var (
+	// ErrUnknownDependency 依赖的任务不存在
+	ErrUnknownDependency = errors.New("依赖的任务不存在")
+	// ErrDependencyFailed 依赖的任务已失败或被取消
+	ErrDependencyFailed = errors.New("依赖的任务未成功完成")
+)
+
+// validateDependencies 检查请求的依赖列表
+func validateDependencies(dependsOn []string) error {
+	if len(dependsOn) > maxDependencies {
+		return fmt.Errorf("最多依赖 %d 个任务", maxDependencies)
+	}
+	seen := make(map[string]bool, len(dependsOn))
+	for _, id := range dependsOn {
+		if id == "" {
+			return fmt.Errorf("依赖的任务 ID 不能为空")
+		}
+		if seen[id] {
+			return fmt.Errorf("重复的依赖任务: %s", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// historyTask 在任务历史中查找任务，不存在时返回 nil，调用方需持有锁
+func (ts *TransferService) historyTask(id string) *models.TransferTask {
+	for i := len(ts.taskHistory) - 1; i >= 0; i-- {
+		if ts.taskHistory[i].ID == id {
+			return ts.taskHistory[i]
+		}
+	}
+	return nil
+}
+
+// pendingDependencies 返回依赖中尚未结束的任务，调用方需持有锁
+// 依赖的任务不存在、已失败或被取消时返回错误；principal 不为 nil 时依赖的任务必须是调用方可以管理的任务
+func (ts *TransferService) pendingDependencies(principal *auth.Principal, dependsOn []string) ([]string, error) {
+	var pending []string
+	for _, id := range dependsOn {
+		task := ts.historyTask(id)
+		if task == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDependency, id)
+		}
+		if principal != nil && !principal.CanManage(task.Owner) {
+			return nil, fmt.Errorf("%w: %s", ErrNotOwner, id)
+		}
+		switch task.Status {
+		case models.StatusCompleted:
+		case models.StatusFailed, models.StatusCancelled:
+			return nil, fmt.Errorf("%w: %s 为 %s", ErrDependencyFailed, id, task.Status)
+		default:
+			pending = append(pending, id)
+		}
+	}
+	return pending, nil
+}
+
+// blockTransfer 创建等待依赖的任务，依赖的任务全部成功完成后按时间窗口、对象存储暂存和设备调度队列继续准备
+func (ts *TransferService) blockTransfer(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings, windows []schedule.Window, pending []string, modeDecision, target string) *models.TransferResponse {
+	task := newServerTask(ctx, req, serverConfig, modeDecision)
+	task.TargetPath = receivingPath(serverConfig, req, target)
+	task.Status = models.StatusBlocked
+	task.AddEvent(models.EventBlocked, strings.Join(pending, ","))
+	task.Message = fmt.Sprintf("等待依赖的任务完成: %s", strings.Join(pending, ", "))
+
+	ts.mu.Lock()
+	if ts.blocked == nil {
+		ts.blocked = make(map[string]*deferredTransfer)
+	}
+	ts.blocked[task.ID] = &deferredTransfer{
+		ctx:      tracing.Detach(ctx),
+		task:     task,
+		req:      *req,
+		settings: *serverConfig,
+		windows:  windows,
+	}
+	ts.taskHistory = append(ts.taskHistory, task)
+	ts.rememberRequest(task, req)
+	ts.recordTask(task, req)
+	ts.mu.Unlock()
+
+	ts.startDependencyWatch()
+	if task.Deadline != nil {
+		ts.startDeadlineWatch()
+	}
+
+	ts.logger.Info("传输任务等待依赖的任务完成",
+		zap.String("task_id", task.ID),
+		zap.Strings("depends_on", pending),
+	)
+
+	return &models.TransferResponse{
+		ID:                task.ID,
+		Status:            task.Status,
+		Message:           task.Message,
+		Mode:              task.Mode,
+		ModeDecision:      modeDecision,
+		Size:              req.Size,
+		HeartbeatInterval: ts.heartbeatInterval(),
+		TraceID:           task.TraceID,
+		CreatedAt:         task.CreatedAt,
+	}
+}
+
+// startDependencyWatch 启动依赖检查，只启动一次
+func (ts *TransferService) startDependencyWatch() {
+	ts.dependencyOnce.Do(func() {
+		ts.mu.Lock()
+		stop := make(chan struct{})
+		ts.dependencyStop = stop
+		ts.mu.Unlock()
+		go ts.runDependencyWatch(stop)
+	})
+}
+
+// runDependencyWatch 定期检查被阻塞任务的依赖
+func (ts *TransferService) runDependencyWatch(stop <-chan struct{}) {
+	ticker := time.NewTicker(dependencyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			ts.releaseBlocked(now)
+		}
+	}
+}
+
+// releaseBlocked 依赖的任务全部成功完成时继续准备被阻塞的任务，依赖的任务失败或被取消时任务随之失败，启用选主时只有领导者处理
+func (ts *TransferService) releaseBlocked(now time.Time) {
+	ts.mu.Lock()
+	if ts.leaderCheck != nil && !ts.leaderCheck() {
+		ts.mu.Unlock()
+		return
+	}
+	var ready []*deferredTransfer
+	for id, b := range ts.blocked {
+		pending, err := ts.pendingDependencies(nil, b.task.DependsOn)
+		switch {
+		case err != nil:
+			b.task.MarkFailed(err.Error())
+			b.task.Message = "依赖的任务未成功完成"
+			ts.notifyFailed(b.task)
+			ts.recordTask(b.task, nil)
+			delete(ts.blocked, id)
+			ts.logger.Warn("依赖的任务未成功完成，任务失败", zap.String("task_id", id), zap.Error(err))
+		case len(pending) == 0:
+			delete(ts.blocked, id)
+			ready = append(ready, b)
+		}
+	}
+	ts.mu.Unlock()
+
+	sort.SliceStable(ready, func(i, j int) bool {
+		return deadlineOrder(ready[i].task, ready[j].task)
+	})
+	for _, b := range ready {
+		ts.startUnblocked(b, now)
+	}
+}
+
+// startUnblocked 依赖的任务全部完成后继续准备任务：时间窗口未开放时转为延后任务，源文件需要从对象存储拉取时在后台拉取，
+// 否则进入设备的调度队列或直接准备传输环境
+func (ts *TransferService) startUnblocked(b *deferredTransfer, now time.Time) {
+	if !schedule.InAny(b.windows, now) {
+		opensAt := schedule.NextOpen(b.windows, now)
+		ts.mu.Lock()
+		b.task.Status = models.StatusDeferred
+		b.task.AddEvent(models.EventDeferred, opensAt.Format(time.RFC3339))
+		b.task.Message = fmt.Sprintf("依赖的任务已完成，配置档案 %s 的时间窗口未开放，预计 %s 开始", b.req.Profile, opensAt.Format(time.RFC3339))
+		b.task.UpdatedAt = now
+		if ts.deferred == nil {
+			ts.deferred = make(map[string]*deferredTransfer)
+		}
+		ts.deferred[b.task.ID] = b
+		ts.recordDeferred(b.task, &b.req)
+		ts.mu.Unlock()
+		ts.startScheduler()
+		return
+	}
+
+	if sourcePull(&b.req, true) != nil {
+		ts.stageTransfer(b.ctx, b.task, b.req, b.settings)
+		return
+	}
+
+	ts.mu.Lock()
+	reserved := ts.reserveDevice(b.ctx, b.task, &b.req, &b.settings)
+	ts.mu.Unlock()
+	if !reserved {
+		return
+	}
+
+	ctx, span := tracing.Start(b.ctx, "transfer.blocked.start", attribute.String("transfer.task_id", b.task.ID))
+	err := ts.PrepareTransfer(ctx, &b.req, &b.settings)
+	tracing.RecordError(span, err)
+	span.End()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.releaseDevice(b.task)
+	if err != nil {
+		b.task.MarkFailed(fmt.Sprintf("依赖的任务完成后准备传输环境失败: %v", err))
+		ts.notifyFailed(b.task)
+		ts.recordTask(b.task, nil)
+		ts.logger.Error("等待依赖的任务启动失败", zap.String("task_id", b.task.ID), zap.Error(err))
+		ts.dispatchDevice(b.settings.Device)
+		return
+	}
+	b.task.Status = models.StatusPrepared
+	b.task.Message = "依赖的任务已完成，传输环境准备就绪"
+	b.task.UpdatedAt = time.Now()
+	// 登记会话时连同请求写入预写日志
+	ts.registerSession(&b.req, b.task)
+	ts.logger.Info("等待依赖的任务已启动", zap.String("task_id", b.task.ID))
+}
+
+// cancelBlocked 取消等待依赖的任务，任务不存在时返回 false，调用方需持有锁
+func (ts *TransferService) cancelBlocked(ctx context.Context, taskID string) (bool, error) {
+	b, ok := ts.blocked[taskID]
+	if !ok {
+		return false, nil
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(b.task.Owner) {
+		return true, fmt.Errorf("%w: %s", ErrNotOwner, taskID)
+	}
+
+	b.task.MarkCancelled()
+	ts.recordTask(b.task, nil)
+	delete(ts.blocked, taskID)
+	return true, nil
+}
+
+// restoreBlocked 按预写日志中的请求重新登记等待依赖的任务，时间窗口按当前配置的档案重新解析，调用方需持有锁
+func (ts *TransferService) restoreBlocked(ctx context.Context, task *models.TransferTask, req *models.TransferRequest) error {
+	if req == nil {
+		return fmt.Errorf("没有记录等待依赖的任务的请求")
+	}
+	if ts.serverConfig == nil {
+		return fmt.Errorf("服务端配置为空")
+	}
+	windows, err := profileWindows(ts.serverConfig, req.Profile)
+	if err != nil {
+		return err
+	}
+
+	settings := ts.taskSettings(task)
+	task.Device = settings.Device
+
+	if ts.blocked == nil {
+		ts.blocked = make(map[string]*deferredTransfer)
+	}
+	ts.blocked[task.ID] = &deferredTransfer{
+		ctx:      tracing.Detach(ctx),
+		task:     task,
+		req:      *req,
+		settings: settings,
+		windows:  windows,
+	}
+	return nil
+}
+
+// stopBlocked 停止依赖检查并取消所有等待依赖的任务，调用方需持有锁
+// 取消不写入预写日志，守护进程重启后等待依赖的任务会从日志中恢复
+func (ts *TransferService) stopBlocked() {
+	if ts.dependencyStop != nil {
+		close(ts.dependencyStop)
+		ts.dependencyStop = nil
+	}
+	for id, b := range ts.blocked {
+		b.task.MarkCancelled()
+		delete(ts.blocked, id)
+	}
+}
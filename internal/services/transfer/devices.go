@@ -0,0 +1,368 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/pkg/tracing"
+)
+
+// ErrUnknownDevice 请求的 RDMA 设备没有配置
+var ErrUnknownDevice = errors.New("RDMA 设备没有配置")
+
+// queuedTransfer 在 RDMA 设备的调度队列中等待并发名额的传输
+type queuedTransfer struct {
+	ctx      context.Context // 保留追踪信息，不随请求结束而取消
+	task     *models.TransferTask
+	req      models.TransferRequest
+	settings models.TransferSettings // 设备已替换为任务使用的设备
+	blocked  error                   // 设备有空闲名额但超过其他并发上限时的原因
+}
+
+// deviceQueue RDMA 设备的调度队列
+type deviceQueue struct {
+	waiting  []*queuedTransfer
+	starting map[string]*models.TransferTask // 已出队、正在准备传输环境的任务
+}
+
+// resolveDevice 获取请求使用的 RDMA 设备，请求未指定时使用配置的默认设备
+func resolveDevice(settings *models.TransferSettings, req *models.TransferRequest) (string, error) {
+	if req.Device == "" || req.Device == settings.Device {
+		return settings.Device, nil
+	}
+	if _, ok := settings.Devices[req.Device]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownDevice, req.Device)
+	}
+	return req.Device, nil
+}
+
+// withDevice 返回使用指定设备的配置副本，设备与配置相同时返回原配置
+func withDevice(settings *models.TransferSettings, device string) *models.TransferSettings {
+	if device == settings.Device {
+		return settings
+	}
+	copied := *settings
+	copied.Device = device
+	return &copied
+}
+
+// deviceLimit 获取设备的并发上限，为 0 表示不单独限制
+func deviceLimit(settings *models.TransferSettings, device string) int {
+	if settings == nil {
+		return 0
+	}
+	return settings.Devices[device].MaxConcurrent
+}
+
+// listenerKey 服务端监听进程的键：默认设备使用模式名，其他设备使用 模式@设备，不同设备的监听进程同时运行
+func (ts *TransferService) listenerKey(device, mode string) string {
+	if device == "" || ts.serverConfig == nil || device == ts.serverConfig.Device {
+		return mode
+	}
+	return mode + "@" + device
+}
+
+// sameDevice 两个监听进程是否属于同一个设备
+func sameDevice(key, other string) bool {
+	_, device, _ := strings.Cut(key, "@")
+	_, otherDevice, _ := strings.Cut(other, "@")
+	return device == otherDevice
+}
+
+// deviceQueue 获取设备的调度队列，不存在时创建，调用方需持有锁
+func (ts *TransferService) deviceQueue(device string) *deviceQueue {
+	if ts.devices == nil {
+		ts.devices = make(map[string]*deviceQueue)
+	}
+	q, ok := ts.devices[device]
+	if !ok {
+		q = &deviceQueue{starting: make(map[string]*models.TransferTask)}
+		ts.devices[device] = q
+	}
+	return q
+}
+
+// deviceBusy 统计设备上准备就绪和执行中的会话数（包括正在准备的任务），调用方需持有锁
+func (ts *TransferService) deviceBusy(device string) int {
+	busy := 0
+	for _, session := range ts.sessions {
+		if session.endedAt == nil && !session.task.IsFinished() && session.task.Device == device {
+			busy++
+		}
+	}
+	if q, ok := ts.devices[device]; ok {
+		busy += len(q.starting)
+	}
+	return busy
+}
+
+// reserveDevice 为任务占用设备的并发名额，设备已满、已有任务在排队或超过其他并发上限时任务进入设备的调度队列并返回 false，调用方需持有锁
+// 常规任务排在所有机会型任务之前，没有常规任务排队而设备已满时抢占设备上的机会型会话；
+// 返回 true 时调用方准备传输环境后需要调用 releaseDevice
+func (ts *TransferService) reserveDevice(ctx context.Context, task *models.TransferTask, req *models.TransferRequest, settings *models.TransferSettings) bool {
+	device := task.Device
+	q := ts.deviceQueue(device)
+	limit := deviceLimit(settings, device)
+	ahead := q.ahead(task)
+	blocked := ts.checkAdmission(task, false)
+	if blocked == nil && (limit <= 0 || ahead == 0 && (ts.deviceBusy(device) < limit || !task.Opportunistic && ts.preemptOpportunistic(device))) {
+		q.starting[task.ID] = task
+		return true
+	}
+
+	q.insert(ahead, &queuedTransfer{
+		ctx:      tracing.Detach(ctx),
+		task:     task,
+		req:      *req,
+		settings: *settings,
+		blocked:  blocked,
+	})
+	task.Status = models.StatusQueued
+	task.UpdatedAt = time.Now()
+	ts.recordTask(task, req)
+	// 排在前面的任务可能都在等待其他并发上限，设备仍有空闲名额时立即启动可以开始的任务
+	ts.dispatchQueue(device)
+
+	ts.logger.Info("传输任务进入设备调度队列",
+		zap.String("task_id", task.ID),
+		zap.String("device", device),
+		zap.Bool("opportunistic", task.Opportunistic),
+		zap.Int("position", ahead+1),
+	)
+	return false
+}
+
+// ahead 新任务前面的排队任务数：常规任务排在所有机会型任务之前，同类任务中截止时间早的优先，其余按提交顺序
+func (q *deviceQueue) ahead(task *models.TransferTask) int {
+	for i, queued := range q.waiting {
+		if queued.task.Opportunistic && !task.Opportunistic ||
+			queued.task.Opportunistic == task.Opportunistic && earlierDeadline(task, queued.task) {
+			return i
+		}
+	}
+	return len(q.waiting)
+}
+
+// insert 把任务插入队列的第 i 位
+func (q *deviceQueue) insert(i int, queued *queuedTransfer) {
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = queued
+}
+
+// renumber 按当前位置更新排队任务的说明
+func (q *deviceQueue) renumber(device string, limit int) {
+	for i, queued := range q.waiting {
+		if queued.task.Status == models.StatusPaused {
+			queued.task.Message = fmt.Sprintf("被常规传输抢占，在设备 %s 的调度队列中排在第 %d 位", device, i+1)
+			continue
+		}
+		if queued.blocked != nil {
+			queued.task.Message = fmt.Sprintf("%v，在设备 %s 的调度队列中排在第 %d 位", queued.blocked, device, i+1)
+			continue
+		}
+		queued.task.Message = fmt.Sprintf("设备 %s 最多同时运行 %d 个传输，排在第 %d 位", device, limit, i+1)
+	}
+}
+
+// preemptOpportunistic 抢占设备上最近准备的机会型会话，为常规任务空出名额，调用方需持有锁
+// 被抢占的会话结束，客户端通过心跳获知任务已暂停并停止传输；任务重新排在机会型任务中，设备再有空闲名额时重新准备并从头传输
+// 监听进程由接着准备的常规任务继续使用，不停止
+func (ts *TransferService) preemptOpportunistic(device string) bool {
+	var victim *preparedSession
+	for _, session := range ts.sessions {
+		if session.endedAt != nil || session.task.IsFinished() || session.task.Device != device || !session.task.Opportunistic {
+			continue
+		}
+		if victim == nil || session.task.UpdatedAt.After(victim.task.UpdatedAt) {
+			victim = session
+		}
+	}
+	if victim == nil {
+		return false
+	}
+
+	now := time.Now()
+	task := victim.task
+	victim.endedAt = &now
+	victim.preempted = true
+	task.Status = models.StatusPaused
+	task.AddEvent(models.EventPreempted, "")
+	task.UpdatedAt = now
+	ts.progress.forget(task.ID)
+
+	q := ts.deviceQueue(device)
+	q.insert(q.ahead(task), &queuedTransfer{
+		ctx:      context.Background(),
+		task:     task,
+		req:      victim.req,
+		settings: ts.taskSettings(task),
+	})
+	q.renumber(device, deviceLimit(ts.serverConfig, device))
+	ts.recordTask(task, &victim.req)
+
+	ts.logger.Info("机会型任务被常规传输抢占",
+		zap.String("task_id", task.ID),
+		zap.String("device", device),
+		zap.Bool("started", victim.started),
+	)
+	return true
+}
+
+// releaseDevice 任务的传输环境已准备好（已登记为会话）或准备失败，释放正在准备的名额，调用方需持有锁
+func (ts *TransferService) releaseDevice(task *models.TransferTask) {
+	if q, ok := ts.devices[task.Device]; ok {
+		delete(q.starting, task.ID)
+	}
+}
+
+// dispatchDevice 设备空出名额后启动排队的任务，调用方需持有锁
+// 任务结束同时空出调用方等并发上限的名额，先启动该设备的任务，再检查其他设备的队列
+func (ts *TransferService) dispatchDevice(device string) {
+	ts.dispatchQueue(device)
+	for other := range ts.devices {
+		if other != device {
+			ts.dispatchQueue(other)
+		}
+	}
+}
+
+// dispatchQueue 设备有空闲名额时按提交顺序启动排队的任务，超过其他并发上限的任务留在队列中，调用方需持有锁
+// 准备传输环境需要等待监听进程启动，在后台执行
+func (ts *TransferService) dispatchQueue(device string) {
+	q, ok := ts.devices[device]
+	if !ok {
+		return
+	}
+	limit := deviceLimit(ts.serverConfig, device)
+	waiting := q.waiting[:0]
+	for _, next := range q.waiting {
+		if limit > 0 && ts.deviceBusy(device) >= limit {
+			waiting = append(waiting, next)
+			continue
+		}
+		if next.blocked = ts.checkAdmission(next.task, false); next.blocked != nil {
+			waiting = append(waiting, next)
+			continue
+		}
+		q.starting[next.task.ID] = next.task
+		go ts.startQueued(next)
+	}
+	clear(q.waiting[len(waiting):])
+	q.waiting = waiting
+	q.renumber(device, limit)
+}
+
+// startQueued 为出队的任务准备传输环境，完成后任务变为准备就绪，失败时释放名额并启动下一个排队的任务
+func (ts *TransferService) startQueued(queued *queuedTransfer) {
+	task, device := queued.task, queued.task.Device
+
+	ctx, span := tracing.Start(queued.ctx, "transfer.queued.start",
+		attribute.String("transfer.task_id", task.ID),
+		attribute.String("rdma.device", device),
+	)
+	err := ts.PrepareTransfer(ctx, &queued.req, &queued.settings)
+	tracing.RecordError(span, err)
+	span.End()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.releaseDevice(task)
+
+	switch {
+	case task.IsFinished():
+		// 等待准备期间任务已被取消，释放不再使用的监听进程
+		ts.stopIdleListener(ts.listenerKey(device, queued.req.Mode))
+		ts.dispatchDevice(device)
+	case err != nil:
+		task.MarkFailed(fmt.Sprintf("设备空出名额后准备传输环境失败: %v", err))
+		ts.notifyFailed(task)
+		ts.recordTask(task, nil)
+		ts.logger.Error("排队任务启动失败", zap.String("task_id", task.ID), zap.String("device", device), zap.Error(err))
+		ts.dispatchDevice(device)
+	default:
+		task.Status = models.StatusPrepared
+		task.Message = "设备已空出名额，传输环境准备就绪"
+		task.UpdatedAt = time.Now()
+		// 登记会话时连同请求写入预写日志
+		ts.registerSession(&queued.req, task)
+		ts.logger.Info("排队任务已启动", zap.String("task_id", task.ID), zap.String("device", device))
+	}
+}
+
+// cancelQueued 取消在设备调度队列中等待的任务，任务不存在时返回 false，调用方需持有锁
+func (ts *TransferService) cancelQueued(ctx context.Context, taskID string) (bool, error) {
+	for device, q := range ts.devices {
+		for i, queued := range q.waiting {
+			if queued.task.ID != taskID {
+				continue
+			}
+			if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(queued.task.Owner) {
+				return true, fmt.Errorf("%w: %s", ErrNotOwner, taskID)
+			}
+			queued.task.MarkCancelled()
+			ts.removeSegment(queued.task)
+			ts.recordTask(queued.task, nil)
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			ts.dispatchDevice(device)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// restoreQueued 按预写日志中的请求把排队任务重新加入设备的调度队列，调用方需持有锁
+// 设备已从配置中删除的任务加入默认设备的队列
+func (ts *TransferService) restoreQueued(ctx context.Context, task *models.TransferTask, req *models.TransferRequest) error {
+	if req == nil {
+		return fmt.Errorf("没有记录排队任务的请求")
+	}
+	if ts.serverConfig == nil {
+		return fmt.Errorf("服务端配置为空")
+	}
+	settings := ts.taskSettings(task)
+	task.Device = settings.Device
+
+	q := ts.deviceQueue(settings.Device)
+	q.insert(q.ahead(task), &queuedTransfer{
+		ctx:      tracing.Detach(ctx),
+		task:     task,
+		req:      *req,
+		settings: settings,
+	})
+	return nil
+}
+
+// taskSettings 按当前配置获取恢复或重新排队的任务使用的配置：任务的设备仍在 transfer.devices 中时使用该设备，否则使用默认设备
+func (ts *TransferService) taskSettings(task *models.TransferTask) models.TransferSettings {
+	if _, ok := ts.serverConfig.Devices[task.Device]; ok {
+		return *withDevice(ts.serverConfig, task.Device)
+	}
+	return *ts.serverConfig
+}
+
+// dispatchDevices 为所有设备启动可以开始的排队任务，调用方需持有锁
+func (ts *TransferService) dispatchDevices() {
+	for device := range ts.devices {
+		ts.dispatchQueue(device)
+	}
+}
+
+// stopQueued 取消所有排队的任务，调用方需持有锁
+// 取消不写入预写日志，守护进程重启后排队任务会从日志中恢复
+func (ts *TransferService) stopQueued() {
+	for device, q := range ts.devices {
+		for _, queued := range q.waiting {
+			queued.task.MarkCancelled()
+		}
+		delete(ts.devices, device)
+	}
+}
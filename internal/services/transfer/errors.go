@@ -0,0 +1,57 @@
+package transfer
+
+import "errors"
+
+// 传输服务返回的哨兵错误，调用方通过 errors.Is 判断错误类型
+var (
+	// ErrTaskNotFound 任务不存在或已结束
+	ErrTaskNotFound = errors.New("任务不存在")
+
+	// ErrConcurrencyLimit 已达到最大并发传输数
+	ErrConcurrencyLimit = errors.New("已达到最大并发传输限制")
+
+	// ErrIntervalNotElapsed 距离上次传输未达到配置的最小间隔
+	ErrIntervalNotElapsed = errors.New("未达到传输最小间隔")
+
+	// ErrDeviceUnavailable RDMA 设备不存在或没有可用端口
+	ErrDeviceUnavailable = errors.New("RDMA设备不可用")
+
+	// ErrNotOwner 调用方不是任务的创建者也不是管理员
+	ErrNotOwner = errors.New("无权操作其他调用方创建的任务")
+
+	// ErrModeDisabled 请求的传输模式在配置中未启用
+	ErrModeDisabled = errors.New("传输模式未启用")
+
+	// ErrFileTooLarge 文件超过传输模式的文件大小上限
+	ErrFileTooLarge = errors.New("文件超过传输模式的大小上限")
+
+	// ErrAdmissionRejected 准入钩子拒绝了传输请求
+	ErrAdmissionRejected = errors.New("准入检查拒绝了传输请求")
+
+	// ErrAdmissionUnavailable 准入钩子执行失败或超时
+	ErrAdmissionUnavailable = errors.New("准入检查执行失败")
+
+	// ErrInvalidStaging 请求的对象存储暂存设置无效，例如引用了未配置的对象存储
+	ErrInvalidStaging = errors.New("无效的对象存储暂存设置")
+
+	// ErrStagingUnavailable 访问对象存储失败
+	ErrStagingUnavailable = errors.New("对象存储不可用")
+
+	// ErrNetworkFilesystem 内存传输模式的目录位于网络文件系统，且策略为拒绝
+	ErrNetworkFilesystem = errors.New("传输模式目录位于网络文件系统")
+
+	// ErrInvalidPath 请求的 source_path 或 destination_path 无法映射到服务端的传输模式目录
+	ErrInvalidPath = errors.New("无效的传输路径")
+
+	// ErrInvalidChecksum 请求附带的源文件摘要无效，例如算法不支持或只用于 put 的摘要出现在其他请求中
+	ErrInvalidChecksum = errors.New("无效的文件摘要")
+
+	// ErrInvalidNamespace get 请求的目标文件命名空间无法确定，例如未启用认证时按调用方划分
+	ErrInvalidNamespace = errors.New("无效的目标文件命名空间")
+
+	// ErrTaskRunning 任务的传输进程仍在运行，应取消任务而不是强制结束
+	ErrTaskRunning = errors.New("传输进程仍在运行")
+
+	// ErrShuttingDown 服务正在关闭，等待进行中的传输结束，不再接受新的传输
+	ErrShuttingDown = errors.New("服务正在关闭，不再接受新的传输")
+)
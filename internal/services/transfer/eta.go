@@ -0,0 +1,105 @@
+package transfer
+
+import (
+	"sort"
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/schedule"
+)
+
+// queueETAs 按历史吞吐量估算排队任务的开始和完成时间，调用方需持有锁
+// 排队任务按最早可以开始的时间（延后任务为时间窗口开放的时间）依次占用并发名额，名额在正在传输的任务预计完成时释放
+// 没有同模式的历史吞吐量或文件大小未知的任务只估算开始时间，不占用名额
+func (ts *TransferService) queueETAs(now time.Time) map[string]*models.TaskETA {
+	type queuedTask struct {
+		task     *models.TransferTask
+		earliest time.Time
+	}
+	var queued []queuedTask
+	for _, task := range ts.taskHistory {
+		// 等待依赖的任务开始时间取决于依赖的任务，不估算
+		if !task.IsQueued() || task.Status == models.StatusBlocked {
+			continue
+		}
+		earliest := now
+		if d, ok := ts.deferred[task.ID]; ok {
+			earliest = schedule.NextOpen(d.windows, now)
+		}
+		queued = append(queued, queuedTask{task: task, earliest: earliest})
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+	sort.SliceStable(queued, func(i, j int) bool {
+		if !queued[i].earliest.Equal(queued[j].earliest) {
+			return queued[i].earliest.Before(queued[j].earliest)
+		}
+		return queued[i].task.CreatedAt.Before(queued[j].task.CreatedAt)
+	})
+
+	stats := ts.throughputStatsLocked()
+
+	// 正在传输的任务按剩余字节数估算释放名额的时间
+	var slots []time.Time
+	running := func(task *models.TransferTask) {
+		free := now
+		if s := lookupThroughput(stats, task.Device, task.Mode, task.Direction); s != nil && task.TotalBytes > task.BytesTransferred {
+			free = now.Add(s.transferTime(task.TotalBytes - task.BytesTransferred))
+		}
+		slots = append(slots, free)
+	}
+	for _, tw := range ts.activeTasks {
+		running(tw.Task)
+	}
+	for _, session := range ts.sessions {
+		if session.task.IsActive() {
+			running(session.task)
+		}
+	}
+
+	etas := make(map[string]*models.TaskETA, len(queued))
+	for _, q := range queued {
+		start := q.earliest
+		slot := -1
+		if ts.maxConcurrent > 0 {
+			for len(slots) < ts.maxConcurrent {
+				slots = append(slots, now)
+			}
+			// 超出并发上限的传输结束后名额才可用
+			for len(slots) > ts.maxConcurrent {
+				i := earliestSlot(slots)
+				slots = append(slots[:i], slots[i+1:]...)
+			}
+			slot = earliestSlot(slots)
+			if slots[slot].After(start) {
+				start = slots[slot]
+			}
+		}
+
+		eta := &models.TaskETA{EstimatedStart: start}
+		finish := start
+		if s := lookupThroughput(stats, q.task.Device, q.task.Mode, q.task.Direction); s != nil && q.task.TotalBytes > 0 {
+			finish = start.Add(s.averageSetup() + s.transferTime(q.task.TotalBytes))
+			eta.EstimatedCompletion = &finish
+			eta.ThroughputMBps = s.throughputMBps()
+			eta.Samples = s.samples
+		}
+		if slot >= 0 {
+			slots[slot] = finish
+		}
+		etas[q.task.ID] = eta
+	}
+	return etas
+}
+
+// earliestSlot 获取最早释放的名额
+func earliestSlot(slots []time.Time) int {
+	earliest := 0
+	for i, free := range slots {
+		if free.Before(slots[earliest]) {
+			earliest = i
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,157 @@
+package transfer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+)
+
+// executions 本进程中正在执行或等待延后任务的客户端传输，按服务端任务ID索引
+// 客户端不保存任务状态，状态以服务端任务记录为准，这里只用于停止本地执行
+var executions = struct {
+	sync.Mutex
+	cancels  map[string]context.CancelFunc
+	running  map[string]*clientProgress // 已开始执行传输的任务，关闭时等待其结束
+	draining bool                       // 正在关闭，拒绝新的传输
+}{cancels: make(map[string]context.CancelFunc), running: make(map[string]*clientProgress)}
+
+// trackExecution 登记客户端执行，返回的函数在执行结束时注销
+func trackExecution(taskID string, cancel context.CancelFunc) func() {
+	executions.Lock()
+	executions.cancels[taskID] = cancel
+	executions.Unlock()
+
+	return func() {
+		executions.Lock()
+		delete(executions.cancels, taskID)
+		executions.Unlock()
+	}
+}
+
+// trackRunning 登记开始执行传输的任务，返回的函数在传输结束并上报结果后注销
+func trackRunning(taskID string, progress *clientProgress) func() {
+	executions.Lock()
+	executions.running[taskID] = progress
+	executions.Unlock()
+
+	return func() {
+		executions.Lock()
+		delete(executions.running, taskID)
+		executions.Unlock()
+	}
+}
+
+// cancelExecution 停止本地执行的客户端传输，任务不在本地执行时返回 false
+func cancelExecution(taskID string) bool {
+	executions.Lock()
+	cancel, ok := executions.cancels[taskID]
+	executions.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// ActiveExecutions 获取本进程中正在执行或等待延后任务的客户端传输数
+func ActiveExecutions() int {
+	executions.Lock()
+	defer executions.Unlock()
+	return len(executions.cancels)
+}
+
+// ExecutionsDrainCheck 客户端就绪检查：正在关闭时返回错误
+func ExecutionsDrainCheck() error {
+	return checkExecutionsDraining()
+}
+
+// checkExecutionsDraining 客户端正在关闭时拒绝新的传输
+func checkExecutionsDraining() error {
+	executions.Lock()
+	defer executions.Unlock()
+	if executions.draining {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// DrainExecutions 停止接受新的客户端传输，在 grace 内等待已开始执行的传输结束，期间定期记录进度
+// 返回超时后仍在执行的传输数，调用方随后通过 StopExecutions 强制停止；等待延后任务的执行不等待
+func DrainExecutions(grace time.Duration, log *zap.Logger) int {
+	executions.Lock()
+	executions.draining = true
+	executions.Unlock()
+	if grace <= 0 {
+		return len(runningExecutions())
+	}
+
+	log.Info("停止接受新的传输，等待执行中的客户端传输结束", zap.Duration("grace_period", grace))
+	deadline := time.Now().Add(grace)
+	nextLog := time.Now()
+	for {
+		running := runningExecutions()
+		if len(running) == 0 {
+			log.Info("执行中的客户端传输已全部结束")
+			return 0
+		}
+
+		now := time.Now()
+		if !now.Before(deadline) {
+			log.Warn("等待超时，强制停止执行中的客户端传输", zap.Int("remaining", len(running)))
+			logRunning(log, running)
+			return len(running)
+		}
+		if !now.Before(nextLog) {
+			log.Info("等待执行中的客户端传输结束",
+				zap.Int("remaining", len(running)),
+				zap.Duration("time_left", deadline.Sub(now).Round(time.Second)),
+			)
+			logRunning(log, running)
+			nextLog = now.Add(shutdownLogInterval)
+		}
+		time.Sleep(shutdownPollInterval)
+	}
+}
+
+// StopExecutions 停止本进程中所有正在执行或等待延后任务的客户端传输
+func StopExecutions() {
+	executions.Lock()
+	defer executions.Unlock()
+	for _, cancel := range executions.cancels {
+		cancel()
+	}
+}
+
+// ResumeExecutions 重新接受新的客户端传输，统一模式运行时切换回客户端角色时调用
+func ResumeExecutions() {
+	executions.Lock()
+	defer executions.Unlock()
+	executions.draining = false
+}
+
+// runningExecutions 获取执行中的客户端传输的进度
+func runningExecutions() map[string]*models.ProgressReport {
+	executions.Lock()
+	defer executions.Unlock()
+
+	running := make(map[string]*models.ProgressReport, len(executions.running))
+	for taskID, progress := range executions.running {
+		running[taskID] = progress.report(models.HeartbeatRunning)
+	}
+	return running
+}
+
+// logRunning 记录执行中的客户端传输的进度
+func logRunning(log *zap.Logger, running map[string]*models.ProgressReport) {
+	for taskID, report := range running {
+		log.Info("执行中的客户端传输",
+			zap.String("task_id", taskID),
+			zap.Int64("bytes_transferred", report.BytesTransferred),
+			zap.Int64("total_bytes", report.TotalBytes),
+		)
+	}
+}
@@ -0,0 +1,454 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/uring"
+	"rdma-burst/pkg/logger"
+)
+
+// 本地暂存参数
+const (
+	stageBufferSize    = 4 << 20 // 复制缓冲区大小
+	stageQueueDepth    = 8       // io_uring 默认同时在途的读请求数
+	maxStageJobHistory = 200     // 保留的已结束暂存任务数
+)
+
+var (
+	// ErrStageSourceNotAllowed 源文件不在 transfer.stage_source_dirs 中，或未配置允许的源目录
+	ErrStageSourceNotAllowed = errors.New("源文件不在允许暂存的目录中")
+
+	// ErrStageJobNotFound 暂存任务不存在
+	ErrStageJobNotFound = errors.New("暂存任务不存在")
+)
+
+// stageJob 执行中的本地暂存任务
+type stageJob struct {
+	job    *models.StageJob
+	start  time.Time
+	cancel context.CancelFunc
+}
+
+// FileStager 把服务端本机的文件复制、硬链接或 reflink 到传输模式目录，替代传输前手动 cp
+// 复制写入目标目录中的临时文件，完成后按冲突策略原子地移动到目标位置，失败或取消时不会留下不完整的目标文件
+type FileStager struct {
+	settings *models.TransferSettings
+	mu       sync.RWMutex
+	jobs     map[string]*stageJob
+	order    []string // 按创建顺序排列的任务 ID
+	logger   *zap.Logger
+}
+
+// NewFileStager 创建本地暂存器
+func NewFileStager(settings *models.TransferSettings) *FileStager {
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+	return &FileStager{
+		settings: settings,
+		jobs:     make(map[string]*stageJob),
+		logger:   logger.GetLogger().Named(logger.ComponentTransfer).Named("stage"),
+	}
+}
+
+// Stage 检查请求并在后台开始暂存，返回任务
+func (s *FileStager) Stage(ctx context.Context, req *models.StageFileRequest) (*models.StageJob, error) {
+	method := req.Method
+	switch method {
+	case "":
+		method = models.StageMethodAuto
+	case models.StageMethodAuto, models.StageMethodCopy, models.StageMethodLink, models.StageMethodReflink:
+	default:
+		return nil, fmt.Errorf("不支持的暂存方式: %s", req.Method)
+	}
+	switch req.OnConflict {
+	case "", models.ConflictFail, models.ConflictOverwrite, models.ConflictRename, models.ConflictVersion:
+	default:
+		return nil, fmt.Errorf("不支持的冲突策略: %s", req.OnConflict)
+	}
+
+	source, err := s.checkSource(req.Source)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("读取源文件失败: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("源文件不是普通文件: %s", req.Source)
+	}
+
+	if _, ok := modeDir(s.settings, req.Mode); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModeDisabled, req.Mode)
+	}
+	name := req.Filename
+	if name == "" {
+		name = filepath.Base(source)
+	}
+	target, err := ServerFilePath(s.settings, req.Mode, name)
+	if err != nil {
+		return nil, err
+	}
+	// 内存模式目录位于网络文件系统时，只有 warn 策略允许暂存
+	if dir, fstype := memoryModeOnNetwork(s.settings, req.Mode); fstype != "" && networkFSPolicy(s.settings) != models.NetworkFSWarn {
+		return nil, fmt.Errorf("%w: %s 模式目录 %s 位于 %s", ErrNetworkFilesystem, req.Mode, dir, fstype)
+	}
+	if err := checkFileSize(s.settings, &models.TransferRequest{Filename: name, Mode: req.Mode, Size: info.Size()}); err != nil {
+		return nil, err
+	}
+	policy := req.OnConflict
+	if policy == "" {
+		policy = models.ConflictOverwrite
+	}
+	if policy == models.ConflictFail {
+		if _, err := os.Lstat(target); err == nil {
+			return nil, fmt.Errorf("%w: %s", ErrFileExists, filepath.Base(target))
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("创建目标目录失败: %v", err)
+	}
+
+	now := time.Now()
+	job := &models.StageJob{
+		ID:         fmt.Sprintf("stage_%d", now.UnixNano()),
+		Source:     source,
+		Mode:       req.Mode,
+		Target:     target,
+		Method:     method,
+		Status:     models.StatusPending,
+		TotalBytes: info.Size(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		job.Owner = principal.Name
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.mu.Lock()
+	s.jobs[job.ID] = &stageJob{job: job, start: now, cancel: cancel}
+	s.order = append(s.order, job.ID)
+	s.pruneLocked()
+	snapshot := *job
+	s.mu.Unlock()
+
+	go s.run(runCtx, job.ID, policy)
+	return &snapshot, nil
+}
+
+// Get 获取暂存任务
+func (s *FileStager) Get(id string) (*models.StageJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStageJobNotFound, id)
+	}
+	snapshot := *j.job
+	return &snapshot, nil
+}
+
+// List 列出暂存任务，最新的在前
+func (s *FileStager) List() []*models.StageJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]*models.StageJob, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		snapshot := *s.jobs[s.order[i]].job
+		jobs = append(jobs, &snapshot)
+	}
+	return jobs
+}
+
+// Cancel 取消未结束的暂存任务，已写入的临时文件会被删除
+func (s *FileStager) Cancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStageJobNotFound, id)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(j.job.Owner) {
+		return fmt.Errorf("%w: %s", ErrNotOwner, id)
+	}
+	if stageFinished(j.job.Status) {
+		return fmt.Errorf("暂存任务已结束: %s", j.job.Status)
+	}
+	j.cancel()
+	s.finishLocked(j, models.StatusCancelled, "暂存已取消", "")
+	return nil
+}
+
+// checkSource 检查源文件是否位于允许暂存的目录中，返回解析符号链接后的绝对路径
+func (s *FileStager) checkSource(source string) (string, error) {
+	if !filepath.IsAbs(source) {
+		return "", fmt.Errorf("源文件必须是绝对路径: %s", source)
+	}
+	if len(s.settings.StageSourceDirs) == 0 {
+		return "", fmt.Errorf("%w: 未配置 transfer.stage_source_dirs", ErrStageSourceNotAllowed)
+	}
+
+	// 按真实路径比较，避免通过符号链接或 .. 读取允许目录之外的文件
+	resolved, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return "", fmt.Errorf("读取源文件失败: %v", err)
+	}
+	for _, dir := range s.settings.StageSourceDirs {
+		allowed, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(allowed, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrStageSourceNotAllowed, source)
+}
+
+// run 执行暂存：硬链接、reflink 或复制到临时文件，完成后按冲突策略移动到目标位置
+func (s *FileStager) run(ctx context.Context, id, policy string) {
+	s.mu.Lock()
+	j := s.jobs[id]
+	if j == nil || stageFinished(j.job.Status) {
+		s.mu.Unlock()
+		return
+	}
+	job := j.job
+	source, target, method := job.Source, job.Target, job.Method
+	job.Status = models.StatusInProgress
+	job.UpdatedAt = time.Now()
+	s.mu.Unlock()
+
+	staged := filepath.Join(filepath.Dir(target), fmt.Sprintf(".%s.%s", filepath.Base(target), id))
+	defer os.Remove(staged)
+
+	var err error
+	if method == models.StageMethodAuto || method == models.StageMethodLink {
+		err = os.Link(source, staged)
+		switch {
+		case err == nil:
+			method = models.StageMethodLink
+		case method == models.StageMethodAuto:
+			// 无法硬链接时（跨文件系统或受 protected_hardlinks 限制）尝试 reflink
+			s.logger.Debug("硬链接源文件失败，尝试 reflink", zap.String("id", id), zap.Error(err))
+			err = nil
+		default:
+			err = fmt.Errorf("硬链接源文件失败: %v", err)
+		}
+	}
+	if err == nil && (method == models.StageMethodAuto || method == models.StageMethodReflink) {
+		err = reflink(source, staged)
+		switch {
+		case err == nil:
+			method = models.StageMethodReflink
+		case method == models.StageMethodAuto:
+			// 跨文件系统（例如磁盘到 tmpfs/hugetlbfs）或文件系统不支持 reflink，改为复制
+			s.logger.Debug("reflink 源文件失败，改为复制", zap.String("id", id), zap.Error(err))
+			method, err = models.StageMethodCopy, nil
+		default:
+			err = fmt.Errorf("reflink 源文件失败: %v", err)
+		}
+	}
+	s.mu.Lock()
+	job.Method = method
+	s.mu.Unlock()
+
+	if err == nil && method == models.StageMethodCopy {
+		err = s.copyFile(ctx, j, source, staged)
+	}
+	final := target
+	if err == nil && ctx.Err() == nil {
+		final, err = finalizeTarget(staged, target, policy)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case stageFinished(job.Status):
+		// 已取消
+	case err != nil:
+		s.finishLocked(j, models.StatusFailed, "暂存失败", err.Error())
+		s.logger.Error("本地暂存失败", zap.String("id", id), zap.String("source", source), zap.Error(err))
+	default:
+		job.Target = final
+		job.BytesCopied = job.TotalBytes
+		job.Progress = 100
+		s.finishLocked(j, models.StatusCompleted, fmt.Sprintf("已%s到 %s", methodLabel(method), final), "")
+		s.logger.Info("本地暂存完成",
+			zap.String("id", id),
+			zap.String("source", source),
+			zap.String("target", final),
+			zap.String("method", method),
+			zap.Int64("bytes", job.TotalBytes),
+		)
+	}
+}
+
+// copyFile 复制源文件到临时文件并更新进度
+func (s *FileStager) copyFile(ctx context.Context, j *stageJob, source, staged string) error {
+	s.mu.RLock()
+	id, size := j.job.ID, j.job.TotalBytes
+	s.mu.RUnlock()
+	return stageCopy(ctx, s.settings.StageIO, source, staged, size,
+		func(engine string) { s.setIOEngine(j, engine) },
+		func(copied int64) { s.updateProgress(j, copied) },
+		s.logger.With(zap.String("id", id)),
+	)
+}
+
+// stageCopy 把源文件的 size 字节复制到新建的 staged 文件，本地暂存和流水线的传输前暂存共用
+// io_uring 同时保持多个读请求在途，不可用时（内核版本、seccomp 或 kernel.io_uring_disabled）按配置改用同步读写；
+// 开始复制时通过 engine 报告实际使用的 I/O 方式，progress 报告已复制的字节数
+func stageCopy(ctx context.Context, settings models.StageIOSettings, source, staged string, size int64, engine func(string), progress func(int64), log *zap.Logger) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("打开源文件失败: %v", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(staged, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("创建目标文件失败: %v", err)
+	}
+	defer out.Close()
+
+	if settings.Engine != models.StageIOSync {
+		depth := settings.QueueDepth
+		if depth <= 0 {
+			depth = stageQueueDepth
+		}
+		err := uring.Copy(ctx, in, out, size, stageBufferSize, depth, progress)
+		switch {
+		case err == nil:
+			engine(models.StageIOURing)
+			if err := out.Close(); err != nil {
+				return fmt.Errorf("写入目标文件失败: %v", err)
+			}
+			return nil
+		case !errors.Is(err, uring.ErrUnsupported):
+			return err
+		case settings.Engine == models.StageIOURing:
+			return fmt.Errorf("本地暂存配置为 io_uring: %v", err)
+		}
+		log.Debug("io_uring 不可用，使用同步读写", zap.Error(err))
+	}
+	engine(models.StageIOSync)
+
+	buf := make([]byte, stageBufferSize)
+	var copied int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				return fmt.Errorf("写入目标文件失败: %v", err)
+			}
+			copied += int64(n)
+			progress(copied)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("读取源文件失败: %v", readErr)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("写入目标文件失败: %v", err)
+	}
+	return nil
+}
+
+// setIOEngine 记录复制使用的 I/O 方式
+func (s *FileStager) setIOEngine(j *stageJob, engine string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.job.IOEngine = engine
+}
+
+// updateProgress 更新已复制字节数、进度和速率
+func (s *FileStager) updateProgress(j *stageJob, copied int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := j.job
+	job.BytesCopied = copied
+	if job.TotalBytes > 0 {
+		job.Progress = float64(copied) / float64(job.TotalBytes) * 100
+	}
+	if elapsed := time.Since(j.start).Seconds(); elapsed > 0 {
+		job.CopyRate = float64(copied) / (1024 * 1024) / elapsed
+	}
+	job.UpdatedAt = time.Now()
+}
+
+// finishLocked 结束暂存任务（调用方需持有锁）
+func (s *FileStager) finishLocked(j *stageJob, status, message, errMsg string) {
+	now := time.Now()
+	j.job.Status = status
+	j.job.Message = message
+	j.job.Error = errMsg
+	j.job.UpdatedAt = now
+	j.job.EndTime = &now
+}
+
+// pruneLocked 只保留最近的 maxStageJobHistory 个已结束任务（调用方需持有锁）
+func (s *FileStager) pruneLocked() {
+	var finished []string
+	for _, id := range s.order {
+		if stageFinished(s.jobs[id].job.Status) {
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) <= maxStageJobHistory {
+		return
+	}
+
+	remove := make(map[string]bool)
+	for _, id := range finished[:len(finished)-maxStageJobHistory] {
+		remove[id] = true
+		delete(s.jobs, id)
+	}
+	order := s.order[:0]
+	for _, id := range s.order {
+		if !remove[id] {
+			order = append(order, id)
+		}
+	}
+	s.order = order
+}
+
+// stageFinished 暂存任务是否已结束
+func stageFinished(status string) bool {
+	return status == models.StatusCompleted || status == models.StatusFailed || status == models.StatusCancelled
+}
+
+// methodLabel 暂存方式的描述
+func methodLabel(method string) string {
+	switch method {
+	case models.StageMethodLink:
+		return "硬链接"
+	case models.StageMethodReflink:
+		return "克隆"
+	}
+	return "复制"
+}
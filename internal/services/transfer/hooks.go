@@ -0,0 +1,72 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/hook"
+)
+
+// runPostHooks 传输成功后执行配置的钩子，并将结果记录到任务中
+// 钩子失败不改变任务状态，失败数量记录在任务消息中
+func (ts *TransferService) runPostHooks(ctx context.Context, task *models.TransferTask) {
+	ts.mu.RLock()
+	settings := ts.serverConfig
+	log := ts.logger
+	ts.mu.RUnlock()
+	if settings == nil || len(settings.PostHooks) == 0 {
+		return
+	}
+
+	results := hook.Run(ctx, settings.PostHooks, task)
+
+	ts.mu.Lock()
+	task.Hooks = results
+	if failed := hook.Failed(results); failed > 0 {
+		task.Message = fmt.Sprintf("传输完成，%d 个钩子执行失败", failed)
+	}
+	task.UpdatedAt = time.Now()
+	ts.mu.Unlock()
+
+	logHookResults(log.With(zap.String("task_id", task.ID)), results)
+}
+
+// runPostHooks 客户端传输成功后执行客户端配置的钩子
+func (cts *ClientTransferService) runPostHooks(ctx context.Context, req *models.TransferRequest, taskID string, log *zap.Logger) {
+	if cts.config == nil || len(cts.config.PostHooks) == 0 {
+		return
+	}
+
+	now := time.Now()
+	task := &models.TransferTask{
+		ID:        taskID,
+		Filename:  req.Filename,
+		Mode:      req.Mode,
+		Direction: req.Direction,
+		Type:      req.Type,
+		Profile:   req.Profile,
+		QoS:       req.QoS,
+		Status:    models.StatusCompleted,
+		Progress:  100,
+		EndTime:   &now,
+		Labels:    req.Labels,
+		Metadata:  req.Metadata,
+		UpdatedAt: now,
+	}
+	logHookResults(log, hook.Run(ctx, cts.config.PostHooks, task))
+}
+
+// logHookResults 记录钩子执行结果
+func logHookResults(log *zap.Logger, results []models.HookResult) {
+	for _, result := range results {
+		if result.Success {
+			log.Info("钩子执行成功", zap.String("hook", result.Name), zap.Duration("duration", result.Duration))
+		} else {
+			log.Error("钩子执行失败", zap.String("hook", result.Name), zap.Duration("duration", result.Duration), zap.String("error", result.Error))
+		}
+	}
+}
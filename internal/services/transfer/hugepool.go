@@ -0,0 +1,50 @@
+package transfer
+
+import (
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/hugepool"
+)
+
+// SetHugepagePool 设置大页缓冲池，hugepages 模式的 put 传输准备时从缓冲池租用接收文件所需的大页，任务结束后归还
+func (ts *TransferService) SetHugepagePool(pool *hugepool.Pool) {
+	ts.mu.Lock()
+	ts.hugepool = pool
+	ts.mu.Unlock()
+	pool.SetLeaseCheck(ts.taskRunning)
+}
+
+// taskRunning 任务是否仍在执行（准备就绪、等待客户端或传输中），任务不存在时返回 false
+func (ts *TransferService) taskRunning(id string) bool {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for i := len(ts.taskHistory) - 1; i >= 0; i-- {
+		if task := ts.taskHistory[i]; task.ID == id {
+			return !task.IsFinished()
+		}
+	}
+	return false
+}
+
+// leaseHugepages hugepages 模式的 put 传输按文件大小从大页缓冲池租用大页，释放的大页由随后接收的文件使用
+// 缓冲池不足时仍继续准备，接收文件按原来的方式向系统申请大页
+func (ts *TransferService) leaseHugepages(task *models.TransferTask, req *models.TransferRequest) {
+	ts.mu.RLock()
+	pool := ts.hugepool
+	ts.mu.RUnlock()
+	if pool == nil || req.Mode != models.ModeHugepages || req.Direction != models.DirectionPut || req.Size <= 0 {
+		return
+	}
+
+	leased, ok := pool.Lease(task.ID, req.Size)
+	if !ok {
+		ts.logger.Warn("大页缓冲池预留的分段不足",
+			zap.String("task_id", task.ID),
+			zap.Int64("size", req.Size),
+			zap.Int64("leased", leased),
+		)
+		return
+	}
+	ts.logger.Debug("从大页缓冲池租用大页", zap.String("task_id", task.ID), zap.Int64("leased", leased))
+}
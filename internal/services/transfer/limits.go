@@ -0,0 +1,192 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+)
+
+// directionLimit 获取传输方向的并发上限，为 0 表示不单独限制
+func directionLimit(settings *models.TransferSettings, direction string) int {
+	switch direction {
+	case models.DirectionPut:
+		return settings.MaxConcurrentPuts
+	case models.DirectionGet:
+		return settings.MaxConcurrentGets
+	}
+	return 0
+}
+
+// modeLimit 获取传输模式的并发上限，为 0 表示不单独限制
+func modeLimit(settings *models.TransferSettings, mode string) int {
+	config, _ := modeConfig(settings, mode)
+	return config.MaxConcurrent
+}
+
+// checkTaskLimits 检查传输方向和传输模式的并发上限，live 为占用并发名额的任务
+func checkTaskLimits(settings *models.TransferSettings, direction, mode string, live map[string]*models.TransferTask) error {
+	if settings == nil {
+		return nil
+	}
+	if limit := directionLimit(settings, direction); limit > 0 {
+		count := countTasks(live, func(task *models.TransferTask) bool { return task.Direction == direction })
+		if count >= limit {
+			return fmt.Errorf("%w: %s 任务最多同时运行 %d 个", ErrConcurrencyLimit, direction, limit)
+		}
+	}
+	if limit := modeLimit(settings, mode); limit > 0 {
+		count := countTasks(live, func(task *models.TransferTask) bool { return task.Mode == mode })
+		if count >= limit {
+			return fmt.Errorf("%w: %s 模式最多同时运行 %d 个任务", ErrConcurrencyLimit, mode, limit)
+		}
+	}
+	return nil
+}
+
+// admitTask 检查新提交的任务是否超过全局、调用方、传输方向、传输模式的并发上限和 QoS 等级的并发份额，已排队的任务一起计数，超过上限时拒绝，调用方需持有锁
+// 记录调用方的并发上限，排队和延后的任务启动时按任务的 owner 检查
+func (ts *TransferService) admitTask(ctx context.Context, task *models.TransferTask) error {
+	if principal, ok := auth.FromContext(ctx); ok && task.Owner != "" {
+		if principal.MaxConcurrentTasks > 0 {
+			if ts.ownerLimits == nil {
+				ts.ownerLimits = make(map[string]int)
+			}
+			ts.ownerLimits[task.Owner] = principal.MaxConcurrentTasks
+		} else {
+			delete(ts.ownerLimits, task.Owner)
+		}
+	}
+	if err := ts.checkAdmission(task, true); err != nil {
+		// 自动调整并发上限按被拒绝的请求判断是否有请求等待名额
+		ts.autoscale.rejected.Add(1)
+		return err
+	}
+	return nil
+}
+
+// checkAdmission 检查任务是否超过全局（自动调整时为生效的上限）、调用方、传输方向、传输模式的并发上限和 QoS 等级的并发份额，调用方需持有锁
+// queued 为 true 时（新提交的任务）设备调度队列中排队的任务一起计数；从队列或后台启动的任务只与已占用名额的任务计数，
+// 排队的任务不会互相阻塞
+func (ts *TransferService) checkAdmission(task *models.TransferTask, queued bool) error {
+	live := ts.liveTasks(task.ID, queued)
+	if ts.maxConcurrent > 0 && len(live) >= ts.maxConcurrent {
+		return fmt.Errorf("%w (%d)", ErrConcurrencyLimit, ts.maxConcurrent)
+	}
+	if limit := ts.ownerLimits[task.Owner]; task.Owner != "" && limit > 0 {
+		if countTasks(live, func(t *models.TransferTask) bool { return t.Owner == task.Owner }) >= limit {
+			return fmt.Errorf("%w: 调用方 %s 最多同时运行 %d 个任务", ErrConcurrencyLimit, task.Owner, limit)
+		}
+	}
+	if err := checkTaskLimits(ts.serverConfig, task.Direction, task.Mode, live); err != nil {
+		return err
+	}
+	return ts.checkQoSLimit(task.QoS, live)
+}
+
+// liveTasks 获取占用并发名额的任务（exclude 除外）：准备就绪和执行中的会话、正在准备传输环境和正在拉取源文件的任务，
+// queued 为 true 时包括设备调度队列中排队的任务，调用方需持有锁
+func (ts *TransferService) liveTasks(exclude string, queued bool) map[string]*models.TransferTask {
+	live := make(map[string]*models.TransferTask)
+	for _, tw := range ts.activeTasks {
+		live[tw.Task.ID] = tw.Task
+	}
+	for _, session := range ts.sessions {
+		if session.endedAt == nil && !session.task.IsFinished() {
+			live[session.task.ID] = session.task
+		}
+	}
+	for _, work := range ts.staging {
+		if work.task.Status == models.StatusStaging {
+			live[work.task.ID] = work.task
+		}
+	}
+	for _, q := range ts.devices {
+		for id, task := range q.starting {
+			live[id] = task
+		}
+		if queued {
+			for _, waiting := range q.waiting {
+				live[waiting.task.ID] = waiting.task
+			}
+		}
+	}
+	delete(live, exclude)
+	return live
+}
+
+// countTasks 统计满足条件的任务数
+func countTasks(tasks map[string]*models.TransferTask, match func(task *models.TransferTask) bool) int {
+	count := 0
+	for _, task := range tasks {
+		if match(task) {
+			count++
+		}
+	}
+	return count
+}
+
+// maxFileSize 获取传输模式的单个文件大小上限，为 0 表示不限制
+// 同时配置字节数和内存百分比时取较小值，无法获取物理内存大小时忽略百分比
+func maxFileSize(settings *models.TransferSettings, mode string) int64 {
+	config, _ := modeConfig(settings, mode)
+	limit := config.MaxFileSize
+	if config.MaxFileSizeMemoryPct > 0 {
+		if total := totalMemory(); total > 0 {
+			memoryLimit := int64(float64(total) * config.MaxFileSizeMemoryPct / 100)
+			if limit == 0 || memoryLimit < limit {
+				limit = memoryLimit
+			}
+		}
+	}
+	return limit
+}
+
+// fillFileSize get 请求使用服务端文件的大小，文件不存在时为 0（由 rtranfile 报告）
+func fillFileSize(settings *models.TransferSettings, req *models.TransferRequest) {
+	if req.Direction != models.DirectionGet {
+		return
+	}
+	req.Size = 0
+	dir, _ := modeDir(settings, req.Mode)
+	if info, err := os.Stat(filepath.Join(dir, filepath.Base(req.Filename))); err == nil && !info.IsDir() {
+		req.Size = info.Size()
+	}
+}
+
+// checkFileSize 检查文件是否超过传输模式的大小上限，文件大小未知时不检查
+func checkFileSize(settings *models.TransferSettings, req *models.TransferRequest) error {
+	limit := maxFileSize(settings, req.Mode)
+	if limit > 0 && req.Size > limit {
+		return fmt.Errorf("%w: %s 大小 %d 字节，%s 模式上限 %d 字节", ErrFileTooLarge, req.Filename, req.Size, req.Mode, limit)
+	}
+	return nil
+}
+
+// totalMemory 读取物理内存大小（字节），失败时返回 0
+func totalMemory() int64 {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+// ModeAvailability 返回各传输模式是否启用及当前能否接受传输：目录剩余空间、大页缓冲池预留的字节数、
+// 该模式的活跃任务数和监听进程状态，自动提交传输的调用方可以据此选择现在就能成功的模式
+func (ts *TransferService) ModeAvailability() *models.TransferModesResponse {
+	settings := ts.serverConfig
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+	response := ListModes(settings)
+	now := time.Now()
+	response.CheckedAt = &now
+
+	// 服务正在关闭或 RDMA 设备不可用时所有模式都不可用
+	serviceErr := ts.checkDraining()
+	if serviceErr == nil {
+		serviceErr = ts.checkDevice()
+	}
+
+	for i := range response.Modes {
+		info := &response.Modes[i]
+		availability := ts.modeAvailability(settings, info)
+		if availability.Available && serviceErr != nil {
+			availability.Available = false
+			availability.Reason = serviceErr.Error()
+		}
+		info.Availability = availability
+		if availability.Available {
+			response.Available = append(response.Available, info.Mode)
+		}
+	}
+	if len(response.Available) > 0 {
+		response.Available = append(response.Available, models.ModeAuto)
+	}
+	return response
+}
+
+// modeAvailability 检查单个传输模式当前能否接受传输
+func (ts *TransferService) modeAvailability(settings *models.TransferSettings, info *models.TransferModeInfo) *models.ModeAvailability {
+	availability := &models.ModeAvailability{
+		Listener: models.ListenerStopped,
+		Warm:     ts.isWarm(info.Mode),
+	}
+	if ts.listenerRunning(info.Mode) {
+		availability.Listener = models.ListenerRunning
+	}
+
+	ts.mu.RLock()
+	availability.Active = countTasks(ts.liveTasks("", false), func(task *models.TransferTask) bool { return task.Mode == info.Mode })
+	pool := ts.hugepool
+	ts.mu.RUnlock()
+	if pool != nil && info.Mode == models.ModeHugepages {
+		availability.ReservedBytes = pool.Status().ReservedBytes
+	}
+
+	dir, ok := modeDir(settings, info.Mode)
+	if !ok {
+		availability.Reason = "未启用"
+		return availability
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		// 文件系统模式的目录在传输前创建
+		if info.Mode != models.ModeFilesystem || !os.IsNotExist(err) {
+			availability.Reason = fmt.Sprintf("目录 %s 不可用: %v", dir, err)
+			return availability
+		}
+	} else {
+		availability.FreeBytes = int64(stat.Bavail) * int64(stat.Bsize)
+		availability.TotalBytes = int64(stat.Blocks) * int64(stat.Bsize)
+		if availability.FreeBytes+availability.ReservedBytes == 0 {
+			availability.Reason = "剩余空间为 0"
+			return availability
+		}
+	}
+
+	if _, fstype := memoryModeOnNetwork(settings, info.Mode); fstype != "" {
+		availability.NetworkFS = fstype
+		if networkFSPolicy(settings) != models.NetworkFSWarn {
+			availability.Reason = fmt.Sprintf("目录位于网络文件系统 (%s)，请求按 %s 策略处理", fstype, networkFSPolicy(settings))
+			return availability
+		}
+	}
+
+	if info.MaxConcurrent > 0 && availability.Active >= info.MaxConcurrent {
+		availability.Reason = fmt.Sprintf("已达到该模式的并发上限 (%d)", info.MaxConcurrent)
+		return availability
+	}
+
+	availability.Available = true
+	return availability
+}
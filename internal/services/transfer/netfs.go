@@ -0,0 +1,86 @@
+package transfer
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+)
+
+// networkFSPolicy 获取内存模式目录位于网络文件系统时的处理策略，未配置时为 warn
+func networkFSPolicy(settings *models.TransferSettings) string {
+	if settings == nil || settings.NetworkFSPolicy == "" {
+		return models.NetworkFSWarn
+	}
+	return settings.NetworkFSPolicy
+}
+
+// memoryModeOnNetwork 检查内存模式（hugepages、tmpfs）的目录是否位于网络文件系统，返回目录和文件系统类型
+// 内存模式依赖 hugetlbfs/tmpfs 的页面语义，目录误配到网络挂载点时传输不会报错，但性能和数据一致性都无法保证
+func memoryModeOnNetwork(settings *models.TransferSettings, mode string) (string, string) {
+	if mode != models.ModeHugepages && mode != models.ModeTmpfs {
+		return "", ""
+	}
+	dir, ok := modeDir(settings, mode)
+	if !ok {
+		return "", ""
+	}
+	return dir, networkFilesystem(dir)
+}
+
+// applyNetworkFSPolicy 请求的内存模式目录位于网络文件系统时按策略处理
+// warn 记录警告；filesystem 将 req.Mode 替换为 filesystem 并返回选择依据；refuse 返回 ErrNetworkFilesystem
+func (ts *TransferService) applyNetworkFSPolicy(settings *models.TransferSettings, req *models.TransferRequest) (string, error) {
+	dir, fstype := memoryModeOnNetwork(settings, req.Mode)
+	if fstype == "" {
+		return "", nil
+	}
+
+	switch networkFSPolicy(settings) {
+	case models.NetworkFSRefuse:
+		return "", fmt.Errorf("%w: %s 模式目录 %s 位于 %s", ErrNetworkFilesystem, req.Mode, dir, fstype)
+	case models.NetworkFSFilesystem:
+		if _, ok := modeDir(settings, models.ModeFilesystem); !ok {
+			return "", fmt.Errorf("%w: %s 模式目录 %s 位于 %s，且 filesystem 模式未启用", ErrNetworkFilesystem, req.Mode, dir, fstype)
+		}
+		decision := fmt.Sprintf("network_fs: %s 模式目录 %s 位于 %s，改用 filesystem 模式", req.Mode, dir, fstype)
+		req.Mode = models.ModeFilesystem
+		return decision, nil
+	default:
+		ts.logger.Warn("传输模式目录位于网络文件系统",
+			zap.String("mode", req.Mode),
+			zap.String("dir", dir),
+			zap.String("fstype", fstype),
+		)
+		return "", nil
+	}
+}
+
+// joinDecision 合并模式选择依据
+func joinDecision(decision, forced string) string {
+	switch {
+	case decision == "":
+		return forced
+	case forced == "":
+		return decision
+	}
+	return decision + "；" + forced
+}
+
+// checkNetworkFilesystems 启动时检查内存模式目录，位于网络文件系统时按策略记录警告
+func (ts *TransferService) checkNetworkFilesystems(settings *models.TransferSettings) {
+	for _, mode := range []string{models.ModeHugepages, models.ModeTmpfs} {
+		dir, fstype := memoryModeOnNetwork(settings, mode)
+		if fstype == "" {
+			continue
+		}
+		policy := networkFSPolicy(settings)
+		ts.logger.Warn("内存传输模式的目录位于网络文件系统，内存模式在网络挂载上无法正常工作",
+			zap.String("mode", mode),
+			zap.String("dir", dir),
+			zap.String("fstype", fstype),
+			zap.String("policy", policy),
+		)
+	}
+}
@@ -0,0 +1,43 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// 网络和集群文件系统的 statfs f_type
+var networkFilesystems = map[int64]string{
+	0x6969:     "nfs",
+	0x0bd00bd0: "lustre",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x517b:     "smbfs",
+	0x00c36400: "ceph",
+	0x47504653: "gpfs",
+	0x01021997: "9p",
+	0x013111a8: "ibrix",
+	0x6b414653: "afs",
+}
+
+// networkFilesystem 获取 dir 所在的网络文件系统类型，不是网络文件系统或无法判断时返回空字符串
+// dir 不存在时检查最近的已存在上级目录，即传输前创建目录后所在的文件系统
+func networkFilesystem(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return ""
+	}
+	return networkFilesystems[int64(uint32(stat.Type))]
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package transfer
+
+// networkFilesystem 非 Linux 平台无法按 statfs 类型判断，视为本地文件系统
+func networkFilesystem(dir string) string {
+	return ""
+}
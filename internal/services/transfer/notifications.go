@@ -0,0 +1,37 @@
+package transfer
+
+import (
+	"time"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/notify"
+)
+
+// SetNotifier 设置任务事件通知器
+func (ts *TransferService) SetNotifier(notifier *notify.Notifier) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.notifier = notifier
+}
+
+// notifyFailed 任务失败时发送通知，调用方需持有锁
+func (ts *TransferService) notifyFailed(task *models.TransferTask) {
+	if ts.notifier != nil && task.Status == models.StatusFailed {
+		ts.notifier.TaskFailed(task)
+	}
+}
+
+// StalledTasks 获取超过 timeout 没有进度更新的活跃任务快照
+func (ts *TransferService) StalledTasks(timeout time.Duration) []*models.TransferTask {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	var stalled []*models.TransferTask
+	for _, taskWrapper := range ts.activeTasks {
+		if time.Since(taskWrapper.Task.UpdatedAt) > timeout {
+			snapshot := *taskWrapper.Task
+			stalled = append(stalled, &snapshot)
+		}
+	}
+	return stalled
+}
@@ -0,0 +1,75 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/manifest"
+)
+
+// partialSuffix 获取接收端传输期间使用的临时文件后缀，为空表示直接写入目标文件
+func partialSuffix(settings *models.TransferSettings) string {
+	if settings == nil {
+		return ""
+	}
+	return settings.PartialSuffix
+}
+
+// partialTarget 获取 put 请求的客户端发送使用的文件名：启用临时后缀时为目标文件名加后缀，否则为 target
+// target 为按冲突策略选择的文件名，与请求的文件名相同时为空
+func partialTarget(settings *models.TransferSettings, req *models.TransferRequest, target string) string {
+	suffix := partialSuffix(settings)
+	if suffix == "" || req.Direction != models.DirectionPut || req.IsVerify() {
+		return target
+	}
+	name := target
+	if name == "" {
+		name = getFileName(req.Filename)
+	}
+	return name + suffix
+}
+
+// completeReceived 把 put 接收的临时文件原子地重命名为目标文件，校验时保存的分块清单随文件一起重命名，调用方需持有锁
+func (ts *TransferService) completeReceived(task *models.TransferTask) error {
+	suffix := partialSuffix(ts.serverConfig)
+	if suffix == "" || task.Direction != models.DirectionPut || task.TargetPath == "" {
+		return nil
+	}
+
+	partial := task.TargetPath + suffix
+	if _, err := os.Lstat(partial); os.IsNotExist(err) {
+		// 客户端没有使用临时文件名发送（例如旧版本客户端），文件已经在目标位置
+		if _, err := os.Lstat(task.TargetPath); err == nil {
+			return nil
+		}
+		return fmt.Errorf("没有找到接收的临时文件: %s", partial)
+	}
+	if err := os.Rename(partial, task.TargetPath); err != nil {
+		return fmt.Errorf("重命名接收的临时文件失败: %v", err)
+	}
+
+	if m, err := manifest.Load(manifest.PathFor(partial)); err == nil {
+		m.Filename = filepath.Base(task.TargetPath)
+		if err := manifest.Save(manifest.PathFor(task.TargetPath), m); err != nil {
+			ts.logger.Warn("重命名分块清单失败", zap.String("task_id", task.ID), zap.Error(err))
+		}
+		os.Remove(manifest.PathFor(partial))
+	}
+	return nil
+}
+
+// discardPartial 客户端上报失败、会话被取消或过期后删除 put 接收的临时文件，调用方需持有锁
+func (ts *TransferService) discardPartial(task *models.TransferTask) {
+	suffix := partialSuffix(ts.serverConfig)
+	if suffix == "" || task.Direction != models.DirectionPut || task.TargetPath == "" {
+		return
+	}
+	partial := task.TargetPath + suffix
+	if err := os.Remove(partial); err != nil && !os.IsNotExist(err) {
+		ts.logger.Warn("删除接收的临时文件失败", zap.String("task_id", task.ID), zap.String("path", partial), zap.Error(err))
+	}
+}
@@ -0,0 +1,94 @@
+package transfer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"rdma-burst/internal/models"
+)
+
+// serverSidePath 获取请求中服务端一侧的路径：put 为 destination_path，get 为 source_path
+func serverSidePath(req *models.TransferRequest) string {
+	if req.Direction == models.DirectionGet {
+		return req.SourcePath
+	}
+	return req.DestinationPath
+}
+
+// clientSidePath 获取请求中客户端一侧的路径：put 为 source_path，get 为 destination_path
+func clientSidePath(req *models.TransferRequest) string {
+	if req.Direction == models.DirectionGet {
+		return req.DestinationPath
+	}
+	return req.SourcePath
+}
+
+// remoteName 获取文件在服务端的文件名，请求未指定服务端一侧的路径时与本地文件名相同
+func remoteName(req *models.TransferRequest) string {
+	if path := serverSidePath(req); path != "" {
+		return filepath.Base(path)
+	}
+	return getFileName(req.Filename)
+}
+
+// MapRequestPaths 把请求的 source_path / destination_path 映射为本端使用的 filename
+// 客户端（server 为 false）使用客户端一侧的本地路径；服务端使用服务端一侧路径的文件名，
+// 服务端一侧的路径可以是文件名，或传输模式基础目录中的绝对路径，auto 模式按路径所在目录选择传输模式
+// 未指定本端路径时沿用 filename，filename 也为空时使用对端路径的文件名
+func MapRequestPaths(req *models.TransferRequest, settings *models.TransferSettings, server bool) error {
+	local, remote := clientSidePath(req), serverSidePath(req)
+	if server {
+		local, remote = remote, local
+	}
+
+	switch {
+	case local != "":
+		req.Filename = local
+	case req.Filename == "" && remote != "":
+		req.Filename = filepath.Base(remote)
+	}
+	if !server || local == "" {
+		return nil
+	}
+
+	// 服务端监听进程只在模式的基础目录中收发文件，不支持子目录
+	if !filepath.IsAbs(local) {
+		if strings.Contains(local, "/") || local == "." || local == ".." {
+			return fmt.Errorf("%w: 服务端路径 %s 必须是文件名或传输模式基础目录中的绝对路径", ErrInvalidPath, local)
+		}
+		return nil
+	}
+
+	dir := filepath.Dir(filepath.Clean(local))
+	mode := modeForDir(settings, dir)
+	if mode == "" {
+		return fmt.Errorf("%w: %s 不在任何传输模式的基础目录中", ErrInvalidPath, local)
+	}
+	switch req.Mode {
+	case models.ModeAuto:
+		req.Mode = mode
+	case mode:
+	default:
+		return fmt.Errorf("%w: %s 位于 %s 模式的基础目录，与请求的 %s 模式不一致", ErrInvalidPath, local, mode, req.Mode)
+	}
+	req.Filename = filepath.Base(local)
+	return nil
+}
+
+// modeForDir 获取基础目录为 dir 的传输模式，没有匹配时返回空字符串
+func modeForDir(settings *models.TransferSettings, dir string) string {
+	if settings == nil {
+		return ""
+	}
+	for mode, baseDir := range map[string]string{
+		models.ModeHugepages:  settings.Modes.Hugepages.BaseDir,
+		models.ModeTmpfs:      settings.Modes.Tmpfs.BaseDir,
+		models.ModeFilesystem: settings.Modes.Filesystem.BaseDir,
+	} {
+		if baseDir != "" && filepath.Clean(baseDir) == dir {
+			return mode
+		}
+	}
+	return ""
+}
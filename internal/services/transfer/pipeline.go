@@ -0,0 +1,539 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/filemeta"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/pkg/logger"
+)
+
+// 流水线传输参数
+const (
+	defaultPipelineLookahead = 2  // 传输前最多提前准备的文件数
+	defaultPipelineIOWorkers = 2  // 同时执行本地 I/O 阶段的文件数
+	maxPipelineHistory       = 50 // 保留的已结束流水线数
+)
+
+// ErrPipelineNotFound 流水线不存在
+var ErrPipelineNotFound = errors.New("流水线不存在")
+
+// pipelineRun 执行中的流水线
+type pipelineRun struct {
+	pipeline *models.Pipeline
+	since    []time.Time // 各文件当前阶段开始的时间
+	cancel   context.CancelFunc
+}
+
+// pipelineEntry 流水线中的单个文件，在各阶段之间传递
+type pipelineEntry struct {
+	index    int
+	req      *models.TransferRequest
+	manifest *manifest.Manifest // put 传输前计算的本地分块清单
+	staged   string             // put 传输前复制源文件的暂存目录，文件结束时删除
+	prepared chan struct{}      // 传输前的本地准备结束后关闭
+	exec     *clientExecution
+	run      *clientRun
+	untrack  func()
+	err      error
+}
+
+// PipelineRunner 客户端流水线传输：按顺序传输一组文件，把每个文件的执行拆分为传输前准备（暂存、校验和）、
+// RDMA 传输和传输后处理（校验、落盘）三段，RDMA 传输依次执行，前后两段的本地 I/O 与其他文件的传输重叠进行
+type PipelineRunner struct {
+	cts      *ClientTransferService
+	settings models.PipelineSettings
+	mu       sync.RWMutex
+	runs     map[string]*pipelineRun
+	order    []string // 按创建顺序排列的流水线 ID
+	logger   *zap.Logger
+}
+
+// NewPipelineRunner 创建流水线传输执行器
+func NewPipelineRunner(cts *ClientTransferService, settings models.PipelineSettings) *PipelineRunner {
+	if settings.Lookahead <= 0 {
+		settings.Lookahead = defaultPipelineLookahead
+	}
+	if settings.IOWorkers <= 0 {
+		settings.IOWorkers = defaultPipelineIOWorkers
+	}
+	return &PipelineRunner{
+		cts:      cts,
+		settings: settings,
+		runs:     make(map[string]*pipelineRun),
+		logger:   logger.GetLogger().Named(logger.ComponentTransfer).Named("pipeline"),
+	}
+}
+
+// Submit 检查请求并在后台开始执行流水线，返回流水线
+func (r *PipelineRunner) Submit(ctx context.Context, req *models.PipelineRequest) (*models.Pipeline, error) {
+	if err := checkExecutionsDraining(); err != nil {
+		return nil, err
+	}
+	for i := range req.Items {
+		if req.Items[i].IsVerify() {
+			return nil, fmt.Errorf("第 %d 个文件为只校验的任务，流水线只执行传输", i)
+		}
+	}
+
+	now := time.Now()
+	p := &models.Pipeline{
+		ID:        fmt.Sprintf("pipeline_%d", now.UnixNano()),
+		Name:      req.Name,
+		Status:    models.PipelineRunning,
+		Items:     make([]models.PipelineItem, len(req.Items)),
+		CreatedAt: now,
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		p.Owner = principal.Name
+	}
+	items := make([]*models.TransferRequest, len(req.Items))
+	for i := range req.Items {
+		item := req.Items[i]
+		items[i] = &item
+		p.Items[i] = models.PipelineItem{
+			Index:     i,
+			Filename:  item.Filename,
+			Direction: item.Direction,
+			Stage:     models.PipelineStageQueued,
+		}
+	}
+
+	// 保留调用方身份（按调用方划分命名空间时使用），但不随请求结束而取消
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run := &pipelineRun{pipeline: p, since: make([]time.Time, len(items)), cancel: cancel}
+	r.mu.Lock()
+	r.runs[p.ID] = run
+	r.order = append(r.order, p.ID)
+	r.pruneLocked()
+	snapshot := snapshotPipeline(p)
+	r.mu.Unlock()
+
+	go r.execute(runCtx, run, items)
+	return snapshot, nil
+}
+
+// Get 获取流水线
+func (r *PipelineRunner) Get(id string) (*models.Pipeline, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	run, ok := r.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPipelineNotFound, id)
+	}
+	return snapshotPipeline(run.pipeline), nil
+}
+
+// List 列出流水线，最新的在前
+func (r *PipelineRunner) List() []*models.Pipeline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pipelines := make([]*models.Pipeline, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		pipelines = append(pipelines, snapshotPipeline(r.runs[r.order[i]].pipeline))
+	}
+	return pipelines
+}
+
+// Cancel 取消未结束的流水线：正在传输的文件停止传输并向服务端上报失败，尚未开始传输的文件不再传输
+func (r *PipelineRunner) Cancel(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run, ok := r.runs[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPipelineNotFound, id)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(run.pipeline.Owner) {
+		return fmt.Errorf("%w: %s", ErrNotOwner, id)
+	}
+	if run.pipeline.Status != models.PipelineRunning {
+		return fmt.Errorf("流水线已结束: %s", run.pipeline.Status)
+	}
+	run.cancel()
+	run.pipeline.Status = models.PipelineCancelled
+	return nil
+}
+
+// execute 执行流水线：准备协程按顺序提前准备至多 lookahead 个文件，当前协程依次执行 RDMA 传输，
+// 传输完成的文件交给后处理协程校验和落盘后立即开始下一个文件的传输
+func (r *PipelineRunner) execute(ctx context.Context, run *pipelineRun, items []*models.TransferRequest) {
+	log := r.logger.With(zap.String("pipeline_id", run.pipeline.ID))
+	log.Info("开始执行流水线传输", zap.Int("files", len(items)))
+
+	queue := make(chan *pipelineEntry, r.settings.Lookahead)  // 按顺序等待传输
+	prepare := make(chan *pipelineEntry)                      // 等待传输前准备
+	finish := make(chan *pipelineEntry, r.settings.Lookahead) // 等待校验和落盘
+
+	go func() {
+		defer close(prepare)
+		defer close(queue)
+		for i, req := range items {
+			e := &pipelineEntry{index: i, req: req, prepared: make(chan struct{})}
+			select {
+			case queue <- e:
+			case <-ctx.Done():
+				return
+			}
+			prepare <- e
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.settings.IOWorkers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for e := range prepare {
+				r.prepare(ctx, run, e, log)
+				close(e.prepared)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for e := range finish {
+				r.finish(ctx, run, e)
+			}
+		}()
+	}
+
+	for e := range queue {
+		<-e.prepared
+		if e.err == nil {
+			r.transfer(ctx, run, e)
+		}
+		if e.err != nil {
+			r.discardStaged(e, log)
+			r.endItem(ctx, run, e.index, e.err)
+			continue
+		}
+		finish <- e
+	}
+	close(finish)
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	p := run.pipeline
+	for i := range p.Items {
+		if p.Items[i].Stage == models.PipelineStageQueued || p.Items[i].Stage == models.PipelineStageReady {
+			p.Items[i].Stage = models.PipelineStageCancelled
+		}
+	}
+	if p.Status == models.PipelineRunning {
+		p.Status = models.PipelineCompleted
+	}
+	p.CompletedAt = &now
+	run.cancel()
+	log.Info("流水线传输结束",
+		zap.String("status", p.Status),
+		zap.Int("completed", p.Completed),
+		zap.Int("failed", p.Failed),
+	)
+}
+
+// prepare 传输前准备：put 从对象存储拉取源文件，或配置了暂存目录时用 io_uring 把源文件复制到暂存目录，
+// 与前一个文件的 RDMA 传输重叠进行；启用分块清单时计算本地清单，服务端校验时不再重新读取文件
+// 启用校验和时在拉取或计算清单的同时计算源文件摘要，随创建任务的请求交给服务端，源文件只读取一次
+func (r *PipelineRunner) prepare(ctx context.Context, run *pipelineRun, e *pipelineEntry, log *zap.Logger) {
+	if ctx.Err() != nil {
+		e.err = ctx.Err()
+		return
+	}
+	if e.req.Direction == models.DirectionPut {
+		digest, err := r.cts.newDigest()
+		if err != nil {
+			e.err = err
+			return
+		}
+		if e.req.Checksum != nil {
+			digest = nil
+		}
+		pulled := false
+		if sourcePull(e.req, false) != nil {
+			r.setStage(run, e.index, models.PipelineStageStaging)
+			if err := checkStaging(r.cts.config, e.req, false); err != nil {
+				e.err = err
+				return
+			}
+			if err := r.cts.pullSource(ctx, e.req, digest, log); err != nil {
+				e.err = err
+				return
+			}
+			// 源文件已在本地，传输阶段不再拉取
+			staged := *e.req
+			spec := *e.req.Staging
+			spec.Pull = nil
+			staged.Staging = &spec
+			e.req = &staged
+			pulled = true
+		} else if r.settings.StageDir != "" {
+			r.setStage(run, e.index, models.PipelineStageStaging)
+			if err := r.stageLocal(ctx, run, e, log); err != nil {
+				e.err = err
+				return
+			}
+		}
+		buildManifest := r.cts.config != nil && r.cts.config.ChunkManifest
+		if buildManifest || (digest != nil && !pulled) {
+			r.setStage(run, e.index, models.PipelineStageChecksum)
+			// 拉取时已计算摘要，计算清单时不再写入
+			var sourceDigest hash.Hash
+			if !pulled {
+				sourceDigest = digest
+			}
+			m, err := hashSource(ctx, e.req.Filename, sourceDigest, buildManifest)
+			if err != nil {
+				e.err = err
+				return
+			}
+			e.manifest = m
+		}
+		if digest != nil {
+			e.req = r.cts.withChecksum(e.req, digest)
+		}
+	}
+	r.setStage(run, e.index, models.PipelineStageReady)
+}
+
+// transfer 在服务端创建任务并执行 RDMA 传输，服务端延后的任务等待准备就绪后执行
+// 成功时执行中的任务继续上报进度，由 finish 校验、落盘并上报结果
+func (r *PipelineRunner) transfer(ctx context.Context, run *pipelineRun, e *pipelineEntry) {
+	if ctx.Err() != nil {
+		e.err = ctx.Err()
+		return
+	}
+	r.setStage(run, e.index, models.PipelineStageTransfer)
+	resp, req, err := r.cts.createTask(ctx, e.req)
+	if err != nil {
+		e.err = err
+		return
+	}
+	r.mu.Lock()
+	run.pipeline.Items[e.index].TaskID = resp.ID
+	r.mu.Unlock()
+
+	// 取消服务端任务时同时停止本地执行
+	taskCtx, cancel := context.WithCancel(ctx)
+	untrack := trackExecution(resp.ID, cancel)
+	if resp.Status == models.StatusDeferred || resp.Status == models.StatusStaging || resp.Status == models.StatusQueued || resp.Status == models.StatusBlocked {
+		if err := r.cts.awaitPrepared(taskCtx, req, resp.ID, resp.Status); err != nil {
+			untrack()
+			cancel()
+			// 流水线取消时服务端任务仍在等待，一并取消
+			if ctx.Err() != nil {
+				if _, cancelErr := r.cts.api.CancelTransfer(context.WithoutCancel(ctx), resp.ID); cancelErr != nil {
+					r.logger.Warn("取消服务端延后任务失败", zap.String("task_id", resp.ID), zap.Error(cancelErr))
+				}
+			}
+			e.err = err
+			return
+		}
+	}
+
+	e.exec = r.cts.beginExecution(taskCtx, req, resp.ID, resp.HeartbeatInterval)
+	for {
+		e.run, err = r.cts.runTransfer(e.exec.ctx, req, resp.TargetFilename, e.manifest, e.exec.progress, e.exec.log)
+		if r.cts.shouldRestart(e.exec, err) {
+			e.run, err = r.cts.runTransfer(e.exec.ctx, req, resp.TargetFilename, e.manifest, e.exec.progress, e.exec.log)
+		}
+		// 机会型任务被常规传输抢占后等待重新准备，从头执行
+		next, paused := r.cts.resumePreempted(taskCtx, e.exec, err)
+		if !paused {
+			break
+		}
+		if next == nil {
+			untrack()
+			cancel()
+			e.err = err
+			return
+		}
+		e.exec = next
+	}
+	e.untrack = func() {
+		untrack()
+		cancel()
+	}
+	if err != nil {
+		r.cts.endExecution(e.exec, err)
+		e.untrack()
+		e.err = err
+	}
+}
+
+// finish 传输后处理：按分块清单校验，移动到目标位置并恢复元数据，之后向服务端上报结果
+func (r *PipelineRunner) finish(ctx context.Context, run *pipelineRun, e *pipelineEntry) {
+	r.setStage(run, e.index, models.PipelineStageVerify)
+	err := r.cts.verifyTransfer(e.exec.ctx, e.run, e.exec.log)
+	if err == nil {
+		r.setStage(run, e.index, models.PipelineStageFinalize)
+		err = r.cts.finalizeTransfer(e.exec.ctx, e.run, e.exec.log)
+	}
+	e.run.close()
+	r.cts.endExecution(e.exec, err)
+	e.untrack()
+	r.discardStaged(e, r.logger.With(zap.String("pipeline_id", run.pipeline.ID)))
+	r.endItem(ctx, run, e.index, err)
+}
+
+// stageLocal 把 put 的源文件复制到暂存目录（例如 tmpfs），之后的校验和与传输都读取暂存的副本
+// 复制按 stage_io 使用 io_uring，按配置档案保留元数据时一并复制源文件的权限、修改时间和扩展属性
+func (r *PipelineRunner) stageLocal(ctx context.Context, run *pipelineRun, e *pipelineEntry, log *zap.Logger) error {
+	source := e.req.Filename
+	if e.req.SourcePath != "" {
+		source = e.req.SourcePath
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("获取源文件信息失败: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("源文件不是普通文件: %s", source)
+	}
+
+	dir := filepath.Join(r.settings.StageDir, fmt.Sprintf("%s-%d", run.pipeline.ID, e.index))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("创建暂存目录失败: %v", err)
+	}
+	e.staged = dir
+	staged := filepath.Join(dir, filepath.Base(source))
+
+	var settings models.StageIOSettings
+	if r.cts.config != nil {
+		settings = r.cts.config.StageIO
+	}
+	var engine string
+	if err := stageCopy(ctx, settings, source, staged, info.Size(),
+		func(name string) { engine = name }, func(int64) {}, log); err != nil {
+		return fmt.Errorf("暂存源文件失败: %v", err)
+	}
+	if r.cts.config != nil && e.req.Profile != "" {
+		if profile, ok := lookupProfile(r.cts.config, e.req.Profile); ok && profile.Metadata.Preserve {
+			m, err := filemeta.Capture(source, profile.Metadata.Xattrs)
+			if err != nil {
+				return fmt.Errorf("读取源文件元数据失败: %v", err)
+			}
+			if err := filemeta.Apply(staged, m); err != nil {
+				return fmt.Errorf("复制源文件元数据失败: %v", err)
+			}
+		}
+	}
+	log.Debug("源文件已暂存", zap.Int("index", e.index), zap.String("path", staged), zap.String("io_engine", engine))
+
+	req := *e.req
+	req.Filename = staged
+	if req.SourcePath != "" {
+		req.SourcePath = staged
+	}
+	e.req = &req
+	return nil
+}
+
+// discardStaged 删除文件的暂存目录
+func (r *PipelineRunner) discardStaged(e *pipelineEntry, log *zap.Logger) {
+	if e.staged == "" {
+		return
+	}
+	if err := os.RemoveAll(e.staged); err != nil {
+		log.Warn("删除暂存目录失败", zap.String("path", e.staged), zap.Error(err))
+	}
+	e.staged = ""
+}
+
+// endItem 结束流水线中的文件，流水线取消导致的失败记为已取消
+func (r *PipelineRunner) endItem(ctx context.Context, run *pipelineRun, index int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case err == nil:
+		r.setStageLocked(run, index, models.PipelineStageCompleted)
+		run.pipeline.Completed++
+	case ctx.Err() != nil && run.pipeline.Status == models.PipelineCancelled:
+		r.setStageLocked(run, index, models.PipelineStageCancelled)
+	default:
+		r.setStageLocked(run, index, models.PipelineStageFailed)
+		run.pipeline.Failed++
+		run.pipeline.Items[index].Error = err.Error()
+	}
+}
+
+// setStage 设置文件当前所处的阶段
+func (r *PipelineRunner) setStage(run *pipelineRun, index int, stage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setStageLocked(run, index, stage)
+}
+
+// setStageLocked 设置文件当前所处的阶段，记录上一个执行阶段的耗时（调用方需持有锁）
+func (r *PipelineRunner) setStageLocked(run *pipelineRun, index int, stage string) {
+	now := time.Now()
+	item := &run.pipeline.Items[index]
+	switch item.Stage {
+	case models.PipelineStageQueued, models.PipelineStageReady:
+	default:
+		if item.StageSeconds == nil {
+			item.StageSeconds = make(map[string]float64)
+		}
+		item.StageSeconds[item.Stage] += now.Sub(run.since[index]).Seconds()
+	}
+	item.Stage = stage
+	run.since[index] = now
+}
+
+// pruneLocked 只保留最近的 maxPipelineHistory 个已结束流水线（调用方需持有锁）
+func (r *PipelineRunner) pruneLocked() {
+	var finished []string
+	for _, id := range r.order {
+		if r.runs[id].pipeline.CompletedAt != nil {
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) <= maxPipelineHistory {
+		return
+	}
+
+	remove := make(map[string]bool)
+	for _, id := range finished[:len(finished)-maxPipelineHistory] {
+		remove[id] = true
+		delete(r.runs, id)
+	}
+	order := r.order[:0]
+	for _, id := range r.order {
+		if !remove[id] {
+			order = append(order, id)
+		}
+	}
+	r.order = order
+}
+
+// snapshotPipeline 复制流水线状态，返回给调用方的副本不随执行变化
+func snapshotPipeline(p *models.Pipeline) *models.Pipeline {
+	snapshot := *p
+	snapshot.Items = make([]models.PipelineItem, len(p.Items))
+	for i, item := range p.Items {
+		if item.StageSeconds != nil {
+			seconds := make(map[string]float64, len(item.StageSeconds))
+			for stage, value := range item.StageSeconds {
+				seconds[stage] = value
+			}
+			item.StageSeconds = seconds
+		}
+		snapshot.Items[i] = item
+	}
+	return &snapshot
+}
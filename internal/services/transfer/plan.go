@@ -0,0 +1,223 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+// planHistoryLimit 估算吞吐量时使用的最近完成的传输数
+const planHistoryLimit = 200
+
+// throughputSample 同一设备、模式和方向（或其汇总）最近完成的传输的累计字节数和耗时
+type throughputSample struct {
+	bytes    int64
+	transfer time.Duration // 启动传输进程到完成的耗时
+	setup    time.Duration // 接受请求到启动传输进程的耗时
+	setups   int           // 记录了准备耗时的传输数
+	samples  int
+}
+
+// throughputMBps 平均吞吐量（MB/s）
+func (s *throughputSample) throughputMBps() float64 {
+	return float64(s.bytes) / s.transfer.Seconds() / (1024 * 1024)
+}
+
+// averageSetup 从接受请求到启动传输进程的平均耗时，没有记录时为 0
+func (s *throughputSample) averageSetup() time.Duration {
+	if s.setups == 0 {
+		return 0
+	}
+	return s.setup / time.Duration(s.setups)
+}
+
+// transferTime 按平均吞吐量传输 size 字节所需的时间
+func (s *throughputSample) transferTime(size int64) time.Duration {
+	return time.Duration(float64(size) * float64(s.transfer) / float64(s.bytes))
+}
+
+// EstimatePlan 按当前配置和最近完成的传输的吞吐量估算传输计划，不创建任务
+// 每个传输按创建任务时的规则检查并选择传输模式，无法执行的传输记录原因
+func (ts *TransferService) EstimatePlan(ctx context.Context, plan *models.TransferPlan, settings *models.TransferSettings) *models.PlanEstimate {
+	stats := ts.throughputStats()
+	estimate := &models.PlanEstimate{
+		Items:       make([]models.PlanItemEstimate, 0, len(plan.Items)),
+		EstimatedAt: time.Now(),
+	}
+
+	for i, item := range plan.Items {
+		req := item
+		itemEstimate := models.PlanItemEstimate{
+			Index:     i,
+			Filename:  req.Filename,
+			Direction: req.Direction,
+			Mode:      req.Mode,
+		}
+
+		if err := ts.resolvePlanItem(ctx, &req, settings, &itemEstimate); err != nil {
+			itemEstimate.Error = err.Error()
+			estimate.Invalid++
+			estimate.Items = append(estimate.Items, itemEstimate)
+			continue
+		}
+
+		estimate.TotalBytes += itemEstimate.Size
+		if s := lookupThroughput(stats, settings.Device, req.Mode, req.Direction); s != nil && itemEstimate.Size > 0 {
+			itemEstimate.ThroughputMBps = s.throughputMBps()
+			itemEstimate.SetupSeconds = s.averageSetup().Seconds()
+			itemEstimate.EstimatedSeconds = (s.averageSetup() + s.transferTime(itemEstimate.Size)).Seconds()
+			itemEstimate.Samples = s.samples
+			estimate.EstimatedSeconds += itemEstimate.EstimatedSeconds
+		} else {
+			estimate.Unestimated++
+		}
+		estimate.Items = append(estimate.Items, itemEstimate)
+	}
+	return estimate
+}
+
+// resolvePlanItem 按创建任务时的规则检查计划中的传输，选择传输模式并确定文件大小
+func (ts *TransferService) resolvePlanItem(ctx context.Context, req *models.TransferRequest, settings *models.TransferSettings, estimate *models.PlanItemEstimate) error {
+	if err := ValidateRequest(req); err != nil {
+		return err
+	}
+	if req.IsVerify() {
+		return fmt.Errorf("传输计划不支持只校验的任务")
+	}
+	if err := MapRequestPaths(req, settings, true); err != nil {
+		return err
+	}
+	estimate.Filename = req.Filename
+	if err := checkStaging(settings, req, true); err != nil {
+		return err
+	}
+	if _, err := profileWindows(settings, req.Profile); err != nil {
+		return err
+	}
+	if _, _, err := resolveQoS(settings, req); err != nil {
+		return err
+	}
+
+	decision, err := ResolveAutoMode(settings, req)
+	if err != nil {
+		return err
+	}
+	forced, err := ts.applyNetworkFSPolicy(settings, req)
+	if err != nil {
+		return err
+	}
+	estimate.Mode = req.Mode
+	estimate.ModeDecision = joinDecision(decision, forced)
+	if err := checkModeEnabled(settings, req); err != nil {
+		return err
+	}
+
+	if req.Direction == models.DirectionGet {
+		if sourcePull(req, true) != nil {
+			if err := stageSize(ctx, settings, req); err != nil {
+				return err
+			}
+		} else {
+			path, err := ServerFilePath(settings, req.Mode, req.Filename)
+			if err != nil {
+				return err
+			}
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				return fmt.Errorf("服务端文件不存在: %s", req.Filename)
+			}
+			req.Size = info.Size()
+		}
+	}
+	estimate.Size = req.Size
+	return checkFileSize(settings, req)
+}
+
+// throughputStats 统计最近完成的传输的吞吐量
+func (ts *TransferService) throughputStats() map[string]*throughputSample {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.throughputStatsLocked()
+}
+
+// throughputStatsLocked 按设备、模式和方向统计最近完成的传输，同时按模式汇总所有设备和两个方向，调用方需持有锁
+// 耗时从启动传输进程（或首次上报字节）到完成，没有记录时使用任务的开始时间
+func (ts *TransferService) throughputStatsLocked() map[string]*throughputSample {
+	stats := make(map[string]*throughputSample)
+	add := func(key string, bytes int64, transfer, setup time.Duration) {
+		s := stats[key]
+		if s == nil {
+			s = &throughputSample{}
+			stats[key] = s
+		}
+		s.bytes += bytes
+		s.transfer += transfer
+		s.samples++
+		if setup > 0 {
+			s.setup += setup
+			s.setups++
+		}
+	}
+
+	used := 0
+	for i := len(ts.taskHistory) - 1; i >= 0 && used < planHistoryLimit; i-- {
+		task := ts.taskHistory[i]
+		if task.Status != models.StatusCompleted || task.Type == models.TaskTypeVerify || task.EndTime == nil || task.BytesTransferred <= 0 {
+			continue
+		}
+
+		start, ok := task.EventTime(models.EventProcessSpawned)
+		if !ok {
+			start, ok = task.EventTime(models.EventFirstByte)
+		}
+		if !ok {
+			start = task.StartTime
+		}
+		if start.IsZero() || !task.EndTime.After(start) {
+			continue
+		}
+
+		var setup time.Duration
+		if spawned, ok := task.EventTime(models.EventProcessSpawned); ok {
+			if queued, ok := task.EventTime(models.EventQueued); ok && !task.HasEvent(models.EventDeferred) && !task.HasEvent(models.EventStaging) {
+				setup = spawned.Sub(queued)
+			}
+		}
+
+		transfer := task.EndTime.Sub(start)
+		devices := []string{""}
+		if task.Device != "" {
+			devices = append(devices, task.Device)
+		}
+		for _, device := range devices {
+			add(throughputKey(device, task.Mode, task.Direction), task.BytesTransferred, transfer, setup)
+			add(throughputKey(device, task.Mode, ""), task.BytesTransferred, transfer, setup)
+		}
+		used++
+	}
+	return stats
+}
+
+// lookupThroughput 获取同设备、同模式、同方向的统计，依次退回到同设备两个方向、所有设备同方向和所有设备两个方向的汇总
+func lookupThroughput(stats map[string]*throughputSample, device, mode, direction string) *throughputSample {
+	keys := []string{
+		throughputKey(device, mode, direction),
+		throughputKey(device, mode, ""),
+		throughputKey("", mode, direction),
+		throughputKey("", mode, ""),
+	}
+	for _, key := range keys {
+		if s, ok := stats[key]; ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// throughputKey 统计的键，device 为空表示所有设备，direction 为空表示两个方向
+func throughputKey(device, mode, direction string) string {
+	return device + "|" + mode + "/" + direction
+}
@@ -0,0 +1,29 @@
+package transfer
+
+import (
+	"time"
+
+	"rdma-burst/internal/models"
+)
+
+// DefaultPollInterval 未配置进度更新间隔时建议的状态查询间隔
+const DefaultPollInterval = 5 * time.Second
+
+// PollAfter 按任务状态给出建议的下一次状态查询间隔，interval 为进度更新间隔（monitoring.client.progress_update_interval）
+// 进度不会比更新间隔更快变化；延后、暂存和排队的任务按后台检查间隔查询；任务已结束时返回 0
+func PollAfter(status string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	switch status {
+	case models.StatusCompleted, models.StatusFailed, models.StatusCancelled:
+		return 0
+	case models.StatusDeferred:
+		return deferredCheckInterval
+	case models.StatusStaging, models.StatusQueued, models.StatusPaused, models.StatusBlocked:
+		if interval < stagingCheckInterval {
+			return stagingCheckInterval
+		}
+	}
+	return interval
+}
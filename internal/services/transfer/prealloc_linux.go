@@ -0,0 +1,29 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// preallocate 按预期大小为目标文件预分配磁盘空间，避免碎片和传输后期才出现空间不足
+// 文件系统不支持 fallocate 时跳过
+func preallocate(path string, size int64) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开目标文件失败: %v", err)
+	}
+	defer file.Close()
+
+	if err := syscall.Fallocate(int(file.Fd()), 0, 0, size); err != nil {
+		if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.ENOSYS) {
+			return nil
+		}
+		if errors.Is(err, syscall.ENOSPC) {
+			return fmt.Errorf("目标空间不足，无法预分配 %d 字节: %v", size, err)
+		}
+		return fmt.Errorf("预分配目标文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package transfer
+
+// preallocate 非 Linux 平台不支持 fallocate，跳过预分配
+func preallocate(path string, size int64) error {
+	return nil
+}
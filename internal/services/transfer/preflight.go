@@ -0,0 +1,80 @@
+package transfer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/utils"
+)
+
+// 预检设备能力的参数
+const (
+	capabilityTTL       = time.Minute // 设备能力的缓存时间，ibv_devinfo 较慢，不在每个请求中执行
+	saturationThreshold = 0.9         // 预计占用链路速率的比例达到该值时告警
+)
+
+// capabilityCache 缓存各 RDMA 设备的能力，设备不存在时缓存 nil
+type capabilityCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedCapabilities
+}
+
+// cachedCapabilities 设备能力及其查询时间
+type cachedCapabilities struct {
+	caps      *utils.RDMACapabilities
+	checkedAt time.Time
+}
+
+// get 获取设备能力，缓存过期时重新查询，设备不存在或查询失败时返回 nil
+func (c *capabilityCache) get(device string) *utils.RDMACapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[device]; ok && time.Since(entry.checkedAt) < capabilityTTL {
+		return entry.caps
+	}
+	caps, err := utils.GetRDMACapabilities(device)
+	if err != nil {
+		caps = nil
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]cachedCapabilities)
+	}
+	c.entries[device] = cachedCapabilities{caps: caps, checkedAt: time.Now()}
+	return caps
+}
+
+// preflight 按设备能力检查传输前的告警：端口未活跃、MTU 不匹配，以及加上本任务后设备上的传输预计占满链路
+// 告警不阻止传输，在创建任务的响应中返回并记录日志
+func (ts *TransferService) preflight(settings *models.TransferSettings, req *models.TransferRequest) []string {
+	if settings == nil || settings.Device == "" {
+		return nil
+	}
+	caps := ts.capabilities.get(settings.Device)
+	if caps == nil {
+		return nil
+	}
+	warnings := append([]string(nil), caps.Warnings...)
+
+	ts.mu.RLock()
+	live := countTasks(ts.liveTasks("", false), func(task *models.TransferTask) bool { return task.Device == settings.Device })
+	sample := lookupThroughput(ts.throughputStatsLocked(), settings.Device, req.Mode, req.Direction)
+	ts.mu.RUnlock()
+
+	// 按最近完成的传输的平均吞吐量估算设备上所有传输的总速率
+	if rate := caps.TotalRateGbps(); rate > 0 && sample != nil {
+		perTransfer := sample.throughputMBps() * 1024 * 1024 * 8 / 1e9
+		if demand := perTransfer * float64(live+1); demand >= rate*saturationThreshold {
+			warnings = append(warnings, fmt.Sprintf("设备 %s 的链路速率为 %.0f Gb/s，加上本任务共 %d 个传输，按平均吞吐量 %.1f Gb/s 预计占满链路",
+				settings.Device, rate, live+1, perTransfer))
+		}
+	}
+
+	if len(warnings) > 0 {
+		ts.logger.Warn("传输预检告警", zap.String("device", settings.Device), zap.Strings("warnings", warnings))
+	}
+	return warnings
+}
@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"rdma-burst/internal/models"
+)
+
+// progressCache 执行中任务的进度快照，状态查询不加服务锁直接读取
+// 快照由进度监控协程或客户端上报进度时发布，任务结束时移除，之后的查询回退到加锁读取任务记录
+type progressCache struct {
+	entries sync.Map // 任务ID -> *atomic.Pointer[models.ProgressResponse]
+}
+
+// track 登记任务并发布快照，已登记时替换快照
+func (c *progressCache) track(id string, snapshot *models.ProgressResponse) {
+	entry, _ := c.entries.LoadOrStore(id, new(atomic.Pointer[models.ProgressResponse]))
+	entry.(*atomic.Pointer[models.ProgressResponse]).Store(snapshot)
+}
+
+// update 发布已登记任务的快照，任务已移除时忽略，避免监控协程在任务结束后写回旧状态
+func (c *progressCache) update(id string, snapshot *models.ProgressResponse) {
+	if entry, ok := c.entries.Load(id); ok {
+		entry.(*atomic.Pointer[models.ProgressResponse]).Store(snapshot)
+	}
+}
+
+// forget 移除任务的快照
+func (c *progressCache) forget(id string) {
+	c.entries.Delete(id)
+}
+
+// load 获取任务快照的副本，任务未登记时返回 false
+func (c *progressCache) load(id string) (*models.ProgressResponse, bool) {
+	entry, ok := c.entries.Load(id)
+	if !ok {
+		return nil, false
+	}
+	snapshot := entry.(*atomic.Pointer[models.ProgressResponse]).Load()
+	if snapshot == nil {
+		return nil, false
+	}
+	resp := *snapshot
+	return &resp, true
+}
+
+// publishSession 发布执行中会话的快照，会话已结束或已移除（如 put 完成后推送到对象存储）时移除快照，调用方需持有锁
+func (ts *TransferService) publishSession(session *preparedSession) {
+	if ts.sessions[session.task.ID] != session || session.task.IsFinished() || session.endedAt != nil || !session.started {
+		ts.progress.forget(session.task.ID)
+		return
+	}
+	ts.progress.track(session.task.ID, ts.buildProgressResponse(session.task, session.progress()))
+}
@@ -0,0 +1,70 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/wrapper"
+)
+
+// ErrUnknownQoSClass 请求的 QoS 等级不存在
+var ErrUnknownQoSClass = errors.New("QoS 等级不存在")
+
+// resolveQoS 获取请求使用的 QoS 等级，请求未指定时使用配置档案的等级，都未指定时返回空名称
+func resolveQoS(settings *models.TransferSettings, req *models.TransferRequest) (string, *models.QoSClass, error) {
+	name := req.QoS
+	if name == "" && req.Profile != "" {
+		profile, _ := lookupProfile(settings, req.Profile)
+		name = profile.QoS
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+
+	// 配置文件中的等级名会被转换为小写
+	class, ok := settings.QoSClasses[name]
+	if !ok {
+		name = strings.ToLower(name)
+		class, ok = settings.QoSClasses[name]
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnknownQoSClass, name)
+	}
+	return name, &class, nil
+}
+
+// applyQoS 将 QoS 等级映射到 rtranfile 参数
+func applyQoS(config *wrapper.TransferConfig, class *models.QoSClass) {
+	if class == nil {
+		return
+	}
+	config.BlockSize = class.ChunkSize
+	// IB 流量类别的高 6 位为 DSCP
+	config.TrafficClass = class.DSCP << 2
+}
+
+// qosLimit 获取 QoS 等级可以占用的并发传输数，至少为 1
+func qosLimit(class *models.QoSClass, maxConcurrent int) int {
+	if class == nil || class.ConcurrencyShare <= 0 || class.ConcurrencyShare >= 1 {
+		return maxConcurrent
+	}
+	return max(1, int(math.Ceil(class.ConcurrencyShare*float64(maxConcurrent))))
+}
+
+// checkQoSLimit 检查 QoS 等级的并发份额，live 为占用并发名额的任务，调用方需持有锁
+func (ts *TransferService) checkQoSLimit(name string, live map[string]*models.TransferTask) error {
+	if name == "" || ts.serverConfig == nil {
+		return nil
+	}
+	class, ok := ts.serverConfig.QoSClasses[name]
+	if !ok {
+		return nil
+	}
+	if limit := qosLimit(&class, ts.maxConcurrent); countTasks(live, func(task *models.TransferTask) bool { return task.QoS == name }) >= limit {
+		return fmt.Errorf("%w: QoS 等级 %s 最多同时运行 %d 个任务", ErrConcurrencyLimit, name, limit)
+	}
+	return nil
+}
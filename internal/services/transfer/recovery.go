@@ -0,0 +1,315 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/accounting"
+	"rdma-burst/internal/services/journal"
+	"rdma-burst/internal/wrapper"
+	"rdma-burst/pkg/metrics"
+	"rdma-burst/pkg/tracing"
+)
+
+// SetJournal 设置任务状态预写日志，任务状态变更时追加记录
+func (ts *TransferService) SetJournal(j *journal.Journal) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.journal = j
+}
+
+// SetAccounting 设置用量账本，任务结束时按调用方计入用量
+func (ts *TransferService) SetAccounting(ledger *accounting.Ledger) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.accounting = ledger
+}
+
+// record 追加任务当前状态到预写日志（调用方需持有锁）
+func (ts *TransferService) record(taskWrapper *TransferTask) {
+	ts.charge(taskWrapper.Task)
+	if ts.journal == nil {
+		return
+	}
+
+	entry := journal.Entry{
+		Task: *taskWrapper.Task,
+		PID:        taskWrapper.Process.GetPID(),
+		StartTicks: taskWrapper.Process.GetStartTicks(),
+	}
+	if taskWrapper.Config != nil {
+		entry.LogFile = taskWrapper.Config.LogFile
+		entry.Device = taskWrapper.Config.Device
+	}
+
+	if err := ts.journal.Append(entry); err != nil {
+		ts.logger.Warn("写入任务日志失败", zap.String("task_id", entry.Task.ID), zap.Error(err))
+	}
+}
+
+// recordTask 把服务端登记的任务（延后任务、准备就绪的会话）追加到预写日志，req 为重启后恢复任务所需的原始请求，调用方需持有锁
+func (ts *TransferService) recordTask(task *models.TransferTask, req *models.TransferRequest) {
+	ts.charge(task)
+	if ts.journal == nil {
+		return
+	}
+	if err := ts.journal.Append(journal.Entry{Task: *task, Request: req}); err != nil {
+		ts.logger.Warn("写入任务日志失败", zap.String("task_id", task.ID), zap.Error(err))
+	}
+}
+
+// recordSession 把会话的任务状态和进度追加到预写日志，未结束的会话同时记录原始请求，调用方需持有锁
+// put 完成后推送到对象存储的任务已不是会话，不记录请求
+func (ts *TransferService) recordSession(session *preparedSession) {
+	var req *models.TransferRequest
+	if ts.sessions[session.task.ID] == session && session.endedAt == nil && !session.task.IsFinished() {
+		req = &session.req
+	}
+	session.checkpointAt = time.Now()
+	ts.recordTask(session.task, req)
+}
+
+// charge 任务结束时计入用量，调用方需持有锁
+func (ts *TransferService) charge(task *models.TransferTask) {
+	if ts.accounting != nil && task.IsFinished() {
+		ts.accounting.Charge(task)
+	}
+}
+
+// Recover 从预写日志恢复任务
+// 已结束的任务加入历史记录；等待时间窗口的延后任务重新登记并启动调度；
+// 准备就绪和客户端执行中的会话从最近的检查点重新登记，并重新启动服务端监听进程；
+// 在设备调度队列中等待和被抢占暂停的任务按原顺序重新排队，设备有空闲名额时启动；等待依赖的任务重新登记，依赖完成后继续准备；
+// 正在从对象存储拉取源文件的任务重新拉取；
+// 进行中的任务如果 rtranfile 进程仍在运行则重新挂载进程和日志监控，否则标记为失败
+// 返回重新挂载的任务和会话数
+func (ts *TransferService) Recover(ctx context.Context) (int, error) {
+	restored, blocked := 0, 0
+	deadlines := false
+	var listener *preparedSession
+	var staged []*models.TransferTask
+	stagedRequests := make(map[string]models.TransferRequest)
+	defer func() {
+		// 调度器、监听进程和对象存储拉取启动时需要加锁，在释放锁之后启动
+		if restored > 0 {
+			ts.startScheduler()
+		}
+		if blocked > 0 {
+			ts.startDependencyWatch()
+		}
+		if deadlines {
+			ts.startDeadlineWatch()
+		}
+		if listener != nil {
+			ts.restartListener(ctx, listener)
+		}
+		for _, task := range staged {
+			ts.restartStaging(ctx, task, stagedRequests[task.ID])
+		}
+	}()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.journal == nil {
+		return 0, nil
+	}
+
+	entries, err := ts.journal.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	reattached, queued := 0, 0
+	compacted := make([]journal.Entry, 0, len(entries))
+	for _, entry := range entries {
+		task := entry.Task
+		if _, exists := ts.activeTasks[task.ID]; exists {
+			continue
+		}
+
+		if task.Status == models.StatusDeferred {
+			if err := ts.restoreDeferred(ctx, &task, entry.Request); err != nil {
+				ts.logger.Warn("恢复延后任务失败，标记为失败", zap.String("task_id", task.ID), zap.Error(err))
+				task.MarkFailed(fmt.Sprintf("守护进程重启后无法恢复延后任务: %v", err))
+				entry.Request = nil
+			} else {
+				restored++
+				ts.logger.Info("已恢复延后任务",
+					zap.String("task_id", task.ID),
+					zap.String("profile", entry.Request.Profile),
+				)
+			}
+		} else if task.Status == models.StatusBlocked {
+			if err := ts.restoreBlocked(ctx, &task, entry.Request); err != nil {
+				ts.logger.Warn("恢复等待依赖的任务失败，标记为失败", zap.String("task_id", task.ID), zap.Error(err))
+				task.MarkFailed(fmt.Sprintf("守护进程重启后无法恢复等待依赖的任务: %v", err))
+				entry.Request = nil
+			} else {
+				blocked++
+				ts.logger.Info("已恢复等待依赖的任务", zap.String("task_id", task.ID), zap.Strings("depends_on", task.DependsOn))
+			}
+		} else if task.Status == models.StatusQueued || task.Status == models.StatusPaused {
+			if err := ts.restoreQueued(ctx, &task, entry.Request); err != nil {
+				ts.logger.Warn("恢复排队任务失败，标记为失败", zap.String("task_id", task.ID), zap.Error(err))
+				task.MarkFailed(fmt.Sprintf("守护进程重启后无法恢复排队任务: %v", err))
+				entry.Request = nil
+			} else {
+				queued++
+				ts.logger.Info("已恢复排队任务", zap.String("task_id", task.ID), zap.String("device", task.Device))
+			}
+		} else if task.Status == models.StatusStaging && entry.Request != nil && ts.serverConfig != nil {
+			// 拉取在释放锁之后重新开始
+			staged = append(staged, &task)
+			stagedRequests[task.ID] = *entry.Request
+			ts.logger.Info("已恢复正在从对象存储拉取源文件的任务", zap.String("task_id", task.ID))
+		} else if entry.Request != nil && entry.PID == 0 && (task.Status == models.StatusPrepared || task.Status == models.StatusInProgress) {
+			session := ts.restoreSession(&task, entry.Request)
+			reattached++
+			// 监听进程同一时间只运行一种模式，优先选择客户端执行中、最近有进度的会话
+			if listener == nil || session.started && (!listener.started || task.UpdatedAt.After(listener.task.UpdatedAt)) {
+				listener = session
+			}
+			ts.logger.Info("已恢复传输会话",
+				zap.String("task_id", task.ID),
+				zap.String("status", task.Status),
+				zap.Int64("bytes_transferred", task.BytesTransferred),
+			)
+		} else if !task.IsFinished() {
+			taskWrapper, err := ts.reattach(ctx, &task, entry)
+			if err != nil {
+				ts.logger.Warn("恢复传输任务失败，标记为失败",
+					zap.String("task_id", task.ID),
+					zap.Int("pid", entry.PID),
+					zap.Error(err),
+				)
+				task.MarkFailed(fmt.Sprintf("守护进程重启后无法恢复传输: %v", err))
+			} else {
+				ts.activeTasks[task.ID] = taskWrapper
+				ts.progress.track(task.ID, ts.buildProgressResponse(&task, nil))
+				metrics.TransferStarted()
+				go ts.monitorTransferProgress(taskWrapper)
+				reattached++
+
+				ts.logger.Info("已恢复传输任务",
+					zap.String("task_id", task.ID),
+					zap.Int("pid", entry.PID),
+					zap.String("log_file", entry.LogFile),
+				)
+			}
+		}
+
+		entry.Task = task
+		compacted = append(compacted, entry)
+		ts.taskHistory = append(ts.taskHistory, &task)
+		// 未结束的任务继续检查截止时间
+		if task.Deadline != nil && !task.IsFinished() {
+			deadlines = true
+		}
+	}
+
+	// 恢复的会话已登记，设备有空闲名额时启动排队的任务
+	if queued > 0 {
+		ts.dispatchDevices()
+	}
+
+	// 用恢复后的状态重写日志，避免日志无限增长
+	if err := ts.journal.Compact(compacted); err != nil {
+		return reattached, err
+	}
+
+	return reattached, nil
+}
+
+// restartStaging 为从预写日志恢复的拉取任务重新从对象存储拉取源文件，完成后与新任务相同准备传输环境
+func (ts *TransferService) restartStaging(ctx context.Context, task *models.TransferTask, req models.TransferRequest) {
+	ts.mu.Lock()
+	settings := ts.taskSettings(task)
+	task.Device = settings.Device
+	ts.mu.Unlock()
+	ts.stageTransfer(tracing.Detach(ctx), task, req, settings)
+}
+
+// restoreSession 按预写日志重新登记会话，心跳超时从现在开始计算，客户端恢复心跳后继续执行，调用方需持有锁
+func (ts *TransferService) restoreSession(task *models.TransferTask, req *models.TransferRequest) *preparedSession {
+	now := time.Now()
+	if ts.serverConfig != nil {
+		task.Device = ts.taskSettings(task).Device
+	}
+	session := &preparedSession{
+		mode:          ts.listenerKey(task.Device, req.Mode),
+		req:           *req,
+		task:          task,
+		started:       task.Status == models.StatusInProgress,
+		restored:      true,
+		lastHeartbeat: now,
+		checkpointAt:  now,
+	}
+	task.Message = "守护进程重启后已恢复传输会话"
+	ts.addSession(session)
+	if session.started {
+		ts.progress.track(task.ID, ts.buildProgressResponse(task, session.progress()))
+	}
+	return session
+}
+
+// restartListener 为恢复的会话重新启动服务端监听进程，失败时只记录日志，会话在客户端没有心跳时按时过期
+func (ts *TransferService) restartListener(ctx context.Context, session *preparedSession) {
+	ts.mu.RLock()
+	settings := ts.serverConfig
+	ts.mu.RUnlock()
+	if settings == nil {
+		return
+	}
+
+	req := session.req
+	ts.mu.RLock()
+	restored := ts.taskSettings(session.task)
+	ts.mu.RUnlock()
+	if err := ts.PrepareTransfer(ctx, &req, &restored); err != nil {
+		ts.logger.Warn("为恢复的会话重新启动服务端监听进程失败",
+			zap.String("task_id", session.task.ID),
+			zap.String("mode", session.mode),
+			zap.Error(err),
+		)
+		return
+	}
+	ts.logger.Info("已为恢复的会话重新启动服务端监听进程", zap.String("mode", session.mode))
+}
+
+// reattach 重新挂载仍在运行的 rtranfile 进程及其日志监控
+func (ts *TransferService) reattach(ctx context.Context, task *models.TransferTask, entry journal.Entry) (*TransferTask, error) {
+	if entry.PID <= 0 {
+		return nil, fmt.Errorf("没有记录传输进程")
+	}
+	if entry.LogFile == "" {
+		return nil, fmt.Errorf("没有记录传输日志文件")
+	}
+
+	process := ts.newProcessManager()
+	if err := process.Attach(entry.PID, wrapper.RtranfileBinary, entry.StartTicks); err != nil {
+		return nil, err
+	}
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	monitor := ts.newMonitor(entry.LogFile)
+	if err := monitor.StartMonitoring(taskCtx); err != nil {
+		cancel()
+		process.Cleanup()
+		return nil, err
+	}
+
+	return &TransferTask{
+		Task:    task,
+		Monitor: monitor,
+		Process: process,
+		Config: &wrapper.TransferConfig{
+			Device:  entry.Device,
+			LogFile: entry.LogFile,
+		},
+		Cancel: cancel,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ficlone FICLONE ioctl，让目标文件与源文件共享数据块（写时复制）
+const ficlone = 0x40049409
+
+// ficloneRange FICLONERANGE ioctl，只共享源文件中的一段数据块
+const ficloneRange = 0x4020940d
+
+// fileCloneRange struct file_clone_range
+type fileCloneRange struct {
+	srcFd      int64
+	srcOffset  uint64
+	srcLength  uint64
+	destOffset uint64
+}
+
+// reflink 通过 FICLONE 创建与源文件共享数据块的目标文件，只复制元数据；
+// 源文件和目标文件不在同一个文件系统或文件系统不支持（ext4、tmpfs、hugetlbfs 等）时返回错误
+func reflink(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("打开源文件失败: %v", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("创建目标文件失败: %v", err)
+	}
+	defer out.Close()
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, out.Fd(), ficlone, in.Fd()); errno != 0 {
+		os.Remove(target)
+		return errno
+	}
+	return nil
+}
+
+// reflinkRange 通过 FICLONERANGE 创建与源文件 offset 之后的 length 字节（为 0 时到文件末尾）共享数据块的目标文件；
+// 除文件系统不支持外，offset 不是文件系统块大小的整数倍时同样返回错误，调用方改为复制
+func reflinkRange(source, target string, offset, length int64) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("打开源文件失败: %v", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("创建目标文件失败: %v", err)
+	}
+	defer out.Close()
+
+	args := fileCloneRange{srcFd: int64(in.Fd()), srcOffset: uint64(offset), srcLength: uint64(length)}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, out.Fd(), ficloneRange, uintptr(unsafe.Pointer(&args))); errno != 0 {
+		os.Remove(target)
+		return errno
+	}
+	return nil
+}
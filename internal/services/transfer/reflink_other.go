@@ -0,0 +1,15 @@
+//go:build !linux
+
+package transfer
+
+import "errors"
+
+// reflink 非 Linux 平台不支持 FICLONE
+func reflink(source, target string) error {
+	return errors.New("当前平台不支持 reflink")
+}
+
+// reflinkRange 非 Linux 平台不支持 FICLONERANGE
+func reflinkRange(source, target string, offset, length int64) error {
+	return errors.New("当前平台不支持 reflink")
+}
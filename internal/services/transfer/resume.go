@@ -0,0 +1,483 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/pkg/tracing"
+)
+
+// resumeRetention 失败的任务保留续传状态的时间，超过后删除 put 已接收的部分
+const resumeRetention = time.Hour
+
+// resumeSegmentSuffix 续传时剩余部分在服务端使用的文件名后缀，后接续传开始的字节偏移
+const resumeSegmentSuffix = ".resume-"
+
+// segmentCopyBlock 不能通过 reflink 共享数据块时复制 get 剩余部分的块大小
+const segmentCopyBlock = 4 << 20
+
+// ErrNotResumable 任务没有失败、没有保留续传状态或续传偏移无效
+var ErrNotResumable = errors.New("任务不能续传")
+
+// resumableTransfer 任务失败后保留的续传状态
+type resumableTransfer struct {
+	task     *models.TransferTask
+	req      models.TransferRequest // 最近一次准备传输环境使用的请求，续传时据此重新准备
+	offset   int64                  // 可以续传的字节偏移：put 为服务端已接收的字节数，get 为客户端最近上报的检查点
+	failedAt time.Time
+}
+
+// receivedPath put 已接收部分所在的文件：启用临时后缀时为临时文件，否则为目标文件
+func (ts *TransferService) receivedPath(task *models.TransferTask) string {
+	return task.TargetPath + partialSuffix(ts.serverConfig)
+}
+
+// segmentPath 从 offset 续传时剩余部分在服务端的文件：put 为客户端发送的文件，get 为服务端从源文件复制的文件
+func segmentPath(task *models.TransferTask, offset int64) string {
+	path := task.SourcePath
+	if task.Direction == models.DirectionPut {
+		path = task.TargetPath
+	}
+	return path + resumeSegmentSuffix + strconv.FormatInt(offset, 10)
+}
+
+// segmentRequest 续传使用的请求：只传输剩余部分，不再从对象存储拉取源文件
+func segmentRequest(req *models.TransferRequest, task *models.TransferTask, offset int64) models.TransferRequest {
+	segment := *req
+	segment.Filename = filepath.Base(segmentPath(task, offset))
+	segment.Mode = task.Mode
+	segment.SourcePath, segment.DestinationPath = "", ""
+	segment.DependsOn = nil
+	segment.Staging = nil
+	if task.TotalBytes > 0 {
+		segment.Size = task.TotalBytes - offset
+	}
+	return segment
+}
+
+// ResumeTransfer 从检查点续传失败的任务：重新准备传输环境，客户端只传输续传偏移之后的剩余部分
+// put 的剩余部分在客户端上报完成后拼接到已接收的部分之后；get 的剩余部分由服务端在后台从源文件准备，客户端等待准备就绪后接收并拼接到本地已接收的部分
+func (ts *TransferService) ResumeTransfer(ctx context.Context, taskID string, resume *models.ResumeRequest) (*models.TransferResponse, error) {
+	if err := ts.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	ts.mu.Lock()
+	task := ts.historyTask(taskID)
+	if task == nil {
+		ts.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(task.Owner) {
+		ts.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotOwner, taskID)
+	}
+	r, ok := ts.resumable[taskID]
+	if !ok || task.Status != models.StatusFailed {
+		ts.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s 为 %s，没有保留续传状态", ErrNotResumable, taskID, task.Status)
+	}
+	offset, err := resumeOffset(task, r, resume.Offset)
+	if err != nil {
+		ts.mu.Unlock()
+		return nil, err
+	}
+	delete(ts.resumable, taskID)
+	settings := ts.taskSettings(task)
+	req := segmentRequest(&r.req, task, offset)
+
+	task.ResumeOffset = offset
+	task.Status = models.StatusPending
+	task.Error = ""
+	task.EndTime = nil
+	task.UpdateProgress(offset, task.TotalBytes)
+	task.AddEvent(models.EventResumed, strconv.FormatInt(offset, 10))
+	task.Message = fmt.Sprintf("从 %d 字节处续传", offset)
+	if task.Direction == models.DirectionGet {
+		// get: 客户端按文件名接收，服务端在后台准备源文件的剩余部分，客户端等待准备就绪
+		ts.stageSegment(tracing.Detach(ctx), task, r, req, settings)
+	} else if ts.reserveDevice(ctx, task, &req, &settings) {
+		ts.mu.Unlock()
+		if err := ts.prepareResumed(ctx, task, r, &req, &settings); err != nil {
+			return nil, err
+		}
+		ts.mu.Lock()
+	}
+	status, message := task.Status, task.Message
+	ts.mu.Unlock()
+
+	ts.logger.Info("传输任务从检查点续传",
+		zap.String("task_id", taskID),
+		zap.Int64("offset", offset),
+		zap.String("status", status),
+	)
+
+	return &models.TransferResponse{
+		ID:                task.ID,
+		Status:            status,
+		Message:           message,
+		Mode:              task.Mode,
+		Size:              task.TotalBytes,
+		TargetFilename:    req.Filename,
+		ResumeOffset:      offset,
+		HeartbeatInterval: ts.heartbeatInterval(),
+		TraceID:           task.TraceID,
+		CreatedAt:         task.CreatedAt,
+	}, nil
+}
+
+// prepareResumed 为已占用设备名额的续传任务准备传输环境并登记为准备就绪的会话
+// 准备失败时任务重新标记为失败，已接收的部分保持不变，客户端可以再次续传
+func (ts *TransferService) prepareResumed(ctx context.Context, task *models.TransferTask, r *resumableTransfer, req *models.TransferRequest, settings *models.TransferSettings) error {
+	err := ts.PrepareTransfer(ctx, req, settings)
+	ts.mu.Lock()
+	ts.releaseDevice(task)
+	if err != nil {
+		ts.failResumed(task, r, fmt.Sprintf("续传时准备传输环境失败: %v", err))
+		ts.dispatchDevice(settings.Device)
+		ts.mu.Unlock()
+		return err
+	}
+	task.Status = models.StatusPrepared
+	task.Message = fmt.Sprintf("传输环境准备就绪，请在客户端从 %d 字节处续传", task.ResumeOffset)
+	task.UpdatedAt = time.Now()
+	ts.registerSession(req, task)
+	ts.mu.Unlock()
+	ts.leaseHugepages(task, req)
+	return nil
+}
+
+// failResumed 续传没有开始传输就失败时恢复保留的续传状态，调用方需持有锁
+func (ts *TransferService) failResumed(task *models.TransferTask, r *resumableTransfer, reason string) {
+	task.MarkFailed(reason)
+	ts.removeSegment(task)
+	r.failedAt = time.Now()
+	ts.resumable[task.ID] = r
+	ts.recordTask(task, nil)
+}
+
+// stageSegment 在后台准备续传的 get 在服务端的剩余部分，期间任务处于 staging 状态，调用方需持有锁
+// 文件系统支持时通过 reflink 共享源文件的数据块，否则按块复制并在任务消息中报告进度，完成后准备传输环境
+func (ts *TransferService) stageSegment(ctx context.Context, task *models.TransferTask, r *resumableTransfer, req models.TransferRequest, settings models.TransferSettings) {
+	ctx, cancel := context.WithCancel(ctx)
+	ts.trackStaging(task, cancel)
+	task.Status = models.StatusStaging
+	task.Message = fmt.Sprintf("正在准备从 %d 字节处续传的剩余部分", task.ResumeOffset)
+	task.UpdatedAt = time.Now()
+	src, dst, offset := task.SourcePath, segmentPath(task, task.ResumeOffset), task.ResumeOffset
+	length := task.TotalBytes - offset
+
+	go func() {
+		defer cancel()
+		err := reflinkRange(src, dst, offset, 0)
+		if err != nil {
+			var last time.Time
+			_, err = copySegment(ctx, src, dst, offset, func(copied int64) {
+				if time.Since(last) < time.Second {
+					return
+				}
+				last = time.Now()
+				ts.mu.Lock()
+				defer ts.mu.Unlock()
+				if task.Status != models.StatusStaging {
+					return
+				}
+				task.Message = fmt.Sprintf("正在准备续传的剩余部分: %d/%d 字节", copied, length)
+				task.UpdatedAt = last
+			})
+		}
+
+		ts.mu.Lock()
+		delete(ts.staging, task.ID)
+		if task.Status == models.StatusCancelled {
+			ts.removeSegment(task)
+			ts.mu.Unlock()
+			return
+		}
+		if err != nil {
+			ts.failResumed(task, r, fmt.Sprintf("准备续传的剩余部分失败: %v", err))
+			ts.logger.Error("准备续传的剩余部分失败", zap.String("task_id", task.ID), zap.Error(err))
+			ts.mu.Unlock()
+			return
+		}
+		// 设备已满时任务进入调度队列，由队列准备传输环境
+		task.Status = models.StatusPending
+		reserved := ts.reserveDevice(ctx, task, &req, &settings)
+		ts.mu.Unlock()
+		if reserved {
+			ts.prepareResumed(ctx, task, r, &req, &settings)
+		}
+	}()
+}
+
+// resumeOffset 确定续传开始的偏移：请求的偏移为 0 时使用保留的偏移，put 不超过服务端已接收的字节数
+func resumeOffset(task *models.TransferTask, r *resumableTransfer, requested int64) (int64, error) {
+	offset := r.offset
+	if requested > 0 && (task.Direction == models.DirectionGet || requested < offset) {
+		offset = requested
+	}
+	if offset <= 0 || task.TotalBytes > 0 && offset >= task.TotalBytes {
+		return 0, fmt.Errorf("%w: 续传偏移 %d 无效", ErrNotResumable, offset)
+	}
+	return offset, nil
+}
+
+// retainResumable 任务失败时按客户端最近上报的检查点保留续传状态，调用方需持有锁
+// put 保留已接收的部分，服务端为 get 准备的剩余部分随即删除；续传的 put 收到的剩余部分需要先拼接到已接收的部分之后，
+// 拼接在后台进行，完成后才保留续传状态；客户端没有启用续传或没有完成的分块时返回 false，调用方按原来的方式处理接收的临时文件
+func (ts *TransferService) retainResumable(session *preparedSession) bool {
+	task := session.task
+	if session.req.IsVerify() || task.Direction == models.DirectionPut && task.TargetPath == "" {
+		ts.removeSegment(task)
+		return false
+	}
+
+	offset := session.checkpoint
+	if offset < task.ResumeOffset {
+		offset = task.ResumeOffset
+	}
+	if task.Direction != models.DirectionPut {
+		ts.removeSegment(task)
+		return ts.keepResumable(task, session.req, offset)
+	}
+	if task.ResumeOffset > 0 {
+		ts.spliceFailed(task, session.req, offset)
+		return true
+	}
+	received, err := ts.keepReceived(task, offset)
+	if err != nil {
+		ts.logger.Warn("保留已接收的部分失败，任务不能续传", zap.String("task_id", task.ID), zap.Error(err))
+		return false
+	}
+	return ts.keepResumable(task, session.req, received)
+}
+
+// keepResumable 保留从 offset 续传的状态，offset 无效时返回 false，调用方需持有锁
+func (ts *TransferService) keepResumable(task *models.TransferTask, req models.TransferRequest, offset int64) bool {
+	if offset <= 0 || task.TotalBytes > 0 && offset >= task.TotalBytes {
+		return false
+	}
+
+	if ts.resumable == nil {
+		ts.resumable = make(map[string]*resumableTransfer)
+	}
+	ts.resumable[task.ID] = &resumableTransfer{
+		task:     task,
+		req:      req,
+		offset:   offset,
+		failedAt: time.Now(),
+	}
+	task.Message += fmt.Sprintf("，可以从 %d 字节处续传", offset)
+	ts.logger.Info("已保留失败任务的续传状态", zap.String("task_id", task.ID), zap.Int64("offset", offset))
+	return true
+}
+
+// keepReceived 把 put 已接收的部分截断到 offset（不超过已接收的字节数），返回保留的字节数，调用方需持有锁
+func (ts *TransferService) keepReceived(task *models.TransferTask, offset int64) (int64, error) {
+	path := ts.receivedPath(task)
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("获取已接收的部分失败: %v", err)
+	}
+	if offset > info.Size() {
+		offset = info.Size()
+	}
+	if offset > 0 {
+		if err := os.Truncate(path, offset); err != nil {
+			return 0, fmt.Errorf("截断已接收的部分失败: %v", err)
+		}
+	}
+	return offset, nil
+}
+
+// spliceFailed 在后台把失败的续传 put 收到的剩余部分中 offset 之前的内容拼接到续传开始的偏移处，
+// 拼接期间不持有锁，完成后保留续传状态，拼接失败时删除接收的临时文件，调用方需持有锁
+func (ts *TransferService) spliceFailed(task *models.TransferTask, req models.TransferRequest, offset int64) {
+	task.Message += "，正在保留已接收的部分"
+	path, segment, resumed := ts.receivedPath(task), segmentPath(task, task.ResumeOffset), task.ResumeOffset
+
+	go func() {
+		received, err := spliceSegment(path, segment, resumed, offset-resumed)
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		if err != nil {
+			os.Remove(segment)
+			ts.logger.Warn("保留已接收的部分失败，任务不能续传", zap.String("task_id", task.ID), zap.Error(err))
+		}
+		if err != nil || task.Status != models.StatusFailed || !ts.keepResumable(task, req, received) {
+			ts.discardPartial(task)
+			return
+		}
+		ts.recordTask(task, nil)
+	}()
+}
+
+// completeResumed 续传的任务上报完成后处理收到的剩余部分：get 删除服务端复制的剩余部分，
+// put 在后台把剩余部分拼接到已接收的部分之后并返回 true，拼接完成后再完成任务，调用方需持有锁
+func (ts *TransferService) completeResumed(task *models.TransferTask) bool {
+	if task.ResumeOffset <= 0 {
+		return false
+	}
+	if task.Direction != models.DirectionPut {
+		ts.removeSegment(task)
+		return false
+	}
+	ts.spliceResumed(task)
+	return true
+}
+
+// spliceResumed 在后台拼接续传的 put 收到的剩余部分，拼接期间不持有锁，完成后校验或完成任务，调用方需持有锁
+func (ts *TransferService) spliceResumed(task *models.TransferTask) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ts.trackStaging(task, cancel)
+	task.Message = "客户端传输完成，正在拼接续传收到的剩余部分"
+	task.UpdatedAt = time.Now()
+	path, segment, offset := ts.receivedPath(task), segmentPath(task, task.ResumeOffset), task.ResumeOffset
+	total := task.TotalBytes
+
+	go func() {
+		defer cancel()
+		size, err := spliceSegment(path, segment, offset, -1)
+		if err == nil && total > 0 && size != total {
+			err = fmt.Errorf("续传拼接后的文件大小 %d 与文件大小 %d 不一致", size, total)
+		}
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		delete(ts.staging, task.ID)
+		if ctx.Err() != nil || task.Status == models.StatusCancelled {
+			return
+		}
+		if err != nil {
+			task.MarkFailed(err.Error())
+			ts.notifyFailed(task)
+			ts.recordTask(task, nil)
+			ts.logger.Error("完成接收的文件失败", zap.String("task_id", task.ID), zap.Error(err))
+			return
+		}
+		ts.finishReceived(task)
+		// 需要校验摘要或推送到对象存储的任务在完成后记录
+		if task.IsFinished() {
+			ts.recordTask(task, nil)
+		}
+	}()
+}
+
+// removeSegment 删除续传的任务在服务端的剩余部分，调用方需持有锁
+func (ts *TransferService) removeSegment(task *models.TransferTask) {
+	if task.ResumeOffset <= 0 {
+		return
+	}
+	path := segmentPath(task, task.ResumeOffset)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		ts.logger.Warn("删除续传的剩余部分失败", zap.String("task_id", task.ID), zap.String("path", path), zap.Error(err))
+	}
+}
+
+// pruneResumable 删除超过保留时间或已不再失败的任务的续传状态，超时的 put 任务同时删除接收的临时文件，调用方需持有锁
+func (ts *TransferService) pruneResumable(now time.Time) {
+	for id, r := range ts.resumable {
+		if r.task.Status == models.StatusFailed && now.Sub(r.failedAt) <= resumeRetention {
+			continue
+		}
+		if r.task.Status == models.StatusFailed {
+			ts.discardPartial(r.task)
+		}
+		delete(ts.resumable, id)
+	}
+}
+
+// copySegment 把 src 中 offset 之后的内容按块复制到 dst，progress 不为 nil 时每复制一块以累计复制的字节数调用，返回复制的字节数
+// ctx 取消时停止复制并删除 dst
+func copySegment(ctx context.Context, src, dst string, offset int64, progress func(int64)) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, fmt.Errorf("打开源文件失败: %v", err)
+	}
+	defer in.Close()
+	if _, err := in.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("定位续传偏移失败: %v", err)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, fmt.Errorf("创建剩余部分的文件失败: %v", err)
+	}
+	var copied int64
+	buf := make([]byte, segmentCopyBlock)
+	for err == nil {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var n int
+		n, err = io.ReadFull(in, buf)
+		if n > 0 {
+			if _, werr := out.Write(buf[:n]); werr != nil {
+				err = werr
+				break
+			}
+			copied += int64(n)
+			if progress != nil {
+				progress(copied)
+			}
+		}
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return 0, fmt.Errorf("复制剩余部分失败: %v", err)
+	}
+	return copied, nil
+}
+
+// spliceSegment 把续传收到的剩余部分的前 n 字节（n 小于 0 时为全部）写入 path 的 offset 处并截断之后的内容，返回拼接后的文件大小
+// 剩余部分的文件拼接后删除，不存在时视为没有收到数据
+func spliceSegment(path, segment string, offset, n int64) (int64, error) {
+	out, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return 0, fmt.Errorf("打开已接收的部分失败: %v", err)
+	}
+	defer out.Close()
+	if err := out.Truncate(offset); err != nil {
+		return 0, fmt.Errorf("截断已接收的部分失败: %v", err)
+	}
+
+	in, err := os.Open(segment)
+	if os.IsNotExist(err) {
+		return offset, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("打开续传收到的剩余部分失败: %v", err)
+	}
+	defer os.Remove(segment)
+	defer in.Close()
+
+	var r io.Reader = in
+	if n >= 0 {
+		r = io.LimitReader(in, n)
+	}
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("定位续传偏移失败: %v", err)
+	}
+	copied, err := io.Copy(out, r)
+	if err != nil {
+		return 0, fmt.Errorf("拼接续传收到的剩余部分失败: %v", err)
+	}
+	return offset + copied, nil
+}
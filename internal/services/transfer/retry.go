@@ -0,0 +1,48 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+)
+
+// ErrNotRetryable 任务没有失败或被取消，或没有记录原始请求
+var ErrNotRetryable = errors.New("任务不能重试")
+
+// rememberRequest 记录任务的原始请求，任务失败或被取消后可以按该请求重试，调用方需持有锁
+func (ts *TransferService) rememberRequest(task *models.TransferTask, req *models.TransferRequest) {
+	if ts.requests == nil {
+		ts.requests = make(map[string]models.TransferRequest)
+	}
+	ts.requests[task.ID] = *req
+}
+
+// RetryTransfer 按失败或被取消的任务的原始请求重新创建任务，只有任务的创建者和管理员可以重试
+// 新任务从头传输并重新检查并发上限和时间窗口，创建者为发起重试的调用方；需要从检查点继续时使用 ResumeTransfer
+func (ts *TransferService) RetryTransfer(ctx context.Context, taskID string, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	ts.mu.RLock()
+	task := ts.historyTask(taskID)
+	if task == nil {
+		ts.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(task.Owner) {
+		ts.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotOwner, taskID)
+	}
+	if task.Status != models.StatusFailed && task.Status != models.StatusCancelled {
+		status := task.Status
+		ts.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s 为 %s，只能重试失败或被取消的任务", ErrNotRetryable, taskID, status)
+	}
+	req, ok := ts.requests[taskID]
+	ts.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: 没有记录 %s 的原始请求（守护进程重启前创建的任务）", ErrNotRetryable, taskID)
+	}
+
+	return ts.Prepare(ctx, &req, serverConfig)
+}
@@ -0,0 +1,120 @@
+package transfer
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultReuseIdleTimeout 未配置空闲超时时复用的监听进程保持运行的默认时间
+const defaultReuseIdleTimeout = 30 * time.Second
+
+// listenerLease 会话复用时监听进程的使用情况：已服务的传输数和空闲计时
+type listenerLease struct {
+	pid       int         // 监听进程的 PID，进程重新启动后重新计数
+	transfers int         // 已服务的传输数
+	idle      *time.Timer // 没有会话时的空闲计时，到期后停止监听进程
+	expired   bool        // 空闲超时已到，不再保留
+}
+
+// reuseEnabled 是否启用会话复用
+func (ts *TransferService) reuseEnabled() bool {
+	return ts.serverConfig != nil && ts.serverConfig.SessionReuse.Enabled
+}
+
+// reuseIdleTimeout 获取复用的监听进程没有会话时保持运行的时间
+func (ts *TransferService) reuseIdleTimeout() time.Duration {
+	if ts.serverConfig != nil && ts.serverConfig.SessionReuse.IdleTimeout > 0 {
+		return ts.serverConfig.SessionReuse.IdleTimeout
+	}
+	return defaultReuseIdleTimeout
+}
+
+// useListener 记录模式的监听进程服务了一个新的会话并取消空闲计时，调用方需持有锁
+func (ts *TransferService) useListener(mode string) {
+	if !ts.reuseEnabled() {
+		return
+	}
+	processMgr, exists := ts.serverProcesses[mode]
+	if !exists {
+		return
+	}
+
+	lease := ts.leases[mode]
+	if lease == nil || lease.pid != processMgr.GetPID() {
+		ts.dropLease(mode)
+		lease = &listenerLease{pid: processMgr.GetPID()}
+		if ts.leases == nil {
+			ts.leases = make(map[string]*listenerLease)
+		}
+		ts.leases[mode] = lease
+	} else {
+		ts.logger.Debug("复用服务端监听进程",
+			zap.String("mode", mode),
+			zap.Int("pid", lease.pid),
+			zap.Int("transfers", lease.transfers),
+		)
+	}
+	lease.transfers++
+	if lease.idle != nil {
+		lease.idle.Stop()
+		lease.idle = nil
+	}
+}
+
+// keepListener 模式已没有会话时是否保留监听进程供后续传输复用，保留时开始空闲计时，调用方需持有锁
+// 监听进程已服务 max_transfers 个传输或空闲超时后不再保留
+func (ts *TransferService) keepListener(mode string) bool {
+	lease := ts.leases[mode]
+	if !ts.reuseEnabled() || lease == nil || lease.expired {
+		return false
+	}
+	if limit := ts.serverConfig.SessionReuse.MaxTransfers; limit > 0 && lease.transfers >= limit {
+		ts.logger.Info("服务端监听进程已达到复用次数上限",
+			zap.String("mode", mode),
+			zap.Int("pid", lease.pid),
+			zap.Int("transfers", lease.transfers),
+		)
+		return false
+	}
+
+	if lease.idle == nil {
+		var timer *time.Timer
+		timer = time.AfterFunc(ts.reuseIdleTimeout(), func() {
+			ts.releaseListener(mode, lease, timer)
+		})
+		lease.idle = timer
+	}
+	return true
+}
+
+// releaseListener 复用的监听进程空闲超时，没有新的会话时停止
+func (ts *TransferService) releaseListener(mode string, lease *listenerLease, timer *time.Timer) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	// 计时已被新的会话取消或租约已被替换
+	if ts.leases[mode] != lease || lease.idle != timer {
+		return
+	}
+	lease.idle = nil
+	lease.expired = true
+	ts.logger.Info("复用的服务端监听进程空闲超时",
+		zap.String("mode", mode),
+		zap.Int("pid", lease.pid),
+		zap.Int("transfers", lease.transfers),
+	)
+	ts.stopIdleListener(mode)
+}
+
+// dropLease 移除模式的复用记录并取消空闲计时，调用方需持有锁
+func (ts *TransferService) dropLease(mode string) {
+	lease, exists := ts.leases[mode]
+	if !exists {
+		return
+	}
+	if lease.idle != nil {
+		lease.idle.Stop()
+	}
+	delete(ts.leases, mode)
+}
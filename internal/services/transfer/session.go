@@ -0,0 +1,498 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/checksum"
+	"rdma-burst/internal/wrapper"
+	"rdma-burst/pkg/metrics"
+	"rdma-burst/pkg/tracing"
+)
+
+// defaultHeartbeatTimeout 未配置心跳超时时使用的默认值
+const defaultHeartbeatTimeout = 60 * time.Second
+
+// defaultPreparedTTL 未配置准备就绪任务有效期时使用的默认值
+const defaultPreparedTTL = 5 * time.Minute
+
+// expiredSessionRetention 过期或取消的会话保留的时间（心跳超时的倍数），期间客户端的心跳可以获知会话已结束
+const expiredSessionRetention = 10
+
+// sessionCheckpointInterval 执行中的会话把客户端上报的进度写入预写日志的最小间隔
+const sessionCheckpointInterval = 30 * time.Second
+
+// ErrSessionExpired 准备就绪的传输会话因客户端心跳超时已过期
+var ErrSessionExpired = errors.New("传输会话已过期")
+
+// preparedSession 已准备就绪、等待客户端执行的传输会话
+type preparedSession struct {
+	mode          string                 // 服务端监听进程的键（模式，非默认设备为 模式@设备）
+	req           models.TransferRequest // 原始请求，守护进程重启后据此重新启动监听进程
+	task          *models.TransferTask
+	started       bool    // 是否收到过客户端心跳
+	preempted     bool    // 机会型任务被常规任务抢占，会话已结束，客户端通过心跳获知任务已暂停
+	restored      bool    // 守护进程重启后从预写日志恢复的会话
+	rate          float64 // 客户端最近上报的传输速率（MB/s）
+	checkpoint    int64   // 客户端最近上报的检查点（已完整传输的字节偏移），任务失败后据此保留续传状态
+	lastHeartbeat time.Time
+	checkpointAt  time.Time  // 最近一次写入预写日志的时间
+	endedAt       *time.Time // 会话过期或被取消的时间
+}
+
+// heartbeatTimeout 获取心跳超时
+func (ts *TransferService) heartbeatTimeout() time.Duration {
+	if ts.serverConfig != nil && ts.serverConfig.HeartbeatTimeout > 0 {
+		return ts.serverConfig.HeartbeatTimeout
+	}
+	return defaultHeartbeatTimeout
+}
+
+// preparedTTL 获取准备就绪的任务等待客户端开始执行的有效期，不小于心跳超时
+func (ts *TransferService) preparedTTL() time.Duration {
+	ttl := defaultPreparedTTL
+	if ts.serverConfig != nil && ts.serverConfig.PreparedTTL > 0 {
+		ttl = ts.serverConfig.PreparedTTL
+	}
+	if timeout := ts.heartbeatTimeout(); ttl < timeout {
+		return timeout
+	}
+	return ttl
+}
+
+// heartbeatInterval 获取客户端发送心跳的间隔（心跳超时的三分之一）
+func (ts *TransferService) heartbeatInterval() time.Duration {
+	return ts.heartbeatTimeout() / 3
+}
+
+// newServerTask 创建服务端登记的任务记录（准备就绪或延后的任务）
+func newServerTask(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings, modeDecision string) *models.TransferTask {
+	task := models.NewTransferTaskWithServer(req.Filename, req.Mode, req.Direction, "")
+	task.Profile = req.Profile
+	task.QoS, _, _ = resolveQoS(serverConfig, req)
+	task.ModeDecision = modeDecision
+	task.Device = serverConfig.Device
+	task.TraceID = tracing.TraceID(ctx)
+	task.Labels = req.Labels
+	task.Metadata = req.Metadata
+	task.Opportunistic = req.Opportunistic
+	task.Deadline = req.Deadline
+	task.DependsOn = req.DependsOn
+	task.TotalBytes = req.Size
+	task.Staging = req.Staging
+	task.Checksum = req.Checksum
+	task.SourcePath, task.TargetPath = req.SourcePath, req.DestinationPath
+	if req.Direction == models.DirectionGet {
+		task.SourcePath, _ = ServerFilePath(serverConfig, req.Mode, req.Filename)
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		task.Owner = principal.Name
+	}
+	return task
+}
+
+// registerSession 将任务登记为准备就绪的会话并写入预写日志，客户端需要在有效期内开始执行并按心跳超时发送心跳，调用方需持有锁
+// 调用方已准备好服务端监听进程，任务时间线记录 listener_started
+func (ts *TransferService) registerSession(req *models.TransferRequest, task *models.TransferTask) {
+	ts.addTimelineEvent(task, models.EventListenerStarted, time.Now())
+	key := ts.listenerKey(task.Device, req.Mode)
+	ts.useListener(key)
+	session := &preparedSession{
+		mode:          key,
+		req:           *req,
+		task:          task,
+		lastHeartbeat: time.Now(),
+	}
+	ts.addSession(session)
+	ts.recordSession(session)
+}
+
+// addSession 登记会话并启动过期检查，调用方需持有锁
+func (ts *TransferService) addSession(session *preparedSession) {
+	if ts.sessions == nil {
+		ts.sessions = make(map[string]*preparedSession)
+	}
+	ts.sessions[session.task.ID] = session
+
+	if ts.sessionStop == nil {
+		stop := make(chan struct{})
+		ts.sessionStop = stop
+		go ts.runSessionExpiry(stop)
+	}
+}
+
+// Heartbeat 记录客户端心跳；completed 和 failed 结束会话
+func (ts *TransferService) Heartbeat(ctx context.Context, id string, req *models.HeartbeatRequest) (*models.HeartbeatResponse, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	session, err := ts.touchSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.preempted {
+		return &models.HeartbeatResponse{ID: id, Status: session.task.Status}, nil
+	}
+	if !session.task.IsFinished() {
+		ts.finishSession(id, session, req.State, req.Error)
+	}
+	ts.publishSession(session)
+
+	response := &models.HeartbeatResponse{ID: id, Status: session.task.Status}
+	if !session.task.IsFinished() {
+		response.ExpiresAt = session.lastHeartbeat.Add(ts.heartbeatTimeout())
+	}
+	return response, nil
+}
+
+// ReportProgress 记录客户端上报的传输进度，同时视为一次心跳；completed 和 failed 结束会话
+func (ts *TransferService) ReportProgress(ctx context.Context, id string, report *models.ProgressReport) (*models.ProgressResponse, error) {
+	if report.Checksum != nil {
+		if err := checksum.Validate(report.Checksum.Algorithm, report.Checksum.Value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidChecksum, err)
+		}
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	session, err := ts.touchSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.preempted {
+		return ts.buildProgressResponse(session.task, nil), nil
+	}
+
+	if !session.task.IsFinished() {
+		total := report.TotalBytes
+		if total <= 0 {
+			total = session.task.TotalBytes
+		}
+		session.task.UpdateProgress(report.BytesTransferred, total)
+		session.rate = report.TransferRate
+		if report.Checkpoint > 0 {
+			session.checkpoint = report.Checkpoint
+		}
+		// 创建任务时没有附带摘要的 put 使用客户端传输前计算的摘要校验收到的文件
+		if report.State == models.HeartbeatCompleted && report.Checksum != nil && session.task.Checksum == nil && session.task.Direction == models.DirectionPut {
+			session.task.Checksum = report.Checksum
+		}
+		ts.recordTimeline(session.task, report)
+		ts.finishSession(id, session, report.State, report.Error)
+		// 定期把进度写入预写日志，守护进程重启后从最近的检查点恢复会话
+		if !session.task.IsFinished() && time.Since(session.checkpointAt) >= sessionCheckpointInterval {
+			ts.recordSession(session)
+		}
+	}
+	ts.publishSession(session)
+
+	response := ts.buildProgressResponse(session.task, session.progress())
+	response.Restored = session.restored
+	return response, nil
+}
+
+// recordTimeline 按客户端上报的进度记录 rtranfile 启动和首字节事件，调用方需持有锁
+// 启动时间来自客户端时钟，晚于服务端当前时间时使用当前时间
+func (ts *TransferService) recordTimeline(task *models.TransferTask, report *models.ProgressReport) {
+	now := time.Now()
+	if report.ProcessStartedAt != nil {
+		at := *report.ProcessStartedAt
+		if at.After(now) {
+			at = now
+		}
+		ts.addTimelineEvent(task, models.EventProcessSpawned, at)
+	}
+	if report.BytesTransferred > 0 {
+		ts.addTimelineEvent(task, models.EventFirstByte, now)
+	}
+}
+
+// addTimelineEvent 记录准备阶段的生命周期事件，第一次记录时导出从服务端接受请求到该阶段的耗时指标
+// 延后或需要从对象存储拉取源文件的任务包含等待时间窗口和拉取的时间，不计入指标
+func (ts *TransferService) addTimelineEvent(task *models.TransferTask, event string, at time.Time) {
+	if task.HasEvent(event) {
+		return
+	}
+	task.AddEventAt(event, at, "")
+	if task.HasEvent(models.EventDeferred) || task.HasEvent(models.EventStaging) {
+		return
+	}
+	metrics.ObserveSetup(task.Mode, event, task.Timeline[len(task.Timeline)-1].Time.Sub(task.CreatedAt))
+}
+
+// touchSession 查找未过期的会话并记录心跳，收到第一个心跳时任务变为进行中，调用方需持有锁
+// 已取消和被抢占的会话原样返回，客户端根据返回的任务状态停止或暂停传输
+func (ts *TransferService) touchSession(ctx context.Context, id string) (*preparedSession, error) {
+	session, ok := ts.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(session.task.Owner) {
+		return nil, fmt.Errorf("%w: %s", ErrNotOwner, id)
+	}
+	if session.task.Status == models.StatusCancelled || session.preempted {
+		return session, nil
+	}
+	if session.endedAt != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSessionExpired, id)
+	}
+
+	session.lastHeartbeat = time.Now()
+	if !session.started {
+		session.started = true
+		session.task.MarkStarted()
+		session.task.MarkInProgress()
+		session.task.Message = "客户端正在执行传输"
+		ts.recordSession(session)
+	}
+	return session, nil
+}
+
+// finishSession 客户端上报 completed 或 failed 时结束任务并移除会话，调用方需持有锁
+func (ts *TransferService) finishSession(id string, session *preparedSession, state, errorMsg string) {
+	switch state {
+	case models.HeartbeatCompleted, models.HeartbeatFailed:
+		// 会话结束后设备空出名额，启动设备调度队列中的下一个任务
+		defer ts.dispatchDevice(session.task.Device)
+		defer ts.recordSession(session)
+		// 启用会话复用时监听进程按空闲超时和复用次数释放
+		if ts.reuseEnabled() {
+			defer ts.stopIdleListener(session.mode)
+		}
+	}
+
+	switch state {
+	case models.HeartbeatCompleted:
+		session.task.UpdateProgress(session.task.TotalBytes, session.task.TotalBytes)
+		delete(ts.sessions, id)
+		// 续传的 put 先在后台拼接收到的剩余部分
+		if ts.completeResumed(session.task) {
+			return
+		}
+		ts.finishReceived(session.task)
+	case models.HeartbeatFailed:
+		session.task.MarkFailed(errorMsg)
+		session.task.Message = "客户端传输失败"
+		ts.notifyFailed(session.task)
+		// 客户端启用续传且已有完成的分块时保留已接收的部分，否则删除
+		if !ts.retainResumable(session) {
+			ts.discardPartial(session.task)
+		}
+		delete(ts.sessions, id)
+	}
+}
+
+// finishReceived 收到的文件已完整：附带源文件摘要的 put 在后台校验收到的文件，一致后才重命名为目标文件，调用方需持有锁
+func (ts *TransferService) finishReceived(task *models.TransferTask) {
+	if task.Checksum != nil && task.Direction == models.DirectionPut {
+		ts.verifyReceived(task)
+		return
+	}
+	ts.completeSession(task)
+}
+
+// completeSession 客户端校验通过并上报完成后，把临时文件重命名为目标文件，收到的文件需要推送到对象存储时推送完成后任务才完成，调用方需持有锁
+func (ts *TransferService) completeSession(task *models.TransferTask) {
+	if err := ts.completeReceived(task); err != nil {
+		task.MarkFailed(err.Error())
+		ts.notifyFailed(task)
+		ts.logger.Error("完成接收的文件失败", zap.String("task_id", task.ID), zap.Error(err))
+		return
+	}
+	if task.Staging != nil && task.Staging.Push != nil && task.Direction == models.DirectionPut {
+		ts.pushReceived(task)
+		return
+	}
+	ts.missDeadline(task, time.Now())
+	task.MarkCompleted()
+	task.Message = "客户端传输完成"
+}
+
+// sessionProgress 获取执行中的会话的实时进度，任务不是准备就绪的会话时返回 nil，调用方需持有锁
+func (ts *TransferService) sessionProgress(id string) *wrapper.ProgressInfo {
+	session, ok := ts.sessions[id]
+	if !ok || !session.started || session.endedAt != nil {
+		return nil
+	}
+	return session.progress()
+}
+
+// progress 按客户端最近上报的速率计算已用时间和预计剩余时间
+func (s *preparedSession) progress() *wrapper.ProgressInfo {
+	task := s.task
+	info := &wrapper.ProgressInfo{
+		Status:           wrapper.TransferStatus(task.Status),
+		BytesTransferred: task.BytesTransferred,
+		TotalBytes:       task.TotalBytes,
+		ProgressPercent:  task.Progress,
+		TransferRate:     s.rate,
+		StartTime:        task.StartTime,
+		LastUpdateTime:   task.UpdatedAt,
+		Error:            task.Error,
+	}
+	if !task.StartTime.IsZero() {
+		end := time.Now()
+		if task.EndTime != nil {
+			end = *task.EndTime
+		}
+		info.ElapsedTime = end.Sub(task.StartTime)
+	}
+	if !task.IsFinished() && s.rate > 0 && task.TotalBytes > task.BytesTransferred {
+		remaining := float64(task.TotalBytes-task.BytesTransferred) / (s.rate * 1024 * 1024)
+		info.EstimatedTime = time.Duration(remaining) * time.Second
+	}
+	return info
+}
+
+// cancelSession 取消准备就绪的会话，会话不存在时返回 false，调用方需持有锁
+// 会话保留一段时间，执行中的客户端通过心跳获知任务已取消
+func (ts *TransferService) cancelSession(ctx context.Context, id string) (bool, error) {
+	session, ok := ts.sessions[id]
+	if !ok || session.endedAt != nil {
+		return false, nil
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(session.task.Owner) {
+		return true, fmt.Errorf("%w: %s", ErrNotOwner, id)
+	}
+
+	now := time.Now()
+	session.task.MarkCancelled()
+	ts.removeSegment(session.task)
+	ts.discardPartial(session.task)
+	session.endedAt = &now
+	ts.recordSession(session)
+	ts.progress.forget(id)
+	ts.stopIdleListener(session.mode)
+	ts.dispatchDevice(session.task.Device)
+	return true, nil
+}
+
+// runSessionExpiry 定期检查会话心跳，过期的会话释放服务端监听进程
+func (ts *TransferService) runSessionExpiry(stop <-chan struct{}) {
+	ticker := time.NewTicker(ts.heartbeatInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			ts.expireSessions(now)
+		}
+	}
+}
+
+// expireSessions 将过期的会话对应的任务标记为失败，并停止不再使用的监听进程
+// 未开始执行的任务超过准备就绪有效期过期，执行中的任务超过心跳超时过期
+func (ts *TransferService) expireSessions(now time.Time) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	timeout, ttl := ts.heartbeatTimeout(), ts.preparedTTL()
+	modes := make(map[string]bool)
+	devices := make(map[string]bool)
+	for id, session := range ts.sessions {
+		if session.endedAt != nil {
+			if now.Sub(*session.endedAt) > expiredSessionRetention*timeout {
+				delete(ts.sessions, id)
+			}
+			continue
+		}
+
+		var reason string
+		switch {
+		case !session.started && now.Sub(session.lastHeartbeat) > ttl:
+			reason = fmt.Sprintf("准备就绪后 %s 内客户端没有开始执行，任务已过期", ttl)
+		case session.started && now.Sub(session.lastHeartbeat) > timeout:
+			reason = fmt.Sprintf("客户端超过 %s 没有发送心跳，传输会话已过期", timeout)
+		default:
+			continue
+		}
+
+		session.endedAt = &now
+		modes[session.mode] = true
+		devices[session.task.Device] = true
+		ts.progress.forget(id)
+		if !session.task.IsFinished() {
+			session.task.MarkFailed(reason)
+			session.task.Message = "传输会话已过期"
+			ts.notifyFailed(session.task)
+			// 客户端启用续传且已有完成的分块时保留已接收的部分，否则删除
+			if !ts.retainResumable(session) {
+				ts.discardPartial(session.task)
+			}
+			ts.recordSession(session)
+		}
+		ts.logger.Warn("传输会话已过期",
+			zap.String("task_id", id),
+			zap.String("mode", session.mode),
+			zap.Bool("started", session.started),
+			zap.Time("last_heartbeat", session.lastHeartbeat),
+		)
+	}
+
+	for mode := range modes {
+		ts.stopIdleListener(mode)
+	}
+	for device := range devices {
+		ts.dispatchDevice(device)
+	}
+	ts.pruneResumable(now)
+}
+
+// stopIdleListener 监听进程（按 listenerKey）没有未过期的会话和活跃任务时停止该监听进程，常驻监听进程和等待复用的监听进程除外，调用方需持有锁
+func (ts *TransferService) stopIdleListener(mode string) {
+	if ts.isWarm(mode) {
+		return
+	}
+	for _, session := range ts.sessions {
+		if session.mode == mode && session.endedAt == nil {
+			return
+		}
+	}
+	for _, taskWrapper := range ts.activeTasks {
+		if taskWrapper.Config != nil && ts.listenerKey(taskWrapper.Config.Device, string(taskWrapper.Config.Mode)) == mode {
+			return
+		}
+	}
+
+	if ts.keepListener(mode) {
+		return
+	}
+	ts.dropLease(mode)
+
+	processMgr, exists := ts.serverProcesses[mode]
+	if !exists {
+		return
+	}
+	if processMgr.IsRunning() {
+		if err := processMgr.Stop(); err != nil {
+			ts.logger.Error("停止服务端监听进程失败", zap.String("mode", mode), zap.Error(err))
+			return
+		}
+		ts.logger.Info("没有活跃的传输会话，已停止服务端监听进程", zap.String("mode", mode))
+	}
+	delete(ts.serverProcesses, mode)
+}
+
+// stopSessions 停止会话过期检查并取消所有未结束的会话，调用方需持有锁
+func (ts *TransferService) stopSessions() {
+	if ts.sessionStop != nil {
+		close(ts.sessionStop)
+		ts.sessionStop = nil
+	}
+	for id, session := range ts.sessions {
+		if !session.task.IsFinished() {
+			session.task.MarkCancelled()
+		}
+		ts.progress.forget(id)
+		delete(ts.sessions, id)
+	}
+}
@@ -0,0 +1,108 @@
+package transfer
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+)
+
+// shutdownLogInterval 关闭前等待进行中的传输期间记录进度的间隔
+const shutdownLogInterval = 5 * time.Second
+
+// shutdownPollInterval 关闭前检查进行中的传输是否已结束的间隔
+const shutdownPollInterval = 200 * time.Millisecond
+
+// Draining 是否正在关闭、拒绝新的传输
+func (ts *TransferService) Draining() bool {
+	return ts.draining.Load()
+}
+
+// DrainCheck 就绪检查：正在关闭时返回错误，负载均衡不再转发新的请求
+func (ts *TransferService) DrainCheck() error {
+	return ts.checkDraining()
+}
+
+// checkDraining 正在关闭时拒绝新的传输
+func (ts *TransferService) checkDraining() error {
+	if ts.draining.Load() {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// Drain 停止接受新的传输，在 grace 内等待服务端监控的任务、客户端执行中的会话和对象存储暂存结束，期间定期记录进度
+// 返回超时后仍未结束的传输数，调用方随后通过 Cleanup 强制结束；grace 不大于 0 时不等待
+func (ts *TransferService) Drain(grace time.Duration) int {
+	ts.draining.Store(true)
+	if grace <= 0 {
+		return len(ts.inFlight())
+	}
+
+	ts.logger.Info("停止接受新的传输，等待进行中的传输结束", zap.Duration("grace_period", grace))
+	deadline := time.Now().Add(grace)
+	nextLog := time.Now()
+	for {
+		tasks := ts.inFlight()
+		if len(tasks) == 0 {
+			ts.logger.Info("进行中的传输已全部结束")
+			return 0
+		}
+
+		now := time.Now()
+		if !now.Before(deadline) {
+			ts.logger.Warn("等待超时，强制结束进行中的传输", zap.Int("remaining", len(tasks)))
+			logInFlight(ts.logger, tasks)
+			return len(tasks)
+		}
+		if !now.Before(nextLog) {
+			ts.logger.Info("等待进行中的传输结束",
+				zap.Int("remaining", len(tasks)),
+				zap.Duration("time_left", deadline.Sub(now).Round(time.Second)),
+			)
+			logInFlight(ts.logger, tasks)
+			nextLog = now.Add(shutdownLogInterval)
+		}
+		time.Sleep(shutdownPollInterval)
+	}
+}
+
+// inFlight 获取进行中的传输快照：服务端监控的任务、客户端已开始执行的会话和正在暂存的任务
+// 尚未开始执行的准备就绪会话和延后任务不等待，由 Cleanup 取消
+func (ts *TransferService) inFlight() []models.TransferTask {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	var tasks []models.TransferTask
+	for _, taskWrapper := range ts.activeTasks {
+		if !taskWrapper.Task.IsFinished() {
+			tasks = append(tasks, *taskWrapper.Task)
+		}
+	}
+	for _, session := range ts.sessions {
+		if session.started && session.endedAt == nil && !session.task.IsFinished() {
+			tasks = append(tasks, *session.task)
+		}
+	}
+	for _, work := range ts.staging {
+		if !work.task.IsFinished() {
+			tasks = append(tasks, *work.task)
+		}
+	}
+	return tasks
+}
+
+// logInFlight 记录进行中的传输的进度
+func logInFlight(log *zap.Logger, tasks []models.TransferTask) {
+	for _, task := range tasks {
+		log.Info("进行中的传输",
+			zap.String("task_id", task.ID),
+			zap.String("status", task.Status),
+			zap.String("filename", task.Filename),
+			zap.Int64("bytes_transferred", task.BytesTransferred),
+			zap.Int64("total_bytes", task.TotalBytes),
+			zap.Float64("progress", task.Progress),
+		)
+	}
+}
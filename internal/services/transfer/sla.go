@@ -0,0 +1,106 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/metrics"
+)
+
+// slaCheckInterval 检查任务截止时间的间隔
+const slaCheckInterval = 10 * time.Second
+
+// ErrDeadlinePassed 请求的截止时间早于当前时间
+var ErrDeadlinePassed = errors.New("截止时间已过")
+
+// checkDeadline 检查请求的截止时间，没有设置时不限制
+func checkDeadline(req *models.TransferRequest, now time.Time) error {
+	if req.Deadline != nil && !req.Deadline.After(now) {
+		return fmt.Errorf("%w: %s", ErrDeadlinePassed, req.Deadline.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// earlierDeadline 按截止时间判断任务是否应排在另一个任务之前：有截止时间的任务排在没有的之前，截止时间早的优先
+func earlierDeadline(task, other *models.TransferTask) bool {
+	switch {
+	case task.Deadline == nil:
+		return false
+	case other.Deadline == nil:
+		return true
+	}
+	return task.Deadline.Before(*other.Deadline)
+}
+
+// deadlineOrder 同时可以开始的任务的准备顺序：按截止时间，截止时间相同或都没有截止时间时按提交顺序
+func deadlineOrder(task, other *models.TransferTask) bool {
+	if earlierDeadline(task, other) != earlierDeadline(other, task) {
+		return earlierDeadline(task, other)
+	}
+	return task.CreatedAt.Before(other.CreatedAt)
+}
+
+// startDeadlineWatch 启动截止时间检查，只启动一次
+func (ts *TransferService) startDeadlineWatch() {
+	ts.deadlineOnce.Do(func() {
+		ts.mu.Lock()
+		stop := make(chan struct{})
+		ts.deadlineStop = stop
+		ts.mu.Unlock()
+		go ts.runDeadlineWatch(stop)
+	})
+}
+
+// runDeadlineWatch 定期检查未结束的任务是否超过截止时间
+func (ts *TransferService) runDeadlineWatch(stop <-chan struct{}) {
+	ticker := time.NewTicker(slaCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			ts.checkDeadlines(now)
+		}
+	}
+}
+
+// checkDeadlines 为超过截止时间仍未结束的任务记录 sla_missed
+func (ts *TransferService) checkDeadlines(now time.Time) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for _, task := range ts.taskHistory {
+		if !task.IsFinished() {
+			ts.missDeadline(task, now)
+		}
+	}
+}
+
+// missDeadline 任务在截止时间之后仍未完成时在时间线中记录 sla_missed 并计入指标，同一任务只记录一次，调用方需持有锁
+// 事件随任务的下一次状态变化写入预写日志
+func (ts *TransferService) missDeadline(task *models.TransferTask, now time.Time) {
+	if task.Deadline == nil || !now.After(*task.Deadline) || task.HasEvent(models.EventSLAMissed) {
+		return
+	}
+	task.AddEventAt(models.EventSLAMissed, now, task.Deadline.Format(time.RFC3339))
+	metrics.SLAMissed(task.Mode, task.Device)
+	ts.logger.Warn("传输任务未能在截止时间前完成",
+		zap.String("task_id", task.ID),
+		zap.String("status", task.Status),
+		zap.Time("deadline", *task.Deadline),
+	)
+}
+
+// stopDeadlineWatch 停止截止时间检查，调用方需持有锁
+func (ts *TransferService) stopDeadlineWatch() {
+	if ts.deadlineStop != nil {
+		close(ts.deadlineStop)
+		ts.deadlineStop = nil
+	}
+}
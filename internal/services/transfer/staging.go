@@ -0,0 +1,265 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/staging"
+)
+
+// stagingCheckInterval 客户端轮询服务端拉取源文件进度的间隔
+const stagingCheckInterval = 5 * time.Second
+
+// stagingWork 正在从对象存储拉取源文件或推送收到的文件的任务
+type stagingWork struct {
+	task   *models.TransferTask
+	cancel context.CancelFunc
+}
+
+// sourcePull 获取发送端需要在传输前从对象存储拉取的对象，客户端为 put 的发送端，服务端为 get 的发送端
+func sourcePull(req *models.TransferRequest, server bool) *models.ObjectRef {
+	if req.Staging == nil || req.IsVerify() {
+		return nil
+	}
+	if (req.Direction == models.DirectionGet) == server {
+		return req.Staging.Pull
+	}
+	return nil
+}
+
+// receiverPush 获取接收端需要在传输完成后推送到对象存储的对象，服务端为 put 的接收端，客户端为 get 的接收端
+func receiverPush(req *models.TransferRequest, server bool) *models.ObjectRef {
+	if req.Staging == nil || req.IsVerify() {
+		return nil
+	}
+	if (req.Direction == models.DirectionPut) == server {
+		return req.Staging.Push
+	}
+	return nil
+}
+
+// checkStaging 检查本端需要执行的拉取和推送引用的对象存储是否已配置
+func checkStaging(settings *models.TransferSettings, req *models.TransferRequest, server bool) error {
+	var stores map[string]models.ObjectStoreSettings
+	if settings != nil {
+		stores = settings.ObjectStores
+	}
+	stager := staging.New(stores)
+	for _, ref := range []*models.ObjectRef{sourcePull(req, server), receiverPush(req, server)} {
+		if ref == nil {
+			continue
+		}
+		if err := stager.Check(ref); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidStaging, err)
+		}
+	}
+	if server && sourcePull(req, server) != nil && req.Mode == models.ModeAuto {
+		return fmt.Errorf("%w: 从对象存储拉取源文件的 get 请求需要指定传输模式", ErrInvalidStaging)
+	}
+	return nil
+}
+
+// stageSize get 请求的源文件需要从对象存储拉取时，以对象大小作为文件大小
+func stageSize(ctx context.Context, settings *models.TransferSettings, req *models.TransferRequest) error {
+	ref := sourcePull(req, true)
+	if ref == nil {
+		return nil
+	}
+	size, err := staging.New(settings.ObjectStores).Size(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrStagingUnavailable, err)
+	}
+	req.Size = size
+	return nil
+}
+
+// receivingPath 获取 put 请求在服务端接收的文件路径，target 为按冲突策略选择的文件名
+func receivingPath(settings *models.TransferSettings, req *models.TransferRequest, target string) string {
+	if req.Direction != models.DirectionPut || req.IsVerify() {
+		return ""
+	}
+	name := req.Filename
+	if target != "" {
+		name = target
+	}
+	path, _ := ServerFilePath(settings, req.Mode, name)
+	return path
+}
+
+// stageTransfer 在后台从对象存储拉取 get 请求的源文件，完成后准备传输环境并登记为准备就绪的会话
+// 守护进程重启后从预写日志恢复的拉取任务同样由此重新拉取
+func (ts *TransferService) stageTransfer(ctx context.Context, task *models.TransferTask, req models.TransferRequest, settings models.TransferSettings) {
+	ref := sourcePull(&req, true)
+	ctx, cancel := context.WithCancel(ctx)
+
+	ts.mu.Lock()
+	ts.trackStaging(task, cancel)
+	task.Status = models.StatusStaging
+	task.AddEvent(models.EventStaging, staging.Describe(ref))
+	task.Message = fmt.Sprintf("正在从对象存储拉取 %s", staging.Describe(ref))
+	task.UpdatedAt = time.Now()
+	// 连同请求写入预写日志，守护进程重启后重新拉取
+	ts.recordTask(task, &req)
+	ts.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		path, err := ServerFilePath(&settings, req.Mode, req.Filename)
+		if err == nil {
+			err = ts.ensureDirectoryExists(filepath.Dir(path))
+		}
+		if err == nil {
+			var size int64
+			if size, err = staging.New(settings.ObjectStores).Pull(ctx, ref, path, nil); err == nil {
+				req.Size = size
+			}
+		}
+		reserved := false
+		if err == nil {
+			// 拉取完成后按设备的调度队列准备传输环境，设备已满时任务进入队列，由队列准备传输环境
+			ts.mu.Lock()
+			if task.Status != models.StatusCancelled {
+				task.TotalBytes = req.Size
+				reserved = ts.reserveDevice(ctx, task, &req, &settings)
+				if !reserved {
+					delete(ts.staging, task.ID)
+					ts.mu.Unlock()
+					return
+				}
+			}
+			ts.mu.Unlock()
+			if reserved {
+				err = ts.PrepareTransfer(ctx, &req, &settings)
+			}
+		}
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		delete(ts.staging, task.ID)
+		if reserved {
+			ts.releaseDevice(task)
+			if err != nil || task.Status == models.StatusCancelled {
+				ts.dispatchDevice(settings.Device)
+			}
+		}
+		if task.Status == models.StatusCancelled {
+			return
+		}
+		if err != nil {
+			task.MarkFailed(fmt.Sprintf("从对象存储拉取源文件失败: %v", err))
+			ts.recordTask(task, nil)
+			ts.notifyFailed(task)
+			ts.logger.Error("拉取源文件失败", zap.String("task_id", task.ID), zap.Error(err))
+			return
+		}
+		task.TotalBytes = req.Size
+		task.Status = models.StatusPrepared
+		task.Message = "源文件已从对象存储拉取，传输环境准备就绪"
+		task.UpdatedAt = time.Now()
+		ts.registerSession(&req, task)
+	}()
+}
+
+// pushReceived 客户端上报 put 传输完成后，在后台把服务端收到的文件推送到对象存储，推送完成后任务才完成，调用方需持有锁
+func (ts *TransferService) pushReceived(task *models.TransferTask) {
+	ref := task.Staging.Push
+	ctx, cancel := context.WithCancel(context.Background())
+	ts.trackStaging(task, cancel)
+	task.Message = fmt.Sprintf("客户端传输完成，正在推送到对象存储 %s", staging.Describe(ref))
+	task.UpdatedAt = time.Now()
+	stores := ts.serverConfig.ObjectStores
+
+	go func() {
+		defer cancel()
+		_, err := staging.New(stores).Push(ctx, ref, task.TargetPath)
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		delete(ts.staging, task.ID)
+		if task.Status == models.StatusCancelled {
+			return
+		}
+		defer ts.recordTask(task, nil)
+		if err != nil {
+			task.MarkFailed(fmt.Sprintf("推送到对象存储失败: %v", err))
+			ts.notifyFailed(task)
+			ts.logger.Error("推送文件到对象存储失败", zap.String("task_id", task.ID), zap.Error(err))
+			return
+		}
+		task.MarkCompleted()
+		task.Message = fmt.Sprintf("传输完成，已推送到对象存储 %s", staging.Describe(ref))
+	}()
+}
+
+// trackStaging 登记正在拉取或推送对象的任务，调用方需持有锁
+func (ts *TransferService) trackStaging(task *models.TransferTask, cancel context.CancelFunc) {
+	if ts.staging == nil {
+		ts.staging = make(map[string]*stagingWork)
+	}
+	ts.staging[task.ID] = &stagingWork{task: task, cancel: cancel}
+}
+
+// cancelStaging 取消正在拉取或推送对象的任务，任务不存在时返回 false，调用方需持有锁
+func (ts *TransferService) cancelStaging(ctx context.Context, taskID string) (bool, error) {
+	work, ok := ts.staging[taskID]
+	if !ok {
+		return false, nil
+	}
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(work.task.Owner) {
+		return true, fmt.Errorf("%w: %s", ErrNotOwner, taskID)
+	}
+
+	work.cancel()
+	work.task.MarkCancelled()
+	ts.recordTask(work.task, nil)
+	delete(ts.staging, taskID)
+	return true, nil
+}
+
+// stopStaging 取消所有正在拉取或推送对象的任务，调用方需持有锁
+// 取消不写入预写日志，守护进程重启后正在拉取的任务会从日志中恢复并重新拉取
+func (ts *TransferService) stopStaging() {
+	for id, work := range ts.staging {
+		work.cancel()
+		work.task.MarkCancelled()
+		delete(ts.staging, id)
+	}
+}
+
+// pullSource 客户端执行 put 前从对象存储拉取源文件到请求的本地路径，tee 不为 nil 时同时写入拉取的数据（例如计算源文件摘要）
+func (cts *ClientTransferService) pullSource(ctx context.Context, req *models.TransferRequest, tee io.Writer, log *zap.Logger) error {
+	ref := sourcePull(req, false)
+	if ref == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(req.Filename), 0755); err != nil {
+		return fmt.Errorf("创建本地目录失败: %v", err)
+	}
+	log.Info("正在从对象存储拉取源文件", zap.String("object", staging.Describe(ref)), zap.String("path", req.Filename))
+	if _, err := staging.New(cts.config.ObjectStores).Pull(ctx, ref, req.Filename, tee); err != nil {
+		return fmt.Errorf("%w: %v", ErrStagingUnavailable, err)
+	}
+	return nil
+}
+
+// pushReceived 客户端 get 完成后把收到的文件推送到对象存储
+func (cts *ClientTransferService) pushReceived(ctx context.Context, req *models.TransferRequest, path string, log *zap.Logger) error {
+	ref := receiverPush(req, false)
+	if ref == nil {
+		return nil
+	}
+	log.Info("正在推送文件到对象存储", zap.String("object", staging.Describe(ref)), zap.String("path", path))
+	if _, err := staging.New(cts.config.ObjectStores).Push(ctx, ref, path); err != nil {
+		return fmt.Errorf("%w: %v", ErrStagingUnavailable, err)
+	}
+	return nil
+}
@@ -4,11 +4,28 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
 	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/accounting"
+	"rdma-burst/internal/services/fault"
+	"rdma-burst/internal/services/hugepool"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/journal"
+	"rdma-burst/internal/services/notify"
+	"rdma-burst/internal/services/schedule"
+	"rdma-burst/internal/services/staging"
 	"rdma-burst/internal/wrapper"
+	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/metrics"
+	"rdma-burst/pkg/tracing"
 )
 
 // TransferService 传输服务
@@ -24,8 +41,39 @@ type TransferService struct {
 	singleTransfer   bool
 	requireReconnect bool
 	activeConnections map[string]time.Time // 活跃连接映射
+	connectionUses   map[string]int           // 启用会话复用时各连接已服务的传输数
 	serverProcesses  map[string]*wrapper.ProcessManager // 服务端进程映射
 	serverConfig     *models.TransferSettings // 服务端配置
+	deviceCheck      func() error             // RDMA 设备可用性检查
+	leaderCheck      func() bool              // 协调者选主，返回 false 时不启动延后任务
+	progressInterval time.Duration            // 读取传输日志和更新任务进度的间隔，为 0 时使用默认值
+	journal          *journal.Journal         // 任务状态预写日志
+	deferred         map[string]*deferredTransfer // 等待时间窗口开放的任务
+	blocked          map[string]*deferredTransfer // 等待依赖的任务完成的任务
+	devices          map[string]*deviceQueue      // 各 RDMA 设备的调度队列
+	notifier         *notify.Notifier         // 任务事件通知
+	accounting       *accounting.Ledger       // 按租户统计用量
+	faults           *fault.Injector          // 故障注入，未启用时为 nil
+	sessions         map[string]*preparedSession // 等待客户端心跳的准备就绪会话
+	staging          map[string]*stagingWork     // 正在从对象存储拉取或推送到对象存储的任务
+	resumable        map[string]*resumableTransfer // 失败后保留了续传状态的任务
+	ownerLimits      map[string]int                // 调用方的并发任务上限，排队和延后的任务启动时按 owner 检查
+	requests         map[string]models.TransferRequest // 服务端登记的任务的原始请求，任务失败或被取消后据此重试
+	sessionStop      chan struct{}
+	schedulerOnce    sync.Once
+	schedulerStop    chan struct{}
+	deadlineOnce     sync.Once
+	deadlineStop     chan struct{}
+	dependencyOnce   sync.Once
+	dependencyStop   chan struct{}
+	progress         progressCache            // 执行中任务的进度快照，状态查询无锁读取
+	warm             warmListeners            // 常驻监听进程的健康检查
+	autoscale        autoscaler               // 按链路利用率自动调整 maxConcurrent
+	capabilities     capabilityCache          // RDMA 设备能力，准备传输时预检链路饱和和 MTU 不匹配
+	hugepool         *hugepool.Pool           // 大页缓冲池，未启用时为 nil
+	leases           map[string]*listenerLease // 会话复用时各模式监听进程的使用情况
+	draining         atomic.Bool              // 正在关闭，拒绝新的传输
+	logger           *zap.Logger
 }
 
 // TransferTask 传输任务包装器
@@ -46,11 +94,12 @@ func NewTransferService(rtranfilePath string, maxConcurrent int, transferInterva
 		taskHistory:      make([]*models.TransferTask, 0),
 		maxConcurrent:    maxConcurrent,
 		transferInterval: transferInterval,
-		lastTransferTime: time.Now(),
 		singleTransfer:   true,
 		requireReconnect: true,
 		activeConnections: make(map[string]time.Time),
+		connectionUses:   make(map[string]int),
 		serverProcesses:  make(map[string]*wrapper.ProcessManager),
+		logger:           logger.GetLogger().Named(logger.ComponentTransfer),
 	}
 }
 
@@ -63,11 +112,15 @@ func NewTransferServiceWithConfig(rtranfilePath string, config *models.TransferS
 		taskHistory:      make([]*models.TransferTask, 0),
 		maxConcurrent:    config.MaxConcurrentTransfers,
 		transferInterval: config.TransferInterval,
-		lastTransferTime: time.Now(),
 		activeConnections: make(map[string]time.Time),
+		connectionUses:   make(map[string]int),
 		serverProcesses:  make(map[string]*wrapper.ProcessManager),
 		serverConfig:     config,
+		faults:           fault.New(config.Faults),
+		logger:           logger.GetLogger().Named(logger.ComponentTransfer),
 	}
+	service.processMgr.SetTimeouts(config.ProcessStopTimeout, config.ProcessCleanupTimeout)
+	service.checkNetworkFilesystems(config)
 
 	if singleTransferConfig != nil {
 		service.singleTransfer = singleTransferConfig.Enabled
@@ -77,8 +130,68 @@ func NewTransferServiceWithConfig(rtranfilePath string, config *models.TransferS
 	return service
 }
 
+// newProcessManager 创建按配置的停止和清理超时等待 rtranfile 进程退出的进程管理器
+func (ts *TransferService) newProcessManager() *wrapper.ProcessManager {
+	process := wrapper.NewProcessManager()
+	if ts.serverConfig != nil {
+		process.SetTimeouts(ts.serverConfig.ProcessStopTimeout, ts.serverConfig.ProcessCleanupTimeout)
+	}
+	return process
+}
+
+// newMonitor 创建按进度更新间隔读取 rtranfile 日志的监控器
+func (ts *TransferService) newMonitor(logFile string) *wrapper.TransferMonitor {
+	monitor := wrapper.NewTransferMonitor(logFile)
+	monitor.SetInterval(ts.progressInterval)
+	return monitor
+}
+
+// SetLogger 设置日志器
+func (ts *TransferService) SetLogger(logger *zap.Logger) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.logger = logger
+}
+
+// SetDeviceCheck 设置 RDMA 设备可用性检查，设备不可用时拒绝准备和启动传输
+func (ts *TransferService) SetDeviceCheck(check func() error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.deviceCheck = check
+}
+
+// SetLeaderCheck 设置协调者领导权检查，不是领导者时延后任务保持等待，由领导者调度
+func (ts *TransferService) SetLeaderCheck(check func() bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.leaderCheck = check
+}
+
+// SetProgressInterval 设置进度更新间隔（monitoring.client.progress_update_interval），
+// 服务端监控的任务按该间隔读取传输日志和更新进度，为 0 时分别使用 100ms 和 1s
+func (ts *TransferService) SetProgressInterval(interval time.Duration) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.progressInterval = interval
+}
+
 // PrepareTransfer 准备传输环境（启动服务端监听进程）
-func (ts *TransferService) PrepareTransfer(req *models.TransferRequest, serverConfig *models.TransferSettings) error {
+func (ts *TransferService) PrepareTransfer(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (err error) {
+	ctx, span := tracing.Start(ctx, "transfer.prepare",
+		attribute.String("transfer.filename", req.Filename),
+		attribute.String("transfer.mode", req.Mode),
+		attribute.String("transfer.direction", req.Direction),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	// 检查 RDMA 设备
+	if err := ts.checkDevice(); err != nil {
+		return err
+	}
+
 	// 构建传输配置
 	transferConfig, err := ts.buildTransferConfig(req, serverConfig)
 	if err != nil {
@@ -86,25 +199,30 @@ func (ts *TransferService) PrepareTransfer(req *models.TransferRequest, serverCo
 	}
 
 	// 启动服务端监听进程
-	if err := ts.ensureServerProcessStarted(transferConfig); err != nil {
+	if err := ts.ensureServerProcessStarted(ctx, transferConfig); err != nil {
 		return fmt.Errorf("启动服务端监听进程失败: %v", err)
 	}
+	span.AddEvent("server_process_started")
 
 	// 等待服务端进程启动
 	timeout := time.After(5 * time.Second)
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 	
-	serverStarted := false
+	// 监听进程已在运行（常驻或被复用）时不再等待
+	key := ts.listenerKey(transferConfig.Device, string(transferConfig.Mode))
+	serverStarted := ts.listenerRunning(key)
 	attempts := 0
 	for !serverStarted {
 		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待服务端进程启动被取消: %v", ctx.Err())
 		case <-timeout:
 			return fmt.Errorf("服务端进程启动超时（等待了5秒）")
 		case <-ticker.C:
 			attempts++
 			ts.mu.RLock()
-			processMgr, exists := ts.serverProcesses[string(transferConfig.Mode)]
+			processMgr, exists := ts.serverProcesses[key]
 			ts.mu.RUnlock()
 			
 			if exists && processMgr.IsRunning() {
@@ -114,8 +232,11 @@ func (ts *TransferService) PrepareTransfer(req *models.TransferRequest, serverCo
 			
 			// 记录调试信息
 			if attempts%2 == 0 { // 每1秒记录一次
-				fmt.Printf("等待服务端进程启动... 尝试次数: %d, 模式: %s, 进程存在: %v\n",
-					attempts, transferConfig.Mode, exists)
+				ts.logger.Debug("等待服务端进程启动",
+					zap.Int("attempts", attempts),
+					zap.String("mode", string(transferConfig.Mode)),
+					zap.Bool("process_exists", exists),
+				)
 			}
 		}
 	}
@@ -123,14 +244,234 @@ func (ts *TransferService) PrepareTransfer(req *models.TransferRequest, serverCo
 	return nil
 }
 
+// Prepare 准备传输环境并返回准备就绪响应
+// 服务端只负责启动监听进程，客户端收到响应后在自己的机器上执行传输命令
+func (ts *TransferService) Prepare(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	if err := ts.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	// 准入钩子可以拒绝或修改请求，后续检查使用修改后的请求
+	if err := ts.admit(ctx, req, serverConfig); err != nil {
+		return nil, err
+	}
+	if err := checkStaging(serverConfig, req, true); err != nil {
+		return nil, err
+	}
+	if err := checkChecksum(req); err != nil {
+		return nil, err
+	}
+	if err := checkDeadline(req, time.Now()); err != nil {
+		return nil, err
+	}
+	// 依赖的任务必须存在且没有失败，尚未完成时任务等待
+	var pending []string
+	if len(req.DependsOn) > 0 {
+		principal, _ := auth.FromContext(ctx)
+		ts.mu.RLock()
+		var err error
+		pending, err = ts.pendingDependencies(principal, req.DependsOn)
+		ts.mu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 配置档案的时间窗口未开放时延后执行
+	windows, err := profileWindows(serverConfig, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := resolveQoS(serverConfig, req); err != nil {
+		return nil, err
+	}
+	// 请求其他 RDMA 设备时按该设备准备监听进程，任务使用该设备的调度队列
+	device, err := resolveDevice(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+	serverConfig = withDevice(serverConfig, device)
+	decision, err := ResolveAutoMode(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkModeEnabled(serverConfig, req); err != nil {
+		return nil, err
+	}
+	forced, err := ts.applyNetworkFSPolicy(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+	decision = joinDecision(decision, forced)
+	fillFileSize(serverConfig, req)
+	if err := stageSize(ctx, serverConfig, req); err != nil {
+		return nil, err
+	}
+	if err := checkFileSize(serverConfig, req); err != nil {
+		return nil, err
+	}
+	warnings := ts.preflight(serverConfig, req)
+
+	// put 时服务端为接收端，按冲突策略处理已存在的同名文件
+	target, err := prepareReceivingTarget(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 启用临时后缀时客户端以临时文件名发送，客户端上报完成后服务端再重命名为目标文件
+	sendAs := partialTarget(serverConfig, req, target)
+
+	if len(pending) > 0 {
+		response := ts.blockTransfer(ctx, req, serverConfig, windows, pending, decision, target)
+		response.TargetFilename = sendAs
+		response.Warnings = warnings
+		return response, nil
+	}
+	if now := time.Now(); !schedule.InAny(windows, now) {
+		response := ts.deferTransfer(ctx, req, serverConfig, windows, now, decision, target)
+		response.TargetFilename = sendAs
+		response.Warnings = warnings
+		return response, nil
+	}
+
+	task := newServerTask(ctx, req, serverConfig, decision)
+	task.TargetPath = receivingPath(serverConfig, req, target)
+	if task.Deadline != nil {
+		ts.startDeadlineWatch()
+	}
+
+	// 源文件需要从对象存储拉取时在后台拉取，完成后才准备传输环境，客户端等待任务变为 prepared
+	// 超过并发上限时拒绝，已排队和正在拉取源文件的任务一起计数
+	if sourcePull(req, true) != nil {
+		ts.mu.Lock()
+		if err := ts.admitTask(ctx, task); err != nil {
+			ts.mu.Unlock()
+			return nil, err
+		}
+		ts.taskHistory = append(ts.taskHistory, task)
+		ts.rememberRequest(task, req)
+		ts.mu.Unlock()
+		ts.stageTransfer(tracing.Detach(ctx), task, *req, *serverConfig)
+	} else {
+		// 设备的并发传输数已满时进入设备的调度队列，客户端等待任务变为 prepared
+		ts.mu.Lock()
+		if err := ts.admitTask(ctx, task); err != nil {
+			ts.mu.Unlock()
+			return nil, err
+		}
+		reserved := ts.reserveDevice(ctx, task, req, serverConfig)
+		if !reserved {
+			ts.taskHistory = append(ts.taskHistory, task)
+			ts.rememberRequest(task, req)
+		}
+		ts.mu.Unlock()
+
+		if reserved {
+			if err := ts.PrepareTransfer(ctx, req, serverConfig); err != nil {
+				ts.mu.Lock()
+				ts.releaseDevice(task)
+				ts.dispatchDevice(device)
+				ts.mu.Unlock()
+				return nil, err
+			}
+
+			// 登记为准备就绪的任务：客户端需要在有效期内开始执行并定期发送心跳，否则任务过期并释放监听进程
+			task.Status = models.StatusPrepared
+			task.Message = "传输环境准备就绪，请在客户端执行传输命令"
+			ts.mu.Lock()
+			ts.releaseDevice(task)
+			ts.taskHistory = append(ts.taskHistory, task)
+			ts.rememberRequest(task, req)
+			ts.registerSession(req, task)
+			ts.mu.Unlock()
+			ts.leaseHugepages(task, req)
+		}
+	}
+
+	ts.mu.RLock()
+	status, message := task.Status, task.Message
+	ts.mu.RUnlock()
+
+	return &models.TransferResponse{
+		ID:                task.ID,
+		Status:            status,
+		Message:           message,
+		Mode:              task.Mode,
+		ModeDecision:      decision,
+		Size:              req.Size,
+		TargetFilename:    sendAs,
+		HeartbeatInterval: ts.heartbeatInterval(),
+		Warnings:          warnings,
+		TraceID:           task.TraceID,
+		CreatedAt:         task.CreatedAt,
+	}, nil
+}
+
 // StartTransfer 启动传输任务
-func (ts *TransferService) StartTransfer(req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+func (ts *TransferService) StartTransfer(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	ctx, span := tracing.Start(ctx, "transfer.start",
+		attribute.String("transfer.filename", req.Filename),
+		attribute.String("transfer.mode", req.Mode),
+		attribute.String("transfer.direction", req.Direction),
+	)
+	defer span.End()
+
+	if err := ts.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	// 检查 RDMA 设备
+	if err := ts.checkDevice(); err != nil {
+		return nil, err
+	}
+
+	// auto 模式先选择实际的传输模式，之后的模式并发限制按选择结果计算
+	decision, err := ResolveAutoMode(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkModeEnabled(serverConfig, req); err != nil {
+		return nil, err
+	}
+	forced, err := ts.applyNetworkFSPolicy(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+	decision = joinDecision(decision, forced)
+	fillFileSize(serverConfig, req)
+	if err := checkFileSize(serverConfig, req); err != nil {
+		return nil, err
+	}
+
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
 	// 检查并发限制
-	if len(ts.activeTasks) >= ts.maxConcurrent {
-		return nil, fmt.Errorf("已达到最大并发传输限制 (%d)", ts.maxConcurrent)
+	if len(ts.liveTasks("", true)) >= ts.maxConcurrent {
+		ts.autoscale.rejected.Add(1)
+		return nil, fmt.Errorf("%w (%d)", ErrConcurrencyLimit, ts.maxConcurrent)
+	}
+
+	// 检查传输方向和传输模式的并发限制
+	if err := checkTaskLimits(serverConfig, req.Direction, req.Mode, ts.liveTasks("", true)); err != nil {
+		return nil, err
+	}
+
+	// 检查调用方的并发限制
+	principal, _ := auth.FromContext(ctx)
+	if principal != nil && principal.MaxConcurrentTasks > 0 {
+		if ts.countOwnerTasks(principal.Name) >= principal.MaxConcurrentTasks {
+			return nil, fmt.Errorf("%w: 调用方 %s 最多同时运行 %d 个任务", ErrConcurrencyLimit, principal.Name, principal.MaxConcurrentTasks)
+		}
+	}
+
+	// 检查 QoS 等级的并发份额
+	qosName, qosClass, err := resolveQoS(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.checkQoSLimit(qosName, ts.liveTasks("", true)); err != nil {
+		return nil, err
 	}
 
 	// 检查传输间隔
@@ -138,23 +479,34 @@ func (ts *TransferService) StartTransfer(req *models.TransferRequest, serverConf
 		return nil, err
 	}
 
-	// 检查单次传输连接要求
+	// 检查单次传输连接要求（启用会话复用时连接可以被后续传输复用）
 	if ts.singleTransfer && ts.requireReconnect {
 		// 使用配置中的默认服务端地址，而不是请求中的 server_ip
 		connectionKey := ts.getConnectionKeyWithConfig(req, serverConfig)
-		if ts.isConnectionActive(connectionKey) {
+		if ts.isConnectionActive(connectionKey) && !ts.reuseConnection(connectionKey) {
 			return nil, fmt.Errorf("需要重新建立连接才能开始新的传输")
 		}
 	}
 
 	// 创建传输任务（使用配置中的服务端地址）
 	task := models.NewTransferTaskWithServer(req.Filename, req.Mode, req.Direction, "")
+	task.TraceID = tracing.TraceID(ctx)
+	task.Labels = req.Labels
+	task.Metadata = req.Metadata
+	task.QoS = qosName
+	task.ModeDecision = decision
+	task.Device = serverConfig.Device
+	if principal != nil {
+		task.Owner = principal.Name
+	}
+	span.SetAttributes(attribute.String("transfer.task_id", task.ID))
 	
 	// 构建传输配置
 	transferConfig, err := ts.buildTransferConfig(req, serverConfig)
 	if err != nil {
 		return nil, err
 	}
+	applyQoS(transferConfig, qosClass)
 
 	// 验证配置
 	if err := ts.rtranfile.ValidateConfig(transferConfig); err != nil {
@@ -165,38 +517,53 @@ func (ts *TransferService) StartTransfer(req *models.TransferRequest, serverConf
 	transferTask := &TransferTask{
 		Task:    task,
 		Config:  transferConfig,
-		Monitor: wrapper.NewTransferMonitor(transferConfig.LogFile),
-		Process: wrapper.NewProcessManager(),
+		Monitor: ts.newMonitor(transferConfig.LogFile),
+		Process: ts.newProcessManager(),
 	}
 
 	// 启动传输任务（无论是客户端还是服务端传输）
-	if err := ts.startTransferTask(transferTask); err != nil {
+	if err := ts.startTransferTask(ctx, transferTask); err != nil {
+		tracing.RecordError(span, err)
 		return nil, err
 	}
 
 	// 添加到活跃任务
 	ts.activeTasks[task.ID] = transferTask
+	ts.progress.track(task.ID, ts.buildProgressResponse(task, nil))
+	metrics.TransferStarted()
 	ts.taskHistory = append(ts.taskHistory, task)
+	ts.record(transferTask)
 
 	// 记录连接（如果是单次传输模式）
 	if ts.singleTransfer {
 		connectionKey := ts.getConnectionKeyWithConfig(req, serverConfig)
 		ts.activeConnections[connectionKey] = time.Now()
+		if ts.reuseEnabled() {
+			ts.connectionUses[connectionKey]++
+		}
 	}
 
 	// 更新最后传输时间
 	ts.updateLastTransferTime()
 
 	return &models.TransferResponse{
-		ID:        task.ID,
-		Status:    task.Status,
-		Message:   "传输任务已启动",
-		CreatedAt: task.CreatedAt,
+		ID:           task.ID,
+		Status:       task.Status,
+		Message:      "传输任务已启动",
+		Mode:         task.Mode,
+		ModeDecision: decision,
+		TraceID:      task.TraceID,
+		CreatedAt:    task.CreatedAt,
 	}, nil
 }
 
 // GetTransferStatus 获取传输状态
 func (ts *TransferService) GetTransferStatus(taskID string) (*models.ProgressResponse, error) {
+	// 执行中的任务读取最近发布的进度快照，频繁的状态查询不与任务管理竞争服务锁
+	if resp, ok := ts.progress.load(taskID); ok {
+		return resp, nil
+	}
+
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 
@@ -205,10 +572,11 @@ func (ts *TransferService) GetTransferStatus(taskID string) (*models.ProgressRes
 		// 检查历史任务
 		for _, task := range ts.taskHistory {
 			if task.ID == taskID {
-				return ts.buildProgressResponse(task, nil), nil
+				// 客户端执行的任务使用客户端上报的进度
+				return ts.buildProgressResponse(task, ts.sessionProgress(taskID)), nil
 			}
 		}
-		return nil, fmt.Errorf("任务不存在: %s", taskID)
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
 	}
 
 	// 获取实时进度
@@ -217,13 +585,45 @@ func (ts *TransferService) GetTransferStatus(taskID string) (*models.ProgressRes
 }
 
 // CancelTransfer 取消传输任务
-func (ts *TransferService) CancelTransfer(taskID string) error {
+func (ts *TransferService) CancelTransfer(ctx context.Context, taskID string) (err error) {
+	_, span := tracing.Start(ctx, "transfer.cancel", attribute.String("transfer.task_id", taskID))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
+	// 延后任务直接取消
+	if found, err := ts.cancelDeferred(ctx, taskID); found {
+		return err
+	}
+	// 准备就绪的会话取消后释放监听进程
+	if found, err := ts.cancelSession(ctx, taskID); found {
+		return err
+	}
+	// 等待依赖的任务直接取消
+	if found, err := ts.cancelBlocked(ctx, taskID); found {
+		return err
+	}
+	// 在设备调度队列中等待的任务直接取消
+	if found, err := ts.cancelQueued(ctx, taskID); found {
+		return err
+	}
+	// 正在拉取或推送对象的任务中止对象存储请求
+	if found, err := ts.cancelStaging(ctx, taskID); found {
+		return err
+	}
+
 	taskWrapper, exists := ts.activeTasks[taskID]
 	if !exists {
-		return fmt.Errorf("任务不存在或已完成: %s", taskID)
+		return fmt.Errorf("%w或已完成: %s", ErrTaskNotFound, taskID)
+	}
+
+	// 只有任务创建者或管理员可以取消
+	if principal, ok := auth.FromContext(ctx); ok && !principal.CanManage(taskWrapper.Task.Owner) {
+		return fmt.Errorf("%w: %s", ErrNotOwner, taskID)
 	}
 
 	// 停止监控
@@ -241,20 +641,32 @@ func (ts *TransferService) CancelTransfer(taskID string) error {
 
 	// 更新任务状态
 	taskWrapper.Task.MarkCancelled()
+	ts.record(taskWrapper)
 
 	// 从活跃任务中移除
 	delete(ts.activeTasks, taskID)
+	ts.progress.forget(taskID)
 
 	return nil
 }
 
-// ListTransfers 列出传输任务
-func (ts *TransferService) ListTransfers(page, size int) *models.TaskListResponse {
+// ListTransfers 列出传输任务，labels 不为空时只返回包含所有指定标签的任务
+func (ts *TransferService) ListTransfers(page, size int, labels map[string]string) *models.TaskListResponse {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 
+	history := ts.taskHistory
+	if len(labels) > 0 {
+		history = make([]*models.TransferTask, 0, len(ts.taskHistory))
+		for _, task := range ts.taskHistory {
+			if task.MatchLabels(labels) {
+				history = append(history, task)
+			}
+		}
+	}
+
 	// 计算分页
-	total := len(ts.taskHistory)
+	total := len(history)
 	start := (page - 1) * size
 	end := start + size
 
@@ -272,7 +684,23 @@ func (ts *TransferService) ListTransfers(page, size int) *models.TaskListRespons
 	}
 
 	tasks := make([]*models.TransferTask, end-start)
-	copy(tasks, ts.taskHistory[start:end])
+	copy(tasks, history[start:end])
+
+	// 排队任务返回带有预计开始和完成时间的副本
+	var etas map[string]*models.TaskETA
+	for i, task := range tasks {
+		if !task.IsQueued() {
+			continue
+		}
+		if etas == nil {
+			etas = ts.queueETAs(time.Now())
+		}
+		if eta, ok := etas[task.ID]; ok {
+			snapshot := *task
+			snapshot.ETA = eta
+			tasks[i] = &snapshot
+		}
+	}
 
 	return &models.TaskListResponse{
 		Tasks: tasks,
@@ -289,6 +717,180 @@ func (ts *TransferService) GetActiveTransfers() int {
 	return len(ts.activeTasks)
 }
 
+// countOwnerTasks 统计调用方占用或等待并发名额的任务数，调用方需持有锁
+func (ts *TransferService) countOwnerTasks(owner string) int {
+	return countTasks(ts.liveTasks("", true), func(task *models.TransferTask) bool { return task.Owner == owner })
+}
+
+// ValidateRequest 验证用户提交的传输请求，标签不能使用批量传输和传输活动保留的标签名
+func ValidateRequest(req *models.TransferRequest) error {
+	return validateRequest(req, false)
+}
+
+// ValidateRequestContext 验证创建任务的传输请求，批量传输或传输活动提交的请求可以带有保留的标签
+// 调用方可以管理所有任务（未启用认证或管理员）时同样允许：客户端模式下客户端把批量传输和传输活动的任务连同保留的标签提交到服务端
+func ValidateRequestContext(ctx context.Context, req *models.TransferRequest) error {
+	reserved, _ := ctx.Value(reservedLabelKey{}).(bool)
+	if principal, ok := auth.FromContext(ctx); !ok || principal.Admin {
+		reserved = true
+	}
+	return validateRequest(req, reserved)
+}
+
+// ValidateItemRequest 验证批量传输或传输活动中的传输请求，提交时另加批次或活动ID标签，标签数量需要为其留出名额
+func ValidateItemRequest(req *models.TransferRequest) error {
+	if err := ValidateRequest(req); err != nil {
+		return err
+	}
+	if len(req.Labels) >= maxLabels {
+		return fmt.Errorf("标签数量不能超过 %d 个（另加批次或活动ID标签）", maxLabels-1)
+	}
+	return nil
+}
+
+// validateRequest 验证传输请求，reserved 为 true 时请求可以带有保留的标签
+func validateRequest(req *models.TransferRequest, reserved bool) error {
+	// 验证文件名，指定源路径或目标路径时可以不指定文件名
+	if req.Filename == "" && req.SourcePath == "" && req.DestinationPath == "" {
+		return fmt.Errorf("文件名不能为空")
+	}
+
+	// 验证传输模式
+	validModes := map[string]bool{
+		models.ModeHugepages:  true,
+		models.ModeTmpfs:      true,
+		models.ModeFilesystem: true,
+		models.ModeAuto:       true,
+	}
+	if !validModes[req.Mode] {
+		return fmt.Errorf("不支持的传输模式: %s", req.Mode)
+	}
+
+	// 验证传输方向
+	validDirections := map[string]bool{
+		models.DirectionPut: true,
+		models.DirectionGet: true,
+	}
+	if !validDirections[req.Direction] {
+		return fmt.Errorf("不支持的传输方向: %s", req.Direction)
+	}
+
+	// 验证文件大小
+	if req.Size < 0 {
+		return fmt.Errorf("文件大小不能为负数")
+	}
+
+	// 验证冲突策略
+	switch req.OnConflict {
+	case "", models.ConflictFail, models.ConflictOverwrite, models.ConflictRename, models.ConflictVersion:
+	default:
+		return fmt.Errorf("不支持的冲突策略: %s", req.OnConflict)
+	}
+
+	// 客户端传输不再需要请求中包含服务端地址
+	// 服务端地址从配置中获取
+
+	// 验证任务类型
+	switch req.Type {
+	case "", models.TaskTypeTransfer, models.TaskTypeVerify:
+	default:
+		return fmt.Errorf("不支持的任务类型: %s", req.Type)
+	}
+	if req.Manifest != nil && !req.IsVerify() {
+		return fmt.Errorf("只有 verify 任务可以携带参考清单")
+	}
+
+	// 验证对象存储暂存
+	if req.Staging != nil {
+		if req.IsVerify() {
+			return fmt.Errorf("%w: verify 任务不能使用对象存储暂存", ErrInvalidStaging)
+		}
+		if err := staging.ValidateSpec(req.Staging); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidStaging, err)
+		}
+	}
+
+	// 验证依赖
+	if err := validateDependencies(req.DependsOn); err != nil {
+		return err
+	}
+
+	// 验证标签
+	return validateLabels(req.Labels, reserved)
+}
+
+// 标签限制
+const (
+	maxLabels          = 32
+	maxLabelKeyLength  = 63
+	maxLabelValueLength = 255
+)
+
+// reservedLabels 批量传输和传输活动按标签查找任务，用户请求不能使用这些标签名
+var reservedLabels = map[string]bool{batchLabel: true, campaignLabel: true}
+
+// reservedLabelKey 在上下文中标记批量传输或传输活动提交的请求
+type reservedLabelKey struct{}
+
+// withReservedLabels 标记批量传输或传输活动提交的请求，验证时允许带有保留的标签
+func withReservedLabels(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reservedLabelKey{}, true)
+}
+
+// ValidateLabels 验证用户请求的任务标签
+func ValidateLabels(labels map[string]string) error {
+	return validateLabels(labels, false)
+}
+
+// validateLabels 验证任务标签，reserved 为 true 时可以使用保留的标签名，数量上限包括保留的标签
+func validateLabels(labels map[string]string, reserved bool) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("标签数量不能超过 %d 个", maxLabels)
+	}
+
+	for key, value := range labels {
+		if key == "" {
+			return fmt.Errorf("标签名不能为空")
+		}
+		if len(key) > maxLabelKeyLength {
+			return fmt.Errorf("标签名 %s 长度不能超过 %d", key, maxLabelKeyLength)
+		}
+		if strings.ContainsAny(key, "=,") {
+			return fmt.Errorf("标签名 %s 不能包含 '=' 或 ','", key)
+		}
+		if reservedLabels[key] && !reserved {
+			return fmt.Errorf("标签名 %s 为批量传输或传输活动保留", key)
+		}
+		if len(value) > maxLabelValueLength {
+			return fmt.Errorf("标签 %s 的值长度不能超过 %d", key, maxLabelValueLength)
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector 解析 key=value 形式的标签选择器
+func ParseLabelSelector(selectors []string) (map[string]string, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(selectors))
+	for _, selector := range selectors {
+		key, value, ok := strings.Cut(selector, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("无效的标签选择器: %s，格式应为 key=value", selector)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// DefaultSettings 获取默认传输设置（未加载配置时使用）
+func DefaultSettings() *models.TransferSettings {
+	settings := models.GetDefaultServerConfig().Transfer
+	return &settings
+}
+
 // buildTransferConfig 构建传输配置
 func (ts *TransferService) buildTransferConfig(req *models.TransferRequest, serverConfig *models.TransferSettings) (*wrapper.TransferConfig, error) {
 	config := &wrapper.TransferConfig{
@@ -375,6 +977,28 @@ func (ts *TransferService) buildTransferConfig(req *models.TransferRequest, serv
 	return config, nil
 }
 
+// ServerFilePath 获取文件在服务端的存放路径（传输模式的基础目录 + 文件名）
+func ServerFilePath(settings *models.TransferSettings, mode, filename string) (string, error) {
+	var baseDir string
+	switch mode {
+	case models.ModeHugepages:
+		baseDir = settings.Modes.Hugepages.BaseDir
+	case models.ModeTmpfs:
+		baseDir = settings.Modes.Tmpfs.BaseDir
+	case models.ModeFilesystem:
+		baseDir = settings.Modes.Filesystem.BaseDir
+	default:
+		return "", fmt.Errorf("不支持的传输模式: %s", mode)
+	}
+
+	// 只使用文件名，避免访问基础目录之外的文件
+	name := getFileName(filename)
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", fmt.Errorf("无效的文件名: %s", filename)
+	}
+	return baseDir + "/" + name, nil
+}
+
 // getFileName 从文件路径中提取文件名
 func getFileName(filepath string) string {
 	// 查找最后一个斜杠
@@ -415,9 +1039,9 @@ func getFileDirectory(filename string) string {
 }
 
 // startTransferTask 启动传输任务
-func (ts *TransferService) startTransferTask(taskWrapper *TransferTask) error {
-	// 创建上下文
-	_, cancel := context.WithCancel(context.Background())
+func (ts *TransferService) startTransferTask(ctx context.Context, taskWrapper *TransferTask) error {
+	// 创建任务上下文（保留追踪信息，但不随请求结束而取消，取消任务时通过 Cancel 结束）
+	taskCtx, cancel := context.WithCancel(tracing.Detach(ctx))
 	taskWrapper.Cancel = cancel
 
 	// 标记任务开始
@@ -432,11 +1056,17 @@ func (ts *TransferService) startTransferTask(taskWrapper *TransferTask) error {
 	}
 
 	// 启动监控
-	if err := taskWrapper.Monitor.StartMonitoring(); err != nil {
+	if err := taskWrapper.Monitor.StartMonitoring(taskCtx); err != nil {
 		taskWrapper.Task.MarkFailed(fmt.Sprintf("启动监控失败: %v", err))
 		return err
 	}
 
+	ts.logger.Debug("启动传输任务",
+		zap.String("task_id", taskWrapper.Task.ID),
+		zap.String("mode", taskWrapper.Task.Mode),
+		zap.String("direction", taskWrapper.Task.Direction),
+	)
+
 	// 检查传输方向
 	if taskWrapper.Config.Direction == wrapper.DirectionPut || taskWrapper.Config.Direction == wrapper.DirectionGet {
 		// 客户端传输 - 在服务端模式下不应该执行客户端传输命令
@@ -450,49 +1080,61 @@ func (ts *TransferService) startTransferTask(taskWrapper *TransferTask) error {
 	}
 }
 
+// defaultProgressCheckInterval 未设置进度更新间隔时服务端监控的任务更新进度的间隔
+const defaultProgressCheckInterval = time.Second
+
 // monitorTransferProgress 监控传输进度
 func (ts *TransferService) monitorTransferProgress(taskWrapper *TransferTask) {
-	// 定期检查进度
-	ticker := time.NewTicker(1 * time.Second)
+	// 按进度更新间隔定期检查进度
+	ts.mu.RLock()
+	interval := ts.progressInterval
+	ts.mu.RUnlock()
+	if interval <= 0 {
+		interval = defaultProgressCheckInterval
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for {
-		select {
-		case <-ticker.C:
-			progress := taskWrapper.Monitor.GetProgress()
-			
-			// 更新任务进度
-			taskWrapper.Task.UpdateProgress(progress.BytesTransferred, progress.TotalBytes)
-			
-			// 检查传输状态
-			switch progress.Status {
-			case wrapper.StatusCompleted:
-				taskWrapper.Task.MarkCompleted()
-				ts.cleanupCompletedTask(taskWrapper)
-				return
-			case wrapper.StatusFailed:
-				taskWrapper.Task.MarkFailed(progress.Error)
-				ts.cleanupCompletedTask(taskWrapper)
-				return
-			case wrapper.StatusCancelled:
-				taskWrapper.Task.MarkCancelled()
-				ts.cleanupCompletedTask(taskWrapper)
-				return
-			}
-			
-		default:
-			// 检查进程是否已退出
-			processInfo := taskWrapper.Process.GetInfo()
-			if processInfo.ExitTime != nil {
-				// 进程已退出
-				if processInfo.State == wrapper.StateError {
-					taskWrapper.Task.MarkFailed(processInfo.Error)
-				} else if taskWrapper.Task.Status != models.StatusCompleted {
-					taskWrapper.Task.MarkFailed("进程异常退出")
-				}
-				ts.cleanupCompletedTask(taskWrapper)
-				return
+	for range ticker.C {
+		// 任务已被取消或清理
+		if taskWrapper.Task.IsFinished() {
+			return
+		}
+
+		progress := taskWrapper.Monitor.GetProgress()
+		
+		// 更新任务进度并发布快照
+		taskWrapper.Task.UpdateProgress(progress.BytesTransferred, progress.TotalBytes)
+		ts.progress.update(taskWrapper.Task.ID, ts.buildProgressResponse(taskWrapper.Task, progress))
+		
+		// 检查传输状态
+		switch progress.Status {
+		case wrapper.StatusCompleted:
+			taskWrapper.Task.MarkCompleted()
+			ts.cleanupCompletedTask(taskWrapper)
+			ts.runPostHooks(context.Background(), taskWrapper.Task)
+			return
+		case wrapper.StatusFailed:
+			taskWrapper.Task.MarkFailed(progress.Error)
+			ts.cleanupCompletedTask(taskWrapper)
+			return
+		case wrapper.StatusCancelled:
+			taskWrapper.Task.MarkCancelled()
+			ts.cleanupCompletedTask(taskWrapper)
+			return
+		}
+
+		// 检查进程是否已退出
+		processInfo := taskWrapper.Process.GetInfo()
+		if processInfo.ExitTime != nil {
+			// 进程已退出
+			if processInfo.State == wrapper.StateError {
+				taskWrapper.Task.MarkFailed(processInfo.Error)
+			} else if taskWrapper.Task.Status != models.StatusCompleted {
+				taskWrapper.Task.MarkFailed("进程异常退出")
 			}
+			ts.cleanupCompletedTask(taskWrapper)
+			return
 		}
 	}
 }
@@ -501,7 +1143,11 @@ func (ts *TransferService) monitorTransferProgress(taskWrapper *TransferTask) {
 func (ts *TransferService) cleanupCompletedTask(taskWrapper *TransferTask) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
+	ts.releaseTask(taskWrapper)
+}
 
+// releaseTask 停止监控、清理进程并从活跃任务中移除已结束的任务，释放并发名额，调用方需持有锁
+func (ts *TransferService) releaseTask(taskWrapper *TransferTask) {
 	// 停止监控
 	taskWrapper.Monitor.StopMonitoring()
 
@@ -510,26 +1156,88 @@ func (ts *TransferService) cleanupCompletedTask(taskWrapper *TransferTask) {
 
 	// 从活跃任务中移除
 	delete(ts.activeTasks, taskWrapper.Task.ID)
+	ts.progress.forget(taskWrapper.Task.ID)
+	ts.record(taskWrapper)
+	ts.notifyFailed(taskWrapper.Task)
+
+	// 记录传输指标
+	metrics.TransferFinished()
+	ts.observeTransfer(taskWrapper)
 
 	// 清理连接状态（如果是单次传输模式）
 	if ts.singleTransfer {
 		// 使用固定的连接标识符清理连接
 		connectionKey := fmt.Sprintf("default_%s", taskWrapper.Task.Direction)
 		delete(ts.activeConnections, connectionKey)
+		delete(ts.connectionUses, connectionKey)
 	}
 }
 
-// checkTransferInterval 检查传输间隔
+// observeTransfer 记录结束任务的耗时和吞吐量指标
+func (ts *TransferService) observeTransfer(taskWrapper *TransferTask) {
+	task := taskWrapper.Task
+
+	result := metrics.ResultFailed
+	switch task.Status {
+	case models.StatusCompleted:
+		result = metrics.ResultCompleted
+	case models.StatusCancelled:
+		result = metrics.ResultCancelled
+	}
+
+	endTime := time.Now()
+	if task.EndTime != nil {
+		endTime = *task.EndTime
+	}
+
+	metrics.ObserveTransfer(task.Mode, task.Direction, taskWrapper.Config.Device, result,
+		endTime.Sub(task.StartTime), task.BytesTransferred)
+}
+
+// StalledTransfers 获取超过 timeout 没有进度更新的活跃任务数
+func (ts *TransferService) StalledTransfers(timeout time.Duration) int {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	stalled := 0
+	for _, taskWrapper := range ts.activeTasks {
+		if time.Since(taskWrapper.Task.UpdatedAt) > timeout {
+			stalled++
+		}
+	}
+	return stalled
+}
+
+// checkDevice 检查 RDMA 设备是否可用
+func (ts *TransferService) checkDevice() error {
+	ts.mu.RLock()
+	check := ts.deviceCheck
+	ts.mu.RUnlock()
+
+	if check == nil {
+		return nil
+	}
+	if err := check(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeviceUnavailable, err)
+	}
+	return nil
+}
+
+// checkTransferInterval 检查传输间隔（调用方需持有锁）
 func (ts *TransferService) checkTransferInterval() error {
-	// 实现传输间隔检查逻辑
-	// 这里需要记录最后传输时间并检查间隔
-	// 简化实现：总是返回 nil
+	if ts.transferInterval <= 0 || ts.lastTransferTime.IsZero() {
+		return nil
+	}
+
+	if wait := ts.transferInterval - time.Since(ts.lastTransferTime); wait > 0 {
+		return fmt.Errorf("%w，还需等待 %s", ErrIntervalNotElapsed, wait.Round(time.Millisecond))
+	}
 	return nil
 }
 
-// updateLastTransferTime 更新最后传输时间
+// updateLastTransferTime 更新最后传输时间（调用方需持有锁）
 func (ts *TransferService) updateLastTransferTime() {
-	// 实现最后传输时间更新逻辑
+	ts.lastTransferTime = time.Now()
 }
 
 // buildProgressResponse 构建进度响应
@@ -541,13 +1249,16 @@ func (ts *TransferService) buildProgressResponse(task *models.TransferTask, prog
 		BytesTransferred: task.BytesTransferred,
 		TotalBytes:       task.TotalBytes,
 		LastUpdated:      task.UpdatedAt,
+		Timeline:         slices.Clone(task.Timeline),
 	}
 
 	if progress != nil {
 		resp.TransferRate = progress.TransferRate
 		resp.ElapsedTime = progress.ElapsedTime.String()
+		resp.ElapsedSeconds = progress.ElapsedTime.Seconds()
 		if progress.EstimatedTime > 0 {
 			resp.EstimatedTime = progress.EstimatedTime.String()
+			resp.EstimatedSeconds = progress.EstimatedTime.Seconds()
 		}
 		resp.Error = progress.Error
 	}
@@ -557,6 +1268,9 @@ func (ts *TransferService) buildProgressResponse(task *models.TransferTask, prog
 
 // Cleanup 清理资源
 func (ts *TransferService) Cleanup() {
+	// 先停止常驻监听进程的健康检查，避免清理后被重新启动
+	ts.StopWarmListeners()
+
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
@@ -568,16 +1282,31 @@ func (ts *TransferService) Cleanup() {
 			taskWrapper.Cancel()
 		}
 		taskWrapper.Task.MarkCancelled()
+		ts.record(taskWrapper)
+		ts.progress.forget(taskWrapper.Task.ID)
 	}
 
+	// 取消延后任务
+	ts.stopDeferred()
+	ts.stopDeadlineWatch()
+	ts.stopBlocked()
+	ts.stopQueued()
+	ts.stopSessions()
+	ts.stopStaging()
+
 	// 停止所有服务端进程
 	for modeName, processMgr := range ts.serverProcesses {
 		processMgr.Cleanup()
 		delete(ts.serverProcesses, modeName)
 	}
 
+	for mode := range ts.leases {
+		ts.dropLease(mode)
+	}
+
 	ts.activeTasks = make(map[string]*TransferTask)
 	ts.activeConnections = make(map[string]time.Time)
+	ts.connectionUses = make(map[string]int)
 	ts.serverProcesses = make(map[string]*wrapper.ProcessManager)
 }
 
@@ -609,9 +1338,19 @@ func (ts *TransferService) isConnectionActive(connectionKey string) bool {
 	return time.Since(lastActive) < timeout
 }
 
+// reuseConnection 启用会话复用时活跃的连接是否还可以服务新的传输
+func (ts *TransferService) reuseConnection(connectionKey string) bool {
+	if !ts.reuseEnabled() {
+		return false
+	}
+	limit := ts.serverConfig.SessionReuse.MaxTransfers
+	return limit <= 0 || ts.connectionUses[connectionKey] < limit
+}
+
 // closeConnection 关闭连接
 func (ts *TransferService) closeConnection(connectionKey string) {
 	delete(ts.activeConnections, connectionKey)
+	delete(ts.connectionUses, connectionKey)
 }
 
 // cleanupExpiredConnections 清理过期的连接
@@ -625,6 +1364,7 @@ func (ts *TransferService) cleanupExpiredConnections() {
 	for key, lastActive := range ts.activeConnections {
 		if currentTime.Sub(lastActive) > timeout {
 			delete(ts.activeConnections, key)
+			delete(ts.connectionUses, key)
 		}
 	}
 }
@@ -640,6 +1380,7 @@ func (ts *TransferService) SetSingleTransferMode(enabled bool, requireReconnect
 	if !enabled {
 		// 禁用单次传输模式时清理所有连接
 		ts.activeConnections = make(map[string]time.Time)
+		ts.connectionUses = make(map[string]int)
 	}
 }
 
@@ -663,31 +1404,56 @@ func (ts *TransferService) GetConnectionStatus() map[string]interface{} {
 }
 
 // ensureServerProcessStarted 确保服务端监听进程已启动
-func (ts *TransferService) ensureServerProcessStarted(config *wrapper.TransferConfig) error {
+func (ts *TransferService) ensureServerProcessStarted(ctx context.Context, config *wrapper.TransferConfig) (err error) {
+	ctx, span := tracing.Start(ctx, "rtranfile.server.ensure",
+		attribute.String("transfer.mode", string(config.Mode)),
+		attribute.String("rdma.device", config.Device),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 	
+	// 每个设备的监听进程单独管理，非默认设备的键为 模式@设备
+	key := ts.listenerKey(config.Device, string(config.Mode))
+	
 	// 首先检查该模式的进程是否已启动且正在运行
-	if processMgr, exists := ts.serverProcesses[string(config.Mode)]; exists {
+	if processMgr, exists := ts.serverProcesses[key]; exists {
 		// 检查进程是否在运行
 		if processMgr.IsRunning() {
-			fmt.Printf("模式 %s 的服务端进程已在运行，PID: %d\n", config.Mode, processMgr.GetPID())
+			span.SetAttributes(attribute.Bool("rtranfile.reused", true))
+			ts.logger.Debug("服务端进程已在运行",
+				zap.String("mode", string(config.Mode)),
+				zap.Int("pid", processMgr.GetPID()),
+			)
 			return nil // 进程已在运行，不需要重新启动
 		}
-		// 进程已停止，从映射中移除
-		fmt.Printf("模式 %s 的服务端进程已停止，需要重新启动\n", config.Mode)
-		delete(ts.serverProcesses, string(config.Mode))
+		// 进程已停止，回收已退出的进程并从映射中移除
+		ts.logger.Info("服务端进程已停止，需要重新启动", zap.String("mode", string(config.Mode)))
+		_ = processMgr.Stop()
+		delete(ts.serverProcesses, key)
 	}
 	
-	// 检查是否有其他模式的进程在运行（只停止不同模式的进程）
+	// 检查同一设备上是否有其他模式的进程在运行（只停止不同模式的进程，常驻监听进程和其他设备的监听进程保持运行）
 	for modeName, processMgr := range ts.serverProcesses {
-		if modeName != string(config.Mode) && processMgr.IsRunning() {
+		if modeName != key && sameDevice(modeName, key) && !ts.isWarm(modeName) && processMgr.IsRunning() {
 			// 停止其他模式的进程
-			fmt.Printf("停止当前运行的模式: %s，切换到模式: %s\n", modeName, config.Mode)
+			ts.logger.Info("切换服务端监听模式",
+				zap.String("from_mode", modeName),
+				zap.String("mode", string(config.Mode)),
+				zap.Int("pid", processMgr.GetPID()),
+			)
 			if err := processMgr.Stop(); err != nil {
-				fmt.Printf("停止模式 %s 的进程失败: %v\n", modeName, err)
+				ts.logger.Error("停止服务端进程失败",
+					zap.String("mode", modeName),
+					zap.Error(err),
+				)
 			}
 			delete(ts.serverProcesses, modeName)
+			ts.dropLease(modeName)
 		}
 	}
 	
@@ -739,7 +1505,7 @@ func (ts *TransferService) ensureServerProcessStarted(config *wrapper.TransferCo
 		Device:    config.Device,
 		Directory: baseDir,
 		Mode:      config.Mode,
-		LogFile:   fmt.Sprintf("/var/log/rtrans/rtranfile_server_%s.log", config.Mode),
+		LogFile:   fmt.Sprintf("/var/log/rtrans/rtranfile_server_%s.log", key),
 		NoHuge:    noHuge,
 		MMan:      mMan,
 		// 服务端配置不需要传输方向和文件名
@@ -753,29 +1519,45 @@ func (ts *TransferService) ensureServerProcessStarted(config *wrapper.TransferCo
 	}
 	
 	// 启动服务端监听进程
-	fmt.Printf("正在启动服务端监听进程... 模式: %s, 设备: %s, 目录: %s\n",
-		config.Mode, serverConfig.Device, serverConfig.Directory)
+	ts.logger.Info("正在启动服务端监听进程",
+		zap.String("mode", string(config.Mode)),
+		zap.String("device", serverConfig.Device),
+		zap.String("directory", serverConfig.Directory),
+	)
+	
+	if err := ts.faults.DelayLaunch(ctx); err != nil {
+		return err
+	}
 	
-	// 使用后台上下文启动服务端进程，避免进程立即退出
-	serverCtx := context.Background()
+	// 服务端进程需要在请求结束后继续运行，只保留追踪信息，不继承请求的取消
+	serverCtx := tracing.Detach(ctx)
 	serverCmd, err := ts.rtranfile.StartServer(serverCtx, serverConfig)
 	if err != nil {
 		return fmt.Errorf("启动服务端监听进程失败: %v", err)
 	}
 	
 	// 创建进程管理器来管理服务端进程
-	serverProcessMgr := wrapper.NewProcessManager()
-	if err := serverProcessMgr.Start(serverCmd); err != nil {
+	serverProcessMgr := ts.newProcessManager()
+	if err := serverProcessMgr.Start(ctx, serverCmd); err != nil {
 		return fmt.Errorf("管理服务端进程失败: %v", err)
 	}
 	
 	// 保存进程管理器
-	ts.serverProcesses[string(config.Mode)] = serverProcessMgr
+	ts.serverProcesses[key] = serverProcessMgr
 	
-	fmt.Printf("服务端监听进程已启动，PID: %d\n", serverProcessMgr.GetPID())
+	ts.logger.Info("服务端监听进程已启动",
+		zap.String("mode", string(config.Mode)),
+		zap.Int("pid", serverProcessMgr.GetPID()),
+	)
+	span.SetAttributes(attribute.Int("process.pid", serverProcessMgr.GetPID()))
+	span.AddEvent("process_started")
 	
 	// 等待服务端进程稳定运行（避免立即退出）
-	time.Sleep(2 * time.Second)
+	select {
+	case <-time.After(2 * time.Second):
+	case <-ctx.Done():
+		return fmt.Errorf("等待服务端进程稳定运行被取消: %v", ctx.Err())
+	}
 	
 	// 检查进程是否仍在运行
 	if !serverProcessMgr.IsRunning() {
@@ -800,7 +1582,14 @@ func (ts *TransferService) ensureServerProcessStarted(config *wrapper.TransferCo
 		errorMsg += "\n3. rtranfile日志文件: " + serverConfig.LogFile
 		errorMsg += "\n4. 系统资源是否充足"
 		
-		return fmt.Errorf(errorMsg)
+		ts.logger.Error("服务端监听进程启动后立即退出",
+			zap.String("mode", string(config.Mode)),
+			zap.Int("pid", processInfo.PID),
+			zap.String("state", string(processInfo.State)),
+			zap.String("error", processInfo.Error),
+		)
+		
+		return fmt.Errorf("%s", errorMsg)
 	}
 	
 	return nil
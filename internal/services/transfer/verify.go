@@ -0,0 +1,122 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/manifest"
+	"rdma-burst/pkg/tracing"
+)
+
+// ErrNoReferenceManifest verify 任务没有可比较的参考清单
+var ErrNoReferenceManifest = errors.New("没有可用的参考清单")
+
+// StartVerify 启动只校验任务：重新计算服务端文件的分块摘要并与参考清单比较，不传输数据
+// 参考清单优先使用请求中客户端重新计算的清单，否则使用 put 校验通过后保存在服务端的清单
+func (ts *TransferService) StartVerify(ctx context.Context, req *models.TransferRequest, serverConfig *models.TransferSettings) (*models.TransferResponse, error) {
+	ctx, span := tracing.Start(ctx, "transfer.verify",
+		attribute.String("transfer.filename", req.Filename),
+		attribute.String("transfer.mode", req.Mode),
+	)
+	defer span.End()
+
+	if err := ts.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	decision, err := ResolveAutoMode(serverConfig, req)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := ServerFilePath(serverConfig, req.Mode, req.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := req.Manifest
+	if reference == nil {
+		stored, err := manifest.Load(manifest.PathFor(path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%w: %s", ErrNoReferenceManifest, req.Filename)
+			}
+			return nil, err
+		}
+		reference = stored
+	}
+
+	task := models.NewTransferTaskWithServer(req.Filename, req.Mode, req.Direction, "")
+	task.Type = models.TaskTypeVerify
+	task.ModeDecision = decision
+	task.TraceID = tracing.TraceID(ctx)
+	task.Labels = req.Labels
+	task.Metadata = req.Metadata
+	if principal, ok := auth.FromContext(ctx); ok {
+		task.Owner = principal.Name
+	}
+	task.TotalBytes = reference.Size
+	task.MarkStarted()
+	task.MarkInProgress()
+	span.SetAttributes(attribute.String("transfer.task_id", task.ID))
+
+	ts.mu.Lock()
+	ts.taskHistory = append(ts.taskHistory, task)
+	ts.mu.Unlock()
+
+	// 校验可能耗时较长，不随请求结束而取消
+	go ts.runVerify(tracing.Detach(ctx), task, path, reference)
+
+	return &models.TransferResponse{
+		ID:           task.ID,
+		Status:       task.Status,
+		Message:      "校验任务已开始",
+		Mode:         task.Mode,
+		ModeDecision: decision,
+		TraceID:      task.TraceID,
+		CreatedAt:    task.CreatedAt,
+	}, nil
+}
+
+// runVerify 执行分块校验并更新任务状态
+func (ts *TransferService) runVerify(ctx context.Context, task *models.TransferTask, path string, reference *manifest.Manifest) {
+	ctx, span := tracing.Start(ctx, "transfer.verify.run", attribute.String("transfer.task_id", task.ID))
+	defer span.End()
+
+	log := ts.logger.With(zap.String("task_id", task.ID), zap.String("path", path))
+	startTime := time.Now()
+
+	report, err := manifest.Verify(ctx, path, reference)
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	defer ts.notifyFailed(task)
+
+	switch {
+	case err != nil:
+		tracing.RecordError(span, err)
+		task.MarkFailed(fmt.Sprintf("分块校验失败: %v", err))
+		log.Error("校验任务失败", zap.Error(err))
+	case !report.OK:
+		task.BytesTransferred = report.Size
+		task.MarkFailed(fmt.Sprintf("%d/%d 个分块不一致 (size=%d, expected=%d), 损坏范围: %v",
+			len(report.BadChunks), report.TotalChunks, report.Size, report.ExpectedSize, report.BadRanges))
+		log.Warn("校验任务发现不一致",
+			zap.Ints("bad_chunks", report.BadChunks),
+			zap.Any("bad_ranges", report.BadRanges),
+		)
+	default:
+		task.BytesTransferred = report.Size
+		task.Message = fmt.Sprintf("%d 个分块全部一致", report.TotalChunks)
+		task.MarkCompleted()
+		log.Info("校验任务完成", zap.Duration("duration", time.Since(startTime)))
+	}
+}
@@ -0,0 +1,172 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/wrapper"
+)
+
+// defaultWarmCheckInterval 未配置检查间隔时常驻监听进程的默认检查间隔
+const defaultWarmCheckInterval = 10 * time.Second
+
+// warmListeners 常驻监听进程：启动后各模式的监听进程不随会话结束而停止，由健康检查在退出时重新启动
+type warmListeners struct {
+	mu     sync.Mutex
+	modes  map[string]bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartWarmListeners 为已启用的模式启动常驻监听进程，并按配置的间隔检查，退出的监听进程自动重新启动
+// 启动失败的模式只记录日志，由下一次检查重试
+func (ts *TransferService) StartWarmListeners(ctx context.Context) {
+	if ts.serverConfig == nil || !ts.serverConfig.WarmListeners.Enabled {
+		return
+	}
+	interval := ts.serverConfig.WarmListeners.CheckInterval
+	if interval <= 0 {
+		interval = defaultWarmCheckInterval
+	}
+
+	modes := enabledModes(ts.serverConfig)
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	ts.warm.mu.Lock()
+	if ts.warm.cancel != nil {
+		ts.warm.mu.Unlock()
+		cancel()
+		return
+	}
+	ts.warm.modes = make(map[string]bool, len(modes))
+	for _, mode := range modes {
+		ts.warm.modes[mode] = true
+	}
+	ts.warm.cancel = cancel
+	ts.warm.done = done
+	ts.warm.mu.Unlock()
+
+	ts.logger.Info("启动常驻监听进程",
+		zap.Strings("modes", modes),
+		zap.Duration("check_interval", interval),
+	)
+	ts.checkWarmListeners(ctx, modes)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ts.checkWarmListeners(ctx, modes)
+			}
+		}
+	}()
+}
+
+// StopWarmListeners 停止常驻监听进程的健康检查并等待正在进行的检查结束，监听进程由 Cleanup 停止
+func (ts *TransferService) StopWarmListeners() {
+	ts.warm.mu.Lock()
+	cancel, done := ts.warm.cancel, ts.warm.done
+	ts.warm.cancel = nil
+	ts.warm.done = nil
+	ts.warm.modes = nil
+	ts.warm.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// WarmListenerCheck 就绪检查：启用常驻监听进程时，任一模式的监听进程未运行则返回错误
+func (ts *TransferService) WarmListenerCheck() error {
+	ts.warm.mu.Lock()
+	modes := make([]string, 0, len(ts.warm.modes))
+	for mode := range ts.warm.modes {
+		modes = append(modes, mode)
+	}
+	ts.warm.mu.Unlock()
+
+	var down []string
+	for _, mode := range modes {
+		if !ts.listenerRunning(mode) {
+			down = append(down, mode)
+		}
+	}
+	if len(down) > 0 {
+		return fmt.Errorf("常驻监听进程未运行: %s", strings.Join(down, ", "))
+	}
+	return nil
+}
+
+// checkWarmListeners 检查各模式的常驻监听进程，未运行时重新启动
+func (ts *TransferService) checkWarmListeners(ctx context.Context, modes []string) {
+	for _, mode := range modes {
+		if ctx.Err() != nil {
+			return
+		}
+		if ts.listenerRunning(mode) {
+			continue
+		}
+
+		ts.mu.RLock()
+		_, existed := ts.serverProcesses[mode]
+		ts.mu.RUnlock()
+		if existed {
+			ts.logger.Warn("常驻监听进程已退出，重新启动", zap.String("mode", mode))
+		}
+
+		config := &wrapper.TransferConfig{
+			Mode:   wrapper.TransferMode(mode),
+			Device: ts.serverConfig.Device,
+		}
+		if err := ts.ensureServerProcessStarted(ctx, config); err != nil {
+			if ctx.Err() == nil {
+				ts.logger.Error("启动常驻监听进程失败", zap.String("mode", mode), zap.Error(err))
+			}
+		}
+	}
+}
+
+// isWarm 模式是否有常驻监听进程，调用方可以持有 ts.mu
+func (ts *TransferService) isWarm(mode string) bool {
+	ts.warm.mu.Lock()
+	defer ts.warm.mu.Unlock()
+	return ts.warm.modes[mode]
+}
+
+// listenerRunning 模式的服务端监听进程是否正在运行
+func (ts *TransferService) listenerRunning(mode string) bool {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	processMgr, exists := ts.serverProcesses[mode]
+	return exists && processMgr.IsRunning()
+}
+
+// enabledModes 获取已启用的传输模式
+func enabledModes(settings *models.TransferSettings) []string {
+	var modes []string
+	if settings.Modes.Hugepages.Enabled {
+		modes = append(modes, models.ModeHugepages)
+	}
+	if settings.Modes.Tmpfs.Enabled {
+		modes = append(modes, models.ModeTmpfs)
+	}
+	if settings.Modes.Filesystem.Enabled {
+		modes = append(modes, models.ModeFilesystem)
+	}
+	return modes
+}
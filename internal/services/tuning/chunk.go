@@ -0,0 +1,246 @@
+package tuning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/pkg/logger"
+)
+
+// 块大小调优的默认值
+const (
+	DefaultMinSamples  = 3
+	DefaultMinFileSize = 64 << 20
+)
+
+// throughputWeight 新样本在吞吐量指数加权平均中的权重，较新的传输更能反映当前的链路和负载
+const throughputWeight = 0.3
+
+// 选择块大小的原因
+const (
+	ReasonExplore     = "explore"     // 候选的样本数不足，先测量
+	ReasonRemeasure   = "remeasure"   // 定期改用最久未使用的候选重新测量
+	ReasonRecommended = "recommended" // 使用吞吐量最高的块大小
+)
+
+// ChunkTuner 按设备统计不同 rtranfile 块大小的吞吐量，推荐或自动选择表现最好的块大小
+type ChunkTuner struct {
+	mu           sync.Mutex
+	apply        bool
+	candidates   []int
+	minSamples   int
+	minFileSize  int64
+	exploreEvery int
+	stateFile    string
+	devices      map[string]*deviceStats
+	logger       *zap.Logger
+}
+
+// deviceStats 一个设备的块大小统计
+type deviceStats struct {
+	Sizes      map[int]*models.ChunkSizeStats `json:"sizes"`
+	Selections int                            `json:"selections"` // 自动选择的次数，用于定期重新测量
+}
+
+// NewChunkTuner 创建块大小调优器并加载 state_file 中保存的统计，文件不存在时从空统计开始
+func NewChunkTuner(settings models.ChunkTuningSettings) (*ChunkTuner, error) {
+	t := &ChunkTuner{
+		apply:        settings.Apply,
+		candidates:   append([]int(nil), settings.Candidates...),
+		minSamples:   settings.MinSamples,
+		minFileSize:  settings.MinFileSize,
+		exploreEvery: settings.ExploreEvery,
+		stateFile:    settings.StateFile,
+		devices:      make(map[string]*deviceStats),
+		logger:       logger.GetLogger().Named(logger.ComponentTransfer).Named("tuning"),
+	}
+	if t.minSamples <= 0 {
+		t.minSamples = DefaultMinSamples
+	}
+	if t.minFileSize <= 0 {
+		t.minFileSize = DefaultMinFileSize
+	}
+	sort.Ints(t.candidates)
+
+	if t.stateFile == "" {
+		return t, nil
+	}
+	data, err := os.ReadFile(t.stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取块大小统计文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &t.devices); err != nil {
+		return nil, fmt.Errorf("解析块大小统计文件失败: %v", err)
+	}
+	for device, stats := range t.devices {
+		if stats == nil || stats.Sizes == nil {
+			t.devices[device] = &deviceStats{Sizes: make(map[int]*models.ChunkSizeStats)}
+		}
+	}
+	return t, nil
+}
+
+// Select 为设备选择块大小，未启用自动选择时返回 0（使用 rtranfile 默认值）
+// 先让每个候选达到 min_samples，之后使用吞吐量最高的块大小，每 explore_every 次改用最久未使用的候选重新测量
+func (t *ChunkTuner) Select(device string) (int, string) {
+	if !t.apply || len(t.candidates) == 0 {
+		return 0, ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.device(device)
+	stats.Selections++
+
+	// 样本数不足的候选中选择样本最少的
+	explore, samples := 0, 0
+	for _, size := range t.candidates {
+		count := 0
+		if s, ok := stats.Sizes[size]; ok {
+			count = s.Transfers
+		}
+		if count < t.minSamples && (explore == 0 || count < samples) {
+			explore, samples = size, count
+		}
+	}
+	if explore != 0 {
+		return explore, ReasonExplore
+	}
+
+	if t.exploreEvery > 0 && stats.Selections%t.exploreEvery == 0 {
+		oldest := t.candidates[0]
+		for _, size := range t.candidates[1:] {
+			if stats.Sizes[size].LastUsed.Before(stats.Sizes[oldest].LastUsed) {
+				oldest = size
+			}
+		}
+		return oldest, ReasonRemeasure
+	}
+
+	if best, ok := t.recommended(stats); ok {
+		return best, ReasonRecommended
+	}
+	return t.candidates[0], ReasonExplore
+}
+
+// Record 记录一次成功传输，chunkSize 为 0 表示使用 rtranfile 默认值；小于 min_file_size 的传输主要是启动开销，不计入统计
+func (t *ChunkTuner) Record(device string, chunkSize int, bytes int64, duration time.Duration) {
+	if bytes < t.minFileSize || duration <= 0 {
+		return
+	}
+	throughput := float64(bytes) / duration.Seconds() / (1024 * 1024)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.device(device)
+	s, ok := stats.Sizes[chunkSize]
+	if !ok {
+		s = &models.ChunkSizeStats{ChunkSize: chunkSize}
+		stats.Sizes[chunkSize] = s
+	}
+	if s.Transfers == 0 {
+		s.Throughput = throughput
+	} else {
+		s.Throughput = throughputWeight*throughput + (1-throughputWeight)*s.Throughput
+	}
+	s.Transfers++
+	s.Bytes += bytes
+	s.Seconds += duration.Seconds()
+	s.LastUsed = time.Now()
+
+	if err := t.save(); err != nil {
+		t.logger.Warn("保存块大小统计失败", zap.String("file", t.stateFile), zap.Error(err))
+	}
+}
+
+// Stats 获取各设备的块大小统计和推荐的块大小
+func (t *ChunkTuner) Stats() models.ChunkStatsResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	response := models.ChunkStatsResponse{
+		Apply:       t.apply,
+		Candidates:  append([]int{}, t.candidates...),
+		MinSamples:  t.minSamples,
+		MinFileSize: t.minFileSize,
+		Devices:     make([]models.DeviceChunkStats, 0, len(t.devices)),
+	}
+	for device, stats := range t.devices {
+		entry := models.DeviceChunkStats{
+			Device:     device,
+			ChunkSizes: make([]models.ChunkSizeStats, 0, len(stats.Sizes)),
+		}
+		if best, ok := t.recommended(stats); ok {
+			entry.Recommended = &best
+		}
+		for _, s := range stats.Sizes {
+			entry.ChunkSizes = append(entry.ChunkSizes, *s)
+		}
+		sort.Slice(entry.ChunkSizes, func(i, j int) bool { return entry.ChunkSizes[i].ChunkSize < entry.ChunkSizes[j].ChunkSize })
+		response.Devices = append(response.Devices, entry)
+	}
+	sort.Slice(response.Devices, func(i, j int) bool { return response.Devices[i].Device < response.Devices[j].Device })
+	return response
+}
+
+// device 获取设备的统计，不存在时创建，调用方需持有锁
+func (t *ChunkTuner) device(device string) *deviceStats {
+	stats, ok := t.devices[device]
+	if !ok {
+		stats = &deviceStats{Sizes: make(map[int]*models.ChunkSizeStats)}
+		t.devices[device] = stats
+	}
+	return stats
+}
+
+// recommended 样本数足够的块大小中吞吐量最高的，启用自动选择时只在候选中选择，没有时返回 false，调用方需持有锁
+func (t *ChunkTuner) recommended(stats *deviceStats) (int, bool) {
+	best, throughput, found := 0, 0.0, false
+	for size, s := range stats.Sizes {
+		if s.Transfers < t.minSamples || (t.apply && !t.isCandidate(size)) {
+			continue
+		}
+		if !found || s.Throughput > throughput || (s.Throughput == throughput && size < best) {
+			best, throughput, found = size, s.Throughput, true
+		}
+	}
+	return best, found
+}
+
+// isCandidate 块大小是否在候选中
+func (t *ChunkTuner) isCandidate(size int) bool {
+	index := sort.SearchInts(t.candidates, size)
+	return index < len(t.candidates) && t.candidates[index] == size
+}
+
+// save 原子地写入统计文件，调用方需持有锁
+func (t *ChunkTuner) save() error {
+	if t.stateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.devices, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.stateFile), 0755); err != nil {
+		return err
+	}
+	tmp := t.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, t.stateFile)
+}
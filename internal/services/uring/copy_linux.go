@@ -0,0 +1,124 @@
+package uring
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Copy 用 io_uring 从 in 读取 size 字节写入 out 的相同偏移，最多 depth 个 bufSize 大小的读请求同时在途；
+// 读取完成的块立即写入目标，进度通过 progress 回调报告。io_uring 不可用时返回 ErrUnsupported，尚未读写任何数据
+func Copy(ctx context.Context, in, out *os.File, size int64, bufSize, depth int, progress func(copied int64)) error {
+	ring, err := New(uint32(depth))
+	if err != nil {
+		return err
+	}
+	defer ring.Close()
+	depth = min(depth, int(ring.Entries()))
+
+	type slot struct {
+		buf    []byte
+		offset int64 // 本次请求在文件中的起始偏移
+		filled int   // 短读时已读取的字节数
+		want   int   // 本次请求需要读取的字节数
+	}
+	slots := make([]*slot, depth)
+	for i := range slots {
+		slots[i] = &slot{buf: make([]byte, bufSize)}
+	}
+
+	var next, copied int64
+	inflight := 0
+	// submitRead 为槽位提交（剩余部分的）读请求，提交队列已满时先把队列中的请求提交给内核再重试
+	submitRead := func(i int) error {
+		s := slots[i]
+		buf, offset := s.buf[s.filled:s.want], s.offset+int64(s.filled)
+		if !ring.PrepareRead(in, buf, offset, uint64(i)) {
+			if err := ring.Submit(0); err != nil {
+				return err
+			}
+			if !ring.PrepareRead(in, buf, offset, uint64(i)) {
+				return fmt.Errorf("io_uring 提交队列已满")
+			}
+		}
+		inflight++
+		return nil
+	}
+	// 退出前等待在途的请求完成，之后才能释放缓冲区和 io_uring 实例
+	defer func() {
+		for inflight > 0 {
+			if ring.Submit(1) != nil {
+				return
+			}
+			for {
+				if _, ok := ring.Completion(); !ok {
+					break
+				}
+				inflight--
+			}
+		}
+	}()
+
+	for i := range slots {
+		if next >= size {
+			break
+		}
+		slots[i].offset, slots[i].filled = next, 0
+		slots[i].want = int(min(int64(bufSize), size-next))
+		next += int64(slots[i].want)
+		if err := submitRead(i); err != nil {
+			return err
+		}
+	}
+
+	for inflight > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := ring.Submit(1); err != nil {
+			return err
+		}
+		for {
+			completion, ok := ring.Completion()
+			if !ok {
+				break
+			}
+			inflight--
+			i := int(completion.UserData)
+			s := slots[i]
+			switch {
+			case completion.Result < 0:
+				return fmt.Errorf("读取源文件失败: %v", syscall.Errno(-completion.Result))
+			case completion.Result == 0:
+				return fmt.Errorf("读取源文件失败: 文件在暂存过程中被截断")
+			}
+			s.filled += int(completion.Result)
+			if s.filled < s.want {
+				// 短读，继续读取剩余部分
+				if err := submitRead(i); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if _, err := out.WriteAt(s.buf[:s.want], s.offset); err != nil {
+				return fmt.Errorf("写入目标文件失败: %v", err)
+			}
+			copied += int64(s.want)
+			if progress != nil {
+				progress(copied)
+			}
+
+			if next < size {
+				s.offset, s.filled = next, 0
+				s.want = int(min(int64(bufSize), size-next))
+				next += int64(s.want)
+				if err := submitRead(i); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package uring
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// io_uring 系统调用号（所有架构统一）
+const (
+	sysIOUringSetup = 425
+	sysIOUringEnter = 426
+)
+
+// io_uring 常量
+const (
+	opReadv        = 1          // IORING_OP_READV，5.1 起支持
+	enterGetEvents = 1          // IORING_ENTER_GETEVENTS
+	offSQRing      = 0          // IORING_OFF_SQ_RING
+	offCQRing      = 0x8000000  // IORING_OFF_CQ_RING
+	offSQEs        = 0x10000000 // IORING_OFF_SQES
+	sqeSize        = 64
+	cqeSize        = 16
+)
+
+// sqRingOffsets struct io_sqring_offsets
+type sqRingOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	userAddr                                                        uint64
+}
+
+// cqRingOffsets struct io_cqring_offsets
+type cqRingOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	userAddr                                                        uint64
+}
+
+// params struct io_uring_params
+type params struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFD uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  sqRingOffsets
+	cqOff                                                                  cqRingOffsets
+}
+
+// sqe struct io_uring_sqe
+type sqe struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	rwFlags     uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFDIn  int32
+	addr3       uint64
+	pad         uint64
+}
+
+// cqe struct io_uring_cqe
+type cqe struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// Completion 一个已完成的请求
+type Completion struct {
+	UserData uint64
+	Result   int32 // 读取的字节数，失败时为负的 errno
+}
+
+// Ring 只提交读请求的 io_uring 实例，不是并发安全的
+type Ring struct {
+	fd      int
+	entries uint32
+	sqRing  []byte
+	cqRing  []byte
+	sqes    []byte
+
+	sqHead, sqTail, sqMask *uint32
+	sqArray                unsafe.Pointer
+	cqHead, cqTail, cqMask *uint32
+	cqes                   unsafe.Pointer
+
+	iovecs  []syscall.Iovec // 与 SQE 槽位一一对应，提交后到完成前必须保持有效
+	pending uint32          // 已放入提交队列、尚未提交给内核的请求数
+}
+
+// New 创建 io_uring 实例，内核不支持或被禁用（容器的 seccomp、kernel.io_uring_disabled）时返回 ErrUnsupported
+func New(entries uint32) (*Ring, error) {
+	var p params
+	fd, _, errno := syscall.Syscall(sysIOUringSetup, uintptr(entries), uintptr(unsafe.Pointer(&p)), 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, errno)
+	}
+	r := &Ring{fd: int(fd), entries: p.sqEntries}
+
+	var err error
+	if r.sqRing, err = syscall.Mmap(r.fd, offSQRing, int(p.sqOff.array+p.sqEntries*4), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("映射提交队列失败: %v", err)
+	}
+	if r.cqRing, err = syscall.Mmap(r.fd, offCQRing, int(p.cqOff.cqes+p.cqEntries*cqeSize), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("映射完成队列失败: %v", err)
+	}
+	if r.sqes, err = syscall.Mmap(r.fd, offSQEs, int(p.sqEntries*sqeSize), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("映射提交队列条目失败: %v", err)
+	}
+
+	r.sqHead = (*uint32)(unsafe.Pointer(&r.sqRing[p.sqOff.head]))
+	r.sqTail = (*uint32)(unsafe.Pointer(&r.sqRing[p.sqOff.tail]))
+	r.sqMask = (*uint32)(unsafe.Pointer(&r.sqRing[p.sqOff.ringMask]))
+	r.sqArray = unsafe.Pointer(&r.sqRing[p.sqOff.array])
+	r.cqHead = (*uint32)(unsafe.Pointer(&r.cqRing[p.cqOff.head]))
+	r.cqTail = (*uint32)(unsafe.Pointer(&r.cqRing[p.cqOff.tail]))
+	r.cqMask = (*uint32)(unsafe.Pointer(&r.cqRing[p.cqOff.ringMask]))
+	r.cqes = unsafe.Pointer(&r.cqRing[p.cqOff.cqes])
+	r.iovecs = make([]syscall.Iovec, p.sqEntries)
+	return r, nil
+}
+
+// Entries 提交队列的容量
+func (r *Ring) Entries() uint32 {
+	return r.entries
+}
+
+// Close 释放映射并关闭 io_uring 实例，调用前必须等待所有已提交的请求完成
+func (r *Ring) Close() error {
+	for _, region := range [][]byte{r.sqes, r.cqRing, r.sqRing} {
+		if region != nil {
+			syscall.Munmap(region)
+		}
+	}
+	r.sqes, r.cqRing, r.sqRing = nil, nil, nil
+	return syscall.Close(r.fd)
+}
+
+// PrepareRead 把从 file 的 offset 处读取到 buf 的请求放入提交队列，队列已满时返回 false
+// 请求完成前 buf 必须保持有效且不能被修改
+func (r *Ring) PrepareRead(file *os.File, buf []byte, offset int64, userData uint64) bool {
+	tail := atomic.LoadUint32(r.sqTail)
+	if tail-atomic.LoadUint32(r.sqHead) >= r.entries {
+		return false
+	}
+	index := tail & *r.sqMask
+
+	iov := &r.iovecs[index]
+	iov.Base = &buf[0]
+	iov.SetLen(len(buf))
+
+	entry := (*sqe)(unsafe.Add(unsafe.Pointer(&r.sqes[0]), uintptr(index)*sqeSize))
+	*entry = sqe{
+		opcode:   opReadv,
+		fd:       int32(file.Fd()),
+		off:      uint64(offset),
+		addr:     uint64(uintptr(unsafe.Pointer(iov))),
+		len:      1,
+		userData: userData,
+	}
+	*(*uint32)(unsafe.Add(r.sqArray, uintptr(index)*4)) = index
+	atomic.StoreUint32(r.sqTail, tail+1)
+	r.pending++
+	return true
+}
+
+// Submit 提交队列中的请求，wait 大于 0 时等待至少 wait 个请求完成
+func (r *Ring) Submit(wait uint32) error {
+	flags := uintptr(0)
+	if wait > 0 {
+		flags = enterGetEvents
+	}
+	for {
+		n, _, errno := syscall.Syscall6(sysIOUringEnter, uintptr(r.fd), uintptr(r.pending), uintptr(wait), flags, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return fmt.Errorf("提交 io_uring 请求失败: %v", errno)
+		}
+		r.pending -= uint32(n)
+		return nil
+	}
+}
+
+// Completion 取出一个已完成的请求，没有时返回 false
+func (r *Ring) Completion() (Completion, bool) {
+	head := atomic.LoadUint32(r.cqHead)
+	if head == atomic.LoadUint32(r.cqTail) {
+		return Completion{}, false
+	}
+	entry := (*cqe)(unsafe.Add(r.cqes, uintptr(head&*r.cqMask)*cqeSize))
+	completion := Completion{UserData: entry.userData, Result: entry.res}
+	atomic.StoreUint32(r.cqHead, head+1)
+	return completion, true
+}
@@ -0,0 +1,8 @@
+// Package uring 提供基于 io_uring 的文件读取，用于把本地文件暂存到传输模式目录：
+// 同时保持多个读请求在途，充分利用 NVMe 的队列深度，写入与后续的读取重叠进行
+package uring
+
+import "errors"
+
+// ErrUnsupported 内核不支持或禁用了 io_uring，调用方应改用同步读写
+var ErrUnsupported = errors.New("io_uring 不可用")
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package uring
+
+import (
+	"context"
+	"os"
+)
+
+// Copy 非 Linux 平台没有 io_uring
+func Copy(ctx context.Context, in, out *os.File, size int64, bufSize, depth int, progress func(copied int64)) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"net"
+	"sync"
+)
+
+// Listen 监听 TCP 地址，maxConns 大于 0 时限制同时打开的连接数，超出的连接留在内核队列中等待已有连接关闭
+func Listen(addr string, maxConns int) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if maxConns <= 0 {
+		return listener, nil
+	}
+	return &limitListener{
+		Listener: listener,
+		slots:    make(chan struct{}, maxConns),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// limitListener 限制同时打开的连接数的监听器
+type limitListener struct {
+	net.Listener
+	slots     chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept 等待空闲名额后接受连接，监听器关闭时返回错误
+func (l *limitListener) Accept() (net.Conn, error) {
+	select {
+	case l.slots <- struct{}{}:
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.slots
+		return nil, err
+	}
+	return &limitConn{Conn: conn, release: func() { <-l.slots }}, nil
+}
+
+// Close 关闭监听器并唤醒等待名额的 Accept
+func (l *limitListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() { close(l.done) })
+	return err
+}
+
+// limitConn 关闭时释放名额的连接
+type limitConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
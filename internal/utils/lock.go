@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// InstanceLock 实例锁（基于 flock 的 PID 文件）
+type InstanceLock struct {
+	path string
+	file *os.File
+}
+
+// AcquireInstanceLock 获取实例锁，锁已被其他进程持有时返回错误
+func AcquireInstanceLock(path string) (*InstanceLock, error) {
+	// 确保锁文件目录存在
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建锁文件目录失败: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开锁文件失败: %v", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if pid, held := CheckInstanceLock(path); held {
+			return nil, fmt.Errorf("实例锁 %s 已被进程 %d 持有", path, pid)
+		}
+		return nil, fmt.Errorf("获取实例锁失败: %v", err)
+	}
+
+	// 写入当前进程PID
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("清空锁文件失败: %v", err)
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("写入锁文件失败: %v", err)
+	}
+
+	return &InstanceLock{
+		path: path,
+		file: file,
+	}, nil
+}
+
+// Release 释放实例锁
+func (l *InstanceLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	_ = os.Remove(l.path)
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Path 获取锁文件路径
+func (l *InstanceLock) Path() string {
+	return l.path
+}
+
+// CheckInstanceLock 检查实例锁是否被持有，返回持有者PID
+func CheckInstanceLock(path string) (int, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	// 读取持有者PID
+	pid := 0
+	buf := make([]byte, 32)
+	if n, _ := file.Read(buf); n > 0 {
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	}
+
+	// 尝试加共享锁，失败说明有进程持有排他锁
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err != nil {
+		return pid, true
+	}
+	_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	return pid, false
+}
@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 // GetIPFromRDMAInterface 根据RDMA设备名称获取对应的IP地址
 func GetIPFromRDMAInterface(rdmaDevice string) (string, error) {
-	// RDMA设备通常与网络接口有对应关系
-	// 例如 mlx5_0 -> ib0, mlx5_1 -> ib1 等
+	// 优先从 sysfs 读取：RoCE v2 GID 对应的地址或设备实际关联网络接口的地址
+	if _, err := os.Stat(filepath.Join(SysfsInfinibandPath, rdmaDevice)); err == nil {
+		return GetSourceIPForRDMA(rdmaDevice)
+	}
 	
-	// 尝试从设备名称推断网络接口
+	// sysfs 不可用时（例如容器中未挂载），回退到按设备名称推断
+	// 例如 mlx5_0 -> ib0, mlx5_1 -> ib1 等
 	interfaceName := inferInterfaceFromRDMA(rdmaDevice)
 	if interfaceName == "" {
 		return "", fmt.Errorf("无法从RDMA设备 %s 推断网络接口", rdmaDevice)
@@ -23,7 +27,7 @@ func GetIPFromRDMAInterface(rdmaDevice string) (string, error) {
 	return getInterfaceIP(interfaceName)
 }
 
-// inferInterfaceFromRDMA 从RDMA设备名称推断网络接口名称
+// inferInterfaceFromRDMA 从RDMA设备名称推断网络接口名称（仅在 sysfs 不可用时使用）
 func inferInterfaceFromRDMA(rdmaDevice string) string {
 	// 常见的RDMA设备到网络接口的映射
 	// mlx5_0 -> ib0, mlx5_1 -> ib1, 等等
@@ -0,0 +1,540 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SysfsInfinibandPath RDMA 设备在 sysfs 中的目录
+var SysfsInfinibandPath = "/sys/class/infiniband"
+
+// RDMADevice RDMA 设备与网络接口的映射
+type RDMADevice struct {
+	Name       string       `json:"name"`
+	Interfaces []RDMANetDev `json:"interfaces"`
+}
+
+// RDMANetDev RDMA 设备对应的网络接口
+type RDMANetDev struct {
+	Name string   `json:"name"`
+	IPs  []string `json:"ips"`
+}
+
+// ListRDMADevices 从 sysfs 读取所有 RDMA 设备及其网络接口
+func ListRDMADevices() ([]RDMADevice, error) {
+	entries, err := os.ReadDir(SysfsInfinibandPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取RDMA设备目录失败: %v", err)
+	}
+
+	devices := make([]RDMADevice, 0, len(entries))
+	for _, entry := range entries {
+		device, err := GetRDMADevice(entry.Name())
+		if err != nil {
+			continue
+		}
+		devices = append(devices, *device)
+	}
+
+	return devices, nil
+}
+
+// GetRDMADevice 从 sysfs 读取单个 RDMA 设备的网络接口映射
+func GetRDMADevice(name string) (*RDMADevice, error) {
+	netDevs, err := GetNetDevsForRDMA(name)
+	if err != nil {
+		return nil, err
+	}
+
+	device := &RDMADevice{
+		Name:       name,
+		Interfaces: make([]RDMANetDev, 0, len(netDevs)),
+	}
+	for _, netDev := range netDevs {
+		device.Interfaces = append(device.Interfaces, RDMANetDev{
+			Name: netDev,
+			IPs:  getInterfaceIPv4s(netDev),
+		})
+	}
+
+	return device, nil
+}
+
+// GetNetDevsForRDMA 读取 /sys/class/infiniband/<dev>/device/net/ 获取 RDMA 设备的网络接口
+// 适用于 InfiniBand（ibN）、RoCE（以太网接口）以及被重命名的接口
+func GetNetDevsForRDMA(rdmaDevice string) ([]string, error) {
+	devicePath := filepath.Join(SysfsInfinibandPath, rdmaDevice)
+	if _, err := os.Stat(devicePath); err != nil {
+		return nil, fmt.Errorf("RDMA设备 %s 不存在: %v", rdmaDevice, err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(devicePath, "device", "net"))
+	if err != nil {
+		return nil, fmt.Errorf("读取RDMA设备 %s 的网络接口失败: %v", rdmaDevice, err)
+	}
+
+	netDevs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		netDevs = append(netDevs, entry.Name())
+	}
+	sort.Strings(netDevs)
+
+	if len(netDevs) == 0 {
+		return nil, fmt.Errorf("RDMA设备 %s 没有关联的网络接口", rdmaDevice)
+	}
+	return netDevs, nil
+}
+
+// getInterfaceIPv4s 获取网络接口的所有IPv4地址
+func getInterfaceIPv4s(interfaceName string) []string {
+	ips := []string{}
+
+	iface, err := net.InterfaceByName(interfaceName)
+	if err != nil {
+		return ips
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return ips
+	}
+
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			ips = append(ips, ipnet.IP.String())
+		}
+	}
+	return ips
+}
+
+// RDMAPortState RDMA 端口链路状态
+type RDMAPortState struct {
+	Device    string `json:"device"`
+	Port      int    `json:"port"`
+	State     string `json:"state"`      // 例如 ACTIVE、DOWN、INIT
+	PhysState string `json:"phys_state"` // 例如 LinkUp、Disabled、Polling
+	Rate      string `json:"rate"`       // 例如 100 Gb/sec (4X EDR)
+	Active    bool   `json:"active"`
+}
+
+// GetRDMAPortStates 从 /sys/class/infiniband/<dev>/ports/<n>/ 读取设备各端口的链路状态
+func GetRDMAPortStates(rdmaDevice string) ([]RDMAPortState, error) {
+	portsPath := filepath.Join(SysfsInfinibandPath, rdmaDevice, "ports")
+	entries, err := os.ReadDir(portsPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取RDMA设备 %s 的端口失败: %v", rdmaDevice, err)
+	}
+
+	states := make([]RDMAPortState, 0, len(entries))
+	for _, entry := range entries {
+		port, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		portPath := filepath.Join(portsPath, entry.Name())
+		state := RDMAPortState{
+			Device:    rdmaDevice,
+			Port:      port,
+			State:     sysfsEnumValue(readSysfsValue(filepath.Join(portPath, "state"))),
+			PhysState: sysfsEnumValue(readSysfsValue(filepath.Join(portPath, "phys_state"))),
+			Rate:      readSysfsValue(filepath.Join(portPath, "rate")),
+		}
+		state.Active = state.State == "ACTIVE" && state.PhysState == "LinkUp"
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Port < states[j].Port })
+	return states, nil
+}
+
+// RDMAPortCounters RDMA 端口的累计流量计数和链路速率
+type RDMAPortCounters struct {
+	Device    string  `json:"device"`
+	Port      int     `json:"port"`
+	XmitBytes uint64  `json:"xmit_bytes"`
+	RcvBytes  uint64  `json:"rcv_bytes"`
+	Counted   bool    `json:"counted"` // 能否读取流量计数，为 false 时 XmitBytes 和 RcvBytes 无效
+	RateGbps  float64 `json:"rate_gbps"`
+	Active    bool    `json:"active"`
+}
+
+// GetRDMAPortCounters 从 /sys/class/infiniband/<dev>/ports/<n>/counters/ 读取设备各端口的累计收发字节数
+// port_xmit_data 和 port_rcv_data 以 4 字节为单位；端口没有流量计数时只返回链路速率
+func GetRDMAPortCounters(rdmaDevice string) ([]RDMAPortCounters, error) {
+	states, err := GetRDMAPortStates(rdmaDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make([]RDMAPortCounters, 0, len(states))
+	for _, state := range states {
+		port := RDMAPortCounters{
+			Device:   rdmaDevice,
+			Port:     state.Port,
+			RateGbps: parseRateGbps(state.Rate),
+			Active:   state.Active,
+		}
+		countersPath := filepath.Join(SysfsInfinibandPath, rdmaDevice, "ports", strconv.Itoa(state.Port), "counters")
+		xmit, xmitErr := strconv.ParseUint(readSysfsValue(filepath.Join(countersPath, "port_xmit_data")), 10, 64)
+		rcv, rcvErr := strconv.ParseUint(readSysfsValue(filepath.Join(countersPath, "port_rcv_data")), 10, 64)
+		if xmitErr == nil && rcvErr == nil {
+			port.XmitBytes = xmit * 4
+			port.RcvBytes = rcv * 4
+			port.Counted = true
+		}
+		counters = append(counters, port)
+	}
+	return counters, nil
+}
+
+// readSysfsValue 读取 sysfs 属性值，读取失败时返回空字符串
+func readSysfsValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// sysfsEnumValue 解析 "4: ACTIVE" 格式的 sysfs 枚举值
+func sysfsEnumValue(value string) string {
+	if idx := strings.Index(value, ":"); idx >= 0 {
+		return strings.TrimSpace(value[idx+1:])
+	}
+	return value
+}
+
+// GID 类型（/sys/class/infiniband/<dev>/ports/<n>/gid_attrs/types/<idx>）
+const (
+	GIDTypeIBRoCEv1 = "IB/RoCE v1"
+	GIDTypeRoCEv2   = "RoCE v2"
+)
+
+// RDMAGID RDMA 端口 GID 表项
+type RDMAGID struct {
+	Device string `json:"device"`
+	Port   int    `json:"port"`
+	Index  int    `json:"index"`
+	GID    string `json:"gid"`
+	Type   string `json:"type"`             // IB/RoCE v1 或 RoCE v2
+	NetDev string `json:"netdev,omitempty"` // 关联的网络接口（仅 RoCE）
+	IP     string `json:"ip,omitempty"`     // GID 对应的 IP 地址（仅 RoCE）
+}
+
+// GetRDMAGIDs 读取设备所有端口的有效 GID 表项
+func GetRDMAGIDs(rdmaDevice string) ([]RDMAGID, error) {
+	portsPath := filepath.Join(SysfsInfinibandPath, rdmaDevice, "ports")
+	entries, err := os.ReadDir(portsPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取RDMA设备 %s 的端口失败: %v", rdmaDevice, err)
+	}
+
+	gids := make([]RDMAGID, 0)
+	for _, entry := range entries {
+		port, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		portGIDs, err := getPortGIDs(rdmaDevice, port)
+		if err != nil {
+			continue
+		}
+		gids = append(gids, portGIDs...)
+	}
+
+	return gids, nil
+}
+
+// getPortGIDs 读取单个端口的有效 GID 表项（全零 GID 视为空）
+func getPortGIDs(rdmaDevice string, port int) ([]RDMAGID, error) {
+	portPath := filepath.Join(SysfsInfinibandPath, rdmaDevice, "ports", strconv.Itoa(port))
+	entries, err := os.ReadDir(filepath.Join(portPath, "gids"))
+	if err != nil {
+		return nil, err
+	}
+
+	gids := make([]RDMAGID, 0)
+	for _, entry := range entries {
+		index, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		value := readSysfsValue(filepath.Join(portPath, "gids", entry.Name()))
+		ip := net.ParseIP(value)
+		if ip == nil || ip.IsUnspecified() {
+			continue
+		}
+
+		gid := RDMAGID{
+			Device: rdmaDevice,
+			Port:   port,
+			Index:  index,
+			GID:    value,
+			Type:   readSysfsValue(filepath.Join(portPath, "gid_attrs", "types", entry.Name())),
+			NetDev: readSysfsValue(filepath.Join(portPath, "gid_attrs", "ndevs", entry.Name())),
+		}
+		// RoCE GID 由网络接口地址生成，IPv4 地址以 ::ffff:a.b.c.d 形式出现
+		if gid.NetDev != "" {
+			if ipv4 := ip.To4(); ipv4 != nil {
+				gid.IP = ipv4.String()
+			} else if !ip.IsLinkLocalUnicast() {
+				gid.IP = ip.String()
+			}
+		}
+		gids = append(gids, gid)
+	}
+
+	sort.Slice(gids, func(i, j int) bool { return gids[i].Index < gids[j].Index })
+	return gids, nil
+}
+
+// SelectRoCEv2GID 选择设备上用于 RoCE v2 的 GID（优先 IPv4，其次全局 IPv6）
+func SelectRoCEv2GID(rdmaDevice string) (*RDMAGID, error) {
+	gids, err := GetRDMAGIDs(rdmaDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	var ipv6 *RDMAGID
+	for i := range gids {
+		gid := &gids[i]
+		if gid.Type != GIDTypeRoCEv2 || gid.IP == "" {
+			continue
+		}
+		if net.ParseIP(gid.IP).To4() != nil {
+			return gid, nil
+		}
+		if ipv6 == nil {
+			ipv6 = gid
+		}
+	}
+
+	if ipv6 != nil {
+		return ipv6, nil
+	}
+	return nil, fmt.Errorf("RDMA设备 %s 没有可用的 RoCE v2 GID", rdmaDevice)
+}
+
+// GetSourceIPForRDMA 获取 RDMA 设备用于传输的源 IP：RoCE 设备使用 RoCE v2 GID 对应的地址，
+// InfiniBand 设备使用关联网络接口（IPoIB）的 IPv4 地址
+func GetSourceIPForRDMA(rdmaDevice string) (string, error) {
+	if gid, err := SelectRoCEv2GID(rdmaDevice); err == nil {
+		return gid.IP, nil
+	}
+
+	netDevs, err := GetNetDevsForRDMA(rdmaDevice)
+	if err != nil {
+		return "", err
+	}
+	for _, netDev := range netDevs {
+		if ips := getInterfaceIPv4s(netDev); len(ips) > 0 {
+			return ips[0], nil
+		}
+	}
+	return "", fmt.Errorf("RDMA设备 %s 的网络接口 %v 没有可用的IPv4地址", rdmaDevice, netDevs)
+}
+
+// RDMACapabilities RDMA 设备能力信息
+type RDMACapabilities struct {
+	Device      string                 `json:"device"`
+	HCAType     string                 `json:"hca_type,omitempty"`
+	FirmwareVer string                 `json:"fw_ver,omitempty"`
+	MaxQP       int                    `json:"max_qp,omitempty"` // 来自 ibv_devinfo，不可用时为 0
+	MaxMRSize   string                 `json:"max_mr_size,omitempty"`
+	Ports       []RDMAPortCapabilities `json:"ports"`
+	Warnings    []string               `json:"warnings,omitempty"`
+}
+
+// RDMAPortCapabilities RDMA 端口能力信息
+type RDMAPortCapabilities struct {
+	Port      int     `json:"port"`
+	LinkLayer string  `json:"link_layer"` // InfiniBand 或 Ethernet
+	Rate      string  `json:"rate"`
+	RateGbps  float64 `json:"rate_gbps"`
+	ActiveMTU int     `json:"active_mtu,omitempty"` // RDMA 路径 MTU（字节），来自 ibv_devinfo
+	MaxMTU    int     `json:"max_mtu,omitempty"`
+	NetDev    string  `json:"netdev,omitempty"`
+	NetDevMTU int     `json:"netdev_mtu,omitempty"`
+	Active    bool    `json:"active"`
+}
+
+// roceHeaderOverhead RoCE v2 封装开销（以太网 + IP + UDP + BTH + ICRC 的保守估计）
+const roceHeaderOverhead = 96
+
+// GetRDMACapabilities 收集设备的速率、MTU 以及基本能力，并给出 MTU 不匹配等告警
+func GetRDMACapabilities(rdmaDevice string) (*RDMACapabilities, error) {
+	devicePath := filepath.Join(SysfsInfinibandPath, rdmaDevice)
+	if _, err := os.Stat(devicePath); err != nil {
+		return nil, fmt.Errorf("RDMA设备 %s 不存在: %v", rdmaDevice, err)
+	}
+
+	caps := &RDMACapabilities{
+		Device:      rdmaDevice,
+		HCAType:     readSysfsValue(filepath.Join(devicePath, "hca_type")),
+		FirmwareVer: readSysfsValue(filepath.Join(devicePath, "fw_ver")),
+		Ports:       make([]RDMAPortCapabilities, 0),
+	}
+
+	states, err := GetRDMAPortStates(rdmaDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	netDevs, _ := GetNetDevsForRDMA(rdmaDevice)
+	devInfo := queryDevInfo(rdmaDevice)
+	if value, exists := devInfo.device["max_qp"]; exists {
+		caps.MaxQP, _ = strconv.Atoi(value)
+	}
+	caps.MaxMRSize = devInfo.device["max_mr_size"]
+
+	for _, state := range states {
+		portPath := filepath.Join(devicePath, "ports", strconv.Itoa(state.Port))
+		port := RDMAPortCapabilities{
+			Port:      state.Port,
+			LinkLayer: readSysfsValue(filepath.Join(portPath, "link_layer")),
+			Rate:      state.Rate,
+			RateGbps:  parseRateGbps(state.Rate),
+			Active:    state.Active,
+		}
+
+		if attrs, exists := devInfo.ports[state.Port]; exists {
+			port.ActiveMTU = parseMTU(attrs["active_mtu"])
+			port.MaxMTU = parseMTU(attrs["max_mtu"])
+		}
+
+		if port.NetDev = portNetDev(rdmaDevice, state.Port, netDevs); port.NetDev != "" {
+			port.NetDevMTU, _ = strconv.Atoi(readSysfsValue(filepath.Join("/sys/class/net", port.NetDev, "mtu")))
+		}
+
+		caps.Warnings = append(caps.Warnings, portWarnings(rdmaDevice, &port)...)
+		caps.Ports = append(caps.Ports, port)
+	}
+
+	return caps, nil
+}
+
+// portNetDev 获取端口对应的网络接口：接口的 dev_port 从 0 开始对应端口号减 1
+// 名称排序与端口顺序不一定一致（例如 eth10 排在 eth9 之前），只有一个接口且没有 dev_port 时使用该接口
+func portNetDev(rdmaDevice string, port int, netDevs []string) string {
+	for _, netDev := range netDevs {
+		value := readSysfsValue(filepath.Join(SysfsInfinibandPath, rdmaDevice, "device", "net", netDev, "dev_port"))
+		if devPort, err := strconv.Atoi(value); err == nil && devPort == port-1 {
+			return netDev
+		}
+	}
+	if len(netDevs) == 1 && readSysfsValue(filepath.Join(SysfsInfinibandPath, rdmaDevice, "device", "net", netDevs[0], "dev_port")) == "" {
+		return netDevs[0]
+	}
+	return ""
+}
+
+// portWarnings 检查端口配置问题
+func portWarnings(rdmaDevice string, port *RDMAPortCapabilities) []string {
+	warnings := make([]string, 0)
+
+	if !port.Active {
+		warnings = append(warnings, fmt.Sprintf("%s 端口 %d 未处于活跃状态", rdmaDevice, port.Port))
+	}
+
+	// RoCE 的 RDMA MTU 必须能装进以太网 MTU，否则大报文会被丢弃
+	if port.LinkLayer == "Ethernet" && port.ActiveMTU > 0 && port.NetDevMTU > 0 &&
+		port.ActiveMTU+roceHeaderOverhead > port.NetDevMTU {
+		warnings = append(warnings, fmt.Sprintf("%s 端口 %d 的 RDMA MTU %d 超过网络接口 %s 的 MTU %d",
+			rdmaDevice, port.Port, port.ActiveMTU, port.NetDev, port.NetDevMTU))
+	}
+
+	if port.MaxMTU > 0 && port.ActiveMTU > 0 && port.ActiveMTU < port.MaxMTU {
+		warnings = append(warnings, fmt.Sprintf("%s 端口 %d 的活跃 MTU %d 小于最大 MTU %d，可能存在对端或交换机 MTU 不匹配",
+			rdmaDevice, port.Port, port.ActiveMTU, port.MaxMTU))
+	}
+
+	return warnings
+}
+
+// parseRateGbps 解析 "100 Gb/sec (4X EDR)" 格式的速率
+func parseRateGbps(rate string) float64 {
+	fields := strings.Fields(rate)
+	if len(fields) == 0 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// parseMTU 解析 "4096 (5)" 格式的 MTU
+func parseMTU(value string) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	mtu, _ := strconv.Atoi(fields[0])
+	return mtu
+}
+
+// devInfo ibv_devinfo -v 的解析结果
+type devInfo struct {
+	device map[string]string
+	ports  map[int]map[string]string
+}
+
+// queryDevInfo 执行 ibv_devinfo -v 获取 sysfs 中没有的能力信息，工具不可用时返回空结果
+func queryDevInfo(rdmaDevice string) devInfo {
+	info := devInfo{
+		device: make(map[string]string),
+		ports:  make(map[int]map[string]string),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "ibv_devinfo", "-d", rdmaDevice, "-v").Output()
+	if err != nil {
+		return info
+	}
+
+	current := info.device
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if key == "port" {
+			if port, err := strconv.Atoi(value); err == nil {
+				current = make(map[string]string)
+				info.ports[port] = current
+			}
+			continue
+		}
+		if _, exists := current[key]; !exists {
+			current[key] = value
+		}
+	}
+
+	return info
+}
+
+// TotalRateGbps 设备所有活跃端口的总速率
+func (c *RDMACapabilities) TotalRateGbps() float64 {
+	total := 0.0
+	for _, port := range c.Ports {
+		if port.Active {
+			total += port.RateGbps
+		}
+	}
+	return total
+}
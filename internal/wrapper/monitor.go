@@ -2,13 +2,22 @@ package wrapper
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"rdma-burst/pkg/logger"
+	"rdma-burst/pkg/tracing"
 )
 
 // TransferStatus 定义传输状态
@@ -129,6 +138,9 @@ func (lp *LogParser) convertToBytes(value int64, unit string) int64 {
 	}
 }
 
+// DefaultMonitorInterval 未设置间隔时读取 rtranfile 日志的间隔
+const DefaultMonitorInterval = 100 * time.Millisecond
+
 // TransferMonitor 传输监控器
 type TransferMonitor struct {
 	mu          sync.RWMutex
@@ -137,6 +149,11 @@ type TransferMonitor struct {
 	parser      *LogParser
 	stopChan    chan struct{}
 	isMonitoring bool
+	logger      *zap.Logger
+	span        trace.Span      // 监控期间的 span
+	lineFilters []func(string) string // 解析前依次处理日志行（录制、故障注入）
+	lines       int                   // 已读取的日志行数
+	interval    time.Duration         // 等待日志文件创建和读取新日志行的间隔
 }
 
 // NewTransferMonitor 创建新的传输监控器
@@ -150,11 +167,37 @@ func NewTransferMonitor(logFile string) *TransferMonitor {
 		logFile:  logFile,
 		parser:   NewLogParser(),
 		stopChan: make(chan struct{}),
+		logger:   logger.GetLogger().Named(logger.ComponentMonitor),
+		interval: DefaultMonitorInterval,
 	}
 }
 
-// StartMonitoring 开始监控
-func (tm *TransferMonitor) StartMonitoring() error {
+// SetLogger 设置日志器
+func (tm *TransferMonitor) SetLogger(logger *zap.Logger) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.logger = logger
+}
+
+// SetInterval 设置读取日志的间隔（进度更新间隔），不大于 0 时使用 DefaultMonitorInterval，需要在 StartMonitoring 之前调用
+func (tm *TransferMonitor) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultMonitorInterval
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.interval = interval
+}
+
+// AddLineFilter 添加解析前处理日志行的函数，按添加顺序执行，需要在 StartMonitoring 之前调用
+func (tm *TransferMonitor) AddLineFilter(filter func(string) string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.lineFilters = append(tm.lineFilters, filter)
+}
+
+// StartMonitoring 开始监控，监控期间的 span 挂在 ctx 下，ctx 取消时停止读取日志
+func (tm *TransferMonitor) StartMonitoring(ctx context.Context) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -166,8 +209,12 @@ func (tm *TransferMonitor) StartMonitoring() error {
 	tm.progress.Status = StatusStarting
 	tm.progress.StartTime = time.Now()
 
+	_, tm.span = tracing.Start(ctx, "rtranfile.monitor", attribute.String("rtranfile.log_file", tm.logFile))
+
+	tm.logger.Debug("开始监控传输日志", zap.String("log_file", tm.logFile))
+
 	// 启动监控协程
-	go tm.monitorLogFile()
+	go tm.monitorLogFile(ctx)
 
 	return nil
 }
@@ -184,6 +231,14 @@ func (tm *TransferMonitor) StopMonitoring() {
 		if tm.progress.Status == StatusInProgress {
 			tm.progress.Status = StatusCancelled
 		}
+
+		if tm.span != nil {
+			tm.span.SetAttributes(
+				attribute.String("transfer.status", string(tm.progress.Status)),
+				attribute.Int64("transfer.bytes_transferred", tm.progress.BytesTransferred),
+			)
+			tm.span.End()
+		}
 	}
 }
 
@@ -218,17 +273,20 @@ func (tm *TransferMonitor) GetProgress() *ProgressInfo {
 }
 
 // monitorLogFile 监控日志文件
-func (tm *TransferMonitor) monitorLogFile() {
+func (tm *TransferMonitor) monitorLogFile(ctx context.Context) {
 	// 等待日志文件创建
 	for {
 		select {
 		case <-tm.stopChan:
 			return
+		case <-ctx.Done():
+			tm.SetStatus(StatusCancelled, ctx.Err().Error())
+			return
 		default:
 			if _, err := os.Stat(tm.logFile); err == nil {
 				break
 			}
-			time.Sleep(100 * time.Millisecond)
+			time.Sleep(tm.interval)
 		}
 		break
 	}
@@ -236,6 +294,7 @@ func (tm *TransferMonitor) monitorLogFile() {
 	// 打开日志文件
 	file, err := os.Open(tm.logFile)
 	if err != nil {
+		tm.logger.Error("打开传输日志文件失败", zap.String("log_file", tm.logFile), zap.Error(err))
 		tm.mu.Lock()
 		tm.progress.Status = StatusFailed
 		tm.progress.Error = fmt.Sprintf("打开日志文件失败: %v", err)
@@ -258,58 +317,99 @@ func (tm *TransferMonitor) monitorLogFile() {
 	tm.progress.Status = StatusInProgress
 	tm.mu.Unlock()
 
-	scanner := bufio.NewScanner(file)
-	ticker := time.NewTicker(100 * time.Millisecond)
+	// bufio.Scanner 读到文件末尾后不会再返回新写入的行，这里用 Reader 持续读取，未写完的行留到下次
+	reader := bufio.NewReader(file)
+	var partial string
+	ticker := time.NewTicker(tm.interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-tm.stopChan:
 			return
+		case <-ctx.Done():
+			tm.SetStatus(StatusCancelled, ctx.Err().Error())
+			return
 		case <-ticker.C:
 			// 读取新的日志行
-			for scanner.Scan() {
-				line := scanner.Text()
-				progressInfo, err := tm.parser.ParseLine(line)
-				if err != nil {
-					// 解析错误，记录但不中断监控
-					continue
+			for {
+				chunk, err := reader.ReadString('\n')
+				if err == io.EOF {
+					partial += chunk
+					break
 				}
-
-				if progressInfo != nil {
+				if err != nil {
+					tm.logger.Error("读取传输日志文件失败", zap.String("log_file", tm.logFile), zap.Error(err))
 					tm.mu.Lock()
-					// 更新进度信息
-					if progressInfo.Status != "" {
-						tm.progress.Status = progressInfo.Status
-					}
-					if progressInfo.BytesTransferred > 0 {
-						tm.progress.BytesTransferred = progressInfo.BytesTransferred
-					}
-					if progressInfo.TotalBytes > 0 {
-						tm.progress.TotalBytes = progressInfo.TotalBytes
-					}
-					if progressInfo.ProgressPercent > 0 {
-						tm.progress.ProgressPercent = progressInfo.ProgressPercent
-					}
-					if progressInfo.Error != "" {
-						tm.progress.Error = progressInfo.Error
-					}
-					tm.progress.LastUpdateTime = time.Now()
+					tm.progress.Status = StatusFailed
+					tm.progress.Error = fmt.Sprintf("读取日志文件失败: %v", err)
 					tm.mu.Unlock()
+					return
 				}
-			}
 
-			if err := scanner.Err(); err != nil {
-				tm.mu.Lock()
-				tm.progress.Status = StatusFailed
-				tm.progress.Error = fmt.Sprintf("读取日志文件失败: %v", err)
-				tm.mu.Unlock()
-				return
+				line := strings.TrimRight(partial+chunk, "\r\n")
+				partial = ""
+				tm.processLine(line)
 			}
 		}
 	}
 }
 
+// processLine 解析一行传输日志并更新进度
+func (tm *TransferMonitor) processLine(line string) {
+	defer func() {
+		tm.mu.Lock()
+		tm.lines++
+		tm.mu.Unlock()
+	}()
+
+	for _, filter := range tm.lineFilters {
+		line = filter(line)
+	}
+	progressInfo, err := tm.parser.ParseLine(line)
+	if err != nil {
+		// 解析错误，记录但不中断监控
+		tm.logger.Debug("解析传输日志行失败", zap.String("line", line), zap.Error(err))
+		return
+	}
+	if progressInfo == nil {
+		return
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	// 更新进度信息
+	if progressInfo.Status != "" {
+		if progressInfo.Status != tm.progress.Status && tm.span != nil {
+			tm.span.AddEvent("status_changed", trace.WithAttributes(
+				attribute.String("transfer.status", string(progressInfo.Status)),
+			))
+		}
+		tm.progress.Status = progressInfo.Status
+	}
+	if progressInfo.BytesTransferred > 0 {
+		tm.progress.BytesTransferred = progressInfo.BytesTransferred
+	}
+	if progressInfo.TotalBytes > 0 {
+		tm.progress.TotalBytes = progressInfo.TotalBytes
+	}
+	if progressInfo.ProgressPercent > 0 {
+		tm.progress.ProgressPercent = progressInfo.ProgressPercent
+	}
+	if progressInfo.Error != "" {
+		tm.progress.Error = progressInfo.Error
+	}
+	tm.progress.LastUpdateTime = time.Now()
+}
+
+// linesProcessed 获取已读取并处理的日志行数
+func (tm *TransferMonitor) linesProcessed() int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.lines
+}
+
 // IsMonitoring 检查是否在监控中
 func (tm *TransferMonitor) IsMonitoring() bool {
 	tm.mu.RLock()
@@ -5,9 +5,16 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/pkg/logger"
 )
 
 // ProcessState 定义进程状态
@@ -21,6 +28,13 @@ const (
 	StateError    ProcessState = "error"
 )
 
+const (
+	// DefaultStopTimeout Stop 发送中断信号后等待进程退出的默认时间，超时后强制终止
+	DefaultStopTimeout = 10 * time.Second
+	// DefaultCleanupTimeout Cleanup 发送中断信号后等待进程退出的默认时间，超时后强制终止
+	DefaultCleanupTimeout = 5 * time.Second
+)
+
 // ProcessInfo 定义进程信息
 type ProcessInfo struct {
 	PID         int          `json:"pid"`
@@ -30,6 +44,7 @@ type ProcessInfo struct {
 	ExitCode    *int         `json:"exit_code,omitempty"`
 	Error       string       `json:"error,omitempty"`
 	CommandLine string       `json:"command_line"`
+	StartTicks  uint64       `json:"start_ticks,omitempty"` // 进程启动时间（系统启动后的时钟周期数），与 PID 一起唯一确定进程
 }
 
 // ProcessManager 进程管理器
@@ -39,6 +54,10 @@ type ProcessManager struct {
 	info     *ProcessInfo
 	ctx      context.Context
 	cancel   context.CancelFunc
+	logger   *zap.Logger
+
+	stopTimeout    time.Duration
+	cleanupTimeout time.Duration
 }
 
 // NewProcessManager 创建新的进程管理器
@@ -48,16 +67,42 @@ func NewProcessManager() *ProcessManager {
 		info: &ProcessInfo{
 			State: StateStopped,
 		},
-		ctx:    ctx,
-		cancel: cancel,
+		ctx:            ctx,
+		cancel:         cancel,
+		logger:         logger.GetLogger().Named(logger.ComponentWrapper),
+		stopTimeout:    DefaultStopTimeout,
+		cleanupTimeout: DefaultCleanupTimeout,
+	}
+}
+
+// SetLogger 设置日志器
+func (pm *ProcessManager) SetLogger(logger *zap.Logger) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.logger = logger
+}
+
+// SetTimeouts 设置停止和清理进程时等待其退出的时间，不大于 0 时保留默认值
+func (pm *ProcessManager) SetTimeouts(stop, cleanup time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if stop > 0 {
+		pm.stopTimeout = stop
+	}
+	if cleanup > 0 {
+		pm.cleanupTimeout = cleanup
 	}
 }
 
-// Start 启动进程
-func (pm *ProcessManager) Start(cmd *exec.Cmd) error {
+// Start 启动进程，ctx 已取消时不再启动
+func (pm *ProcessManager) Start(ctx context.Context, cmd *exec.Cmd) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("启动进程已取消: %v", err)
+	}
+
 	if pm.process != nil && pm.info.State == StateRunning {
 		return fmt.Errorf("进程已经在运行中")
 	}
@@ -66,7 +111,7 @@ func (pm *ProcessManager) Start(cmd *exec.Cmd) error {
 	pm.info = &ProcessInfo{
 		State:       StateStarting,
 		StartTime:   time.Now(),
-		CommandLine: fmt.Sprintf("%s %v", cmd.Path, cmd.Args),
+		CommandLine: logger.Redact(fmt.Sprintf("%s %v", cmd.Path, cmd.Args)),
 	}
 
 	// 启动进程
@@ -79,6 +124,12 @@ func (pm *ProcessManager) Start(cmd *exec.Cmd) error {
 	pm.process = cmd
 	pm.info.PID = cmd.Process.Pid
 	pm.info.State = StateRunning
+	pm.info.StartTicks, _ = startTicks(pm.info.PID)
+
+	pm.logger.Info("进程已启动",
+		zap.Int("pid", pm.info.PID),
+		zap.String("command", pm.info.CommandLine),
+	)
 
 	// 对于服务端进程（rtranfile服务端），不启动监控协程
 	// 因为服务端进程应该在循环模式下持续运行
@@ -91,6 +142,72 @@ func (pm *ProcessManager) Start(cmd *exec.Cmd) error {
 	return nil
 }
 
+// Attach 挂载一个已在运行、但不是由当前进程启动的进程（例如守护进程重启后的 rtranfile）
+// name 为进程可执行文件的文件名，ticks 为进程启动时记录的启动时间，两者不一致时说明 PID 已被复用（例如系统重启后），不挂载
+// 无法通过 Wait 获取退出码，只能周期性检查进程是否存在
+func (pm *ProcessManager) Attach(pid int, name string, ticks uint64) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.process != nil && pm.info.State == StateRunning {
+		return fmt.Errorf("进程已经在运行中")
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("查找进程失败: %v", err)
+	}
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return fmt.Errorf("进程 %d 不存在: %v", pid, err)
+	}
+	if err := checkIdentity(pid, name, ticks); err != nil {
+		return err
+	}
+
+	pm.process = &exec.Cmd{Process: process}
+	pm.info = &ProcessInfo{
+		PID:         pid,
+		State:       StateRunning,
+		StartTime:   time.Now(),
+		CommandLine: fmt.Sprintf("attached pid %d", pid),
+		StartTicks:  ticks,
+	}
+
+	pm.logger.Info("已挂载运行中的进程", zap.Int("pid", pid))
+
+	go pm.pollProcess(process)
+
+	return nil
+}
+
+// pollProcess 周期性检查挂载的进程是否退出
+func (pm *ProcessManager) pollProcess(process *os.Process) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pm.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := process.Signal(syscall.Signal(0)); err == nil {
+				continue
+			}
+
+			pm.mu.Lock()
+			if pm.info.State != StateStopping {
+				exitTime := time.Now()
+				pm.info.ExitTime = &exitTime
+				pm.info.State = StateStopped
+				pm.logger.Info("挂载的进程已退出", zap.Int("pid", pm.info.PID))
+			}
+			pm.process = nil
+			pm.mu.Unlock()
+			return
+		}
+	}
+}
+
 // Stop 停止进程
 func (pm *ProcessManager) Stop() error {
 	pm.mu.Lock()
@@ -133,7 +250,7 @@ func (pm *ProcessManager) Stop() error {
 		} else {
 			pm.info.State = StateStopped
 		}
-	case <-time.After(10 * time.Second):
+	case <-time.After(pm.stopTimeout):
 		// 超时强制终止
 		if err := pm.process.Process.Kill(); err != nil {
 			pm.info.State = StateError
@@ -171,6 +288,17 @@ func (pm *ProcessManager) GetPID() int {
 	return pm.info.PID
 }
 
+// GetStartTicks 获取进程的启动时间（系统启动后的时钟周期数），重新挂载进程时据此确认 PID 没有被复用
+func (pm *ProcessManager) GetStartTicks() uint64 {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if pm.info == nil {
+		return 0
+	}
+	return pm.info.StartTicks
+}
+
 // IsRunning 检查进程是否在运行
 func (pm *ProcessManager) IsRunning() bool {
 	pm.mu.RLock()
@@ -199,8 +327,79 @@ func (pm *ProcessManager) IsRunning() bool {
 
 	// 尝试向进程发送信号0（不发送信号，只检查进程是否存在）
 	// 在Unix系统上，向进程发送信号0可以检查进程是否存在
-	err := process.Signal(syscall.Signal(0))
-	return err == nil
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return false
+	}
+
+	// 没有监控协程等待的进程（服务端监听进程）退出后成为僵尸进程，信号0仍然成功
+	return !exited(process.Pid)
+}
+
+// exited 通过 /proc/<pid>/stat 判断进程是否已退出但尚未被回收，无法读取时认为仍在运行
+func exited(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// 进程名可能包含空格和括号，状态字段位于最后一个右括号之后
+	stat := string(data)
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 || i+2 >= len(stat) {
+		return false
+	}
+	state := stat[i+2]
+	return state == 'Z' || state == 'X'
+}
+
+// checkIdentity 通过 /proc/<pid> 确认进程是启动时间为 ticks 的 name 进程
+// 命令行的第一个参数或可执行文件的文件名为 name 即认为一致（rtranfile 可能通过指向当前版本的符号链接启动）
+func checkIdentity(pid int, name string, ticks uint64) error {
+	if ticks == 0 {
+		return fmt.Errorf("没有记录进程 %d 的启动时间，无法确认进程身份", pid)
+	}
+	started, err := startTicks(pid)
+	if err != nil {
+		return err
+	}
+	if started != ticks {
+		return fmt.Errorf("进程 %d 的启动时间与记录不一致，PID 已被其他进程使用", pid)
+	}
+
+	matched := false
+	if cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil {
+		argv0, _, _ := strings.Cut(string(cmdline), "\x00")
+		matched = filepath.Base(argv0) == name
+	}
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil && filepath.Base(exe) == name {
+		matched = true
+	}
+	if !matched {
+		return fmt.Errorf("进程 %d 不是 %s，PID 已被其他进程使用", pid, name)
+	}
+	return nil
+}
+
+// startTicks 读取 /proc/<pid>/stat 中进程的启动时间（第 22 个字段，系统启动后的时钟周期数）
+func startTicks(pid int) (uint64, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, fmt.Errorf("读取进程 %d 的状态失败: %v", pid, err)
+	}
+	// 进程名可能包含空格和括号，从最后一个右括号之后的第 3 个字段（状态）开始计数
+	stat := string(data)
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("进程 %d 的状态格式无效", pid)
+	}
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("进程 %d 的状态格式无效", pid)
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("进程 %d 的启动时间无效: %v", pid, err)
+	}
+	return ticks, nil
 }
 
 // monitorProcess 监控进程状态
@@ -227,14 +426,21 @@ func (pm *ProcessManager) monitorProcess() {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode := exitErr.ExitCode()
 			pm.info.ExitCode = &exitCode
-			fmt.Printf("进程异常退出，退出码: %d, 错误: %v\n", exitCode, err)
+			pm.logger.Warn("进程异常退出",
+				zap.Int("pid", pm.info.PID),
+				zap.Int("exit_code", exitCode),
+				zap.Error(err),
+			)
 		} else {
-			fmt.Printf("进程退出错误: %v\n", err)
+			pm.logger.Error("进程退出错误",
+				zap.Int("pid", pm.info.PID),
+				zap.Error(err),
+			)
 		}
 		pm.info.State = StateError
 		pm.info.Error = err.Error()
 	} else {
-		fmt.Printf("进程正常退出\n")
+		pm.logger.Info("进程正常退出", zap.Int("pid", pm.info.PID))
 		pm.info.State = StateStopped
 	}
 
@@ -269,7 +475,7 @@ func (pm *ProcessManager) Cleanup() {
 		
 		// 等待一段时间后强制终止
 		select {
-		case <-time.After(5 * time.Second):
+		case <-time.After(pm.cleanupTimeout):
 			_ = pm.process.Process.Kill()
 		case <-pm.ctx.Done():
 		}
@@ -0,0 +1,161 @@
+package wrapper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordedLine 录制的一行 rtranfile 日志，offset_ms 为相对录制开始的毫秒数
+type RecordedLine struct {
+	OffsetMs int64  `json:"offset_ms"`
+	Line     string `json:"line"`
+}
+
+// Offset 获取相对录制开始的时间
+func (l RecordedLine) Offset() time.Duration {
+	return time.Duration(l.OffsetMs) * time.Millisecond
+}
+
+// LogRecorder 将 rtranfile 日志逐行录制为 JSON Lines 文件，用于回放验证日志解析
+type LogRecorder struct {
+	mu    sync.Mutex
+	start time.Time
+	file  *os.File
+	enc   *json.Encoder
+}
+
+// NewLogRecorder 创建录制文件
+func NewLogRecorder(path string) (*LogRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建录制目录失败: %v", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建录制文件失败: %v", err)
+	}
+	return &LogRecorder{start: time.Now(), file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Path 获取录制文件路径
+func (r *LogRecorder) Path() string {
+	return r.file.Name()
+}
+
+// Record 录制一行日志并原样返回，可以作为 TransferMonitor 的行处理函数；关闭后不再录制
+func (r *LogRecorder) Record(line string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enc != nil {
+		r.enc.Encode(RecordedLine{OffsetMs: time.Since(r.start).Milliseconds(), Line: line})
+	}
+	return line
+}
+
+// Close 关闭录制文件
+func (r *LogRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enc == nil {
+		return nil
+	}
+	r.enc = nil
+	return r.file.Close()
+}
+
+// ReadRecording 读取录制文件；不是 JSON Lines 格式时按普通 rtranfile 日志读取，所有行的时间偏移为 0
+func ReadRecording(r io.Reader) ([]RecordedLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []RecordedLine
+	plain := false
+	for scanner.Scan() {
+		text := scanner.Text()
+		if !plain {
+			var line RecordedLine
+			if err := json.Unmarshal([]byte(text), &line); err == nil {
+				lines = append(lines, line)
+				continue
+			}
+			if len(lines) > 0 {
+				return nil, fmt.Errorf("第 %d 行不是有效的录制记录", len(lines)+1)
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			plain = true
+		}
+		lines = append(lines, RecordedLine{Line: strings.TrimRight(text, "\r")})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %v", err)
+	}
+	return lines, nil
+}
+
+// ReplayLog 按录制的时间间隔（除以 speed，speed <= 0 时不等待）将日志行写入 logFile，由 TransferMonitor 读取
+// 返回监控器处理完全部日志行后的进度
+func ReplayLog(ctx context.Context, lines []RecordedLine, speed float64, logFile string) (*ProgressInfo, error) {
+	file, err := os.Create(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("创建回放日志文件失败: %v", err)
+	}
+	defer file.Close()
+
+	monitor := NewTransferMonitor(logFile)
+	if err := monitor.StartMonitoring(ctx); err != nil {
+		return nil, err
+	}
+	defer monitor.StopMonitoring()
+
+	// 监控器从文件末尾开始读取，等它打开文件后再写入
+	if err := waitReplay(ctx, func() bool { return monitor.GetProgress().Status != StatusStarting }); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	for _, line := range lines {
+		if speed > 0 {
+			due := start.Add(time.Duration(float64(line.Offset()) / speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+		if _, err := file.WriteString(line.Line + "\n"); err != nil {
+			return nil, fmt.Errorf("写入回放日志失败: %v", err)
+		}
+	}
+
+	if err := waitReplay(ctx, func() bool { return monitor.linesProcessed() >= len(lines) }); err != nil {
+		return nil, err
+	}
+	return monitor.GetProgress(), nil
+}
+
+// waitReplay 轮询直到 done 返回 true 或 ctx 结束
+func waitReplay(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
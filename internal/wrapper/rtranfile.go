@@ -6,7 +6,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/pkg/logger"
 )
 
 // TransferMode 定义传输模式
@@ -57,6 +63,12 @@ type TransferConfig struct {
 	
 	// 是否使用内存映射
 	MMan bool `json:"mman"`
+	
+	// IB 块大小（-s），为 0 时使用 rtranfile 默认值
+	BlockSize int `json:"block_size,omitempty"`
+	
+	// IB 流量类别（--tclass），为 0 时不设置
+	TrafficClass int `json:"traffic_class,omitempty"`
 }
 
 // TransferResult 定义传输结果
@@ -69,18 +81,28 @@ type TransferResult struct {
 	EndTime          time.Time     `json:"end_time"`
 }
 
+// RtranfileBinary rtranfile 可执行文件的文件名，守护进程重启后重新挂载进程时据此确认进程身份
+const RtranfileBinary = "rtranfile"
+
 // RtranfileWrapper rtranfile 包装器
 type RtranfileWrapper struct {
 	binPath string // rtranfile 二进制文件路径
+	logger  *zap.Logger
 }
 
 // NewRtranfileWrapper 创建新的 rtranfile 包装器
 func NewRtranfileWrapper(binPath string) *RtranfileWrapper {
 	return &RtranfileWrapper{
 		binPath: binPath,
+		logger:  logger.GetLogger().Named(logger.ComponentWrapper),
 	}
 }
 
+// SetLogger 设置日志器
+func (w *RtranfileWrapper) SetLogger(logger *zap.Logger) {
+	w.logger = logger
+}
+
 // StartServer 启动 rtranfile 服务端
 func (w *RtranfileWrapper) StartServer(ctx context.Context, config *TransferConfig) (*exec.Cmd, error) {
 	// 确保工作目录存在
@@ -90,12 +112,11 @@ func (w *RtranfileWrapper) StartServer(ctx context.Context, config *TransferConf
 	
 	args := w.buildServerArgs(config)
 	
-	// 调试信息：显示完整的命令（去掉方括号）
-	cmdStr := w.binPath
-	for _, arg := range args {
-		cmdStr += " " + arg
-	}
-	fmt.Printf("执行 rtranfile 命令: %s\n", cmdStr)
+	w.logger.Info("执行 rtranfile 服务端命令",
+		zap.String("mode", string(config.Mode)),
+		zap.String("device", config.Device),
+		zap.String("command", w.commandLine(args)),
+	)
 	
 	cmd := exec.CommandContext(ctx, w.binPath, args...)
 	
@@ -125,12 +146,12 @@ func (w *RtranfileWrapper) StartClient(ctx context.Context, config *TransferConf
 	
 	args := w.buildClientArgs(config)
 	
-	// 调试信息：显示完整的命令（去掉方括号）
-	cmdStr := w.binPath
-	for _, arg := range args {
-		cmdStr += " " + arg
-	}
-	fmt.Printf("执行 rtranfile 命令: %s\n", cmdStr)
+	w.logger.Info("执行 rtranfile 客户端命令",
+		zap.String("mode", string(config.Mode)),
+		zap.String("direction", string(config.Direction)),
+		zap.String("server_address", config.ServerAddress),
+		zap.String("command", w.commandLine(args)),
+	)
 	
 	cmd := exec.CommandContext(ctx, w.binPath, args...)
 	
@@ -147,6 +168,11 @@ func (w *RtranfileWrapper) StartClient(ctx context.Context, config *TransferConf
 	return cmd, nil
 }
 
+// commandLine 拼接完整的命令行（用于日志）
+func (w *RtranfileWrapper) commandLine(args []string) string {
+	return w.binPath + " " + strings.Join(args, " ")
+}
+
 // buildServerArgs 构建服务端命令行参数
 func (w *RtranfileWrapper) buildServerArgs(config *TransferConfig) []string {
 	args := []string{
@@ -175,6 +201,9 @@ func (w *RtranfileWrapper) buildClientArgs(config *TransferConfig) []string {
 	// 根据传输模式添加参数
 	args = w.addModeSpecificArgs(args, config)
 	
+	// 添加 QoS 参数
+	args = w.addQoSArgs(args, config)
+	
 	// 添加传输方向参数
 	// 只使用文件名，不包含路径
 	filename := filepath.Base(config.Filename)
@@ -216,6 +245,18 @@ func (w *RtranfileWrapper) addModeSpecificArgs(args []string, config *TransferCo
 	return args
 }
 
+// addQoSArgs 添加 QoS 等级对应的参数
+// 服务端监听进程由同一模式的所有任务共享，QoS 参数只在客户端按任务设置
+func (w *RtranfileWrapper) addQoSArgs(args []string, config *TransferConfig) []string {
+	if config.BlockSize > 0 {
+		args = append(args, "-s", strconv.Itoa(config.BlockSize))
+	}
+	if config.TrafficClass > 0 {
+		args = append(args, "--tclass", strconv.Itoa(config.TrafficClass))
+	}
+	return args
+}
+
 // createLogFile 创建日志文件
 func (w *RtranfileWrapper) createLogFile(logPath string) (*os.File, error) {
 	// 确保日志目录存在
@@ -0,0 +1,738 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/auth"
+	"rdma-burst/internal/services/filemeta"
+	"rdma-burst/internal/services/manifest"
+)
+
+// 对外暴露的 API 类型（与服务端模型一致）
+type (
+	TransferRequest      = models.TransferRequest
+	TransferResponse     = models.TransferResponse
+	ProgressResponse     = models.ProgressResponse
+	TimelineEvent        = models.TimelineEvent
+	TaskListResponse     = models.TaskListResponse
+	HeartbeatRequest     = models.HeartbeatRequest
+	ProgressReport       = models.ProgressReport
+	ResumeRequest        = models.ResumeRequest
+	BatchTransferRequest = models.BatchTransferRequest
+	BatchTransfer        = models.BatchTransfer
+	HeartbeatResponse    = models.HeartbeatResponse
+	HealthResponse       = models.HealthResponse
+	MetricsResponse      = models.MetricsResponse
+	ErrorResponse        = models.ErrorResponse
+	TransferGrant        = auth.Grant
+	TransferGrantScope   = auth.GrantScope
+	Manifest             = manifest.Manifest
+	ManifestReport       = manifest.Report
+	FileMetadata         = filemeta.Metadata
+	StageFileRequest     = models.StageFileRequest
+	StageJob             = models.StageJob
+	TransferPlan         = models.TransferPlan
+	PlanEstimate         = models.PlanEstimate
+	AccountingResponse   = models.AccountingResponse
+	ModesResponse        = models.TransferModesResponse
+)
+
+// 任务状态
+const (
+	StatusPrepared   = models.StatusPrepared
+	StatusInProgress = models.StatusInProgress
+	StatusCompleted  = models.StatusCompleted
+	StatusFailed     = models.StatusFailed
+	StatusCancelled  = models.StatusCancelled
+)
+
+// 心跳上报的客户端传输状态
+const (
+	HeartbeatRunning   = models.HeartbeatRunning
+	HeartbeatCompleted = models.HeartbeatCompleted
+	HeartbeatFailed    = models.HeartbeatFailed
+)
+
+// APIError 服务端返回的错误
+type APIError struct {
+	StatusCode int    // HTTP 状态码
+	Code       string // 错误码，例如 TASK_NOT_FOUND
+	Message    string
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("请求失败: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// IsNotFound 判断错误是否为任务不存在
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsSessionExpired 判断错误是否为传输会话因心跳超时已过期
+func IsSessionExpired(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusGone
+}
+
+// IsNotResumable 判断错误是否为服务端没有保留任务的续传状态
+func IsNotResumable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == "NOT_RESUMABLE"
+}
+
+// 默认的请求超时
+const (
+	DefaultTimeout     = 30 * time.Second // 普通请求
+	DefaultWaitTimeout = 10 * time.Minute // 服务端需要读取整个文件的请求（生成和校验分块清单）
+)
+
+// sharedTransport 所有客户端共享的连接池，高频轮询时复用连接，避免耗尽临时端口
+var sharedTransport = newTransport()
+
+// newTransport 基于默认 Transport 调大每个服务端保留的空闲连接数
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 32
+	transport.IdleConnTimeout = 90 * time.Second
+	return transport
+}
+
+// Client rdma-burst HTTP API 客户端
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	timeout     time.Duration
+	waitTimeout time.Duration
+	token       string
+	signingKey  string
+	grantToken  string
+	username    string
+	password    string
+	retries     int
+	retryDelay  time.Duration
+	userAgent   string
+	language    string
+	observe     RequestObserver
+}
+
+// RequestObserver 每次请求尝试结束后调用，status 为 0 表示请求未得到响应（网络错误或超时）
+type RequestObserver func(method string, status int, duration time.Duration)
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义 HTTP 客户端
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout 设置普通请求每次尝试的超时，非正数时保留默认值
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithWaitTimeout 设置服务端需要读取整个文件的请求（生成和校验分块清单）每次尝试的超时，非正数时保留默认值
+func WithWaitTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.waitTimeout = timeout
+		}
+	}
+}
+
+// WithRetry 设置重试次数和间隔（仅对幂等请求以及网络错误、502/503/504 重试）
+func WithRetry(attempts int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.retries = attempts
+		c.retryDelay = delay
+	}
+}
+
+// WithRequestObserver 设置请求观察者，用于记录请求数和耗时等指标
+func WithRequestObserver(observe RequestObserver) Option {
+	return func(c *Client) {
+		c.observe = observe
+	}
+}
+
+// WithToken 使用 Bearer 令牌认证
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithBasicAuth 使用用户名密码认证
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithSigning 使用 HMAC 密钥为每个请求签名（服务端启用 security.signing 时使用）
+func WithSigning(secret string) Option {
+	return func(c *Client) {
+		c.signingKey = secret
+	}
+}
+
+// WithTransferToken 使用管理员签发的一次性传输令牌创建传输任务
+func WithTransferToken(token string) Option {
+	return func(c *Client) {
+		c.grantToken = token
+	}
+}
+
+// WithUserAgent 设置 User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLanguage 设置 Accept-Language，服务端按该语言（zh 或 en）返回错误消息，错误码不受影响
+func WithLanguage(language string) Option {
+	return func(c *Client) {
+		c.language = language
+	}
+}
+
+// New 创建客户端，baseURL 为服务地址，例如 http://192.168.1.100:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  &http.Client{Transport: sharedTransport},
+		timeout:     DefaultTimeout,
+		waitTimeout: DefaultWaitTimeout,
+		retryDelay:  time.Second,
+		userAgent:   "rdma-burst-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewFromHostPort 根据主机和端口创建客户端
+func NewFromHostPort(host string, port int, opts ...Option) *Client {
+	return New(fmt.Sprintf("http://%s:%d", host, port), opts...)
+}
+
+// BaseURL 获取服务地址
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// CreateTransfer 创建传输任务
+func (c *Client) CreateTransfer(ctx context.Context, req *TransferRequest) (*TransferResponse, error) {
+	var response TransferResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/transfers", req, http.StatusCreated, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// CreateBatchTransfer 一次创建多个文件的传输任务，所有任务使用同一个批次ID
+func (c *Client) CreateBatchTransfer(ctx context.Context, req *BatchTransferRequest) (*BatchTransfer, error) {
+	var batch BatchTransfer
+	if err := c.do(ctx, http.MethodPost, "/api/v1/transfers/batch", req, http.StatusCreated, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatchTransfer 获取批量传输的任务状态和汇总进度
+func (c *Client) GetBatchTransfer(ctx context.Context, batchID string) (*BatchTransfer, error) {
+	var batch BatchTransfer
+	if err := c.do(ctx, http.MethodGet, "/api/v1/transfers/batch/"+url.PathEscape(batchID), nil, http.StatusOK, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// EstimatePlan 请求服务端按历史吞吐量估算传输计划，不创建任务
+func (c *Client) EstimatePlan(ctx context.Context, plan *TransferPlan) (*PlanEstimate, error) {
+	var estimate PlanEstimate
+	if err := c.do(ctx, http.MethodPost, "/api/v1/plans/estimate", plan, http.StatusOK, &estimate); err != nil {
+		return nil, err
+	}
+	return &estimate, nil
+}
+
+// GetAccounting 查询 [from, to) 内各统计周期的用量记录，时间为零值时使用服务端默认范围，tenant 为空时返回所有租户（非管理员为自己）
+func (c *Client) GetAccounting(ctx context.Context, from, to time.Time, tenant string) (*AccountingResponse, error) {
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.RFC3339))
+	}
+	if tenant != "" {
+		query.Set("tenant", tenant)
+	}
+
+	var response AccountingResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/accounting?"+query.Encode(), nil, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// MintTransferToken 签发绑定文件名、模式和方向的一次性传输令牌（需要管理员凭据）
+func (c *Client) MintTransferToken(ctx context.Context, filename, mode, direction string, ttl time.Duration) (*TransferGrant, error) {
+	return c.MintScopedTransferToken(ctx, TransferGrantScope{Filename: filename, Mode: mode, Direction: direction}, ttl)
+}
+
+// MintScopedTransferToken 签发同时绑定源路径、目标路径和命名空间的一次性传输令牌（需要管理员凭据）
+func (c *Client) MintScopedTransferToken(ctx context.Context, scope TransferGrantScope, ttl time.Duration) (*TransferGrant, error) {
+	body := map[string]interface{}{
+		"filename":    scope.Filename,
+		"mode":        scope.Mode,
+		"direction":   scope.Direction,
+		"ttl_seconds": int(ttl / time.Second),
+	}
+	if scope.SourcePath != "" {
+		body["source_path"] = scope.SourcePath
+	}
+	if scope.DestinationPath != "" {
+		body["destination_path"] = scope.DestinationPath
+	}
+	if scope.Namespace != "" {
+		body["namespace"] = scope.Namespace
+	}
+
+	var grant TransferGrant
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tokens", body, http.StatusCreated, &grant); err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// GetManifest 获取服务端文件的分块清单，chunkSize 为 0 时使用服务端默认值
+func (c *Client) GetManifest(ctx context.Context, filename, mode string, chunkSize int64) (*Manifest, error) {
+	query := url.Values{}
+	query.Set("filename", filename)
+	query.Set("mode", mode)
+	if chunkSize > 0 {
+		query.Set("chunk_size", strconv.FormatInt(chunkSize, 10))
+	}
+
+	var m Manifest
+	if err := c.doTimeout(ctx, c.waitTimeout, http.MethodGet, "/api/v1/manifests?"+query.Encode(), nil, http.StatusOK, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// VerifyManifest 请求服务端按清单逐块校验其文件
+func (c *Client) VerifyManifest(ctx context.Context, mode string, m *Manifest) (*ManifestReport, error) {
+	body := map[string]interface{}{
+		"mode":     mode,
+		"manifest": m,
+	}
+
+	var report ManifestReport
+	if err := c.doTimeout(ctx, c.waitTimeout, http.MethodPost, "/api/v1/manifests/verify", body, http.StatusOK, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetFileMetadata 获取服务端文件的元数据，xattrs 为需要读取的扩展属性名模式
+func (c *Client) GetFileMetadata(ctx context.Context, filename, mode string, xattrs []string) (*FileMetadata, error) {
+	query := url.Values{}
+	query.Set("filename", filename)
+	query.Set("mode", mode)
+	if len(xattrs) > 0 {
+		query.Set("xattrs", strings.Join(xattrs, ","))
+	}
+
+	var m FileMetadata
+	if err := c.do(ctx, http.MethodGet, "/api/v1/metadata?"+query.Encode(), nil, http.StatusOK, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ApplyFileMetadata 请求服务端将元数据恢复到其文件
+func (c *Client) ApplyFileMetadata(ctx context.Context, mode string, m *FileMetadata) error {
+	body := map[string]interface{}{
+		"mode":     mode,
+		"metadata": m,
+	}
+	return c.do(ctx, http.MethodPut, "/api/v1/metadata", body, http.StatusOK, nil)
+}
+
+// StageFile 请求服务端把本机文件复制或硬链接到传输模式目录，暂存在后台执行
+func (c *Client) StageFile(ctx context.Context, req *StageFileRequest) (*StageJob, error) {
+	var job StageJob
+	if err := c.do(ctx, http.MethodPost, "/api/v1/files/stage", req, http.StatusAccepted, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetStageJob 获取本地暂存任务的进度
+func (c *Client) GetStageJob(ctx context.Context, id string) (*StageJob, error) {
+	var job StageJob
+	if err := c.do(ctx, http.MethodGet, "/api/v1/files/stage/"+url.PathEscape(id), nil, http.StatusOK, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetTransfer 获取传输任务状态和进度
+func (c *Client) GetTransfer(ctx context.Context, taskID string) (*ProgressResponse, error) {
+	var progress ProgressResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/transfers/"+url.PathEscape(taskID), nil, http.StatusOK, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// ListTransfers 分页列出传输任务
+func (c *Client) ListTransfers(ctx context.Context, page, size int) (*TaskListResponse, error) {
+	return c.ListTransfersWithLabels(ctx, page, size, nil)
+}
+
+// ListTransfersWithLabels 分页列出包含所有指定标签的传输任务
+func (c *Client) ListTransfersWithLabels(ctx context.Context, page, size int, labels map[string]string) (*TaskListResponse, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("size", strconv.Itoa(size))
+	for key, value := range labels {
+		query.Add("label", key+"="+value)
+	}
+	path := "/api/v1/transfers?" + query.Encode()
+
+	var taskList TaskListResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, http.StatusOK, &taskList); err != nil {
+		return nil, err
+	}
+	return &taskList, nil
+}
+
+// CancelTransfer 取消传输任务
+func (c *Client) CancelTransfer(ctx context.Context, taskID string) (*TransferResponse, error) {
+	var response TransferResponse
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/transfers/"+url.PathEscape(taskID), nil, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Heartbeat 在客户端执行传输期间上报心跳，会话过期时返回的错误满足 IsSessionExpired
+func (c *Client) Heartbeat(ctx context.Context, taskID string, req *HeartbeatRequest) (*HeartbeatResponse, error) {
+	var response HeartbeatResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/transfers/"+url.PathEscape(taskID)+"/heartbeat", req, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ReportProgress 在客户端执行传输期间上报进度，同时视为一次心跳
+func (c *Client) ReportProgress(ctx context.Context, taskID string, report *ProgressReport) (*ProgressResponse, error) {
+	var progress ProgressResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/transfers/"+url.PathEscape(taskID)+"/progress", report, http.StatusOK, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// ResumeTransfer 从检查点续传失败的任务，req 为 nil 时使用服务端保留的偏移
+func (c *Client) ResumeTransfer(ctx context.Context, taskID string, req *ResumeRequest) (*TransferResponse, error) {
+	if req == nil {
+		req = &ResumeRequest{}
+	}
+	var response TransferResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/transfers/"+url.PathEscape(taskID)+"/resume", req, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// RetryTransfer 按原始请求重试失败或被取消的任务，返回新创建的任务
+func (c *Client) RetryTransfer(ctx context.Context, taskID string) (*TransferResponse, error) {
+	var response TransferResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/transfers/"+url.PathEscape(taskID)+"/retry", nil, http.StatusCreated, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ListModes 获取服务端各传输模式是否启用及其限制
+func (c *Client) ListModes(ctx context.Context) (*ModesResponse, error) {
+	var modes ModesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/modes", nil, http.StatusOK, &modes); err != nil {
+		return nil, err
+	}
+	return &modes, nil
+}
+
+// Health 检查服务健康状态
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var health HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/api/health", nil, http.StatusOK, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Metrics 获取服务运行指标（包括 Goroutine、文件描述符和内存使用）
+func (c *Client) Metrics(ctx context.Context) (*MetricsResponse, error) {
+	var metrics MetricsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/health/metrics", nil, http.StatusOK, &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// WaitForCompletion 轮询任务直到结束（完成、失败或取消），任务失败时返回最后的进度和错误
+func (c *Client) WaitForCompletion(ctx context.Context, taskID string, interval time.Duration) (*ProgressResponse, error) {
+	var last *ProgressResponse
+	for progress := range c.StreamProgress(ctx, taskID, interval) {
+		if progress.Err != nil {
+			return last, progress.Err
+		}
+		last = progress.Progress
+	}
+
+	if last == nil {
+		return nil, ctx.Err()
+	}
+	switch last.Status {
+	case StatusCompleted:
+		return last, nil
+	case StatusFailed:
+		return last, fmt.Errorf("传输任务 %s 失败: %s", taskID, last.Error)
+	case StatusCancelled:
+		return last, fmt.Errorf("传输任务 %s 已取消", taskID)
+	default:
+		return last, ctx.Err()
+	}
+}
+
+// ProgressUpdate 进度流中的一次更新
+type ProgressUpdate struct {
+	Progress *ProgressResponse
+	Err      error
+}
+
+// StreamProgress 按间隔轮询任务进度并通过通道推送，服务端建议的查询间隔更长时按建议间隔轮询，任务结束、出错或 ctx 取消时关闭通道
+func (c *Client) StreamProgress(ctx context.Context, taskID string, interval time.Duration) <-chan ProgressUpdate {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	updates := make(chan ProgressUpdate, 1)
+	go func() {
+		defer close(updates)
+
+		for {
+			progress, err := c.GetTransfer(ctx, taskID)
+			if err != nil {
+				if ctx.Err() == nil {
+					updates <- ProgressUpdate{Err: err}
+				}
+				return
+			}
+
+			select {
+			case updates <- ProgressUpdate{Progress: progress}:
+			case <-ctx.Done():
+				return
+			}
+
+			if isFinished(progress.Status) {
+				return
+			}
+
+			// 服务端建议的查询间隔（poll_after）更长时按建议的间隔等待
+			wait := interval
+			if progress.PollAfter > wait {
+				wait = progress.PollAfter
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates
+}
+
+// isFinished 判断任务是否已结束
+func isFinished(status string) bool {
+	return status == StatusCompleted || status == StatusFailed || status == StatusCancelled
+}
+
+// do 发送请求并解析响应，按配置重试，每次尝试使用普通请求的超时
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, expectedStatus int, out interface{}) error {
+	return c.doTimeout(ctx, c.timeout, method, path, body, expectedStatus, out)
+}
+
+// doTimeout 发送请求并解析响应，按配置重试，每次尝试的超时为 timeout
+func (c *Client) doTimeout(ctx context.Context, timeout time.Duration, method, path string, body interface{}, expectedStatus int, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("序列化请求失败: %v", err)
+		}
+		payload = data
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.retryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		retryable, err := c.doOnce(ctx, timeout, method, path, payload, expectedStatus, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		// 非幂等请求（创建任务）不重试，避免重复创建
+		if !retryable || method == http.MethodPost || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// doOnce 发送一次请求，返回错误是否可重试
+func (c *Client) doOnce(ctx context.Context, timeout time.Duration, method, path string, payload []byte, expectedStatus int, out interface{}) (bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, fmt.Errorf("创建请求失败: %v", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.language != "" {
+		req.Header.Set("Accept-Language", c.language)
+	}
+	c.authenticate(req)
+	if c.signingKey != "" {
+		// 每次尝试重新签名，重试时使用新的随机数
+		if err := auth.SignRequest(req, payload, c.signingKey); err != nil {
+			return false, err
+		}
+	}
+
+	// 透传追踪上下文
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if c.observe != nil {
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
+		c.observe(method, status, time.Since(start))
+	}
+	if err != nil {
+		return true, fmt.Errorf("调用服务端API失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		return isRetryableStatus(resp.StatusCode), decodeError(resp)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("解析服务端响应失败: %v", err)
+		}
+	}
+	return false, nil
+}
+
+// authenticate 设置认证头
+func (c *Client) authenticate(req *http.Request) {
+	if c.grantToken != "" {
+		req.Header.Set(auth.GrantTokenHeader, c.grantToken)
+		return
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		return
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+}
+
+// isRetryableStatus 判断状态码是否可重试
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
+}
+
+// decodeError 解析服务端错误响应
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    resp.Status,
+	}
+
+	var errorResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Error != "" {
+		apiErr.Code = errorResp.Error
+		apiErr.Message = errorResp.Message
+	}
+	return apiErr
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"rdma-burst/internal/models"
+	"rdma-burst/internal/services/transfer"
+)
+
+// 对外暴露的类型（与服务端模型一致）
+type (
+	TransferRequest        = models.TransferRequest
+	TransferResponse       = models.TransferResponse
+	ProgressResponse       = models.ProgressResponse
+	TaskListResponse       = models.TaskListResponse
+	TransferSettings       = models.TransferSettings
+	SingleTransferSettings = models.SingleTransferSettings
+)
+
+// 传输模式
+const (
+	ModeHugepages  = models.ModeHugepages
+	ModeTmpfs      = models.ModeTmpfs
+	ModeFilesystem = models.ModeFilesystem
+)
+
+// 传输方向
+const (
+	DirectionPut = models.DirectionPut
+	DirectionGet = models.DirectionGet
+)
+
+// DefaultRtranfilePath 默认 rtranfile 路径
+const DefaultRtranfilePath = "./bin/rtranfile"
+
+// 引擎返回的错误，通过 errors.Is 判断
+var (
+	ErrClosed               = errors.New("传输引擎已关闭")
+	ErrTaskNotFound         = transfer.ErrTaskNotFound
+	ErrConcurrencyLimit     = transfer.ErrConcurrencyLimit
+	ErrIntervalNotElapsed   = transfer.ErrIntervalNotElapsed
+	ErrDeviceUnavailable    = transfer.ErrDeviceUnavailable
+	ErrNotOwner             = transfer.ErrNotOwner
+	ErrNoReferenceManifest  = transfer.ErrNoReferenceManifest
+	ErrUnknownQoSClass      = transfer.ErrUnknownQoSClass
+	ErrFileTooLarge         = transfer.ErrFileTooLarge
+	ErrFileExists           = transfer.ErrFileExists
+	ErrAdmissionRejected    = transfer.ErrAdmissionRejected
+	ErrAdmissionUnavailable = transfer.ErrAdmissionUnavailable
+	ErrSessionExpired       = transfer.ErrSessionExpired
+)
+
+// Config 引擎配置
+type Config struct {
+	RtranfilePath  string                  // rtranfile 路径，为空时使用 DefaultRtranfilePath
+	Transfer       *TransferSettings       // 传输设置，为空时使用默认设置
+	SingleTransfer *SingleTransferSettings // 单次传输设置，为空时使用默认值
+	ServerAddress  string                  // 客户端连接的服务端地址，为空时使用 localhost
+	DeviceCheck    func() error            // RDMA 设备可用性检查，返回错误时拒绝传输
+	Logger         *zap.Logger             // 日志器，为空时使用全局日志器
+}
+
+// Engine 可嵌入的传输引擎，不依赖 HTTP 层即可驱动传输
+type Engine struct {
+	service  *transfer.TransferService
+	settings TransferSettings
+	closed   atomic.Bool
+}
+
+// New 创建传输引擎
+func New(cfg Config) *Engine {
+	rtranfilePath := cfg.RtranfilePath
+	if rtranfilePath == "" {
+		rtranfilePath = DefaultRtranfilePath
+	}
+
+	settings := cfg.Transfer
+	if settings == nil {
+		settings = transfer.DefaultSettings()
+	}
+
+	service := transfer.NewTransferServiceWithConfig(rtranfilePath, settings, cfg.SingleTransfer)
+	if cfg.Logger != nil {
+		service.SetLogger(cfg.Logger)
+	}
+	if cfg.DeviceCheck != nil {
+		service.SetDeviceCheck(cfg.DeviceCheck)
+	}
+
+	// 保存副本，避免调用方后续修改影响引擎
+	e := &Engine{
+		service:  service,
+		settings: *settings,
+	}
+	e.settings.ServerAddress = cfg.ServerAddress
+	if e.settings.ServerAddress == "" {
+		e.settings.ServerAddress = "localhost"
+	}
+	return e
+}
+
+// Prepare 验证请求并准备传输环境（启动服务端监听进程）
+func (e *Engine) Prepare(ctx context.Context, req *TransferRequest) (*TransferResponse, error) {
+	if e.closed.Load() {
+		return nil, ErrClosed
+	}
+	if err := transfer.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	settings := e.settings
+	response, err := e.service.Prepare(ctx, req, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("准备传输环境失败: %w", err)
+	}
+	return response, nil
+}
+
+// Verify 启动只校验任务，重新计算服务端文件的分块摘要并与参考清单比较
+func (e *Engine) Verify(ctx context.Context, req *TransferRequest) (*TransferResponse, error) {
+	if e.closed.Load() {
+		return nil, ErrClosed
+	}
+	if err := transfer.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	settings := e.settings
+	return e.service.StartVerify(ctx, req, &settings)
+}
+
+// Status 获取传输任务状态和进度
+func (e *Engine) Status(taskID string) (*ProgressResponse, error) {
+	return e.service.GetTransferStatus(taskID)
+}
+
+// List 分页列出传输任务，labels 不为空时只返回包含所有指定标签的任务
+func (e *Engine) List(page, size int, labels map[string]string) *TaskListResponse {
+	return e.service.ListTransfers(page, size, labels)
+}
+
+// Cancel 取消传输任务
+func (e *Engine) Cancel(ctx context.Context, taskID string) error {
+	return e.service.CancelTransfer(ctx, taskID)
+}
+
+// ActiveTransfers 获取活跃传输任务数量
+func (e *Engine) ActiveTransfers() int {
+	return e.service.GetActiveTransfers()
+}
+
+// Close 停止所有任务和服务端监听进程
+func (e *Engine) Close() {
+	if e.closed.Swap(true) {
+		return
+	}
+	e.service.Cleanup()
+}
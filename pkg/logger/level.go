@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 组件名称（与 zap.Logger.Named 使用的名称一致）
+const (
+	ComponentAPI      = "api"
+	ComponentTransfer = "transfer"
+	ComponentWrapper  = "wrapper"
+	ComponentMonitor  = "monitor"
+)
+
+// DefaultComponent 表示未单独配置级别的组件
+const DefaultComponent = "default"
+
+// levelRegistry 组件日志级别注册表
+type levelRegistry struct {
+	mu           sync.RWMutex
+	defaultLevel zapcore.Level
+	components   map[string]zapcore.Level
+}
+
+// newLevelRegistry 创建组件日志级别注册表
+func newLevelRegistry(defaultLevel zapcore.Level, components map[string]string) *levelRegistry {
+	registry := &levelRegistry{
+		defaultLevel: defaultLevel,
+		components:   make(map[string]zapcore.Level),
+	}
+
+	for name, levelText := range components {
+		// 无效的级别配置直接忽略，使用默认级别
+		level, err := parseLevel(levelText)
+		if err != nil {
+			continue
+		}
+		registry.components[name] = level
+	}
+
+	return registry
+}
+
+// levelFor 获取日志器名称对应的级别（按名称第一段匹配组件）
+func (r *levelRegistry) levelFor(loggerName string) zapcore.Level {
+	component := loggerName
+	if idx := strings.Index(component, "."); idx >= 0 {
+		component = component[:idx]
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if level, exists := r.components[component]; exists {
+		return level
+	}
+	return r.defaultLevel
+}
+
+// minLevel 获取所有组件中的最低级别
+func (r *levelRegistry) minLevel() zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	min := r.defaultLevel
+	for _, level := range r.components {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+// set 设置组件级别
+func (r *levelRegistry) set(component string, level zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if component == "" || component == DefaultComponent {
+		r.defaultLevel = level
+		return
+	}
+	r.components[component] = level
+}
+
+// reset 移除组件级别，恢复使用默认级别
+func (r *levelRegistry) reset(component string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.components, component)
+}
+
+// snapshot 获取当前所有级别
+func (r *levelRegistry) snapshot() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := map[string]string{
+		DefaultComponent: r.defaultLevel.String(),
+	}
+	for name, level := range r.components {
+		levels[name] = level.String()
+	}
+	return levels
+}
+
+// componentCore 按日志器名称过滤级别的核心
+type componentCore struct {
+	zapcore.Core
+	registry *levelRegistry
+}
+
+// Enabled 只要有组件启用该级别即返回 true，具体过滤在 Check 中完成
+func (c *componentCore) Enabled(level zapcore.Level) bool {
+	return level >= c.registry.minLevel()
+}
+
+// With 添加字段
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &componentCore{
+		Core:     c.Core.With(fields),
+		registry: c.registry,
+	}
+}
+
+// Check 按组件级别过滤日志条目
+func (c *componentCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.registry.levelFor(entry.LoggerName) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// parseLevel 解析日志级别
+func parseLevel(text string) (zapcore.Level, error) {
+	level := zap.InfoLevel
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return level, fmt.Errorf("无效的日志级别: %s", text)
+	}
+	return level, nil
+}
+
+// SetLevel 运行时调整组件日志级别，component 为空或 default 时调整默认级别
+func SetLevel(component, level string) error {
+	if levels == nil {
+		return fmt.Errorf("日志系统未初始化")
+	}
+
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	levels.set(component, parsed)
+	return nil
+}
+
+// ResetLevel 移除组件的单独级别设置
+func ResetLevel(component string) error {
+	if levels == nil {
+		return fmt.Errorf("日志系统未初始化")
+	}
+
+	levels.reset(component)
+	return nil
+}
+
+// GetLevels 获取当前各组件日志级别
+func GetLevels() map[string]string {
+	if levels == nil {
+		return map[string]string{}
+	}
+	return levels.snapshot()
+}
@@ -16,6 +16,9 @@ type Config struct {
 	MaxBackups int    `yaml:"max_backups"` // 文件数量
 	MaxAge     int    `yaml:"max_age"`     // 天数
 	Format     string `yaml:"format"`      // json 或 text
+	Components map[string]string `yaml:"components"` // 组件级别，例如 wrapper: debug
+	Sink       SinkConfig        `yaml:"sink"`       // 可选的 syslog/journald 输出
+	RedactKeys []string          `yaml:"redact_keys"` // 额外需要脱敏的字段名
 }
 
 // Logger 日志接口
@@ -28,20 +31,37 @@ type Logger interface {
 	With(fields ...zap.Field) *zap.Logger
 }
 
-var globalLogger *zap.Logger
+var (
+	globalLogger *zap.Logger
+	levels       *levelRegistry // 全局日志器的组件级别
+)
 
 // Init 初始化日志系统
 func Init(config Config) error {
-	// 创建日志目录
-	if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err != nil {
+	logger, registry, err := build(config)
+	if err != nil {
 		return err
 	}
 
+	globalLogger = logger
+	levels = registry
+	return nil
+}
+
+// New 根据配置创建日志器（文件输出按 lumberjack 规则轮转）
+func New(config Config) (*zap.Logger, error) {
+	logger, _, err := build(config)
+	return logger, err
+}
+
+// build 创建日志器及其组件级别注册表
+func build(config Config) (*zap.Logger, *levelRegistry, error) {
 	// 设置日志级别
-	level := zap.InfoLevel
-	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+	level, err := parseLevel(config.Level)
+	if err != nil {
 		level = zap.InfoLevel
 	}
+	registry := newLevelRegistry(level, config.Components)
 
 	// 创建编码器配置
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -57,28 +77,52 @@ func Init(config Config) error {
 		encoder = zapcore.NewJSONEncoder(encoderConfig)
 	}
 
-	// 创建文件写入器
-	fileWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   config.FilePath,
-		MaxSize:    config.MaxSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   true,
-	})
-
 	// 创建控制台写入器
 	consoleWriter := zapcore.AddSync(os.Stdout)
+	// 底层核心接收所有级别，由 componentCore 按组件过滤
+	cores := []zapcore.Core{
+		zapcore.NewCore(encoder, consoleWriter, zap.DebugLevel),
+	}
 
-	// 创建核心
-	core := zapcore.NewTee(
-		zapcore.NewCore(encoder, fileWriter, level),
-		zapcore.NewCore(encoder, consoleWriter, level),
-	)
+	// 配置了日志文件时同时写入文件
+	if config.FilePath != "" {
+		// 创建日志目录
+		if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err != nil {
+			return nil, nil, err
+		}
+
+		// 创建文件写入器
+		fileWriter := zapcore.AddSync(&lumberjack.Logger{
+			Filename:   config.FilePath,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAge,
+			Compress:   true,
+		})
+		cores = append(cores, zapcore.NewCore(encoder, fileWriter, zap.DebugLevel))
+	}
 
-	// 创建日志器
-	globalLogger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel))
+	// 配置了 syslog/journald 时同时写入（时间和级别由接收方记录，仅保留消息和字段）
+	if config.Sink.Type != "" {
+		sinkEncoderConfig := encoderConfig
+		sinkEncoderConfig.TimeKey = zapcore.OmitKey
+		sinkEncoderConfig.LevelKey = zapcore.OmitKey
+		sinkCore, err := newSinkCore(config.Sink, zapcore.NewJSONEncoder(sinkEncoderConfig), zap.DebugLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+		cores = append(cores, sinkCore)
+	}
 
-	return nil
+	// 创建日志器（所有输出统一脱敏，再按组件过滤级别）
+	core := &componentCore{
+		Core: &redactCore{
+			Core:     zapcore.NewTee(cores...),
+			redactor: newRedactor(config.RedactKeys),
+		},
+		registry: registry,
+	}
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel)), registry, nil
 }
 
 // NewLogger 创建新的日志器
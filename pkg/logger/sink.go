@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 额外日志输出类型
+const (
+	SinkSyslog   = "syslog"
+	SinkJournald = "journald"
+)
+
+// journaldSocket journald 原生协议套接字
+const journaldSocket = "/run/systemd/journal/socket"
+
+// SinkConfig 额外日志输出配置（在控制台和文件之外）
+type SinkConfig struct {
+	Type    string `yaml:"type"`    // syslog 或 journald，为空表示不启用
+	Network string `yaml:"network"` // syslog 网络类型: udp, tcp, unix；为空时使用本地 syslog
+	Address string `yaml:"address"` // syslog 地址
+	Tag     string `yaml:"tag"`     // syslog tag / journald SYSLOG_IDENTIFIER
+}
+
+// levelWriter 按日志级别写入的输出
+type levelWriter interface {
+	WriteLevel(level zapcore.Level, msg []byte) error
+	Sync() error
+}
+
+// sinkCore 将编码后的日志条目按级别写入 syslog/journald
+type sinkCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  levelWriter
+}
+
+// newSinkCore 根据配置创建额外输出核心
+func newSinkCore(config SinkConfig, encoder zapcore.Encoder, enabler zapcore.LevelEnabler) (zapcore.Core, error) {
+	tag := config.Tag
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+
+	var writer levelWriter
+	var err error
+	switch config.Type {
+	case SinkSyslog:
+		writer, err = newSyslogWriter(config.Network, config.Address, tag)
+	case SinkJournald:
+		writer, err = newJournaldWriter(tag)
+	default:
+		return nil, fmt.Errorf("不支持的日志输出类型: %s", config.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sinkCore{
+		LevelEnabler: enabler,
+		encoder:      encoder,
+		writer:       writer,
+	}, nil
+}
+
+// With 添加字段
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(clone)
+	}
+	return &sinkCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      clone,
+		writer:       c.writer,
+	}
+}
+
+// Check 检查是否需要记录
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 编码并写入日志条目
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	return c.writer.WriteLevel(entry.Level, bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// Sync 刷新输出
+func (c *sinkCore) Sync() error {
+	return c.writer.Sync()
+}
+
+// syslogWriter syslog 输出
+type syslogWriter struct {
+	writer *syslog.Writer
+}
+
+// newSyslogWriter 连接 syslog
+func newSyslogWriter(network, address, tag string) (*syslogWriter, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("连接 syslog 失败: %v", err)
+	}
+	return &syslogWriter{writer: writer}, nil
+}
+
+// WriteLevel 按级别写入 syslog
+func (w *syslogWriter) WriteLevel(level zapcore.Level, msg []byte) error {
+	text := string(msg)
+	switch level {
+	case zapcore.DebugLevel:
+		return w.writer.Debug(text)
+	case zapcore.InfoLevel:
+		return w.writer.Info(text)
+	case zapcore.WarnLevel:
+		return w.writer.Warning(text)
+	case zapcore.ErrorLevel:
+		return w.writer.Err(text)
+	default:
+		return w.writer.Crit(text)
+	}
+}
+
+// Sync syslog 无缓冲
+func (w *syslogWriter) Sync() error {
+	return nil
+}
+
+// journaldWriter journald 原生协议输出
+type journaldWriter struct {
+	mu   sync.Mutex
+	conn *net.UnixConn
+	tag  string
+}
+
+// newJournaldWriter 连接 journald
+func newJournaldWriter(tag string) (*journaldWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("连接 journald 失败: %v", err)
+	}
+	return &journaldWriter{conn: conn, tag: tag}, nil
+}
+
+// WriteLevel 按级别写入 journald
+func (w *journaldWriter) WriteLevel(level zapcore.Level, msg []byte) error {
+	var buf bytes.Buffer
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(journalPriority(level)))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", w.tag)
+	writeJournalField(&buf, "MESSAGE", string(msg))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.conn.Write(buf.Bytes())
+	return err
+}
+
+// Sync journald 无缓冲
+func (w *journaldWriter) Sync() error {
+	return nil
+}
+
+// writeJournalField 按 journald 原生协议编码字段，包含换行的值使用二进制长度格式
+func writeJournalField(buf *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(key + "=" + value + "\n")
+		return
+	}
+
+	buf.WriteString(key + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// journalPriority 将日志级别映射为 syslog 优先级
+func journalPriority(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
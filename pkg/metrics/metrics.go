@@ -0,0 +1,342 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 指标名称前缀
+const Namespace = "rdma_burst"
+
+// 传输结果标签值
+const (
+	ResultCompleted = "completed"
+	ResultFailed    = "failed"
+	ResultCancelled = "cancelled"
+)
+
+// 文件巡检结果标签值
+const (
+	ScrubOK        = "ok"
+	ScrubCorrupted = "corrupted"
+	ScrubSkipped   = "skipped" // 数据文件不存在或巡检期间被修改
+	ScrubError     = "error"
+)
+
+// transferLabels 传输指标标签
+var transferLabels = []string{"mode", "direction", "device", "result"}
+
+var (
+	// registry 独立的指标注册表，避免与依赖库的全局注册冲突
+	registry = prometheus.NewRegistry()
+
+	// transferDuration 传输耗时分布
+	transferDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "transfer_duration_seconds",
+		Help:      "Duration of completed transfer tasks in seconds.",
+		// 0.1s ~ 约 55min
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 16),
+	}, transferLabels)
+
+	// transferThroughput 传输吞吐量分布
+	transferThroughput = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "transfer_throughput_bytes_per_second",
+		Help:      "Throughput of completed transfer tasks in bytes per second.",
+		// 1MB/s ~ 约 32GB/s
+		Buckets: prometheus.ExponentialBuckets(1<<20, 2, 16),
+	}, transferLabels)
+
+	// transferBytes 传输字节总数
+	transferBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "transfer_bytes_total",
+		Help:      "Total bytes moved by transfer tasks.",
+	}, transferLabels)
+
+	// transfersTotal 传输任务总数
+	transfersTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "transfers_total",
+		Help:      "Total number of finished transfer tasks.",
+	}, transferLabels)
+
+	// transferSetup 服务端接受请求到监听进程就绪、客户端启动 rtranfile 和首字节的耗时分布
+	transferSetup = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "transfer_setup_seconds",
+		Help:      "Time from request acceptance to each setup stage (listener_started, process_spawned, first_byte) in seconds.",
+		// 0.05s ~ 约 7min
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 14),
+	}, []string{"mode", "stage"})
+
+	// slaMissedTotal 超过截止时间仍未完成的传输任务数
+	slaMissedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "sla_missed_total",
+		Help:      "Total number of transfer tasks not completed by their deadline.",
+	}, []string{"mode", "device"})
+
+	// activeTransfersGauge 进行中的传输任务数
+	activeTransfersGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "active_transfers",
+		Help:      "Number of transfer tasks currently in progress.",
+	})
+
+	// leaderGauge 是否持有协调者 Lease（仅启用选主时导出）
+	leaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "leader",
+		Help:      "Whether this instance holds the coordinator lease (1) or serves read-only APIs (0).",
+	})
+
+	// scrubFilesTotal 文件巡检按结果统计的文件数
+	scrubFilesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scrub_files_total",
+		Help:      "Total number of retained files re-verified by the scrubber, by result.",
+	}, []string{"mode", "result"})
+
+	// scrubCorruptedGauge 最近一轮巡检发现的损坏文件数
+	scrubCorruptedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "scrub_corrupted_files",
+		Help:      "Number of corrupted files found by the last completed scrub pass.",
+	})
+
+	// scrubLastRunGauge 最近一轮巡检完成的时间
+	scrubLastRunGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "scrub_last_run_timestamp_seconds",
+		Help:      "Unix time of the last completed scrub pass.",
+	})
+
+	// concurrencyLimitGauge 生效的并发传输上限，启用并发自动调整时随链路利用率变化
+	concurrencyLimitGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "concurrency_limit",
+		Help:      "Effective limit of concurrent transfers.",
+	})
+
+	// linkUtilizationGauge 并发自动调整最近一次计算的链路利用率（0-1）
+	linkUtilizationGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "link_utilization_ratio",
+		Help:      "RDMA link utilization (0-1) observed by concurrency autoscaling.",
+	})
+
+	// apiClientRequests 客户端模式调用服务端 API 的请求数，code 为 HTTP 状态码，网络错误为 error
+	apiClientRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "api_client_requests_total",
+		Help:      "Total number of requests sent to the server API in client mode, by method and status code.",
+	}, []string{"method", "code"})
+
+	// apiClientDuration 客户端模式调用服务端 API 的耗时分布
+	apiClientDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "api_client_request_duration_seconds",
+		Help:      "Duration of requests sent to the server API in client mode in seconds.",
+		// 5ms ~ 约 80s
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 15),
+	}, []string{"method"})
+
+	// mrLookupsTotal 内存注册缓存的查找次数，result 为 hit 或 miss
+	mrLookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "mr_cache_lookups_total",
+		Help:      "Total number of memory registration cache lookups, by device and result (hit, miss).",
+	}, []string{"device", "result"})
+
+	// mrEvictionsTotal 内存注册缓存淘汰的空闲注册数
+	mrEvictionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "mr_cache_evictions_total",
+		Help:      "Total number of idle memory registrations evicted from the cache.",
+	}, []string{"device"})
+
+	// mrRegisteredBytesGauge 内存注册缓存中的注册覆盖的字节数
+	mrRegisteredBytesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "mr_cache_registered_bytes",
+		Help:      "Bytes covered by memory registrations held in the cache.",
+	}, []string{"device"})
+)
+
+var (
+	// activeTransfers 进行中的传输任务数（供内置告警读取）
+	activeTransfers atomic.Int64
+
+	// finishedMu 保护 finished
+	finishedMu sync.Mutex
+	// finished 按结果统计的结束任务数（供内置告警读取）
+	finished = map[string]int64{}
+)
+
+func init() {
+	registry.MustRegister(
+		transferDuration,
+		transferThroughput,
+		transferBytes,
+		transfersTotal,
+		transferSetup,
+		slaMissedTotal,
+		activeTransfersGauge,
+		leaderGauge,
+		scrubFilesTotal,
+		scrubCorruptedGauge,
+		scrubLastRunGauge,
+		concurrencyLimitGauge,
+		linkUtilizationGauge,
+		apiClientRequests,
+		apiClientDuration,
+		mrLookupsTotal,
+		mrEvictionsTotal,
+		mrRegisteredBytesGauge,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Registry 获取指标注册表，供其他模块注册自定义指标
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Handler 获取 Prometheus 指标 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveTransfer 记录一次结束的传输任务，throughput 仅在成功且字节数有效时记录
+func ObserveTransfer(mode, direction, device, result string, duration time.Duration, bytes int64) {
+	labels := prometheus.Labels{
+		"mode":      mode,
+		"direction": direction,
+		"device":    device,
+		"result":    result,
+	}
+
+	transfersTotal.With(labels).Inc()
+	finishedMu.Lock()
+	finished[result]++
+	finishedMu.Unlock()
+
+	transferDuration.With(labels).Observe(duration.Seconds())
+
+	if bytes > 0 {
+		transferBytes.With(labels).Add(float64(bytes))
+		if result == ResultCompleted && duration > 0 {
+			transferThroughput.With(labels).Observe(float64(bytes) / duration.Seconds())
+		}
+	}
+}
+
+// ObserveSetup 记录任务从服务端接受请求到某个准备阶段（监听进程就绪、rtranfile 启动、首字节）的耗时
+func ObserveSetup(mode, stage string, duration time.Duration) {
+	transferSetup.WithLabelValues(mode, stage).Observe(duration.Seconds())
+}
+
+// SLAMissed 记录一个超过截止时间仍未完成的传输任务
+func SLAMissed(mode, device string) {
+	slaMissedTotal.WithLabelValues(mode, device).Inc()
+}
+
+// ObserveAPIRequest 记录一次客户端模式调用服务端 API 的请求，status 为 0 表示网络错误
+func ObserveAPIRequest(method string, status int, duration time.Duration) {
+	code := "error"
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	apiClientRequests.WithLabelValues(method, code).Inc()
+	apiClientDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// TransferStarted 记录一个传输任务开始
+func TransferStarted() {
+	activeTransfersGauge.Set(float64(activeTransfers.Add(1)))
+}
+
+// TransferFinished 记录一个传输任务结束
+func TransferFinished() {
+	activeTransfersGauge.Set(float64(activeTransfers.Add(-1)))
+}
+
+// SetLeader 记录当前实例是否是协调者领导者
+func SetLeader(leader bool) {
+	if leader {
+		leaderGauge.Set(1)
+	} else {
+		leaderGauge.Set(0)
+	}
+}
+
+// ObserveScrub 记录一个文件的巡检结果
+func ObserveScrub(mode, result string) {
+	scrubFilesTotal.WithLabelValues(mode, result).Inc()
+}
+
+// SetScrubCorrupted 记录最近一轮巡检发现的损坏文件数
+func SetScrubCorrupted(count int) {
+	scrubCorruptedGauge.Set(float64(count))
+}
+
+// ScrubFinished 记录一轮巡检完成
+func ScrubFinished(at time.Time) {
+	scrubLastRunGauge.Set(float64(at.Unix()))
+}
+
+// SetConcurrencyLimit 记录生效的并发传输上限
+func SetConcurrencyLimit(limit int) {
+	concurrencyLimitGauge.Set(float64(limit))
+}
+
+// SetLinkUtilization 记录并发自动调整观测到的链路利用率
+func SetLinkUtilization(ratio float64) {
+	linkUtilizationGauge.Set(ratio)
+}
+
+// ObserveMRLookup 记录一次内存注册缓存查找
+func ObserveMRLookup(device string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	mrLookupsTotal.WithLabelValues(device, result).Inc()
+}
+
+// MREvicted 记录一个被淘汰的空闲内存注册
+func MREvicted(device string) {
+	mrEvictionsTotal.WithLabelValues(device).Inc()
+}
+
+// SetMRRegisteredBytes 记录内存注册缓存中设备的注册覆盖的字节数
+func SetMRRegisteredBytes(device string, bytes int64) {
+	mrRegisteredBytesGauge.WithLabelValues(device).Set(float64(bytes))
+}
+
+// ActiveTransfers 获取进行中的传输任务数
+func ActiveTransfers() int64 {
+	return activeTransfers.Load()
+}
+
+// FinishedCounts 获取按结果统计的结束任务数
+func FinishedCounts() map[string]int64 {
+	finishedMu.Lock()
+	defer finishedMu.Unlock()
+
+	counts := make(map[string]int64, len(finished))
+	for result, count := range finished {
+		counts[result] = count
+	}
+	return counts
+}